@@ -1,16 +1,34 @@
 package infrastructure
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// IRevocationList reports whether an access token, identified by its jti
+// claim, has been revoked before its expiry.
+type IRevocationList interface {
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// IAccountChecker reports whether a user's account still exists and is
+// enabled.
+type IAccountChecker interface {
+	IsAccountActive(ctx context.Context, userID primitive.ObjectID) (bool, error)
+}
+
 // AuthMiddleware creates a middleware that validates a JWT using the provided JWTService.
-func AuthMiddleware(jwtService IJWTService) gin.HandlerFunc {
+// When revocations is non-nil, tokens whose jti has been revoked are rejected.
+// When accounts is non-nil, tokens of deleted or disabled users are rejected,
+// even though they have not expired yet.
+func AuthMiddleware(jwtService IJWTService, revocations IRevocationList, accounts IAccountChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -30,25 +48,194 @@ func AuthMiddleware(jwtService IJWTService) gin.HandlerFunc {
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Set("user_id", claims["user_id"])
-			c.Set("role", claims["role"])
-			c.Next()
-		} else {
+		claims, ok := token.Claims.(jwt.MapClaims)
+		// Stream tickets are signed with the same key, but are not access tokens.
+		if typ, _ := claims["typ"].(string); !ok || !token.Valid || typ != "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+			return
+		}
+
+		if revocations != nil {
+			jti, _ := claims["jti"].(string)
+			if jti == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+				return
+			}
+			revoked, err := revocations.IsAccessTokenRevoked(c.Request.Context(), jti)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
+				return
+			}
+			if revoked {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+				return
+			}
+		}
+
+		if !checkAccount(c, accounts, claims) {
+			return
+		}
+
+		c.Set("user_id", claims["user_id"])
+		c.Set("token_id", claims["jti"])
+		if expiresAt, err := claims.GetExpirationTime(); err == nil && expiresAt != nil {
+			c.Set("token_expires_at", expiresAt.Time)
+		}
+		c.Next()
+	}
+}
+
+// checkAccount answers the request itself and returns false when accounts
+// is non-nil and the user of claims is deleted or disabled.
+func checkAccount(c *gin.Context, accounts IAccountChecker, claims jwt.MapClaims) bool {
+	if accounts == nil {
+		return true
+	}
+	userIDHex, _ := claims["user_id"].(string)
+	userID, err := primitive.ObjectIDFromHex(userIDHex)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		return false
+	}
+	active, err := accounts.IsAccountActive(c.Request.Context(), userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify account"})
+		return false
+	}
+	if !active {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Account is disabled"})
+		return false
+	}
+	return true
+}
+
+// ITicketLedger records the use of single-use tickets.
+type ITicketLedger interface {
+	// ConsumeToken marks the ticket with the jti as used until it expires at
+	// expiresAt, and reports whether it had not been used before.
+	ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error)
+}
+
+// StreamAuthMiddleware authenticates like AuthMiddleware. Browsers cannot set
+// headers on EventSource and WebSocket connections, so it also takes a stream
+// ticket from the ticket query parameter. A ticket opens one connection,
+// within StreamTicketTTL of being issued, for as long as the access token it
+// was issued for is valid. Access tokens are never read from the query
+// string, where proxy logs and browser history would keep them.
+func StreamAuthMiddleware(jwtService IJWTService, revocations IRevocationList, tickets ITicketLedger, accounts IAccountChecker) gin.HandlerFunc {
+	auth := AuthMiddleware(jwtService, revocations, accounts)
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" || c.GetHeader("Authorization") != "" {
+			if c.Query("access_token") != "" && c.GetHeader("Authorization") == "" {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Pass a ticket from POST /tasks/stream/ticket instead of access_token"})
+				return
+			}
+			auth(c)
+			return
+		}
+
+		token, err := jwtService.ValidateToken(ticket)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired ticket"})
+			return
+		}
+		claims, ok := token.Claims.(jwt.MapClaims)
+		jti, _ := claims["jti"].(string)
+		expiresAt, _ := claims.GetExpirationTime()
+		if typ, _ := claims["typ"].(string); !ok || !token.Valid || typ != streamTicketType || jti == "" || expiresAt == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid ticket claims"})
+			return
 		}
+
+		// Logging out revokes the access token, and with it its tickets.
+		if revocations != nil {
+			sessionID, _ := claims["sid"].(string)
+			revoked, err := revocations.IsAccessTokenRevoked(c.Request.Context(), sessionID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify ticket"})
+				return
+			}
+			if revoked {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+				return
+			}
+		}
+		if !checkAccount(c, accounts, claims) {
+			return
+		}
+		first, err := tickets.ConsumeToken(c.Request.Context(), jti, expiresAt.Time)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify ticket"})
+			return
+		}
+		if !first {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Ticket has already been used"})
+			return
+		}
+
+		c.Set("user_id", claims["user_id"])
+		if sessionExp, ok := claims["session_exp"].(float64); ok {
+			c.Set("token_expires_at", time.Unix(int64(sessionExp), 0))
+		}
+		c.Next()
 	}
 }
 
-// RoleAuthMiddleware creates a middleware to check for a specific user role.
-func RoleAuthMiddleware(requiredRole string) gin.HandlerFunc {
+// IPermissionChecker resolves the permissions a user holds through their
+// roles.
+type IPermissionChecker interface {
+	UserPermissions(ctx context.Context, userID primitive.ObjectID) ([]string, error)
+}
+
+// RequirePermission creates a middleware that only lets through users
+// holding the given permission. It must run after AuthMiddleware. The
+// permissions are looked up on every request rather than read from the
+// token, so role changes apply at once.
+func RequirePermission(checker IPermissionChecker, permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		log.Printf("DEBUG: Role from context: %v (%T), Required role: %s", role, role, requiredRole)
-		if !exists || role.(string) != requiredRole {
+		userID, err := primitive.ObjectIDFromHex(c.GetString("user_id"))
+		if err != nil {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden: insufficient permissions"})
 			return
 		}
+		permissions, err := checker.UserPermissions(c.Request.Context(), userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
+			return
+		}
+		if !slices.Contains(permissions, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden: insufficient permissions"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// IFeedTokenResolver finds the user a calendar feed token belongs to. It
+// returns a zero ID for tokens that are unknown or revoked.
+type IFeedTokenResolver interface {
+	ResolveFeedToken(ctx context.Context, token string) (primitive.ObjectID, error)
+}
+
+// FeedTokenMiddleware authenticates calendar feed requests. Calendar apps
+// cannot send an Authorization header, so the secret token is the :token
+// path parameter, with an optional .ics extension. It sets user_id only;
+// feed routes must not be given permission-protected handlers.
+func FeedTokenMiddleware(resolver IFeedTokenResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSuffix(c.Param("token"), ".ics")
+		userID, err := resolver.ResolveFeedToken(c.Request.Context(), token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
+			return
+		}
+		// A revoked feed looks like one that never existed.
+		if userID.IsZero() {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Calendar feed not found"})
+			return
+		}
+		c.Set("user_id", userID.Hex())
 		c.Next()
 	}
 }
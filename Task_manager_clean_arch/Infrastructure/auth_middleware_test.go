@@ -1,31 +1,31 @@
 package infrastructure
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"taskmanager/domain"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-func setupTestRouter(t *testing.T, jwtService IJWTService) *gin.Engine {
+func setupTestRouter(t *testing.T, jwtService IJWTService, revocations IRevocationList, accounts IAccountChecker) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 
 	router := gin.New()
 
 	testHandler := func(c *gin.Context) {
-		role, _ := c.Get("role")
 		userID, _ := c.Get("user_id")
 
-		assert.Equal(t, "user", role)
 		assert.NotEmpty(t, userID)
 		c.Status(http.StatusOK)
 	}
-	router.GET("/test", AuthMiddleware(jwtService), testHandler)
+	router.GET("/test", AuthMiddleware(jwtService, revocations, accounts), testHandler)
 	return router
 }
 
@@ -34,11 +34,12 @@ func TestAuthMiddleware(t *testing.T) {
 	defer os.Unsetenv("JWT_SECRET")
 
 	jwtService := NewJWTService()
-	testUser := domain.User{ID: primitive.NewObjectID(), Role: "user"}
-	validToken, err := jwtService.GenerateToken(testUser)
+	testUser := domain.User{ID: primitive.NewObjectID(), Roles: []string{"user"}}
+	accessToken, err := jwtService.GenerateToken(testUser)
 	assert.NoError(t, err)
+	validToken := accessToken.Token
 
-	router := setupTestRouter(t, jwtService)
+	router := setupTestRouter(t, jwtService, nil, nil)
 
 	t.Run("Success - Valid Token", func(t *testing.T) {
 
@@ -80,54 +81,245 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 }
 
-func TestRoleAuthMiddleware(t *testing.T) {
+// staticRevocationList reports a fixed set of jtis as revoked.
+type staticRevocationList map[string]bool
 
-	gin.SetMode(gin.TestMode)
+func (l staticRevocationList) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return l[jti], nil
+}
 
-	protectedHandler := func(c *gin.Context) {
-		c.Status(http.StatusOK)
-	}
+func TestAuthMiddleware_RevokedToken(t *testing.T) {
+	os.Setenv("JWT_SECRET", "a_secret_for_testing")
+	defer os.Unsetenv("JWT_SECRET")
 
-	t.Run("Success - User has required role", func(t *testing.T) {
-		router := gin.New()
-		setContextMiddleware := func(c *gin.Context) {
-			c.Set("role", "admin")
-			c.Next()
-		}
-		router.GET("/protected", setContextMiddleware, RoleAuthMiddleware("admin"), protectedHandler)
+	jwtService := NewJWTService()
+	testUser := domain.User{ID: primitive.NewObjectID(), Roles: []string{"user"}}
+	revokedToken, err := jwtService.GenerateToken(testUser)
+	assert.NoError(t, err)
+	liveToken, err := jwtService.GenerateToken(testUser)
+	assert.NoError(t, err)
+
+	router := setupTestRouter(t, jwtService, staticRevocationList{revokedToken.ID: true}, nil)
 
+	t.Run("Success - Token Not Revoked", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+liveToken.Token)
 		rr := httptest.NewRecorder()
-		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
 
+	t.Run("Failure - Revoked Token", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+revokedToken.Token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Token has been revoked")
+	})
+}
+
+// staticAccounts reports the users in the set as active.
+type staticAccounts map[primitive.ObjectID]bool
+
+func (a staticAccounts) IsAccountActive(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	return a[userID], nil
+}
+
+func TestAuthMiddleware_InactiveAccount(t *testing.T) {
+	os.Setenv("JWT_SECRET", "a_secret_for_testing")
+	defer os.Unsetenv("JWT_SECRET")
+
+	jwtService := NewJWTService()
+	activeUser := domain.User{ID: primitive.NewObjectID(), Roles: []string{"user"}}
+	disabledUser := domain.User{ID: primitive.NewObjectID(), Roles: []string{"user"}}
+	activeToken, err := jwtService.GenerateToken(activeUser)
+	assert.NoError(t, err)
+	disabledToken, err := jwtService.GenerateToken(disabledUser)
+	assert.NoError(t, err)
+
+	router := setupTestRouter(t, jwtService, nil, staticAccounts{activeUser.ID: true})
+
+	t.Run("Success - Active Account", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+activeToken.Token)
+		rr := httptest.NewRecorder()
 		router.ServeHTTP(rr, req)
 		assert.Equal(t, http.StatusOK, rr.Code)
 	})
 
-	t.Run("Failure - User has wrong role", func(t *testing.T) {
+	t.Run("Failure - Disabled or Deleted Account", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+disabledToken.Token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), "Account is disabled")
+	})
+}
+
+// ticketLedger remembers the tickets that have been used.
+type ticketLedger map[string]bool
+
+func (l ticketLedger) ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	if l[jti] {
+		return false, nil
+	}
+	l[jti] = true
+	return true, nil
+}
+
+func TestStreamAuthMiddleware(t *testing.T) {
+	os.Setenv("JWT_SECRET", "a_secret_for_testing")
+	defer os.Unsetenv("JWT_SECRET")
+
+	jwtService := NewJWTService()
+	testUser := domain.User{ID: primitive.NewObjectID(), Roles: []string{"user"}}
+	accessToken, err := jwtService.GenerateToken(testUser)
+	assert.NoError(t, err)
+	revokedToken, err := jwtService.GenerateToken(testUser)
+	assert.NoError(t, err)
+	issue := func(session *AccessToken) string {
+		ticket, err := jwtService.GenerateStreamTicket(testUser.ID.Hex(), session.ID, session.ExpiresAt)
+		assert.NoError(t, err)
+		return ticket.Token
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/stream", StreamAuthMiddleware(jwtService, staticRevocationList{revokedToken.ID: true}, ticketLedger{}, nil), func(c *gin.Context) {
+		expiresAt, _ := c.Get("token_expires_at")
+		assert.Equal(t, accessToken.ExpiresAt.Unix(), expiresAt.(time.Time).Unix(), "the stream lasts as long as the access token")
+		assert.Equal(t, testUser.ID.Hex(), c.GetString("user_id"))
+		c.Status(http.StatusOK)
+	})
+	router.GET("/protected", AuthMiddleware(jwtService, nil, nil), func(c *gin.Context) { c.Status(http.StatusOK) })
+	serve := func(path, authorization string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Success - Ticket in Query, Once", func(t *testing.T) {
+		ticket := issue(accessToken)
+		assert.Equal(t, http.StatusOK, serve("/stream?ticket="+ticket, "").Code)
+		rr := serve("/stream?ticket="+ticket, "")
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), "already been used")
+	})
+
+	t.Run("Success - Token in Header", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("/stream", "Bearer "+accessToken.Token).Code)
+	})
+
+	t.Run("Failure - Access Token in Query", func(t *testing.T) {
+		rr := serve("/stream?access_token="+accessToken.Token, "")
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Contains(t, rr.Body.String(), "POST /tasks/stream/ticket")
+	})
+
+	t.Run("Failure - Ticket of a Revoked Token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("/stream?ticket="+issue(revokedToken), "").Code)
+	})
+
+	t.Run("Failure - Expired Ticket", func(t *testing.T) {
+		expired, err := jwtService.GenerateStreamTicket(testUser.ID.Hex(), accessToken.ID, time.Now().Add(-time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, serve("/stream?ticket="+expired.Token, "").Code)
+	})
+
+	t.Run("Failure - Tickets and Access Tokens Are Not Interchangeable", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("/protected", "Bearer "+issue(accessToken)).Code)
+		assert.Equal(t, http.StatusUnauthorized, serve("/stream?ticket="+accessToken.Token, "").Code)
+	})
+
+	t.Run("Failure - No Token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("/stream", "").Code)
+	})
+}
+
+// staticPermissions grants each user the permissions in its map.
+type staticPermissions map[primitive.ObjectID][]string
+
+func (p staticPermissions) UserPermissions(ctx context.Context, userID primitive.ObjectID) ([]string, error) {
+	return p[userID], nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager, user := primitive.NewObjectID(), primitive.NewObjectID()
+	checker := staticPermissions{
+		manager: {domain.PermTaskCreate, domain.PermTaskUpdateAny},
+		user:    nil,
+	}
+
+	protectedHandler := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	serveAs := func(userID string) *httptest.ResponseRecorder {
 		router := gin.New()
 		setContextMiddleware := func(c *gin.Context) {
-			c.Set("role", "user")
+			if userID != "" {
+				c.Set("user_id", userID)
+			}
 			c.Next()
 		}
-		router.GET("/protected", setContextMiddleware, RoleAuthMiddleware("admin"), protectedHandler)
+		router.GET("/protected", setContextMiddleware, RequirePermission(checker, domain.PermTaskCreate), protectedHandler)
 
 		rr := httptest.NewRecorder()
 		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
-
 		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Success - User holds the permission", func(t *testing.T) {
+		rr := serveAs(manager.Hex())
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Failure - User lacks the permission", func(t *testing.T) {
+		rr := serveAs(user.Hex())
 		assert.Equal(t, http.StatusForbidden, rr.Code)
 		assert.Contains(t, rr.Body.String(), "Forbidden")
 	})
 
-	t.Run("Failure - Role not set in context", func(t *testing.T) {
-		router := gin.New()
-		router.GET("/protected", RoleAuthMiddleware("admin"), protectedHandler)
+	t.Run("Failure - User not set in context", func(t *testing.T) {
+		rr := serveAs("")
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
 
-		rr := httptest.NewRecorder()
-		req, _ := http.NewRequest(http.MethodGet, "/protected", nil)
+// feedTokens resolves the feed tokens in its map.
+type feedTokens map[string]primitive.ObjectID
 
-		router.ServeHTTP(rr, req)
+func (f feedTokens) ResolveFeedToken(ctx context.Context, token string) (primitive.ObjectID, error) {
+	return f[token], nil
+}
 
-		assert.Equal(t, http.StatusForbidden, rr.Code)
+func TestFeedTokenMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ownerID := primitive.NewObjectID()
+	router := gin.New()
+	router.GET("/calendar/:token", FeedTokenMiddleware(feedTokens{"secret": ownerID}), func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		assert.Equal(t, ownerID.Hex(), userID)
+		c.Status(http.StatusOK)
 	})
+
+	for path, want := range map[string]int{
+		"/calendar/secret.ics":  http.StatusOK,
+		"/calendar/secret":      http.StatusOK,
+		"/calendar/revoked.ics": http.StatusNotFound,
+	} {
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(rr, req)
+
+		// --- ASSERT ---
+		assert.Equal(t, want, rr.Code, path)
+	}
 }
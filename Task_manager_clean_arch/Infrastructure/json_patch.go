@@ -0,0 +1,323 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidJSONPatch is returned for a patch that is not a well-formed
+	// RFC 6902 document.
+	ErrInvalidJSONPatch = errors.New("invalid JSON patch")
+	// ErrJSONPatchConflict is returned when a well-formed patch cannot be
+	// applied to the document, such as a path that does not exist.
+	ErrJSONPatchConflict = errors.New("JSON patch cannot be applied")
+	// ErrJSONPatchTestFailed is returned when a "test" operation fails.
+	ErrJSONPatchTestFailed = errors.New("JSON patch test failed")
+)
+
+// jsonPatchOperation is one entry of an RFC 6902 patch.
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch to a JSON document and
+// returns the patched document. The operations apply in order and either all
+// of them do or none does.
+func ApplyJSONPatch(document, patch []byte) ([]byte, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSONPatch, err)
+	}
+	doc, err := decodeJSON(document)
+	if err != nil {
+		return nil, err
+	}
+	for i, operation := range operations {
+		if doc, err = applyJSONPatchOperation(doc, operation); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func applyJSONPatchOperation(doc interface{}, operation jsonPatchOperation) (interface{}, error) {
+	if operation.Path == nil {
+		return nil, fmt.Errorf("%w: %q needs a path", ErrInvalidJSONPatch, operation.Op)
+	}
+	path, err := parseJSONPointer(*operation.Path)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("%w: %q needs a value", ErrInvalidJSONPatch, operation.Op)
+		}
+		if value, err = decodeJSON(operation.Value); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		if operation.From == nil {
+			return nil, fmt.Errorf("%w: %q needs from", ErrInvalidJSONPatch, operation.Op)
+		}
+		from, err := parseJSONPointer(*operation.From)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = getJSONPointer(doc, from); err != nil {
+			return nil, err
+		}
+		if operation.Op == "move" {
+			if isJSONPointerPrefix(from, path) && len(from) < len(path) {
+				return nil, fmt.Errorf("%w: cannot move %s into itself", ErrJSONPatchConflict, *operation.From)
+			}
+			if doc, err = removeJSONPointer(doc, from); err != nil {
+				return nil, err
+			}
+		} else if value, err = copyJSON(value); err != nil {
+			return nil, err
+		}
+		return addJSONPointer(doc, path, value)
+	case "remove":
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidJSONPatch, operation.Op)
+	}
+
+	switch operation.Op {
+	case "add":
+		return addJSONPointer(doc, path, value)
+	case "remove":
+		return removeJSONPointer(doc, path)
+	case "replace":
+		if len(path) == 0 {
+			return value, nil
+		}
+		if doc, err = removeJSONPointer(doc, path); err != nil {
+			return nil, err
+		}
+		return addJSONPointer(doc, path, value)
+	default: // test
+		current, err := getJSONPointer(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(current, value) {
+			return nil, fmt.Errorf("%w: %s", ErrJSONPatchTestFailed, *operation.Path)
+		}
+		return doc, nil
+	}
+}
+
+// decodeJSON decodes one JSON value, keeping numbers exact.
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJSONPatch, err)
+	}
+	return value, nil
+}
+
+func copyJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(data)
+}
+
+// jsonEqual compares two decoded JSON values, numbers by value.
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			other, ok := b[key]
+			if !ok || !jsonEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// parseJSONPointer splits an RFC 6901 pointer into its unescaped tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: path %q must start with /", ErrInvalidJSONPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isJSONPointerPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex reads an array index token. "-" and len are only valid when
+// appending.
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if token == "-" && appending {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("%w: %q is not an array index", ErrJSONPatchConflict, token)
+	}
+	if index > length || (index == length && !appending) {
+		return 0, fmt.Errorf("%w: index %d is out of range", ErrJSONPatchConflict, index)
+	}
+	return index, nil
+}
+
+func getJSONPointer(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			child, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: %q does not exist", ErrJSONPatchConflict, token)
+			}
+			doc = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("%w: %q is not inside an object or array", ErrJSONPatchConflict, token)
+		}
+	}
+	return doc, nil
+}
+
+// updateJSONPointer walks to the container holding the last token of path
+// and replaces it with what edit returns.
+func updateJSONPointer(doc interface{}, path []string, edit func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return edit(doc, path[0])
+	}
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q does not exist", ErrJSONPatchConflict, path[0])
+		}
+		updated, err := updateJSONPointer(child, path[1:], edit)
+		if err != nil {
+			return nil, err
+		}
+		node[path[0]] = updated
+		return node, nil
+	case []interface{}:
+		index, err := arrayIndex(path[0], len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := updateJSONPointer(node[index], path[1:], edit)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = updated
+		return node, nil
+	default:
+		return nil, fmt.Errorf("%w: %q is not inside an object or array", ErrJSONPatchConflict, path[0])
+	}
+}
+
+func addJSONPointer(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updateJSONPointer(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("%w: %q is not inside an object or array", ErrJSONPatchConflict, token)
+		}
+	})
+}
+
+func removeJSONPointer(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the whole document", ErrJSONPatchConflict)
+	}
+	return updateJSONPointer(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("%w: %q does not exist", ErrJSONPatchConflict, token)
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:index], node[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%w: %q is not inside an object or array", ErrJSONPatchConflict, token)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	cases := []struct {
+		name     string
+		document string
+		patch    string
+		want     string
+	}{
+		{"add member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`},
+		{"remove", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":null}]`, `{"baz":null,"foo":"bar"}`},
+		{"move", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{"copy", `{"a":[1]}`, `[{"op":"copy","from":"/a","path":"/b"},{"op":"add","path":"/b/-","value":2}]`, `{"a":[1],"b":[1,2]}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"replace","path":"/a~1b","value":3},{"op":"remove","path":"/m~0n"}]`, `{"a/b":3}`},
+		{"passing test", `{"n":10,"s":"x"}`, `[{"op":"test","path":"/n","value":10.0},{"op":"test","path":"/s","value":"x"}]`, `{"n":10,"s":"x"}`},
+		{"replace root", `{"a":1}`, `[{"op":"replace","path":"","value":{"b":2}}]`, `{"b":2}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ApplyJSONPatch([]byte(tc.document), []byte(tc.patch))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestApplyJSONPatch_Failures(t *testing.T) {
+	cases := []struct {
+		name  string
+		patch string
+		want  error
+	}{
+		{"not an array", `{"op":"add"}`, ErrInvalidJSONPatch},
+		{"unknown op", `[{"op":"frob","path":"/a"}]`, ErrInvalidJSONPatch},
+		{"missing value", `[{"op":"add","path":"/a"}]`, ErrInvalidJSONPatch},
+		{"relative path", `[{"op":"remove","path":"a"}]`, ErrInvalidJSONPatch},
+		{"missing member", `[{"op":"remove","path":"/missing"}]`, ErrJSONPatchConflict},
+		{"index out of range", `[{"op":"add","path":"/list/5","value":1}]`, ErrJSONPatchConflict},
+		{"leading zero index", `[{"op":"remove","path":"/list/01"}]`, ErrJSONPatchConflict},
+		{"move into itself", `[{"op":"move","from":"/list","path":"/list/0"}]`, ErrJSONPatchConflict},
+		{"failed test", `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`, ErrJSONPatchTestFailed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ApplyJSONPatch([]byte(`{"a":1,"list":[1,2]}`), []byte(tc.patch))
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package infrastructure
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"taskmanager/domain"
@@ -9,13 +11,37 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// DefaultAccessTokenTTL is how long an access token lives unless
+// ACCESS_TOKEN_TTL overrides it. Sessions are extended with refresh tokens.
+const DefaultAccessTokenTTL = 15 * time.Minute
+
+// StreamTicketTTL is how long a stream ticket can wait to be used.
+const StreamTicketTTL = 30 * time.Second
+
+// streamTicketType is the typ claim of a stream ticket. Access tokens have
+// none, so neither can be used in place of the other.
+const streamTicketType = "stream"
+
+// AccessToken is a signed JWT together with the claims callers need to
+// track it: its unique ID (jti) and its expiry.
+type AccessToken struct {
+	Token     string
+	ID        string
+	ExpiresAt time.Time
+}
+
 type IJWTService interface {
-	GenerateToken(user domain.User) (string, error)
+	GenerateToken(user domain.User) (*AccessToken, error)
+	// GenerateStreamTicket issues a single-use ticket that opens one task
+	// stream for the user of the access token sessionID. The stream ends
+	// when that access token expires, at sessionExpiresAt.
+	GenerateStreamTicket(userID, sessionID string, sessionExpiresAt time.Time) (*AccessToken, error)
 	ValidateToken(tokenString string) (*jwt.Token, error)
 }
 
 type jwtService struct {
 	secretKey string
+	ttl       time.Duration
 }
 
 func NewJWTService() IJWTService {
@@ -23,19 +49,75 @@ func NewJWTService() IJWTService {
 	if secret == "" {
 		panic("JWT_SECRET environment variable not set")
 	}
-	return &jwtService{secretKey: secret}
+
+	ttl := DefaultAccessTokenTTL
+	if value := os.Getenv("ACCESS_TOKEN_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			panic("ACCESS_TOKEN_TTL must be a positive duration such as 15m")
+		}
+		ttl = parsed
+	}
+	return &jwtService{secretKey: secret, ttl: ttl}
+}
+
+// newTokenID returns a random jti claim.
+func newTokenID() (string, error) {
+	jtiBytes := make([]byte, 16)
+	if _, err := rand.Read(jtiBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(jtiBytes), nil
 }
 
-func (s *jwtService) GenerateToken(user domain.User) (string, error) {
+func (s *jwtService) GenerateToken(user domain.User) (*AccessToken, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(s.ttl)
+
 	claims := jwt.MapClaims{
+		"jti":      jti,
 		"user_id":  user.ID.Hex(),
 		"username": user.Username,
-		"role":     user.Role,
-		"exp":      time.Now().Add(time.Hour * 72).Unix(),
+		"roles":    user.Roles,
+		"exp":      expiresAt.Unix(),
+	}
+
+	return s.sign(claims, jti, expiresAt)
+}
+
+func (s *jwtService) GenerateStreamTicket(userID, sessionID string, sessionExpiresAt time.Time) (*AccessToken, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(StreamTicketTTL)
+	if !sessionExpiresAt.IsZero() && sessionExpiresAt.Before(expiresAt) {
+		expiresAt = sessionExpiresAt
 	}
 
+	claims := jwt.MapClaims{
+		"jti":     jti,
+		"typ":     streamTicketType,
+		"user_id": userID,
+		"sid":     sessionID,
+		"exp":     expiresAt.Unix(),
+	}
+	if !sessionExpiresAt.IsZero() {
+		claims["session_exp"] = sessionExpiresAt.Unix()
+	}
+	return s.sign(claims, jti, expiresAt)
+}
+
+func (s *jwtService) sign(claims jwt.MapClaims, jti string, expiresAt time.Time) (*AccessToken, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.secretKey))
+	signed, err := token.SignedString([]byte(s.secretKey))
+	if err != nil {
+		return nil, err
+	}
+	return &AccessToken{Token: signed, ID: jti, ExpiresAt: expiresAt}, nil
 }
 
 func (s *jwtService) ValidateToken(tokenString string) (*jwt.Token, error) {
@@ -4,6 +4,7 @@ import (
 	"os"
 	"taskmanager/domain"
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -20,13 +21,16 @@ func TestJWTService(t *testing.T) {
 	user := domain.User{
 		ID:       userID,
 		Username: "testuser",
-		Role:     "admin",
+		Roles:    []string{"admin", "manager"},
 	}
 
 	// Test Token Generation
-	tokenString, err := jwtService.GenerateToken(user)
+	accessToken, err := jwtService.GenerateToken(user)
 	assert.NoError(t, err)
-	assert.NotEmpty(t, tokenString)
+	assert.NotEmpty(t, accessToken.Token)
+	assert.NotEmpty(t, accessToken.ID)
+	assert.WithinDuration(t, time.Now().Add(DefaultAccessTokenTTL), accessToken.ExpiresAt, time.Minute)
+	tokenString := accessToken.Token
 
 	// Test Token Validation (Success)
 	validatedToken, err := jwtService.ValidateToken(tokenString)
@@ -38,7 +42,8 @@ func TestJWTService(t *testing.T) {
 	claims, ok := validatedToken.Claims.(jwt.MapClaims)
 	assert.True(t, ok)
 	assert.Equal(t, userID.Hex(), claims["user_id"])
-	assert.Equal(t, "admin", claims["role"])
+	assert.Equal(t, []interface{}{"admin", "manager"}, claims["roles"])
+	assert.Equal(t, accessToken.ID, claims["jti"])
 
 	// Test Token Validation (Failure - Malformed Token)
 	_, err = jwtService.ValidateToken("this.is.a.bad.token")
@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Mail is a message to one user. Accounts have no e-mail address, so To is
+// the recipient's username; a mailer that sends real mail looks up where it
+// goes.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// IMailer delivers mail to users, such as password reset tokens.
+type IMailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+type logMailer struct {
+	logger *log.Logger
+}
+
+// NewLogMailer notes mail in logger, or in the standard logger when logger
+// is nil. Only the recipient and subject are written: bodies carry
+// secrets such as password reset tokens, and anyone who can read the log
+// could use them.
+func NewLogMailer(logger *log.Logger) IMailer {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &logMailer{logger: logger}
+}
+
+func (m *logMailer) Send(ctx context.Context, mail Mail) error {
+	m.logger.Printf("Mail to %s: %s (body withheld; no mailer is configured)", mail.To, mail.Subject)
+	return nil
+}
+
+type fileMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileMailer appends mail to the file at path, creating it when needed,
+// so that messages can be read during local development.
+func NewFileMailer(path string) IMailer {
+	return &fileMailer{path: path}
+}
+
+func (m *fileMailer) Send(ctx context.Context, mail Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "Date: %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		time.Now().UTC().Format(time.RFC1123Z), mail.To, mail.Subject, mail.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMailer(t *testing.T) {
+	var out bytes.Buffer
+	mailer := NewLogMailer(log.New(&out, "", 0))
+
+	err := mailer.Send(context.Background(), Mail{To: "alice", Subject: "Reset your password", Body: "token: abc"})
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "Mail to alice: Reset your password")
+	assert.NotContains(t, out.String(), "abc", "the body is not logged")
+}
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.txt")
+	mailer := NewFileMailer(path)
+
+	require.NoError(t, mailer.Send(context.Background(), Mail{To: "alice", Subject: "First", Body: "one"}))
+	require.NoError(t, mailer.Send(context.Background(), Mail{To: "bob", Subject: "Second", Body: "two"}))
+	data, err := os.ReadFile(path)
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "To: alice\nSubject: First\n\none\n")
+	assert.Contains(t, string(data), "To: bob\nSubject: Second\n\ntwo\n")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
@@ -0,0 +1,109 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"taskmanager/domain"
+	"time"
+)
+
+// INotifier delivers a due-date reminder for a task to the reminder's user.
+type INotifier interface {
+	Notify(ctx context.Context, reminder *domain.Reminder, task *domain.Task) error
+}
+
+// describeReminder says when the task is due, as of the time the reminder
+// is sent. Anything within a minute of the due date reads as "due now".
+func describeReminder(reminder *domain.Reminder, task *domain.Task) string {
+	remaining := task.Duedate.Sub(reminder.SentAt).Round(time.Minute)
+	switch {
+	case remaining > 0:
+		return "due in " + remaining.String()
+	case remaining < 0:
+		return "overdue by " + (-remaining).String()
+	default:
+		return "due now"
+	}
+}
+
+type logNotifier struct {
+	logger *log.Logger
+}
+
+// NewLogNotifier writes reminders to logger, or to the standard logger when
+// logger is nil.
+func NewLogNotifier(logger *log.Logger) INotifier {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, reminder *domain.Reminder, task *domain.Task) error {
+	n.logger.Printf("Reminder for user %s: task %q (%s) is %s (due %s)",
+		reminder.UserID.Hex(), task.Title, task.ID.Hex(), describeReminder(reminder, task), task.Duedate.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// WebhookPayload is the JSON body the webhook notifier posts.
+type WebhookPayload struct {
+	ReminderID string    `json:"reminder_id"`
+	TaskID     string    `json:"task_id"`
+	Title      string    `json:"title"`
+	UserID     string    `json:"user_id"`
+	DueDate    time.Time `json:"due_date"`
+	Offset     string    `json:"offset"`
+	Overdue    bool      `json:"overdue"`
+	Message    string    `json:"message"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier posts each reminder as JSON to url. A nil client gets
+// a default one with a 10 second timeout.
+func NewWebhookNotifier(url string, client *http.Client) INotifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &webhookNotifier{url: url, client: client}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, reminder *domain.Reminder, task *domain.Task) error {
+	body, err := json.Marshal(WebhookPayload{
+		ReminderID: reminder.ID.Hex(),
+		TaskID:     task.ID.Hex(),
+		Title:      task.Title,
+		UserID:     reminder.UserID.Hex(),
+		DueDate:    task.Duedate,
+		Offset:     reminder.Offset.String(),
+		Overdue:    task.Duedate.Before(reminder.SentAt),
+		Message:    fmt.Sprintf("Task %q is %s", task.Title, describeReminder(reminder, task)),
+		SentAt:     reminder.SentAt,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("reminder webhook answered %s", resp.Status)
+	}
+	return nil
+}
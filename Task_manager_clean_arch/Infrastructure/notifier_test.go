@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func testReminder(sentBeforeDue time.Duration) (*domain.Reminder, *domain.Task) {
+	due := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	task := &domain.Task{ID: primitive.NewObjectID(), Title: "File taxes", Duedate: due}
+	reminder := &domain.Reminder{ID: primitive.NewObjectID(), TaskID: task.ID, UserID: primitive.NewObjectID(),
+		DueDate: due, Offset: time.Hour, SentAt: due.Add(-sentBeforeDue)}
+	return reminder, task
+}
+
+func TestLogNotifier(t *testing.T) {
+	var out bytes.Buffer
+	notifier := NewLogNotifier(log.New(&out, "", 0))
+
+	reminder, task := testReminder(time.Hour)
+	assert.NoError(t, notifier.Notify(context.Background(), reminder, task))
+	assert.Contains(t, out.String(), `task "File taxes"`)
+	assert.Contains(t, out.String(), "is due in 1h0m0s")
+
+	out.Reset()
+	reminder, task = testReminder(-90 * time.Minute)
+	assert.NoError(t, notifier.Notify(context.Background(), reminder, task))
+	assert.Contains(t, out.String(), "is overdue by 1h30m0s")
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var received WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	reminder, task := testReminder(0)
+	assert.NoError(t, NewWebhookNotifier(server.URL, nil).Notify(context.Background(), reminder, task))
+	assert.Equal(t, reminder.ID.Hex(), received.ReminderID)
+	assert.Equal(t, task.ID.Hex(), received.TaskID)
+	assert.Equal(t, reminder.UserID.Hex(), received.UserID)
+	assert.Equal(t, "1h0m0s", received.Offset)
+	assert.Equal(t, `Task "File taxes" is due now`, received.Message)
+	assert.False(t, received.Overdue)
+}
+
+func TestWebhookNotifier_FailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	reminder, task := testReminder(0)
+	err := NewWebhookNotifier(server.URL, nil).Notify(context.Background(), reminder, task)
+	assert.ErrorContains(t, err, "502")
+}
@@ -0,0 +1,88 @@
+package infrastructure
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger logs every request like gin's default logger, but without
+// the tickets that stream clients pass in the query string, access tokens
+// that outdated ones still pass there, or the secret tokens in calendar feed
+// URLs.
+func RequestLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactCalendarToken(redactQueryTokens(param.Path)),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// secretQueryParameters are the query parameters that carry credentials.
+var secretQueryParameters = []string{"access_token", "ticket"}
+
+// redactQueryTokens hides the values of the secretQueryParameters of a
+// request path.
+func redactQueryTokens(path string) string {
+	base, rawQuery, found := strings.Cut(path, "?")
+	if !found {
+		return path
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return base + "?[unparsable query]"
+	}
+	redacted := false
+	for _, name := range secretQueryParameters {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return path
+	}
+	return base + "?" + query.Encode()
+}
+
+// calendarFeedPrefix starts the path of a calendar feed, whose last segment
+// is the feed's secret token.
+const calendarFeedPrefix = "/calendar/"
+
+// redactCalendarToken hides the secret token of a calendar feed path, keeping
+// its .ics suffix and query string.
+func redactCalendarToken(path string) string {
+	if !strings.HasPrefix(path, calendarFeedPrefix) {
+		return path
+	}
+	segment, query, _ := strings.Cut(path[len(calendarFeedPrefix):], "?")
+	if segment == "" || strings.Contains(segment, "/") {
+		return path
+	}
+	redacted := calendarFeedPrefix + "REDACTED"
+	if strings.HasSuffix(segment, ".ics") {
+		redacted += ".ics"
+	}
+	if query != "" {
+		redacted += "?" + query
+	}
+	return redacted
+}
@@ -0,0 +1,55 @@
+package infrastructure
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogger_RedactsQueryTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logged bytes.Buffer
+	stdout := gin.DefaultWriter
+	gin.DefaultWriter = &logged
+	defer func() { gin.DefaultWriter = stdout }()
+	router := gin.New()
+	router.Use(RequestLogger())
+	router.GET("/tasks/stream", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req, _ := http.NewRequest(http.MethodGet, "/tasks/stream?access_token=eyJsecret&last_event_id=x-1", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	req, _ = http.NewRequest(http.MethodGet, "/tasks/stream?ticket=eyJticket", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// --- ASSERT ---
+	assert.NotContains(t, logged.String(), "eyJsecret")
+	assert.NotContains(t, logged.String(), "eyJticket")
+	assert.Contains(t, logged.String(), "access_token=REDACTED")
+	assert.Contains(t, logged.String(), "ticket=REDACTED")
+	assert.Contains(t, logged.String(), "last_event_id=x-1")
+	assert.Equal(t, "/tasks?status=done", redactQueryTokens("/tasks?status=done"))
+}
+
+func TestRequestLogger_RedactsCalendarFeedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logged bytes.Buffer
+	stdout := gin.DefaultWriter
+	gin.DefaultWriter = &logged
+	defer func() { gin.DefaultWriter = stdout }()
+	router := gin.New()
+	router.Use(RequestLogger())
+	router.GET("/calendar/:token", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req, _ := http.NewRequest(http.MethodGet, "/calendar/feedsecret123.ics", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// --- ASSERT ---
+	assert.NotContains(t, logged.String(), "feedsecret123")
+	assert.Contains(t, logged.String(), `"/calendar/REDACTED.ics"`)
+	assert.Equal(t, "/calendar/REDACTED?x=1", redactCalendarToken("/calendar/feedsecret123?x=1"))
+	assert.Equal(t, "/me/calendar-feeds", redactCalendarToken("/me/calendar-feeds"))
+}
@@ -0,0 +1,200 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"taskmanager/domain"
+	"time"
+)
+
+// Headers of a webhook delivery request.
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// IWebhookSender makes one attempt at a webhook delivery.
+type IWebhookSender interface {
+	// Send posts the delivery's payload to url, signed with secret, and
+	// returns the response status, or zero when no response came back. A
+	// status outside 2xx is an error.
+	Send(ctx context.Context, url, secret string, delivery *domain.WebhookDelivery) (int, error)
+}
+
+// SignWebhook returns the signature header of a payload sent at timestamp:
+// "sha256=" and the hex HMAC-SHA256, keyed with secret, of the Unix
+// timestamp, a dot and the body. Receivers should compute the same value,
+// compare the two in constant time, and reject old timestamps.
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ErrBlockedAddress is returned when a webhook URL resolves to an address
+// that deliveries may not reach.
+var ErrBlockedAddress = errors.New("webhook address is not allowed")
+
+// blockedNetworks are the special-purpose ranges, beyond loopback, private,
+// link-local, multicast and unspecified addresses, that webhooks may not
+// reach without an allowlist entry.
+var blockedNetworks = mustParseNetworks(
+	"0.0.0.0/8",      // "this network"
+	"100.64.0.0/10",  // carrier-grade NAT
+	"192.0.0.0/24",   // IETF protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"240.0.0.0/4",    // reserved, and the broadcast address
+	"64:ff9b::/96",   // NAT64, which embeds IPv4 addresses
+	"64:ff9b:1::/48", // local-use NAT64
+	"2002::/16",      // 6to4, which embeds IPv4 addresses
+	"2001::/32",      // Teredo
+	"100::/64",       // discard-only
+)
+
+func mustParseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// ParseNetworks reads a comma-separated list of CIDR blocks, such as
+// "10.1.2.0/24, fd00::/8". A bare address stands for itself alone.
+func ParseNetworks(list string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither an address nor a CIDR block", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// addressGuard refuses connections to addresses inside the server's own
+// networks, unless they are in allowed.
+type addressGuard struct {
+	allowed []*net.IPNet
+}
+
+// control runs after a name is resolved and before each connection is made,
+// so a hostname that resolves, or is later rebound, to a blocked address
+// cannot get through.
+func (g addressGuard) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	for _, allowed := range g.allowed {
+		if allowed.Contains(ip) {
+			return nil
+		}
+	}
+	if isInternalAddress(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, ip)
+	}
+	return nil
+}
+
+// isInternalAddress reports whether ip is not a public unicast address.
+func isInternalAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, blocked := range blockedNetworks {
+		if blocked.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type webhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender sends deliveries with a 10 second timeout. Redirects are
+// not followed, so a 3xx answer fails the attempt. Deliveries only connect to
+// public addresses: loopback, private, link-local (cloud metadata included)
+// and other special-purpose addresses fail with ErrBlockedAddress unless they
+// are in one of the allowed networks. Proxy settings from the environment are
+// ignored, as they would hide the address actually reached.
+func NewWebhookSender(allowed []*net.IPNet) IWebhookSender {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   addressGuard{allowed: allowed}.control,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &webhookSender{client: &http.Client{
+		Timeout:       10 * time.Second,
+		Transport:     transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+func (s *webhookSender) Send(ctx context.Context, url, secret string, delivery *domain.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, now, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Read a little of the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,127 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// loopback lets the tests' senders reach httptest servers.
+var loopback = mustParseNetworks("127.0.0.0/8", "::1/128")
+
+func TestSignWebhook(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	signature := SignWebhook("secret", at, []byte(`{"a":1}`))
+
+	// --- ASSERT ---
+	assert.Equal(t, "sha256=", signature[:7])
+	assert.Len(t, signature, 7+64)
+	assert.Equal(t, signature, SignWebhook("secret", at, []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhook("other", at, []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhook("secret", at.Add(time.Second), []byte(`{"a":1}`)))
+}
+
+func TestWebhookSender_SignsPayload(t *testing.T) {
+	delivery := &domain.WebhookDelivery{ID: primitive.NewObjectID(), Event: "task.created", Payload: `{"event":"task.created"}`}
+	var verified bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		unix, err := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+		require.NoError(t, err)
+		expected := SignWebhook("s3cret", time.Unix(unix, 0), body)
+		verified = hmac.Equal([]byte(expected), []byte(r.Header.Get(WebhookSignatureHeader)))
+		assert.Equal(t, "task.created", r.Header.Get(WebhookEventHeader))
+		assert.Equal(t, delivery.ID.Hex(), r.Header.Get(WebhookDeliveryHeader))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	status, err := NewWebhookSender(loopback).Send(context.Background(), server.URL, "s3cret", delivery)
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.True(t, verified, "the signature should verify with the shared secret")
+}
+
+func TestWebhookSender_FailsOnErrorStatusAndRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	sender := NewWebhookSender(loopback)
+	delivery := &domain.WebhookDelivery{ID: primitive.NewObjectID(), Payload: "{}"}
+
+	status, err := sender.Send(context.Background(), server.URL, "s", delivery)
+	redirectStatus, redirectErr := sender.Send(context.Background(), server.URL+"/moved", "s", delivery)
+
+	// --- ASSERT ---
+	assert.ErrorContains(t, err, "503")
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.ErrorContains(t, redirectErr, "302")
+	assert.Equal(t, http.StatusFound, redirectStatus)
+}
+
+func TestWebhookSender_BlocksInternalAddresses(t *testing.T) {
+	var reached bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+	delivery := &domain.WebhookDelivery{ID: primitive.NewObjectID(), Payload: "{}"}
+
+	status, err := NewWebhookSender(nil).Send(context.Background(), server.URL, "s", delivery)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+	assert.Zero(t, status)
+	assert.False(t, reached, "the request must not reach a loopback server")
+
+	guard := addressGuard{}
+	for _, address := range []string{
+		"127.0.0.1", "10.0.0.1", "172.16.5.4", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0",
+		"198.18.0.1", "255.255.255.255", "224.0.0.1", "::1", "::", "fe80::1", "fd00:ec2::254", "::ffff:127.0.0.1",
+		"::ffff:169.254.169.254", "64:ff9b::a9fe:a9fe", "2002:a9fe:a9fe::1",
+	} {
+		assert.ErrorIs(t, guard.control("tcp", net.JoinHostPort(address, "80"), nil), ErrBlockedAddress, address)
+	}
+	for _, address := range []string{"93.184.216.34", "8.8.8.8", "2606:4700:4700::1111"} {
+		assert.NoError(t, guard.control("tcp", net.JoinHostPort(address, "443"), nil), address)
+	}
+}
+
+func TestWebhookSender_AllowedNetworks(t *testing.T) {
+	allowed, err := ParseNetworks(" 10.1.2.0/24, 192.168.7.7,fd12::/16 ")
+	require.NoError(t, err)
+	guard := addressGuard{allowed: allowed}
+
+	_, parseErr := ParseNetworks("10.0.0.0/8,intranet")
+
+	// --- ASSERT ---
+	assert.Len(t, allowed, 3)
+	assert.NoError(t, guard.control("tcp", "10.1.2.3:80", nil))
+	assert.NoError(t, guard.control("tcp", "192.168.7.7:8080", nil))
+	assert.NoError(t, guard.control("tcp", "[fd12::1]:443", nil))
+	assert.ErrorIs(t, guard.control("tcp", "10.1.3.1:80", nil), ErrBlockedAddress)
+	assert.ErrorIs(t, guard.control("tcp", "192.168.7.8:80", nil), ErrBlockedAddress)
+	assert.ErrorIs(t, guard.control("tcp", "169.254.169.254:80", nil), ErrBlockedAddress)
+	assert.ErrorContains(t, parseErr, "intranet")
+}
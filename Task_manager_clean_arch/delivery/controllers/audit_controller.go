@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"taskmanager/delivery/dto"
+	"taskmanager/repositories"
+	"taskmanager/usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IAuditController interface {
+	ListAuditEntries(c *gin.Context)
+}
+
+// parseAuditQuery reads the audit log filters and pagination parameters.
+// IDs are hex ObjectIDs; from (inclusive) and to (exclusive) are RFC 3339.
+func parseAuditQuery(c *gin.Context) (repositories.AuditQuery, error) {
+	query := repositories.AuditQuery{
+		EntityType: c.Query("entity_type"),
+		Action:     c.Query("action"),
+		Cursor:     c.Query("cursor"),
+	}
+
+	ids := map[string]*primitive.ObjectID{
+		"entity_id": &query.EntityID,
+		"actor_id":  &query.ActorID,
+	}
+	for param, target := range ids {
+		if value := c.Query(param); value != "" {
+			id, err := primitive.ObjectIDFromHex(value)
+			if err != nil {
+				return query, errors.New(param + " must be a valid ID")
+			}
+			*target = id
+		}
+	}
+
+	dates := map[string]*time.Time{
+		"from": &query.From,
+		"to":   &query.To,
+	}
+	for param, target := range dates {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, errors.New(param + " must be an RFC 3339 timestamp")
+			}
+			*target = parsed
+		}
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return query, errors.New("limit must be a positive integer")
+		}
+		query.Limit = n
+	}
+	return query, nil
+}
+
+type AuditController struct {
+	auditUsecase usecases.IAuditUsecase
+}
+
+func NewAuditController(auditUsecase usecases.IAuditUsecase) *AuditController {
+	return &AuditController{auditUsecase: auditUsecase}
+}
+
+func (ac *AuditController) ListAuditEntries(c *gin.Context) {
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entries, nextCursor, err := ac.auditUsecase.ListAuditEntries(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidAuditQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, dto.AuditListResponse{Entries: toAuditEntryResponses(entries), NextCursor: nextCursor})
+}
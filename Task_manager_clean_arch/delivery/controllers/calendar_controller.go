@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ICalendarController interface {
+	CreateFeed(c *gin.Context)
+	ListFeeds(c *gin.Context)
+	RevokeFeed(c *gin.Context)
+	GetFeed(c *gin.Context)
+}
+
+type CalendarController struct {
+	calendarUsecase usecases.ICalendarUsecase
+}
+
+func NewCalendarController(calendarUsecase usecases.ICalendarUsecase) *CalendarController {
+	return &CalendarController{calendarUsecase: calendarUsecase}
+}
+
+func toCalendarFeedResponse(feed *domain.CalendarFeed) dto.CalendarFeedResponse {
+	return dto.CalendarFeedResponse{ID: feed.ID.Hex(), Name: feed.Name, CreatedAt: feed.CreatedAt}
+}
+
+// feedURL is the address of a feed on the host the request was sent to.
+// X-Forwarded-Proto is trusted so that a TLS-terminating proxy gets https
+// URLs.
+func feedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host + "/calendar/" + token + ".ics"
+}
+
+func (cc *CalendarController) CreateFeed(c *gin.Context) {
+	var input dto.CalendarFeedRequest
+	// The body is optional.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+			return
+		}
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	feed, token, err := cc.calendarUsecase.CreateFeed(c.Request.Context(), input.Name, userID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidCalendarFeed) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create calendar feed"})
+		return
+	}
+	response := toCalendarFeedResponse(feed)
+	response.URL = feedURL(c, token)
+	c.JSON(http.StatusCreated, response)
+}
+
+func (cc *CalendarController) ListFeeds(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	feeds, err := cc.calendarUsecase.ListFeeds(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve calendar feeds"})
+		return
+	}
+	response := dto.CalendarFeedListResponse{Feeds: make([]dto.CalendarFeedResponse, len(feeds))}
+	for i := range feeds {
+		response.Feeds[i] = toCalendarFeedResponse(&feeds[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (cc *CalendarController) RevokeFeed(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err := cc.calendarUsecase.RevokeFeed(c.Request.Context(), c.Param("id"), userID); err != nil {
+		if errors.Is(err, usecases.ErrCalendarFeedNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke calendar feed"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetFeed serves the tasks with a due date as an iCalendar file, as events
+// by default or as to-dos with component=todo. The route authenticates with
+// the feed token instead of a JWT.
+func (cc *CalendarController) GetFeed(c *gin.Context) {
+	var events bool
+	switch c.DefaultQuery("component", "event") {
+	case "event":
+		events = true
+	case "todo":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "component must be event or todo"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	c.Header("Content-Type", formatMediaTypes[formatICS]+"; charset=utf-8")
+	// The URL is a secret, so neither it nor the tasks belong in shared caches.
+	c.Header("Cache-Control", "private, max-age=300")
+	encoder := newICSFeedWriter(c.Writer, "Tasks", events)
+	err := cc.calendarUsecase.FeedTasks(c.Request.Context(), userID, encoder.Encode)
+	if err == nil {
+		err = encoder.Close()
+	}
+	if err == nil {
+		return
+	}
+	_ = c.Error(err)
+	if !c.Writer.Written() {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render calendar feed"})
+	}
+}
@@ -0,0 +1,77 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"taskmanager/delivery/controllers"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/usecases"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarController_FeedUntilRevoked(t *testing.T) {
+	f := newFixture(t)
+	taskUsecase := f.newTaskUsecase()
+	calendarUsecase := usecases.NewCalendarUsecase(f.repos.CalendarFeeds, f.repos.Users, taskUsecase)
+	calendar := controllers.NewCalendarController(calendarUsecase)
+	ctx := context.Background()
+	userID := f.user
+	due := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	deadline, err := taskUsecase.CreateTask(ctx, &domain.Task{Title: "File taxes", Status: usecases.StatusPending, Duedate: due}, userID)
+	require.NoError(t, err)
+	_, err = taskUsecase.CreateTask(ctx, &domain.Task{Title: "Ship it", Status: usecases.StatusCompleted, Duedate: due.AddDate(0, 0, 1)}, userID)
+	require.NoError(t, err)
+	_, err = taskUsecase.CreateTask(ctx, &domain.Task{Title: "Someday", Status: usecases.StatusPending}, userID)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/calendar/:token", infrastructure.FeedTokenMiddleware(calendarUsecase), calendar.GetFeed)
+	me := router.Group("/me", func(c *gin.Context) { c.Set("user_id", userID.Hex()) })
+	me.GET("/calendar-feeds", calendar.ListFeeds)
+	me.POST("/calendar-feeds", calendar.CreateFeed)
+	me.DELETE("/calendar-feeds/:id", calendar.RevokeFeed)
+
+	w := serve(router, http.MethodPost, "/me/calendar-feeds", `{"name": "Phone"}`, map[string]string{"X-Forwarded-Proto": "https"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var feed struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &feed))
+	require.True(t, strings.HasPrefix(feed.URL, "https://example.com/calendar/"), feed.URL)
+	feedURL, err := url.Parse(feed.URL)
+	require.NoError(t, err)
+	w = serve(router, http.MethodGet, "/me/calendar-feeds", "", nil)
+	assert.NotContains(t, w.Body.String(), feedURL.Path, "the token is only shown once")
+
+	w = serve(router, http.MethodGet, feedURL.Path, "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/calendar")
+	body := w.Body.String()
+	assert.Equal(t, 2, strings.Count(body, "BEGIN:VEVENT"))
+	assert.Contains(t, body, "UID:"+deadline.ID.Hex()+"\r\n")
+	assert.Contains(t, body, "DTSTART:20260301T093000Z\r\n")
+	assert.Contains(t, body, "SUMMARY:✓ Ship it\r\n")
+	assert.NotContains(t, body, "Someday")
+	w = serve(router, http.MethodGet, feedURL.Path+"?component=todo", "", nil)
+	assert.Contains(t, w.Body.String(), "STATUS:COMPLETED\r\n")
+	assert.Contains(t, w.Body.String(), "DUE:20260301T093000Z\r\n")
+
+	w = serve(router, http.MethodDelete, "/me/calendar-feeds/"+feed.ID, "", nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	// --- ASSERT ---
+	w = serve(router, http.MethodGet, feedURL.Path, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serve(router, http.MethodDelete, "/me/calendar-feeds/"+feed.ID, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,86 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"taskmanager/domain"
+	"taskmanager/mocks"
+	"taskmanager/repositories"
+	"taskmanager/usecases"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fixture is what the controller tests build on: in-memory repositories with
+// the built-in roles, one user holding each of them, and a router that
+// serves requests as the user named in the X-User header. Options add what a
+// test needs on top.
+type fixture struct {
+	repos   *repositories.Repositories
+	roles   usecases.IRoleUsecase
+	router  *gin.Engine
+	admin   primitive.ObjectID
+	manager primitive.ObjectID
+	user    primitive.ObjectID
+
+	// Set by withStream.
+	stream usecases.ITaskStream
+	tasks  usecases.ITaskUsecase
+	server *httptest.Server
+}
+
+// fixtureOption adds to the fixture. Options run in order, after the
+// defaults are in place.
+type fixtureOption func(t *testing.T, f *fixture)
+
+func newFixture(t *testing.T, opts ...fixtureOption) *fixture {
+	gin.SetMode(gin.TestMode)
+	f := &fixture{repos: repositories.NewMemoryRepositories(), router: gin.New()}
+	f.roles = usecases.NewRoleUsecase(f.repos.Roles, f.repos.Users, f.repos.Audit)
+	require.NoError(t, f.roles.EnsureBuiltInRoles(context.Background()))
+	f.admin = f.addUser(t, domain.RoleAdmin, domain.RoleAdmin)
+	f.manager = f.addUser(t, domain.RoleManager, domain.RoleManager)
+	f.user = f.addUser(t, domain.RoleUser)
+	f.router.Use(func(c *gin.Context) { c.Set("user_id", c.GetHeader("X-User")) })
+	for _, opt := range opts {
+		opt(t, f)
+	}
+	return f
+}
+
+// addUser stores a user with the password "pw" in name only, and the user
+// role unless other roles are given.
+func (f *fixture) addUser(t *testing.T, username string, roles ...string) primitive.ObjectID {
+	if len(roles) == 0 {
+		roles = []string{domain.RoleUser}
+	}
+	user := &domain.User{Username: username, Password: "pw", Roles: roles}
+	require.NoError(t, f.repos.Users.Create(context.Background(), user))
+	return user.ID
+}
+
+func (f *fixture) newTaskUsecase(opts ...usecases.TaskUsecaseOption) usecases.ITaskUsecase {
+	return usecases.NewTaskUsecase(f.repos.Tasks, f.repos.Users, f.repos.Audit, f.repos.Tags, f.repos.Projects, opts...)
+}
+
+// newFakePasswordService hashes a password by prefixing it, so tests that
+// log in do not spend their time in bcrypt.
+func newFakePasswordService() *mocks.IPasswordService {
+	passwords := new(mocks.IPasswordService)
+	passwords.On("HashPassword", mock.Anything).Return(func(password string) (string, error) {
+		return "hashed:" + password, nil
+	})
+	passwords.On("CheckPasswordHash", mock.Anything, mock.Anything).Return(func(password, hash string) bool {
+		return hash == "hashed:"+password
+	})
+	return passwords
+}
+
+// as returns the headers of a request made by userID.
+func as(userID primitive.ObjectID) map[string]string {
+	return map[string]string{"X-User": userID.Hex()}
+}
@@ -0,0 +1,440 @@
+package controllers
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/usecases"
+	"time"
+)
+
+// iCalendar (RFC 5545) support for task import and export. Tasks are VTODO
+// components; the exact status goes in X-TASKMANAGER-STATUS, since the
+// standard STATUS values cannot hold every workflow status.
+
+const (
+	icsProductID   = "-//taskmanager//Task Manager//EN"
+	icsStatusProp  = "X-TASKMANAGER-STATUS"
+	icsDateTimeUTC = "20060102T150405Z"
+	icsDateTime    = "20060102T150405"
+	icsDate        = "20060102"
+	// icsLineOctets is the longest a content line may be before folding.
+	icsLineOctets = 75
+)
+
+// icsStatus maps a task status to the nearest VTODO STATUS.
+func icsStatus(status string) string {
+	switch {
+	case strings.EqualFold(status, usecases.StatusCompleted):
+		return "COMPLETED"
+	case strings.EqualFold(status, usecases.StatusInProgress):
+		return "IN-PROCESS"
+	default:
+		return "NEEDS-ACTION"
+	}
+}
+
+// taskStatusOfICS maps a VTODO STATUS back to a task status.
+func taskStatusOfICS(status string) string {
+	switch strings.ToUpper(status) {
+	case "COMPLETED":
+		return usecases.StatusCompleted
+	case "IN-PROCESS":
+		return usecases.StatusInProgress
+	default:
+		return usecases.StatusPending
+	}
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsText escapes a TEXT value.
+func icsText(value string) string {
+	return icsEscaper.Replace(value)
+}
+
+// icsUnescape undoes icsText.
+func icsUnescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// splitICSList splits a list value on the commas that are not escaped, and
+// unescapes the items.
+func splitICSList(value string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ',':
+			items = append(items, icsUnescape(value[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, icsUnescape(value[start:]))
+}
+
+// icsWriter writes tasks as the VTODOs of one VCALENDAR, or as VEVENTs for
+// calendar apps that do not show to-dos.
+type icsWriter struct {
+	writer *bufio.Writer
+	stamp  time.Time
+	events bool
+}
+
+func newICSWriter(w io.Writer) *icsWriter {
+	return startICS(w, false)
+}
+
+// newICSFeedWriter starts a calendar to subscribe to: it has a name, and
+// asks clients to check it for changes every hour.
+func newICSFeedWriter(w io.Writer, name string, events bool) *icsWriter {
+	e := startICS(w, events)
+	e.line("X-WR-CALNAME:" + icsText(name))
+	e.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	e.line("X-PUBLISHED-TTL:PT1H")
+	return e
+}
+
+func startICS(w io.Writer, events bool) *icsWriter {
+	e := &icsWriter{writer: bufio.NewWriter(w), stamp: time.Now().UTC(), events: events}
+	e.line("BEGIN:VCALENDAR")
+	e.line("VERSION:2.0")
+	e.line("PRODID:" + icsProductID)
+	e.line("CALSCALE:GREGORIAN")
+	return e
+}
+
+// line writes a content line, folded so that no line is longer than
+// icsLineOctets without splitting a UTF-8 sequence. Write errors stick to the
+// buffer and come out of Encode or Close.
+func (e *icsWriter) line(content string) {
+	limit := icsLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		_, _ = e.writer.WriteString(content[:cut])
+		_, _ = e.writer.WriteString("\r\n ")
+		content = content[cut:]
+		// The leading space of a continuation line counts towards its length.
+		limit = icsLineOctets - 1
+	}
+	_, _ = e.writer.WriteString(content)
+	_, _ = e.writer.WriteString("\r\n")
+}
+
+func (e *icsWriter) Encode(tasks []domain.Task) error {
+	for i := range tasks {
+		e.component(&tasks[i])
+	}
+	// An empty write reports the error the buffer has stuck at, if any.
+	_, err := e.writer.Write(nil)
+	return err
+}
+
+// component writes one task. The UID is the task's export ID, which stays the
+// same as long as the task exists.
+func (e *icsWriter) component(task *domain.Task) {
+	kind, dateProp, summary := "VTODO", "DUE", task.Title
+	if e.events {
+		kind, dateProp = "VEVENT", "DTSTART"
+		// Events have no status for work that is done, so the title shows it.
+		if icsStatus(task.Status) == "COMPLETED" {
+			summary = "✓ " + summary
+		}
+	}
+	e.line("BEGIN:" + kind)
+	e.line("UID:" + icsText(exportID(task)))
+	e.line("DTSTAMP:" + e.stamp.Format(icsDateTimeUTC))
+	if !task.CreatedAt.IsZero() {
+		e.line("CREATED:" + task.CreatedAt.UTC().Format(icsDateTimeUTC))
+	}
+	e.line("SUMMARY:" + icsText(summary))
+	if task.Description != "" {
+		e.line("DESCRIPTION:" + icsText(task.Description))
+	}
+	if !task.Duedate.IsZero() {
+		e.line(icsDateProperty(dateProp, task))
+	}
+	if e.events {
+		// A deadline does not make anyone busy.
+		e.line("TRANSP:TRANSPARENT")
+	} else {
+		e.line("STATUS:" + icsStatus(task.Status))
+	}
+	if len(task.Tags) > 0 {
+		tags := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			tags[i] = icsText(tag)
+		}
+		e.line("CATEGORIES:" + strings.Join(tags, ","))
+	}
+	if task.Recurrence != nil && task.Recurrence.Rule != "" {
+		e.line("RRULE:" + task.Recurrence.Rule)
+	}
+	e.line(icsStatusProp + ":" + icsText(task.Status))
+	e.line("END:" + kind)
+}
+
+// icsDateProperty renders the due date as the named property, in the time
+// zone of a recurring task so that clients expand the rule as the server
+// does, and in UTC otherwise.
+func icsDateProperty(name string, task *domain.Task) string {
+	if r := task.Recurrence; r != nil && r.TimeZone != "" && r.TimeZone != "UTC" {
+		if loc, err := time.LoadLocation(r.TimeZone); err == nil {
+			return name + ";TZID=" + r.TimeZone + ":" + task.Duedate.In(loc).Format(icsDateTime)
+		}
+	}
+	return name + ":" + task.Duedate.UTC().Format(icsDateTimeUTC)
+}
+
+func (e *icsWriter) Close() error {
+	e.line("END:VCALENDAR")
+	return e.writer.Flush()
+}
+
+// icsContentLine is a property of an iCalendar component, such as
+// DUE;TZID=Europe/Paris:20250101T090000.
+type icsContentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseICSContentLine splits a content line into its name, parameters and
+// value. Parameter values may be quoted, and quotes may hide ';' and ':'.
+func parseICSContentLine(line string) (icsContentLine, error) {
+	var parsed icsContentLine
+	quoted := false
+	colon := -1
+	for i := 0; i < len(line) && colon < 0; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ':':
+			if !quoted {
+				colon = i
+			}
+		}
+	}
+	if colon < 0 {
+		return parsed, errors.New("content line without a value: " + line)
+	}
+	parsed.value = line[colon+1:]
+
+	var parts []string
+	start := 0
+	quoted = false
+	for i := 0; i < colon; i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				parts = append(parts, line[start:i])
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, line[start:colon])
+
+	parsed.name = strings.ToUpper(parts[0])
+	parsed.params = map[string]string{}
+	for _, param := range parts[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		parsed.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return parsed, nil
+}
+
+// parseICSDue reads a DUE value: a date, a UTC time, a time in the zone
+// named by TZID, or a floating time, which is taken as UTC. The time comes
+// back in UTC, with the zone, if any, beside it.
+func parseICSDue(property icsContentLine) (time.Time, string, error) {
+	value := property.value
+	if property.params["VALUE"] == "DATE" || len(value) == len(icsDate) {
+		due, err := time.Parse(icsDate, value)
+		return due, "", err
+	}
+	if strings.HasSuffix(value, "Z") {
+		due, err := time.Parse(icsDateTimeUTC, value)
+		return due, "", err
+	}
+	zone := property.params["TZID"]
+	if zone == "" {
+		due, err := time.Parse(icsDateTime, value)
+		return due, "", err
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	due, err := time.ParseInLocation(icsDateTime, value, loc)
+	return due.UTC(), zone, err
+}
+
+// icsImportReader reads the VTODOs of an iCalendar file one at a time;
+// other components are skipped. Rows are numbered from 1.
+type icsImportReader struct {
+	lines *bufio.Scanner
+	// ahead is the line read past the end of the last content line, which
+	// is only over once the next line is not a continuation.
+	ahead    string
+	hasAhead bool
+	row      int
+	ended    bool
+}
+
+// icsMaxLineBytes bounds one physical line of an imported file.
+const icsMaxLineBytes = 1 << 20
+
+func newICSImportReader(r io.Reader) (*icsImportReader, error) {
+	lines := bufio.NewScanner(r)
+	lines.Buffer(make([]byte, 0, 64*1024), icsMaxLineBytes)
+	reader := &icsImportReader{lines: lines}
+	first, err := reader.contentLine()
+	if err == io.EOF || err == nil && (first.name != "BEGIN" || !strings.EqualFold(first.value, "VCALENDAR")) {
+		return nil, errors.New("the file must start with BEGIN:VCALENDAR")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// physicalLine returns the next line of the file without its line ending.
+func (r *icsImportReader) physicalLine() (string, bool, error) {
+	if r.hasAhead {
+		r.hasAhead = false
+		return r.ahead, true, nil
+	}
+	if !r.lines.Scan() {
+		return "", false, r.lines.Err()
+	}
+	return strings.TrimSuffix(r.lines.Text(), "\r"), true, nil
+}
+
+// contentLine returns the next unfolded content line, skipping blank lines.
+func (r *icsImportReader) contentLine() (icsContentLine, error) {
+	var line string
+	for line == "" {
+		next, ok, err := r.physicalLine()
+		if err != nil {
+			return icsContentLine{}, err
+		}
+		if !ok {
+			return icsContentLine{}, io.EOF
+		}
+		line = next
+	}
+	for {
+		next, ok, err := r.physicalLine()
+		if err != nil {
+			return icsContentLine{}, err
+		}
+		if !ok {
+			break
+		}
+		if next == "" || next[0] != ' ' && next[0] != '\t' {
+			r.ahead, r.hasAhead = next, true
+			break
+		}
+		line += next[1:]
+	}
+	return parseICSContentLine(line)
+}
+
+func (r *icsImportReader) Read() (*usecases.ImportRow, error) {
+	for !r.ended {
+		property, err := r.contentLine()
+		if err == io.EOF {
+			return nil, errors.New("the file ends before END:VCALENDAR")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case property.name == "BEGIN" && strings.EqualFold(property.value, "VTODO"):
+			return r.readTodo()
+		case property.name == "END" && strings.EqualFold(property.value, "VCALENDAR"):
+			r.ended = true
+		}
+	}
+	return nil, io.EOF
+}
+
+// readTodo reads the properties of a VTODO up to its END line. Components
+// nested in it, such as alarms, are skipped.
+func (r *icsImportReader) readTodo() (*usecases.ImportRow, error) {
+	r.row++
+	input := dto.TaskImportRow{TaskRequest: dto.TaskRequest{Status: usecases.StatusPending}}
+	var rowErr error
+	exactStatus := ""
+	nested := 0
+	for {
+		property, err := r.contentLine()
+		if err == io.EOF {
+			return nil, errors.New("the file ends inside a VTODO")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case property.name == "BEGIN":
+			nested++
+		case property.name == "END" && nested > 0:
+			nested--
+		case property.name == "END":
+			if exactStatus != "" {
+				input.Status = exactStatus
+			}
+			if rowErr != nil {
+				return &usecases.ImportRow{Row: r.row, ExternalID: input.ExternalID, Err: rowErr}, nil
+			}
+			return toImportRow(r.row, &input), nil
+		case nested > 0:
+		case property.name == "UID":
+			input.ExternalID = icsUnescape(property.value)
+		case property.name == "SUMMARY":
+			input.Title = icsUnescape(property.value)
+		case property.name == "DESCRIPTION":
+			input.Description = icsUnescape(property.value)
+		case property.name == "DUE":
+			input.DueDate, input.TimeZone, err = parseICSDue(property)
+			if err != nil {
+				rowErr = rowError("DUE must be an iCalendar date or date-time")
+			}
+		case property.name == "STATUS":
+			input.Status = taskStatusOfICS(property.value)
+		case property.name == icsStatusProp:
+			exactStatus = icsUnescape(property.value)
+		case property.name == "CATEGORIES":
+			input.Tags = append(input.Tags, splitICSList(property.value)...)
+		case property.name == "RRULE":
+			input.Recurrence = property.value
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IProjectController interface {
+	CreateProject(c *gin.Context)
+	ListProjects(c *gin.Context)
+	GetProject(c *gin.Context)
+	UpdateProject(c *gin.Context)
+	SetMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+	ArchiveProject(c *gin.Context)
+	UnarchiveProject(c *gin.Context)
+}
+
+type ProjectController struct {
+	projectUsecase usecases.IProjectUsecase
+}
+
+func NewProjectController(projectUsecase usecases.IProjectUsecase) *ProjectController {
+	return &ProjectController{projectUsecase: projectUsecase}
+}
+
+func toProjectResponse(project *domain.Project) dto.ProjectResponse {
+	response := dto.ProjectResponse{
+		ID:          project.ID.Hex(),
+		Name:        project.Name,
+		Description: project.Description,
+		Members:     make([]dto.ProjectMemberResponse, len(project.Members)),
+		CreatedAt:   project.CreatedAt,
+	}
+	for i, m := range project.Members {
+		response.Members[i] = dto.ProjectMemberResponse{UserID: m.UserID.Hex(), Role: m.Role}
+	}
+	if !project.ArchivedAt.IsZero() {
+		archivedAt := project.ArchivedAt
+		response.ArchivedAt = &archivedAt
+	}
+	return response
+}
+
+// respondProjectError maps a project usecase error to its HTTP status.
+func respondProjectError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidProject), errors.Is(err, usecases.ErrInvalidProjectMember),
+		errors.Is(err, usecases.ErrUserNotFound):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrProjectArchived):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (pc *ProjectController) CreateProject(c *gin.Context) {
+	var input dto.ProjectRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	project, err := pc.projectUsecase.CreateProject(c.Request.Context(), input.Name, input.Description, userID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to create project")
+		return
+	}
+	c.JSON(http.StatusCreated, toProjectResponse(project))
+}
+
+// ListProjects lists the caller's projects; archived=true includes archived ones.
+func (pc *ProjectController) ListProjects(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	includeArchived := c.Query("archived") == "true"
+	projects, err := pc.projectUsecase.ListProjects(c.Request.Context(), includeArchived, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve projects"})
+		return
+	}
+	response := dto.ProjectListResponse{Projects: make([]dto.ProjectResponse, len(projects))}
+	for i := range projects {
+		response.Projects[i] = toProjectResponse(&projects[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (pc *ProjectController) GetProject(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	project, err := pc.projectUsecase.GetProject(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to retrieve project")
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+func (pc *ProjectController) UpdateProject(c *gin.Context) {
+	var input dto.ProjectUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	update := usecases.ProjectUpdate{Name: input.Name, Description: input.Description}
+	project, err := pc.projectUsecase.UpdateProject(c.Request.Context(), c.Param("id"), update, userID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to update project")
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+func (pc *ProjectController) SetMember(c *gin.Context) {
+	var input dto.ProjectMemberRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	memberID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	project, err := pc.projectUsecase.SetMember(c.Request.Context(), c.Param("id"), memberID, input.Role, userID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to update project members")
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+func (pc *ProjectController) RemoveMember(c *gin.Context) {
+	memberID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	project, err := pc.projectUsecase.RemoveMember(c.Request.Context(), c.Param("id"), memberID, userID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to update project members")
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+func (pc *ProjectController) ArchiveProject(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	project, err := pc.projectUsecase.ArchiveProject(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to archive project")
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
+
+func (pc *ProjectController) UnarchiveProject(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	project, err := pc.projectUsecase.UnarchiveProject(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondProjectError(c, err, "Failed to unarchive project")
+		return
+	}
+	c.JSON(http.StatusOK, toProjectResponse(project))
+}
@@ -0,0 +1,63 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"taskmanager/delivery/controllers"
+	"taskmanager/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectController_SharedBacklog(t *testing.T) {
+	f := newFixture(t)
+	ownerID, memberID := f.addUser(t, "owner"), f.addUser(t, "member")
+	tasks := controllers.NewTaskController(f.newTaskUsecase())
+	projects := controllers.NewProjectController(usecases.NewProjectUsecase(f.repos.Projects, f.repos.Tasks, f.repos.Users))
+	f.router.POST("/projects", projects.CreateProject)
+	f.router.PUT("/projects/:id/members/:userId", projects.SetMember)
+	f.router.POST("/projects/:id/archive", projects.ArchiveProject)
+	f.router.GET("/projects/:id/tasks", tasks.ListProjectTasks)
+	f.router.POST("/projects/:id/tasks", tasks.CreateProjectTask)
+	owner, member := as(ownerID), as(memberID)
+
+	w := serve(f.router, http.MethodPost, "/projects", `{"name":"Website"}`, owner)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var project struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &project))
+	path := "/projects/" + project.ID
+
+	w = serve(f.router, http.MethodGet, path+"/tasks", "", member)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = serve(f.router, http.MethodPut, path+"/members/"+memberID.Hex(), `{"role":"editor"}`, owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = serve(f.router, http.MethodPut, path+"/members/"+ownerID.Hex(), `{"role":"viewer"}`, owner)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = serve(f.router, http.MethodPost, path+"/tasks", `{"title":"Landing page","status":"pending"}`, member)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"project_id":"`+project.ID+`"`)
+
+	w = serve(f.router, http.MethodPost, path+"/archive", "", member)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(f.router, http.MethodPost, path+"/archive", "", owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = serve(f.router, http.MethodPost, path+"/tasks", `{"title":"Late","status":"pending"}`, member)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// --- ASSERT ---
+	w = serve(f.router, http.MethodGet, path+"/tasks", "", owner)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed struct {
+		Tasks []map[string]interface{} `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Tasks, 1)
+	assert.Equal(t, "Landing page", listed.Tasks[0]["title"])
+	assert.Equal(t, memberID.Hex(), listed.Tasks[0]["user_id"])
+	assert.NotEmpty(t, listed.Tasks[0]["archived_at"])
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"taskmanager/delivery/dto"
+	"taskmanager/usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IReminderController interface {
+	GetReminderSettings(c *gin.Context)
+	UpdateReminderSettings(c *gin.Context)
+	ResetReminderSettings(c *gin.Context)
+}
+
+type ReminderController struct {
+	reminderUsecase usecases.IReminderUsecase
+}
+
+func NewReminderController(reminderUsecase usecases.IReminderUsecase) *ReminderController {
+	return &ReminderController{reminderUsecase: reminderUsecase}
+}
+
+func toReminderSettingsResponse(offsets []time.Duration, isDefault bool) dto.ReminderSettingsResponse {
+	formatted := make([]string, len(offsets))
+	for i, offset := range offsets {
+		formatted[i] = offset.String()
+	}
+	return dto.ReminderSettingsResponse{Offsets: formatted, Default: isDefault}
+}
+
+func (rc *ReminderController) GetReminderSettings(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	offsets, isDefault, err := rc.reminderUsecase.GetReminderOffsets(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reminder settings"})
+		return
+	}
+	c.JSON(http.StatusOK, toReminderSettingsResponse(offsets, isDefault))
+}
+
+func (rc *ReminderController) UpdateReminderSettings(c *gin.Context) {
+	var input dto.ReminderSettingsRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	offsets := make([]time.Duration, len(input.Offsets))
+	for i, raw := range input.Offsets {
+		offset, err := time.ParseDuration(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offsets must be durations such as 24h or 30m"})
+			return
+		}
+		offsets[i] = offset
+	}
+	rc.saveReminderOffsets(c, offsets)
+}
+
+func (rc *ReminderController) ResetReminderSettings(c *gin.Context) {
+	rc.saveReminderOffsets(c, nil)
+}
+
+func (rc *ReminderController) saveReminderOffsets(c *gin.Context, offsets []time.Duration) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	saved, err := rc.reminderUsecase.SetReminderOffsets(c.Request.Context(), userID, offsets)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidReminderOffsets) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save reminder settings"})
+		return
+	}
+	c.JSON(http.StatusOK, toReminderSettingsResponse(saved, offsets == nil))
+}
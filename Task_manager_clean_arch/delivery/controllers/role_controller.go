@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IRoleController interface {
+	ListPermissions(c *gin.Context)
+	ListRoles(c *gin.Context)
+	CreateRole(c *gin.Context)
+	GetRole(c *gin.Context)
+	UpdateRole(c *gin.Context)
+	DeleteRole(c *gin.Context)
+	SetUserRoles(c *gin.Context)
+}
+
+type RoleController struct {
+	roleUsecase usecases.IRoleUsecase
+}
+
+func NewRoleController(roleUsecase usecases.IRoleUsecase) *RoleController {
+	return &RoleController{roleUsecase: roleUsecase}
+}
+
+func toRoleResponse(role *domain.Role) dto.RoleResponse {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return dto.RoleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		BuiltIn:     role.BuiltIn,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// respondRoleError maps a role usecase error to its HTTP status.
+func respondRoleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidRole):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrRoleExists), errors.Is(err, usecases.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrRoleNotFound), errors.Is(err, usecases.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (rc *RoleController) ListPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.PermissionListResponse{Permissions: usecases.KnownPermissions()})
+}
+
+func (rc *RoleController) ListRoles(c *gin.Context) {
+	roles, err := rc.roleUsecase.ListRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve roles"})
+		return
+	}
+	response := dto.RoleListResponse{Roles: make([]dto.RoleResponse, len(roles))}
+	for i := range roles {
+		response.Roles[i] = toRoleResponse(&roles[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (rc *RoleController) CreateRole(c *gin.Context) {
+	var input dto.RoleRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	role := &domain.Role{Name: input.Name, Description: input.Description, Permissions: input.Permissions}
+	created, err := rc.roleUsecase.CreateRole(c.Request.Context(), role, userID)
+	if err != nil {
+		respondRoleError(c, err, "Failed to create role")
+		return
+	}
+	c.JSON(http.StatusCreated, toRoleResponse(created))
+}
+
+func (rc *RoleController) GetRole(c *gin.Context) {
+	role, err := rc.roleUsecase.GetRole(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		respondRoleError(c, err, "Failed to retrieve role")
+		return
+	}
+	c.JSON(http.StatusOK, toRoleResponse(role))
+}
+
+func (rc *RoleController) UpdateRole(c *gin.Context) {
+	var input dto.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	update := &usecases.RoleUpdate{Description: input.Description, Permissions: input.Permissions}
+	role, err := rc.roleUsecase.UpdateRole(c.Request.Context(), c.Param("name"), update, userID)
+	if err != nil {
+		respondRoleError(c, err, "Failed to update role")
+		return
+	}
+	c.JSON(http.StatusOK, toRoleResponse(role))
+}
+
+func (rc *RoleController) DeleteRole(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err := rc.roleUsecase.DeleteRole(c.Request.Context(), c.Param("name"), userID); err != nil {
+		respondRoleError(c, err, "Failed to delete role")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetUserRoles replaces the roles of the user in the path.
+func (rc *RoleController) SetUserRoles(c *gin.Context) {
+	var input dto.UserRolesRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	user, err := rc.roleUsecase.SetUserRoles(c.Request.Context(), c.Param("id"), input.Roles, userID)
+	if err != nil {
+		respondRoleError(c, err, "Failed to update user roles")
+		return
+	}
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
@@ -0,0 +1,75 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"taskmanager/delivery/controllers"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleController_ManageRoles(t *testing.T) {
+	f := newFixture(t)
+	roleUsecase := f.roles
+	roles := controllers.NewRoleController(roleUsecase)
+	router := f.router
+	manage := router.Group("/admin", infrastructure.RequirePermission(roleUsecase, domain.PermRoleManage))
+	manage.GET("/permissions", roles.ListPermissions)
+	manage.GET("/roles", roles.ListRoles)
+	manage.POST("/roles", roles.CreateRole)
+	manage.GET("/roles/:name", roles.GetRole)
+	manage.PATCH("/roles/:name", roles.UpdateRole)
+	manage.DELETE("/roles/:name", roles.DeleteRole)
+	router.PUT("/admin/users/:id/roles", infrastructure.RequirePermission(roleUsecase, domain.PermUserPromote), roles.SetUserRoles)
+	admin, manager := as(f.admin), as(f.manager)
+
+	w := serve(router, http.MethodGet, "/admin/roles", "", manager)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(router, http.MethodPut, "/admin/users/"+f.user.Hex()+"/roles", `{"roles":["admin"]}`, manager)
+	assert.Equal(t, http.StatusForbidden, w.Code, "managers cannot promote users")
+
+	w = serve(router, http.MethodPost, "/admin/roles", `{"name":"auditor","permissions":["audit:read"]}`, admin)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = serve(router, http.MethodPost, "/admin/roles", `{"name":"auditor"}`, admin)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = serve(router, http.MethodPost, "/admin/roles", `{"name":"wizard","permissions":["task:levitate"]}`, admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = serve(router, http.MethodPatch, "/admin/roles/auditor", `{"description":"Reads the audit log"}`, admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = serve(router, http.MethodPut, "/admin/users/"+f.manager.Hex()+"/roles", `{"roles":["manager","auditor"]}`, admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var user struct {
+		Roles []string `json:"roles"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &user))
+
+	// --- ASSERT ---
+	assert.Equal(t, []string{"manager", "auditor"}, user.Roles)
+	w = serve(router, http.MethodGet, "/admin/roles", "", admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Roles []struct {
+			Name        string   `json:"name"`
+			Description string   `json:"description"`
+			Permissions []string `json:"permissions"`
+			BuiltIn     bool     `json:"built_in"`
+		} `json:"roles"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Roles, 4)
+	assert.Equal(t, "Reads the audit log", list.Roles[1].Description)
+	assert.Equal(t, []string{"audit:read"}, list.Roles[1].Permissions)
+	assert.False(t, list.Roles[1].BuiltIn)
+	w = serve(router, http.MethodGet, "/admin/permissions", "", admin)
+	assert.Contains(t, w.Body.String(), `"task:update:any"`)
+	w = serve(router, http.MethodDelete, "/admin/roles/auditor", "", admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code, "the role is still in use")
+	w = serve(router, http.MethodDelete, "/admin/roles/user", "", admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = serve(router, http.MethodGet, "/admin/roles/ghost", "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
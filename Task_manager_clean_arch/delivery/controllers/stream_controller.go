@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"taskmanager/delivery/dto"
+	"taskmanager/infrastructure"
+	"taskmanager/usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/websocket"
+)
+
+type IStreamController interface {
+	IssueTicket(c *gin.Context)
+	StreamTasks(c *gin.Context)
+	StreamTasksWebSocket(c *gin.Context)
+}
+
+// DefaultStreamHeartbeat is how often an idle task stream sends a heartbeat,
+// so that proxies keep it open and clients notice a dead connection.
+const DefaultStreamHeartbeat = 15 * time.Second
+
+type StreamController struct {
+	stream    usecases.ITaskStream
+	tickets   infrastructure.IJWTService
+	heartbeat time.Duration
+}
+
+// StreamControllerOption customizes a StreamController at construction time.
+type StreamControllerOption func(*StreamController)
+
+// WithStreamHeartbeat sets how often idle streams send a heartbeat.
+func WithStreamHeartbeat(interval time.Duration) StreamControllerOption {
+	return func(sc *StreamController) { sc.heartbeat = interval }
+}
+
+func NewStreamController(stream usecases.ITaskStream, tickets infrastructure.IJWTService, opts ...StreamControllerOption) *StreamController {
+	sc := &StreamController{stream: stream, tickets: tickets, heartbeat: DefaultStreamHeartbeat}
+	for _, opt := range opts {
+		opt(sc)
+	}
+	return sc
+}
+
+// IssueTicket hands out a single-use ticket that opens one stream, for
+// browsers, which cannot set the Authorization header on stream connections.
+// The stream ends when the caller's access token expires.
+func (sc *StreamController) IssueTicket(c *gin.Context) {
+	ticket, err := sc.tickets.GenerateStreamTicket(c.GetString("user_id"), c.GetString("token_id"), tokenExpiry(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue stream ticket"})
+		return
+	}
+	c.JSON(http.StatusCreated, dto.StreamTicketResponse{Ticket: ticket.Token, ExpiresAt: ticket.ExpiresAt})
+}
+
+// StreamTasks sends the caller's task events as Server-Sent Events until the
+// client goes away. EventSource sends the Last-Event-ID header when it
+// reconnects; other clients can pass last_event_id instead.
+func (sc *StreamController) StreamTasks(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	subscription, err := sc.stream.Subscribe(c.Request.Context(), userID, lastEventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open task stream"})
+		return
+	}
+	defer subscription.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	// Stop nginx from buffering the stream.
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	w := newSSEWriter(c.Writer)
+	if err := w.flush(); err != nil {
+		return
+	}
+	sc.pump(c.Request.Context(), subscription, w, tokenExpiry(c))
+}
+
+// StreamTasksWebSocket sends the caller's task events as JSON text messages
+// over a WebSocket. Reconnecting clients pass last_event_id.
+func (sc *StreamController) StreamTasksWebSocket(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	expiresAt := tokenExpiry(c)
+	// Subscribing before the upgrade lets a failure be answered over HTTP.
+	subscription, err := sc.stream.Subscribe(c.Request.Context(), userID, c.Query("last_event_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open task stream"})
+		return
+	}
+	defer subscription.Close()
+	server := websocket.Server{
+		// A ticket or access token, not a cookie, authenticates the
+		// connection, so another site's page cannot open it on the user's
+		// behalf.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			ctx, cancel := context.WithCancel(c.Request.Context())
+			defer cancel()
+			// Messages from the client are ignored, but reading them is how
+			// a closed connection is noticed.
+			go func() {
+				defer cancel()
+				var message []byte
+				for websocket.Message.Receive(conn, &message) == nil {
+				}
+			}()
+			sc.pump(ctx, subscription, &wsWriter{conn: conn}, expiresAt)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// tokenExpiry returns when the caller's access token expires, or the zero
+// time when that is not known.
+func tokenExpiry(c *gin.Context) time.Time {
+	if expiresAt, ok := c.Get("token_expires_at"); ok {
+		return expiresAt.(time.Time)
+	}
+	return time.Time{}
+}
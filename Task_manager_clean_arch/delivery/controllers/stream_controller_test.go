@@ -0,0 +1,202 @@
+package controllers_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"taskmanager/delivery/controllers"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/usecases"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/net/websocket"
+)
+
+// withStream serves live task streams over a real listener, since streams
+// are never done, to the user named in the user query parameter rather than
+// X-User. The fixture's task usecase publishes to the stream.
+func withStream(opts ...usecases.TaskStreamOption) fixtureOption {
+	return func(t *testing.T, f *fixture) {
+		f.stream = usecases.NewTaskStream(opts...)
+		f.tasks = f.newTaskUsecase(usecases.WithTaskEventSink(f.stream))
+		streams := controllers.NewStreamController(f.stream, nil, controllers.WithStreamHeartbeat(50*time.Millisecond))
+		group := f.router.Group("/tasks/stream", func(c *gin.Context) { c.Set("user_id", c.Query("user")) })
+		group.GET("", streams.StreamTasks)
+		group.GET("/ws", streams.StreamTasksWebSocket)
+		f.server = httptest.NewServer(f.router)
+		t.Cleanup(func() {
+			f.stream.Close()
+			f.server.Close()
+		})
+	}
+}
+
+// sseEvent is one parsed Server-Sent Event; heartbeats have only a comment.
+type sseEvent struct {
+	id, event, data, comment string
+}
+
+// readSSE parses the event stream in the background.
+func readSSE(t *testing.T, resp *http.Response) <-chan sseEvent {
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var event sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				events <- event
+				event = sseEvent{}
+			case strings.HasPrefix(line, ":"):
+				event.comment = strings.TrimSpace(line[1:])
+			case strings.HasPrefix(line, "id: "):
+				event.id = line[len("id: "):]
+			case strings.HasPrefix(line, "event: "):
+				event.event = line[len("event: "):]
+			case strings.HasPrefix(line, "data: "):
+				event.data = line[len("data: "):]
+			}
+		}
+	}()
+	return events
+}
+
+// nextEvent returns the next event that is not a heartbeat.
+func nextEvent(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			require.True(t, ok, "the stream ended")
+			if event.comment == "" {
+				return event
+			}
+		case <-timeout:
+			require.FailNow(t, "no event arrived")
+		}
+	}
+}
+
+func openSSE(t *testing.T, f *fixture, userID primitive.ObjectID, lastEventID string) <-chan sseEvent {
+	req, err := http.NewRequest(http.MethodGet, f.server.URL+"/tasks/stream?user="+userID.Hex(), nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return readSSE(t, resp)
+}
+
+func TestStreamController_ServerSentEvents(t *testing.T) {
+	f := newFixture(t, withStream())
+	ctx := context.Background()
+	ownerID, strangerID := primitive.NewObjectID(), primitive.NewObjectID()
+	events := openSSE(t, f, ownerID, "")
+	strangerEvents := openSSE(t, f, strangerID, "")
+
+	task, err := f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: usecases.StatusPending}, ownerID)
+	require.NoError(t, err)
+	created := nextEvent(t, events)
+	heartbeat := <-strangerEvents
+	_, err = f.tasks.UpdateTask(ctx, task.ID.Hex(), &domain.Task{Title: "Deploy v2", Status: usecases.StatusPending}, ownerID)
+	require.NoError(t, err)
+	require.NoError(t, f.tasks.DeleteTask(ctx, task.ID.Hex(), 0, ownerID))
+	// A client that reconnects after the creation is sent what it missed.
+	resumed := openSSE(t, f, ownerID, created.id)
+
+	// --- ASSERT ---
+	assert.Equal(t, usecases.EventTaskCreated, created.event)
+	assert.NotEmpty(t, created.id)
+	var payload dto.TaskStreamEvent
+	require.NoError(t, json.Unmarshal([]byte(created.data), &payload))
+	assert.Equal(t, created.id, payload.ID)
+	assert.Equal(t, "Deploy", payload.Task.Title)
+	assert.Equal(t, ownerID.Hex(), payload.ActorID)
+	assert.Equal(t, "heartbeat", heartbeat.comment, "the stranger only gets heartbeats")
+	assert.Equal(t, usecases.EventTaskUpdated, nextEvent(t, events).event)
+	assert.Equal(t, usecases.EventTaskDeleted, nextEvent(t, events).event)
+	assert.Equal(t, usecases.EventTaskUpdated, nextEvent(t, resumed).event)
+	assert.Equal(t, usecases.EventTaskDeleted, nextEvent(t, resumed).event)
+	reset := openSSE(t, f, ownerID, "from-another-run")
+	assert.Equal(t, "reset", nextEvent(t, reset).event)
+	f.stream.Close()
+	closing := nextEvent(t, events)
+	assert.Equal(t, "reconnect", closing.event)
+	assert.Contains(t, closing.data, `"reason":"shutdown"`)
+}
+
+func TestStreamController_WebSocket(t *testing.T) {
+	f := newFixture(t, withStream())
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+	wsURL := "ws" + strings.TrimPrefix(f.server.URL, "http") + "/tasks/stream/ws?user=" + userID.Hex()
+	conn, err := websocket.Dial(wsURL, "", f.server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+	// Wait for a heartbeat, so the subscription is surely in place.
+	var message dto.TaskStreamEvent
+	require.NoError(t, websocket.JSON.Receive(conn, &message))
+	require.Equal(t, "heartbeat", message.Event)
+
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: usecases.StatusPending}, userID)
+	require.NoError(t, err)
+	var received []dto.TaskStreamEvent
+	for len(received) == 0 || received[len(received)-1].Event == "heartbeat" {
+		message = dto.TaskStreamEvent{}
+		require.NoError(t, websocket.JSON.Receive(conn, &message))
+		received = append(received, message)
+	}
+
+	// --- ASSERT ---
+	created := received[len(received)-1]
+	assert.Equal(t, usecases.EventTaskCreated, created.Event)
+	assert.Equal(t, "Deploy", created.Task.Title)
+	assert.NotEmpty(t, created.ID)
+}
+
+func TestStreamController_IssueTicket(t *testing.T) {
+	f := newFixture(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	jwtService := infrastructure.NewJWTService()
+	streams := controllers.NewStreamController(usecases.NewTaskStream(), jwtService)
+	router := gin.New()
+	router.POST("/tasks/stream/ticket", infrastructure.AuthMiddleware(jwtService, f.repos.Tokens, nil), streams.IssueTicket)
+	router.GET("/tasks/stream", infrastructure.StreamAuthMiddleware(jwtService, f.repos.Tokens, f.repos.Tokens, nil), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("user_id"))
+	})
+	user := domain.User{ID: primitive.NewObjectID(), Username: "alice"}
+	accessToken, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+
+	w := serve(router, http.MethodPost, "/tasks/stream/ticket", "", map[string]string{"Authorization": "Bearer " + accessToken.Token})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var ticket dto.StreamTicketResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ticket))
+	opened := serve(router, http.MethodGet, "/tasks/stream?ticket="+ticket.Ticket, "", nil)
+	reused := serve(router, http.MethodGet, "/tasks/stream?ticket="+ticket.Ticket, "", nil)
+
+	// --- ASSERT ---
+	assert.WithinDuration(t, time.Now().Add(infrastructure.StreamTicketTTL), ticket.ExpiresAt, 5*time.Second)
+	assert.Equal(t, http.StatusOK, opened.Code)
+	assert.Equal(t, user.ID.Hex(), opened.Body.String())
+	assert.Equal(t, http.StatusUnauthorized, reused.Code, "a ticket opens one stream")
+	w = serve(router, http.MethodPost, "/tasks/stream/ticket", "", map[string]string{"Authorization": "Bearer " + ticket.Ticket})
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "a ticket cannot get more tickets")
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ITagController interface {
+	CreateTag(c *gin.Context)
+	ListTags(c *gin.Context)
+	UpdateTag(c *gin.Context)
+	DeleteTag(c *gin.Context)
+}
+
+type TagController struct {
+	tagUsecase usecases.ITagUsecase
+}
+
+func NewTagController(tagUsecase usecases.ITagUsecase) *TagController {
+	return &TagController{tagUsecase: tagUsecase}
+}
+
+func toTagResponse(tag *domain.Tag) dto.TagResponse {
+	return dto.TagResponse{ID: tag.ID.Hex(), Name: tag.Name, Color: tag.Color, CreatedAt: tag.CreatedAt}
+}
+
+// respondTagError maps a tag usecase error to its HTTP status.
+func respondTagError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidTag):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrTagExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrTagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (tc *TagController) CreateTag(c *gin.Context) {
+	var input dto.TagRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	tag, err := tc.tagUsecase.CreateTag(c.Request.Context(), input.Name, input.Color, userID)
+	if err != nil {
+		respondTagError(c, err, "Failed to create tag")
+		return
+	}
+	c.JSON(http.StatusCreated, toTagResponse(tag))
+}
+
+func (tc *TagController) ListTags(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	tags, err := tc.tagUsecase.ListTags(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags"})
+		return
+	}
+	response := dto.TagListResponse{Tags: make([]dto.TagResponse, len(tags))}
+	for i := range tags {
+		response.Tags[i] = toTagResponse(&tags[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (tc *TagController) UpdateTag(c *gin.Context) {
+	var input dto.TagUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	update := usecases.TagUpdate{Name: input.Name, Color: input.Color}
+	tag, err := tc.tagUsecase.UpdateTag(c.Request.Context(), c.Param("id"), update, userID)
+	if err != nil {
+		respondTagError(c, err, "Failed to update tag")
+		return
+	}
+	c.JSON(http.StatusOK, toTagResponse(tag))
+}
+
+func (tc *TagController) DeleteTag(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err := tc.tagUsecase.DeleteTag(c.Request.Context(), c.Param("id"), userID); err != nil {
+		respondTagError(c, err, "Failed to delete tag")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,62 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"taskmanager/delivery/controllers"
+	"taskmanager/repositories"
+	"taskmanager/usecases"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestTagController_TagAndFilterTasks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repos := repositories.NewMemoryRepositories()
+	tasks := controllers.NewTaskController(usecases.NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects))
+	tags := controllers.NewTagController(usecases.NewTagUsecase(repos.Tags, repos.Tasks, repos.UnitOfWork))
+	userID := primitive.NewObjectID()
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", userID.Hex()) })
+	router.GET("/tasks", tasks.GetUserTasks)
+	router.POST("/tasks", tasks.CreateTask)
+	router.POST("/tags", tags.CreateTag)
+	router.PATCH("/tags/:id", tags.UpdateTag)
+
+	w := serve(router, http.MethodPost, "/tags", `{"name":"backend","color":"#336699"}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var tag struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tag))
+	w = serve(router, http.MethodPost, "/tags", `{"name":"backend"}`, nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = serve(router, http.MethodPost, "/tasks", `{"title":"API","status":"pending","tags":["backend"]}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = serve(router, http.MethodPost, "/tasks", `{"title":"Notes","status":"pending"}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = serve(router, http.MethodPost, "/tasks", `{"title":"Docs","status":"pending","tags":["docs"]}`, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	w = serve(router, http.MethodPatch, "/tags/"+tag.ID, `{"name":"api"}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// --- ASSERT ---
+	w = serve(router, http.MethodGet, "/tasks?tags=api,docs", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed struct {
+		Tasks []struct {
+			Title string   `json:"title"`
+			Tags  []string `json:"tags"`
+		} `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Tasks, 1)
+	assert.Equal(t, "API", listed.Tasks[0].Title)
+	assert.Equal(t, []string{"api"}, listed.Tasks[0].Tags)
+}
@@ -0,0 +1,1000 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/repositories"
+	"taskmanager/usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ITaskController interface {
+	CreateTask(c *gin.Context)
+	GetUserTasks(c *gin.Context)
+	SearchTasks(c *gin.Context)
+	GetTaskByID(c *gin.Context)
+	UpdateTask(c *gin.Context)
+	PatchTask(c *gin.Context)
+	DeleteTask(c *gin.Context)
+	ListTrash(c *gin.Context)
+	RestoreTask(c *gin.Context)
+	GetTaskHistory(c *gin.Context)
+	GetSubtasks(c *gin.Context)
+	GetDependencies(c *gin.Context)
+	GetOccurrences(c *gin.Context)
+	RunBatch(c *gin.Context)
+	ExportTasks(c *gin.Context)
+	ImportTasks(c *gin.Context)
+	AssignTask(c *gin.Context)
+	UnassignTask(c *gin.Context)
+	ShareTask(c *gin.Context)
+	UnshareTask(c *gin.Context)
+	ListProjectTasks(c *gin.Context)
+	CreateProjectTask(c *gin.Context)
+}
+
+// isTaskValidationError reports whether err means the request was well formed
+// but broke a business rule, which the API reports as 422.
+func isTaskValidationError(err error) bool {
+	return errors.Is(err, usecases.ErrUnknownStatus) || errors.Is(err, usecases.ErrInvalidStatusTransition) ||
+		errors.Is(err, usecases.ErrInvalidTaskRelation) || errors.Is(err, usecases.ErrDependencyCycle) ||
+		errors.Is(err, usecases.ErrOpenBlockers) || errors.Is(err, usecases.ErrInvalidCollaborator) ||
+		errors.Is(err, usecases.ErrUserNotFound) || errors.Is(err, usecases.ErrInvalidRecurrence) ||
+		errors.Is(err, usecases.ErrInvalidTaskPatch) || errors.Is(err, usecases.ErrInvalidTag)
+}
+
+// taskErrorStatus maps a task usecase error to its HTTP status. Errors the
+// client cannot fix map to 500.
+func taskErrorStatus(err error) int {
+	switch {
+	case isTaskValidationError(err):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecases.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, usecases.ErrTaskNotFound), errors.Is(err, usecases.ErrInvalidTaskID),
+		errors.Is(err, usecases.ErrProjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrProjectArchived):
+		return http.StatusConflict
+	case errors.Is(err, usecases.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// respondTaskError answers with the status of a task usecase error. Errors
+// the client cannot fix are logged by gin and reported with the generic
+// message.
+func respondTaskError(c *gin.Context, err error, message string) {
+	status := taskErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		_ = c.Error(err)
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// toDomainTask maps a create or update request to a domain task. Relation
+// IDs must be hex ObjectIDs; an empty parent_id means a top-level task.
+func toDomainTask(input *dto.TaskRequest) (*domain.Task, error) {
+	task := &domain.Task{
+		Title:       input.Title,
+		Description: input.Description,
+		Duedate:     input.DueDate,
+		Status:      input.Status,
+		Tags:        input.Tags,
+	}
+	if input.ParentID != "" {
+		parentID, err := primitive.ObjectIDFromHex(input.ParentID)
+		if err != nil {
+			return nil, errors.New("parent_id must be a valid task ID")
+		}
+		task.ParentID = parentID
+	}
+	if input.ProjectID != "" {
+		projectID, err := primitive.ObjectIDFromHex(input.ProjectID)
+		if err != nil {
+			return nil, errors.New("project_id must be a valid project ID")
+		}
+		task.ProjectID = projectID
+	}
+	for _, hex := range input.BlockedBy {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			return nil, errors.New("blocked_by must contain valid task IDs")
+		}
+		task.BlockedBy = append(task.BlockedBy, id)
+	}
+	if input.Recurrence != "" {
+		task.Recurrence = &domain.Recurrence{Rule: input.Recurrence, TimeZone: input.TimeZone}
+	}
+	return task, nil
+}
+
+// Media types PATCH /tasks/:id accepts; plain application/json is read as a
+// merge patch.
+const (
+	mergePatchMediaType = "application/merge-patch+json"
+	jsonPatchMediaType  = "application/json-patch+json"
+)
+
+// toTaskPatchDocument renders the fields of a task that PATCH can change.
+func toTaskPatchDocument(task *domain.Task) dto.TaskPatchDocument {
+	document := dto.TaskPatchDocument{
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		BlockedBy:   make([]string, len(task.BlockedBy)),
+		Tags:        append([]string{}, task.Tags...),
+	}
+	if !task.Duedate.IsZero() {
+		document.DueDate = &task.Duedate
+	}
+	if !task.ParentID.IsZero() {
+		parentID := task.ParentID.Hex()
+		document.ParentID = &parentID
+	}
+	for i, id := range task.BlockedBy {
+		document.BlockedBy[i] = id.Hex()
+	}
+	if task.Recurrence != nil {
+		document.Recurrence = &task.Recurrence.Rule
+		document.TimeZone = &task.Recurrence.TimeZone
+	}
+	return document
+}
+
+// parseTaskPatch reads an RFC 7396 merge patch over the fields of
+// dto.TaskPatchDocument, checking the type of each one. null clears an
+// optional field; title and status cannot be null.
+func parseTaskPatch(data []byte) (*usecases.TaskPatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, errors.New("a merge patch must be a JSON object")
+	}
+	patch := &usecases.TaskPatch{}
+	for name, raw := range fields {
+		null := string(raw) == "null"
+		var err error
+		switch name {
+		case "title":
+			patch.Title, err = patchString(raw, false)
+		case "status":
+			patch.Status, err = patchString(raw, false)
+		case "description":
+			patch.Description, err = patchString(raw, true)
+		case "recurrence":
+			patch.Recurrence, err = patchString(raw, true)
+		case "time_zone":
+			patch.TimeZone, err = patchString(raw, true)
+		case "due_date":
+			patch.DueDate = &time.Time{}
+			if !null {
+				err = json.Unmarshal(raw, patch.DueDate)
+			}
+		case "parent_id":
+			patch.ParentID = &primitive.ObjectID{}
+			var hex *string
+			if err = json.Unmarshal(raw, &hex); err == nil && hex != nil && *hex != "" {
+				*patch.ParentID, err = primitive.ObjectIDFromHex(*hex)
+			}
+		case "blocked_by":
+			var hexes []string
+			ids := []primitive.ObjectID{}
+			err = json.Unmarshal(raw, &hexes)
+			for _, hex := range hexes {
+				id, idErr := primitive.ObjectIDFromHex(hex)
+				if idErr != nil {
+					err = idErr
+					break
+				}
+				ids = append(ids, id)
+			}
+			patch.BlockedBy = &ids
+		case "tags":
+			tags := []string{}
+			if !null {
+				err = json.Unmarshal(raw, &tags)
+			}
+			patch.Tags = &tags
+		default:
+			return nil, fmt.Errorf("%s cannot be patched", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s", name)
+		}
+	}
+	return patch, nil
+}
+
+// patchString reads a string member of a merge patch. A nullable one reads
+// null as "".
+func patchString(raw json.RawMessage, nullable bool) (*string, error) {
+	if string(raw) == "null" {
+		if !nullable {
+			return nil, errors.New("cannot be null")
+		}
+		return new(string), nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// mergePatchBetween returns the merge patch that turns the JSON object
+// before into after, comparing top-level members only.
+func mergePatchBetween(before, after []byte) ([]byte, error) {
+	var old, updated map[string]json.RawMessage
+	if err := json.Unmarshal(before, &old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &updated); err != nil || updated == nil {
+		return nil, errors.New("the patched task must be a JSON object")
+	}
+	patch := map[string]json.RawMessage{}
+	for name, value := range updated {
+		if previous, ok := old[name]; !ok || string(previous) != string(value) {
+			patch[name] = value
+		}
+	}
+	for name := range old {
+		if _, ok := updated[name]; !ok {
+			patch[name] = json.RawMessage("null")
+		}
+	}
+	return json.Marshal(patch)
+}
+
+// taskETag is the entity tag of a task's current version.
+func taskETag(task *domain.Task) string {
+	return `"` + strconv.FormatInt(task.Version, 10) + `"`
+}
+
+// respondTask writes a task along with the ETag of its version.
+func respondTask(c *gin.Context, status int, task *domain.Task) {
+	c.Header("ETag", taskETag(task))
+	c.JSON(status, toTaskResponse(task))
+}
+
+// errIfMatchMismatch means the If-Match header cannot match any version.
+var errIfMatchMismatch = errors.New("the If-Match header must be a single ETag returned by this API, or *")
+
+// parseIfMatch reads the version a client expects from If-Match. A missing
+// header or "*" puts no condition on the version and yields 0. Weak tags
+// never match, as If-Match uses strong comparison.
+func parseIfMatch(c *gin.Context) (version int64, present bool, err error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, false, nil
+	}
+	if header == "*" {
+		return 0, true, nil
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, true, errIfMatchMismatch
+	}
+	version, err = strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, true, errIfMatchMismatch
+	}
+	return version, true, nil
+}
+
+// noneMatch reports whether an If-None-Match header lists etag, using the
+// weak comparison RFC 9110 prescribes for it.
+func noneMatch(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+type TaskController struct {
+	taskUsecase    usecases.ITaskUsecase
+	requireIfMatch bool
+}
+
+// TaskControllerOption customizes a task controller at construction time.
+type TaskControllerOption func(*TaskController)
+
+// WithRequireIfMatch makes PUT, PATCH and DELETE on a task answer 428 Precondition
+// Required unless they carry an If-Match header.
+func WithRequireIfMatch(required bool) TaskControllerOption {
+	return func(tc *TaskController) { tc.requireIfMatch = required }
+}
+
+func NewTaskController(taskUsecase usecases.ITaskUsecase, opts ...TaskControllerOption) *TaskController {
+	tc := &TaskController{taskUsecase: taskUsecase}
+	for _, opt := range opts {
+		opt(tc)
+	}
+	return tc
+}
+
+// expectedVersion reads If-Match for a PUT or DELETE. It answers the request
+// itself and returns false when the precondition is missing or can never hold.
+func (tc *TaskController) expectedVersion(c *gin.Context) (int64, bool) {
+	version, present, err := parseIfMatch(c)
+	switch {
+	case err != nil:
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+		return 0, false
+	case !present && tc.requireIfMatch:
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header is required"})
+		return 0, false
+	}
+	return version, true
+}
+
+func (tc *TaskController) CreateTask(c *gin.Context) {
+	tc.createTask(c, "")
+}
+
+// CreateProjectTask creates a task in the project named by the path. Project
+// membership, not the admin role, decides who may do so.
+func (tc *TaskController) CreateProjectTask(c *gin.Context) {
+	tc.createTask(c, c.Param("id"))
+}
+
+// createTask creates a task from the request body, in projectID when it is
+// set and otherwise in the project the body names, if any.
+func (tc *TaskController) createTask(c *gin.Context, projectID string) {
+	var input dto.TaskRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	if projectID != "" {
+		input.ProjectID = projectID
+	}
+
+	// Map the DTO to the Domain model
+	domainTask, err := toDomainTask(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createdTask, err := tc.taskUsecase.CreateTask(c.Request.Context(), domainTask, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to create task")
+		return
+	}
+
+	respondTask(c, http.StatusCreated, createdTask)
+}
+
+// queryList reads a query parameter that may be repeated, comma-separated,
+// or both.
+func queryList(c *gin.Context, param string) []string {
+	var values []string
+	for _, value := range c.QueryArray(param) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+// parseTaskQuery reads the GET /tasks filter, sort and pagination parameters.
+// Statuses and tags may be repeated or comma separated; sort takes a "-" prefix for
+// descending order; dates are RFC 3339.
+func parseTaskQuery(c *gin.Context) (repositories.TaskQuery, error) {
+	var query repositories.TaskQuery
+
+	query.Statuses = queryList(c, "status")
+	// tags matches tasks with any of the tags and all_tags those with every one.
+	query.AnyTags = queryList(c, "tags")
+	query.AllTags = queryList(c, "all_tags")
+
+	dates := map[string]*time.Time{
+		"due_from":      &query.DueFrom,
+		"due_to":        &query.DueTo,
+		"created_after": &query.CreatedAfter,
+	}
+	for param, target := range dates {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, errors.New(param + " must be an RFC 3339 timestamp")
+			}
+			*target = parsed
+		}
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		query.Descending = strings.HasPrefix(sort, "-")
+		query.SortBy = repositories.TaskSortField(strings.TrimPrefix(sort, "-"))
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return query, errors.New("limit must be a positive integer")
+		}
+		query.Limit = n
+	}
+
+	query.Scope = repositories.TaskScope(c.Query("scope"))
+	query.Cursor = c.Query("cursor")
+	return query, nil
+}
+
+func (tc *TaskController) GetUserTasks(c *gin.Context) {
+	tc.listTasks(c, primitive.NilObjectID)
+}
+
+// ListProjectTasks lists the tasks of the project named by the path, with
+// the same parameters as GetUserTasks.
+func (tc *TaskController) ListProjectTasks(c *gin.Context) {
+	projectID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": usecases.ErrProjectNotFound.Error()})
+		return
+	}
+	tc.listTasks(c, projectID)
+}
+
+func (tc *TaskController) listTasks(c *gin.Context, projectID primitive.ObjectID) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query.ProjectID = projectID
+	tasks, nextCursor, err := tc.taskUsecase.ListTasks(c.Request.Context(), query, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrInvalidTaskQuery):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tasks"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, dto.TaskListResponse{Tasks: toTasksResponse(tasks), NextCursor: nextCursor})
+}
+
+func (tc *TaskController) SearchTasks(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = n
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	tasks, err := tc.taskUsecase.SearchTasks(c.Request.Context(), c.Query("q"), limit, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrInvalidTaskQuery):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrProjectNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search tasks"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, dto.TaskSearchResponse{Tasks: toTasksResponse(tasks)})
+}
+
+func (tc *TaskController) GetTaskByID(c *gin.Context) {
+	taskID := c.Param("id")
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	task, err := tc.taskUsecase.GetTaskByID(c.Request.Context(), taskID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if etag := taskETag(task); noneMatch(c.GetHeader("If-None-Match"), etag) {
+		c.Header("ETag", etag)
+		c.Status(http.StatusNotModified)
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) UpdateTask(c *gin.Context) {
+	taskID := c.Param("id")
+	var input dto.TaskRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	domainTask, err := toDomainTask(&input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	version, ok := tc.expectedVersion(c)
+	if !ok {
+		return
+	}
+	domainTask.Version = version
+
+	updatedTask, err := tc.taskUsecase.UpdateTask(c.Request.Context(), taskID, domainTask, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to update task")
+		return
+	}
+	respondTask(c, http.StatusOK, updatedTask)
+}
+
+// PatchTask changes only the fields the request names. The body is either a
+// JSON merge patch or a JSON Patch, told apart by Content-Type. A JSON Patch
+// is applied to the task as it is read here, so the update fails with 412 if
+// the task changes before it is saved.
+func (tc *TaskController) PatchTask(c *gin.Context) {
+	taskID := c.Param("id")
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	version, ok := tc.expectedVersion(c)
+	if !ok {
+		return
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	var patch *usecases.TaskPatch
+	switch mediaType {
+	case mergePatchMediaType, "application/json":
+		if patch, err = parseTaskPatch(body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	case jsonPatchMediaType:
+		task, err := tc.taskUsecase.GetTaskByID(c.Request.Context(), taskID, userID)
+		if err != nil {
+			respondTaskError(c, err, "Failed to update task")
+			return
+		}
+		if version == 0 {
+			version = task.Version
+		}
+		current, err := json.Marshal(toTaskPatchDocument(task))
+		if err != nil {
+			respondTaskError(c, err, "Failed to update task")
+			return
+		}
+		patched, err := infrastructure.ApplyJSONPatch(current, body)
+		switch {
+		case errors.Is(err, infrastructure.ErrInvalidJSONPatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, infrastructure.ErrJSONPatchTestFailed):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		merge, err := mergePatchBetween(current, patched)
+		if err == nil {
+			patch, err = parseTaskPatch(merge)
+		}
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	default:
+		c.Header("Accept-Patch", mergePatchMediaType+", "+jsonPatchMediaType)
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be " + mergePatchMediaType + " or " + jsonPatchMediaType})
+		return
+	}
+	patch.Version = version
+
+	updatedTask, err := tc.taskUsecase.PatchTask(c.Request.Context(), taskID, patch, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to update task")
+		return
+	}
+	respondTask(c, http.StatusOK, updatedTask)
+}
+
+func (tc *TaskController) DeleteTask(c *gin.Context) {
+	taskID := c.Param("id")
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	version, ok := tc.expectedVersion(c)
+	if !ok {
+		return
+	}
+	err := tc.taskUsecase.DeleteTask(c.Request.Context(), taskID, version, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to delete task")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (tc *TaskController) ListTrash(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	tasks, err := tc.taskUsecase.ListTrash(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve trash"})
+		return
+	}
+	c.JSON(http.StatusOK, dto.TaskListResponse{Tasks: toTasksResponse(tasks)})
+}
+
+func (tc *TaskController) RestoreTask(c *gin.Context) {
+	taskID := c.Param("id")
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	task, err := tc.taskUsecase.RestoreTask(c.Request.Context(), taskID, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to restore task")
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) GetTaskHistory(c *gin.Context) {
+	taskID := c.Param("id")
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	query, err := parseAuditQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	entries, nextCursor, err := tc.taskUsecase.GetTaskHistory(c.Request.Context(), taskID, query, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, usecases.ErrInvalidAuditQuery):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecases.ErrTaskNotFound), errors.Is(err, usecases.ErrInvalidTaskID):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve task history"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, dto.AuditListResponse{Entries: toAuditEntryResponses(entries), NextCursor: nextCursor})
+}
+
+func (tc *TaskController) GetSubtasks(c *gin.Context) {
+	taskID := c.Param("id")
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	subtasks, err := tc.taskUsecase.GetSubtasks(c.Request.Context(), taskID, userID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrTaskNotFound) || errors.Is(err, usecases.ErrInvalidTaskID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve subtasks"})
+		return
+	}
+	c.JSON(http.StatusOK, dto.TaskListResponse{Tasks: toTasksResponse(subtasks)})
+}
+
+func (tc *TaskController) GetDependencies(c *gin.Context) {
+	taskID := c.Param("id")
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	deps, err := tc.taskUsecase.GetDependencies(c.Request.Context(), taskID, userID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrTaskNotFound) || errors.Is(err, usecases.ErrInvalidTaskID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve dependencies"})
+		return
+	}
+	c.JSON(http.StatusOK, dto.TaskDependenciesResponse{
+		BlockedBy: toTasksResponse(deps.BlockedBy),
+		Blocking:  toTasksResponse(deps.Blocking),
+	})
+}
+
+// GetOccurrences previews the due dates of the next occurrences of a
+// recurring task. count defaults to 5 and is capped at 100.
+func (tc *TaskController) GetOccurrences(c *gin.Context) {
+	count := 0
+	if raw := c.Query("count"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "count must be a positive integer"})
+			return
+		}
+		count = n
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	occurrences, err := tc.taskUsecase.GetOccurrences(c.Request.Context(), c.Param("id"), count, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to preview occurrences")
+		return
+	}
+	c.JSON(http.StatusOK, dto.OccurrencesResponse{Occurrences: occurrences})
+}
+
+// RunBatch applies a list of creates, updates and deletes atomically. The
+// response has a result per operation; if one fails, it answers with that
+// operation's status and nothing in the batch is kept.
+func (tc *TaskController) RunBatch(c *gin.Context) {
+	var input dto.BatchRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	ops := make([]usecases.BatchOperation, len(input.Operations))
+	for i, in := range input.Operations {
+		op := usecases.BatchOperation{Op: in.Op, TaskID: in.ID, Version: in.Version}
+		if in.Task != nil {
+			task, err := toDomainTask(in.Task)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("operation %d: %v", i, err)})
+				return
+			}
+			op.Task = task
+		}
+		if tc.requireIfMatch && op.Op != usecases.BatchCreate && op.Version == 0 {
+			c.JSON(http.StatusPreconditionRequired, gin.H{"error": fmt.Sprintf("operation %d: version is required", i)})
+			return
+		}
+		ops[i] = op
+	}
+
+	results, err := tc.taskUsecase.RunBatch(c.Request.Context(), ops, userID)
+	if results == nil {
+		switch {
+		case errors.Is(err, usecases.ErrInvalidBatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repositories.ErrTransactionsUnsupported):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			_ = c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run batch"})
+		}
+		return
+	}
+
+	status := http.StatusOK
+	response := dto.BatchResponse{Committed: err == nil, Results: make([]dto.BatchResultResponse, len(results))}
+	for i, result := range results {
+		item := dto.BatchResultResponse{Index: i, Op: ops[i].Op}
+		switch {
+		case result.Err == nil && result.Task == nil:
+			item.Status = http.StatusNoContent
+		case result.Err == nil:
+			item.Status = http.StatusOK
+			if ops[i].Op == usecases.BatchCreate {
+				item.Status = http.StatusCreated
+			}
+			task := toTaskResponse(result.Task)
+			item.Task = &task
+		case errors.Is(result.Err, usecases.ErrBatchAborted):
+			item.Status, item.Error = http.StatusFailedDependency, result.Err.Error()
+		default:
+			item.Status, item.Error = taskErrorStatus(result.Err), result.Err.Error()
+			if item.Status == http.StatusInternalServerError {
+				_ = c.Error(result.Err)
+				item.Error = "Failed to run operation"
+			}
+			status = item.Status
+		}
+		response.Results[i] = item
+	}
+	c.JSON(status, response)
+}
+
+// ExportTasks sends the user's tasks as a CSV, JSON or iCalendar file,
+// written a page at a time. It takes the filters and sort of GET /tasks.
+func (tc *TaskController) ExportTasks(c *gin.Context) {
+	format := c.DefaultQuery("format", formatJSON)
+	mediaType, ok := formatMediaTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUnknownFormat.Error()})
+		return
+	}
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	c.Header("Content-Type", mediaType+"; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="tasks.`+format+`"`)
+	encoder := newTaskEncoder(format, c.Writer)
+	err = tc.taskUsecase.ExportTasks(c.Request.Context(), query, userID, encoder.Encode)
+	if err == nil {
+		err = encoder.Close()
+	}
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		// Part of the file is out; all that is left is to cut it short.
+		_ = c.Error(err)
+		return
+	}
+	c.Writer.Header().Del("Content-Disposition")
+	switch {
+	case errors.Is(err, usecases.ErrInvalidTaskQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export tasks"})
+	}
+}
+
+// ImportTasks creates tasks from a CSV, JSON or iCalendar file, read as it
+// arrives. The format comes from the format parameter or the Content-Type.
+// With dry_run=true nothing is stored and the report says what would be.
+func (tc *TaskController) ImportTasks(c *gin.Context) {
+	format := c.Query("format")
+	if format == "" {
+		if format = formatOfMediaType(c.ContentType()); format == "" {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be text/csv, application/json or text/calendar"})
+			return
+		}
+	}
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+		return
+	}
+	rows, err := newImportReader(format, c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+
+	report, err := tc.taskUsecase.ImportTasks(c.Request.Context(), rows, dryRun, userID)
+	if report == nil {
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tasks"})
+		return
+	}
+	response := dto.ImportResponse{
+		DryRun:  report.DryRun,
+		Created: report.Created,
+		Skipped: report.Skipped,
+		Failed:  report.Failed,
+		Results: make([]dto.ImportResultResponse, len(report.Results)),
+	}
+	for i, result := range report.Results {
+		item := dto.ImportResultResponse{Row: result.Row, ExternalID: result.ExternalID, Status: result.Status}
+		if !result.TaskID.IsZero() {
+			item.TaskID = result.TaskID.Hex()
+		}
+		var badRow rowError
+		switch {
+		case result.Err == nil:
+		case result.Status == usecases.ImportFailed && !errors.As(result.Err, &badRow) && taskErrorStatus(result.Err) == http.StatusInternalServerError:
+			_ = c.Error(result.Err)
+			item.Error = "Failed to import row"
+		default:
+			item.Error = result.Err.Error()
+		}
+		response.Results[i] = item
+	}
+
+	// The rows before an unreadable part of the file have been imported.
+	status := http.StatusOK
+	if err != nil {
+		status, response.Error = http.StatusBadRequest, err.Error()
+	}
+	c.JSON(status, response)
+}
+
+func (tc *TaskController) AssignTask(c *gin.Context) {
+	taskID := c.Param("id")
+	var input dto.AssignRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	assigneeID, err := primitive.ObjectIDFromHex(input.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id must be a valid ID"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	task, err := tc.taskUsecase.AssignTask(c.Request.Context(), taskID, assigneeID, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to assign task")
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) UnassignTask(c *gin.Context) {
+	taskID := c.Param("id")
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	task, err := tc.taskUsecase.UnassignTask(c.Request.Context(), taskID, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to unassign task")
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) ShareTask(c *gin.Context) {
+	taskID := c.Param("id")
+	var input dto.ShareRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	collaboratorID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	task, err := tc.taskUsecase.ShareTask(c.Request.Context(), taskID, collaboratorID, input.Role, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to share task")
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
+
+func (tc *TaskController) UnshareTask(c *gin.Context) {
+	taskID := c.Param("id")
+	collaboratorID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	task, err := tc.taskUsecase.UnshareTask(c.Request.Context(), taskID, collaboratorID, userID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to unshare task")
+		return
+	}
+	respondTask(c, http.StatusOK, task)
+}
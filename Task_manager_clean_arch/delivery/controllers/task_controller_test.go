@@ -0,0 +1,355 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"taskmanager/delivery/controllers"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"taskmanager/usecases"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newTaskRouter serves the task controller over in-memory repositories, as
+// the admin who owns one task. It returns the router and that task's ID.
+func newTaskRouter(t *testing.T, opts ...controllers.TaskControllerOption) (*gin.Engine, string) {
+	gin.SetMode(gin.TestMode)
+	repos := repositories.NewMemoryRepositories()
+	usecase := usecases.NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects, usecases.WithUnitOfWork(repos.UnitOfWork))
+	ownerID := primitive.NewObjectID()
+	task, err := usecase.CreateTask(context.Background(), &domain.Task{Title: "Draft", Status: usecases.StatusPending}, ownerID)
+	require.NoError(t, err)
+
+	controller := controllers.NewTaskController(usecase, opts...)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", ownerID.Hex()) })
+	router.GET("/tasks/:id", controller.GetTaskByID)
+	router.PUT("/tasks/:id", controller.UpdateTask)
+	router.PATCH("/tasks/:id", controller.PatchTask)
+	router.DELETE("/tasks/:id", controller.DeleteTask)
+	router.GET("/tasks/trash", controller.ListTrash)
+	router.GET("/tasks/search", controller.SearchTasks)
+	router.POST("/tasks/batch", controller.RunBatch)
+	router.GET("/tasks/export", controller.ExportTasks)
+	router.POST("/tasks/import", controller.ImportTasks)
+	router.POST("/tasks/:id/restore", controller.RestoreTask)
+	return router, task.ID.Hex()
+}
+
+func serve(router *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTaskController_ConditionalRequests(t *testing.T) {
+	router, id := newTaskRouter(t)
+	update := `{"title": "Final", "status": "Pending"}`
+
+	w := serve(router, http.MethodGet, "/tasks/"+id, "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+
+	w = serve(router, http.MethodGet, "/tasks/"+id, "", map[string]string{"If-None-Match": `"1"`})
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = serve(router, http.MethodPut, "/tasks/"+id, update, map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+
+	// A client still holding version 1 can neither overwrite nor delete.
+	w = serve(router, http.MethodPut, "/tasks/"+id, update, map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = serve(router, http.MethodDelete, "/tasks/"+id, "", map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = serve(router, http.MethodPut, "/tasks/"+id, update, map[string]string{"If-Match": `W/"2"`})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = serve(router, http.MethodGet, "/tasks/"+id, "", map[string]string{"If-None-Match": `"1"`})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// --- ASSERT ---
+	w = serve(router, http.MethodDelete, "/tasks/"+id, "", map[string]string{"If-Match": `"2"`})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestTaskController_RequireIfMatch(t *testing.T) {
+	router, id := newTaskRouter(t, controllers.WithRequireIfMatch(true))
+
+	w := serve(router, http.MethodPut, "/tasks/"+id, `{"title": "Final", "status": "Pending"}`, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+	w = serve(router, http.MethodDelete, "/tasks/"+id, "", nil)
+	assert.Equal(t, http.StatusPreconditionRequired, w.Code)
+
+	// --- ASSERT ---
+	w = serve(router, http.MethodDelete, "/tasks/"+id, "", map[string]string{"If-Match": "*"})
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestTaskController_PatchTask(t *testing.T) {
+	router, id := newTaskRouter(t)
+	mergePatch := map[string]string{"Content-Type": "application/merge-patch+json"}
+	jsonPatch := map[string]string{"Content-Type": "application/json-patch+json"}
+
+	w := serve(router, http.MethodPatch, "/tasks/"+id, `{"description": "Notes", "due_date": "2026-05-01T18:00:00Z"}`, mergePatch)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Only the status changes; the description and due date stay.
+	w = serve(router, http.MethodPatch, "/tasks/"+id, `{"status": "in_progress"}`, mergePatch)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var task map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+	assert.Equal(t, "In Progress", task["status"])
+	assert.Equal(t, "Notes", task["description"])
+	assert.Equal(t, "2026-05-01T18:00:00Z", task["due_date"])
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+	w = serve(router, http.MethodPatch, "/tasks/"+id,
+		`[{"op": "test", "path": "/title", "value": "Draft"}, {"op": "replace", "path": "/title", "value": "Final"}, {"op": "remove", "path": "/due_date"}]`, jsonPatch)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+	assert.Equal(t, "Final", task["title"])
+	assert.Equal(t, "0001-01-01T00:00:00Z", task["due_date"])
+	assert.Equal(t, "Notes", task["description"])
+
+	// --- ASSERT ---
+	w = serve(router, http.MethodPatch, "/tasks/"+id, `[{"op": "test", "path": "/title", "value": "Draft"}]`, jsonPatch)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = serve(router, http.MethodPatch, "/tasks/"+id, `[{"op": "remove", "path": "/nope"}]`, jsonPatch)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = serve(router, http.MethodPatch, "/tasks/"+id, `{"title": null}`, mergePatch)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodPatch, "/tasks/"+id, `{"user_id": "x"}`, mergePatch)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodPatch, "/tasks/"+id, `{"status": "Shelved"}`, mergePatch)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = serve(router, http.MethodPatch, "/tasks/"+id, `{"title": "Stale"}`, map[string]string{"Content-Type": "application/merge-patch+json", "If-Match": `"1"`})
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	w = serve(router, http.MethodPatch, "/tasks/"+id, `title=x`, map[string]string{"Content-Type": "text/plain"})
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Contains(t, w.Header().Get("Accept-Patch"), "application/json-patch+json")
+}
+
+func TestTaskController_TrashAndRestore(t *testing.T) {
+	router, id := newTaskRouter(t)
+
+	w := serve(router, http.MethodDelete, "/tasks/"+id, "", nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = serve(router, http.MethodGet, "/tasks/"+id, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve(router, http.MethodGet, "/tasks/trash", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var trash struct {
+		Tasks []map[string]interface{} `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &trash))
+	require.Len(t, trash.Tasks, 1)
+	assert.Equal(t, id, trash.Tasks[0]["id"])
+	assert.NotEmpty(t, trash.Tasks[0]["deleted_at"])
+
+	w = serve(router, http.MethodPost, "/tasks/"+id+"/restore", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "deleted_at")
+
+	// --- ASSERT ---
+	w = serve(router, http.MethodGet, "/tasks/"+id, "", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = serve(router, http.MethodPost, "/tasks/"+id+"/restore", "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTaskController_SearchTasks(t *testing.T) {
+	router, id := newTaskRouter(t)
+
+	w := serve(router, http.MethodGet, "/tasks/search?q=", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodGet, "/tasks/search?q=owner:me", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodGet, "/tasks/search?q=draft&limit=0", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodGet, "/tasks/search?q=project:"+primitive.NewObjectID().Hex(), "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// --- ASSERT ---
+	w = serve(router, http.MethodGet, "/tasks/search?q=DRAFT+status:pending&limit=5", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var found struct {
+		Tasks []map[string]interface{} `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &found))
+	require.Len(t, found.Tasks, 1)
+	assert.Equal(t, id, found.Tasks[0]["id"])
+	w = serve(router, http.MethodGet, "/tasks/search?q=draft+-status:open", "", nil)
+	assert.JSONEq(t, `{"tasks": []}`, w.Body.String())
+}
+
+func TestTaskController_RunBatch(t *testing.T) {
+	router, id := newTaskRouter(t)
+	type batchResponse struct {
+		Committed bool `json:"committed"`
+		Results   []struct {
+			Status int                    `json:"status"`
+			Task   map[string]interface{} `json:"task"`
+			Error  string                 `json:"error"`
+		} `json:"results"`
+	}
+
+	w := serve(router, http.MethodPost, "/tasks/batch", `{"operations": []}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodPost, "/tasks/batch", `{"operations": [{"op": "rename", "id": "`+id+`"}]}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// The delete fails, so the create before it is undone.
+	w = serve(router, http.MethodPost, "/tasks/batch", `{"operations": [
+		{"op": "create", "task": {"title": "Lost", "status": "Pending"}},
+		{"op": "delete", "id": "`+primitive.NewObjectID().Hex()+`"},
+		{"op": "update", "id": "`+id+`", "task": {"title": "Never", "status": "Pending"}}
+	]}`, nil)
+	require.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+	var failed batchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failed))
+	assert.False(t, failed.Committed)
+	require.Len(t, failed.Results, 3)
+	assert.Equal(t, []int{http.StatusFailedDependency, http.StatusNotFound, http.StatusFailedDependency},
+		[]int{failed.Results[0].Status, failed.Results[1].Status, failed.Results[2].Status})
+	assert.Nil(t, failed.Results[0].Task)
+
+	// --- ASSERT ---
+	w = serve(router, http.MethodPost, "/tasks/batch", `{"operations": [
+		{"op": "create", "task": {"title": "Imported", "status": "Pending"}},
+		{"op": "update", "id": "`+id+`", "version": 1, "task": {"title": "Final", "status": "Pending"}},
+		{"op": "delete", "id": "`+id+`", "version": 2}
+	]}`, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var done batchResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &done))
+	assert.True(t, done.Committed)
+	require.Len(t, done.Results, 3)
+	assert.Equal(t, http.StatusCreated, done.Results[0].Status)
+	assert.Equal(t, "Imported", done.Results[0].Task["title"])
+	assert.Equal(t, http.StatusOK, done.Results[1].Status)
+	assert.Equal(t, http.StatusNoContent, done.Results[2].Status)
+	w = serve(router, http.MethodGet, "/tasks/"+id, "", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// TestTaskController_RunBatchWithoutTransactions expects a batch to be
+// refused with 501 when the database cannot run it as one unit.
+func TestTaskController_RunBatchWithoutTransactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repos := repositories.NewMemoryRepositories()
+	usecase := usecases.NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ownerID := primitive.NewObjectID()
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("user_id", ownerID.Hex()) })
+	router.POST("/tasks/batch", controllers.NewTaskController(usecase).RunBatch)
+
+	w := serve(router, http.MethodPost, "/tasks/batch", `{"operations": [{"op": "create", "task": {"title": "Imported", "status": "Pending"}}]}`, nil)
+	tasks, err := usecase.GetUserTasks(context.Background(), ownerID)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+	assert.JSONEq(t, `{"error": "transactions are not supported by this database"}`, w.Body.String())
+	assert.Empty(t, tasks)
+}
+
+func TestTaskController_ImportExport(t *testing.T) {
+	router, id := newTaskRouter(t)
+	type importResponse struct {
+		Created int `json:"created"`
+		Skipped int `json:"skipped"`
+		Failed  int `json:"failed"`
+		Results []struct {
+			Row    int    `json:"row"`
+			Status string `json:"status"`
+			TaskID string `json:"task_id"`
+			Error  string `json:"error"`
+		} `json:"results"`
+		Error string `json:"error"`
+	}
+	importFile := func(path, contentType, body string) (int, importResponse) {
+		w := serve(router, http.MethodPost, path, body, map[string]string{"Content-Type": contentType})
+		var report importResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report), w.Body.String())
+		return w.Code, report
+	}
+	csvFile := "external_id,title,status,due_date\n" +
+		"T-1,Imported,Pending,2025-03-01T09:00:00Z\n" +
+		"T-2,,Pending,\n" +
+		"T-3,Late,Pending,tomorrow\n"
+
+	w := serve(router, http.MethodPost, "/tasks/import", csvFile, map[string]string{"Content-Type": "text/plain"})
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	code, report := importFile("/tasks/import?format=json", "text/csv", `[{"title": "Cut short", "status": "Pending"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.NotEmpty(t, report.Error)
+	assert.Equal(t, 1, report.Created, "rows before the problem are kept")
+
+	// A dry run reports every row and stores nothing.
+	code, report = importFile("/tasks/import?dry_run=true", "text/csv", csvFile)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, [3]int{1, 0, 2}, [3]int{report.Created, report.Skipped, report.Failed})
+	require.Len(t, report.Results, 3)
+	assert.Equal(t, []int{2, 3, 4}, []int{report.Results[0].Row, report.Results[1].Row, report.Results[2].Row})
+	assert.Equal(t, "title is required", report.Results[1].Error)
+	assert.Equal(t, "due_date must be an RFC 3339 timestamp", report.Results[2].Error)
+	assert.Empty(t, report.Results[0].TaskID)
+
+	code, report = importFile("/tasks/import", "text/csv; charset=utf-8", csvFile)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, 1, report.Created)
+	w = serve(router, http.MethodGet, "/tasks/"+report.Results[0].TaskID, "", nil)
+	assert.Contains(t, w.Body.String(), `"external_id":"T-1"`)
+
+	// A calendar from another tool: folded lines, escapes, a zoned due date
+	// and an alarm whose properties are not the task's.
+	code, report = importFile("/tasks/import", "text/calendar", strings.Join([]string{
+		"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Other//EN",
+		"BEGIN:VTODO", "UID:cal-1", "SUMMARY:Call the\\, plumber",
+		"DESCRIPTION:First line\\nsecond", " line", "DUE;TZID=Europe/Paris:20250301T090000",
+		"STATUS:COMPLETED",
+		"BEGIN:VALARM", "ACTION:DISPLAY", "DESCRIPTION:Reminder", "END:VALARM",
+		"END:VTODO", "BEGIN:VEVENT", "UID:event-1", "SUMMARY:Not a task", "END:VEVENT",
+		"END:VCALENDAR", "",
+	}, "\r\n"))
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, [3]int{1, 0, 0}, [3]int{report.Created, report.Skipped, report.Failed})
+	w = serve(router, http.MethodGet, "/tasks/"+report.Results[0].TaskID, "", nil)
+	var fromCalendar map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fromCalendar))
+	assert.Equal(t, "Call the, plumber", fromCalendar["title"])
+	assert.Equal(t, "First line\nsecondline", fromCalendar["description"])
+	assert.Equal(t, "2025-03-01T08:00:00Z", fromCalendar["due_date"])
+	assert.Equal(t, usecases.StatusCompleted, fromCalendar["status"])
+
+	// --- ASSERT ---
+	// Every export names each task so that importing it back skips it.
+	for _, format := range []string{"json", "csv", "ics"} {
+		w = serve(router, http.MethodGet, "/tasks/export?format="+format, "", nil)
+		require.Equal(t, http.StatusOK, w.Code, format)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "tasks."+format)
+		assert.Contains(t, w.Body.String(), id, format)
+		assert.Contains(t, w.Body.String(), "T-1", format)
+		code, report = importFile("/tasks/import?format="+format, "", w.Body.String())
+		require.Equal(t, http.StatusOK, code, format)
+		assert.Equal(t, [3]int{0, 4, 0}, [3]int{report.Created, report.Skipped, report.Failed}, format)
+	}
+	w = serve(router, http.MethodGet, "/tasks/export?format=xml", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
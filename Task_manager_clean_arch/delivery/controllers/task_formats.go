@@ -0,0 +1,342 @@
+package controllers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/usecases"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// File formats of GET /tasks/export and POST /tasks/import.
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+	formatICS  = "ics"
+)
+
+// formatMediaTypes are the media types files of each format are sent and
+// recognised with.
+var formatMediaTypes = map[string]string{
+	formatCSV:  "text/csv",
+	formatJSON: "application/json",
+	formatICS:  "text/calendar",
+}
+
+// formatOfMediaType returns the format sent as mediaType, or "" if there is
+// none.
+func formatOfMediaType(mediaType string) string {
+	for format, known := range formatMediaTypes {
+		if strings.EqualFold(mediaType, known) {
+			return format
+		}
+	}
+	return ""
+}
+
+var errUnknownFormat = errors.New("format must be csv, json or ics")
+
+// csvColumns are the columns of an exported CSV file. An imported file may
+// have them in any order, and may also have parent_id, blocked_by and
+// project_id; only title is required.
+var csvColumns = []string{"external_id", "title", "description", "due_date", "status", "tags", "recurrence", "time_zone"}
+
+var csvImportColumns = append([]string{"parent_id", "blocked_by", "project_id"}, csvColumns...)
+
+// rowError is a problem with the content of one row of an import file.
+type rowError string
+
+func (e rowError) Error() string { return string(e) }
+
+// exportID is the external ID a task is exported with: the one it was
+// imported with, or its own ID, so that importing the file again skips it.
+func exportID(task *domain.Task) string {
+	if task.ExternalID != "" {
+		return task.ExternalID
+	}
+	return task.ID.Hex()
+}
+
+func toTaskExportRecord(task *domain.Task) dto.TaskExportRecord {
+	record := dto.TaskExportRecord{
+		ExternalID:  exportID(task),
+		Title:       task.Title,
+		Description: task.Description,
+		DueDate:     task.Duedate,
+		Status:      task.Status,
+		Tags:        task.Tags,
+	}
+	if task.Recurrence != nil {
+		record.Recurrence, record.TimeZone = task.Recurrence.Rule, task.Recurrence.TimeZone
+	}
+	return record
+}
+
+// toImportRow checks an imported task against the rules of a create request
+// and maps it to a domain task.
+func toImportRow(row int, input *dto.TaskImportRow) *usecases.ImportRow {
+	result := &usecases.ImportRow{Row: row, ExternalID: input.ExternalID}
+	if err := binding.Validator.ValidateStruct(&input.TaskRequest); err != nil {
+		result.Err = validationRowError(err)
+		return result
+	}
+	task, err := toDomainTask(&input.TaskRequest)
+	if err != nil {
+		result.Err = rowError(err.Error())
+		return result
+	}
+	result.Task = task
+	return result
+}
+
+// validationRowError words the binding errors of a row like "title is
+// required".
+func validationRowError(err error) error {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return rowError(err.Error())
+	}
+	messages := make([]string, len(fieldErrors))
+	for i, fe := range fieldErrors {
+		field := strings.ToLower(fe.Field())
+		if fe.Tag() == "required" {
+			messages[i] = field + " is required"
+		} else {
+			messages[i] = field + " is invalid"
+		}
+	}
+	return rowError(strings.Join(messages, "; "))
+}
+
+// splitList splits a comma-separated cell, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// newImportReader reads the rows of an import file in format.
+func newImportReader(format string, r io.Reader) (usecases.ImportRowReader, error) {
+	switch format {
+	case formatCSV:
+		return newCSVImportReader(r)
+	case formatJSON:
+		return newJSONImportReader(r)
+	case formatICS:
+		return newICSImportReader(r)
+	default:
+		return nil, errUnknownFormat
+	}
+}
+
+// csvImportReader reads a CSV file with a header row naming its columns.
+// Rows are numbered by the line they start on.
+type csvImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVImportReader(r io.Reader) (*csvImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file has no header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, known := columns[name]; known {
+			return nil, fmt.Errorf("column %q appears twice", name)
+		}
+		if !slices.Contains(csvImportColumns, name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("the header has no title column")
+	}
+	return &csvImportReader{reader: reader, columns: columns}, nil
+}
+
+func (r *csvImportReader) Read() (*usecases.ImportRow, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	line, _ := r.reader.FieldPos(0)
+	cell := func(column string) string {
+		if i, ok := r.columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	input := dto.TaskImportRow{
+		ExternalID: cell("external_id"),
+		TaskRequest: dto.TaskRequest{
+			Title:       cell("title"),
+			Description: cell("description"),
+			Status:      cell("status"),
+			ParentID:    cell("parent_id"),
+			BlockedBy:   splitList(cell("blocked_by")),
+			Tags:        splitList(cell("tags")),
+			ProjectID:   cell("project_id"),
+			Recurrence:  cell("recurrence"),
+			TimeZone:    cell("time_zone"),
+		},
+	}
+	if due := cell("due_date"); due != "" {
+		input.DueDate, err = time.Parse(time.RFC3339, due)
+		if err != nil {
+			return &usecases.ImportRow{Row: line, ExternalID: input.ExternalID, Err: rowError("due_date must be an RFC 3339 timestamp")}, nil
+		}
+	}
+	return toImportRow(line, &input), nil
+}
+
+// jsonImportReader reads a JSON array of dto.TaskImportRow objects one at a
+// time. Rows are numbered from 1.
+type jsonImportReader struct {
+	decoder *json.Decoder
+	row     int
+}
+
+func newJSONImportReader(r io.Reader) (*jsonImportReader, error) {
+	decoder := json.NewDecoder(r)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return nil, errors.New("the file must hold a JSON array of tasks")
+	}
+	return &jsonImportReader{decoder: decoder}, nil
+}
+
+func (r *jsonImportReader) Read() (*usecases.ImportRow, error) {
+	if !r.decoder.More() {
+		// Only the closing bracket ends the file cleanly.
+		if _, err := r.decoder.Token(); err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	r.row++
+	var input dto.TaskImportRow
+	err := r.decoder.Decode(&input)
+
+	// A value of the wrong type spoils its row but not the rest of the file.
+	var typeErr *json.UnmarshalTypeError
+	var timeErr *time.ParseError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return &usecases.ImportRow{Row: r.row, Err: rowError("the task must be a JSON object")}, nil
+	case errors.As(err, &typeErr):
+		return &usecases.ImportRow{Row: r.row, ExternalID: input.ExternalID, Err: rowError(typeErr.Field + " cannot be a JSON " + typeErr.Value)}, nil
+	case errors.As(err, &timeErr):
+		return &usecases.ImportRow{Row: r.row, ExternalID: input.ExternalID, Err: rowError("due_date must be an RFC 3339 timestamp")}, nil
+	case err != nil:
+		return nil, err
+	}
+	return toImportRow(r.row, &input), nil
+}
+
+// taskEncoder writes exported tasks in one format, a page at a time. Close
+// ends the file and flushes it.
+type taskEncoder interface {
+	Encode(tasks []domain.Task) error
+	Close() error
+}
+
+func newTaskEncoder(format string, w io.Writer) taskEncoder {
+	switch format {
+	case formatCSV:
+		return newCSVTaskEncoder(w)
+	case formatICS:
+		return newICSWriter(w)
+	default:
+		return newJSONTaskEncoder(w)
+	}
+}
+
+type csvTaskEncoder struct {
+	writer *csv.Writer
+}
+
+func newCSVTaskEncoder(w io.Writer) *csvTaskEncoder {
+	writer := csv.NewWriter(w)
+	_ = writer.Write(csvColumns)
+	return &csvTaskEncoder{writer: writer}
+}
+
+func (e *csvTaskEncoder) Encode(tasks []domain.Task) error {
+	for i := range tasks {
+		record := toTaskExportRecord(&tasks[i])
+		dueDate := ""
+		if !record.DueDate.IsZero() {
+			dueDate = record.DueDate.Format(time.RFC3339)
+		}
+		row := []string{record.ExternalID, record.Title, record.Description, dueDate, record.Status,
+			strings.Join(record.Tags, ","), record.Recurrence, record.TimeZone}
+		if err := e.writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *csvTaskEncoder) Close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonTaskEncoder writes a JSON array of dto.TaskExportRecord objects.
+type jsonTaskEncoder struct {
+	writer *bufio.Writer
+	empty  bool
+}
+
+func newJSONTaskEncoder(w io.Writer) *jsonTaskEncoder {
+	writer := bufio.NewWriter(w)
+	_ = writer.WriteByte('[')
+	return &jsonTaskEncoder{writer: writer, empty: true}
+}
+
+func (e *jsonTaskEncoder) Encode(tasks []domain.Task) error {
+	for i := range tasks {
+		data, err := json.Marshal(toTaskExportRecord(&tasks[i]))
+		if err != nil {
+			return err
+		}
+		if !e.empty {
+			_ = e.writer.WriteByte(',')
+		}
+		e.empty = false
+		if _, err := e.writer.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *jsonTaskEncoder) Close() error {
+	_, _ = e.writer.WriteString("]\n")
+	return e.writer.Flush()
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"taskmanager/delivery/dto"
+	"taskmanager/usecases"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// Live task streams. The same events go out as Server-Sent Events or as
+// WebSocket messages; streamWriter hides the difference.
+
+// streamWriteTimeout is how long a client may take to accept one message
+// before it is disconnected.
+const streamWriteTimeout = 10 * time.Second
+
+// Reasons given by the reconnect event.
+const (
+	reconnectLagging      = "lagging"
+	reconnectTokenExpired = "token_expired"
+	reconnectShutdown     = "shutdown"
+)
+
+type streamWriter interface {
+	write(event dto.TaskStreamEvent) error
+	heartbeat() error
+}
+
+// sseWriter writes the text/event-stream format, flushing every message.
+type sseWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	return &sseWriter{w: w, controller: http.NewResponseController(w)}
+}
+
+func (s *sseWriter) write(event dto.TaskStreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.deadline()
+	if event.ID != "" {
+		if _, err := fmt.Fprintf(s.w, "id: %s\n", event.ID); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event.Event, data); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// heartbeat writes a comment, which EventSource ignores.
+func (s *sseWriter) heartbeat() error {
+	s.deadline()
+	if _, err := fmt.Fprint(s.w, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+func (s *sseWriter) flush() error {
+	return s.controller.Flush()
+}
+
+// deadline bounds the next write. Not every ResponseWriter supports
+// deadlines, and those that do not are left without one.
+func (s *sseWriter) deadline() {
+	_ = s.controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+}
+
+// wsWriter sends every message as a JSON text frame.
+type wsWriter struct {
+	conn *websocket.Conn
+}
+
+func (s *wsWriter) write(event dto.TaskStreamEvent) error {
+	if err := s.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	return websocket.JSON.Send(s.conn, event)
+}
+
+func (s *wsWriter) heartbeat() error {
+	return s.write(dto.TaskStreamEvent{Event: "heartbeat"})
+}
+
+func toTaskStreamEvent(event *usecases.StreamEvent) dto.TaskStreamEvent {
+	task := toTaskResponse(&event.Task)
+	return dto.TaskStreamEvent{
+		ID:             event.ID,
+		Event:          event.Type,
+		OccurredAt:     &event.OccurredAt,
+		ActorID:        event.ActorID.Hex(),
+		PreviousStatus: event.PreviousStatus,
+		Task:           &task,
+	}
+}
+
+// pump writes the subscription's events until ctx ends, the client stops
+// accepting them, or the stream has to end. A stream that ends while the
+// client is still there says why in a reconnect event. expiresAt is when
+// the caller's access token expires, since a stream should not outlive it.
+func (sc *StreamController) pump(ctx context.Context, subscription *usecases.TaskSubscription, w streamWriter, expiresAt time.Time) {
+	if subscription.Reset {
+		if err := w.write(dto.TaskStreamEvent{Event: "reset"}); err != nil {
+			return
+		}
+	}
+	for i := range subscription.Missed {
+		if err := w.write(toTaskStreamEvent(&subscription.Missed[i])); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(sc.heartbeat)
+	defer heartbeat.Stop()
+	var expired <-chan time.Time
+	if !expiresAt.IsZero() {
+		timer := time.NewTimer(time.Until(expiresAt))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				reason := reconnectShutdown
+				if subscription.Lagged() {
+					reason = reconnectLagging
+				}
+				_ = w.write(dto.TaskStreamEvent{Event: "reconnect", Reason: reason})
+				return
+			}
+			if err := w.write(toTaskStreamEvent(&event)); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := w.heartbeat(); err != nil {
+				return
+			}
+		case <-expired:
+			_ = w.write(dto.TaskStreamEvent{Event: "reconnect", Reason: reconnectTokenExpired})
+			return
+		}
+	}
+}
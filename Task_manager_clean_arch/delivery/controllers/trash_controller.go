@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"taskmanager/delivery/dto"
+	"taskmanager/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ITrashController interface {
+	EmptyTrash(c *gin.Context)
+}
+
+type TrashController struct {
+	trashUsecase usecases.ITrashUsecase
+}
+
+func NewTrashController(trashUsecase usecases.ITrashUsecase) *TrashController {
+	return &TrashController{trashUsecase: trashUsecase}
+}
+
+// EmptyTrash permanently removes every task in the trash of the user in the
+// path.
+func (tc *TrashController) EmptyTrash(c *gin.Context) {
+	adminIDHex, _ := c.Get("user_id")
+	adminID, _ := primitive.ObjectIDFromHex(adminIDHex.(string))
+	purged, err := tc.trashUsecase.EmptyTrash(c.Request.Context(), c.Param("id"), adminID)
+	if err != nil {
+		if errors.Is(err, usecases.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to empty trash"})
+		return
+	}
+	c.JSON(http.StatusOK, dto.EmptyTrashResponse{Purged: purged})
+}
@@ -0,0 +1,168 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"taskmanager/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IUserAdminController interface {
+	ListUsers(c *gin.Context)
+	GetUser(c *gin.Context)
+	PromoteUser(c *gin.Context)
+	DemoteUser(c *gin.Context)
+	DisableUser(c *gin.Context)
+	EnableUser(c *gin.Context)
+	UnlockUser(c *gin.Context)
+	DeleteUser(c *gin.Context)
+	ResetPassword(c *gin.Context)
+}
+
+type UserAdminController struct {
+	userAdminUsecase usecases.IUserAdminUsecase
+}
+
+func NewUserAdminController(userAdminUsecase usecases.IUserAdminUsecase) *UserAdminController {
+	return &UserAdminController{userAdminUsecase: userAdminUsecase}
+}
+
+// respondUserAdminError maps a user administration error to its HTTP status.
+func respondUserAdminError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidUserQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrInvalidReassignment):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// ListUsers lists users by username, optionally only those whose username
+// contains search, who have a role or who have a status.
+func (uc *UserAdminController) ListUsers(c *gin.Context) {
+	query := repositories.UserQuery{
+		Search: c.Query("search"),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+		Cursor: c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		query.Limit = n
+	}
+	users, nextCursor, err := uc.userAdminUsecase.ListUsers(c.Request.Context(), query)
+	if err != nil {
+		respondUserAdminError(c, err, "Failed to retrieve users")
+		return
+	}
+	response := dto.UserListResponse{Users: make([]dto.UserResponse, len(users)), NextCursor: nextCursor}
+	for i := range users {
+		response.Users[i] = toUserResponse(&users[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (uc *UserAdminController) GetUser(c *gin.Context) {
+	user, err := uc.userAdminUsecase.GetUser(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondUserAdminError(c, err, "Failed to retrieve user")
+		return
+	}
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// PromoteUser gives the user in the path the admin role.
+func (uc *UserAdminController) PromoteUser(c *gin.Context) {
+	actorIDHex, _ := c.Get("user_id")
+	actorID, _ := primitive.ObjectIDFromHex(actorIDHex.(string))
+	user, err := uc.userAdminUsecase.PromoteUser(c.Request.Context(), c.Param("id"), actorID)
+	if err != nil {
+		respondUserAdminError(c, err, "Failed to promote user")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User promoted", "user": toUserResponse(user)})
+}
+
+func (uc *UserAdminController) DemoteUser(c *gin.Context) {
+	uc.changeUser(c, uc.userAdminUsecase.DemoteUser, "Failed to demote user")
+}
+
+func (uc *UserAdminController) DisableUser(c *gin.Context) {
+	uc.changeUser(c, uc.userAdminUsecase.DisableUser, "Failed to disable user")
+}
+
+func (uc *UserAdminController) EnableUser(c *gin.Context) {
+	uc.changeUser(c, uc.userAdminUsecase.EnableUser, "Failed to enable user")
+}
+
+func (uc *UserAdminController) UnlockUser(c *gin.Context) {
+	uc.changeUser(c, uc.userAdminUsecase.UnlockUser, "Failed to unlock user")
+}
+
+// changeUser applies a change to the user in the path and responds with the
+// changed user.
+func (uc *UserAdminController) changeUser(c *gin.Context, change func(context.Context, string, primitive.ObjectID) (*domain.User, error), message string) {
+	actorIDHex, _ := c.Get("user_id")
+	actorID, _ := primitive.ObjectIDFromHex(actorIDHex.(string))
+	user, err := change(c.Request.Context(), c.Param("id"), actorID)
+	if err != nil {
+		respondUserAdminError(c, err, message)
+		return
+	}
+	c.JSON(http.StatusOK, toUserResponse(user))
+}
+
+// DeleteUser deletes the user in the path. The request must say what
+// happens to their tasks: reassign_to names the user they go to, and
+// delete_tasks=true deletes them for good.
+func (uc *UserAdminController) DeleteUser(c *gin.Context) {
+	reassignTo := c.Query("reassign_to")
+	deleteTasks, _ := strconv.ParseBool(c.Query("delete_tasks"))
+	if (reassignTo == "") == !deleteTasks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either reassign_to or delete_tasks=true is required"})
+		return
+	}
+	actorIDHex, _ := c.Get("user_id")
+	actorID, _ := primitive.ObjectIDFromHex(actorIDHex.(string))
+	tasks, err := uc.userAdminUsecase.DeleteUser(c.Request.Context(), c.Param("id"), reassignTo, actorID)
+	if err != nil {
+		respondUserAdminError(c, err, "Failed to delete user")
+		return
+	}
+	if deleteTasks {
+		c.JSON(http.StatusOK, dto.UserDeletionResponse{TasksDeleted: tasks})
+		return
+	}
+	c.JSON(http.StatusOK, dto.UserDeletionResponse{TasksReassigned: tasks})
+}
+
+func (uc *UserAdminController) ResetPassword(c *gin.Context) {
+	actorIDHex, _ := c.Get("user_id")
+	actorID, _ := primitive.ObjectIDFromHex(actorIDHex.(string))
+	password, err := uc.userAdminUsecase.ResetPassword(c.Request.Context(), c.Param("id"), actorID)
+	if err != nil {
+		respondUserAdminError(c, err, "Failed to reset password")
+		return
+	}
+	c.JSON(http.StatusOK, dto.PasswordResetResponse{TemporaryPassword: password})
+}
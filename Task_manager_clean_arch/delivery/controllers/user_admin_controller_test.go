@@ -0,0 +1,102 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"taskmanager/delivery/controllers"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/usecases"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAdminController_ManageUsers(t *testing.T) {
+	f := newFixture(t)
+	roleUsecase := f.roles
+	userAdmin := usecases.NewUserAdminUsecase(f.repos, infrastructure.NewPasswordService(), roleUsecase)
+	admins := controllers.NewUserAdminController(userAdmin)
+	router := f.router
+	router.PUT("/admin/demote/:id", infrastructure.RequirePermission(roleUsecase, domain.PermUserPromote), admins.DemoteUser)
+	manage := router.Group("/admin/users", infrastructure.RequirePermission(roleUsecase, domain.PermUserManage))
+	manage.GET("", admins.ListUsers)
+	manage.GET("/:id", admins.GetUser)
+	manage.DELETE("/:id", admins.DeleteUser)
+	manage.POST("/:id/disable", admins.DisableUser)
+	manage.POST("/:id/enable", admins.EnableUser)
+	manage.POST("/:id/unlock", admins.UnlockUser)
+	manage.POST("/:id/password-reset", admins.ResetPassword)
+	admin, manager := as(f.admin), as(f.manager)
+	userPath := "/admin/users/" + f.user.Hex()
+
+	w := serve(router, http.MethodGet, "/admin/users", "", manager)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(router, http.MethodGet, "/admin/users?limit=0", "", admin)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodGet, "/admin/users?status=asleep", "", admin)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodPut, "/admin/demote/"+f.admin.Hex(), "", admin)
+	assert.Equal(t, http.StatusConflict, w.Code, "the last admin stays one")
+	w = serve(router, http.MethodPost, userPath+"/disable", "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var disabled dto.UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &disabled))
+	w = serve(router, http.MethodGet, "/admin/users?status=disabled", "", admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	var list dto.UserListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	w = serve(router, http.MethodPost, userPath+"/unlock", "", manager)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(router, http.MethodPost, userPath+"/unlock", "", admin)
+	assert.Equal(t, http.StatusOK, w.Code, "unlocking a user who is not locked out is harmless")
+	w = serve(router, http.MethodPost, userPath+"/password-reset", "", admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	var reset dto.PasswordResetResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reset))
+	w = serve(router, http.MethodDelete, userPath, "", admin)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "what happens to the tasks must be said")
+	w = serve(router, http.MethodDelete, userPath+"?reassign_to=nobody", "", admin)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = serve(router, http.MethodDelete, userPath+"?reassign_to="+f.manager.Hex(), "", admin)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// --- ASSERT ---
+	assert.NotNil(t, disabled.DisabledAt)
+	require.Len(t, list.Users, 1)
+	assert.Equal(t, domain.RoleUser, list.Users[0].Username)
+	assert.Empty(t, list.NextCursor)
+	assert.NotEmpty(t, reset.TemporaryPassword)
+	assert.JSONEq(t, `{"tasks_reassigned":0,"tasks_deleted":0}`, w.Body.String())
+	w = serve(router, http.MethodGet, userPath, "", admin)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUserAdminController_PromoteUser(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	promoter := f.addUser(t, "promoter")
+	_, err := f.roles.CreateRole(ctx, &domain.Role{Name: "promoter", Permissions: []string{domain.PermUserPromote}}, f.admin)
+	require.NoError(t, err)
+	_, err = f.roles.SetUserRoles(ctx, promoter.Hex(), []string{"promoter"}, f.admin)
+	require.NoError(t, err)
+	admins := controllers.NewUserAdminController(usecases.NewUserAdminUsecase(f.repos, infrastructure.NewPasswordService(), f.roles))
+	router := f.router
+	router.PUT("/admin/promote/:id", infrastructure.RequirePermission(f.roles, domain.PermUserPromote), admins.PromoteUser)
+	promoterPath := "/admin/promote/" + promoter.Hex()
+
+	selfPromoted := serve(router, http.MethodPut, promoterPath, "", as(promoter))
+	promoted := serve(router, http.MethodPut, promoterPath, "", as(f.admin))
+
+	// --- ASSERT ---
+	assert.Equal(t, http.StatusForbidden, selfPromoted.Code, "user:promote alone cannot hand out the admin role")
+	require.Equal(t, http.StatusOK, promoted.Code, promoted.Body.String())
+	var body struct {
+		User dto.UserResponse `json:"user"`
+	}
+	require.NoError(t, json.Unmarshal(promoted.Body.Bytes(), &body))
+	assert.Equal(t, []string{"promoter", domain.RoleAdmin}, body.User.Roles)
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"taskmanager/delivery/dto"
+	"taskmanager/usecases"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IUserController interface {
+	Register(c *gin.Context)
+	Login(c *gin.Context)
+	Refresh(c *gin.Context)
+	Logout(c *gin.Context)
+	ChangePassword(c *gin.Context)
+	RequestPasswordReset(c *gin.Context)
+	ConfirmPasswordReset(c *gin.Context)
+}
+
+func toTokenResponse(tokens *usecases.TokenPair) dto.TokenResponse {
+	return dto.TokenResponse{
+		Token:            tokens.AccessToken,
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+	}
+}
+
+type UserController struct {
+	userUsecase usecases.IUserUsecase
+}
+
+func NewUserController(userUsecase usecases.IUserUsecase) *UserController {
+	return &UserController{userUsecase: userUsecase}
+}
+
+func (uc *UserController) Register(c *gin.Context) {
+	var input dto.RegisterRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	createdUser, err := uc.userUsecase.Register(c.Request.Context(), input.Username, input.Password)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Map the result to our response DTO
+	response := toUserResponse(createdUser)
+	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully", "user": response})
+}
+
+func (uc *UserController) Login(c *gin.Context) {
+	var input dto.LoginRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	tokens, err := uc.userUsecase.Login(c.Request.Context(), input.Username, input.Password, c.ClientIP())
+	if err != nil {
+		var throttled *usecases.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			respondThrottled(c, err, throttled.RetryAfter)
+		case errors.Is(err, usecases.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			_ = c.Error(err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, toTokenResponse(tokens))
+}
+
+func (uc *UserController) Refresh(c *gin.Context) {
+	var input dto.RefreshRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	tokens, err := uc.userUsecase.Refresh(c.Request.Context(), input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidRefreshToken) || errors.Is(err, usecases.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+	c.JSON(http.StatusOK, toTokenResponse(tokens))
+}
+
+func (uc *UserController) Logout(c *gin.Context) {
+	var input dto.RefreshRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	err := uc.userUsecase.Logout(c.Request.Context(), input.RefreshToken)
+	if err != nil {
+		if errors.Is(err, usecases.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// respondThrottled answers a request refused until retryAfter has passed.
+func respondThrottled(c *gin.Context, err error, retryAfter time.Duration) {
+	// Retry-After is in whole seconds, rounded up.
+	c.Header("Retry-After", strconv.Itoa(int((retryAfter+time.Second-1)/time.Second)))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+}
+
+// respondPasswordError answers a failed password change or reset. Errors
+// the client cannot fix are logged by gin and reported with message.
+func respondPasswordError(c *gin.Context, err error, message string) {
+	var throttled *usecases.LoginThrottledError
+	var resetThrottled *usecases.ResetThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		respondThrottled(c, err, throttled.RetryAfter)
+	case errors.As(err, &resetThrottled):
+		respondThrottled(c, err, resetThrottled.RetryAfter)
+	case errors.Is(err, usecases.ErrInvalidResetToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrWrongPassword):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrInvalidPassword):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// ChangePassword ends every session of the user, so it answers with a new
+// token pair for the caller.
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	var input dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	tokens, err := uc.userUsecase.ChangePassword(c.Request.Context(), userID, input.CurrentPassword, input.NewPassword, c.ClientIP())
+	if err != nil {
+		respondPasswordError(c, err, "Failed to change password")
+		return
+	}
+	c.JSON(http.StatusOK, toTokenResponse(tokens))
+}
+
+// RequestPasswordReset answers the same whether or not the username exists.
+func (uc *UserController) RequestPasswordReset(c *gin.Context) {
+	var input dto.PasswordResetRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if err := uc.userUsecase.RequestPasswordReset(c.Request.Context(), input.Username, c.ClientIP()); err != nil {
+		respondPasswordError(c, err, "Failed to request a password reset")
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the account exists, a password reset token has been sent"})
+}
+
+func (uc *UserController) ConfirmPasswordReset(c *gin.Context) {
+	var input dto.PasswordResetConfirmRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+	if err := uc.userUsecase.ConfirmPasswordReset(c.Request.Context(), input.Token, input.NewPassword); err != nil {
+		respondPasswordError(c, err, "Failed to reset password")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
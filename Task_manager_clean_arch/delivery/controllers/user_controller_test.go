@@ -0,0 +1,124 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"taskmanager/delivery/controllers"
+	"taskmanager/delivery/dto"
+	"taskmanager/infrastructure"
+	"taskmanager/mocks"
+	"taskmanager/usecases"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserController_Passwords(t *testing.T) {
+	f := newFixture(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	mailer := new(mocks.IMailer)
+	sent := make(chan infrastructure.Mail, 2)
+	mailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent <- args.Get(1).(infrastructure.Mail)
+	}).Return(nil)
+	userUsecase := usecases.NewUserUsecase(f.repos.Users, f.repos.Tokens, newFakePasswordService(), infrastructure.NewJWTService(),
+		usecases.WithMailer(mailer))
+	user, err := userUsecase.Register(context.Background(), "alice", "old-password")
+	require.NoError(t, err)
+	users := controllers.NewUserController(userUsecase)
+	router := f.router
+	router.POST("/auth/login", users.Login)
+	router.POST("/auth/password-reset", users.RequestPasswordReset)
+	router.POST("/auth/password-reset/confirm", users.ConfirmPasswordReset)
+	router.PUT("/me/password", users.ChangePassword)
+	alice := as(user.ID)
+
+	w := serve(router, http.MethodPut, "/me/password", `{"current_password": "guess", "new_password": "new-password"}`, alice)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = serve(router, http.MethodPut, "/me/password", `{"current_password": "old-password"}`, alice)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodPut, "/me/password", `{"current_password": "old-password", "new_password": "new-password"}`, alice)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var tokens dto.TokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+	unknown := serve(router, http.MethodPost, "/auth/password-reset", `{"username": "nobody"}`, nil)
+	known := serve(router, http.MethodPost, "/auth/password-reset", `{"username": "alice"}`, nil)
+	var mail infrastructure.Mail
+	select {
+	case mail = <-sent:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no reset token was mailed")
+	}
+	token := strings.Split(mail.Body, "\n")[2]
+	w = serve(router, http.MethodPost, "/auth/password-reset/confirm", `{"token": "bogus", "new_password": "reset-password"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodPost, "/auth/password-reset/confirm", `{"token": "`+token+`", "new_password": "reset-password"}`, nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	replay := serve(router, http.MethodPost, "/auth/password-reset/confirm", `{"token": "`+token+`", "new_password": "other"}`, nil)
+
+	// --- ASSERT ---
+	assert.NotEmpty(t, tokens.Token)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, http.StatusAccepted, unknown.Code)
+	assert.Equal(t, known.Code, unknown.Code, "unknown usernames are not revealed")
+	assert.Equal(t, known.Body.String(), unknown.Body.String())
+	assert.Equal(t, http.StatusBadRequest, replay.Code)
+	w = serve(router, http.MethodPost, "/auth/login", `{"username": "alice", "password": "new-password"}`, nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = serve(router, http.MethodPost, "/auth/login", `{"username": "alice", "password": "reset-password"}`, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestUserController_LoginThrottling(t *testing.T) {
+	f := newFixture(t)
+	t.Setenv("JWT_SECRET", "test-secret")
+	policy := usecases.LoginPolicy{Username: usecases.LoginLimit{LockoutThreshold: 3}, IP: usecases.LoginLimit{FreeAttempts: 10},
+		BaseDelay: 30 * time.Second, MaxDelay: time.Minute, LockoutDuration: time.Hour}
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	userUsecase := usecases.NewUserUsecase(f.repos.Users, f.repos.Tokens, newFakePasswordService(), infrastructure.NewJWTService(),
+		usecases.WithLoginThrottle(f.repos.LoginAttempts, policy), usecases.WithUserClock(func() time.Time { return now }))
+	alice, err := userUsecase.Register(context.Background(), "alice", "password")
+	require.NoError(t, err)
+	users := controllers.NewUserController(userUsecase)
+	router := f.router
+	router.POST("/auth/login", users.Login)
+	router.PUT("/me/password", users.ChangePassword)
+
+	wrong := serve(router, http.MethodPost, "/auth/login", `{"username": "alice", "password": "guess"}`, nil)
+	throttled := serve(router, http.MethodPost, "/auth/login", `{"username": "alice", "password": "password"}`, nil)
+	unknown := serve(router, http.MethodPost, "/auth/login", `{"username": "nobody", "password": "guess"}`, nil)
+	unknownThrottled := serve(router, http.MethodPost, "/auth/login", `{"username": "nobody", "password": "guess"}`, nil)
+	change := serve(router, http.MethodPut, "/me/password", `{"current_password": "password", "new_password": "new-password"}`, as(alice.ID))
+
+	// --- ASSERT ---
+	assert.Equal(t, http.StatusUnauthorized, wrong.Code)
+	assert.Equal(t, http.StatusTooManyRequests, throttled.Code)
+	assert.Equal(t, "30", throttled.Header().Get("Retry-After"))
+	assert.Equal(t, wrong.Body.String(), unknown.Body.String(), "unknown usernames are not revealed")
+	assert.Equal(t, throttled.Code, unknownThrottled.Code)
+	assert.Equal(t, throttled.Body.String(), unknownThrottled.Body.String())
+	assert.Equal(t, http.StatusTooManyRequests, change.Code, "password changes wait like logins")
+	assert.Equal(t, "30", change.Header().Get("Retry-After"))
+}
+
+func TestUserController_LoginLookupFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userRepo := new(mocks.IUserRepository)
+	userRepo.On("FindByUsername", mock.Anything, "alice").Return(nil, errors.New("connection refused"))
+	userUsecase := usecases.NewUserUsecase(userRepo, new(mocks.ITokenRepository), new(mocks.IPasswordService), new(mocks.IJWTService))
+	router := gin.New()
+	router.POST("/auth/login", controllers.NewUserController(userUsecase).Login)
+
+	w := serve(router, http.MethodPost, "/auth/login", `{"username": "alice", "password": "password"}`, nil)
+
+	// --- ASSERT ---
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error": "Failed to log in"}`, w.Body.String())
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"taskmanager/delivery/dto"
+	"taskmanager/domain"
+	"taskmanager/usecases"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IWebhookController interface {
+	CreateWebhook(c *gin.Context)
+	ListWebhooks(c *gin.Context)
+	GetWebhook(c *gin.Context)
+	UpdateWebhook(c *gin.Context)
+	DeleteWebhook(c *gin.Context)
+	ListDeliveries(c *gin.Context)
+	Redeliver(c *gin.Context)
+}
+
+type WebhookController struct {
+	webhookUsecase usecases.IWebhookUsecase
+}
+
+func NewWebhookController(webhookUsecase usecases.IWebhookUsecase) *WebhookController {
+	return &WebhookController{webhookUsecase: webhookUsecase}
+}
+
+func toWebhookResponse(webhook *domain.Webhook) dto.WebhookResponse {
+	response := dto.WebhookResponse{
+		ID:               webhook.ID.Hex(),
+		URL:              webhook.URL,
+		Events:           webhook.Events,
+		AllTasks:         webhook.AllTasks,
+		Enabled:          webhook.DisabledAt.IsZero(),
+		FailedDeliveries: webhook.FailedDeliveries,
+		CreatedAt:        webhook.CreatedAt,
+	}
+	if response.Events == nil {
+		response.Events = []string{}
+	}
+	if !webhook.DisabledAt.IsZero() {
+		response.DisabledAt = &webhook.DisabledAt
+	}
+	return response
+}
+
+func toWebhookDeliveryResponse(delivery *domain.WebhookDelivery) dto.WebhookDeliveryResponse {
+	response := dto.WebhookDeliveryResponse{
+		ID:             delivery.ID.Hex(),
+		EventID:        delivery.EventID.Hex(),
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+		Payload:        json.RawMessage(delivery.Payload),
+	}
+	if delivery.Status == domain.WebhookDeliveryPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	if !delivery.LastAttemptAt.IsZero() {
+		response.LastAttemptAt = &delivery.LastAttemptAt
+	}
+	return response
+}
+
+func respondWebhookError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidWebhook):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecases.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func (wc *WebhookController) CreateWebhook(c *gin.Context) {
+	var input dto.WebhookRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	webhook := &domain.Webhook{URL: input.URL, Events: input.Events, AllTasks: input.AllTasks}
+	created, err := wc.webhookUsecase.CreateWebhook(c.Request.Context(), webhook, userID)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to create webhook")
+		return
+	}
+	response := toWebhookResponse(created)
+	response.Secret = created.Secret
+	c.JSON(http.StatusCreated, response)
+}
+
+func (wc *WebhookController) ListWebhooks(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	webhooks, err := wc.webhookUsecase.ListWebhooks(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhooks"})
+		return
+	}
+	response := dto.WebhookListResponse{Webhooks: make([]dto.WebhookResponse, len(webhooks))}
+	for i := range webhooks {
+		response.Webhooks[i] = toWebhookResponse(&webhooks[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (wc *WebhookController) GetWebhook(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	webhook, err := wc.webhookUsecase.GetWebhook(c.Request.Context(), c.Param("id"), userID)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to retrieve webhook")
+		return
+	}
+	c.JSON(http.StatusOK, toWebhookResponse(webhook))
+}
+
+func (wc *WebhookController) UpdateWebhook(c *gin.Context) {
+	var input dto.WebhookUpdateRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		return
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	update := &usecases.WebhookUpdate{URL: input.URL, Events: input.Events, AllTasks: input.AllTasks, Enabled: input.Enabled}
+	webhook, err := wc.webhookUsecase.UpdateWebhook(c.Request.Context(), c.Param("id"), update, userID)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to update webhook")
+		return
+	}
+	c.JSON(http.StatusOK, toWebhookResponse(webhook))
+}
+
+func (wc *WebhookController) DeleteWebhook(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	if err := wc.webhookUsecase.DeleteWebhook(c.Request.Context(), c.Param("id"), userID); err != nil {
+		respondWebhookError(c, err, "Failed to delete webhook")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries returns the webhook's delivery log, newest first, with
+// the payload of each delivery.
+func (wc *WebhookController) ListDeliveries(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = n
+	}
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	deliveries, err := wc.webhookUsecase.ListDeliveries(c.Request.Context(), c.Param("id"), limit, userID)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to retrieve webhook deliveries")
+		return
+	}
+	response := dto.WebhookDeliveryListResponse{Deliveries: make([]dto.WebhookDeliveryResponse, len(deliveries))}
+	for i := range deliveries {
+		response.Deliveries[i] = toWebhookDeliveryResponse(&deliveries[i])
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Redeliver queues an earlier delivery again. It answers 202: the new
+// delivery is sent in the background.
+func (wc *WebhookController) Redeliver(c *gin.Context) {
+	userIDHex, _ := c.Get("user_id")
+	userID, _ := primitive.ObjectIDFromHex(userIDHex.(string))
+	delivery, err := wc.webhookUsecase.Redeliver(c.Request.Context(), c.Param("id"), c.Param("deliveryId"), userID)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to redeliver webhook event")
+		return
+	}
+	c.JSON(http.StatusAccepted, toWebhookDeliveryResponse(delivery))
+}
@@ -0,0 +1,106 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"taskmanager/delivery/controllers"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/usecases"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWebhookController_DeliverAndRedeliver(t *testing.T) {
+	f := newFixture(t)
+	var received []string
+	var secret string
+	var verified bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r.Header.Get(infrastructure.WebhookEventHeader))
+		unix, _ := strconv.ParseInt(r.Header.Get(infrastructure.WebhookTimestampHeader), 10, 64)
+		verified = r.Header.Get(infrastructure.WebhookSignatureHeader) == infrastructure.SignWebhook(secret, time.Unix(unix, 0), body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	loopback, err := infrastructure.ParseNetworks("127.0.0.0/8,::1")
+	require.NoError(t, err)
+	webhookUsecase := usecases.NewWebhookUsecase(f.repos.Webhooks, infrastructure.NewWebhookSender(loopback))
+	taskUsecase := f.newTaskUsecase(usecases.WithTaskEventSink(webhookUsecase))
+	webhooks := controllers.NewWebhookController(webhookUsecase)
+	userID := f.user
+	router := f.router
+	group := router.Group("/webhooks", func(c *gin.Context) {
+		c.Set("user_id", userID.Hex())
+	})
+	group.GET("", webhooks.ListWebhooks)
+	group.POST("", webhooks.CreateWebhook)
+	group.PATCH("/:id", webhooks.UpdateWebhook)
+	group.GET("/:id/deliveries", webhooks.ListDeliveries)
+	group.POST("/:id/deliveries/:deliveryId/redeliver", webhooks.Redeliver)
+
+	w := serve(router, http.MethodPost, "/webhooks", `{"url": "`+receiver.URL+`", "all_tasks": true}`, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, "only admins can watch every task")
+	w = serve(router, http.MethodPost, "/webhooks", `{"url": "mailto:me@example.com"}`, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	w = serve(router, http.MethodPost, "/webhooks", `{"url": "`+receiver.URL+`", "events": ["task.created"]}`, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var hook struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &hook))
+	require.NotEmpty(t, hook.Secret)
+	secret = hook.Secret
+	w = serve(router, http.MethodGet, "/webhooks", "", nil)
+	assert.NotContains(t, w.Body.String(), hook.Secret, "the secret is only shown once")
+
+	ctx := context.Background()
+	_, err = taskUsecase.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: usecases.StatusPending}, userID)
+	require.NoError(t, err)
+	_, err = webhookUsecase.DeliverDue(ctx, time.Now())
+	require.NoError(t, err)
+	w = serve(router, http.MethodGet, "/webhooks/"+hook.ID+"/deliveries", "", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var deliveryLog struct {
+		Deliveries []struct {
+			ID             string `json:"id"`
+			Status         string `json:"status"`
+			ResponseStatus int    `json:"response_status"`
+			Payload        struct {
+				Task struct {
+					Title string `json:"title"`
+				} `json:"task"`
+			} `json:"payload"`
+		} `json:"deliveries"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deliveryLog))
+	require.Len(t, deliveryLog.Deliveries, 1)
+
+	w = serve(router, http.MethodPost, "/webhooks/"+hook.ID+"/deliveries/"+deliveryLog.Deliveries[0].ID+"/redeliver", "", nil)
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	_, err = webhookUsecase.DeliverDue(ctx, time.Now())
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.Equal(t, []string{usecases.EventTaskCreated, usecases.EventTaskCreated}, received)
+	assert.True(t, verified)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, deliveryLog.Deliveries[0].Status)
+	assert.Equal(t, http.StatusNoContent, deliveryLog.Deliveries[0].ResponseStatus)
+	assert.Equal(t, "Deploy", deliveryLog.Deliveries[0].Payload.Task.Title)
+	w = serve(router, http.MethodGet, "/webhooks/"+hook.ID+"/deliveries?limit=0", "", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = serve(router, http.MethodPatch, "/webhooks/"+primitive.NewObjectID().Hex(), `{"enabled": false}`, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
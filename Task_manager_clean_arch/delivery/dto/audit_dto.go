@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type AuditEntryResponse struct {
+	ID         string                `json:"id"`
+	EntityType string                `json:"entity_type"`
+	EntityID   string                `json:"entity_id"`
+	Action     string                `json:"action"`
+	ActorID    string                `json:"actor_id"`
+	Timestamp  time.Time             `json:"timestamp"`
+	Changes    []FieldChangeResponse `json:"changes"`
+}
+type FieldChangeResponse struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+type AuditListResponse struct {
+	Entries    []AuditEntryResponse `json:"entries"`
+	NextCursor string               `json:"next_cursor"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+type CalendarFeedRequest struct {
+	// Name tells the user's feeds apart, such as "Phone"; it defaults to
+	// "Calendar".
+	Name string `json:"name"`
+}
+
+// CalendarFeedResponse describes a feed. URL, the secret address to give a
+// calendar app, is only returned when the feed is created.
+type CalendarFeedResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	URL       string    `json:"url,omitempty"`
+}
+type CalendarFeedListResponse struct {
+	Feeds []CalendarFeedResponse `json:"feeds"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+type ProjectRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+// ProjectUpdateRequest renames a project or changes its description;
+// omitted fields stay as they are.
+type ProjectUpdateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// ProjectMemberRequest sets a member's role: owner, editor or viewer.
+type ProjectMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+type ProjectMemberResponse struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+type ProjectResponse struct {
+	ID          string                  `json:"id"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Members     []ProjectMemberResponse `json:"members"`
+	CreatedAt   time.Time               `json:"created_at"`
+	ArchivedAt  *time.Time              `json:"archived_at,omitempty"`
+}
+type ProjectListResponse struct {
+	Projects []ProjectResponse `json:"projects"`
+}
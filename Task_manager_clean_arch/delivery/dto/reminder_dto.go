@@ -0,0 +1,11 @@
+package dto
+
+// ReminderSettingsRequest lists offsets as Go durations such as "24h" or
+// "-30m". An empty list turns reminders off.
+type ReminderSettingsRequest struct {
+	Offsets []string `json:"offsets" binding:"required"`
+}
+type ReminderSettingsResponse struct {
+	Offsets []string `json:"offsets"`
+	Default bool     `json:"default"`
+}
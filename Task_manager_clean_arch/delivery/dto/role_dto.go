@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type RoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// RoleUpdateRequest changes a role; omitted fields stay as they are.
+type RoleUpdateRequest struct {
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+type RoleResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	BuiltIn     bool      `json:"built_in"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+type RoleListResponse struct {
+	Roles []RoleResponse `json:"roles"`
+}
+type PermissionListResponse struct {
+	Permissions []string `json:"permissions"`
+}
+
+// UserRolesRequest replaces a user's roles.
+type UserRolesRequest struct {
+	Roles []string `json:"roles" binding:"required"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// StreamTicketResponse is a single-use ticket that opens one live task
+// stream, passed as its ticket query parameter.
+type StreamTicketResponse struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TaskStreamEvent is one message of a live task stream. Event is a task
+// event type such as "task.updated", which comes with the task, or one of
+// the control events "reset", "reconnect" and "heartbeat".
+type TaskStreamEvent struct {
+	// ID is what a reconnecting client sends back as Last-Event-ID.
+	ID             string        `json:"id,omitempty"`
+	Event          string        `json:"event"`
+	OccurredAt     *time.Time    `json:"occurred_at,omitempty"`
+	ActorID        string        `json:"actor_id,omitempty"`
+	PreviousStatus string        `json:"previous_status,omitempty"`
+	Task           *TaskResponse `json:"task,omitempty"`
+	// Reason says why a reconnect event ended the stream.
+	Reason string `json:"reason,omitempty"`
+}
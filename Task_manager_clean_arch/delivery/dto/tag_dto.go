@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type TagRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Color is "#rrggbb"; a default grey is used when it is empty.
+	Color string `json:"color"`
+}
+
+// TagUpdateRequest renames or recolors a tag; omitted fields stay as they are.
+type TagUpdateRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+type TagResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"created_at"`
+}
+type TagListResponse struct {
+	Tags []TagResponse `json:"tags"`
+}
@@ -7,12 +7,151 @@ type TaskRequest struct {
 	Description string    `json:"description"`
 	DueDate     time.Time `json:"due_date"`
 	Status      string    `json:"status" binding:"required"`
+	ParentID    string    `json:"parent_id"`
+	BlockedBy   []string  `json:"blocked_by"`
+	// Tags are names of the owner's tags.
+	Tags []string `json:"tags"`
+	// ProjectID puts a new task in a project; updates leave it unchanged.
+	ProjectID string `json:"project_id"`
+	// Recurrence is an RRULE such as "FREQ=WEEKLY;BYDAY=MO"; TimeZone is the
+	// IANA zone it is evaluated in and defaults to UTC.
+	Recurrence string `json:"recurrence"`
+	TimeZone   string `json:"time_zone"`
+}
+
+// TaskPatchDocument holds the fields PATCH /tasks/:id can change, as the
+// target a JSON Patch is applied to. Empty optional fields are null.
+type TaskPatchDocument struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date"`
+	Status      string     `json:"status"`
+	ParentID    *string    `json:"parent_id"`
+	BlockedBy   []string   `json:"blocked_by"`
+	Tags        []string   `json:"tags"`
+	Recurrence  *string    `json:"recurrence"`
+	TimeZone    *string    `json:"time_zone"`
 }
 type TaskResponse struct {
-	ID          string    `json:"id"`
+	ID            string                 `json:"id"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	DueDate       time.Time              `json:"due_date"`
+	Status        string                 `json:"status"`
+	UserID        string                 `json:"user_id"`
+	CreatedAt     time.Time              `json:"created_at"`
+	StatusHistory []StatusChangeResponse `json:"status_history"`
+	ParentID      string                 `json:"parent_id,omitempty"`
+	BlockedBy     []string               `json:"blocked_by"`
+	AssigneeID    string                 `json:"assignee_id,omitempty"`
+	Collaborators []CollaboratorResponse `json:"collaborators"`
+	Recurrence    *RecurrenceResponse    `json:"recurrence,omitempty"`
+	Tags          []string               `json:"tags"`
+	ProjectID     string                 `json:"project_id,omitempty"`
+	ExternalID    string                 `json:"external_id,omitempty"`
+	ArchivedAt    *time.Time             `json:"archived_at,omitempty"`
+	Version       int64                  `json:"version"`
+	DeletedAt     *time.Time             `json:"deleted_at,omitempty"`
+	DeletedBy     string                 `json:"deleted_by,omitempty"`
+}
+type RecurrenceResponse struct {
+	Rule       string `json:"rule"`
+	TimeZone   string `json:"time_zone"`
+	SeriesID   string `json:"series_id"`
+	Occurrence int    `json:"occurrence"`
+	NextTaskID string `json:"next_task_id,omitempty"`
+}
+type OccurrencesResponse struct {
+	Occurrences []time.Time `json:"occurrences"`
+}
+type CollaboratorResponse struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+type AssignRequest struct {
+	UserID string `json:"user_id" binding:"required"`
+}
+type ShareRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+type StatusChangeResponse struct {
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	ChangedBy string    `json:"changed_by"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+type TaskListResponse struct {
+	Tasks      []TaskResponse `json:"tasks"`
+	NextCursor string         `json:"next_cursor"`
+}
+type TaskSearchResponse struct {
+	Tasks []TaskResponse `json:"tasks"`
+}
+
+// BatchOperationRequest is one operation of a batch. Op is "create",
+// "update" or "delete". ID names the task an update or delete changes, and a
+// non-zero Version must match its current version. Task is required for
+// create and update.
+type BatchOperationRequest struct {
+	Op      string       `json:"op" binding:"required"`
+	ID      string       `json:"id"`
+	Version int64        `json:"version"`
+	Task    *TaskRequest `json:"task"`
+}
+type BatchRequest struct {
+	Operations []BatchOperationRequest `json:"operations" binding:"required,dive"`
+}
+type BatchResultResponse struct {
+	Index  int           `json:"index"`
+	Op     string        `json:"op"`
+	Status int           `json:"status"`
+	Task   *TaskResponse `json:"task,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+type BatchResponse struct {
+	Committed bool                  `json:"committed"`
+	Results   []BatchResultResponse `json:"results"`
+}
+
+// TaskImportRow is one task of a JSON import. ExternalID identifies the task
+// in the tool it comes from, so importing it again skips it.
+type TaskImportRow struct {
+	ExternalID string `json:"external_id"`
+	TaskRequest
+}
+
+// TaskExportRecord is one task of a JSON or CSV export. ExternalID is the
+// ID the task was imported with, or else its own ID.
+type TaskExportRecord struct {
+	ExternalID  string    `json:"external_id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	DueDate     time.Time `json:"due_date"`
 	Status      string    `json:"status"`
-	UserID      string    `json:"user_id"`
+	Tags        []string  `json:"tags,omitempty"`
+	Recurrence  string    `json:"recurrence,omitempty"`
+	TimeZone    string    `json:"time_zone,omitempty"`
+}
+type ImportResultResponse struct {
+	Row        int    `json:"row"`
+	ExternalID string `json:"external_id,omitempty"`
+	Status     string `json:"status"`
+	TaskID     string `json:"task_id,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+type ImportResponse struct {
+	DryRun  bool                   `json:"dry_run"`
+	Created int                    `json:"created"`
+	Skipped int                    `json:"skipped"`
+	Failed  int                    `json:"failed"`
+	Results []ImportResultResponse `json:"results"`
+	// Error is why the file could not be read to the end.
+	Error string `json:"error,omitempty"`
+}
+type TaskDependenciesResponse struct {
+	BlockedBy []TaskResponse `json:"blocked_by"`
+	Blocking  []TaskResponse `json:"blocking"`
+}
+type EmptyTrashResponse struct {
+	Purged int `json:"purged"`
 }
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
@@ -9,7 +11,44 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 type UserResponse struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	ID         string     `json:"id"`
+	Username   string     `json:"username"`
+	Roles      []string   `json:"roles"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+type UserListResponse struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+// UserDeletionResponse counts the deleted user's tasks, which were either
+// reassigned or deleted.
+type UserDeletionResponse struct {
+	TasksReassigned int `json:"tasks_reassigned"`
+	TasksDeleted    int `json:"tasks_deleted"`
+}
+
+// PasswordResetResponse carries the new password, which is only shown once.
+type PasswordResetResponse struct {
+	TemporaryPassword string `json:"temporary_password"`
+}
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+type PasswordResetRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+type PasswordResetConfirmRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+type TokenResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookRequest struct {
+	URL string `json:"url" binding:"required"`
+	// Events are the event types to send, such as "task.created"; all of
+	// them when omitted.
+	Events []string `json:"events"`
+	// AllTasks sends the events of every task, not only the user's. It
+	// needs the webhook:all_tasks permission.
+	AllTasks bool `json:"all_tasks"`
+}
+
+// WebhookUpdateRequest changes a webhook; omitted fields stay as they are.
+type WebhookUpdateRequest struct {
+	URL      *string   `json:"url"`
+	Events   *[]string `json:"events"`
+	AllTasks *bool     `json:"all_tasks"`
+	Enabled  *bool     `json:"enabled"`
+}
+
+// WebhookResponse describes a webhook. Secret, which verifies the
+// signatures of its deliveries, is only returned when the webhook is created.
+type WebhookResponse struct {
+	ID               string     `json:"id"`
+	URL              string     `json:"url"`
+	Events           []string   `json:"events"`
+	AllTasks         bool       `json:"all_tasks"`
+	Enabled          bool       `json:"enabled"`
+	FailedDeliveries int        `json:"failed_deliveries"`
+	DisabledAt       *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	Secret           string     `json:"secret,omitempty"`
+}
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	EventID        string          `json:"event_id"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Payload        json.RawMessage `json:"payload"`
+}
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
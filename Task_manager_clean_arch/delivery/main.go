@@ -3,6 +3,13 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"taskmanager/delivery/controllers"
 	"taskmanager/delivery/routers"
 	"taskmanager/infrastructure"
@@ -21,38 +28,329 @@ func main() {
 		log.Println("No .env file found, relying on environment variables.")
 	}
 
-	// --- DATABASE CONNECTION ---
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI("mongodb://localhost:27017"))
-	if err != nil {
-		log.Fatalf("Failed to connect to MongoDB: %v", err)
-	}
-	defer client.Disconnect(ctx)
-	log.Println("Connected to MongoDB!")
-	db := client.Database("taskmanager_clean")
-
 	// --- DEPENDENCY INJECTION (WIRING THE LAYERS TOGETHER) ---
 	// Layer 4: Infrastructure (The Tools)
 	passwordService := infrastructure.NewPasswordService()
 	jwtService := infrastructure.NewJWTService()
 
 	// Layer 3: Repositories (The Database Implementations)
-	userRepo := repositories.NewUserRepository(db)
-	taskRepo := repositories.NewTaskRepository(db)
+	// STORAGE_BACKEND selects the implementation: "mongo" (default), "sqlite" or "memory".
+	var repos *repositories.Repositories
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "mongo":
+		mongoURI := os.Getenv("MONGO_URI")
+		if mongoURI == "" {
+			mongoURI = "mongodb://localhost:27017"
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+		if err != nil {
+			log.Fatalf("Failed to connect to MongoDB: %v", err)
+		}
+		defer client.Disconnect(context.Background())
+		log.Println("Connected to MongoDB!")
+		repos = repositories.NewMongoRepositories(client.Database("taskmanager_clean"))
+	case "sqlite":
+		sqlitePath := os.Getenv("SQLITE_PATH")
+		if sqlitePath == "" {
+			sqlitePath = "taskmanager.db"
+		}
+		db, err := repositories.OpenSQLite(sqlitePath)
+		if err != nil {
+			log.Fatalf("Failed to open SQLite database: %v", err)
+		}
+		defer db.Close()
+		log.Printf("Using SQLite database %s", sqlitePath)
+		repos = repositories.NewSQLiteRepositories(db)
+	case "memory":
+		log.Println("Using in-memory storage; data will be lost on restart.")
+		repos = repositories.NewMemoryRepositories()
+	default:
+		log.Fatalf("Unknown STORAGE_BACKEND %q", backend)
+	}
 
 	// Layer 2: Usecases (The Business Logic)
-	userUsecase := usecases.NewUserUsecase(userRepo, passwordService, jwtService)
-	taskUsecase := usecases.NewTaskUsecase(taskRepo)
+	// background counts the work that outlives a request or runs on its own,
+	// which shutdown waits for.
+	var background sync.WaitGroup
+	userUsecase := usecases.NewUserUsecase(repos.Users, repos.Tokens, passwordService, jwtService,
+		usecases.WithMailer(mailer()), usecases.WithUserAuditLog(repos.Audit), usecases.WithLoginThrottle(repos.LoginAttempts, loginPolicy()),
+		usecases.WithPasswordResetThrottle(repos.LoginAttempts, usecases.DefaultPasswordResetPolicy()), usecases.WithBackgroundWork(&background))
+	roleUsecase := usecases.NewRoleUsecase(repos.Roles, repos.Users, repos.Audit)
+	if err := roleUsecase.EnsureBuiltInRoles(context.Background()); err != nil {
+		log.Fatalf("Failed to create the built-in roles: %v", err)
+	}
+	// TASK_WORKFLOW_FILE optionally points at a JSON status workflow.
+	workflow := usecases.DefaultStatusWorkflow()
+	if path := os.Getenv("TASK_WORKFLOW_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read task workflow: %v", err)
+		}
+		if workflow, err = usecases.ParseStatusWorkflow(data); err != nil {
+			log.Fatalf("Invalid task workflow in %s: %v", path, err)
+		}
+	}
+	webhookUsecase := usecases.NewWebhookUsecase(repos.Webhooks, webhookSender(),
+		append(webhookOptions(), usecases.WithWebhookPermissions(roleUsecase))...)
+	taskStream := usecases.NewTaskStream(usecases.WithStreamPermissions(roleUsecase))
+	taskUsecase := usecases.NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects, usecases.WithStatusWorkflow(workflow), usecases.WithUnitOfWork(repos.UnitOfWork),
+		usecases.WithTaskEventSink(webhookUsecase), usecases.WithTaskEventSink(taskStream), usecases.WithPermissionChecker(roleUsecase))
+	auditUsecase := usecases.NewAuditUsecase(repos.Audit)
+	reminderUsecase := usecases.NewReminderUsecase(repos.Tasks, repos.Users, repos.Reminders, reminderNotifier(),
+		append(reminderOptions(), usecases.WithReminderWorkflow(workflow))...)
+	tagUsecase := usecases.NewTagUsecase(repos.Tags, repos.Tasks, repos.UnitOfWork)
+	projectUsecase := usecases.NewProjectUsecase(repos.Projects, repos.Tasks, repos.Users)
+	calendarUsecase := usecases.NewCalendarUsecase(repos.CalendarFeeds, repos.Users, taskUsecase)
+	trashUsecase := usecases.NewTrashUsecase(repos.Tasks, repos.Users, repos.Audit,
+		append(trashOptions(), usecases.WithTrashUnitOfWork(repos.UnitOfWork))...)
+	userAdminUsecase := usecases.NewUserAdminUsecase(repos, passwordService, roleUsecase)
 
 	// Layer 1: Delivery (The HTTP Handlers)
 	userController := controllers.NewUserController(userUsecase)
-	taskController := controllers.NewTaskController(taskUsecase)
+	// REQUIRE_IF_MATCH=true rejects task updates, patches and deletes without If-Match.
+	requireIfMatch, _ := strconv.ParseBool(os.Getenv("REQUIRE_IF_MATCH"))
+	taskController := controllers.NewTaskController(taskUsecase, controllers.WithRequireIfMatch(requireIfMatch))
+	auditController := controllers.NewAuditController(auditUsecase)
+	reminderController := controllers.NewReminderController(reminderUsecase)
+	trashController := controllers.NewTrashController(trashUsecase)
+	tagController := controllers.NewTagController(tagUsecase)
+	projectController := controllers.NewProjectController(projectUsecase)
+	calendarController := controllers.NewCalendarController(calendarUsecase)
+	webhookController := controllers.NewWebhookController(webhookUsecase)
+	streamController := controllers.NewStreamController(taskStream, jwtService)
+	roleController := controllers.NewRoleController(roleUsecase)
+	userAdminController := controllers.NewUserAdminController(userAdminUsecase)
 
 	// --- SETUP ROUTER AND START SERVER ---
-	router := routers.SetupRouter(userController, taskController, jwtService)
-	log.Println("Server starting on port 8080...")
-	if err := router.Run(":8080"); err != nil {
+	router := routers.SetupRouter(routers.Controllers{
+		User:      userController,
+		Task:      taskController,
+		Audit:     auditController,
+		Reminder:  reminderController,
+		Trash:     trashController,
+		Tag:       tagController,
+		Project:   projectController,
+		Calendar:  calendarController,
+		Webhook:   webhookController,
+		Stream:    streamController,
+		Role:      roleController,
+		UserAdmin: userAdminController,
+	}, routers.Auth{
+		JWT:         jwtService,
+		Tokens:      repos.Tokens,
+		FeedTokens:  calendarUsecase,
+		Permissions: roleUsecase,
+		Accounts:    userAdminUsecase,
+	})
+	// Login throttling counts failures per client address, so the
+	// X-Forwarded-For header is only believed from TRUSTED_PROXIES, a
+	// comma-separated list of addresses or CIDR ranges.
+	var trustedProxies []string
+	if raw := os.Getenv("TRUSTED_PROXIES"); raw != "" {
+		for _, proxy := range strings.Split(raw, ",") {
+			trustedProxies = append(trustedProxies, strings.TrimSpace(proxy))
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES %q: %v", os.Getenv("TRUSTED_PROXIES"), err)
+	}
+	server := &http.Server{Addr: ":8080", Handler: router}
+	// Live task streams never finish on their own; end them so that
+	// Shutdown does not wait for them.
+	server.RegisterOnShutdown(taskStream.Close)
+
+	// Stop on Ctrl+C or SIGTERM: stop accepting requests, let the ones in
+	// flight, the password reset mail they started, the current reminder
+	// check, trash purge and webhook deliveries finish, then close the
+	// database.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	background.Add(3)
+	go func() {
+		defer background.Done()
+		reminderUsecase.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		trashUsecase.Run(ctx)
+	}()
+	go func() {
+		defer background.Done()
+		webhookUsecase.Run(ctx)
+	}()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Println("Server starting on port 8080...")
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		stop()
+		background.Wait()
 		log.Fatalf("Failed to run server: %v", err)
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Server shutdown: %v", err)
+	}
+	background.Wait()
+	log.Println("Server stopped.")
+}
+
+// reminderNotifier posts reminders to REMINDER_WEBHOOK_URL when it is set,
+// and logs them otherwise.
+func reminderNotifier() infrastructure.INotifier {
+	if url := os.Getenv("REMINDER_WEBHOOK_URL"); url != "" {
+		log.Printf("Sending reminders to %s", url)
+		return infrastructure.NewWebhookNotifier(url, nil)
+	}
+	return infrastructure.NewLogNotifier(nil)
+}
+
+// webhookSender delivers webhooks to public addresses, and to the networks
+// listed in WEBHOOK_ALLOWED_NETWORKS (such as 10.1.2.0/24,fd00::/8).
+func webhookSender() infrastructure.IWebhookSender {
+	allowed, err := infrastructure.ParseNetworks(os.Getenv("WEBHOOK_ALLOWED_NETWORKS"))
+	if err != nil {
+		log.Fatalf("Invalid WEBHOOK_ALLOWED_NETWORKS: %v", err)
+	}
+	return infrastructure.NewWebhookSender(allowed)
+}
+
+// mailer appends mail, such as password reset tokens, to MAIL_FILE when it
+// is set. Otherwise it warns and logs only who mail was for, since a token
+// in the log would let anyone who reads it take over the account.
+func mailer() infrastructure.IMailer {
+	if path := os.Getenv("MAIL_FILE"); path != "" {
+		log.Printf("Writing mail to %s", path)
+		return infrastructure.NewFileMailer(path)
+	}
+	log.Printf("Warning: no mailer is configured, so password reset mail is not delivered; set MAIL_FILE to write it to a file")
+	return infrastructure.NewLogMailer(nil)
+}
+
+// loginPolicy reads LOGIN_LOCKOUT_THRESHOLD (such as 10),
+// LOGIN_IP_LOCKOUT_THRESHOLD (such as 50) and LOGIN_LOCKOUT_DURATION (such
+// as 15m) for login throttling.
+func loginPolicy() usecases.LoginPolicy {
+	policy := usecases.DefaultLoginPolicy()
+	if raw := os.Getenv("LOGIN_LOCKOUT_THRESHOLD"); raw != "" {
+		threshold, err := strconv.Atoi(raw)
+		if err != nil || threshold < 1 {
+			log.Fatalf("Invalid LOGIN_LOCKOUT_THRESHOLD %q", raw)
+		}
+		policy.Username.LockoutThreshold = threshold
+		policy.Username.FreeAttempts = min(policy.Username.FreeAttempts, threshold-1)
+	}
+	if raw := os.Getenv("LOGIN_IP_LOCKOUT_THRESHOLD"); raw != "" {
+		threshold, err := strconv.Atoi(raw)
+		if err != nil || threshold < 1 {
+			log.Fatalf("Invalid LOGIN_IP_LOCKOUT_THRESHOLD %q", raw)
+		}
+		policy.IP.LockoutThreshold = threshold
+		policy.IP.FreeAttempts = min(policy.IP.FreeAttempts, threshold-1)
+	}
+	if raw := os.Getenv("LOGIN_LOCKOUT_DURATION"); raw != "" {
+		duration, err := time.ParseDuration(raw)
+		if err != nil || duration <= 0 {
+			log.Fatalf("Invalid LOGIN_LOCKOUT_DURATION %q", raw)
+		}
+		policy.LockoutDuration = duration
+	}
+	return policy
+}
+
+// reminderOptions reads REMINDER_INTERVAL (such as 30s) and
+// REMINDER_OFFSETS (such as 24h,1h,0s) for the reminder scheduler.
+func reminderOptions() []usecases.ReminderOption {
+	var opts []usecases.ReminderOption
+	if raw := os.Getenv("REMINDER_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid REMINDER_INTERVAL %q", raw)
+		}
+		opts = append(opts, usecases.WithReminderInterval(interval))
+	}
+	if raw := os.Getenv("REMINDER_OFFSETS"); raw != "" {
+		var offsets []time.Duration
+		for _, part := range strings.Split(raw, ",") {
+			offset, err := time.ParseDuration(strings.TrimSpace(part))
+			if err != nil {
+				log.Fatalf("Invalid REMINDER_OFFSETS %q: %v", raw, err)
+			}
+			offsets = append(offsets, offset)
+		}
+		offsets, err := usecases.ValidateReminderOffsets(offsets)
+		if err != nil {
+			log.Fatalf("Invalid REMINDER_OFFSETS %q: %v", raw, err)
+		}
+		opts = append(opts, usecases.WithDefaultReminderOffsets(offsets))
+	}
+	return opts
+}
+
+// trashOptions reads TRASH_RETENTION (such as 720h) and TRASH_PURGE_INTERVAL
+// (such as 1h) for the trash purger.
+func trashOptions() []usecases.TrashOption {
+	var opts []usecases.TrashOption
+	if raw := os.Getenv("TRASH_RETENTION"); raw != "" {
+		retention, err := time.ParseDuration(raw)
+		if err != nil || retention < 0 {
+			log.Fatalf("Invalid TRASH_RETENTION %q", raw)
+		}
+		opts = append(opts, usecases.WithTrashRetention(retention))
+	}
+	if raw := os.Getenv("TRASH_PURGE_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid TRASH_PURGE_INTERVAL %q", raw)
+		}
+		opts = append(opts, usecases.WithPurgeInterval(interval))
+	}
+	return opts
+}
+
+// webhookOptions reads WEBHOOK_INTERVAL (such as 10s), WEBHOOK_WORKERS (such
+// as 4), WEBHOOK_FAILURE_LIMIT (such as 5) and WEBHOOK_RETENTION (such as
+// 720h) for the webhook deliverer.
+func webhookOptions() []usecases.WebhookOption {
+	var opts []usecases.WebhookOption
+	if raw := os.Getenv("WEBHOOK_INTERVAL"); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid WEBHOOK_INTERVAL %q", raw)
+		}
+		opts = append(opts, usecases.WithWebhookInterval(interval))
+	}
+	if raw := os.Getenv("WEBHOOK_WORKERS"); raw != "" {
+		workers, err := strconv.Atoi(raw)
+		if err != nil || workers < 1 {
+			log.Fatalf("Invalid WEBHOOK_WORKERS %q", raw)
+		}
+		opts = append(opts, usecases.WithWebhookWorkers(workers))
+	}
+	if raw := os.Getenv("WEBHOOK_FAILURE_LIMIT"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			log.Fatalf("Invalid WEBHOOK_FAILURE_LIMIT %q", raw)
+		}
+		opts = append(opts, usecases.WithWebhookFailureLimit(limit))
+	}
+	if raw := os.Getenv("WEBHOOK_RETENTION"); raw != "" {
+		retention, err := time.ParseDuration(raw)
+		if err != nil || retention < 0 {
+			log.Fatalf("Invalid WEBHOOK_RETENTION %q", raw)
+		}
+		opts = append(opts, usecases.WithWebhookRetention(retention))
 	}
+	return opts
 }
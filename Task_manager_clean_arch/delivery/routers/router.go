@@ -2,45 +2,176 @@ package routers
 
 import (
 	"taskmanager/delivery/controllers"
+	"taskmanager/domain"
 	"taskmanager/infrastructure"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(
-	userController controllers.IUserController,
-	taskController controllers.ITaskController,
-	jwtService infrastructure.IJWTService) *gin.Engine {
-	r := gin.Default()
+// Controllers holds the controllers SetupRouter routes requests to.
+type Controllers struct {
+	User      controllers.IUserController
+	Task      controllers.ITaskController
+	Audit     controllers.IAuditController
+	Reminder  controllers.IReminderController
+	Trash     controllers.ITrashController
+	Tag       controllers.ITagController
+	Project   controllers.IProjectController
+	Calendar  controllers.ICalendarController
+	Webhook   controllers.IWebhookController
+	Stream    controllers.IStreamController
+	Role      controllers.IRoleController
+	UserAdmin controllers.IUserAdminController
+}
+
+// ITokenStore records revoked access tokens and used stream tickets.
+type ITokenStore interface {
+	infrastructure.IRevocationList
+	infrastructure.ITicketLedger
+}
+
+// Auth holds what the authentication middlewares check requests against.
+type Auth struct {
+	JWT         infrastructure.IJWTService
+	Tokens      ITokenStore
+	FeedTokens  infrastructure.IFeedTokenResolver
+	Permissions infrastructure.IPermissionChecker
+	Accounts    infrastructure.IAccountChecker
+}
+
+func SetupRouter(c Controllers, auth Auth) *gin.Engine {
+	r := gin.New()
+	r.Use(infrastructure.RequestLogger(), gin.Recovery())
 
 	// Public routes for authentication
 	authRoutes := r.Group("/auth")
 	{
-		authRoutes.POST("/register", userController.Register)
-		authRoutes.POST("/login", userController.Login)
+		authRoutes.POST("/register", c.User.Register)
+		authRoutes.POST("/login", c.User.Login)
+		authRoutes.POST("/refresh", c.User.Refresh)
+		authRoutes.POST("/logout", c.User.Logout)
+		authRoutes.POST("/password-reset", c.User.RequestPasswordReset)
+		authRoutes.POST("/password-reset/confirm", c.User.ConfirmPasswordReset)
+	}
+
+	// Calendar feeds authenticate with the secret token in their URL
+	r.GET("/calendar/:token", infrastructure.FeedTokenMiddleware(auth.FeedTokens), c.Calendar.GetFeed)
+
+	// Live task events. Browsers cannot set headers on these connections,
+	// so a single-use stream ticket may come in the query string instead
+	streamRoutes := r.Group("/tasks/stream", infrastructure.StreamAuthMiddleware(auth.JWT, auth.Tokens, auth.Tokens, auth.Accounts))
+	{
+		streamRoutes.GET("", c.Stream.StreamTasks)
+		streamRoutes.GET("/ws", c.Stream.StreamTasksWebSocket)
 	}
 
 	// Protected routes that require a valid token
 	protected := r.Group("")
-	protected.Use(infrastructure.AuthMiddleware(jwtService))
+	protected.Use(infrastructure.AuthMiddleware(auth.JWT, auth.Tokens, auth.Accounts))
 	{
 		// Task routes, accessible to all logged-in users
 		taskRoutes := protected.Group("/tasks")
 		{
-			taskRoutes.GET("", taskController.GetUserTasks)
-			taskRoutes.GET("/:id", taskController.GetTaskByID)
+			taskRoutes.GET("", c.Task.GetUserTasks)
+			taskRoutes.GET("/trash", c.Task.ListTrash)
+			taskRoutes.GET("/search", c.Task.SearchTasks)
+			taskRoutes.GET("/export", c.Task.ExportTasks)
+			taskRoutes.GET("/:id", c.Task.GetTaskByID)
+			taskRoutes.GET("/:id/history", c.Task.GetTaskHistory)
+			taskRoutes.GET("/:id/subtasks", c.Task.GetSubtasks)
+			taskRoutes.GET("/:id/dependencies", c.Task.GetDependencies)
+			taskRoutes.GET("/:id/occurrences", c.Task.GetOccurrences)
 
-			// Admin-only task routes
-			taskRoutes.POST("", infrastructure.RoleAuthMiddleware("admin"), taskController.CreateTask)
-			taskRoutes.PUT("/:id", infrastructure.RoleAuthMiddleware("admin"), taskController.UpdateTask)
-			taskRoutes.DELETE("/:id", infrastructure.RoleAuthMiddleware("admin"), taskController.DeleteTask)
+			// Task permissions decide who may change a task
+			taskRoutes.PUT("/:id", c.Task.UpdateTask)
+			taskRoutes.PATCH("/:id", c.Task.PatchTask)
+			taskRoutes.PUT("/:id/assignee", c.Task.AssignTask)
+			taskRoutes.DELETE("/:id/assignee", c.Task.UnassignTask)
+			taskRoutes.PUT("/:id/collaborators/:userId", c.Task.ShareTask)
+			taskRoutes.DELETE("/:id/collaborators/:userId", c.Task.UnshareTask)
+			taskRoutes.POST("/:id/restore", c.Task.RestoreTask)
+
+			// Task routes that need a permission from the user's roles
+			taskRoutes.POST("", infrastructure.RequirePermission(auth.Permissions, domain.PermTaskCreate), c.Task.CreateTask)
+			taskRoutes.POST("/stream/ticket", c.Stream.IssueTicket)
+			taskRoutes.POST("/batch", infrastructure.RequirePermission(auth.Permissions, domain.PermTaskBatch), c.Task.RunBatch)
+			taskRoutes.POST("/import", infrastructure.RequirePermission(auth.Permissions, domain.PermTaskImport), c.Task.ImportTasks)
+			taskRoutes.DELETE("/:id", infrastructure.RequirePermission(auth.Permissions, domain.PermTaskDelete), c.Task.DeleteTask)
 		}
 
-		// Admin-only management routes
+		// Project routes; membership roles decide who may do what
+		projectRoutes := protected.Group("/projects")
+		{
+			projectRoutes.GET("", c.Project.ListProjects)
+			projectRoutes.POST("", c.Project.CreateProject)
+			projectRoutes.GET("/:id", c.Project.GetProject)
+			projectRoutes.PATCH("/:id", c.Project.UpdateProject)
+			projectRoutes.PUT("/:id/members/:userId", c.Project.SetMember)
+			projectRoutes.DELETE("/:id/members/:userId", c.Project.RemoveMember)
+			projectRoutes.POST("/:id/archive", c.Project.ArchiveProject)
+			projectRoutes.POST("/:id/unarchive", c.Project.UnarchiveProject)
+			projectRoutes.GET("/:id/tasks", c.Task.ListProjectTasks)
+			projectRoutes.POST("/:id/tasks", c.Task.CreateProjectTask)
+		}
+
+		// Tags belong to the logged-in user
+		tagRoutes := protected.Group("/tags")
+		{
+			tagRoutes.GET("", c.Tag.ListTags)
+			tagRoutes.POST("", c.Tag.CreateTag)
+			tagRoutes.PATCH("/:id", c.Tag.UpdateTag)
+			tagRoutes.DELETE("/:id", c.Tag.DeleteTag)
+		}
+
+		// Settings of the logged-in user
+		meRoutes := protected.Group("/me")
+		{
+			meRoutes.PUT("/password", c.User.ChangePassword)
+			meRoutes.GET("/reminders", c.Reminder.GetReminderSettings)
+			meRoutes.PUT("/reminders", c.Reminder.UpdateReminderSettings)
+			meRoutes.DELETE("/reminders", c.Reminder.ResetReminderSettings)
+			meRoutes.GET("/calendar-feeds", c.Calendar.ListFeeds)
+			meRoutes.POST("/calendar-feeds", c.Calendar.CreateFeed)
+			meRoutes.DELETE("/calendar-feeds/:id", c.Calendar.RevokeFeed)
+		}
+
+		// Webhooks belong to the logged-in user
+		webhookRoutes := protected.Group("/webhooks")
+		{
+			webhookRoutes.GET("", c.Webhook.ListWebhooks)
+			webhookRoutes.POST("", c.Webhook.CreateWebhook)
+			webhookRoutes.GET("/:id", c.Webhook.GetWebhook)
+			webhookRoutes.PATCH("/:id", c.Webhook.UpdateWebhook)
+			webhookRoutes.DELETE("/:id", c.Webhook.DeleteWebhook)
+			webhookRoutes.GET("/:id/deliveries", c.Webhook.ListDeliveries)
+			webhookRoutes.POST("/:id/deliveries/:deliveryId/redeliver", c.Webhook.Redeliver)
+		}
+
+		// Management routes, each guarded by its own permission
 		adminRoutes := protected.Group("/admin")
-		adminRoutes.Use(infrastructure.RoleAuthMiddleware("admin"))
 		{
-			adminRoutes.PUT("/promote/:id", userController.Promote)
+			adminRoutes.PUT("/promote/:id", infrastructure.RequirePermission(auth.Permissions, domain.PermUserPromote), c.UserAdmin.PromoteUser)
+			adminRoutes.PUT("/demote/:id", infrastructure.RequirePermission(auth.Permissions, domain.PermUserPromote), c.UserAdmin.DemoteUser)
+			adminRoutes.PUT("/users/:id/roles", infrastructure.RequirePermission(auth.Permissions, domain.PermUserPromote), c.Role.SetUserRoles)
+			adminRoutes.GET("/audit", infrastructure.RequirePermission(auth.Permissions, domain.PermAuditRead), c.Audit.ListAuditEntries)
+			adminRoutes.DELETE("/users/:id/trash", infrastructure.RequirePermission(auth.Permissions, domain.PermTrashPurge), c.Trash.EmptyTrash)
+
+			userRoutes := adminRoutes.Group("/users", infrastructure.RequirePermission(auth.Permissions, domain.PermUserManage))
+			userRoutes.GET("", c.UserAdmin.ListUsers)
+			userRoutes.GET("/:id", c.UserAdmin.GetUser)
+			userRoutes.DELETE("/:id", c.UserAdmin.DeleteUser)
+			userRoutes.POST("/:id/disable", c.UserAdmin.DisableUser)
+			userRoutes.POST("/:id/enable", c.UserAdmin.EnableUser)
+			userRoutes.POST("/:id/unlock", c.UserAdmin.UnlockUser)
+			userRoutes.POST("/:id/password-reset", c.UserAdmin.ResetPassword)
+
+			roleRoutes := adminRoutes.Group("", infrastructure.RequirePermission(auth.Permissions, domain.PermRoleManage))
+			roleRoutes.GET("/permissions", c.Role.ListPermissions)
+			roleRoutes.GET("/roles", c.Role.ListRoles)
+			roleRoutes.POST("/roles", c.Role.CreateRole)
+			roleRoutes.GET("/roles/:name", c.Role.GetRole)
+			roleRoutes.PATCH("/roles/:name", c.Role.UpdateRole)
+			roleRoutes.DELETE("/roles/:name", c.Role.DeleteRole)
 		}
 	}
 
@@ -13,11 +13,20 @@ import (
 func TestRouter_AdminRouteIsProtected(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
-	mockJwtService := new(mocks.IJWTService)
-	mockUserController := new(mocks.IUserController)
-	mockTaskController := new(mocks.ITaskController)
-
-	router := SetupRouter(mockUserController, mockTaskController, mockJwtService)
+	router := SetupRouter(Controllers{
+		User:      new(mocks.IUserController),
+		Task:      new(mocks.ITaskController),
+		Audit:     new(mocks.IAuditController),
+		Reminder:  new(mocks.IReminderController),
+		Trash:     new(mocks.ITrashController),
+		Tag:       new(mocks.ITagController),
+		Project:   new(mocks.IProjectController),
+		Calendar:  new(mocks.ICalendarController),
+		Webhook:   new(mocks.IWebhookController),
+		Stream:    new(mocks.IStreamController),
+		Role:      new(mocks.IRoleController),
+		UserAdmin: new(mocks.IUserAdminController),
+	}, Auth{JWT: new(mocks.IJWTService)})
 
 	req, _ := http.NewRequest(http.MethodPut, "/admin/promote/123", nil)
 	rr := httptest.NewRecorder()
@@ -10,7 +10,57 @@ type User struct {
 	ID       primitive.ObjectID
 	Username string
 	Password string // Hashed password
-	Role     string
+	// Roles name the roles whose permissions the user holds.
+	Roles []string
+	// ReminderOffsets are how long before a due date the user is reminded;
+	// zero means at the due date and negative means after it. nil means the
+	// server's defaults, and an empty list turns reminders off.
+	ReminderOffsets []time.Duration
+	// DisabledAt is when an admin disabled the account; zero while it is
+	// enabled. Disabled users cannot log in or use their tokens.
+	DisabledAt time.Time
+}
+
+// Built-in roles. They always exist; admin holds every permission and
+// cannot be changed.
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleUser    = "user"
+)
+
+// Permissions name what a role lets its holders do. Owning, being assigned
+// to or sharing a task needs no permission; the *:any permissions reach
+// every task regardless.
+const (
+	PermTaskCreate = "task:create"
+	PermTaskImport = "task:import"
+	PermTaskBatch  = "task:batch"
+	PermTaskDelete = "task:delete"
+	// PermTaskReadAny, PermTaskUpdateAny and PermTaskManageAny give their
+	// holders the view, edit and manage permission on every task.
+	PermTaskReadAny   = "task:read:any"
+	PermTaskUpdateAny = "task:update:any"
+	PermTaskManageAny = "task:manage:any"
+	PermUserPromote   = "user:promote"
+	// PermUserManage lists, disables, deletes and resets the passwords of users.
+	PermUserManage      = "user:manage"
+	PermRoleManage      = "role:manage"
+	PermAuditRead       = "audit:read"
+	PermTrashPurge      = "trash:purge"
+	PermWebhookAllTasks = "webhook:all_tasks"
+)
+
+// Role is a named set of permissions that users are given.
+type Role struct {
+	ID          primitive.ObjectID
+	Name        string
+	Description string
+	Permissions []string
+	// BuiltIn roles cannot be deleted.
+	BuiltIn   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type Task struct {
@@ -20,4 +70,250 @@ type Task struct {
 	Duedate     time.Time
 	Status      string
 	UserID      primitive.ObjectID
+	CreatedAt   time.Time
+	// StatusHistory records every status transition, oldest first.
+	StatusHistory []StatusChange
+	// ParentID is the task this one is a subtask of; zero for top-level tasks.
+	ParentID primitive.ObjectID
+	// BlockedBy lists the tasks that must be done before this one can be.
+	BlockedBy []primitive.ObjectID
+	// AssigneeID is the user working on the task; zero when unassigned.
+	AssigneeID primitive.ObjectID
+	// Collaborators are the other users the task is shared with.
+	Collaborators []Collaborator
+	// Recurrence is set when the task is one occurrence of a repeating series.
+	Recurrence *Recurrence
+	// Tags are names of the owner's tags.
+	Tags []string
+	// ProjectID is the project the task belongs to; zero for personal tasks.
+	ProjectID primitive.ObjectID
+	// ExternalID identifies an imported task in the tool it came from, and is
+	// unique among its owner's tasks. It is empty for tasks created here.
+	ExternalID string
+	// ArchivedAt is when the task's project was archived; zero otherwise.
+	ArchivedAt time.Time
+	// Version counts the saved changes to the task. Repositories only apply
+	// an update made against the current version, then increment it.
+	Version int64
+	// DeletedAt is when the task was moved to the trash; zero while it is live.
+	DeletedAt time.Time
+	// DeletedBy is the user who moved the task to the trash.
+	DeletedBy primitive.ObjectID
+}
+
+// Recurrence ties a task to a series described by an RFC 5545 RRULE.
+// Completing an occurrence creates the next one.
+type Recurrence struct {
+	Rule       string             // RRULE, e.g. "FREQ=WEEKLY;BYDAY=MO,FR"
+	TimeZone   string             // IANA zone the rule is evaluated in
+	Start      time.Time          // due date of the first occurrence (DTSTART)
+	SeriesID   primitive.ObjectID // first task of the series
+	Occurrence int                // 1-based position in the series
+	NextTaskID primitive.ObjectID // set once the next occurrence exists
+}
+
+// Tag is a label a user defines for their tasks, such as an area of work.
+// Tasks carry tags by name, and names are unique per user.
+type Tag struct {
+	ID        primitive.ObjectID
+	UserID    primitive.ObjectID
+	Name      string
+	Color     string // "#rrggbb"
+	CreatedAt time.Time
+}
+
+// Project member roles. Owners manage the project and its tasks, editors
+// can create and change tasks, and viewers can read them.
+const (
+	ProjectOwner  = "owner"
+	ProjectEditor = "editor"
+	ProjectViewer = "viewer"
+)
+
+// Project groups tasks that a team of members shares.
+type Project struct {
+	ID          primitive.ObjectID
+	Name        string
+	Description string
+	// Members always include at least one owner.
+	Members   []ProjectMember
+	CreatedAt time.Time
+	// ArchivedAt is when the project was archived; zero while it is active.
+	ArchivedAt time.Time
+}
+
+// ProjectMember gives one user a role in a project.
+type ProjectMember struct {
+	UserID primitive.ObjectID
+	Role   string
+}
+
+// Collaborator roles. A viewer can read a task; an editor can also change it.
+const (
+	CollaboratorViewer = "viewer"
+	CollaboratorEditor = "editor"
+)
+
+// Collaborator grants one user access to a task they do not own.
+type Collaborator struct {
+	UserID primitive.ObjectID
+	Role   string
+}
+
+// StatusChange is one transition of a task's status. From is empty for the
+// status a task was created with.
+type StatusChange struct {
+	From      string
+	To        string
+	ChangedBy primitive.ObjectID
+	ChangedAt time.Time
+}
+
+// Reminder records a due-date reminder that was sent, so it is never sent
+// twice. A reminder belongs to one due date: moving the task schedules new ones.
+type Reminder struct {
+	ID      primitive.ObjectID
+	TaskID  primitive.ObjectID
+	UserID  primitive.ObjectID
+	DueDate time.Time
+	Offset  time.Duration
+	SentAt  time.Time
+}
+
+// RefreshToken is one link in a rotating refresh-token chain. Every token
+// minted from the same login shares a FamilyID, so a replayed token can
+// revoke the whole chain. Only a hash of the token value is stored.
+type RefreshToken struct {
+	ID              primitive.ObjectID
+	UserID          primitive.ObjectID
+	FamilyID        primitive.ObjectID
+	TokenHash       string
+	AccessTokenID   string // jti of the access token issued alongside it
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	CreatedAt       time.Time
+	UsedAt          time.Time // set once the token has been rotated
+	Revoked         bool
+}
+
+// PasswordResetToken lets a user who forgot their password set a new one.
+// It is mailed to the user, expires quickly and works once. Only a hash of
+// the token value is stored.
+type PasswordResetToken struct {
+	ID        primitive.ObjectID
+	UserID    primitive.ObjectID
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    time.Time // set once the token has been spent or superseded
+}
+
+// LoginAttempts counts the recent failed logins for one username or one
+// client address, so that guessing passwords gets slower and then stops.
+type LoginAttempts struct {
+	Key         string // what failed to log in, such as "username:alice"
+	Failures    int    // failures in a row, forgotten a while after the last
+	LastFailure time.Time
+	LockedUntil time.Time // zero while logins may be tried at once
+	ExpiresAt   time.Time // when the record no longer matters
+}
+
+// CalendarFeed lets a calendar app read a user's task deadlines through a
+// secret URL, since such apps cannot send a JWT. Deleting the feed revokes
+// its token. Only a hash of the token is stored.
+type CalendarFeed struct {
+	ID        primitive.ObjectID
+	UserID    primitive.ObjectID
+	Name      string // what the user calls it, such as the device it is on
+	TokenHash string
+	CreatedAt time.Time
+}
+
+// Webhook is an endpoint that is sent task events as signed JSON POSTs.
+type Webhook struct {
+	ID     primitive.ObjectID
+	UserID primitive.ObjectID
+	URL    string
+	// Secret keys the HMAC-SHA256 signature of every payload, so unlike
+	// tokens it has to be stored as is.
+	Secret string
+	// Events are the event types sent, such as "task.created"; empty means all.
+	Events []string
+	// AllTasks sends the events of every task instead of only those of tasks
+	// the user can see. Only admins can set it.
+	AllTasks bool
+	// FailedDeliveries counts the deliveries in a row that failed for good.
+	// A successful delivery resets it.
+	FailedDeliveries int
+	// DisabledAt is when the webhook was switched off, by its user or after
+	// too many failed deliveries; zero while it is enabled.
+	DisabledAt time.Time
+	CreatedAt  time.Time
+}
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one webhook, with the outcome of its
+// latest attempt. Redelivering an event creates a new delivery of it.
+type WebhookDelivery struct {
+	ID        primitive.ObjectID
+	WebhookID primitive.ObjectID
+	EventID   primitive.ObjectID
+	Event     string // event type, such as "task.created"
+	// Payload is the JSON body, kept so that every attempt sends the same bytes.
+	Payload  string
+	Status   string
+	Attempts int
+	// NextAttemptAt is when a pending delivery is tried next.
+	NextAttemptAt time.Time
+	LastAttemptAt time.Time
+	// ResponseStatus is the HTTP status of the last attempt; zero when no
+	// response came back.
+	ResponseStatus int
+	Error          string // why the last attempt failed
+	CreatedAt      time.Time
+}
+
+// Audit actions and entity types recorded in the audit log.
+const (
+	AuditEntityTask = "task"
+	AuditEntityRole = "role"
+	AuditEntityUser = "user"
+
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	// AuditActionRestore brings a task back from the trash, and
+	// AuditActionPurge removes it from the trash for good.
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+	// AuditActionLock records that failed logins locked a user out, and
+	// AuditActionUnlock that an admin let them back in.
+	AuditActionLock   = "lock"
+	AuditActionUnlock = "unlock"
+)
+
+// AuditEntry is an immutable record of one change to an entity, such as a
+// task: who made it, when, and the value of every field it touched.
+type AuditEntry struct {
+	ID         primitive.ObjectID
+	EntityType string // e.g. "task"
+	EntityID   primitive.ObjectID
+	Action     string
+	ActorID    primitive.ObjectID
+	Timestamp  time.Time
+	Changes    []FieldChange
+}
+
+// FieldChange is a field's value before and after a change. Before is empty
+// for a create and After is empty for a delete.
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
 }
@@ -4,10 +4,13 @@ package mocks
 
 import (
 	domain "taskmanager/domain"
+	infrastructure "taskmanager/infrastructure"
 
 	jwt "github.com/golang-jwt/jwt/v5"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // IJWTService is an autogenerated mock type for the IJWTService type
@@ -15,23 +18,55 @@ type IJWTService struct {
 	mock.Mock
 }
 
+// GenerateStreamTicket provides a mock function with given fields: userID, sessionID, sessionExpiresAt
+func (_m *IJWTService) GenerateStreamTicket(userID string, sessionID string, sessionExpiresAt time.Time) (*infrastructure.AccessToken, error) {
+	ret := _m.Called(userID, sessionID, sessionExpiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GenerateStreamTicket")
+	}
+
+	var r0 *infrastructure.AccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) (*infrastructure.AccessToken, error)); ok {
+		return rf(userID, sessionID, sessionExpiresAt)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, time.Time) *infrastructure.AccessToken); ok {
+		r0 = rf(userID, sessionID, sessionExpiresAt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*infrastructure.AccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, time.Time) error); ok {
+		r1 = rf(userID, sessionID, sessionExpiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GenerateToken provides a mock function with given fields: user
-func (_m *IJWTService) GenerateToken(user domain.User) (string, error) {
+func (_m *IJWTService) GenerateToken(user domain.User) (*infrastructure.AccessToken, error) {
 	ret := _m.Called(user)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateToken")
 	}
 
-	var r0 string
+	var r0 *infrastructure.AccessToken
 	var r1 error
-	if rf, ok := ret.Get(0).(func(domain.User) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(domain.User) (*infrastructure.AccessToken, error)); ok {
 		return rf(user)
 	}
-	if rf, ok := ret.Get(0).(func(domain.User) string); ok {
+	if rf, ok := ret.Get(0).(func(domain.User) *infrastructure.AccessToken); ok {
 		r0 = rf(user)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*infrastructure.AccessToken)
+		}
 	}
 
 	if rf, ok := ret.Get(1).(func(domain.User) error); ok {
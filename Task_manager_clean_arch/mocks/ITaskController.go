@@ -12,6 +12,16 @@ type ITaskController struct {
 	mock.Mock
 }
 
+// AssignTask provides a mock function with given fields: c
+func (_m *ITaskController) AssignTask(c *gin.Context) {
+	_m.Called(c)
+}
+
+// CreateProjectTask provides a mock function with given fields: c
+func (_m *ITaskController) CreateProjectTask(c *gin.Context) {
+	_m.Called(c)
+}
+
 // CreateTask provides a mock function with given fields: c
 func (_m *ITaskController) CreateTask(c *gin.Context) {
 	_m.Called(c)
@@ -22,16 +32,91 @@ func (_m *ITaskController) DeleteTask(c *gin.Context) {
 	_m.Called(c)
 }
 
+// ExportTasks provides a mock function with given fields: c
+func (_m *ITaskController) ExportTasks(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetDependencies provides a mock function with given fields: c
+func (_m *ITaskController) GetDependencies(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetOccurrences provides a mock function with given fields: c
+func (_m *ITaskController) GetOccurrences(c *gin.Context) {
+	_m.Called(c)
+}
+
+// GetSubtasks provides a mock function with given fields: c
+func (_m *ITaskController) GetSubtasks(c *gin.Context) {
+	_m.Called(c)
+}
+
 // GetTaskByID provides a mock function with given fields: c
 func (_m *ITaskController) GetTaskByID(c *gin.Context) {
 	_m.Called(c)
 }
 
+// GetTaskHistory provides a mock function with given fields: c
+func (_m *ITaskController) GetTaskHistory(c *gin.Context) {
+	_m.Called(c)
+}
+
 // GetUserTasks provides a mock function with given fields: c
 func (_m *ITaskController) GetUserTasks(c *gin.Context) {
 	_m.Called(c)
 }
 
+// ImportTasks provides a mock function with given fields: c
+func (_m *ITaskController) ImportTasks(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ListProjectTasks provides a mock function with given fields: c
+func (_m *ITaskController) ListProjectTasks(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ListTrash provides a mock function with given fields: c
+func (_m *ITaskController) ListTrash(c *gin.Context) {
+	_m.Called(c)
+}
+
+// PatchTask provides a mock function with given fields: c
+func (_m *ITaskController) PatchTask(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RestoreTask provides a mock function with given fields: c
+func (_m *ITaskController) RestoreTask(c *gin.Context) {
+	_m.Called(c)
+}
+
+// RunBatch provides a mock function with given fields: c
+func (_m *ITaskController) RunBatch(c *gin.Context) {
+	_m.Called(c)
+}
+
+// SearchTasks provides a mock function with given fields: c
+func (_m *ITaskController) SearchTasks(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ShareTask provides a mock function with given fields: c
+func (_m *ITaskController) ShareTask(c *gin.Context) {
+	_m.Called(c)
+}
+
+// UnassignTask provides a mock function with given fields: c
+func (_m *ITaskController) UnassignTask(c *gin.Context) {
+	_m.Called(c)
+}
+
+// UnshareTask provides a mock function with given fields: c
+func (_m *ITaskController) UnshareTask(c *gin.Context) {
+	_m.Called(c)
+}
+
 // UpdateTask provides a mock function with given fields: c
 func (_m *ITaskController) UpdateTask(c *gin.Context) {
 	_m.Called(c)
@@ -9,6 +9,10 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	repositories "taskmanager/repositories"
+
+	time "time"
 )
 
 // ITaskRepository is an autogenerated mock type for the ITaskRepository type
@@ -16,6 +20,24 @@ type ITaskRepository struct {
 	mock.Mock
 }
 
+// ArchiveProject provides a mock function with given fields: ctx, projectID, archivedAt
+func (_m *ITaskRepository) ArchiveProject(ctx context.Context, projectID primitive.ObjectID, archivedAt time.Time) error {
+	ret := _m.Called(ctx, projectID, archivedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ArchiveProject")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, time.Time) error); ok {
+		r0 = rf(ctx, projectID, archivedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: ctx, task
 func (_m *ITaskRepository) Create(ctx context.Context, task *domain.Task) error {
 	ret := _m.Called(ctx, task)
@@ -82,6 +104,36 @@ func (_m *ITaskRepository) GetAllByUserID(ctx context.Context, userID primitive.
 	return r0, r1
 }
 
+// GetByExternalID provides a mock function with given fields: ctx, userID, externalID
+func (_m *ITaskRepository) GetByExternalID(ctx context.Context, userID primitive.ObjectID, externalID string) (*domain.Task, error) {
+	ret := _m.Called(ctx, userID, externalID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByExternalID")
+	}
+
+	var r0 *domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) (*domain.Task, error)); ok {
+		return rf(ctx, userID, externalID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) *domain.Task); ok {
+		r0 = rf(ctx, userID, externalID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID, string) error); ok {
+		r1 = rf(ctx, userID, externalID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByID provides a mock function with given fields: ctx, id
 func (_m *ITaskRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
 	ret := _m.Called(ctx, id)
@@ -112,6 +164,295 @@ func (_m *ITaskRepository) GetByID(ctx context.Context, id primitive.ObjectID) (
 	return r0, r1
 }
 
+// GetByIDs provides a mock function with given fields: ctx, ids
+func (_m *ITaskRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Task, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIDs")
+	}
+
+	var r0 []domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) ([]domain.Task, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) []domain.Task); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []primitive.ObjectID) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTrashedByID provides a mock function with given fields: ctx, id
+func (_m *ITaskRepository) GetTrashedByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTrashedByID")
+	}
+
+	var r0 *domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) (*domain.Task, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) *domain.Task); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListBlockedTasks provides a mock function with given fields: ctx, blockerID
+func (_m *ITaskRepository) ListBlockedTasks(ctx context.Context, blockerID primitive.ObjectID) ([]domain.Task, error) {
+	ret := _m.Called(ctx, blockerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBlockedTasks")
+	}
+
+	var r0 []domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) ([]domain.Task, error)); ok {
+		return rf(ctx, blockerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) []domain.Task); ok {
+		r0 = rf(ctx, blockerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, blockerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListDueBetween provides a mock function with given fields: ctx, from, to
+func (_m *ITaskRepository) ListDueBetween(ctx context.Context, from time.Time, to time.Time) ([]domain.Task, error) {
+	ret := _m.Called(ctx, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListDueBetween")
+	}
+
+	var r0 []domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) ([]domain.Task, error)); ok {
+		return rf(ctx, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []domain.Task); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSubtasks provides a mock function with given fields: ctx, parentID
+func (_m *ITaskRepository) ListSubtasks(ctx context.Context, parentID primitive.ObjectID) ([]domain.Task, error) {
+	ret := _m.Called(ctx, parentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSubtasks")
+	}
+
+	var r0 []domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) ([]domain.Task, error)); ok {
+		return rf(ctx, parentID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) []domain.Task); ok {
+		r0 = rf(ctx, parentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, primitive.ObjectID) error); ok {
+		r1 = rf(ctx, parentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTasks provides a mock function with given fields: ctx, query
+func (_m *ITaskRepository) ListTasks(ctx context.Context, query repositories.TaskQuery) ([]domain.Task, string, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTasks")
+	}
+
+	var r0 []domain.Task
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, repositories.TaskQuery) ([]domain.Task, string, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repositories.TaskQuery) []domain.Task); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repositories.TaskQuery) string); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, repositories.TaskQuery) error); ok {
+		r2 = rf(ctx, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListTrash provides a mock function with given fields: ctx, query
+func (_m *ITaskRepository) ListTrash(ctx context.Context, query repositories.TrashQuery) ([]domain.Task, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListTrash")
+	}
+
+	var r0 []domain.Task
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, repositories.TrashQuery) ([]domain.Task, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repositories.TrashQuery) []domain.Task); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Task)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repositories.TrashQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveTag provides a mock function with given fields: ctx, userID, name
+func (_m *ITaskRepository) RemoveTag(ctx context.Context, userID primitive.ObjectID, name string) error {
+	ret := _m.Called(ctx, userID, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveTag")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string) error); ok {
+		r0 = rf(ctx, userID, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveTaskLinks provides a mock function with given fields: ctx, taskIDs
+func (_m *ITaskRepository) RemoveTaskLinks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	ret := _m.Called(ctx, taskIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveTaskLinks")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []primitive.ObjectID) error); ok {
+		r0 = rf(ctx, taskIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RemoveUser provides a mock function with given fields: ctx, userID
+func (_m *ITaskRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RenameTag provides a mock function with given fields: ctx, userID, from, to
+func (_m *ITaskRepository) RenameTag(ctx context.Context, userID primitive.ObjectID, from string, to string) error {
+	ret := _m.Called(ctx, userID, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenameTag")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID, string, string) error); ok {
+		r0 = rf(ctx, userID, from, to)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, task
 func (_m *ITaskRepository) Update(ctx context.Context, task *domain.Task) error {
 	ret := _m.Called(ctx, task)
@@ -130,6 +471,24 @@ func (_m *ITaskRepository) Update(ctx context.Context, task *domain.Task) error
 	return r0
 }
 
+// UpdateFields provides a mock function with given fields: ctx, task, fields
+func (_m *ITaskRepository) UpdateFields(ctx context.Context, task *domain.Task, fields []repositories.TaskField) error {
+	ret := _m.Called(ctx, task, fields)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFields")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.Task, []repositories.TaskField) error); ok {
+		r0 = rf(ctx, task, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewITaskRepository creates a new instance of ITaskRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewITaskRepository(t interface {
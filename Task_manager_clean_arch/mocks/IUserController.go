@@ -12,13 +12,28 @@ type IUserController struct {
 	mock.Mock
 }
 
+// ChangePassword provides a mock function with given fields: c
+func (_m *IUserController) ChangePassword(c *gin.Context) {
+	_m.Called(c)
+}
+
+// ConfirmPasswordReset provides a mock function with given fields: c
+func (_m *IUserController) ConfirmPasswordReset(c *gin.Context) {
+	_m.Called(c)
+}
+
 // Login provides a mock function with given fields: c
 func (_m *IUserController) Login(c *gin.Context) {
 	_m.Called(c)
 }
 
-// Promote provides a mock function with given fields: c
-func (_m *IUserController) Promote(c *gin.Context) {
+// Logout provides a mock function with given fields: c
+func (_m *IUserController) Logout(c *gin.Context) {
+	_m.Called(c)
+}
+
+// Refresh provides a mock function with given fields: c
+func (_m *IUserController) Refresh(c *gin.Context) {
 	_m.Called(c)
 }
 
@@ -27,6 +42,11 @@ func (_m *IUserController) Register(c *gin.Context) {
 	_m.Called(c)
 }
 
+// RequestPasswordReset provides a mock function with given fields: c
+func (_m *IUserController) RequestPasswordReset(c *gin.Context) {
+	_m.Called(c)
+}
+
 // NewIUserController creates a new instance of IUserController. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIUserController(t interface {
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	primitive "go.mongodb.org/mongo-driver/bson/primitive"
+
+	repositories "taskmanager/repositories"
 )
 
 // IUserRepository is an autogenerated mock type for the IUserRepository type
@@ -44,6 +46,34 @@ func (_m *IUserRepository) Count(ctx context.Context) (int64, error) {
 	return r0, r1
 }
 
+// CountByRole provides a mock function with given fields: ctx, role
+func (_m *IUserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	ret := _m.Called(ctx, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountByRole")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, role)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, user
 func (_m *IUserRepository) Create(ctx context.Context, user *domain.User) error {
 	ret := _m.Called(ctx, user)
@@ -62,6 +92,24 @@ func (_m *IUserRepository) Create(ctx context.Context, user *domain.User) error
 	return r0
 }
 
+// Delete provides a mock function with given fields: ctx, id
+func (_m *IUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, primitive.ObjectID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FindByID provides a mock function with given fields: ctx, id
 func (_m *IUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
 	ret := _m.Called(ctx, id)
@@ -122,6 +170,43 @@ func (_m *IUserRepository) FindByUsername(ctx context.Context, username string)
 	return r0, r1
 }
 
+// List provides a mock function with given fields: ctx, query
+func (_m *IUserRepository) List(ctx context.Context, query repositories.UserQuery) ([]domain.User, string, error) {
+	ret := _m.Called(ctx, query)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []domain.User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, repositories.UserQuery) ([]domain.User, string, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, repositories.UserQuery) []domain.User); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, repositories.UserQuery) string); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, repositories.UserQuery) error); ok {
+		r2 = rf(ctx, query)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Update provides a mock function with given fields: ctx, user
 func (_m *IUserRepository) Update(ctx context.Context, user *domain.User) error {
 	ret := _m.Called(ctx, user)
@@ -140,6 +225,24 @@ func (_m *IUserRepository) Update(ctx context.Context, user *domain.User) error
 	return r0
 }
 
+// UpdateFields provides a mock function with given fields: ctx, user, fields
+func (_m *IUserRepository) UpdateFields(ctx context.Context, user *domain.User, fields []repositories.UserField) error {
+	ret := _m.Called(ctx, user, fields)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFields")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *domain.User, []repositories.UserField) error); ok {
+		r0 = rf(ctx, user, fields)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewIUserRepository creates a new instance of IUserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIUserRepository(t interface {
@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IAuditRepository is an append-only store of audit entries. It deliberately
+// offers no way to change or remove an entry once written.
+type IAuditRepository interface {
+	Append(ctx context.Context, entry *domain.AuditEntry) error
+	List(ctx context.Context, query AuditQuery) ([]domain.AuditEntry, string, error)
+}
+
+// AuditQuery describes a filtered page of audit entries, newest first.
+// Zero values mean "no filter".
+type AuditQuery struct {
+	EntityType string
+	EntityID   primitive.ObjectID
+	ActorID    primitive.ObjectID
+	Action     string
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+	Limit      int
+	Cursor     string
+}
+
+// auditCursor records the timestamp and ID of the last entry on a page.
+type auditCursor struct {
+	Timestamp string `json:"t"`
+	ID        string `json:"id"`
+}
+
+func encodeAuditCursor(entry *domain.AuditEntry) string {
+	raw, _ := json.Marshal(auditCursor{
+		Timestamp: entry.Timestamp.UTC().Format(time.RFC3339Nano),
+		ID:        entry.ID.Hex(),
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeAuditCursor returns the position encoded in an audit cursor.
+func decodeAuditCursor(cursor string) (time.Time, primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, ErrInvalidCursor
+	}
+	var cur auditCursor
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return time.Time{}, primitive.NilObjectID, ErrInvalidCursor
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, cur.Timestamp)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, ErrInvalidCursor
+	}
+	id, err := primitive.ObjectIDFromHex(cur.ID)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, ErrInvalidCursor
+	}
+	return timestamp, id, nil
+}
+
+// pageAuditEntries trims a result fetched with Limit+1 rows down to one page
+// and returns the cursor for the next page, or "" on the last one.
+func pageAuditEntries(entries []domain.AuditEntry, q AuditQuery) ([]domain.AuditEntry, string, error) {
+	if q.Limit <= 0 || len(entries) <= q.Limit {
+		return entries, "", nil
+	}
+	entries = entries[:q.Limit]
+	return entries, encodeAuditCursor(&entries[len(entries)-1]), nil
+}
+
+// mongoAuditRepository is the concrete implementation.
+type mongoAuditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository is the constructor.
+func NewAuditRepository(db *mongo.Database) IAuditRepository {
+	collection := db.Collection("audit_log")
+	_, _ = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "entity_id", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "actor_id", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+	})
+	return &mongoAuditRepository{collection: collection}
+}
+
+// toBsonAuditEntry converts a domain.AuditEntry to its BSON model.
+func toBsonAuditEntry(entry *domain.AuditEntry) *datamodels.AuditEntry {
+	changes := make([]datamodels.FieldChange, len(entry.Changes))
+	for i, c := range entry.Changes {
+		changes[i] = datamodels.FieldChange{Field: c.Field, Before: c.Before, After: c.After}
+	}
+	return &datamodels.AuditEntry{
+		ID:         entry.ID,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		ActorID:    entry.ActorID,
+		Timestamp:  entry.Timestamp,
+		Changes:    changes,
+	}
+}
+
+// toDomainAuditEntry converts a BSON audit entry to a domain.AuditEntry.
+func toDomainAuditEntry(entry *datamodels.AuditEntry) domain.AuditEntry {
+	var changes []domain.FieldChange
+	for _, c := range entry.Changes {
+		changes = append(changes, domain.FieldChange{Field: c.Field, Before: c.Before, After: c.After})
+	}
+	return domain.AuditEntry{
+		ID:         entry.ID,
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Action:     entry.Action,
+		ActorID:    entry.ActorID,
+		Timestamp:  entry.Timestamp,
+		Changes:    changes,
+	}
+}
+
+func (r *mongoAuditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	result, err := r.collection.InsertOne(ctx, toBsonAuditEntry(entry))
+	if err != nil {
+		return err
+	}
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoAuditRepository) List(ctx context.Context, q AuditQuery) ([]domain.AuditEntry, string, error) {
+	filter := bson.M{}
+	if q.EntityType != "" {
+		filter["entity_type"] = q.EntityType
+	}
+	if !q.EntityID.IsZero() {
+		filter["entity_id"] = q.EntityID
+	}
+	if !q.ActorID.IsZero() {
+		filter["actor_id"] = q.ActorID
+	}
+	if q.Action != "" {
+		filter["action"] = q.Action
+	}
+	timeRange := bson.M{}
+	if !q.From.IsZero() {
+		timeRange["$gte"] = q.From
+	}
+	if !q.To.IsZero() {
+		timeRange["$lt"] = q.To
+	}
+	if len(timeRange) > 0 {
+		filter["timestamp"] = timeRange
+	}
+
+	if q.Cursor != "" {
+		lastTime, lastID, err := decodeAuditCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		// Resume strictly after the last (timestamp, _id) pair, newest first.
+		after := bson.M{"$or": []bson.M{
+			{"timestamp": bson.M{"$lt": lastTime}},
+			{"timestamp": lastTime, "_id": bson.M{"$lt": lastID}},
+		}}
+		filter = bson.M{"$and": []bson.M{filter, after}}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}})
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit) + 1)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonEntries []datamodels.AuditEntry
+	if err = cursor.All(ctx, &bsonEntries); err != nil {
+		return nil, "", err
+	}
+	entries := make([]domain.AuditEntry, len(bsonEntries))
+	for i := range bsonEntries {
+		entries[i] = toDomainAuditEntry(&bsonEntries[i])
+	}
+	return pageAuditEntries(entries, q)
+}
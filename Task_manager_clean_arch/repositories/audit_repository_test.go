@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AuditRepositoryTestSuite exercises an IAuditRepository implementation.
+type AuditRepositoryTestSuite struct {
+	suite.Suite
+	backend   testBackend
+	auditRepo IAuditRepository
+}
+
+// SetupTest gives every test an empty repository.
+func (s *AuditRepositoryTestSuite) SetupTest() {
+	s.auditRepo = s.backend.open(s.T()).Audit
+}
+
+func TestAuditRepository(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			suite.Run(t, &AuditRepositoryTestSuite{backend: backend})
+		})
+	}
+}
+
+func (s *AuditRepositoryTestSuite) TestAppendAndFilter() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Millisecond)
+	taskID, otherTaskID := primitive.NewObjectID(), primitive.NewObjectID()
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	entries := []*domain.AuditEntry{
+		{EntityType: "task", EntityID: taskID, Action: domain.AuditActionCreate, ActorID: alice, Timestamp: start,
+			Changes: []domain.FieldChange{{Field: "title", After: "Draft"}}},
+		{EntityType: "task", EntityID: taskID, Action: domain.AuditActionUpdate, ActorID: bob, Timestamp: start.Add(time.Minute),
+			Changes: []domain.FieldChange{{Field: "title", Before: "Draft", After: "Final"}}},
+		{EntityType: "task", EntityID: otherTaskID, Action: domain.AuditActionDelete, ActorID: alice, Timestamp: start.Add(2 * time.Minute)},
+	}
+	for _, entry := range entries {
+		assert.NoError(s.auditRepo.Append(ctx, entry))
+		assert.False(entry.ID.IsZero())
+	}
+
+	// Newest first.
+	all, next, err := s.auditRepo.List(ctx, AuditQuery{})
+	assert.NoError(err)
+	assert.Empty(next)
+	if assert.Len(all, 3) {
+		assert.Equal(entries[2].ID, all[0].ID)
+		assert.Equal(entries[0].ID, all[2].ID)
+		assert.Equal([]domain.FieldChange{{Field: "title", After: "Draft"}}, all[2].Changes)
+		assert.True(start.Equal(all[2].Timestamp))
+	}
+
+	history, _, err := s.auditRepo.List(ctx, AuditQuery{EntityType: "task", EntityID: taskID})
+	assert.NoError(err)
+	assert.Len(history, 2)
+
+	byAlice, _, err := s.auditRepo.List(ctx, AuditQuery{ActorID: alice})
+	assert.NoError(err)
+	assert.Len(byAlice, 2)
+
+	updates, _, err := s.auditRepo.List(ctx, AuditQuery{Action: domain.AuditActionUpdate})
+	assert.NoError(err)
+	if assert.Len(updates, 1) {
+		assert.Equal(bob, updates[0].ActorID)
+	}
+
+	window, _, err := s.auditRepo.List(ctx, AuditQuery{From: start.Add(time.Minute), To: start.Add(2 * time.Minute)})
+	assert.NoError(err)
+	if assert.Len(window, 1) {
+		assert.Equal(entries[1].ID, window[0].ID)
+	}
+}
+
+func (s *AuditRepositoryTestSuite) TestListPagesWithCursor() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	// Two entries share a timestamp, so the ID must break the tie.
+	for _, offset := range []time.Duration{0, time.Second, time.Second, 2 * time.Second, 3 * time.Second} {
+		entry := &domain.AuditEntry{EntityType: "task", EntityID: primitive.NewObjectID(), Action: domain.AuditActionCreate,
+			ActorID: primitive.NewObjectID(), Timestamp: now.Add(offset)}
+		assert.NoError(s.auditRepo.Append(ctx, entry))
+	}
+
+	seen := map[primitive.ObjectID]bool{}
+	query := AuditQuery{Limit: 2}
+	var last time.Time
+	for page := 0; page < 5; page++ {
+		entries, next, err := s.auditRepo.List(ctx, query)
+		assert.NoError(err)
+		for _, entry := range entries {
+			assert.False(seen[entry.ID], "entry returned twice")
+			seen[entry.ID] = true
+			if !last.IsZero() {
+				assert.False(entry.Timestamp.After(last), "entries must be newest first")
+			}
+			last = entry.Timestamp
+		}
+		if next == "" {
+			break
+		}
+		query.Cursor = next
+	}
+	assert.Len(seen, 5)
+
+	_, _, err := s.auditRepo.List(ctx, AuditQuery{Cursor: "not-a-cursor"})
+	assert.ErrorIs(err, ErrInvalidCursor)
+}
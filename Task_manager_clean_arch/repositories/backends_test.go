@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testBackend opens a fresh, empty set of repositories for one storage
+// implementation. The repository suites run once per backend.
+type testBackend struct {
+	name string
+	open func(t *testing.T) *Repositories
+}
+
+func testBackends() []testBackend {
+	return []testBackend{
+		{name: "memory", open: openMemoryBackend},
+		{name: "sqlite", open: openSQLiteBackend},
+		{name: "mongo", open: openMongoBackend},
+	}
+}
+
+func openMemoryBackend(t *testing.T) *Repositories {
+	return NewMemoryRepositories()
+}
+
+// openSQLiteBackend creates a migrated database file in a temp directory.
+func openSQLiteBackend(t *testing.T) *Repositories {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open SQLite for testing: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewSQLiteRepositories(db)
+}
+
+var (
+	mongoProbe    sync.Once
+	mongoProbeErr error
+)
+
+// mongoTestURI returns MONGO_TEST_URI, or the local default.
+func mongoTestURI() string {
+	if uri := os.Getenv("MONGO_TEST_URI"); uri != "" {
+		return uri
+	}
+	return "mongodb://localhost:27017"
+}
+
+// openMongoBackend connects to the test server and skips the test when no
+// server answers. Reachability is only probed once per test binary.
+func openMongoBackend(t *testing.T) *Repositories {
+	mongoProbe.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoTestURI()).SetServerSelectionTimeout(2*time.Second))
+		if err == nil {
+			err = client.Ping(ctx, nil)
+			_ = client.Disconnect(context.TODO())
+		}
+		mongoProbeErr = err
+	})
+	if mongoProbeErr != nil {
+		t.Skipf("MongoDB not reachable at %s: %v", mongoTestURI(), mongoProbeErr)
+	}
+
+	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(mongoTestURI()))
+	if err != nil {
+		t.Fatalf("Failed to connect to Mongo for testing: %v", err)
+	}
+
+	db := client.Database("taskmanager_testdb")
+	if err := db.Drop(context.TODO()); err != nil {
+		t.Fatalf("Failed to reset test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := db.Drop(context.TODO()); err != nil {
+			t.Errorf("Failed to drop test database: %v", err)
+		}
+		if err := client.Disconnect(context.TODO()); err != nil {
+			t.Errorf("Failed to disconnect from Mongo: %v", err)
+		}
+	})
+	return NewMongoRepositories(db)
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ICalendarFeedRepository stores calendar feeds. Token hashes are unique:
+// Create fails with a duplicate key error otherwise.
+type ICalendarFeedRepository interface {
+	Create(ctx context.Context, feed *domain.CalendarFeed) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.CalendarFeed, error)
+	FindByTokenHash(ctx context.Context, hash string) (*domain.CalendarFeed, error)
+	// ListByUserID returns the user's feeds, oldest first.
+	ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.CalendarFeed, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// mongoCalendarFeedRepository is the concrete implementation.
+type mongoCalendarFeedRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCalendarFeedRepository is the constructor.
+func NewCalendarFeedRepository(db *mongo.Database) ICalendarFeedRepository {
+	collection := db.Collection("calendar_feeds")
+	_, _ = collection.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"token_hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	return &mongoCalendarFeedRepository{collection: collection}
+}
+
+func toBsonCalendarFeed(feed *domain.CalendarFeed) *datamodels.CalendarFeed {
+	return &datamodels.CalendarFeed{ID: feed.ID, UserID: feed.UserID, Name: feed.Name, TokenHash: feed.TokenHash, CreatedAt: feed.CreatedAt}
+}
+
+func toDomainCalendarFeed(feed *datamodels.CalendarFeed) *domain.CalendarFeed {
+	return &domain.CalendarFeed{ID: feed.ID, UserID: feed.UserID, Name: feed.Name, TokenHash: feed.TokenHash, CreatedAt: feed.CreatedAt}
+}
+
+func (r *mongoCalendarFeedRepository) Create(ctx context.Context, feed *domain.CalendarFeed) error {
+	result, err := r.collection.InsertOne(ctx, toBsonCalendarFeed(feed))
+	if err != nil {
+		return err
+	}
+	feed.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoCalendarFeedRepository) findOne(ctx context.Context, filter bson.M) (*domain.CalendarFeed, error) {
+	var bsonFeed datamodels.CalendarFeed
+	if err := r.collection.FindOne(ctx, filter).Decode(&bsonFeed); err != nil {
+		return nil, err
+	}
+	return toDomainCalendarFeed(&bsonFeed), nil
+}
+
+func (r *mongoCalendarFeedRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.CalendarFeed, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoCalendarFeedRepository) FindByTokenHash(ctx context.Context, hash string) (*domain.CalendarFeed, error) {
+	return r.findOne(ctx, bson.M{"token_hash": hash})
+}
+
+func (r *mongoCalendarFeedRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.CalendarFeed, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonFeeds []datamodels.CalendarFeed
+	if err := cursor.All(ctx, &bsonFeeds); err != nil {
+		return nil, err
+	}
+	feeds := make([]domain.CalendarFeed, len(bsonFeeds))
+	for i := range bsonFeeds {
+		feeds[i] = *toDomainCalendarFeed(&bsonFeeds[i])
+	}
+	return feeds, nil
+}
+
+func (r *mongoCalendarFeedRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
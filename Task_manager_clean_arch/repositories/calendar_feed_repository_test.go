@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCalendarFeedRepository(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			feeds := backend.open(t).CalendarFeeds
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+			phone := &domain.CalendarFeed{UserID: alice, Name: "Phone", TokenHash: "hash-1", CreatedAt: now}
+			laptop := &domain.CalendarFeed{UserID: alice, Name: "Laptop", TokenHash: "hash-2", CreatedAt: now.Add(time.Minute)}
+			require.NoError(t, feeds.Create(ctx, laptop))
+			require.NoError(t, feeds.Create(ctx, phone))
+			require.NoError(t, feeds.Create(ctx, &domain.CalendarFeed{UserID: bob, Name: "Work", TokenHash: "hash-3", CreatedAt: now}))
+			err := feeds.Create(ctx, &domain.CalendarFeed{UserID: bob, Name: "Copy", TokenHash: "hash-1", CreatedAt: now})
+			assert.True(t, mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+
+			found, err := feeds.FindByTokenHash(ctx, "hash-1")
+			require.NoError(t, err)
+			assert.Equal(t, phone.ID, found.ID)
+			assert.Equal(t, "Phone", found.Name)
+			assert.True(t, now.Equal(found.CreatedAt))
+			listed, err := feeds.ListByUserID(ctx, alice)
+			require.NoError(t, err)
+			require.Len(t, listed, 2)
+			assert.Equal(t, []string{"Phone", "Laptop"}, []string{listed[0].Name, listed[1].Name})
+
+			require.NoError(t, feeds.Delete(ctx, phone.ID))
+
+			// --- ASSERT ---
+			_, err = feeds.FindByTokenHash(ctx, "hash-1")
+			assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+			_, err = feeds.GetByID(ctx, phone.ID)
+			assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+			found, err = feeds.GetByID(ctx, laptop.ID)
+			require.NoError(t, err)
+			assert.Equal(t, alice, found.UserID)
+		})
+	}
+}
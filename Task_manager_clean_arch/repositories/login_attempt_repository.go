@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ILoginAttemptRepository counts failed logins per key, such as a username
+// or a client address. It is shared by every server instance, so the count
+// holds across restarts and load balancers. Password reset requests are
+// counted in it too, under keys of their own.
+type ILoginAttemptRepository interface {
+	// Find returns mongo.ErrNoDocuments when the key has no record.
+	Find(ctx context.Context, key string) (*domain.LoginAttempts, error)
+	// RecordFailure atomically counts a failed login at the given time and
+	// returns the updated record. The count starts over when the previous
+	// failure is more than window old, and the record expires window after
+	// the failure unless a lock lasts longer. It keeps any lock as it is.
+	RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempts, error)
+	// Lock makes logins for the key wait until the given time. It only ever
+	// extends a lock, so a short wait never cuts a longer one short, and it
+	// does nothing when the key has no record.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// UndoFailure takes back one failure RecordFailure counted, for an
+	// attempt that was counted before it was checked and then succeeded. It
+	// does nothing when the key has no record or no failures.
+	UndoFailure(ctx context.Context, key string) error
+	// Delete forgets the key's failures and lock. It does nothing when the
+	// key has no record.
+	Delete(ctx context.Context, key string) error
+}
+
+// mongoLoginAttemptRepository is the concrete implementation.
+type mongoLoginAttemptRepository struct {
+	collection *mongo.Collection
+}
+
+// NewLoginAttemptRepository is the constructor.
+func NewLoginAttemptRepository(db *mongo.Database) ILoginAttemptRepository {
+	collection := db.Collection("login_attempts")
+	// Let Mongo drop records once they no longer matter.
+	_, _ = collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return &mongoLoginAttemptRepository{collection: collection}
+}
+
+func toDomainLoginAttempts(attempts *datamodels.LoginAttempts) *domain.LoginAttempts {
+	return &domain.LoginAttempts{
+		Key:         attempts.Key,
+		Failures:    attempts.Failures,
+		LastFailure: attempts.LastFailure,
+		LockedUntil: attempts.LockedUntil,
+		ExpiresAt:   attempts.ExpiresAt,
+	}
+}
+
+func (r *mongoLoginAttemptRepository) Find(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	var bsonAttempts datamodels.LoginAttempts
+	if err := r.collection.FindOne(ctx, bson.M{"_id": key}).Decode(&bsonAttempts); err != nil {
+		return nil, err
+	}
+	return toDomainLoginAttempts(&bsonAttempts), nil
+}
+
+func (r *mongoLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempts, error) {
+	// An update pipeline reads the previous failure and writes the new count
+	// in one atomic step. A new record has no last_failure, which compares
+	// below any date and so starts the count at 1.
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: "failures", Value: bson.D{{Key: "$cond", Value: bson.A{
+			bson.D{{Key: "$gte", Value: bson.A{"$last_failure", at.Add(-window)}}},
+			bson.D{{Key: "$add", Value: bson.A{"$failures", 1}}},
+			1,
+		}}}},
+		{Key: "last_failure", Value: at},
+		{Key: "locked_until", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$locked_until", time.Time{}}}}},
+		{Key: "expires_at", Value: bson.D{{Key: "$max", Value: bson.A{"$locked_until", at.Add(window)}}}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var bsonAttempts datamodels.LoginAttempts
+	if err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&bsonAttempts); err != nil {
+		return nil, err
+	}
+	return toDomainLoginAttempts(&bsonAttempts), nil
+}
+
+func (r *mongoLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	update := bson.M{"$max": bson.M{"locked_until": until, "expires_at": until}}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key}, update)
+	return err
+}
+
+func (r *mongoLoginAttemptRepository) UndoFailure(ctx context.Context, key string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key, "failures": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"failures": -1}})
+	return err
+}
+
+func (r *mongoLoginAttemptRepository) Delete(ctx context.Context, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
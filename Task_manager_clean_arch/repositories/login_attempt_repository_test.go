@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestLoginAttemptRepository(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			attempts := backend.open(t).LoginAttempts
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			window := 15 * time.Minute
+
+			_, missingErr := attempts.Find(ctx, "username:alice")
+			require.NoError(t, attempts.Lock(ctx, "username:alice", now.Add(time.Hour)), "locking an unknown key does nothing")
+			first, err := attempts.RecordFailure(ctx, "username:alice", now, window)
+			require.NoError(t, err)
+			second, err := attempts.RecordFailure(ctx, "username:alice", now.Add(time.Minute), window)
+			require.NoError(t, err)
+			require.NoError(t, attempts.Lock(ctx, "username:alice", now.Add(time.Hour)))
+			require.NoError(t, attempts.Lock(ctx, "username:alice", now.Add(2*time.Minute)), "a shorter wait keeps the lock")
+			_, err = attempts.RecordFailure(ctx, "ip:10.0.0.1", now.Add(20*time.Minute), window)
+			require.NoError(t, err)
+			locked, err := attempts.Find(ctx, "username:alice")
+			require.NoError(t, err)
+			// A failure long after the previous one starts the count over,
+			// but leaves the lock alone.
+			restarted, err := attempts.RecordFailure(ctx, "username:alice", now.Add(30*time.Minute), window)
+			require.NoError(t, err)
+			require.NoError(t, attempts.UndoFailure(ctx, "username:alice"))
+			require.NoError(t, attempts.UndoFailure(ctx, "username:alice"), "no failures are left to take back")
+			undone, err := attempts.Find(ctx, "username:alice")
+			require.NoError(t, err)
+			require.NoError(t, attempts.UndoFailure(ctx, "username:bob"), "undoing an unknown key does nothing")
+			require.NoError(t, attempts.Delete(ctx, "username:alice"))
+			_, deletedErr := attempts.Find(ctx, "username:alice")
+			other, err := attempts.Find(ctx, "ip:10.0.0.1")
+			require.NoError(t, err)
+
+			// --- ASSERT ---
+			assert.ErrorIs(t, missingErr, mongo.ErrNoDocuments)
+			assert.Equal(t, 1, first.Failures)
+			assert.True(t, first.LockedUntil.IsZero())
+			assert.True(t, now.Add(window).Equal(first.ExpiresAt))
+			assert.Equal(t, 2, second.Failures)
+			assert.True(t, now.Add(time.Minute).Equal(second.LastFailure))
+			assert.True(t, now.Add(time.Hour).Equal(locked.LockedUntil))
+			assert.True(t, now.Add(time.Hour).Equal(locked.ExpiresAt), "a lock outlasts the window")
+			assert.Equal(t, 1, restarted.Failures)
+			assert.True(t, now.Add(time.Hour).Equal(restarted.LockedUntil))
+			assert.True(t, now.Add(time.Hour).Equal(restarted.ExpiresAt))
+			assert.Zero(t, undone.Failures)
+			assert.True(t, now.Add(time.Hour).Equal(undone.LockedUntil))
+			assert.ErrorIs(t, deletedErr, mongo.ErrNoDocuments)
+			assert.Equal(t, 1, other.Failures)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryAuditRepository keeps audit entries in process memory.
+type memoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []domain.AuditEntry
+}
+
+// NewMemoryAuditRepository is the constructor for the in-memory backend.
+func NewMemoryAuditRepository() IAuditRepository {
+	return &memoryAuditRepository{}
+}
+
+// cloneAuditEntry copies an entry so the stored log can never be modified
+// through a slice handed to a caller.
+func cloneAuditEntry(entry domain.AuditEntry) domain.AuditEntry {
+	entry.Changes = append([]domain.FieldChange(nil), entry.Changes...)
+	return entry
+}
+
+func (r *memoryAuditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+	r.entries = append(r.entries, cloneAuditEntry(*entry))
+	id := entry.ID
+	recordUndo(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.entries = slices.DeleteFunc(r.entries, func(e domain.AuditEntry) bool { return e.ID == id })
+	})
+	return nil
+}
+
+func (r *memoryAuditRepository) List(ctx context.Context, q AuditQuery) ([]domain.AuditEntry, string, error) {
+	var afterCursor func(*domain.AuditEntry) bool
+	if q.Cursor != "" {
+		lastTime, lastID, err := decodeAuditCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		afterCursor = func(e *domain.AuditEntry) bool {
+			if c := e.Timestamp.Compare(lastTime); c != 0 {
+				return c < 0
+			}
+			return e.ID.Hex() < lastID.Hex()
+		}
+	}
+
+	r.mu.RLock()
+	var entries []domain.AuditEntry
+	for i := range r.entries {
+		entry := &r.entries[i]
+		if matchesAuditQuery(entry, q) && (afterCursor == nil || afterCursor(entry)) {
+			entries = append(entries, cloneAuditEntry(*entry))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if c := entries[i].Timestamp.Compare(entries[j].Timestamp); c != 0 {
+			return c > 0
+		}
+		return entries[i].ID.Hex() > entries[j].ID.Hex()
+	})
+	if q.Limit > 0 && len(entries) > q.Limit+1 {
+		entries = entries[:q.Limit+1]
+	}
+	return pageAuditEntries(entries, q)
+}
+
+// matchesAuditQuery applies the AuditQuery filters to a single entry.
+func matchesAuditQuery(entry *domain.AuditEntry, q AuditQuery) bool {
+	if q.EntityType != "" && entry.EntityType != q.EntityType {
+		return false
+	}
+	if !q.EntityID.IsZero() && entry.EntityID != q.EntityID {
+		return false
+	}
+	if !q.ActorID.IsZero() && entry.ActorID != q.ActorID {
+		return false
+	}
+	if q.Action != "" && entry.Action != q.Action {
+		return false
+	}
+	if !q.From.IsZero() && entry.Timestamp.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !entry.Timestamp.Before(q.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryCalendarFeedRepository keeps calendar feeds in process memory.
+type memoryCalendarFeedRepository struct {
+	mu    sync.RWMutex
+	feeds map[primitive.ObjectID]domain.CalendarFeed
+}
+
+// NewMemoryCalendarFeedRepository is the constructor for the in-memory backend.
+func NewMemoryCalendarFeedRepository() ICalendarFeedRepository {
+	return &memoryCalendarFeedRepository{feeds: make(map[primitive.ObjectID]domain.CalendarFeed)}
+}
+
+func (r *memoryCalendarFeedRepository) Create(ctx context.Context, feed *domain.CalendarFeed) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.feeds {
+		if existing.TokenHash == feed.TokenHash {
+			return errDuplicateKey("duplicate key: token_hash")
+		}
+	}
+	if feed.ID.IsZero() {
+		feed.ID = primitive.NewObjectID()
+	}
+	remember(ctx, &r.mu, r.feeds, feed.ID)
+	r.feeds[feed.ID] = *feed
+	return nil
+}
+
+func (r *memoryCalendarFeedRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.CalendarFeed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	feed, ok := r.feeds[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &feed, nil
+}
+
+func (r *memoryCalendarFeedRepository) FindByTokenHash(ctx context.Context, hash string) (*domain.CalendarFeed, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, feed := range r.feeds {
+		if feed.TokenHash == hash {
+			return &feed, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *memoryCalendarFeedRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.CalendarFeed, error) {
+	r.mu.RLock()
+	feeds := []domain.CalendarFeed{}
+	for _, feed := range r.feeds {
+		if feed.UserID == userID {
+			feeds = append(feeds, feed)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(feeds, func(i, j int) bool {
+		if !feeds[i].CreatedAt.Equal(feeds[j].CreatedAt) {
+			return feeds[i].CreatedAt.Before(feeds[j].CreatedAt)
+		}
+		return feeds[i].ID.Hex() < feeds[j].ID.Hex()
+	})
+	return feeds, nil
+}
+
+func (r *memoryCalendarFeedRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remember(ctx, &r.mu, r.feeds, id)
+	delete(r.feeds, id)
+	return nil
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryLoginAttemptRepository counts failed logins in process memory, so
+// the count is lost on restart and not shared between instances.
+type memoryLoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]domain.LoginAttempts
+}
+
+// NewMemoryLoginAttemptRepository is the constructor for the in-memory backend.
+func NewMemoryLoginAttemptRepository() ILoginAttemptRepository {
+	return &memoryLoginAttemptRepository{attempts: make(map[string]domain.LoginAttempts)}
+}
+
+func (r *memoryLoginAttemptRepository) Find(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &attempts, nil
+}
+
+func (r *memoryLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, attempts := range r.attempts {
+		if attempts.ExpiresAt.Before(at) {
+			remember(ctx, &r.mu, r.attempts, k)
+			delete(r.attempts, k)
+		}
+	}
+	attempts, ok := r.attempts[key]
+	if !ok || attempts.LastFailure.Before(at.Add(-window)) {
+		attempts.Failures = 0
+	}
+	attempts.Key = key
+	attempts.Failures++
+	attempts.LastFailure = at
+	attempts.ExpiresAt = at.Add(window)
+	if attempts.LockedUntil.After(attempts.ExpiresAt) {
+		attempts.ExpiresAt = attempts.LockedUntil
+	}
+	remember(ctx, &r.mu, r.attempts, key)
+	r.attempts[key] = attempts
+	return &attempts, nil
+}
+
+func (r *memoryLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok {
+		return nil
+	}
+	if until.After(attempts.LockedUntil) {
+		attempts.LockedUntil = until
+	}
+	if until.After(attempts.ExpiresAt) {
+		attempts.ExpiresAt = until
+	}
+	remember(ctx, &r.mu, r.attempts, key)
+	r.attempts[key] = attempts
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) UndoFailure(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts, ok := r.attempts[key]
+	if !ok || attempts.Failures == 0 {
+		return nil
+	}
+	attempts.Failures--
+	remember(ctx, &r.mu, r.attempts, key)
+	r.attempts[key] = attempts
+	return nil
+}
+
+func (r *memoryLoginAttemptRepository) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remember(ctx, &r.mu, r.attempts, key)
+	delete(r.attempts, key)
+	return nil
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryProjectRepository keeps projects in process memory.
+type memoryProjectRepository struct {
+	mu       sync.RWMutex
+	projects map[primitive.ObjectID]domain.Project
+}
+
+// NewMemoryProjectRepository is the constructor for the in-memory backend.
+func NewMemoryProjectRepository() IProjectRepository {
+	return &memoryProjectRepository{projects: make(map[primitive.ObjectID]domain.Project)}
+}
+
+// cloneProject copies a project so callers and the store never share members.
+func cloneProject(project domain.Project) domain.Project {
+	project.Members = append([]domain.ProjectMember(nil), project.Members...)
+	return project
+}
+
+func (r *memoryProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if project.ID.IsZero() {
+		project.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.projects[project.ID]; exists {
+		return errDuplicateKey("duplicate key: _id " + project.ID.Hex())
+	}
+	remember(ctx, &r.mu, r.projects, project.ID)
+	r.projects[project.ID] = cloneProject(*project)
+	return nil
+}
+
+func (r *memoryProjectRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Project, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	project = cloneProject(project)
+	return &project, nil
+}
+
+func (r *memoryProjectRepository) ListByMember(ctx context.Context, userID primitive.ObjectID, includeArchived bool) ([]domain.Project, error) {
+	r.mu.RLock()
+	projects := []domain.Project{}
+	for _, project := range r.projects {
+		if !includeArchived && !project.ArchivedAt.IsZero() {
+			continue
+		}
+		for _, m := range project.Members {
+			if m.UserID == userID {
+				projects = append(projects, cloneProject(project))
+				break
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].ID.Hex() < projects[j].ID.Hex()
+	})
+	return projects, nil
+}
+
+func (r *memoryProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.projects[project.ID]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	updated := cloneProject(*project)
+	stored.Name, stored.Description = updated.Name, updated.Description
+	stored.Members, stored.ArchivedAt = updated.Members, updated.ArchivedAt
+	remember(ctx, &r.mu, r.projects, project.ID)
+	r.projects[project.ID] = stored
+	return nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reminderKey is what makes a reminder unique.
+type reminderKey struct {
+	taskID, userID primitive.ObjectID
+	dueDate        time.Time
+	offset         time.Duration
+}
+
+// memoryReminderRepository keeps sent reminders in process memory, so they
+// are forgotten on restart.
+type memoryReminderRepository struct {
+	mu        sync.Mutex
+	reminders map[reminderKey]primitive.ObjectID
+}
+
+// NewMemoryReminderRepository is the constructor for the in-memory backend.
+func NewMemoryReminderRepository() IReminderRepository {
+	return &memoryReminderRepository{reminders: make(map[reminderKey]primitive.ObjectID)}
+}
+
+func (r *memoryReminderRepository) Create(ctx context.Context, reminder *domain.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := reminderKey{reminder.TaskID, reminder.UserID, reminder.DueDate.UTC(), reminder.Offset}
+	if _, exists := r.reminders[key]; exists {
+		return errDuplicateKey("duplicate key: reminder for task " + reminder.TaskID.Hex())
+	}
+	if reminder.ID.IsZero() {
+		reminder.ID = primitive.NewObjectID()
+	}
+	remember(ctx, &r.mu, r.reminders, key)
+	r.reminders[key] = reminder.ID
+	return nil
+}
+
+func (r *memoryReminderRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, existing := range r.reminders {
+		if existing == id {
+			remember(ctx, &r.mu, r.reminders, key)
+			delete(r.reminders, key)
+		}
+	}
+	return nil
+}
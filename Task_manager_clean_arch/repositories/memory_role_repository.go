@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryRoleRepository keeps roles in process memory.
+type memoryRoleRepository struct {
+	mu    sync.RWMutex
+	roles map[primitive.ObjectID]domain.Role
+}
+
+// NewMemoryRoleRepository is the constructor for the in-memory backend.
+func NewMemoryRoleRepository() IRoleRepository {
+	return &memoryRoleRepository{roles: make(map[primitive.ObjectID]domain.Role)}
+}
+
+// copyRole copies a role so callers and the store never share its
+// permission list.
+func copyRole(role domain.Role) domain.Role {
+	role.Permissions = slices.Clone(role.Permissions)
+	return role
+}
+
+func (r *memoryRoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if role.ID.IsZero() {
+		role.ID = primitive.NewObjectID()
+	}
+	for id, existing := range r.roles {
+		if id == role.ID || existing.Name == role.Name {
+			return errDuplicateKey("duplicate key: role " + role.Name)
+		}
+	}
+	remember(ctx, &r.mu, r.roles, role.ID)
+	r.roles[role.ID] = copyRole(*role)
+	return nil
+}
+
+func (r *memoryRoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, role := range r.roles {
+		if role.Name == name {
+			found := copyRole(role)
+			return &found, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *memoryRoleRepository) List(ctx context.Context) ([]domain.Role, error) {
+	r.mu.RLock()
+	roles := make([]domain.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, copyRole(role))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+func (r *memoryRoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.roles[role.ID]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	stored.Description = role.Description
+	stored.Permissions = slices.Clone(role.Permissions)
+	stored.UpdatedAt = role.UpdatedAt
+	remember(ctx, &r.mu, r.roles, role.ID)
+	r.roles[role.ID] = stored
+	return nil
+}
+
+func (r *memoryRoleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remember(ctx, &r.mu, r.roles, id)
+	delete(r.roles, id)
+	return nil
+}
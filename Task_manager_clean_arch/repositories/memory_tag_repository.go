@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryTagRepository keeps tags in process memory.
+type memoryTagRepository struct {
+	mu   sync.RWMutex
+	tags map[primitive.ObjectID]domain.Tag
+}
+
+// NewMemoryTagRepository is the constructor for the in-memory backend.
+func NewMemoryTagRepository() ITagRepository {
+	return &memoryTagRepository{tags: make(map[primitive.ObjectID]domain.Tag)}
+}
+
+// checkUniqueName fails like a unique index if the user has another tag
+// with the same name. The caller holds the lock.
+func (r *memoryTagRepository) checkUniqueName(tag *domain.Tag) error {
+	for _, existing := range r.tags {
+		if existing.ID != tag.ID && existing.UserID == tag.UserID && existing.Name == tag.Name {
+			return errDuplicateKey("duplicate key: tag " + tag.Name)
+		}
+	}
+	return nil
+}
+
+func (r *memoryTagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if tag.ID.IsZero() {
+		tag.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.tags[tag.ID]; exists {
+		return errDuplicateKey("duplicate key: _id " + tag.ID.Hex())
+	}
+	if err := r.checkUniqueName(tag); err != nil {
+		return err
+	}
+	remember(ctx, &r.mu, r.tags, tag.ID)
+	r.tags[tag.ID] = *tag
+	return nil
+}
+
+func (r *memoryTagRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tag, ok := r.tags[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &tag, nil
+}
+
+func (r *memoryTagRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Tag, error) {
+	r.mu.RLock()
+	tags := []domain.Tag{}
+	for _, tag := range r.tags {
+		if tag.UserID == userID {
+			tags = append(tags, tag)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (r *memoryTagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tags[tag.ID]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	if err := r.checkUniqueName(&domain.Tag{ID: tag.ID, UserID: stored.UserID, Name: tag.Name}); err != nil {
+		return err
+	}
+	stored.Name, stored.Color = tag.Name, tag.Color
+	remember(ctx, &r.mu, r.tags, tag.ID)
+	r.tags[tag.ID] = stored
+	return nil
+}
+
+func (r *memoryTagRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remember(ctx, &r.mu, r.tags, id)
+	delete(r.tags, id)
+	return nil
+}
@@ -0,0 +1,544 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryTaskRepository keeps tasks in process memory.
+type memoryTaskRepository struct {
+	mu    sync.RWMutex
+	tasks map[primitive.ObjectID]domain.Task
+}
+
+// NewMemoryTaskRepository is the constructor for the in-memory backend.
+func NewMemoryTaskRepository() ITaskRepository {
+	return &memoryTaskRepository{tasks: make(map[primitive.ObjectID]domain.Task)}
+}
+
+// cloneTask copies a task deeply enough that callers and the store never
+// share slices.
+func cloneTask(task domain.Task) domain.Task {
+	task.StatusHistory = append([]domain.StatusChange(nil), task.StatusHistory...)
+	task.BlockedBy = append([]primitive.ObjectID(nil), task.BlockedBy...)
+	task.Collaborators = append([]domain.Collaborator(nil), task.Collaborators...)
+	task.Tags = append([]string(nil), task.Tags...)
+	if task.Recurrence != nil {
+		recurrence := *task.Recurrence
+		task.Recurrence = &recurrence
+	}
+	return task
+}
+
+func (r *memoryTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if task.ID.IsZero() {
+		task.ID = primitive.NewObjectID()
+	}
+	if _, exists := r.tasks[task.ID]; exists {
+		return errDuplicateKey("duplicate key: _id " + task.ID.Hex())
+	}
+	if task.ExternalID != "" && r.findByExternalID(task.UserID, task.ExternalID) != nil {
+		return errDuplicateKey("duplicate key: external_id " + task.ExternalID)
+	}
+	if task.Version == 0 {
+		task.Version = 1
+	}
+	remember(ctx, &r.mu, r.tasks, task.ID)
+	r.tasks[task.ID] = cloneTask(*task)
+	return nil
+}
+
+func (r *memoryTaskRepository) GetAllByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []domain.Task
+	for _, task := range r.tasks {
+		if task.UserID == userID && task.DeletedAt.IsZero() {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) ListTasks(ctx context.Context, q TaskQuery) ([]domain.Task, string, error) {
+	var last *domain.Task
+	if q.Cursor != "" {
+		cur, lastID, err := decodeCursor(q)
+		if err != nil {
+			return nil, "", err
+		}
+		last = &domain.Task{ID: lastID}
+		switch q.SortBy {
+		case SortByTitle:
+			last.Title = cur.Value
+		case SortByStatus:
+			last.Status = cur.Value
+		default:
+			last.Duedate, _ = time.Parse(time.RFC3339Nano, cur.Value)
+		}
+	}
+
+	r.mu.RLock()
+	var tasks []domain.Task
+	for _, task := range r.tasks {
+		if task.DeletedAt.IsZero() && matchesTaskQuery(&task, q) {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	r.mu.RUnlock()
+
+	// less orders two tasks by the sort key, then by ID, honoring direction.
+	less := func(a, b *domain.Task) bool {
+		c := compareTasks(a, b, q.SortBy)
+		if c == 0 {
+			c = strings.Compare(a.ID.Hex(), b.ID.Hex())
+		}
+		if q.Descending {
+			return c > 0
+		}
+		return c < 0
+	}
+	sort.Slice(tasks, func(i, j int) bool { return less(&tasks[i], &tasks[j]) })
+
+	if last != nil {
+		start := sort.Search(len(tasks), func(i int) bool { return less(last, &tasks[i]) })
+		tasks = tasks[start:]
+	}
+	if q.Limit > 0 && len(tasks) > q.Limit+1 {
+		tasks = tasks[:q.Limit+1]
+	}
+	return pageTasks(tasks, q)
+}
+
+// matchesTaskQuery applies the TaskQuery filters to a single task.
+func matchesTaskQuery(task *domain.Task, q TaskQuery) bool {
+	owned := task.UserID == q.UserID
+	assigned := task.AssigneeID == q.UserID
+	shared := false
+	for _, c := range task.Collaborators {
+		if c.UserID == q.UserID {
+			shared = true
+			break
+		}
+	}
+	switch q.Scope {
+	case ScopeOwned:
+		if !owned {
+			return false
+		}
+	case ScopeAssigned:
+		if !assigned {
+			return false
+		}
+	case ScopeShared:
+		if !shared {
+			return false
+		}
+	default:
+		if q.ProjectID.IsZero() && !owned && !assigned && !shared {
+			return false
+		}
+	}
+	if !q.ProjectID.IsZero() && task.ProjectID != q.ProjectID {
+		return false
+	}
+	if !q.IncludeArchived && !task.ArchivedAt.IsZero() {
+		return false
+	}
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, task.Status) {
+		return false
+	}
+	if slices.Contains(q.ExcludeStatuses, task.Status) {
+		return false
+	}
+	if !q.DueFrom.IsZero() && task.Duedate.Before(q.DueFrom) {
+		return false
+	}
+	if !q.DueTo.IsZero() && task.Duedate.After(q.DueTo) {
+		return false
+	}
+	if !q.DueBefore.IsZero() && !task.Duedate.Before(q.DueBefore) {
+		return false
+	}
+	if !q.CreatedAfter.IsZero() && !task.CreatedAt.After(q.CreatedAfter) {
+		return false
+	}
+	if len(q.AnyTags) > 0 {
+		found := false
+		for _, tag := range q.AnyTags {
+			if hasTag(task, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, tag := range q.AllTags {
+		if !hasTag(task, tag) {
+			return false
+		}
+	}
+	for _, tag := range q.ExcludeTags {
+		if hasTag(task, tag) {
+			return false
+		}
+	}
+	title, description := strings.ToLower(task.Title), strings.ToLower(task.Description)
+	for _, term := range q.Text {
+		term = strings.ToLower(term)
+		if !strings.Contains(title, term) && !strings.Contains(description, term) {
+			return false
+		}
+	}
+	for _, term := range q.ExcludeText {
+		term = strings.ToLower(term)
+		if strings.Contains(title, term) || strings.Contains(description, term) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(task *domain.Task, name string) bool {
+	for _, tag := range task.Tags {
+		if tag == name {
+			return true
+		}
+	}
+	return false
+}
+
+// compareTasks compares two tasks on a single sort field.
+func compareTasks(a, b *domain.Task, field TaskSortField) int {
+	switch field {
+	case SortByTitle:
+		return strings.Compare(a.Title, b.Title)
+	case SortByStatus:
+		return strings.Compare(a.Status, b.Status)
+	default:
+		return a.Duedate.Compare(b.Duedate)
+	}
+}
+
+func (r *memoryTaskRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok || !task.DeletedAt.IsZero() {
+		return nil, mongo.ErrNoDocuments
+	}
+	task = cloneTask(task)
+	return &task, nil
+}
+
+func (r *memoryTaskRepository) GetTrashedByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.tasks[id]
+	if !ok || task.DeletedAt.IsZero() {
+		return nil, mongo.ErrNoDocuments
+	}
+	task = cloneTask(task)
+	return &task, nil
+}
+
+func (r *memoryTaskRepository) GetByExternalID(ctx context.Context, userID primitive.ObjectID, externalID string) (*domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task := r.findByExternalID(userID, externalID)
+	if task == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	found := cloneTask(*task)
+	return &found, nil
+}
+
+// findByExternalID looks a task up by owner and external ID. The caller
+// holds r.mu.
+func (r *memoryTaskRepository) findByExternalID(userID primitive.ObjectID, externalID string) *domain.Task {
+	for _, task := range r.tasks {
+		if task.UserID == userID && task.ExternalID == externalID {
+			return &task
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) ListTrash(ctx context.Context, q TrashQuery) ([]domain.Task, error) {
+	r.mu.RLock()
+	var tasks []domain.Task
+	for _, task := range r.tasks {
+		if task.DeletedAt.IsZero() || (!q.UserID.IsZero() && task.UserID != q.UserID) ||
+			(!q.DeletedBefore.IsZero() && !task.DeletedAt.Before(q.DeletedBefore)) {
+			continue
+		}
+		tasks = append(tasks, cloneTask(task))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if c := tasks[i].DeletedAt.Compare(tasks[j].DeletedAt); c != 0 {
+			return c > 0
+		}
+		return tasks[i].ID.Hex() > tasks[j].ID.Hex()
+	})
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []domain.Task
+	for _, id := range ids {
+		if task, ok := r.tasks[id]; ok && task.DeletedAt.IsZero() {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	return tasks, nil
+}
+
+func (r *memoryTaskRepository) ListSubtasks(ctx context.Context, parentID primitive.ObjectID) ([]domain.Task, error) {
+	return r.findOldestFirst(func(task *domain.Task) bool { return task.ParentID == parentID }), nil
+}
+
+func (r *memoryTaskRepository) ListBlockedTasks(ctx context.Context, blockerID primitive.ObjectID) ([]domain.Task, error) {
+	return r.findOldestFirst(func(task *domain.Task) bool {
+		for _, id := range task.BlockedBy {
+			if id == blockerID {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r *memoryTaskRepository) ListDueBetween(ctx context.Context, from, to time.Time) ([]domain.Task, error) {
+	tasks := r.findOldestFirst(func(task *domain.Task) bool {
+		return !task.Duedate.Before(from) && !task.Duedate.After(to) && task.ArchivedAt.IsZero()
+	})
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if c := tasks[i].Duedate.Compare(tasks[j].Duedate); c != 0 {
+			return c < 0
+		}
+		return tasks[i].ID.Hex() < tasks[j].ID.Hex()
+	})
+	return tasks, nil
+}
+
+// findOldestFirst returns the tasks matching keep, ordered by creation time
+// and then ID like the other backends.
+func (r *memoryTaskRepository) findOldestFirst(keep func(*domain.Task) bool) []domain.Task {
+	r.mu.RLock()
+	var tasks []domain.Task
+	for _, task := range r.tasks {
+		if task.DeletedAt.IsZero() && keep(&task) {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if c := tasks[i].CreatedAt.Compare(tasks[j].CreatedAt); c != 0 {
+			return c < 0
+		}
+		return tasks[i].ID.Hex() < tasks[j].ID.Hex()
+	})
+	return tasks
+}
+
+func (r *memoryTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tasks[task.ID]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	if stored.Version != task.Version {
+		return ErrVersionConflict
+	}
+	task.Version++
+	remember(ctx, &r.mu, r.tasks, task.ID)
+	r.tasks[task.ID] = cloneTask(*task)
+	return nil
+}
+
+func (r *memoryTaskRepository) UpdateFields(ctx context.Context, task *domain.Task, fields []TaskField) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tasks[task.ID]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	if stored.Version != task.Version {
+		return ErrVersionConflict
+	}
+	updated := cloneTask(*task)
+	for _, field := range fields {
+		switch field {
+		case TaskFieldTitle:
+			stored.Title = updated.Title
+		case TaskFieldDescription:
+			stored.Description = updated.Description
+		case TaskFieldDueDate:
+			stored.Duedate = updated.Duedate
+		case TaskFieldStatus:
+			stored.Status = updated.Status
+			stored.StatusHistory = updated.StatusHistory
+		case TaskFieldParent:
+			stored.ParentID = updated.ParentID
+		case TaskFieldBlockedBy:
+			stored.BlockedBy = updated.BlockedBy
+		case TaskFieldAssignee:
+			stored.AssigneeID = updated.AssigneeID
+		case TaskFieldCollaborators:
+			stored.Collaborators = updated.Collaborators
+		case TaskFieldRecurrence:
+			stored.Recurrence = updated.Recurrence
+		case TaskFieldTags:
+			stored.Tags = updated.Tags
+		case TaskFieldDeletion:
+			stored.DeletedAt = updated.DeletedAt
+			stored.DeletedBy = updated.DeletedBy
+		}
+	}
+	task.Version++
+	stored.Version = task.Version
+	remember(ctx, &r.mu, r.tasks, task.ID)
+	r.tasks[task.ID] = stored
+	return nil
+}
+
+func (r *memoryTaskRepository) RenameTag(ctx context.Context, userID primitive.ObjectID, from, to string) error {
+	r.editTags(ctx, userID, from, func(tags []string) []string {
+		tags = slices.DeleteFunc(tags, func(tag string) bool { return tag == from })
+		if !slices.Contains(tags, to) {
+			tags = append(tags, to)
+		}
+		return tags
+	})
+	return nil
+}
+
+func (r *memoryTaskRepository) RemoveTag(ctx context.Context, userID primitive.ObjectID, name string) error {
+	r.editTags(ctx, userID, name, func(tags []string) []string {
+		kept := tags[:0]
+		for _, tag := range tags {
+			if tag != name {
+				kept = append(kept, tag)
+			}
+		}
+		if len(kept) == 0 {
+			return nil
+		}
+		return kept
+	})
+	return nil
+}
+
+// editTags rewrites the tags of every task the user owns that carries name,
+// and bumps its version.
+func (r *memoryTaskRepository) editTags(ctx context.Context, userID primitive.ObjectID, name string, edit func([]string) []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, task := range r.tasks {
+		if task.UserID != userID || !hasTag(&task, name) {
+			continue
+		}
+		task = cloneTask(task)
+		task.Tags = edit(task.Tags)
+		task.Version++
+		remember(ctx, &r.mu, r.tasks, id)
+		r.tasks[id] = task
+	}
+}
+
+func (r *memoryTaskRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, task := range r.tasks {
+		isCollaborator := func(c domain.Collaborator) bool { return c.UserID == userID }
+		if task.AssigneeID != userID && !slices.ContainsFunc(task.Collaborators, isCollaborator) {
+			continue
+		}
+		task = cloneTask(task)
+		if task.AssigneeID == userID {
+			task.AssigneeID = primitive.NilObjectID
+		}
+		if task.Collaborators = slices.DeleteFunc(task.Collaborators, isCollaborator); len(task.Collaborators) == 0 {
+			task.Collaborators = nil
+		}
+		task.Version++
+		remember(ctx, &r.mu, r.tasks, id)
+		r.tasks[id] = task
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) RemoveTaskLinks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	removed := func(id primitive.ObjectID) bool { return slices.Contains(taskIDs, id) }
+	for id, task := range r.tasks {
+		if !removed(task.ParentID) && !slices.ContainsFunc(task.BlockedBy, removed) {
+			continue
+		}
+		task = cloneTask(task)
+		if removed(task.ParentID) {
+			task.ParentID = primitive.NilObjectID
+		}
+		if task.BlockedBy = slices.DeleteFunc(task.BlockedBy, removed); len(task.BlockedBy) == 0 {
+			task.BlockedBy = nil
+		}
+		task.Version++
+		remember(ctx, &r.mu, r.tasks, id)
+		r.tasks[id] = task
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) ArchiveProject(ctx context.Context, projectID primitive.ObjectID, archivedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, task := range r.tasks {
+		if task.ProjectID == projectID {
+			task.ArchivedAt = archivedAt
+			task.Version++
+			remember(ctx, &r.mu, r.tasks, id)
+			r.tasks[id] = task
+		}
+	}
+	return nil
+}
+
+func (r *memoryTaskRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remember(ctx, &r.mu, r.tasks, id)
+	delete(r.tasks, id)
+	return nil
+}
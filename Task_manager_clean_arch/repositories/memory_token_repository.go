@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryTokenRepository keeps refresh tokens, revoked access token IDs and
+// password reset tokens in process memory.
+type memoryTokenRepository struct {
+	mu            sync.Mutex
+	refreshTokens map[primitive.ObjectID]domain.RefreshToken
+	revokedTokens map[string]time.Time
+	resetTokens   map[primitive.ObjectID]domain.PasswordResetToken
+}
+
+// NewMemoryTokenRepository is the constructor for the in-memory backend.
+func NewMemoryTokenRepository() ITokenRepository {
+	return &memoryTokenRepository{
+		refreshTokens: make(map[primitive.ObjectID]domain.RefreshToken),
+		revokedTokens: make(map[string]time.Time),
+		resetTokens:   make(map[primitive.ObjectID]domain.PasswordResetToken),
+	}
+}
+
+func (r *memoryTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.refreshTokens {
+		if existing.TokenHash == token.TokenHash {
+			return errDuplicateKey("duplicate key: token_hash")
+		}
+	}
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	remember(ctx, &r.mu, r.refreshTokens, token.ID)
+	r.refreshTokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryTokenRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.refreshTokens {
+		if token.TokenHash == hash {
+			found := token
+			return &found, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *memoryTokenRepository) MarkRefreshTokenUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.refreshTokens[id]
+	if !ok || !token.UsedAt.IsZero() {
+		return false, nil
+	}
+	token.UsedAt = usedAt
+	remember(ctx, &r.mu, r.refreshTokens, id)
+	r.refreshTokens[id] = token
+	return true, nil
+}
+
+func (r *memoryTokenRepository) FindRefreshTokenFamily(ctx context.Context, familyID primitive.ObjectID) ([]domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tokens []domain.RefreshToken
+	for _, token := range r.refreshTokens {
+		if token.FamilyID == familyID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memoryTokenRepository) FindRefreshTokensByUser(ctx context.Context, userID primitive.ObjectID) ([]domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tokens []domain.RefreshToken
+	for _, token := range r.refreshTokens {
+		if token.UserID == userID {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *memoryTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.refreshTokens {
+		if token.FamilyID == familyID {
+			token.Revoked = true
+			remember(ctx, &r.mu, r.refreshTokens, id)
+			r.refreshTokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *memoryTokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, expiry := range r.revokedTokens {
+		if expiry.Before(now) {
+			remember(ctx, &r.mu, r.revokedTokens, id)
+			delete(r.revokedTokens, id)
+		}
+	}
+	remember(ctx, &r.mu, r.revokedTokens, jti)
+	r.revokedTokens[jti] = expiresAt
+	return nil
+}
+
+func (r *memoryTokenRepository) ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if expiry, used := r.revokedTokens[jti]; used && !expiry.Before(time.Now()) {
+		return false, nil
+	}
+	remember(ctx, &r.mu, r.revokedTokens, jti)
+	r.revokedTokens[jti] = expiresAt
+	return true, nil
+}
+
+func (r *memoryTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, revoked := r.revokedTokens[jti]
+	return revoked, nil
+}
+
+func (r *memoryTokenRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.resetTokens {
+		if existing.TokenHash == token.TokenHash {
+			return errDuplicateKey("duplicate key: token_hash")
+		}
+	}
+	if token.ID.IsZero() {
+		token.ID = primitive.NewObjectID()
+	}
+	remember(ctx, &r.mu, r.resetTokens, token.ID)
+	r.resetTokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryTokenRepository) FindPasswordResetTokenByHash(ctx context.Context, hash string) (*domain.PasswordResetToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.resetTokens {
+		if token.TokenHash == hash {
+			found := token
+			return &found, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *memoryTokenRepository) MarkPasswordResetTokenUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.resetTokens[id]
+	if !ok || !token.UsedAt.IsZero() {
+		return false, nil
+	}
+	token.UsedAt = usedAt
+	remember(ctx, &r.mu, r.resetTokens, id)
+	r.resetTokens[id] = token
+	return true, nil
+}
+
+func (r *memoryTokenRepository) InvalidatePasswordResetTokens(ctx context.Context, userID primitive.ObjectID, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.resetTokens {
+		if token.UserID == userID && token.UsedAt.IsZero() {
+			token.UsedAt = usedAt
+			remember(ctx, &r.mu, r.resetTokens, id)
+			r.resetTokens[id] = token
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type memoryUnitKey struct{}
+
+// memoryJournal records how to undo each write a unit of work makes.
+type memoryJournal struct {
+	mu   sync.Mutex
+	undo []func()
+}
+
+// memoryUnitOfWork runs one unit of work at a time and, when one fails,
+// undoes the unit's own writes, newest first. Writes other requests make
+// while the unit runs are kept. There is no isolation: other requests see
+// the unit's writes before it finishes, and a key both wrote is put back to
+// what it held before the unit wrote it. That is acceptable for a backend
+// meant for demos and development.
+type memoryUnitOfWork struct {
+	mu sync.Mutex
+}
+
+// NewMemoryUnitOfWork is the constructor. It covers the in-memory
+// repositories, which record their writes in the unit through the context;
+// writes to any other repositories are not undone.
+func NewMemoryUnitOfWork() IUnitOfWork {
+	return &memoryUnitOfWork{}
+}
+
+func (u *memoryUnitOfWork) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	// A unit of work inside another is part of it.
+	if ctx.Value(memoryUnitKey{}) != nil {
+		return fn(ctx)
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	journal := &memoryJournal{}
+	if err := fn(context.WithValue(ctx, memoryUnitKey{}, journal)); err != nil {
+		journal.mu.Lock()
+		undo := journal.undo
+		journal.mu.Unlock()
+		for _, fn := range slices.Backward(undo) {
+			fn()
+		}
+		return err
+	}
+	return nil
+}
+
+// recordUndo adds fn to the journal of the unit of work ctx belongs to, if
+// any.
+func recordUndo(ctx context.Context, fn func()) {
+	journal, ok := ctx.Value(memoryUnitKey{}).(*memoryJournal)
+	if !ok {
+		return
+	}
+	journal.mu.Lock()
+	journal.undo = append(journal.undo, fn)
+	journal.mu.Unlock()
+}
+
+// remember is called under mu before a write to m[key]. Within a unit of
+// work it records the value key holds, or its absence, so the write can be
+// undone. Stored values are never modified in place, so keeping the value
+// itself is enough.
+func remember[K comparable, V any](ctx context.Context, mu sync.Locker, m map[K]V, key K) {
+	old, existed := m[key]
+	recordUndo(ctx, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if existed {
+			m[key] = old
+		} else {
+			delete(m, key)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryUserRepository keeps users in process memory. It mirrors the Mongo
+// implementation's behaviour, including its errors, so callers can't tell
+// the two apart.
+type memoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[primitive.ObjectID]domain.User
+}
+
+// NewMemoryUserRepository is the constructor for the in-memory backend.
+func NewMemoryUserRepository() IUserRepository {
+	return &memoryUserRepository{users: make(map[primitive.ObjectID]domain.User)}
+}
+
+// errDuplicateKey builds the same error Mongo returns for a unique index
+// violation, so mongo.IsDuplicateKeyError works for every backend.
+func errDuplicateKey(message string) error {
+	return mongo.WriteException{
+		WriteErrors: []mongo.WriteError{{Code: 11000, Message: message}},
+	}
+}
+
+// cloneUser copies a user so callers and the store never share its role or
+// offset lists. nil and empty offset lists mean different things and stay
+// distinct.
+func cloneUser(user domain.User) domain.User {
+	user.Roles = slices.Clone(user.Roles)
+	if user.ReminderOffsets != nil {
+		user.ReminderOffsets = append([]time.Duration{}, user.ReminderOffsets...)
+	}
+	return user
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Username == user.Username {
+			return errDuplicateKey("duplicate key: username " + user.Username)
+		}
+	}
+
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	remember(ctx, &r.mu, r.users, user.ID)
+	r.users[user.ID] = cloneUser(*user)
+	return nil
+}
+
+func (r *memoryUserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			found := cloneUser(user)
+			return &found, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	found := cloneUser(user)
+	return &found, nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Like an UpdateOne that matches nothing, updating a missing user is a no-op.
+	if _, ok := r.users[user.ID]; !ok {
+		return nil
+	}
+	for id, existing := range r.users {
+		if id != user.ID && existing.Username == user.Username {
+			return errDuplicateKey("duplicate key: username " + user.Username)
+		}
+	}
+	remember(ctx, &r.mu, r.users, user.ID)
+	r.users[user.ID] = cloneUser(*user)
+	return nil
+}
+
+func (r *memoryUserRepository) UpdateFields(ctx context.Context, user *domain.User, fields []UserField) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.users[user.ID]
+	if !ok {
+		return nil
+	}
+	updated := cloneUser(*user)
+	for _, field := range fields {
+		switch field {
+		case UserFieldPassword:
+			stored.Password = updated.Password
+		case UserFieldRoles:
+			stored.Roles = updated.Roles
+		case UserFieldReminderOffsets:
+			stored.ReminderOffsets = updated.ReminderOffsets
+		case UserFieldDisabled:
+			stored.DisabledAt = updated.DisabledAt
+		}
+	}
+	remember(ctx, &r.mu, r.users, user.ID)
+	r.users[user.ID] = stored
+	return nil
+}
+
+func (r *memoryUserRepository) Count(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return int64(len(r.users)), nil
+}
+
+func (r *memoryUserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, user := range r.users {
+		if slices.Contains(user.Roles, role) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, q UserQuery) ([]domain.User, string, error) {
+	var last string
+	if q.Cursor != "" {
+		var err error
+		if last, err = decodeUserCursor(q.Cursor); err != nil {
+			return nil, "", err
+		}
+	}
+	search := strings.ToLower(q.Search)
+
+	r.mu.RLock()
+	var users []domain.User
+	for _, user := range r.users {
+		if search != "" && !strings.Contains(strings.ToLower(user.Username), search) {
+			continue
+		}
+		if q.Role != "" && !slices.Contains(user.Roles, q.Role) {
+			continue
+		}
+		if (q.Status == UserStatusActive && !user.DisabledAt.IsZero()) || (q.Status == UserStatusDisabled && user.DisabledAt.IsZero()) {
+			continue
+		}
+		if last != "" && user.Username <= last {
+			continue
+		}
+		users = append(users, cloneUser(user))
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(users, func(a, b domain.User) int { return strings.Compare(a.Username, b.Username) })
+	if q.Limit > 0 && len(users) > q.Limit+1 {
+		users = users[:q.Limit+1]
+	}
+	return pageUsers(users, q)
+}
+
+func (r *memoryUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remember(ctx, &r.mu, r.users, id)
+	delete(r.users, id)
+	return nil
+}
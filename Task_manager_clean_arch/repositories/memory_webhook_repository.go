@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryWebhookRepository keeps webhooks and their deliveries in process memory.
+type memoryWebhookRepository struct {
+	mu         sync.RWMutex
+	webhooks   map[primitive.ObjectID]domain.Webhook
+	deliveries map[primitive.ObjectID]domain.WebhookDelivery
+}
+
+// NewMemoryWebhookRepository is the constructor for the in-memory backend.
+func NewMemoryWebhookRepository() IWebhookRepository {
+	return &memoryWebhookRepository{
+		webhooks:   make(map[primitive.ObjectID]domain.Webhook),
+		deliveries: make(map[primitive.ObjectID]domain.WebhookDelivery),
+	}
+}
+
+// copyWebhook keeps stored webhooks from sharing their event list with callers.
+func copyWebhook(webhook domain.Webhook) domain.Webhook {
+	webhook.Events = slices.Clone(webhook.Events)
+	return webhook
+}
+
+func (r *memoryWebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if webhook.ID.IsZero() {
+		webhook.ID = primitive.NewObjectID()
+	}
+	remember(ctx, &r.mu, r.webhooks, webhook.ID)
+	r.webhooks[webhook.ID] = copyWebhook(*webhook)
+	return nil
+}
+
+func (r *memoryWebhookRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	webhook = copyWebhook(webhook)
+	return &webhook, nil
+}
+
+// listWebhooks returns the webhooks that match, oldest first.
+func (r *memoryWebhookRepository) listWebhooks(match func(domain.Webhook) bool) []domain.Webhook {
+	r.mu.RLock()
+	webhooks := []domain.Webhook{}
+	for _, webhook := range r.webhooks {
+		if match(webhook) {
+			webhooks = append(webhooks, copyWebhook(webhook))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		if !webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+		}
+		return webhooks[i].ID.Hex() < webhooks[j].ID.Hex()
+	})
+	return webhooks
+}
+
+func (r *memoryWebhookRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Webhook, error) {
+	return r.listWebhooks(func(webhook domain.Webhook) bool { return webhook.UserID == userID }), nil
+}
+
+func (r *memoryWebhookRepository) ListEnabledFor(ctx context.Context, userIDs []primitive.ObjectID) ([]domain.Webhook, error) {
+	return r.listWebhooks(func(webhook domain.Webhook) bool {
+		return webhook.DisabledAt.IsZero() && (webhook.AllTasks || slices.Contains(userIDs, webhook.UserID))
+	}), nil
+}
+
+func (r *memoryWebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[webhook.ID]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	remember(ctx, &r.mu, r.webhooks, webhook.ID)
+	r.webhooks[webhook.ID] = copyWebhook(*webhook)
+	return nil
+}
+
+func (r *memoryWebhookRepository) RecordDeliveryFailure(ctx context.Context, id primitive.ObjectID, disableAfter int, at time.Time) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	webhook.FailedDeliveries++
+	if disableAfter > 0 && webhook.FailedDeliveries >= disableAfter && webhook.DisabledAt.IsZero() {
+		webhook.DisabledAt = at
+	}
+	remember(ctx, &r.mu, r.webhooks, id)
+	r.webhooks[id] = webhook
+	saved := copyWebhook(webhook)
+	return &saved, nil
+}
+
+func (r *memoryWebhookRepository) ResetDeliveryFailures(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return mongo.ErrNoDocuments
+	}
+	remember(ctx, &r.mu, r.webhooks, id)
+	webhook.FailedDeliveries = 0
+	r.webhooks[id] = webhook
+	return nil
+}
+
+func (r *memoryWebhookRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remember(ctx, &r.mu, r.webhooks, id)
+	delete(r.webhooks, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.WebhookID == id {
+			remember(ctx, &r.mu, r.deliveries, deliveryID)
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (r *memoryWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+	remember(ctx, &r.mu, r.deliveries, delivery.ID)
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *memoryWebhookRepository) GetDelivery(ctx context.Context, id primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	delivery, ok := r.deliveries[id]
+	if !ok {
+		return nil, mongo.ErrNoDocuments
+	}
+	return &delivery, nil
+}
+
+func (r *memoryWebhookRepository) ListDeliveries(ctx context.Context, webhookID primitive.ObjectID, limit int) ([]domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	deliveries := []domain.WebhookDelivery{}
+	for _, delivery := range r.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+		}
+		return deliveries[i].ID.Hex() > deliveries[j].ID.Hex()
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (r *memoryWebhookRepository) ClaimDelivery(ctx context.Context, now, until time.Time, skip []primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed *domain.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status != domain.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) || slices.Contains(skip, delivery.WebhookID) {
+			continue
+		}
+		if claimed == nil || delivery.NextAttemptAt.Before(claimed.NextAttemptAt) ||
+			delivery.NextAttemptAt.Equal(claimed.NextAttemptAt) && delivery.ID.Hex() < claimed.ID.Hex() {
+			delivery := delivery
+			claimed = &delivery
+		}
+	}
+	if claimed == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	claimed.NextAttemptAt = until
+	remember(ctx, &r.mu, r.deliveries, claimed.ID)
+	r.deliveries[claimed.ID] = *claimed
+	return claimed, nil
+}
+
+func (r *memoryWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery, claimedUntil time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.deliveries[delivery.ID]; !ok || !stored.NextAttemptAt.Equal(claimedUntil) {
+		return mongo.ErrNoDocuments
+	}
+	remember(ctx, &r.mu, r.deliveries, delivery.ID)
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *memoryWebhookRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, delivery := range r.deliveries {
+		if delivery.Status != domain.WebhookDeliveryPending && delivery.CreatedAt.Before(before) {
+			remember(ctx, &r.mu, r.deliveries, id)
+			delete(r.deliveries, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,20 @@
+CREATE TABLE users (
+    id       TEXT PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    password TEXT NOT NULL,
+    role     TEXT NOT NULL
+);
+
+CREATE TABLE tasks (
+    id          TEXT PRIMARY KEY,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    due_date    TEXT NOT NULL,
+    status      TEXT NOT NULL,
+    user_id     TEXT NOT NULL,
+    created_at  TEXT NOT NULL
+);
+
+CREATE INDEX idx_tasks_user_due_date ON tasks (user_id, due_date, id);
+CREATE INDEX idx_tasks_user_title ON tasks (user_id, title, id);
+CREATE INDEX idx_tasks_user_status ON tasks (user_id, status, id);
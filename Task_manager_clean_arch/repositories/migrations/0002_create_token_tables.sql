@@ -0,0 +1,19 @@
+CREATE TABLE refresh_tokens (
+    id                TEXT PRIMARY KEY,
+    user_id           TEXT NOT NULL,
+    family_id         TEXT NOT NULL,
+    token_hash        TEXT NOT NULL UNIQUE,
+    access_token_id   TEXT NOT NULL,
+    access_expires_at TEXT NOT NULL,
+    expires_at        TEXT NOT NULL,
+    created_at        TEXT NOT NULL,
+    used_at           TEXT,
+    revoked           INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_refresh_tokens_family ON refresh_tokens (family_id);
+
+CREATE TABLE revoked_tokens (
+    jti        TEXT PRIMARY KEY,
+    expires_at TEXT NOT NULL
+);
@@ -0,0 +1,2 @@
+-- JSON array of {"from", "to", "changed_by", "changed_at"} objects, oldest first.
+ALTER TABLE tasks ADD COLUMN status_history TEXT NOT NULL DEFAULT '[]';
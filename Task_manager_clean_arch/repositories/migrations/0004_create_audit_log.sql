@@ -0,0 +1,24 @@
+CREATE TABLE audit_log (
+    id          TEXT PRIMARY KEY,
+    entity_type TEXT NOT NULL,
+    entity_id   TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    actor_id    TEXT NOT NULL,
+    timestamp   TEXT NOT NULL,
+    changes     TEXT NOT NULL DEFAULT '[]'
+);
+
+CREATE INDEX idx_audit_log_timestamp ON audit_log (timestamp, id);
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_id, timestamp, id);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor_id, timestamp, id);
+
+-- The audit log is append-only.
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
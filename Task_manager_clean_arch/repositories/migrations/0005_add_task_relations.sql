@@ -0,0 +1,5 @@
+-- parent_id is empty for top-level tasks; blocked_by is a JSON array of task IDs.
+ALTER TABLE tasks ADD COLUMN parent_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN blocked_by TEXT NOT NULL DEFAULT '[]';
+
+CREATE INDEX idx_tasks_parent ON tasks (parent_id, created_at, id);
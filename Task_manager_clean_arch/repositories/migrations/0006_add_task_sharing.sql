@@ -0,0 +1,6 @@
+-- assignee_id is empty for unassigned tasks; collaborators is a JSON array of
+-- {"user_id", "role"} objects.
+ALTER TABLE tasks ADD COLUMN assignee_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN collaborators TEXT NOT NULL DEFAULT '[]';
+
+CREATE INDEX idx_tasks_assignee ON tasks (assignee_id);
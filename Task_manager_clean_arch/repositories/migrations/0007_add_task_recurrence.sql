@@ -0,0 +1,3 @@
+-- recurrence is empty for one-off tasks, otherwise a JSON object with the
+-- rule, time zone, series start and position of the occurrence.
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
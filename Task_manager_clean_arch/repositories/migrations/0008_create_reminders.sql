@@ -0,0 +1,17 @@
+-- reminder_offsets is a JSON array of nanosecond offsets, or NULL for the
+-- server defaults.
+ALTER TABLE users ADD COLUMN reminder_offsets TEXT;
+
+-- reminders records every reminder sent. The unique key is what keeps a
+-- reminder from going out twice, even across restarts.
+CREATE TABLE reminders (
+    id        TEXT PRIMARY KEY,
+    task_id   TEXT NOT NULL,
+    user_id   TEXT NOT NULL,
+    due_date  TEXT NOT NULL,
+    offset_ns INTEGER NOT NULL,
+    sent_at   TEXT NOT NULL,
+    UNIQUE (task_id, user_id, due_date, offset_ns)
+);
+
+CREATE INDEX idx_tasks_due_date ON tasks (due_date, id);
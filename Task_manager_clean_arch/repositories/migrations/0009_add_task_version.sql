@@ -0,0 +1,3 @@
+-- version counts saved changes for optimistic concurrency. Existing tasks
+-- start at 0, like Mongo documents written before the field existed.
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- deleted_at is empty for live tasks and the time the task was moved to the
+-- trash otherwise; deleted_by is who moved it.
+ALTER TABLE tasks ADD COLUMN deleted_at TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN deleted_by TEXT NOT NULL DEFAULT '';
+CREATE INDEX idx_tasks_deleted_at ON tasks (deleted_at);
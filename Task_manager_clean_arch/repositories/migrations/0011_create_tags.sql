@@ -0,0 +1,12 @@
+-- tags holds each user's tag definitions; tasks.tags is a JSON array of the
+-- owner's tag names.
+CREATE TABLE tags (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    color      TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+ALTER TABLE tasks ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
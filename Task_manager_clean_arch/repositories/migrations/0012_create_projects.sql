@@ -0,0 +1,16 @@
+-- members is a JSON array of {"user_id", "role"} objects. Tasks outside a
+-- project have an empty project_id, and archived_at is empty unless the
+-- task's project is archived.
+CREATE TABLE projects (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL,
+    members     TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    archived_at TEXT NOT NULL DEFAULT ''
+);
+
+ALTER TABLE tasks ADD COLUMN project_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN archived_at TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_tasks_project ON tasks (project_id, due_date, id);
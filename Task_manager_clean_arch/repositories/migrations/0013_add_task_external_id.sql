@@ -0,0 +1,5 @@
+-- external_id is empty for tasks that were not imported. An owner's
+-- imported tasks have distinct external IDs.
+ALTER TABLE tasks ADD COLUMN external_id TEXT NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX idx_tasks_external_id ON tasks (user_id, external_id) WHERE external_id != '';
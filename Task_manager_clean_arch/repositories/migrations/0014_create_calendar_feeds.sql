@@ -0,0 +1,10 @@
+-- calendar_feeds holds the secret feed URLs users give their calendar apps.
+CREATE TABLE calendar_feeds (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_calendar_feeds_user ON calendar_feeds (user_id);
@@ -0,0 +1,33 @@
+-- webhooks are the endpoints task events are posted to, and
+-- webhook_deliveries logs every event sent to one of them.
+CREATE TABLE webhooks (
+    id                TEXT PRIMARY KEY,
+    user_id           TEXT NOT NULL,
+    url               TEXT NOT NULL,
+    secret            TEXT NOT NULL,
+    events            TEXT NOT NULL DEFAULT '[]',
+    all_tasks         INTEGER NOT NULL DEFAULT 0,
+    failed_deliveries INTEGER NOT NULL DEFAULT 0,
+    disabled_at       TEXT NOT NULL DEFAULT '',
+    created_at        TEXT NOT NULL
+);
+
+CREATE INDEX idx_webhooks_user ON webhooks (user_id);
+
+CREATE TABLE webhook_deliveries (
+    id              TEXT PRIMARY KEY,
+    webhook_id      TEXT NOT NULL,
+    event_id        TEXT NOT NULL,
+    event           TEXT NOT NULL,
+    payload         TEXT NOT NULL,
+    status          TEXT NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL,
+    last_attempt_at TEXT NOT NULL DEFAULT '',
+    response_status INTEGER NOT NULL DEFAULT 0,
+    error           TEXT NOT NULL DEFAULT '',
+    created_at      TEXT NOT NULL
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, created_at);
@@ -0,0 +1,16 @@
+-- roles holds the named permission sets users are given. permissions is a
+-- JSON array of permission names.
+CREATE TABLE roles (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT NOT NULL DEFAULT '[]',
+    built_in    INTEGER NOT NULL DEFAULT 0,
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
+
+-- users.roles, a JSON array of role names, replaces the single role.
+ALTER TABLE users ADD COLUMN roles TEXT NOT NULL DEFAULT '[]';
+UPDATE users SET roles = json_array(role) WHERE role != '';
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,2 @@
+-- disabled_at is when an admin disabled the account; '' while it is enabled.
+ALTER TABLE users ADD COLUMN disabled_at TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+-- Changing a password revokes every session of the user.
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens (user_id);
+
+-- password_reset_tokens holds hashes of the single-use tokens mailed to
+-- users who forgot their password.
+CREATE TABLE password_reset_tokens (
+    id         TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    used_at    TEXT
+);
+
+CREATE INDEX idx_password_reset_tokens_user ON password_reset_tokens (user_id);
@@ -0,0 +1,11 @@
+-- login_attempts counts recent failed logins per username and per client
+-- address. locked_until is '' while logins may be tried at once.
+CREATE TABLE login_attempts (
+    key          TEXT PRIMARY KEY,
+    failures     INTEGER NOT NULL,
+    last_failure TEXT NOT NULL,
+    locked_until TEXT NOT NULL DEFAULT '',
+    expires_at   TEXT NOT NULL
+);
+
+CREATE INDEX idx_login_attempts_expires ON login_attempts (expires_at);
@@ -0,0 +1,3 @@
+-- Tasks saved before versioning were left at version 0, which no If-Match
+-- header can name. Start them at 1 like every newly created task.
+UPDATE tasks SET version = 1 WHERE version = 0;
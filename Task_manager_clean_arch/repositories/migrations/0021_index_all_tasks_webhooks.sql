@@ -0,0 +1,3 @@
+-- Every task event is offered to the all_tasks webhooks, so find them
+-- without reading the whole table.
+CREATE INDEX IF NOT EXISTS idx_webhooks_all_tasks ON webhooks (all_tasks) WHERE all_tasks = 1;
@@ -10,7 +10,15 @@ type User struct {
 	ID       primitive.ObjectID `bson:"_id,omitempty"`
 	Username string             `bson:"username"`
 	Password string             `bson:"password"`
-	Role     string             `bson:"role"`
+	Roles    []string           `bson:"roles"`
+	// Role is the single role users had before they could have several. It
+	// is only read, and removed when the user is next saved.
+	Role string `bson:"role,omitempty"`
+	// ReminderOffsets are stored in nanoseconds. It is always written, so a
+	// null can tell "use the defaults" apart from an empty list.
+	ReminderOffsets []time.Duration `bson:"reminder_offsets"`
+	// DisabledAt is null, or missing, while the account is enabled.
+	DisabledAt *time.Time `bson:"disabled_at"`
 }
 type Task struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
@@ -19,4 +27,157 @@ type Task struct {
 	DueDate     time.Time          `bson:"due_date"`
 	Status      string             `bson:"status"`
 	UserID      primitive.ObjectID `bson:"user_id"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	// StatusHistory is embedded in the task document.
+	StatusHistory []StatusChange `bson:"status_history,omitempty"`
+	// The relation and sharing fields are always written so an update can
+	// clear them.
+	ParentID      primitive.ObjectID   `bson:"parent_id"`
+	BlockedBy     []primitive.ObjectID `bson:"blocked_by"`
+	AssigneeID    primitive.ObjectID   `bson:"assignee_id"`
+	Collaborators []Collaborator       `bson:"collaborators"`
+	Recurrence    *Recurrence          `bson:"recurrence"`
+	Tags          []string             `bson:"tags"`
+	ProjectID     primitive.ObjectID   `bson:"project_id"`
+	// ExternalID is missing for tasks that were not imported, which keeps
+	// them out of the unique (user_id, external_id) index.
+	ExternalID string `bson:"external_id,omitempty"`
+	// ArchivedAt is null, or missing, for tasks outside archived projects.
+	ArchivedAt *time.Time `bson:"archived_at"`
+	// Version is missing from tasks saved before it existed, which reads as 0.
+	Version int64 `bson:"version"`
+	// DeletedAt is null, or missing, for tasks that are not in the trash.
+	DeletedAt *time.Time         `bson:"deleted_at"`
+	DeletedBy primitive.ObjectID `bson:"deleted_by"`
+}
+type Tag struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	Name      string             `bson:"name"`
+	Color     string             `bson:"color"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+type CalendarFeed struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	Name      string             `bson:"name"`
+	TokenHash string             `bson:"token_hash"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+type Webhook struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty"`
+	UserID           primitive.ObjectID `bson:"user_id"`
+	URL              string             `bson:"url"`
+	Secret           string             `bson:"secret"`
+	Events           []string           `bson:"events"`
+	AllTasks         bool               `bson:"all_tasks"`
+	FailedDeliveries int                `bson:"failed_deliveries"`
+	// DisabledAt is null while the webhook is enabled.
+	DisabledAt *time.Time `bson:"disabled_at"`
+	CreatedAt  time.Time  `bson:"created_at"`
+}
+type WebhookDelivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	WebhookID      primitive.ObjectID `bson:"webhook_id"`
+	EventID        primitive.ObjectID `bson:"event_id"`
+	Event          string             `bson:"event"`
+	Payload        string             `bson:"payload"`
+	Status         string             `bson:"status"`
+	Attempts       int                `bson:"attempts"`
+	NextAttemptAt  time.Time          `bson:"next_attempt_at"`
+	LastAttemptAt  *time.Time         `bson:"last_attempt_at"`
+	ResponseStatus int                `bson:"response_status"`
+	Error          string             `bson:"error"`
+	CreatedAt      time.Time          `bson:"created_at"`
+}
+type Recurrence struct {
+	Rule       string             `bson:"rule"`
+	TimeZone   string             `bson:"time_zone"`
+	Start      time.Time          `bson:"start"`
+	SeriesID   primitive.ObjectID `bson:"series_id"`
+	Occurrence int                `bson:"occurrence"`
+	NextTaskID primitive.ObjectID `bson:"next_task_id"`
+}
+type Collaborator struct {
+	UserID primitive.ObjectID `bson:"user_id"`
+	Role   string             `bson:"role"`
+}
+type StatusChange struct {
+	From      string             `bson:"from"`
+	To        string             `bson:"to"`
+	ChangedBy primitive.ObjectID `bson:"changed_by"`
+	ChangedAt time.Time          `bson:"changed_at"`
+}
+type Reminder struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	TaskID  primitive.ObjectID `bson:"task_id"`
+	UserID  primitive.ObjectID `bson:"user_id"`
+	DueDate time.Time          `bson:"due_date"`
+	Offset  time.Duration      `bson:"offset"`
+	SentAt  time.Time          `bson:"sent_at"`
+}
+type RefreshToken struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty"`
+	UserID          primitive.ObjectID `bson:"user_id"`
+	FamilyID        primitive.ObjectID `bson:"family_id"`
+	TokenHash       string             `bson:"token_hash"`
+	AccessTokenID   string             `bson:"access_token_id"`
+	AccessExpiresAt time.Time          `bson:"access_expires_at"`
+	ExpiresAt       time.Time          `bson:"expires_at"`
+	CreatedAt       time.Time          `bson:"created_at"`
+	UsedAt          time.Time          `bson:"used_at"`
+	Revoked         bool               `bson:"revoked"`
+}
+type PasswordResetToken struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	TokenHash string             `bson:"token_hash"`
+	ExpiresAt time.Time          `bson:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UsedAt    time.Time          `bson:"used_at"`
+}
+type LoginAttempts struct {
+	Key         string    `bson:"_id"`
+	Failures    int       `bson:"failures"`
+	LastFailure time.Time `bson:"last_failure"`
+	LockedUntil time.Time `bson:"locked_until"`
+	ExpiresAt   time.Time `bson:"expires_at"`
+}
+type AuditEntry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	EntityType string             `bson:"entity_type"`
+	EntityID   primitive.ObjectID `bson:"entity_id"`
+	Action     string             `bson:"action"`
+	ActorID    primitive.ObjectID `bson:"actor_id"`
+	Timestamp  time.Time          `bson:"timestamp"`
+	Changes    []FieldChange      `bson:"changes"`
+}
+type FieldChange struct {
+	Field  string `bson:"field"`
+	Before string `bson:"before"`
+	After  string `bson:"after"`
+}
+
+type Project struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description"`
+	Members     []ProjectMember    `bson:"members"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	ArchivedAt  *time.Time         `bson:"archived_at"`
+}
+
+type ProjectMember struct {
+	UserID primitive.ObjectID `bson:"user_id"`
+	Role   string             `bson:"role"`
+}
+
+type Role struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Name        string             `bson:"name"`
+	Description string             `bson:"description"`
+	Permissions []string           `bson:"permissions"`
+	BuiltIn     bool               `bson:"built_in"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
 }
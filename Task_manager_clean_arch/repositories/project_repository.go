@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IProjectRepository stores projects together with their members.
+type IProjectRepository interface {
+	Create(ctx context.Context, project *domain.Project) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Project, error)
+	// ListByMember returns the projects the user is a member of, ordered by
+	// name. Archived projects are left out unless includeArchived is set.
+	ListByMember(ctx context.Context, userID primitive.ObjectID, includeArchived bool) ([]domain.Project, error)
+	// Update saves the project's name, description, members and archive
+	// time. It returns mongo.ErrNoDocuments if the project does not exist.
+	Update(ctx context.Context, project *domain.Project) error
+}
+
+// mongoProjectRepository is the concrete implementation.
+type mongoProjectRepository struct {
+	collection *mongo.Collection
+}
+
+// NewProjectRepository is the constructor.
+func NewProjectRepository(db *mongo.Database) IProjectRepository {
+	collection := db.Collection("projects")
+	_, _ = collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "members.user_id", Value: 1}, {Key: "name", Value: 1}},
+	})
+	return &mongoProjectRepository{collection: collection}
+}
+
+func toBsonProject(project *domain.Project) *datamodels.Project {
+	members := make([]datamodels.ProjectMember, len(project.Members))
+	for i, m := range project.Members {
+		members[i] = datamodels.ProjectMember{UserID: m.UserID, Role: m.Role}
+	}
+	return &datamodels.Project{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Members:     members,
+		CreatedAt:   project.CreatedAt,
+		ArchivedAt:  toBsonTime(project.ArchivedAt),
+	}
+}
+
+func toDomainProject(project *datamodels.Project) *domain.Project {
+	var members []domain.ProjectMember
+	for _, m := range project.Members {
+		members = append(members, domain.ProjectMember{UserID: m.UserID, Role: m.Role})
+	}
+	return &domain.Project{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Members:     members,
+		CreatedAt:   project.CreatedAt,
+		ArchivedAt:  toDomainTime(project.ArchivedAt),
+	}
+}
+
+func (r *mongoProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	result, err := r.collection.InsertOne(ctx, toBsonProject(project))
+	if err != nil {
+		return err
+	}
+	project.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoProjectRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Project, error) {
+	var bsonProject datamodels.Project
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&bsonProject); err != nil {
+		return nil, err
+	}
+	return toDomainProject(&bsonProject), nil
+}
+
+func (r *mongoProjectRepository) ListByMember(ctx context.Context, userID primitive.ObjectID, includeArchived bool) ([]domain.Project, error) {
+	filter := bson.M{"members.user_id": userID}
+	if !includeArchived {
+		filter["archived_at"] = nil
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonProjects []datamodels.Project
+	if err := cursor.All(ctx, &bsonProjects); err != nil {
+		return nil, err
+	}
+	projects := make([]domain.Project, len(bsonProjects))
+	for i := range bsonProjects {
+		projects[i] = *toDomainProject(&bsonProjects[i])
+	}
+	return projects, nil
+}
+
+func (r *mongoProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	bsonProject := toBsonProject(project)
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": project.ID}, bson.M{"$set": bson.M{
+		"name":        bsonProject.Name,
+		"description": bsonProject.Description,
+		"members":     bsonProject.Members,
+		"archived_at": bsonProject.ArchivedAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
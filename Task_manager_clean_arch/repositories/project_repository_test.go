@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ProjectRepositoryTestSuite exercises an IProjectRepository implementation.
+type ProjectRepositoryTestSuite struct {
+	suite.Suite
+	backend     testBackend
+	projectRepo IProjectRepository
+}
+
+// SetupTest gives every test an empty repository.
+func (s *ProjectRepositoryTestSuite) SetupTest() {
+	s.projectRepo = s.backend.open(s.T()).Projects
+}
+
+func TestProjectRepository(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			suite.Run(t, &ProjectRepositoryTestSuite{backend: backend})
+		})
+	}
+}
+
+func (s *ProjectRepositoryTestSuite) TestListByMember() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	website := &domain.Project{Name: "Website", Members: []domain.ProjectMember{{UserID: alice, Role: domain.ProjectOwner}}, CreatedAt: now}
+	backlog := &domain.Project{Name: "Backlog", Description: "Someday", CreatedAt: now, Members: []domain.ProjectMember{
+		{UserID: bob, Role: domain.ProjectOwner},
+		{UserID: alice, Role: domain.ProjectViewer},
+	}}
+	assert.NoError(s.projectRepo.Create(ctx, website))
+	assert.NoError(s.projectRepo.Create(ctx, backlog))
+	assert.False(website.ID.IsZero())
+
+	projects, err := s.projectRepo.ListByMember(ctx, alice, false)
+	assert.NoError(err)
+	if assert.Len(projects, 2) {
+		assert.Equal("Backlog", projects[0].Name)
+		assert.Equal(backlog.Members, projects[0].Members)
+		assert.Equal("Website", projects[1].Name)
+	}
+
+	backlog.Name = "Icebox"
+	backlog.ArchivedAt = now
+	backlog.Members = backlog.Members[:1]
+	assert.NoError(s.projectRepo.Update(ctx, backlog))
+	found, err := s.projectRepo.GetByID(ctx, backlog.ID)
+	assert.NoError(err)
+	assert.Equal("Icebox", found.Name)
+	assert.Equal("Someday", found.Description)
+	assert.True(now.Equal(found.ArchivedAt))
+	assert.True(now.Equal(found.CreatedAt))
+
+	projects, err = s.projectRepo.ListByMember(ctx, alice, true)
+	assert.NoError(err)
+	assert.Len(projects, 1)
+	projects, err = s.projectRepo.ListByMember(ctx, bob, false)
+	assert.NoError(err)
+	assert.Empty(projects)
+	projects, err = s.projectRepo.ListByMember(ctx, bob, true)
+	assert.NoError(err)
+	assert.Len(projects, 1)
+
+	_, err = s.projectRepo.GetByID(ctx, primitive.NewObjectID())
+	assert.ErrorIs(err, mongo.ErrNoDocuments)
+	assert.ErrorIs(s.projectRepo.Update(ctx, &domain.Project{ID: primitive.NewObjectID()}), mongo.ErrNoDocuments)
+}
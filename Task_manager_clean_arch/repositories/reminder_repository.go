@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IReminderRepository records which due-date reminders have been sent.
+type IReminderRepository interface {
+	// Create records a reminder. It fails with a duplicate key error if the
+	// same reminder (task, user, due date and offset) was already recorded,
+	// which lets the scheduler claim a reminder before sending it.
+	Create(ctx context.Context, reminder *domain.Reminder) error
+	// Delete forgets a reminder, so it is sent again on the next check.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// mongoReminderRepository is the concrete implementation.
+type mongoReminderRepository struct {
+	collection *mongo.Collection
+}
+
+// NewReminderRepository is the constructor.
+func NewReminderRepository(db *mongo.Database) IReminderRepository {
+	collection := db.Collection("reminders")
+	_, _ = collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "task_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "due_date", Value: 1}, {Key: "offset", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return &mongoReminderRepository{collection: collection}
+}
+
+func (r *mongoReminderRepository) Create(ctx context.Context, reminder *domain.Reminder) error {
+	result, err := r.collection.InsertOne(ctx, &datamodels.Reminder{
+		ID:      reminder.ID,
+		TaskID:  reminder.TaskID,
+		UserID:  reminder.UserID,
+		DueDate: reminder.DueDate,
+		Offset:  reminder.Offset,
+		SentAt:  reminder.SentAt,
+	})
+	if err != nil {
+		return err
+	}
+	reminder.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoReminderRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReminderRepositoryTestSuite exercises an IReminderRepository implementation.
+type ReminderRepositoryTestSuite struct {
+	suite.Suite
+	backend      testBackend
+	reminderRepo IReminderRepository
+}
+
+// SetupTest gives every test an empty repository.
+func (s *ReminderRepositoryTestSuite) SetupTest() {
+	s.reminderRepo = s.backend.open(s.T()).Reminders
+}
+
+func TestReminderRepository(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			suite.Run(t, &ReminderRepositoryTestSuite{backend: backend})
+		})
+	}
+}
+
+func (s *ReminderRepositoryTestSuite) TestCreate_RejectsTheSameReminderTwice() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	due := time.Now().UTC().Truncate(time.Millisecond)
+	reminder := func(offset time.Duration) *domain.Reminder {
+		return &domain.Reminder{TaskID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), DueDate: due, Offset: offset, SentAt: due}
+	}
+
+	first := reminder(time.Hour)
+	assert.NoError(s.reminderRepo.Create(ctx, first))
+	assert.False(first.ID.IsZero())
+
+	again := *first
+	again.ID = primitive.NilObjectID
+	err := s.reminderRepo.Create(ctx, &again)
+	assert.True(mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+
+	// A different offset or due date is a different reminder.
+	otherOffset := *first
+	otherOffset.ID, otherOffset.Offset = primitive.NilObjectID, 0
+	assert.NoError(s.reminderRepo.Create(ctx, &otherOffset))
+	moved := *first
+	moved.ID, moved.DueDate = primitive.NilObjectID, due.Add(24*time.Hour)
+	assert.NoError(s.reminderRepo.Create(ctx, &moved))
+
+	// Deleting a reminder lets it be recorded again.
+	assert.NoError(s.reminderRepo.Delete(ctx, first.ID))
+	again.ID = primitive.NilObjectID
+	assert.NoError(s.reminderRepo.Create(ctx, &again))
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Repositories bundles one storage backend's implementation of every
+// repository, so the backend can be chosen in a single place at startup.
+type Repositories struct {
+	Users         IUserRepository
+	Tasks         ITaskRepository
+	Tokens        ITokenRepository
+	Audit         IAuditRepository
+	Reminders     IReminderRepository
+	Tags          ITagRepository
+	Projects      IProjectRepository
+	CalendarFeeds ICalendarFeedRepository
+	Webhooks      IWebhookRepository
+	Roles         IRoleRepository
+	LoginAttempts ILoginAttemptRepository
+	// UnitOfWork makes calls to the repositories above atomic.
+	UnitOfWork IUnitOfWork
+}
+
+// NewMongoRepositories builds the MongoDB implementations.
+func NewMongoRepositories(db *mongo.Database) *Repositories {
+	return &Repositories{
+		Users:         NewUserRepository(db),
+		Tasks:         NewTaskRepository(db),
+		Tokens:        NewTokenRepository(db),
+		Audit:         NewAuditRepository(db),
+		Reminders:     NewReminderRepository(db),
+		Tags:          NewTagRepository(db),
+		Projects:      NewProjectRepository(db),
+		CalendarFeeds: NewCalendarFeedRepository(db),
+		Webhooks:      NewWebhookRepository(db),
+		Roles:         NewRoleRepository(db),
+		LoginAttempts: NewLoginAttemptRepository(db),
+		UnitOfWork:    NewMongoUnitOfWork(db.Client()),
+	}
+}
+
+// NewSQLiteRepositories builds the SQLite implementations. db must come
+// from OpenSQLite.
+func NewSQLiteRepositories(db *sql.DB) *Repositories {
+	return &Repositories{
+		Users:         NewSQLiteUserRepository(db),
+		Tasks:         NewSQLiteTaskRepository(db),
+		Tokens:        NewSQLiteTokenRepository(db),
+		Audit:         NewSQLiteAuditRepository(db),
+		Reminders:     NewSQLiteReminderRepository(db),
+		Tags:          NewSQLiteTagRepository(db),
+		Projects:      NewSQLiteProjectRepository(db),
+		CalendarFeeds: NewSQLiteCalendarFeedRepository(db),
+		Webhooks:      NewSQLiteWebhookRepository(db),
+		Roles:         NewSQLiteRoleRepository(db),
+		LoginAttempts: NewSQLiteLoginAttemptRepository(db),
+		UnitOfWork:    NewSQLiteUnitOfWork(db),
+	}
+}
+
+// NewMemoryRepositories builds the in-memory implementations.
+func NewMemoryRepositories() *Repositories {
+	return &Repositories{
+		Users:         NewMemoryUserRepository(),
+		Tasks:         NewMemoryTaskRepository(),
+		Tokens:        NewMemoryTokenRepository(),
+		Audit:         NewMemoryAuditRepository(),
+		Reminders:     NewMemoryReminderRepository(),
+		Tags:          NewMemoryTagRepository(),
+		Projects:      NewMemoryProjectRepository(),
+		CalendarFeeds: NewMemoryCalendarFeedRepository(),
+		Webhooks:      NewMemoryWebhookRepository(),
+		Roles:         NewMemoryRoleRepository(),
+		LoginAttempts: NewMemoryLoginAttemptRepository(),
+		UnitOfWork:    NewMemoryUnitOfWork(),
+	}
+}
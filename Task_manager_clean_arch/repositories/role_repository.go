@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IRoleRepository stores the roles users are given. Role names are unique:
+// Create fails with a duplicate key error otherwise. A role keeps its name,
+// since users refer to their roles by name.
+type IRoleRepository interface {
+	Create(ctx context.Context, role *domain.Role) error
+	GetByName(ctx context.Context, name string) (*domain.Role, error)
+	// List returns every role ordered by name.
+	List(ctx context.Context) ([]domain.Role, error)
+	// Update saves the role's description, permissions and update time. It
+	// returns mongo.ErrNoDocuments if the role does not exist.
+	Update(ctx context.Context, role *domain.Role) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// mongoRoleRepository is the concrete implementation.
+type mongoRoleRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRoleRepository is the constructor.
+func NewRoleRepository(db *mongo.Database) IRoleRepository {
+	collection := db.Collection("roles")
+	_, _ = collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.M{"name": 1},
+		Options: options.Index().SetUnique(true),
+	})
+	return &mongoRoleRepository{collection: collection}
+}
+
+func toBsonRole(role *domain.Role) *datamodels.Role {
+	return &datamodels.Role{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		BuiltIn:     role.BuiltIn,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+func toDomainRole(role *datamodels.Role) *domain.Role {
+	return &domain.Role{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+		BuiltIn:     role.BuiltIn,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+func (r *mongoRoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	result, err := r.collection.InsertOne(ctx, toBsonRole(role))
+	if err != nil {
+		return err
+	}
+	role.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoRoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	var bsonRole datamodels.Role
+	if err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&bsonRole); err != nil {
+		return nil, err
+	}
+	return toDomainRole(&bsonRole), nil
+}
+
+func (r *mongoRoleRepository) List(ctx context.Context) ([]domain.Role, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonRoles []datamodels.Role
+	if err := cursor.All(ctx, &bsonRoles); err != nil {
+		return nil, err
+	}
+	roles := make([]domain.Role, len(bsonRoles))
+	for i := range bsonRoles {
+		roles[i] = *toDomainRole(&bsonRoles[i])
+	}
+	return roles, nil
+}
+
+func (r *mongoRoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": role.ID}, bson.M{"$set": bson.M{
+		"description": role.Description,
+		"permissions": role.Permissions,
+		"updated_at":  role.UpdatedAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoRoleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RoleRepositoryTestSuite exercises an IRoleRepository implementation.
+type RoleRepositoryTestSuite struct {
+	suite.Suite
+	backend  testBackend
+	roleRepo IRoleRepository
+	userRepo IUserRepository
+}
+
+// SetupTest gives every test empty repositories.
+func (s *RoleRepositoryTestSuite) SetupTest() {
+	repos := s.backend.open(s.T())
+	s.roleRepo, s.userRepo = repos.Roles, repos.Users
+}
+
+func TestRoleRepository(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			suite.Run(t, &RoleRepositoryTestSuite{backend: backend})
+		})
+	}
+}
+
+func (s *RoleRepositoryTestSuite) TestRolesAreUniqueByName() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	manager := &domain.Role{Name: "manager", Permissions: []string{domain.PermTaskCreate}, BuiltIn: true, CreatedAt: now, UpdatedAt: now}
+	auditor := &domain.Role{Name: "auditor", Description: "Reads the audit log", CreatedAt: now, UpdatedAt: now}
+	assert.NoError(s.roleRepo.Create(ctx, manager))
+	assert.NoError(s.roleRepo.Create(ctx, auditor))
+	assert.False(manager.ID.IsZero())
+	err := s.roleRepo.Create(ctx, &domain.Role{Name: "manager", CreatedAt: now, UpdatedAt: now})
+	assert.True(mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+
+	auditor.Permissions = []string{domain.PermAuditRead}
+	auditor.UpdatedAt = now.Add(time.Minute)
+	assert.NoError(s.roleRepo.Update(ctx, auditor))
+	found, err := s.roleRepo.GetByName(ctx, "auditor")
+	assert.NoError(err)
+	assert.Equal("Reads the audit log", found.Description)
+	assert.Equal([]string{domain.PermAuditRead}, found.Permissions)
+	assert.False(found.BuiltIn)
+	assert.True(now.Equal(found.CreatedAt))
+	assert.True(auditor.UpdatedAt.Equal(found.UpdatedAt))
+
+	roles, err := s.roleRepo.List(ctx)
+	assert.NoError(err)
+	if assert.Len(roles, 2) {
+		assert.Equal("auditor", roles[0].Name)
+		assert.Equal("manager", roles[1].Name)
+		assert.True(roles[1].BuiltIn)
+	}
+
+	assert.NoError(s.roleRepo.Delete(ctx, auditor.ID))
+	_, err = s.roleRepo.GetByName(ctx, "auditor")
+	assert.ErrorIs(err, mongo.ErrNoDocuments)
+	assert.ErrorIs(s.roleRepo.Update(ctx, auditor), mongo.ErrNoDocuments)
+}
+
+func (s *RoleRepositoryTestSuite) TestCountUsersByRole() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	assert.NoError(s.userRepo.Create(ctx, &domain.User{Username: "ada", Password: "pw", Roles: []string{"admin", "manager"}}))
+	assert.NoError(s.userRepo.Create(ctx, &domain.User{Username: "bob", Password: "pw", Roles: []string{"manager"}}))
+	assert.NoError(s.userRepo.Create(ctx, &domain.User{Username: "cy", Password: "pw", Roles: []string{"user"}}))
+
+	admins, err := s.userRepo.CountByRole(ctx, "admin")
+	assert.NoError(err)
+	managers, err := s.userRepo.CountByRole(ctx, "manager")
+	assert.NoError(err)
+	auditors, err := s.userRepo.CountByRole(ctx, "auditor")
+	assert.NoError(err)
+
+	// --- ASSERT ---
+	assert.Equal(int64(1), admins)
+	assert.Equal(int64(2), managers)
+	assert.Zero(auditors)
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteAuditRepository stores audit entries in the audit_log table, which
+// triggers keep append-only.
+type sqliteAuditRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAuditRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteAuditRepository(db *sql.DB) IAuditRepository {
+	return &sqliteAuditRepository{db: db}
+}
+
+const auditColumns = `id, entity_type, entity_id, action, actor_id, timestamp, changes`
+
+// sqlFieldChange is the JSON shape of a field change in the changes column.
+type sqlFieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// scanAuditEntry reads one audit_log row into a domain.AuditEntry.
+func scanAuditEntry(row interface{ Scan(...interface{}) error }) (*domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	var id, entityID, actorID, timestamp, changes string
+	if err := row.Scan(&id, &entry.EntityType, &entityID, &entry.Action, &actorID, &timestamp, &changes); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if entry.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if entry.EntityID, err = parseSQLID(entityID); err != nil {
+		return nil, err
+	}
+	if entry.ActorID, err = parseSQLID(actorID); err != nil {
+		return nil, err
+	}
+	if entry.Timestamp, err = fromSQLTime(timestamp); err != nil {
+		return nil, err
+	}
+	var fieldChanges []sqlFieldChange
+	if err := json.Unmarshal([]byte(changes), &fieldChanges); err != nil {
+		return nil, err
+	}
+	for _, c := range fieldChanges {
+		entry.Changes = append(entry.Changes, domain.FieldChange{Field: c.Field, Before: c.Before, After: c.After})
+	}
+	return &entry, nil
+}
+
+func (r *sqliteAuditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	id := entry.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	changes := make([]sqlFieldChange, len(entry.Changes))
+	for i, c := range entry.Changes {
+		changes[i] = sqlFieldChange{Field: c.Field, Before: c.Before, After: c.After}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	_, err = sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO audit_log (`+auditColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		id.Hex(), entry.EntityType, entry.EntityID.Hex(), entry.Action, entry.ActorID.Hex(), toSQLTime(entry.Timestamp), string(data))
+	if err != nil {
+		return sqlError(err)
+	}
+	entry.ID = id
+	return nil
+}
+
+func (r *sqliteAuditRepository) List(ctx context.Context, q AuditQuery) ([]domain.AuditEntry, string, error) {
+	where := []string{"1 = 1"}
+	var args []interface{}
+
+	if q.EntityType != "" {
+		where = append(where, "entity_type = ?")
+		args = append(args, q.EntityType)
+	}
+	if !q.EntityID.IsZero() {
+		where = append(where, "entity_id = ?")
+		args = append(args, q.EntityID.Hex())
+	}
+	if !q.ActorID.IsZero() {
+		where = append(where, "actor_id = ?")
+		args = append(args, q.ActorID.Hex())
+	}
+	if q.Action != "" {
+		where = append(where, "action = ?")
+		args = append(args, q.Action)
+	}
+	if !q.From.IsZero() {
+		where = append(where, "timestamp >= ?")
+		args = append(args, toSQLTime(q.From))
+	}
+	if !q.To.IsZero() {
+		where = append(where, "timestamp < ?")
+		args = append(args, toSQLTime(q.To))
+	}
+	if q.Cursor != "" {
+		lastTime, lastID, err := decodeAuditCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		where = append(where, "(timestamp < ? OR (timestamp = ? AND id < ?))")
+		args = append(args, toSQLTime(lastTime), toSQLTime(lastTime), lastID.Hex())
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE ` + strings.Join(where, " AND ") +
+		` ORDER BY timestamp DESC, id DESC`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit+1)
+	}
+
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		entries = append(entries, *entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	return pageAuditEntries(entries, q)
+}
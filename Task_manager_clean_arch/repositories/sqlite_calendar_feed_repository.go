@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteCalendarFeedRepository stores calendar feeds in the calendar_feeds table.
+type sqliteCalendarFeedRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteCalendarFeedRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteCalendarFeedRepository(db *sql.DB) ICalendarFeedRepository {
+	return &sqliteCalendarFeedRepository{db: db}
+}
+
+const calendarFeedColumns = `id, user_id, name, token_hash, created_at`
+
+// scanCalendarFeed reads one calendar_feeds row into a domain.CalendarFeed.
+func scanCalendarFeed(row interface{ Scan(...interface{}) error }) (*domain.CalendarFeed, error) {
+	var feed domain.CalendarFeed
+	var id, userID, createdAt string
+	if err := row.Scan(&id, &userID, &feed.Name, &feed.TokenHash, &createdAt); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if feed.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if feed.UserID, err = parseSQLID(userID); err != nil {
+		return nil, err
+	}
+	if feed.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (r *sqliteCalendarFeedRepository) Create(ctx context.Context, feed *domain.CalendarFeed) error {
+	id := feed.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO calendar_feeds (`+calendarFeedColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		id.Hex(), feed.UserID.Hex(), feed.Name, feed.TokenHash, toSQLTime(feed.CreatedAt))
+	if err != nil {
+		return sqlError(err)
+	}
+	feed.ID = id
+	return nil
+}
+
+func (r *sqliteCalendarFeedRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.CalendarFeed, error) {
+	return scanCalendarFeed(sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+calendarFeedColumns+` FROM calendar_feeds WHERE id = ?`, id.Hex()))
+}
+
+func (r *sqliteCalendarFeedRepository) FindByTokenHash(ctx context.Context, hash string) (*domain.CalendarFeed, error) {
+	return scanCalendarFeed(sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+calendarFeedColumns+` FROM calendar_feeds WHERE token_hash = ?`, hash))
+}
+
+func (r *sqliteCalendarFeedRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.CalendarFeed, error) {
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, `SELECT `+calendarFeedColumns+` FROM calendar_feeds WHERE user_id = ? ORDER BY created_at, id`, userID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []domain.CalendarFeed{}
+	for rows.Next() {
+		feed, err := scanCalendarFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, *feed)
+	}
+	return feeds, rows.Err()
+}
+
+func (r *sqliteCalendarFeedRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM calendar_feeds WHERE id = ?`, id.Hex())
+	return err
+}
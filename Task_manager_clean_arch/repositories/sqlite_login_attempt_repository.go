@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"taskmanager/domain"
+	"time"
+)
+
+// sqliteLoginAttemptRepository counts failed logins in the login_attempts
+// table.
+type sqliteLoginAttemptRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteLoginAttemptRepository is the constructor. db must come from
+// OpenSQLite.
+func NewSQLiteLoginAttemptRepository(db *sql.DB) ILoginAttemptRepository {
+	return &sqliteLoginAttemptRepository{db: db}
+}
+
+const loginAttemptColumns = `key, failures, last_failure, locked_until, expires_at`
+
+// scanLoginAttempts reads one login_attempts row into a domain.LoginAttempts.
+func scanLoginAttempts(row interface{ Scan(...interface{}) error }) (*domain.LoginAttempts, error) {
+	var attempts domain.LoginAttempts
+	var lastFailure, lockedUntil, expiresAt string
+	if err := row.Scan(&attempts.Key, &attempts.Failures, &lastFailure, &lockedUntil, &expiresAt); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if attempts.LastFailure, err = fromSQLTime(lastFailure); err != nil {
+		return nil, err
+	}
+	if attempts.LockedUntil, err = fromSQLOptionalTime(lockedUntil); err != nil {
+		return nil, err
+	}
+	if attempts.ExpiresAt, err = fromSQLTime(expiresAt); err != nil {
+		return nil, err
+	}
+	return &attempts, nil
+}
+
+func (r *sqliteLoginAttemptRepository) Find(ctx context.Context, key string) (*domain.LoginAttempts, error) {
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+loginAttemptColumns+` FROM login_attempts WHERE key = ?`, key)
+	return scanLoginAttempts(row)
+}
+
+func (r *sqliteLoginAttemptRepository) RecordFailure(ctx context.Context, key string, at time.Time, window time.Duration) (*domain.LoginAttempts, error) {
+	// Expired records are swept on every failure; nothing reads them again.
+	if _, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM login_attempts WHERE expires_at < ?`, toSQLTime(at)); err != nil {
+		return nil, err
+	}
+	// The upsert reads the previous failure and writes the new count in one
+	// statement, so concurrent failures are all counted.
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `INSERT INTO login_attempts (key, failures, last_failure, expires_at) VALUES (?, 1, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN last_failure >= ? THEN failures + 1 ELSE 1 END,
+			last_failure = excluded.last_failure,
+			expires_at = MAX(excluded.expires_at, locked_until)
+		RETURNING `+loginAttemptColumns,
+		key, toSQLTime(at), toSQLTime(at.Add(window)), toSQLTime(at.Add(-window)))
+	return scanLoginAttempts(row)
+}
+
+func (r *sqliteLoginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE login_attempts SET locked_until = MAX(locked_until, ?), expires_at = MAX(expires_at, ?) WHERE key = ?`,
+		toSQLTime(until), toSQLTime(until), key)
+	return err
+}
+
+func (r *sqliteLoginAttemptRepository) UndoFailure(ctx context.Context, key string) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE login_attempts SET failures = failures - 1 WHERE key = ? AND failures > 0`, key)
+	return err
+}
+
+func (r *sqliteLoginAttemptRepository) Delete(ctx context.Context, key string) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM login_attempts WHERE key = ?`, key)
+	return err
+}
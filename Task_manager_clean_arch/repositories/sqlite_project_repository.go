@@ -0,0 +1,141 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteProjectRepository stores projects in the projects table.
+type sqliteProjectRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteProjectRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteProjectRepository(db *sql.DB) IProjectRepository {
+	return &sqliteProjectRepository{db: db}
+}
+
+const projectColumns = `id, name, description, members, created_at, archived_at`
+
+// sqlProjectMember is the JSON shape of an entry in the members column.
+type sqlProjectMember struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+func marshalProjectMembers(members []domain.ProjectMember) (string, error) {
+	converted := make([]sqlProjectMember, len(members))
+	for i, m := range members {
+		converted[i] = sqlProjectMember{UserID: m.UserID.Hex(), Role: m.Role}
+	}
+	data, err := json.Marshal(converted)
+	return string(data), err
+}
+
+func unmarshalProjectMembers(data string) ([]domain.ProjectMember, error) {
+	var stored []sqlProjectMember
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, err
+	}
+	var members []domain.ProjectMember
+	for _, m := range stored {
+		userID, err := parseSQLID(m.UserID)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, domain.ProjectMember{UserID: userID, Role: m.Role})
+	}
+	return members, nil
+}
+
+// scanProject reads one projects row into a domain.Project.
+func scanProject(row interface{ Scan(...interface{}) error }) (*domain.Project, error) {
+	var project domain.Project
+	var id, members, createdAt, archivedAt string
+	if err := row.Scan(&id, &project.Name, &project.Description, &members, &createdAt, &archivedAt); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if project.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if project.Members, err = unmarshalProjectMembers(members); err != nil {
+		return nil, err
+	}
+	if project.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	if project.ArchivedAt, err = fromSQLOptionalTime(archivedAt); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *sqliteProjectRepository) Create(ctx context.Context, project *domain.Project) error {
+	id := project.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	members, err := marshalProjectMembers(project.Members)
+	if err != nil {
+		return err
+	}
+	_, err = sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO projects (`+projectColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		id.Hex(), project.Name, project.Description, members, toSQLTime(project.CreatedAt), toSQLOptionalTime(project.ArchivedAt))
+	if err != nil {
+		return sqlError(err)
+	}
+	project.ID = id
+	return nil
+}
+
+func (r *sqliteProjectRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Project, error) {
+	return scanProject(sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+projectColumns+` FROM projects WHERE id = ?`, id.Hex()))
+}
+
+func (r *sqliteProjectRepository) ListByMember(ctx context.Context, userID primitive.ObjectID, includeArchived bool) ([]domain.Project, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects
+		WHERE EXISTS (SELECT 1 FROM json_each(projects.members) WHERE json_extract(json_each.value, '$.user_id') = ?)`
+	if !includeArchived {
+		query += ` AND archived_at = ''`
+	}
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, query+` ORDER BY name, id`, userID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []domain.Project{}
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *project)
+	}
+	return projects, rows.Err()
+}
+
+func (r *sqliteProjectRepository) Update(ctx context.Context, project *domain.Project) error {
+	members, err := marshalProjectMembers(project.Members)
+	if err != nil {
+		return err
+	}
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE projects SET name = ?, description = ?, members = ?, archived_at = ? WHERE id = ?`,
+		project.Name, project.Description, members, toSQLOptionalTime(project.ArchivedAt), project.ID.Hex())
+	if err != nil {
+		return sqlError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sqlError(sql.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteReminderRepository stores sent reminders in the reminders table.
+type sqliteReminderRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteReminderRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteReminderRepository(db *sql.DB) IReminderRepository {
+	return &sqliteReminderRepository{db: db}
+}
+
+func (r *sqliteReminderRepository) Create(ctx context.Context, reminder *domain.Reminder) error {
+	id := reminder.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO reminders (id, task_id, user_id, due_date, offset_ns, sent_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		id.Hex(), reminder.TaskID.Hex(), reminder.UserID.Hex(), toSQLTime(reminder.DueDate), int64(reminder.Offset), toSQLTime(reminder.SentAt))
+	if err != nil {
+		return sqlError(err)
+	}
+	reminder.ID = id
+	return nil
+}
+
+func (r *sqliteReminderRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM reminders WHERE id = ?`, id.Hex())
+	return err
+}
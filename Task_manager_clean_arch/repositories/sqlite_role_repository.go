@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteRoleRepository stores roles in the roles table.
+type sqliteRoleRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRoleRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteRoleRepository(db *sql.DB) IRoleRepository {
+	return &sqliteRoleRepository{db: db}
+}
+
+const roleColumns = `id, name, description, permissions, built_in, created_at, updated_at`
+
+// scanRole reads one roles row into a domain.Role.
+func scanRole(row interface{ Scan(...interface{}) error }) (*domain.Role, error) {
+	var role domain.Role
+	var id, permissions, createdAt, updatedAt string
+	if err := row.Scan(&id, &role.Name, &role.Description, &permissions, &role.BuiltIn, &createdAt, &updatedAt); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if role.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if role.Permissions, err = unmarshalTags(permissions); err != nil {
+		return nil, err
+	}
+	if role.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	if role.UpdatedAt, err = fromSQLTime(updatedAt); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *sqliteRoleRepository) Create(ctx context.Context, role *domain.Role) error {
+	id := role.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	permissions, err := marshalTags(role.Permissions)
+	if err != nil {
+		return err
+	}
+	_, err = sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO roles (`+roleColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		id.Hex(), role.Name, role.Description, permissions, role.BuiltIn, toSQLTime(role.CreatedAt), toSQLTime(role.UpdatedAt))
+	if err != nil {
+		return sqlError(err)
+	}
+	role.ID = id
+	return nil
+}
+
+func (r *sqliteRoleRepository) GetByName(ctx context.Context, name string) (*domain.Role, error) {
+	return scanRole(sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+roleColumns+` FROM roles WHERE name = ?`, name))
+}
+
+func (r *sqliteRoleRepository) List(ctx context.Context) ([]domain.Role, error) {
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, `SELECT `+roleColumns+` FROM roles ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []domain.Role{}
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+func (r *sqliteRoleRepository) Update(ctx context.Context, role *domain.Role) error {
+	permissions, err := marshalTags(role.Permissions)
+	if err != nil {
+		return err
+	}
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE roles SET description = ?, permissions = ?, updated_at = ? WHERE id = ?`,
+		role.Description, permissions, toSQLTime(role.UpdatedAt), role.ID.Hex())
+	if err != nil {
+		return sqlError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sqlError(sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *sqliteRoleRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM roles WHERE id = ?`, id.Hex())
+	return err
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteTagRepository stores tags in the tags table.
+type sqliteTagRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteTagRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteTagRepository(db *sql.DB) ITagRepository {
+	return &sqliteTagRepository{db: db}
+}
+
+const tagColumns = `id, user_id, name, color, created_at`
+
+// scanTag reads one tags row into a domain.Tag.
+func scanTag(row interface{ Scan(...interface{}) error }) (*domain.Tag, error) {
+	var tag domain.Tag
+	var id, userID, createdAt string
+	if err := row.Scan(&id, &userID, &tag.Name, &tag.Color, &createdAt); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if tag.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if tag.UserID, err = parseSQLID(userID); err != nil {
+		return nil, err
+	}
+	if tag.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *sqliteTagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	id := tag.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO tags (`+tagColumns+`) VALUES (?, ?, ?, ?, ?)`,
+		id.Hex(), tag.UserID.Hex(), tag.Name, tag.Color, toSQLTime(tag.CreatedAt))
+	if err != nil {
+		return sqlError(err)
+	}
+	tag.ID = id
+	return nil
+}
+
+func (r *sqliteTagRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Tag, error) {
+	return scanTag(sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+tagColumns+` FROM tags WHERE id = ?`, id.Hex()))
+}
+
+func (r *sqliteTagRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Tag, error) {
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, `SELECT `+tagColumns+` FROM tags WHERE user_id = ? ORDER BY name`, userID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []domain.Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, *tag)
+	}
+	return tags, rows.Err()
+}
+
+func (r *sqliteTagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE tags SET name = ?, color = ? WHERE id = ?`, tag.Name, tag.Color, tag.ID.Hex())
+	if err != nil {
+		return sqlError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sqlError(sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *sqliteTagRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, id.Hex())
+	return err
+}
@@ -0,0 +1,679 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteTaskRepository stores tasks in the tasks table.
+type sqliteTaskRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteTaskRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteTaskRepository(db *sql.DB) ITaskRepository {
+	return &sqliteTaskRepository{db: db}
+}
+
+const taskColumns = `id, title, description, due_date, status, user_id, created_at, status_history, parent_id, blocked_by, assignee_id, collaborators, recurrence, tags, project_id, archived_at, external_id, version, deleted_at, deleted_by`
+
+// sqlStatusChange is the JSON shape of a status change in status_history.
+type sqlStatusChange struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	ChangedBy string `json:"changed_by"`
+	ChangedAt string `json:"changed_at"`
+}
+
+func marshalStatusHistory(history []domain.StatusChange) (string, error) {
+	changes := make([]sqlStatusChange, len(history))
+	for i, h := range history {
+		changes[i] = sqlStatusChange{From: h.From, To: h.To, ChangedBy: h.ChangedBy.Hex(), ChangedAt: toSQLTime(h.ChangedAt)}
+	}
+	data, err := json.Marshal(changes)
+	return string(data), err
+}
+
+func unmarshalStatusHistory(data string) ([]domain.StatusChange, error) {
+	var changes []sqlStatusChange
+	if err := json.Unmarshal([]byte(data), &changes); err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	history := make([]domain.StatusChange, len(changes))
+	for i, c := range changes {
+		changedBy, err := parseSQLID(c.ChangedBy)
+		if err != nil {
+			return nil, err
+		}
+		changedAt, err := fromSQLTime(c.ChangedAt)
+		if err != nil {
+			return nil, err
+		}
+		history[i] = domain.StatusChange{From: c.From, To: c.To, ChangedBy: changedBy, ChangedAt: changedAt}
+	}
+	return history, nil
+}
+
+func marshalIDs(ids []primitive.ObjectID) (string, error) {
+	hexes := make([]string, len(ids))
+	for i, id := range ids {
+		hexes[i] = id.Hex()
+	}
+	data, err := json.Marshal(hexes)
+	return string(data), err
+}
+
+func unmarshalIDs(data string) ([]primitive.ObjectID, error) {
+	var hexes []string
+	if err := json.Unmarshal([]byte(data), &hexes); err != nil {
+		return nil, err
+	}
+	if len(hexes) == 0 {
+		return nil, nil
+	}
+	ids := make([]primitive.ObjectID, len(hexes))
+	for i, hex := range hexes {
+		id, err := parseSQLID(hex)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func marshalTags(tags []string) (string, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	data, err := json.Marshal(tags)
+	return string(data), err
+}
+
+func unmarshalTags(data string) ([]string, error) {
+	var tags []string
+	if err := json.Unmarshal([]byte(data), &tags); err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// sqlCollaborator is the JSON shape of an entry in the collaborators column.
+type sqlCollaborator struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+func marshalCollaborators(collaborators []domain.Collaborator) (string, error) {
+	converted := make([]sqlCollaborator, len(collaborators))
+	for i, c := range collaborators {
+		converted[i] = sqlCollaborator{UserID: c.UserID.Hex(), Role: c.Role}
+	}
+	data, err := json.Marshal(converted)
+	return string(data), err
+}
+
+func unmarshalCollaborators(data string) ([]domain.Collaborator, error) {
+	var stored []sqlCollaborator
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, err
+	}
+	if len(stored) == 0 {
+		return nil, nil
+	}
+	collaborators := make([]domain.Collaborator, len(stored))
+	for i, c := range stored {
+		userID, err := parseSQLID(c.UserID)
+		if err != nil {
+			return nil, err
+		}
+		collaborators[i] = domain.Collaborator{UserID: userID, Role: c.Role}
+	}
+	return collaborators, nil
+}
+
+// sqlRecurrence is the JSON shape of the recurrence column.
+type sqlRecurrence struct {
+	Rule       string `json:"rule"`
+	TimeZone   string `json:"time_zone"`
+	Start      string `json:"start"`
+	SeriesID   string `json:"series_id"`
+	Occurrence int    `json:"occurrence"`
+	NextTaskID string `json:"next_task_id"`
+}
+
+// marshalRecurrence stores a one-off task's missing recurrence as "".
+func marshalRecurrence(r *domain.Recurrence) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(sqlRecurrence{
+		Rule:       r.Rule,
+		TimeZone:   r.TimeZone,
+		Start:      toSQLTime(r.Start),
+		SeriesID:   toSQLID(r.SeriesID),
+		Occurrence: r.Occurrence,
+		NextTaskID: toSQLID(r.NextTaskID),
+	})
+	return string(data), err
+}
+
+func unmarshalRecurrence(data string) (*domain.Recurrence, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var stored sqlRecurrence
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		return nil, err
+	}
+	r := &domain.Recurrence{Rule: stored.Rule, TimeZone: stored.TimeZone, Occurrence: stored.Occurrence}
+	var err error
+	if r.Start, err = fromSQLTime(stored.Start); err != nil {
+		return nil, err
+	}
+	if r.SeriesID, err = parseSQLID(stored.SeriesID); err != nil {
+		return nil, err
+	}
+	if r.NextTaskID, err = parseSQLID(stored.NextTaskID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// scanTask reads one tasks row into a domain.Task.
+func scanTask(row interface{ Scan(...interface{}) error }) (*domain.Task, error) {
+	var task domain.Task
+	var id, userID, dueDate, createdAt, statusHistory, parentID, blockedBy, assigneeID, collaborators, recurrence, tags, projectID, archivedAt, deletedAt, deletedBy string
+	if err := row.Scan(&id, &task.Title, &task.Description, &dueDate, &task.Status, &userID, &createdAt, &statusHistory,
+		&parentID, &blockedBy, &assigneeID, &collaborators, &recurrence, &tags, &projectID, &archivedAt, &task.ExternalID, &task.Version, &deletedAt, &deletedBy); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if task.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if task.UserID, err = parseSQLID(userID); err != nil {
+		return nil, err
+	}
+	if task.Duedate, err = fromSQLTime(dueDate); err != nil {
+		return nil, err
+	}
+	if task.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	if task.StatusHistory, err = unmarshalStatusHistory(statusHistory); err != nil {
+		return nil, err
+	}
+	if task.ParentID, err = parseSQLID(parentID); err != nil {
+		return nil, err
+	}
+	if task.BlockedBy, err = unmarshalIDs(blockedBy); err != nil {
+		return nil, err
+	}
+	if task.AssigneeID, err = parseSQLID(assigneeID); err != nil {
+		return nil, err
+	}
+	if task.Collaborators, err = unmarshalCollaborators(collaborators); err != nil {
+		return nil, err
+	}
+	if task.Recurrence, err = unmarshalRecurrence(recurrence); err != nil {
+		return nil, err
+	}
+	if task.Tags, err = unmarshalTags(tags); err != nil {
+		return nil, err
+	}
+	if task.ProjectID, err = parseSQLID(projectID); err != nil {
+		return nil, err
+	}
+	if task.ArchivedAt, err = fromSQLOptionalTime(archivedAt); err != nil {
+		return nil, err
+	}
+	if task.DeletedAt, err = fromSQLOptionalTime(deletedAt); err != nil {
+		return nil, err
+	}
+	if task.DeletedBy, err = parseSQLID(deletedBy); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// queryTasks runs a SELECT over the tasks table and scans every row.
+func (r *sqliteTaskRepository) queryTasks(ctx context.Context, query string, args ...interface{}) ([]domain.Task, error) {
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []domain.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, rows.Err()
+}
+
+func (r *sqliteTaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	id := task.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	statusHistory, err := marshalStatusHistory(task.StatusHistory)
+	if err != nil {
+		return err
+	}
+	blockedBy, err := marshalIDs(task.BlockedBy)
+	if err != nil {
+		return err
+	}
+	collaborators, err := marshalCollaborators(task.Collaborators)
+	if err != nil {
+		return err
+	}
+	recurrence, err := marshalRecurrence(task.Recurrence)
+	if err != nil {
+		return err
+	}
+	tags, err := marshalTags(task.Tags)
+	if err != nil {
+		return err
+	}
+	version := task.Version
+	if version == 0 {
+		version = 1
+	}
+	_, err = sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO tasks (`+taskColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id.Hex(), task.Title, task.Description, toSQLTime(task.Duedate), task.Status, task.UserID.Hex(), toSQLTime(task.CreatedAt), statusHistory,
+		toSQLID(task.ParentID), blockedBy, toSQLID(task.AssigneeID), collaborators, recurrence, tags,
+		toSQLID(task.ProjectID), toSQLOptionalTime(task.ArchivedAt), task.ExternalID, version, toSQLOptionalTime(task.DeletedAt), toSQLID(task.DeletedBy))
+	if err != nil {
+		return sqlError(err)
+	}
+	task.ID = id
+	task.Version = version
+	return nil
+}
+
+func (r *sqliteTaskRepository) GetAllByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error) {
+	return r.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks WHERE user_id = ? AND deleted_at = ''`, userID.Hex())
+}
+
+func (r *sqliteTaskRepository) ListTasks(ctx context.Context, q TaskQuery) ([]domain.Task, string, error) {
+	const sharedWith = `EXISTS (SELECT 1 FROM json_each(tasks.collaborators) WHERE json_extract(json_each.value, '$.user_id') = ?)`
+	var where []string
+	var args []interface{}
+	switch q.Scope {
+	case ScopeOwned:
+		where = append(where, "user_id = ?")
+		args = append(args, q.UserID.Hex())
+	case ScopeAssigned:
+		where = append(where, "assignee_id = ?")
+		args = append(args, q.UserID.Hex())
+	case ScopeShared:
+		where = append(where, sharedWith)
+		args = append(args, q.UserID.Hex())
+	default:
+		if q.ProjectID.IsZero() {
+			where = append(where, "(user_id = ? OR assignee_id = ? OR "+sharedWith+")")
+			args = append(args, q.UserID.Hex(), q.UserID.Hex(), q.UserID.Hex())
+		}
+	}
+
+	where = append(where, "deleted_at = ''")
+	if !q.ProjectID.IsZero() {
+		where = append(where, "project_id = ?")
+		args = append(args, q.ProjectID.Hex())
+	}
+	if !q.IncludeArchived {
+		where = append(where, "archived_at = ''")
+	}
+	if len(q.Statuses) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(q.Statuses)), ", ")
+		where = append(where, "status IN ("+placeholders+")")
+		for _, status := range q.Statuses {
+			args = append(args, status)
+		}
+	}
+	if len(q.ExcludeStatuses) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(q.ExcludeStatuses)), ", ")
+		where = append(where, "status NOT IN ("+placeholders+")")
+		for _, status := range q.ExcludeStatuses {
+			args = append(args, status)
+		}
+	}
+	if !q.DueFrom.IsZero() {
+		where = append(where, "due_date >= ?")
+		args = append(args, toSQLTime(q.DueFrom))
+	}
+	if !q.DueTo.IsZero() {
+		where = append(where, "due_date <= ?")
+		args = append(args, toSQLTime(q.DueTo))
+	}
+	if !q.DueBefore.IsZero() {
+		where = append(where, "due_date < ?")
+		args = append(args, toSQLTime(q.DueBefore))
+	}
+	if !q.CreatedAfter.IsZero() {
+		where = append(where, "created_at > ?")
+		args = append(args, toSQLTime(q.CreatedAfter))
+	}
+	if len(q.AnyTags) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(q.AnyTags)), ", ")
+		where = append(where, "EXISTS (SELECT 1 FROM json_each(tasks.tags) WHERE json_each.value IN ("+placeholders+"))")
+		for _, tag := range q.AnyTags {
+			args = append(args, tag)
+		}
+	}
+	for _, tag := range q.AllTags {
+		where = append(where, "EXISTS (SELECT 1 FROM json_each(tasks.tags) WHERE json_each.value = ?)")
+		args = append(args, tag)
+	}
+	for _, tag := range q.ExcludeTags {
+		where = append(where, "NOT EXISTS (SELECT 1 FROM json_each(tasks.tags) WHERE json_each.value = ?)")
+		args = append(args, tag)
+	}
+	// LIKE ignores case for ASCII letters only.
+	for _, term := range q.Text {
+		where = append(where, `(title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`)
+		args = append(args, likePattern(term), likePattern(term))
+	}
+	for _, term := range q.ExcludeText {
+		where = append(where, `title NOT LIKE ? ESCAPE '\' AND description NOT LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(term), likePattern(term))
+	}
+
+	// SortBy has been validated, so it is safe to use as a column name.
+	column := string(q.SortBy)
+	direction, cmp := "ASC", ">"
+	if q.Descending {
+		direction, cmp = "DESC", "<"
+	}
+
+	if q.Cursor != "" {
+		cur, lastID, err := decodeCursor(q)
+		if err != nil {
+			return nil, "", err
+		}
+		lastValue := cur.Value
+		if q.SortBy == SortByDueDate {
+			due, _ := time.Parse(time.RFC3339Nano, cur.Value)
+			lastValue = toSQLTime(due)
+		}
+		where = append(where, "("+column+" "+cmp+" ? OR ("+column+" = ? AND id "+cmp+" ?))")
+		args = append(args, lastValue, lastValue, lastID.Hex())
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE ` + strings.Join(where, " AND ") +
+		` ORDER BY ` + column + ` ` + direction + `, id ` + direction
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit+1)
+	}
+
+	tasks, err := r.queryTasks(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	return pageTasks(tasks, q)
+}
+
+// likePattern matches term anywhere in a column, escaping LIKE wildcards.
+func likePattern(term string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+	return "%" + escaped + "%"
+}
+
+func (r *sqliteTaskRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ? AND deleted_at = ''`, id.Hex())
+	return scanTask(row)
+}
+
+func (r *sqliteTaskRepository) GetTrashedByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = ? AND deleted_at != ''`, id.Hex())
+	return scanTask(row)
+}
+
+func (r *sqliteTaskRepository) ListTrash(ctx context.Context, q TrashQuery) ([]domain.Task, error) {
+	where := []string{"deleted_at != ''"}
+	var args []interface{}
+	if !q.UserID.IsZero() {
+		where = append(where, "user_id = ?")
+		args = append(args, q.UserID.Hex())
+	}
+	if !q.DeletedBefore.IsZero() {
+		where = append(where, "deleted_at < ?")
+		args = append(args, toSQLTime(q.DeletedBefore))
+	}
+	return r.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks WHERE `+strings.Join(where, " AND ")+
+		` ORDER BY deleted_at DESC, id DESC`, args...)
+}
+
+func (r *sqliteTaskRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Task, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id.Hex()
+	}
+	return r.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id IN (`+placeholders+`) AND deleted_at = ''`, args...)
+}
+
+func (r *sqliteTaskRepository) GetByExternalID(ctx context.Context, userID primitive.ObjectID, externalID string) (*domain.Task, error) {
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks WHERE user_id = ? AND external_id = ?`, userID.Hex(), externalID)
+	return scanTask(row)
+}
+
+func (r *sqliteTaskRepository) ListSubtasks(ctx context.Context, parentID primitive.ObjectID) ([]domain.Task, error) {
+	return r.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks WHERE parent_id = ? AND deleted_at = '' ORDER BY created_at, id`, parentID.Hex())
+}
+
+func (r *sqliteTaskRepository) ListBlockedTasks(ctx context.Context, blockerID primitive.ObjectID) ([]domain.Task, error) {
+	return r.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks
+		WHERE EXISTS (SELECT 1 FROM json_each(tasks.blocked_by) WHERE json_each.value = ?) AND deleted_at = ''
+		ORDER BY created_at, id`, blockerID.Hex())
+}
+
+func (r *sqliteTaskRepository) ListDueBetween(ctx context.Context, from, to time.Time) ([]domain.Task, error) {
+	return r.queryTasks(ctx, `SELECT `+taskColumns+` FROM tasks WHERE due_date >= ? AND due_date <= ? AND deleted_at = '' AND archived_at = '' ORDER BY due_date, id`,
+		toSQLTime(from), toSQLTime(to))
+}
+
+func (r *sqliteTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	statusHistory, err := marshalStatusHistory(task.StatusHistory)
+	if err != nil {
+		return err
+	}
+	blockedBy, err := marshalIDs(task.BlockedBy)
+	if err != nil {
+		return err
+	}
+	collaborators, err := marshalCollaborators(task.Collaborators)
+	if err != nil {
+		return err
+	}
+	recurrence, err := marshalRecurrence(task.Recurrence)
+	if err != nil {
+		return err
+	}
+	tags, err := marshalTags(task.Tags)
+	if err != nil {
+		return err
+	}
+	assignments := []string{"title = ?", "description = ?", "due_date = ?", "status = ?", "user_id = ?", "created_at = ?", "status_history = ?",
+		"parent_id = ?", "blocked_by = ?", "assignee_id = ?", "collaborators = ?", "recurrence = ?", "tags = ?", "project_id = ?", "archived_at = ?", "external_id = ?", "deleted_at = ?", "deleted_by = ?"}
+	return r.updateVersioned(ctx, task, assignments,
+		task.Title, task.Description, toSQLTime(task.Duedate), task.Status, task.UserID.Hex(), toSQLTime(task.CreatedAt), statusHistory,
+		toSQLID(task.ParentID), blockedBy, toSQLID(task.AssigneeID), collaborators, recurrence, tags,
+		toSQLID(task.ProjectID), toSQLOptionalTime(task.ArchivedAt), task.ExternalID, toSQLOptionalTime(task.DeletedAt), toSQLID(task.DeletedBy))
+}
+
+func (r *sqliteTaskRepository) UpdateFields(ctx context.Context, task *domain.Task, fields []TaskField) error {
+	var assignments []string
+	var args []interface{}
+	set := func(column string, value interface{}) {
+		assignments = append(assignments, column+" = ?")
+		args = append(args, value)
+	}
+	for _, field := range fields {
+		switch field {
+		case TaskFieldTitle:
+			set("title", task.Title)
+		case TaskFieldDescription:
+			set("description", task.Description)
+		case TaskFieldDueDate:
+			set("due_date", toSQLTime(task.Duedate))
+		case TaskFieldStatus:
+			statusHistory, err := marshalStatusHistory(task.StatusHistory)
+			if err != nil {
+				return err
+			}
+			set("status", task.Status)
+			set("status_history", statusHistory)
+		case TaskFieldParent:
+			set("parent_id", toSQLID(task.ParentID))
+		case TaskFieldBlockedBy:
+			blockedBy, err := marshalIDs(task.BlockedBy)
+			if err != nil {
+				return err
+			}
+			set("blocked_by", blockedBy)
+		case TaskFieldAssignee:
+			set("assignee_id", toSQLID(task.AssigneeID))
+		case TaskFieldCollaborators:
+			collaborators, err := marshalCollaborators(task.Collaborators)
+			if err != nil {
+				return err
+			}
+			set("collaborators", collaborators)
+		case TaskFieldRecurrence:
+			recurrence, err := marshalRecurrence(task.Recurrence)
+			if err != nil {
+				return err
+			}
+			set("recurrence", recurrence)
+		case TaskFieldTags:
+			tags, err := marshalTags(task.Tags)
+			if err != nil {
+				return err
+			}
+			set("tags", tags)
+		case TaskFieldDeletion:
+			set("deleted_at", toSQLOptionalTime(task.DeletedAt))
+			set("deleted_by", toSQLID(task.DeletedBy))
+		}
+	}
+	return r.updateVersioned(ctx, task, assignments, args...)
+}
+
+// updateVersioned applies the column assignments and bumps the version if
+// the task is still at task.Version, and then increments task.Version.
+func (r *sqliteTaskRepository) updateVersioned(ctx context.Context, task *domain.Task, assignments []string, args ...interface{}) error {
+	assignments = append(assignments[:len(assignments):len(assignments)], "version = version + 1")
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE tasks SET `+strings.Join(assignments, ", ")+` WHERE id = ? AND version = ?`,
+		append(args, task.ID.Hex(), task.Version)...)
+	if err != nil {
+		return sqlError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		var exists bool
+		if err := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ?)`, task.ID.Hex()).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return sqlError(sql.ErrNoRows)
+		}
+		return ErrVersionConflict
+	}
+	task.Version++
+	return nil
+}
+
+func (r *sqliteTaskRepository) RenameTag(ctx context.Context, userID primitive.ObjectID, from, to string) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE tasks
+		SET tags = (SELECT json_group_array(value) FROM (
+				SELECT value FROM json_each(tasks.tags) WHERE value != ?
+				UNION ALL
+				SELECT ? WHERE NOT EXISTS (SELECT 1 FROM json_each(tasks.tags) WHERE json_each.value = ?))),
+			version = version + 1
+		WHERE user_id = ? AND EXISTS (SELECT 1 FROM json_each(tasks.tags) WHERE json_each.value = ?)`,
+		from, to, to, userID.Hex(), from)
+	return err
+}
+
+func (r *sqliteTaskRepository) RemoveTag(ctx context.Context, userID primitive.ObjectID, name string) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE tasks
+		SET tags = (SELECT json_group_array(value) FROM json_each(tasks.tags) WHERE value != ?),
+			version = version + 1
+		WHERE user_id = ? AND EXISTS (SELECT 1 FROM json_each(tasks.tags) WHERE json_each.value = ?)`,
+		name, userID.Hex(), name)
+	return err
+}
+
+func (r *sqliteTaskRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	conn := sqlConn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, `UPDATE tasks SET assignee_id = '', version = version + 1 WHERE assignee_id = ?`,
+		userID.Hex()); err != nil {
+		return err
+	}
+	_, err := conn.ExecContext(ctx, `UPDATE tasks
+		SET collaborators = (SELECT json_group_array(json(value)) FROM json_each(tasks.collaborators)
+				WHERE json_extract(value, '$.user_id') != ?),
+			version = version + 1
+		WHERE EXISTS (SELECT 1 FROM json_each(tasks.collaborators) WHERE json_extract(value, '$.user_id') = ?)`,
+		userID.Hex(), userID.Hex())
+	return err
+}
+
+func (r *sqliteTaskRepository) RemoveTaskLinks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(taskIDs))
+	for i, id := range taskIDs {
+		args[i] = id.Hex()
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(taskIDs)), ", ")
+	conn := sqlConn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, `UPDATE tasks SET parent_id = '', version = version + 1
+		WHERE parent_id IN (`+placeholders+`)`, args...); err != nil {
+		return err
+	}
+	_, err := conn.ExecContext(ctx, `UPDATE tasks
+		SET blocked_by = (SELECT json_group_array(value) FROM json_each(tasks.blocked_by) WHERE value NOT IN (`+placeholders+`)),
+			version = version + 1
+		WHERE EXISTS (SELECT 1 FROM json_each(tasks.blocked_by) WHERE json_each.value IN (`+placeholders+`))`,
+		append(args, args...)...)
+	return err
+}
+
+func (r *sqliteTaskRepository) ArchiveProject(ctx context.Context, projectID primitive.ObjectID, archivedAt time.Time) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE tasks SET archived_at = ?, version = version + 1 WHERE project_id = ?`,
+		toSQLOptionalTime(archivedAt), projectID.Hex())
+	return err
+}
+
+func (r *sqliteTaskRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM tasks WHERE id = ?`, id.Hex())
+	return err
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"path/filepath"
+	"taskmanager/domain"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMigrateSQLite_AppliesEveryVersionOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migrate.db")
+
+	db, err := OpenSQLite(path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations, err := loadMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	var version, applied int
+	err = db.QueryRow(`SELECT MAX(version), COUNT(*) FROM schema_migrations`).Scan(&version, &applied)
+	assert.NoError(t, err)
+	assert.Equal(t, migrations[len(migrations)-1].version, version)
+	assert.Equal(t, len(migrations), applied)
+
+	// Running again on an up-to-date schema must be a no-op.
+	assert.NoError(t, MigrateSQLite(context.Background(), db))
+	err = db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied)
+	assert.NoError(t, err)
+	assert.Equal(t, len(migrations), applied)
+}
+
+func TestLoadMigrations_SortedByVersion(t *testing.T) {
+	migrations, err := loadMigrations()
+	require.NoError(t, err)
+
+	for i := 1; i < len(migrations); i++ {
+		assert.Less(t, migrations[i-1].version, migrations[i].version, "migration versions must be unique and increasing")
+	}
+}
+
+func TestSQLiteAuditLog_IsAppendOnly(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "audit.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`INSERT INTO audit_log (id, entity_type, entity_id, action, actor_id, timestamp) VALUES ('a', 'task', 'b', 'create', 'c', 'd')`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`UPDATE audit_log SET action = 'delete'`)
+	assert.ErrorContains(t, err, "append-only")
+	_, err = db.Exec(`DELETE FROM audit_log`)
+	assert.ErrorContains(t, err, "append-only")
+}
+
+func TestMigrateSQLite_BackfillsUnversionedTasks(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "version.db"))
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewSQLiteTaskRepository(db)
+	task := &domain.Task{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Title: "Legacy", Status: "pending"}
+	require.NoError(t, repo.Create(context.Background(), task))
+
+	// Put the task back the way it was saved before versioning, and replay
+	// the backfill and the migrations after it.
+	_, err = db.Exec(`UPDATE tasks SET version = 0`)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM schema_migrations WHERE version >= 20`)
+	require.NoError(t, err)
+	require.NoError(t, MigrateSQLite(context.Background(), db))
+
+	stored, err := repo.GetByID(context.Background(), task.ID)
+	require.NoError(t, err)
+	backfilled := stored.Version
+	stored.Title = "Legacy, edited"
+	updateErr := repo.Update(context.Background(), stored)
+
+	// --- ASSERT ---
+	assert.Equal(t, int64(1), backfilled)
+	assert.NoError(t, updateErr)
+	assert.Equal(t, int64(2), stored.Version)
+}
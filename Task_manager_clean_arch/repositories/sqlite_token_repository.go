@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteTokenRepository stores refresh tokens, revoked access token IDs and
+// password reset tokens.
+type sqliteTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteTokenRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteTokenRepository(db *sql.DB) ITokenRepository {
+	return &sqliteTokenRepository{db: db}
+}
+
+const refreshTokenColumns = `id, user_id, family_id, token_hash, access_token_id, access_expires_at, expires_at, created_at, used_at, revoked`
+
+// scanRefreshToken reads one refresh_tokens row into a domain.RefreshToken.
+func scanRefreshToken(row interface{ Scan(...interface{}) error }) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	var id, userID, familyID, accessExpiresAt, expiresAt, createdAt string
+	var usedAt sql.NullString
+	err := row.Scan(&id, &userID, &familyID, &token.TokenHash, &token.AccessTokenID,
+		&accessExpiresAt, &expiresAt, &createdAt, &usedAt, &token.Revoked)
+	if err != nil {
+		return nil, sqlError(err)
+	}
+	if token.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if token.UserID, err = parseSQLID(userID); err != nil {
+		return nil, err
+	}
+	if token.FamilyID, err = parseSQLID(familyID); err != nil {
+		return nil, err
+	}
+	if token.AccessExpiresAt, err = fromSQLTime(accessExpiresAt); err != nil {
+		return nil, err
+	}
+	if token.ExpiresAt, err = fromSQLTime(expiresAt); err != nil {
+		return nil, err
+	}
+	if token.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		if token.UsedAt, err = fromSQLTime(usedAt.String); err != nil {
+			return nil, err
+		}
+	}
+	return &token, nil
+}
+
+func (r *sqliteTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	id := token.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	var usedAt sql.NullString
+	if !token.UsedAt.IsZero() {
+		usedAt = sql.NullString{String: toSQLTime(token.UsedAt), Valid: true}
+	}
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO refresh_tokens (`+refreshTokenColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		id.Hex(), token.UserID.Hex(), token.FamilyID.Hex(), token.TokenHash, token.AccessTokenID,
+		toSQLTime(token.AccessExpiresAt), toSQLTime(token.ExpiresAt), toSQLTime(token.CreatedAt), usedAt, token.Revoked)
+	if err != nil {
+		return sqlError(err)
+	}
+	token.ID = id
+	return nil
+}
+
+func (r *sqliteTokenRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = ?`, hash)
+	return scanRefreshToken(row)
+}
+
+func (r *sqliteTokenRepository) MarkRefreshTokenUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time) (bool, error) {
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`,
+		toSQLTime(usedAt), id.Hex())
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (r *sqliteTokenRepository) FindRefreshTokenFamily(ctx context.Context, familyID primitive.ObjectID) ([]domain.RefreshToken, error) {
+	return r.findRefreshTokens(ctx, `family_id = ?`, familyID.Hex())
+}
+
+func (r *sqliteTokenRepository) FindRefreshTokensByUser(ctx context.Context, userID primitive.ObjectID) ([]domain.RefreshToken, error) {
+	return r.findRefreshTokens(ctx, `user_id = ?`, userID.Hex())
+}
+
+func (r *sqliteTokenRepository) findRefreshTokens(ctx context.Context, where string, args ...interface{}) ([]domain.RefreshToken, error) {
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []domain.RefreshToken
+	for rows.Next() {
+		token, err := scanRefreshToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *sqliteTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE refresh_tokens SET revoked = 1 WHERE family_id = ?`, familyID.Hex())
+	return err
+}
+
+func (r *sqliteTokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	// Expired entries are swept on every revocation; nothing reads them again.
+	if _, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < ?`, toSQLTime(time.Now())); err != nil {
+		return err
+	}
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO revoked_tokens (jti, expires_at) VALUES (?, ?)
+		ON CONFLICT (jti) DO UPDATE SET expires_at = excluded.expires_at`, jti, toSQLTime(expiresAt))
+	return err
+}
+
+func (r *sqliteTokenRepository) ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	if _, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at < ?`, toSQLTime(time.Now())); err != nil {
+		return false, err
+	}
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO revoked_tokens (jti, expires_at) VALUES (?, ?)
+		ON CONFLICT (jti) DO NOTHING`, jti, toSQLTime(expiresAt))
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	return inserted == 1, err
+}
+
+func (r *sqliteTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var exists bool
+	err := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = ?)`, jti).Scan(&exists)
+	return exists, err
+}
+
+const passwordResetTokenColumns = `id, user_id, token_hash, expires_at, created_at, used_at`
+
+// scanPasswordResetToken reads one password_reset_tokens row into a
+// domain.PasswordResetToken.
+func scanPasswordResetToken(row interface{ Scan(...interface{}) error }) (*domain.PasswordResetToken, error) {
+	var token domain.PasswordResetToken
+	var id, userID, expiresAt, createdAt string
+	var usedAt sql.NullString
+	err := row.Scan(&id, &userID, &token.TokenHash, &expiresAt, &createdAt, &usedAt)
+	if err != nil {
+		return nil, sqlError(err)
+	}
+	if token.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if token.UserID, err = parseSQLID(userID); err != nil {
+		return nil, err
+	}
+	if token.ExpiresAt, err = fromSQLTime(expiresAt); err != nil {
+		return nil, err
+	}
+	if token.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		if token.UsedAt, err = fromSQLTime(usedAt.String); err != nil {
+			return nil, err
+		}
+	}
+	return &token, nil
+}
+
+func (r *sqliteTokenRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	id := token.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	var usedAt sql.NullString
+	if !token.UsedAt.IsZero() {
+		usedAt = sql.NullString{String: toSQLTime(token.UsedAt), Valid: true}
+	}
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO password_reset_tokens (`+passwordResetTokenColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		id.Hex(), token.UserID.Hex(), token.TokenHash, toSQLTime(token.ExpiresAt), toSQLTime(token.CreatedAt), usedAt)
+	if err != nil {
+		return sqlError(err)
+	}
+	token.ID = id
+	return nil
+}
+
+func (r *sqliteTokenRepository) FindPasswordResetTokenByHash(ctx context.Context, hash string) (*domain.PasswordResetToken, error) {
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+passwordResetTokenColumns+` FROM password_reset_tokens WHERE token_hash = ?`, hash)
+	return scanPasswordResetToken(row)
+}
+
+func (r *sqliteTokenRepository) MarkPasswordResetTokenUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time) (bool, error) {
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`,
+		toSQLTime(usedAt), id.Hex())
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+func (r *sqliteTokenRepository) InvalidatePasswordResetTokens(ctx context.Context, userID primitive.ObjectID, usedAt time.Time) error {
+	// Expired tokens are swept here too; Mongo drops them with a TTL index.
+	if _, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < ?`, toSQLTime(time.Now())); err != nil {
+		return err
+	}
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL`,
+		toSQLTime(usedAt), userID.Hex())
+	return err
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+)
+
+// sqlExecutor is what the SQLite repositories need from a connection; both
+// *sql.DB and *sql.Tx provide it.
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type sqlTxKey struct{}
+
+// sqlConn returns the transaction of the unit of work ctx belongs to, or db
+// outside of one.
+func sqlConn(ctx context.Context, db *sql.DB) sqlExecutor {
+	if tx, ok := ctx.Value(sqlTxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// sqliteUnitOfWork runs units of work in a database transaction that the
+// SQLite repositories pick up from the context.
+type sqliteUnitOfWork struct {
+	db *sql.DB
+}
+
+// NewSQLiteUnitOfWork is the constructor. db must come from OpenSQLite.
+func NewSQLiteUnitOfWork(db *sql.DB) IUnitOfWork {
+	return &sqliteUnitOfWork{db: db}
+}
+
+func (u *sqliteUnitOfWork) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	// A unit of work inside another joins its transaction.
+	if _, ok := ctx.Value(sqlTxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolling back after a commit does nothing.
+	defer tx.Rollback()
+	if err := fn(context.WithValue(ctx, sqlTxKey{}, tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,214 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteUserRepository stores users in the users table.
+type sqliteUserRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteUserRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteUserRepository(db *sql.DB) IUserRepository {
+	return &sqliteUserRepository{db: db}
+}
+
+const userColumns = `id, username, password, roles, reminder_offsets, disabled_at`
+
+// marshalOffsets stores nil reminder offsets as NULL and any other list,
+// even an empty one, as a JSON array.
+func marshalOffsets(offsets []time.Duration) (sql.NullString, error) {
+	if offsets == nil {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(offsets)
+	return sql.NullString{String: string(data), Valid: true}, err
+}
+
+// scanUser reads one users row into a domain.User.
+func scanUser(row interface{ Scan(...interface{}) error }) (*domain.User, error) {
+	var user domain.User
+	var id, roles, disabledAt string
+	var offsets sql.NullString
+	if err := row.Scan(&id, &user.Username, &user.Password, &roles, &offsets, &disabledAt); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if user.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if user.Roles, err = unmarshalTags(roles); err != nil {
+		return nil, err
+	}
+	if user.DisabledAt, err = fromSQLOptionalTime(disabledAt); err != nil {
+		return nil, err
+	}
+	if offsets.Valid {
+		user.ReminderOffsets = []time.Duration{}
+		if err := json.Unmarshal([]byte(offsets.String), &user.ReminderOffsets); err != nil {
+			return nil, err
+		}
+	}
+	return &user, nil
+}
+
+func (r *sqliteUserRepository) Create(ctx context.Context, user *domain.User) error {
+	id := user.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	offsets, err := marshalOffsets(user.ReminderOffsets)
+	if err != nil {
+		return err
+	}
+	roles, err := marshalTags(user.Roles)
+	if err != nil {
+		return err
+	}
+	_, err = sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?, ?)`,
+		id.Hex(), user.Username, user.Password, roles, offsets, toSQLOptionalTime(user.DisabledAt))
+	if err != nil {
+		return sqlError(err)
+	}
+	user.ID = id
+	return nil
+}
+
+func (r *sqliteUserRepository) FindByUsername(ctx context.Context, username string) (*domain.User, error) {
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE username = ?`, username)
+	return scanUser(row)
+}
+
+func (r *sqliteUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id.Hex())
+	return scanUser(row)
+}
+
+func (r *sqliteUserRepository) Update(ctx context.Context, user *domain.User) error {
+	offsets, err := marshalOffsets(user.ReminderOffsets)
+	if err != nil {
+		return err
+	}
+	roles, err := marshalTags(user.Roles)
+	if err != nil {
+		return err
+	}
+	_, err = sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE users SET username = ?, password = ?, roles = ?, reminder_offsets = ?, disabled_at = ? WHERE id = ?`,
+		user.Username, user.Password, roles, offsets, toSQLOptionalTime(user.DisabledAt), user.ID.Hex())
+	return sqlError(err)
+}
+
+func (r *sqliteUserRepository) UpdateFields(ctx context.Context, user *domain.User, fields []UserField) error {
+	var assignments []string
+	var args []interface{}
+	set := func(column string, value interface{}) {
+		assignments = append(assignments, column+" = ?")
+		args = append(args, value)
+	}
+	for _, field := range fields {
+		switch field {
+		case UserFieldPassword:
+			set("password", user.Password)
+		case UserFieldRoles:
+			roles, err := marshalTags(user.Roles)
+			if err != nil {
+				return err
+			}
+			set("roles", roles)
+		case UserFieldReminderOffsets:
+			offsets, err := marshalOffsets(user.ReminderOffsets)
+			if err != nil {
+				return err
+			}
+			set("reminder_offsets", offsets)
+		case UserFieldDisabled:
+			set("disabled_at", toSQLOptionalTime(user.DisabledAt))
+		}
+	}
+	if len(assignments) == 0 {
+		return nil
+	}
+	args = append(args, user.ID.Hex())
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE users SET `+strings.Join(assignments, ", ")+` WHERE id = ?`, args...)
+	return sqlError(err)
+}
+
+func (r *sqliteUserRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
+
+func (r *sqliteUserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	var count int64
+	err := sqlConn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM users WHERE EXISTS (SELECT 1 FROM json_each(users.roles) WHERE json_each.value = ?)`, role).Scan(&count)
+	return count, err
+}
+
+func (r *sqliteUserRepository) List(ctx context.Context, q UserQuery) ([]domain.User, string, error) {
+	where := []string{"1 = 1"}
+	var args []interface{}
+
+	// LIKE ignores case for ASCII letters only.
+	if q.Search != "" {
+		where = append(where, `username LIKE ? ESCAPE '\'`)
+		args = append(args, likePattern(q.Search))
+	}
+	if q.Role != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM json_each(users.roles) WHERE json_each.value = ?)")
+		args = append(args, q.Role)
+	}
+	switch q.Status {
+	case UserStatusActive:
+		where = append(where, "disabled_at = ''")
+	case UserStatusDisabled:
+		where = append(where, "disabled_at != ''")
+	}
+	if q.Cursor != "" {
+		last, err := decodeUserCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		where = append(where, "username > ?")
+		args = append(args, last)
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + strings.Join(where, " AND ") + ` ORDER BY username`
+	if q.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, q.Limit+1)
+	}
+
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	return pageUsers(users, q)
+}
+
+func (r *sqliteUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id.Hex())
+	return err
+}
@@ -0,0 +1,294 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sqliteWebhookRepository stores webhooks in the webhooks table and their
+// deliveries in webhook_deliveries.
+type sqliteWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteWebhookRepository is the constructor. db must come from OpenSQLite.
+func NewSQLiteWebhookRepository(db *sql.DB) IWebhookRepository {
+	return &sqliteWebhookRepository{db: db}
+}
+
+const webhookColumns = `id, user_id, url, secret, events, all_tasks, failed_deliveries, disabled_at, created_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event, payload, status, attempts, next_attempt_at, last_attempt_at, response_status, error, created_at`
+
+// scanWebhook reads one webhooks row into a domain.Webhook.
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	var id, userID, events, disabledAt, createdAt string
+	if err := row.Scan(&id, &userID, &webhook.URL, &webhook.Secret, &events, &webhook.AllTasks,
+		&webhook.FailedDeliveries, &disabledAt, &createdAt); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if webhook.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if webhook.UserID, err = parseSQLID(userID); err != nil {
+		return nil, err
+	}
+	if webhook.Events, err = unmarshalTags(events); err != nil {
+		return nil, err
+	}
+	if webhook.DisabledAt, err = fromSQLOptionalTime(disabledAt); err != nil {
+		return nil, err
+	}
+	if webhook.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// scanWebhookDelivery reads one webhook_deliveries row into a domain.WebhookDelivery.
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var id, webhookID, eventID, nextAttemptAt, lastAttemptAt, createdAt string
+	if err := row.Scan(&id, &webhookID, &eventID, &delivery.Event, &delivery.Payload, &delivery.Status, &delivery.Attempts,
+		&nextAttemptAt, &lastAttemptAt, &delivery.ResponseStatus, &delivery.Error, &createdAt); err != nil {
+		return nil, sqlError(err)
+	}
+	var err error
+	if delivery.ID, err = parseSQLID(id); err != nil {
+		return nil, err
+	}
+	if delivery.WebhookID, err = parseSQLID(webhookID); err != nil {
+		return nil, err
+	}
+	if delivery.EventID, err = parseSQLID(eventID); err != nil {
+		return nil, err
+	}
+	if delivery.NextAttemptAt, err = fromSQLTime(nextAttemptAt); err != nil {
+		return nil, err
+	}
+	if delivery.LastAttemptAt, err = fromSQLOptionalTime(lastAttemptAt); err != nil {
+		return nil, err
+	}
+	if delivery.CreatedAt, err = fromSQLTime(createdAt); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// webhookValues are the column values of a webhook after its ID.
+func webhookValues(webhook *domain.Webhook) ([]interface{}, error) {
+	events, err := marshalTags(webhook.Events)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{webhook.UserID.Hex(), webhook.URL, webhook.Secret, events, webhook.AllTasks,
+		webhook.FailedDeliveries, toSQLOptionalTime(webhook.DisabledAt), toSQLTime(webhook.CreatedAt)}, nil
+}
+
+// webhookDeliveryValues are the column values of a delivery after its ID.
+func webhookDeliveryValues(delivery *domain.WebhookDelivery) []interface{} {
+	return []interface{}{delivery.WebhookID.Hex(), delivery.EventID.Hex(), delivery.Event, delivery.Payload, delivery.Status,
+		delivery.Attempts, toSQLTime(delivery.NextAttemptAt), toSQLOptionalTime(delivery.LastAttemptAt),
+		delivery.ResponseStatus, delivery.Error, toSQLTime(delivery.CreatedAt)}
+}
+
+func (r *sqliteWebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	id := webhook.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	values, err := webhookValues(webhook)
+	if err != nil {
+		return err
+	}
+	_, err = sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO webhooks (`+webhookColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		append([]interface{}{id.Hex()}, values...)...)
+	if err != nil {
+		return sqlError(err)
+	}
+	webhook.ID = id
+	return nil
+}
+
+func (r *sqliteWebhookRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error) {
+	return scanWebhook(sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id.Hex()))
+}
+
+func (r *sqliteWebhookRepository) queryWebhooks(ctx context.Context, query string, args ...interface{}) ([]domain.Webhook, error) {
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []domain.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *sqliteWebhookRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Webhook, error) {
+	return r.queryWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE user_id = ? ORDER BY created_at, id`, userID.Hex())
+}
+
+func (r *sqliteWebhookRepository) ListEnabledFor(ctx context.Context, userIDs []primitive.ObjectID) ([]domain.Webhook, error) {
+	audience := "all_tasks = 1"
+	args := []interface{}{}
+	if len(userIDs) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(userIDs)), ", ")
+		audience += " OR user_id IN (" + placeholders + ")"
+		for _, id := range userIDs {
+			args = append(args, id.Hex())
+		}
+	}
+	return r.queryWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE disabled_at = '' AND (`+audience+`) ORDER BY created_at, id`, args...)
+}
+
+func (r *sqliteWebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	values, err := webhookValues(webhook)
+	if err != nil {
+		return err
+	}
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE webhooks SET user_id = ?, url = ?, secret = ?, events = ?, all_tasks = ?, failed_deliveries = ?, disabled_at = ?, created_at = ? WHERE id = ?`,
+		append(values, webhook.ID.Hex())...)
+	if err != nil {
+		return sqlError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sqlError(sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *sqliteWebhookRepository) RecordDeliveryFailure(ctx context.Context, id primitive.ObjectID, disableAfter int, at time.Time) (*domain.Webhook, error) {
+	// The right-hand sides read the row as it was, so the count and the
+	// decision to disable are taken in one statement.
+	row := sqlConn(ctx, r.db).QueryRowContext(ctx, `UPDATE webhooks SET failed_deliveries = failed_deliveries + 1,
+			disabled_at = CASE WHEN disabled_at = '' AND ? > 0 AND failed_deliveries + 1 >= ? THEN ? ELSE disabled_at END
+		WHERE id = ? RETURNING `+webhookColumns,
+		disableAfter, disableAfter, toSQLTime(at), id.Hex())
+	return scanWebhook(row)
+}
+
+func (r *sqliteWebhookRepository) ResetDeliveryFailures(ctx context.Context, id primitive.ObjectID) error {
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `UPDATE webhooks SET failed_deliveries = 0 WHERE id = ?`, id.Hex())
+	if err != nil {
+		return sqlError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sqlError(sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *sqliteWebhookRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	conn := sqlConn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id.Hex()); err != nil {
+		return err
+	}
+	_, err := conn.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id.Hex())
+	return err
+}
+
+func (r *sqliteWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	id := delivery.ID
+	if id.IsZero() {
+		id = primitive.NewObjectID()
+	}
+	_, err := sqlConn(ctx, r.db).ExecContext(ctx, `INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		append([]interface{}{id.Hex()}, webhookDeliveryValues(delivery)...)...)
+	if err != nil {
+		return sqlError(err)
+	}
+	delivery.ID = id
+	return nil
+}
+
+func (r *sqliteWebhookRepository) GetDelivery(ctx context.Context, id primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	return scanWebhookDelivery(sqlConn(ctx, r.db).QueryRowContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id.Hex()))
+}
+
+func (r *sqliteWebhookRepository) ListDeliveries(ctx context.Context, webhookID primitive.ObjectID, limit int) ([]domain.WebhookDelivery, error) {
+	rows, err := sqlConn(ctx, r.db).QueryContext(ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE webhook_id = ? ORDER BY created_at DESC, id DESC LIMIT ?`, webhookID.Hex(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *sqliteWebhookRepository) ClaimDelivery(ctx context.Context, now, until time.Time, skip []primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	where := "status = ? AND next_attempt_at <= ?"
+	args := []interface{}{toSQLTime(until), domain.WebhookDeliveryPending, toSQLTime(now)}
+	if len(skip) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(skip)), ", ")
+		where += " AND webhook_id NOT IN (" + placeholders + ")"
+		for _, id := range skip {
+			args = append(args, id.Hex())
+		}
+	}
+	// A single statement picks and postpones the delivery, so two workers
+	// cannot claim the same one.
+	return scanWebhookDelivery(sqlConn(ctx, r.db).QueryRowContext(ctx,
+		`UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = (
+			SELECT id FROM webhook_deliveries WHERE `+where+` ORDER BY next_attempt_at, id LIMIT 1
+		) RETURNING `+webhookDeliveryColumns, args...))
+}
+
+func (r *sqliteWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery, claimedUntil time.Time) error {
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx,
+		`UPDATE webhook_deliveries SET webhook_id = ?, event_id = ?, event = ?, payload = ?, status = ?, attempts = ?,
+			next_attempt_at = ?, last_attempt_at = ?, response_status = ?, error = ?, created_at = ? WHERE id = ? AND next_attempt_at = ?`,
+		append(webhookDeliveryValues(delivery), delivery.ID.Hex(), toSQLTime(claimedUntil))...)
+	if err != nil {
+		return sqlError(err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sqlError(sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *sqliteWebhookRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := sqlConn(ctx, r.db).ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE status <> ? AND created_at < ?`,
+		domain.WebhookDeliveryPending, toSQLTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
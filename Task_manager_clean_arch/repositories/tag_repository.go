@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ITagRepository stores the tags users define. Tag names are unique per
+// user: Create and Update fail with a duplicate key error otherwise.
+type ITagRepository interface {
+	Create(ctx context.Context, tag *domain.Tag) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Tag, error)
+	// ListByUserID returns the user's tags ordered by name.
+	ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Tag, error)
+	// Update saves the tag's name and color. It returns mongo.ErrNoDocuments
+	// if the tag does not exist.
+	Update(ctx context.Context, tag *domain.Tag) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// mongoTagRepository is the concrete implementation.
+type mongoTagRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTagRepository is the constructor.
+func NewTagRepository(db *mongo.Database) ITagRepository {
+	collection := db.Collection("tags")
+	_, _ = collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return &mongoTagRepository{collection: collection}
+}
+
+func toBsonTag(tag *domain.Tag) *datamodels.Tag {
+	return &datamodels.Tag{ID: tag.ID, UserID: tag.UserID, Name: tag.Name, Color: tag.Color, CreatedAt: tag.CreatedAt}
+}
+
+func toDomainTag(tag *datamodels.Tag) *domain.Tag {
+	return &domain.Tag{ID: tag.ID, UserID: tag.UserID, Name: tag.Name, Color: tag.Color, CreatedAt: tag.CreatedAt}
+}
+
+func (r *mongoTagRepository) Create(ctx context.Context, tag *domain.Tag) error {
+	result, err := r.collection.InsertOne(ctx, toBsonTag(tag))
+	if err != nil {
+		return err
+	}
+	tag.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoTagRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Tag, error) {
+	var bsonTag datamodels.Tag
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&bsonTag); err != nil {
+		return nil, err
+	}
+	return toDomainTag(&bsonTag), nil
+}
+
+func (r *mongoTagRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Tag, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonTags []datamodels.Tag
+	if err := cursor.All(ctx, &bsonTags); err != nil {
+		return nil, err
+	}
+	tags := make([]domain.Tag, len(bsonTags))
+	for i := range bsonTags {
+		tags[i] = *toDomainTag(&bsonTags[i])
+	}
+	return tags, nil
+}
+
+func (r *mongoTagRepository) Update(ctx context.Context, tag *domain.Tag) error {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": tag.ID},
+		bson.M{"$set": bson.M{"name": tag.Name, "color": tag.Color}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoTagRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TagRepositoryTestSuite exercises an ITagRepository implementation.
+type TagRepositoryTestSuite struct {
+	suite.Suite
+	backend testBackend
+	tagRepo ITagRepository
+}
+
+// SetupTest gives every test an empty repository.
+func (s *TagRepositoryTestSuite) SetupTest() {
+	s.tagRepo = s.backend.open(s.T()).Tags
+}
+
+func TestTagRepository(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			suite.Run(t, &TagRepositoryTestSuite{backend: backend})
+		})
+	}
+}
+
+func (s *TagRepositoryTestSuite) TestNamesAreUniquePerUser() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	infra := &domain.Tag{UserID: alice, Name: "infra", Color: "#00ff00", CreatedAt: now}
+	backend := &domain.Tag{UserID: alice, Name: "backend", Color: "#0000ff", CreatedAt: now}
+	assert.NoError(s.tagRepo.Create(ctx, infra))
+	assert.NoError(s.tagRepo.Create(ctx, backend))
+	assert.False(infra.ID.IsZero())
+	// Another user may reuse the name.
+	assert.NoError(s.tagRepo.Create(ctx, &domain.Tag{UserID: bob, Name: "infra", Color: "#00ff00", CreatedAt: now}))
+
+	err := s.tagRepo.Create(ctx, &domain.Tag{UserID: alice, Name: "infra", Color: "#ffffff", CreatedAt: now})
+	assert.True(mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+	infra.Name = "backend"
+	err = s.tagRepo.Update(ctx, infra)
+	assert.True(mongo.IsDuplicateKeyError(err), "expected a duplicate key error, got %v", err)
+
+	infra.Name, infra.Color = "ops", "#123456"
+	assert.NoError(s.tagRepo.Update(ctx, infra))
+	found, err := s.tagRepo.GetByID(ctx, infra.ID)
+	assert.NoError(err)
+	assert.Equal("ops", found.Name)
+	assert.Equal("#123456", found.Color)
+	assert.True(now.Equal(found.CreatedAt))
+
+	tags, err := s.tagRepo.ListByUserID(ctx, alice)
+	assert.NoError(err)
+	if assert.Len(tags, 2) {
+		assert.Equal("backend", tags[0].Name)
+		assert.Equal("ops", tags[1].Name)
+	}
+
+	assert.NoError(s.tagRepo.Delete(ctx, infra.ID))
+	_, err = s.tagRepo.GetByID(ctx, infra.ID)
+	assert.ErrorIs(err, mongo.ErrNoDocuments)
+	assert.ErrorIs(s.tagRepo.Update(ctx, infra), mongo.ErrNoDocuments)
+}
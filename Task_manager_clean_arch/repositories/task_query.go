@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded or
+// does not belong to the requested sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// TrashQuery selects trashed tasks. Zero fields do not filter.
+type TrashQuery struct {
+	// UserID limits the query to the tasks of one owner.
+	UserID primitive.ObjectID
+	// DeletedBefore limits the query to tasks trashed before this time.
+	DeletedBefore time.Time
+}
+
+// TaskSortField names a task field that listings can be ordered by.
+type TaskSortField string
+
+const (
+	SortByDueDate TaskSortField = "due_date"
+	SortByTitle   TaskSortField = "title"
+	SortByStatus  TaskSortField = "status"
+)
+
+// IsValid reports whether the sort field is one the repositories support.
+func (f TaskSortField) IsValid() bool {
+	switch f {
+	case SortByDueDate, SortByTitle, SortByStatus:
+		return true
+	}
+	return false
+}
+
+// TaskScope narrows a listing to one way a user can be related to a task.
+type TaskScope string
+
+const (
+	ScopeAll      TaskScope = ""         // owned, assigned or shared
+	ScopeOwned    TaskScope = "owned"    // created by the user
+	ScopeAssigned TaskScope = "assigned" // assigned to the user
+	ScopeShared   TaskScope = "shared"   // shared with the user as a collaborator
+)
+
+// IsValid reports whether the scope is one the repositories support.
+func (s TaskScope) IsValid() bool {
+	switch s {
+	case ScopeAll, ScopeOwned, ScopeAssigned, ScopeShared:
+		return true
+	}
+	return false
+}
+
+// TaskQuery describes a filtered, sorted page of the tasks a user can see.
+// Zero values mean "no filter" for the optional fields. Tasks of archived
+// projects are left out unless IncludeArchived is set.
+type TaskQuery struct {
+	UserID primitive.ObjectID
+	Scope  TaskScope
+	// ProjectID limits the listing to one project. With ScopeAll it lists
+	// every task in the project, whoever can see it; the caller checks
+	// membership.
+	ProjectID       primitive.ObjectID
+	IncludeArchived bool
+	Statuses        []string
+	DueFrom         time.Time // inclusive
+	DueTo           time.Time // inclusive
+	DueBefore       time.Time // exclusive
+	CreatedAfter    time.Time // exclusive
+	AnyTags         []string  // tasks with at least one of these tags
+	AllTags         []string  // tasks with every one of these tags
+	// Text holds words or phrases that must each appear in the title or the
+	// description, ignoring case; ExcludeText those that must not appear in
+	// either.
+	Text            []string
+	ExcludeText     []string
+	ExcludeStatuses []string
+	ExcludeTags     []string // tasks with none of these tags
+	SortBy          TaskSortField
+	Descending      bool
+	Limit           int
+	Cursor          string
+}
+
+// taskCursor is the decoded form of the opaque cursor handed to clients.
+// It records the sort key and ID of the last task on the previous page.
+type taskCursor struct {
+	SortBy     TaskSortField `json:"s"`
+	Descending bool          `json:"d,omitempty"`
+	Value      string        `json:"v"`
+	ID         string        `json:"id"`
+}
+
+// sortValue returns the string form of the task's value for the sort field.
+func sortValue(task *domain.Task, field TaskSortField) string {
+	switch field {
+	case SortByTitle:
+		return task.Title
+	case SortByStatus:
+		return task.Status
+	default:
+		return task.Duedate.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// encodeCursor builds the cursor that resumes the listing after the given task.
+func encodeCursor(task *domain.Task, q TaskQuery) string {
+	raw, _ := json.Marshal(taskCursor{
+		SortBy:     q.SortBy,
+		Descending: q.Descending,
+		Value:      sortValue(task, q.SortBy),
+		ID:         task.ID.Hex(),
+	})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor parses a cursor and checks that it was issued for the same
+// ordering as the query it is used with.
+func decodeCursor(q TaskQuery) (*taskCursor, primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, primitive.NilObjectID, ErrInvalidCursor
+	}
+	var cur taskCursor
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return nil, primitive.NilObjectID, ErrInvalidCursor
+	}
+	if cur.SortBy != q.SortBy || cur.Descending != q.Descending {
+		return nil, primitive.NilObjectID, ErrInvalidCursor
+	}
+	id, err := primitive.ObjectIDFromHex(cur.ID)
+	if err != nil {
+		return nil, primitive.NilObjectID, ErrInvalidCursor
+	}
+	if cur.SortBy == SortByDueDate {
+		if _, err := time.Parse(time.RFC3339Nano, cur.Value); err != nil {
+			return nil, primitive.NilObjectID, ErrInvalidCursor
+		}
+	}
+	return &cur, id, nil
+}
+
+// pageTasks trims a result fetched with Limit+1 rows down to one page and
+// returns the cursor for the next page, or "" when this is the last one.
+func pageTasks(tasks []domain.Task, q TaskQuery) ([]domain.Task, string, error) {
+	if q.Limit <= 0 || len(tasks) <= q.Limit {
+		return tasks, "", nil
+	}
+	tasks = tasks[:q.Limit]
+	return tasks, encodeCursor(&tasks[len(tasks)-1], q), nil
+}
@@ -2,20 +2,88 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"taskmanager/domain"
 	datamodels "taskmanager/repositories/models" // Aliased import
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// TaskRepository interface definition remains the same.
+// ErrVersionConflict is returned when a task was changed after the version
+// an update was made against.
+var ErrVersionConflict = errors.New("task version conflict")
+
+// TaskField names a part of a task that UpdateFields can write on its own.
+type TaskField string
+
+const (
+	TaskFieldTitle         TaskField = "title"
+	TaskFieldDescription   TaskField = "description"
+	TaskFieldDueDate       TaskField = "due_date"
+	TaskFieldStatus        TaskField = "status" // together with the status history
+	TaskFieldParent        TaskField = "parent_id"
+	TaskFieldBlockedBy     TaskField = "blocked_by"
+	TaskFieldAssignee      TaskField = "assignee_id"
+	TaskFieldCollaborators TaskField = "collaborators"
+	TaskFieldRecurrence    TaskField = "recurrence"
+	TaskFieldTags          TaskField = "tags"
+	TaskFieldDeletion      TaskField = "deleted_at" // together with deleted_by
+)
+
+// TaskRepository interface definition remains the same. Tasks in the trash
+// are invisible to every method except GetTrashedByID and ListTrash.
 type ITaskRepository interface {
+	// Create stores a new task. A task without a version starts at version 1.
 	Create(ctx context.Context, task *domain.Task) error
 	GetAllByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error)
+	ListTasks(ctx context.Context, query TaskQuery) ([]domain.Task, string, error)
 	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error)
+	// GetByIDs returns the tasks that exist among ids, in no particular order.
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Task, error)
+	// ListSubtasks returns the direct subtasks of a task, oldest first.
+	ListSubtasks(ctx context.Context, parentID primitive.ObjectID) ([]domain.Task, error)
+	// ListBlockedTasks returns the tasks blocked by a task, oldest first.
+	ListBlockedTasks(ctx context.Context, blockerID primitive.ObjectID) ([]domain.Task, error)
+	// ListDueBetween returns every user's tasks due in [from, to], soonest
+	// first, leaving out tasks of archived projects.
+	ListDueBetween(ctx context.Context, from, to time.Time) ([]domain.Task, error)
+	// GetTrashedByID returns a task that is in the trash.
+	GetTrashedByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error)
+	// GetByExternalID returns the user's task with this external ID, whether
+	// it is live or in the trash.
+	GetByExternalID(ctx context.Context, userID primitive.ObjectID, externalID string) (*domain.Task, error)
+	// ListTrash returns the trashed tasks matching query, most recently
+	// trashed first.
+	ListTrash(ctx context.Context, query TrashQuery) ([]domain.Task, error)
+	// Update saves the task if the stored copy is still at task.Version and
+	// then increments task.Version. It returns ErrVersionConflict if the task
+	// has changed since, and mongo.ErrNoDocuments if it no longer exists.
 	Update(ctx context.Context, task *domain.Task) error
+	// UpdateFields is Update for only the listed fields; the stored values
+	// of all other fields are left as they are.
+	UpdateFields(ctx context.Context, task *domain.Task, fields []TaskField) error
+	// RenameTag replaces one tag name with another on every task the user
+	// owns, trashed ones included, and bumps the version of each task changed.
+	// A task that already carries the new name keeps it once, where it was;
+	// otherwise the new name goes last.
+	RenameTag(ctx context.Context, userID primitive.ObjectID, from, to string) error
+	// RemoveTag takes a tag name off every task the user owns, like RenameTag.
+	RemoveTag(ctx context.Context, userID primitive.ObjectID, name string) error
+	// RemoveUser unassigns the user from every task and takes them off every
+	// task's collaborators, trashed tasks included, bumping the version of
+	// each task changed.
+	RemoveUser(ctx context.Context, userID primitive.ObjectID) error
+	// RemoveTaskLinks makes the subtasks of the given tasks top-level and
+	// takes the tasks off every blocked_by list, like RemoveUser.
+	RemoveTaskLinks(ctx context.Context, taskIDs []primitive.ObjectID) error
+	// ArchiveProject sets ArchivedAt on every task in the project, trashed
+	// ones included, and bumps their versions. The zero time unarchives them.
+	ArchiveProject(ctx context.Context, projectID primitive.ObjectID, archivedAt time.Time) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
@@ -26,31 +94,200 @@ type mongoTaskRepository struct {
 
 // NewTaskRepository is the constructor.
 func NewTaskRepository(db *mongo.Database) ITaskRepository {
-	return &mongoTaskRepository{collection: db.Collection("tasks")}
+	collection := db.Collection("tasks")
+	// Back every supported listing order with a (user_id, sort key, _id) index
+	indexModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "due_date", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "title", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "status", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "parent_id", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "blocked_by", Value: 1}}},
+		{Keys: bson.D{{Key: "assignee_id", Value: 1}}},
+		{Keys: bson.D{{Key: "collaborators.user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "due_date", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "deleted_at", Value: 1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "tags", Value: 1}}},
+		{Keys: bson.D{{Key: "project_id", Value: 1}, {Key: "due_date", Value: 1}, {Key: "_id", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "external_id", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"external_id": bson.M{"$exists": true}}),
+		},
+	}
+	_, _ = collection.Indexes().CreateMany(context.Background(), indexModels)
+	// Tasks saved before versioning have no version field at all, which no
+	// If-Match header can name. Start them at 1 like every new task.
+	_, _ = collection.UpdateMany(context.Background(),
+		bson.M{"version": bson.M{"$in": bson.A{0, nil}}}, bson.M{"$set": bson.M{"version": 1}})
+	return &mongoTaskRepository{collection: collection}
 }
 
 // toBsonTask converts a Domain Task to a BSON Task model.
 func toBsonTask(task *domain.Task) *datamodels.Task {
 	return &datamodels.Task{
-		ID:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		DueDate:     task.Duedate,
-		Status:      task.Status,
-		UserID:      task.UserID,
+		ID:            task.ID,
+		Title:         task.Title,
+		Description:   task.Description,
+		DueDate:       task.Duedate,
+		Status:        task.Status,
+		UserID:        task.UserID,
+		CreatedAt:     task.CreatedAt,
+		StatusHistory: toBsonStatusHistory(task.StatusHistory),
+		ParentID:      task.ParentID,
+		BlockedBy:     task.BlockedBy,
+		AssigneeID:    task.AssigneeID,
+		Collaborators: toBsonCollaborators(task.Collaborators),
+		Recurrence:    toBsonRecurrence(task.Recurrence),
+		Tags:          toBsonTags(task.Tags),
+		ProjectID:     task.ProjectID,
+		ExternalID:    task.ExternalID,
+		ArchivedAt:    toBsonTime(task.ArchivedAt),
+		Version:       task.Version,
+		DeletedAt:     toBsonTime(task.DeletedAt),
+		DeletedBy:     task.DeletedBy,
 	}
 }
 
+// toBsonTime stores the zero time as null.
+func toBsonTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
 // toDomainTask converts a BSON Task model to a Domain Task.
 func toDomainTask(task *datamodels.Task) *domain.Task {
 	return &domain.Task{
-		ID:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		Duedate:     task.DueDate,
-		Status:      task.Status,
-		UserID:      task.UserID,
+		ID:            task.ID,
+		Title:         task.Title,
+		Description:   task.Description,
+		Duedate:       task.DueDate,
+		Status:        task.Status,
+		UserID:        task.UserID,
+		CreatedAt:     task.CreatedAt,
+		StatusHistory: toDomainStatusHistory(task.StatusHistory),
+		ParentID:      task.ParentID,
+		BlockedBy:     toDomainIDs(task.BlockedBy),
+		AssigneeID:    task.AssigneeID,
+		Collaborators: toDomainCollaborators(task.Collaborators),
+		Recurrence:    toDomainRecurrence(task.Recurrence),
+		Tags:          toDomainTags(task.Tags),
+		ProjectID:     task.ProjectID,
+		ExternalID:    task.ExternalID,
+		ArchivedAt:    toDomainTime(task.ArchivedAt),
+		Version:       task.Version,
+		DeletedAt:     toDomainTime(task.DeletedAt),
+		DeletedBy:     task.DeletedBy,
+	}
+}
+
+// toDomainTime reads a null time as the zero time.
+func toDomainTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// toBsonStatusHistory converts domain status changes to their BSON model.
+func toBsonStatusHistory(history []domain.StatusChange) []datamodels.StatusChange {
+	if len(history) == 0 {
+		return nil
+	}
+	changes := make([]datamodels.StatusChange, len(history))
+	for i, h := range history {
+		changes[i] = datamodels.StatusChange{From: h.From, To: h.To, ChangedBy: h.ChangedBy, ChangedAt: h.ChangedAt}
+	}
+	return changes
+}
+
+// toDomainStatusHistory converts BSON status changes to domain ones.
+func toDomainStatusHistory(history []datamodels.StatusChange) []domain.StatusChange {
+	if len(history) == 0 {
+		return nil
+	}
+	changes := make([]domain.StatusChange, len(history))
+	for i, h := range history {
+		changes[i] = domain.StatusChange{From: h.From, To: h.To, ChangedBy: h.ChangedBy, ChangedAt: h.ChangedAt}
+	}
+	return changes
+}
+
+// toBsonCollaborators converts domain collaborators to their BSON model.
+func toBsonCollaborators(collaborators []domain.Collaborator) []datamodels.Collaborator {
+	converted := make([]datamodels.Collaborator, len(collaborators))
+	for i, c := range collaborators {
+		converted[i] = datamodels.Collaborator{UserID: c.UserID, Role: c.Role}
+	}
+	return converted
+}
+
+// toDomainCollaborators converts BSON collaborators to domain ones.
+func toDomainCollaborators(collaborators []datamodels.Collaborator) []domain.Collaborator {
+	if len(collaborators) == 0 {
+		return nil
+	}
+	converted := make([]domain.Collaborator, len(collaborators))
+	for i, c := range collaborators {
+		converted[i] = domain.Collaborator{UserID: c.UserID, Role: c.Role}
+	}
+	return converted
+}
+
+// toBsonRecurrence converts a domain recurrence to its BSON model.
+func toBsonRecurrence(r *domain.Recurrence) *datamodels.Recurrence {
+	if r == nil {
+		return nil
+	}
+	return &datamodels.Recurrence{
+		Rule:       r.Rule,
+		TimeZone:   r.TimeZone,
+		Start:      r.Start,
+		SeriesID:   r.SeriesID,
+		Occurrence: r.Occurrence,
+		NextTaskID: r.NextTaskID,
+	}
+}
+
+// toDomainRecurrence converts a BSON recurrence to a domain one.
+func toDomainRecurrence(r *datamodels.Recurrence) *domain.Recurrence {
+	if r == nil {
+		return nil
+	}
+	return &domain.Recurrence{
+		Rule:       r.Rule,
+		TimeZone:   r.TimeZone,
+		Start:      r.Start,
+		SeriesID:   r.SeriesID,
+		Occurrence: r.Occurrence,
+		NextTaskID: r.NextTaskID,
+	}
+}
+
+// toDomainIDs normalizes a stored ID list, so an empty list reads back as nil
+// on every backend.
+func toDomainIDs(ids []primitive.ObjectID) []primitive.ObjectID {
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+// toBsonTags always stores a list, so that $in and $all never meet a null.
+func toBsonTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
 	}
+	return tags
+}
+
+// toDomainTags reads an empty tag list back as nil, as the other backends do.
+func toDomainTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
 }
 
 // toDomainTasks converts a slice of BSON Task models to a slice of Domain Tasks.
@@ -64,17 +301,21 @@ func toDomainTasks(tasks []datamodels.Task) []domain.Task {
 
 func (r *mongoTaskRepository) Create(ctx context.Context, task *domain.Task) error {
 	bsonTask := toBsonTask(task)
+	if bsonTask.Version == 0 {
+		bsonTask.Version = 1
+	}
 	result, err := r.collection.InsertOne(ctx, bsonTask)
 	if err != nil {
 		return err
 	}
 	task.ID = result.InsertedID.(primitive.ObjectID)
+	task.Version = bsonTask.Version
 	return nil
 }
 
 func (r *mongoTaskRepository) GetAllByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error) {
 	var bsonTasks []datamodels.Task
-	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID, "deleted_at": nil})
 	if err != nil {
 		return nil, err
 	}
@@ -86,23 +327,301 @@ func (r *mongoTaskRepository) GetAllByUserID(ctx context.Context, userID primiti
 	return toDomainTasks(bsonTasks), nil
 }
 
+func (r *mongoTaskRepository) ListTasks(ctx context.Context, q TaskQuery) ([]domain.Task, string, error) {
+	var filter bson.M
+	switch q.Scope {
+	case ScopeOwned:
+		filter = bson.M{"user_id": q.UserID}
+	case ScopeAssigned:
+		filter = bson.M{"assignee_id": q.UserID}
+	case ScopeShared:
+		filter = bson.M{"collaborators.user_id": q.UserID}
+	default:
+		if !q.ProjectID.IsZero() {
+			filter = bson.M{}
+			break
+		}
+		filter = bson.M{"$or": []bson.M{
+			{"user_id": q.UserID},
+			{"assignee_id": q.UserID},
+			{"collaborators.user_id": q.UserID},
+		}}
+	}
+	filter["deleted_at"] = nil
+	if !q.ProjectID.IsZero() {
+		filter["project_id"] = q.ProjectID
+	}
+	if !q.IncludeArchived {
+		filter["archived_at"] = nil
+	}
+	statuses := bson.M{}
+	if len(q.Statuses) > 0 {
+		statuses["$in"] = q.Statuses
+	}
+	if len(q.ExcludeStatuses) > 0 {
+		statuses["$nin"] = q.ExcludeStatuses
+	}
+	if len(statuses) > 0 {
+		filter["status"] = statuses
+	}
+	dueRange := bson.M{}
+	if !q.DueFrom.IsZero() {
+		dueRange["$gte"] = q.DueFrom
+	}
+	if !q.DueTo.IsZero() {
+		dueRange["$lte"] = q.DueTo
+	}
+	if !q.DueBefore.IsZero() {
+		dueRange["$lt"] = q.DueBefore
+	}
+	if len(dueRange) > 0 {
+		filter["due_date"] = dueRange
+	}
+	if !q.CreatedAfter.IsZero() {
+		filter["created_at"] = bson.M{"$gt": q.CreatedAfter}
+	}
+	tags := bson.M{}
+	if len(q.AnyTags) > 0 {
+		tags["$in"] = q.AnyTags
+	}
+	if len(q.AllTags) > 0 {
+		tags["$all"] = q.AllTags
+	}
+	if len(q.ExcludeTags) > 0 {
+		tags["$nin"] = q.ExcludeTags
+	}
+	if len(tags) > 0 {
+		filter["tags"] = tags
+	}
+	var text []bson.M
+	for _, term := range q.Text {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(term), Options: "i"}
+		text = append(text, bson.M{"$or": []bson.M{{"title": pattern}, {"description": pattern}}})
+	}
+	for _, term := range q.ExcludeText {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(term), Options: "i"}
+		text = append(text, bson.M{"title": bson.M{"$not": pattern}, "description": bson.M{"$not": pattern}})
+	}
+	if len(text) > 0 {
+		filter["$and"] = text
+	}
+
+	sortKey := string(q.SortBy)
+	direction, cmp := 1, "$gt"
+	if q.Descending {
+		direction, cmp = -1, "$lt"
+	}
+
+	if q.Cursor != "" {
+		cur, lastID, err := decodeCursor(q)
+		if err != nil {
+			return nil, "", err
+		}
+		var lastValue interface{} = cur.Value
+		if q.SortBy == SortByDueDate {
+			lastValue, _ = time.Parse(time.RFC3339Nano, cur.Value)
+		}
+		// Resume strictly after the last (sort key, _id) pair of the previous page.
+		after := bson.M{"$or": []bson.M{
+			{sortKey: bson.M{cmp: lastValue}},
+			{sortKey: lastValue, "_id": bson.M{cmp: lastID}},
+		}}
+		filter = bson.M{"$and": []bson.M{filter, after}}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: sortKey, Value: direction}, {Key: "_id", Value: direction}})
+	if q.Limit > 0 {
+		// Fetch one extra task to find out whether another page follows.
+		opts.SetLimit(int64(q.Limit) + 1)
+	}
+
+	var bsonTasks []datamodels.Task
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &bsonTasks); err != nil {
+		return nil, "", err
+	}
+	tasks := toDomainTasks(bsonTasks)
+	return pageTasks(tasks, q)
+}
+
 func (r *mongoTaskRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
 	var bsonTask datamodels.Task
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&bsonTask)
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "deleted_at": nil}).Decode(&bsonTask)
 	if err != nil {
 		return nil, err
 	}
 	return toDomainTask(&bsonTask), nil
 }
 
+func (r *mongoTaskRepository) GetTrashedByID(ctx context.Context, id primitive.ObjectID) (*domain.Task, error) {
+	var bsonTask datamodels.Task
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "deleted_at": bson.M{"$ne": nil}}).Decode(&bsonTask)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainTask(&bsonTask), nil
+}
+
+func (r *mongoTaskRepository) GetByExternalID(ctx context.Context, userID primitive.ObjectID, externalID string) (*domain.Task, error) {
+	var bsonTask datamodels.Task
+	err := r.collection.FindOne(ctx, bson.M{"user_id": userID, "external_id": externalID}).Decode(&bsonTask)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainTask(&bsonTask), nil
+}
+
+func (r *mongoTaskRepository) ListTrash(ctx context.Context, q TrashQuery) ([]domain.Task, error) {
+	deletedAt := bson.M{"$ne": nil}
+	if !q.DeletedBefore.IsZero() {
+		deletedAt["$lt"] = q.DeletedBefore
+	}
+	filter := bson.M{"deleted_at": deletedAt}
+	if !q.UserID.IsZero() {
+		filter["user_id"] = q.UserID
+	}
+	return r.findTasks(ctx, filter, options.Find().SetSort(bson.D{{Key: "deleted_at", Value: -1}, {Key: "_id", Value: -1}}))
+}
+
+func (r *mongoTaskRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Task, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.findTasks(ctx, bson.M{"_id": bson.M{"$in": ids}, "deleted_at": nil})
+}
+
+func (r *mongoTaskRepository) ListSubtasks(ctx context.Context, parentID primitive.ObjectID) ([]domain.Task, error) {
+	return r.findTasks(ctx, bson.M{"parent_id": parentID, "deleted_at": nil}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+func (r *mongoTaskRepository) ListBlockedTasks(ctx context.Context, blockerID primitive.ObjectID) ([]domain.Task, error) {
+	return r.findTasks(ctx, bson.M{"blocked_by": blockerID, "deleted_at": nil}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+func (r *mongoTaskRepository) ListDueBetween(ctx context.Context, from, to time.Time) ([]domain.Task, error) {
+	filter := bson.M{"due_date": bson.M{"$gte": from, "$lte": to}, "deleted_at": nil, "archived_at": nil}
+	return r.findTasks(ctx, filter, options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}, {Key: "_id", Value: 1}}))
+}
+
+// findTasks runs a Find and decodes every matching task.
+func (r *mongoTaskRepository) findTasks(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]domain.Task, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonTasks []datamodels.Task
+	if err = cursor.All(ctx, &bsonTasks); err != nil {
+		return nil, err
+	}
+	return toDomainTasks(bsonTasks), nil
+}
+
 func (r *mongoTaskRepository) Update(ctx context.Context, task *domain.Task) error {
 	bsonTask := toBsonTask(task)
-	filter := bson.M{"_id": bsonTask.ID}
-	update := bson.M{"$set": bsonTask}
-	_, err := r.collection.UpdateOne(ctx, filter, update)
+	bsonTask.Version = task.Version + 1
+	return r.updateVersioned(ctx, task, bsonTask)
+}
+
+func (r *mongoTaskRepository) UpdateFields(ctx context.Context, task *domain.Task, fields []TaskField) error {
+	// Render the whole task with its bson tags, then keep the chosen keys.
+	data, err := bson.Marshal(toBsonTask(task))
+	if err != nil {
+		return err
+	}
+	var document bson.M
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	set := bson.M{"version": task.Version + 1}
+	for _, field := range fields {
+		set[string(field)] = document[string(field)]
+		switch field {
+		case TaskFieldStatus:
+			set["status_history"] = document["status_history"]
+		case TaskFieldDeletion:
+			set["deleted_by"] = document["deleted_by"]
+		}
+	}
+	return r.updateVersioned(ctx, task, set)
+}
+
+func (r *mongoTaskRepository) RenameTag(ctx context.Context, userID primitive.ObjectID, from, to string) error {
+	// One update cannot both add to and pull from tags, so the version is
+	// bumped once, by the second.
+	filter := bson.M{"user_id": userID, "tags": from}
+	if _, err := r.collection.UpdateMany(ctx, filter, bson.M{"$addToSet": bson.M{"tags": to}}); err != nil {
+		return err
+	}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$pull": bson.M{"tags": from}, "$inc": bson.M{"version": 1}})
+	return err
+}
+
+func (r *mongoTaskRepository) RemoveTag(ctx context.Context, userID primitive.ObjectID, name string) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"user_id": userID, "tags": name},
+		bson.M{"$pull": bson.M{"tags": name}, "$inc": bson.M{"version": 1}})
+	return err
+}
+
+func (r *mongoTaskRepository) RemoveUser(ctx context.Context, userID primitive.ObjectID) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"assignee_id": userID},
+		bson.M{"$set": bson.M{"assignee_id": primitive.NilObjectID}, "$inc": bson.M{"version": 1}})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.UpdateMany(ctx, bson.M{"collaborators.user_id": userID},
+		bson.M{"$pull": bson.M{"collaborators": bson.M{"user_id": userID}}, "$inc": bson.M{"version": 1}})
+	return err
+}
+
+func (r *mongoTaskRepository) RemoveTaskLinks(ctx context.Context, taskIDs []primitive.ObjectID) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	_, err := r.collection.UpdateMany(ctx, bson.M{"parent_id": bson.M{"$in": taskIDs}},
+		bson.M{"$set": bson.M{"parent_id": primitive.NilObjectID}, "$inc": bson.M{"version": 1}})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.UpdateMany(ctx, bson.M{"blocked_by": bson.M{"$in": taskIDs}},
+		bson.M{"$pull": bson.M{"blocked_by": bson.M{"$in": taskIDs}}, "$inc": bson.M{"version": 1}})
 	return err
 }
 
+func (r *mongoTaskRepository) ArchiveProject(ctx context.Context, projectID primitive.ObjectID, archivedAt time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"project_id": projectID},
+		bson.M{"$set": bson.M{"archived_at": toBsonTime(archivedAt)}, "$inc": bson.M{"version": 1}})
+	return err
+}
+
+// updateVersioned applies $set to the task if it is still at task.Version,
+// and then increments task.Version.
+func (r *mongoTaskRepository) updateVersioned(ctx context.Context, task *domain.Task, set interface{}) error {
+	filter := bson.M{"_id": task.ID, "version": task.Version}
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		count, err := r.collection.CountDocuments(ctx, bson.M{"_id": task.ID})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return mongo.ErrNoDocuments
+		}
+		return ErrVersionConflict
+	}
+	task.Version++
+	return nil
+}
+
 func (r *mongoTaskRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	filter := bson.M{"_id": id}
 	_, err := r.collection.DeleteOne(ctx, filter)
@@ -2,60 +2,44 @@ package repositories
 
 import (
 	"context"
-	"log"
-	"os"
+	"fmt"
 	"taskmanager/domain"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type MongoTaskTestSuite struct {
+// TaskRepositoryTestSuite exercises an ITaskRepository implementation.
+type TaskRepositoryTestSuite struct {
 	suite.Suite
+	backend  testBackend
 	taskRepo ITaskRepository
 	userRepo IUserRepository
-	dbName   string
-	client   *mongo.Client
 }
 
-func (s *MongoTaskTestSuite) SetupSuite() {
-	mongoURI := os.Getenv("MONGO_TEST_URI")
-	if mongoURI == "" {
-		mongoURI = "mongodb://localhost:27017"
-	}
-
-	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatalf("Failed to connect to Mongo for testing: %v", err)
-	}
-
-	s.client = client
-	s.dbName = "taskmanager_testdb"
-	db := s.client.Database(s.dbName)
-	s.userRepo = NewUserRepository(db)
-	s.taskRepo = NewTaskRepository(db)
-}
-
-func (s *MongoTaskTestSuite) TearDownSuite() {
-	err := s.client.Database(s.dbName).Drop(context.TODO())
-	assert.NoError(s.T(), err, "Failed to drop test database")
-
-	err = s.client.Disconnect(context.TODO())
-	assert.NoError(s.T(), err, "Failed to disconnect from Mongo")
+// SetupTest gives every test empty repositories.
+func (s *TaskRepositoryTestSuite) SetupTest() {
+	repos := s.backend.open(s.T())
+	s.userRepo, s.taskRepo = repos.Users, repos.Tasks
 }
 
-// This function runs the test suite.
+// This function runs the test suite against every backend.
 func TestTaskRepository(t *testing.T) {
-	suite.Run(t, new(MongoTaskTestSuite))
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			suite.Run(t, &TaskRepositoryTestSuite{backend: backend})
+		})
+	}
 }
 
-func (s *MongoTaskTestSuite) TestCreateAndGetTasks() {
+func (s *TaskRepositoryTestSuite) TestCreateAndGetTasks() {
 	assert := assert.New(s.T())
 
-	owner := &domain.User{Username: "taskowner", Password: "pw", Role: "user"}
+	owner := &domain.User{Username: "taskowner", Password: "pw", Roles: []string{"user"}}
 	err := s.userRepo.Create(context.Background(), owner)
 	assert.NoError(err)
 
@@ -88,3 +72,521 @@ func (s *MongoTaskTestSuite) TestCreateAndGetTasks() {
 	assert.NoError(err)
 	assert.Equal(task1.Title, foundTask.Title)
 }
+
+func (s *TaskRepositoryTestSuite) TestListTasks_FiltersSortsAndPaginates() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	owner := &domain.User{Username: "pageowner", Password: "pw", Roles: []string{"user"}}
+	assert.NoError(s.userRepo.Create(ctx, owner))
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, status := range []string{"Pending", "Done", "Pending", "Pending", "Done"} {
+		task := &domain.Task{
+			Title:   fmt.Sprintf("Task %d", i),
+			Status:  status,
+			Duedate: base.AddDate(0, 0, i),
+			UserID:  owner.ID,
+		}
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+
+	query := TaskQuery{UserID: owner.ID, Statuses: []string{"Pending"}, SortBy: SortByDueDate, Descending: true, Limit: 2}
+	page1, next, err := s.taskRepo.ListTasks(ctx, query)
+	assert.NoError(err)
+	assert.NotEmpty(next)
+	assert.Len(page1, 2)
+	assert.Equal("Task 3", page1[0].Title)
+	assert.Equal("Task 2", page1[1].Title)
+
+	query.Cursor = next
+	page2, next, err := s.taskRepo.ListTasks(ctx, query)
+	assert.NoError(err)
+	assert.Empty(next)
+	assert.Len(page2, 1)
+	assert.Equal("Task 0", page2[0].Title)
+
+	// A cursor issued for one ordering cannot be replayed against another.
+	query.SortBy = SortByTitle
+	_, _, err = s.taskRepo.ListTasks(ctx, query)
+	assert.ErrorIs(err, ErrInvalidCursor)
+
+	dueRange := TaskQuery{UserID: owner.ID, DueFrom: base.AddDate(0, 0, 1), DueTo: base.AddDate(0, 0, 3), SortBy: SortByTitle, Limit: 10}
+	inRange, _, err := s.taskRepo.ListTasks(ctx, dueRange)
+	assert.NoError(err)
+	assert.Len(inRange, 3)
+}
+
+func (s *TaskRepositoryTestSuite) TestSubtasksAndDependencies() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	owner := primitive.NewObjectID()
+
+	parent := &domain.Task{Title: "Parent", Status: "Pending", UserID: owner, CreatedAt: now}
+	assert.NoError(s.taskRepo.Create(ctx, parent))
+	blocker := &domain.Task{Title: "Blocker", Status: "Pending", UserID: owner, CreatedAt: now}
+	assert.NoError(s.taskRepo.Create(ctx, blocker))
+
+	second := &domain.Task{Title: "Second", Status: "Pending", UserID: owner, CreatedAt: now.Add(time.Second),
+		ParentID: parent.ID, BlockedBy: []primitive.ObjectID{blocker.ID}}
+	first := &domain.Task{Title: "First", Status: "Pending", UserID: owner, CreatedAt: now,
+		ParentID: parent.ID, BlockedBy: []primitive.ObjectID{blocker.ID, parent.ID}}
+	assert.NoError(s.taskRepo.Create(ctx, second))
+	assert.NoError(s.taskRepo.Create(ctx, first))
+
+	found, err := s.taskRepo.GetByID(ctx, first.ID)
+	assert.NoError(err)
+	assert.Equal(parent.ID, found.ParentID)
+	assert.Equal([]primitive.ObjectID{blocker.ID, parent.ID}, found.BlockedBy)
+
+	top, err := s.taskRepo.GetByID(ctx, parent.ID)
+	assert.NoError(err)
+	assert.True(top.ParentID.IsZero())
+	assert.Nil(top.BlockedBy)
+
+	subtasks, err := s.taskRepo.ListSubtasks(ctx, parent.ID)
+	assert.NoError(err)
+	if assert.Len(subtasks, 2) {
+		assert.Equal("First", subtasks[0].Title)
+		assert.Equal("Second", subtasks[1].Title)
+	}
+
+	blocked, err := s.taskRepo.ListBlockedTasks(ctx, blocker.ID)
+	assert.NoError(err)
+	assert.Len(blocked, 2)
+
+	byIDs, err := s.taskRepo.GetByIDs(ctx, []primitive.ObjectID{parent.ID, blocker.ID, primitive.NewObjectID()})
+	assert.NoError(err)
+	assert.Len(byIDs, 2)
+
+	// Clearing the relations must stick.
+	found.ParentID = primitive.NilObjectID
+	found.BlockedBy = nil
+	assert.NoError(s.taskRepo.Update(ctx, found))
+	subtasks, err = s.taskRepo.ListSubtasks(ctx, parent.ID)
+	assert.NoError(err)
+	assert.Len(subtasks, 1)
+	blocked, err = s.taskRepo.ListBlockedTasks(ctx, parent.ID)
+	assert.NoError(err)
+	assert.Empty(blocked)
+}
+
+func (s *TaskRepositoryTestSuite) TestListTasks_IncludesAssignedAndSharedTasks() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	owned := &domain.Task{Title: "Owned", Status: "Pending", UserID: alice}
+	assigned := &domain.Task{Title: "Assigned", Status: "Pending", UserID: bob, AssigneeID: alice}
+	shared := &domain.Task{Title: "Shared", Status: "Pending", UserID: bob,
+		Collaborators: []domain.Collaborator{{UserID: alice, Role: domain.CollaboratorViewer}}}
+	unrelated := &domain.Task{Title: "Unrelated", Status: "Pending", UserID: bob}
+	for _, task := range []*domain.Task{owned, assigned, shared, unrelated} {
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+
+	found, err := s.taskRepo.GetByID(ctx, shared.ID)
+	assert.NoError(err)
+	assert.Equal(shared.Collaborators, found.Collaborators)
+
+	titles := func(scope TaskScope) []string {
+		tasks, _, err := s.taskRepo.ListTasks(ctx, TaskQuery{UserID: alice, Scope: scope, SortBy: SortByTitle})
+		assert.NoError(err)
+		var names []string
+		for _, task := range tasks {
+			names = append(names, task.Title)
+		}
+		return names
+	}
+	assert.Equal([]string{"Assigned", "Owned", "Shared"}, titles(ScopeAll))
+	assert.Equal([]string{"Owned"}, titles(ScopeOwned))
+	assert.Equal([]string{"Assigned"}, titles(ScopeAssigned))
+	assert.Equal([]string{"Shared"}, titles(ScopeShared))
+
+	// Unsharing must stick.
+	found.Collaborators = nil
+	assert.NoError(s.taskRepo.Update(ctx, found))
+	assert.Empty(titles(ScopeShared))
+}
+
+func (s *TaskRepositoryTestSuite) TestRecurrenceRoundTrip() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	start := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	task := &domain.Task{Title: "Standup", Status: "Pending", UserID: primitive.NewObjectID(), Duedate: start}
+	assert.NoError(s.taskRepo.Create(ctx, task))
+	task.Recurrence = &domain.Recurrence{
+		Rule:       "FREQ=WEEKLY;BYDAY=MO,TH",
+		TimeZone:   "Europe/Berlin",
+		Start:      start,
+		SeriesID:   task.ID,
+		Occurrence: 1,
+	}
+	assert.NoError(s.taskRepo.Update(ctx, task))
+
+	found, err := s.taskRepo.GetByID(ctx, task.ID)
+	assert.NoError(err)
+	assert.Equal(task.Recurrence, found.Recurrence)
+
+	// Dropping the rule must stick.
+	found.Recurrence = nil
+	assert.NoError(s.taskRepo.Update(ctx, found))
+	found, err = s.taskRepo.GetByID(ctx, task.ID)
+	assert.NoError(err)
+	assert.Nil(found.Recurrence)
+}
+
+func (s *TaskRepositoryTestSuite) TestUpdate_RejectsStaleVersion() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	task := &domain.Task{Title: "Draft", Status: "Pending", UserID: primitive.NewObjectID()}
+	assert.NoError(s.taskRepo.Create(ctx, task))
+	assert.Equal(int64(1), task.Version)
+
+	first, err := s.taskRepo.GetByID(ctx, task.ID)
+	assert.NoError(err)
+	second, err := s.taskRepo.GetByID(ctx, task.ID)
+	assert.NoError(err)
+
+	first.Title = "First edit"
+	assert.NoError(s.taskRepo.Update(ctx, first))
+	assert.Equal(int64(2), first.Version)
+
+	// The second editor started from version 1 and must not overwrite.
+	second.Title = "Second edit"
+	assert.ErrorIs(s.taskRepo.Update(ctx, second), ErrVersionConflict)
+	assert.Equal(int64(1), second.Version)
+
+	found, err := s.taskRepo.GetByID(ctx, task.ID)
+	assert.NoError(err)
+	assert.Equal("First edit", found.Title)
+	assert.Equal(int64(2), found.Version)
+
+	assert.NoError(s.taskRepo.Delete(ctx, task.ID))
+	assert.ErrorIs(s.taskRepo.Update(ctx, found), mongo.ErrNoDocuments)
+}
+
+func (s *TaskRepositoryTestSuite) TestUpdateFields_WritesOnlyListedFields() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+
+	due := time.Now().UTC().Truncate(time.Millisecond)
+	task := &domain.Task{Title: "Draft", Description: "Keep me", Status: "Pending", UserID: ownerID, Duedate: due,
+		StatusHistory: []domain.StatusChange{{To: "Pending", ChangedBy: ownerID, ChangedAt: due}}}
+	assert.NoError(s.taskRepo.Create(ctx, task))
+
+	task.Title = "Final"
+	task.Description = "Not saved"
+	task.Status = "Completed"
+	task.StatusHistory = append(task.StatusHistory, domain.StatusChange{From: "Pending", To: "Completed", ChangedBy: ownerID, ChangedAt: due})
+	assert.NoError(s.taskRepo.UpdateFields(ctx, task, []TaskField{TaskFieldTitle, TaskFieldStatus}))
+	assert.Equal(int64(2), task.Version)
+
+	found, err := s.taskRepo.GetByID(ctx, task.ID)
+	assert.NoError(err)
+	assert.Equal("Final", found.Title)
+	assert.Equal("Keep me", found.Description)
+	assert.Equal("Completed", found.Status)
+	assert.Len(found.StatusHistory, 2)
+	assert.True(due.Equal(found.Duedate))
+	assert.Equal(int64(2), found.Version)
+
+	// Field updates are versioned like full ones.
+	task.Version = 1
+	assert.ErrorIs(s.taskRepo.UpdateFields(ctx, task, []TaskField{TaskFieldDescription}), ErrVersionConflict)
+}
+
+func (s *TaskRepositoryTestSuite) TestListDueBetween() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	late := &domain.Task{Title: "Late", Status: "Pending", UserID: primitive.NewObjectID(), Duedate: now.Add(2 * time.Hour)}
+	soon := &domain.Task{Title: "Soon", Status: "Pending", UserID: primitive.NewObjectID(), Duedate: now.Add(time.Hour)}
+	outside := &domain.Task{Title: "Outside", Status: "Pending", UserID: primitive.NewObjectID(), Duedate: now.Add(3 * time.Hour)}
+	for _, task := range []*domain.Task{late, soon, outside} {
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+
+	// Both bounds are inclusive, and every user's tasks are returned.
+	tasks, err := s.taskRepo.ListDueBetween(ctx, now.Add(time.Hour), now.Add(2*time.Hour))
+	assert.NoError(err)
+	if assert.Len(tasks, 2) {
+		assert.Equal("Soon", tasks[0].Title)
+		assert.Equal("Late", tasks[1].Title)
+	}
+}
+
+func (s *TaskRepositoryTestSuite) TestTrash_HidesAndRestoresTasks() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	older := &domain.Task{Title: "Older", Status: "Pending", UserID: ownerID, Duedate: now}
+	newer := &domain.Task{Title: "Newer", Status: "Pending", UserID: ownerID, Duedate: now}
+	other := &domain.Task{Title: "Other", Status: "Pending", UserID: primitive.NewObjectID(), Duedate: now}
+	for _, task := range []*domain.Task{older, newer, other} {
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+	trash := func(task *domain.Task, at time.Time) {
+		task.DeletedAt, task.DeletedBy = at, task.UserID
+		assert.NoError(s.taskRepo.UpdateFields(ctx, task, []TaskField{TaskFieldDeletion}))
+	}
+	trash(older, now.Add(-48*time.Hour))
+	trash(newer, now)
+	trash(other, now.Add(-time.Hour))
+
+	// Trashed tasks drop out of every normal read.
+	_, err := s.taskRepo.GetByID(ctx, older.ID)
+	assert.ErrorIs(err, mongo.ErrNoDocuments)
+	tasks, err := s.taskRepo.GetAllByUserID(ctx, ownerID)
+	assert.NoError(err)
+	assert.Empty(tasks)
+	tasks, _, err = s.taskRepo.ListTasks(ctx, TaskQuery{UserID: ownerID, SortBy: SortByDueDate})
+	assert.NoError(err)
+	assert.Empty(tasks)
+	tasks, err = s.taskRepo.ListDueBetween(ctx, now, now)
+	assert.NoError(err)
+	assert.Empty(tasks)
+
+	found, err := s.taskRepo.GetTrashedByID(ctx, older.ID)
+	assert.NoError(err)
+	assert.True(older.DeletedAt.Equal(found.DeletedAt))
+	assert.Equal(ownerID, found.DeletedBy)
+
+	tasks, err = s.taskRepo.ListTrash(ctx, TrashQuery{UserID: ownerID})
+	assert.NoError(err)
+	if assert.Len(tasks, 2) {
+		assert.Equal("Newer", tasks[0].Title)
+		assert.Equal("Older", tasks[1].Title)
+	}
+	tasks, err = s.taskRepo.ListTrash(ctx, TrashQuery{DeletedBefore: now.Add(-30 * time.Minute)})
+	assert.NoError(err)
+	if assert.Len(tasks, 2) {
+		assert.Equal("Other", tasks[0].Title)
+		assert.Equal("Older", tasks[1].Title)
+	}
+
+	// Clearing the deletion brings the task back.
+	newer.DeletedAt, newer.DeletedBy = time.Time{}, primitive.NilObjectID
+	assert.NoError(s.taskRepo.UpdateFields(ctx, newer, []TaskField{TaskFieldDeletion}))
+	found, err = s.taskRepo.GetByID(ctx, newer.ID)
+	assert.NoError(err)
+	assert.True(found.DeletedAt.IsZero())
+	_, err = s.taskRepo.GetTrashedByID(ctx, newer.ID)
+	assert.ErrorIs(err, mongo.ErrNoDocuments)
+}
+
+func (s *TaskRepositoryTestSuite) TestTags_FilterAndCascade() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+
+	both := &domain.Task{Title: "Both", Status: "Pending", UserID: ownerID, Tags: []string{"backend", "infra"}}
+	// The task carries the new name already, and the old one twice.
+	renamed := &domain.Task{Title: "Renamed", Status: "Pending", UserID: ownerID, Tags: []string{"backend", "api", "backend"}}
+	backend := &domain.Task{Title: "Backend", Status: "Pending", UserID: ownerID, Tags: []string{"backend"}}
+	docs := &domain.Task{Title: "Docs", Status: "Pending", UserID: ownerID, Tags: []string{"docs"}}
+	untagged := &domain.Task{Title: "Untagged", Status: "Pending", UserID: ownerID}
+	other := &domain.Task{Title: "Other", Status: "Pending", UserID: primitive.NewObjectID(), Tags: []string{"backend"}}
+	for _, task := range []*domain.Task{both, renamed, backend, docs, untagged, other} {
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+	titles := func(q TaskQuery) []string {
+		q.UserID, q.SortBy = ownerID, SortByTitle
+		tasks, _, err := s.taskRepo.ListTasks(ctx, q)
+		assert.NoError(err)
+		var titles []string
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	assert.Equal([]string{"Backend", "Both", "Docs", "Renamed"}, titles(TaskQuery{AnyTags: []string{"backend", "docs"}}))
+	assert.Equal([]string{"Both"}, titles(TaskQuery{AllTags: []string{"backend", "infra"}}))
+	assert.Equal([]string{"Both"}, titles(TaskQuery{AnyTags: []string{"infra", "docs"}, AllTags: []string{"backend"}}))
+
+	assert.NoError(s.taskRepo.RenameTag(ctx, ownerID, "backend", "api"))
+	assert.NoError(s.taskRepo.RemoveTag(ctx, ownerID, "infra"))
+
+	found, err := s.taskRepo.GetByID(ctx, both.ID)
+	assert.NoError(err)
+	assert.Equal([]string{"api"}, found.Tags)
+	assert.Equal(int64(3), found.Version)
+	found, err = s.taskRepo.GetByID(ctx, renamed.ID)
+	assert.NoError(err)
+	assert.Equal([]string{"api"}, found.Tags)
+	assert.Equal(int64(2), found.Version)
+	found, err = s.taskRepo.GetByID(ctx, untagged.ID)
+	assert.NoError(err)
+	assert.Nil(found.Tags)
+	assert.Equal(int64(1), found.Version)
+	// Another user's tag of the same name is theirs alone.
+	found, err = s.taskRepo.GetByID(ctx, other.ID)
+	assert.NoError(err)
+	assert.Equal([]string{"backend"}, found.Tags)
+	assert.Equal([]string{"Backend", "Both", "Renamed"}, titles(TaskQuery{AnyTags: []string{"api"}}))
+}
+
+func (s *TaskRepositoryTestSuite) TestRemoveUserAndTaskLinks() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	ownerID, goneID, stayID := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	parent := &domain.Task{Title: "Parent", Status: "Pending", UserID: ownerID}
+	kept := &domain.Task{Title: "Kept", Status: "Pending", UserID: ownerID}
+	for _, task := range []*domain.Task{parent, kept} {
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+	linked := &domain.Task{
+		Title:      "Linked",
+		Status:     "Pending",
+		UserID:     ownerID,
+		ParentID:   parent.ID,
+		BlockedBy:  []primitive.ObjectID{parent.ID, kept.ID},
+		AssigneeID: goneID,
+		Collaborators: []domain.Collaborator{
+			{UserID: goneID, Role: domain.CollaboratorEditor},
+			{UserID: stayID, Role: domain.CollaboratorViewer},
+		},
+	}
+	untouched := &domain.Task{Title: "Untouched", Status: "Pending", UserID: ownerID, AssigneeID: stayID, BlockedBy: []primitive.ObjectID{kept.ID}}
+	for _, task := range []*domain.Task{linked, untouched} {
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+
+	assert.NoError(s.taskRepo.RemoveUser(ctx, goneID))
+	assert.NoError(s.taskRepo.RemoveTaskLinks(ctx, []primitive.ObjectID{parent.ID}))
+
+	found, err := s.taskRepo.GetByID(ctx, linked.ID)
+	assert.NoError(err)
+	assert.True(found.AssigneeID.IsZero())
+	assert.Equal([]domain.Collaborator{{UserID: stayID, Role: domain.CollaboratorViewer}}, found.Collaborators)
+	assert.True(found.ParentID.IsZero())
+	assert.Equal([]primitive.ObjectID{kept.ID}, found.BlockedBy)
+	assert.Greater(found.Version, linked.Version)
+	found, err = s.taskRepo.GetByID(ctx, untouched.ID)
+	assert.NoError(err)
+	assert.Equal(stayID, found.AssigneeID)
+	assert.Equal([]primitive.ObjectID{kept.ID}, found.BlockedBy)
+	assert.Equal(int64(1), found.Version)
+}
+
+func (s *TaskRepositoryTestSuite) TestProjects_ScopeAndArchive() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	memberID := primitive.NewObjectID()
+	projectID, otherProjectID := primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	// The member neither owns nor is assigned to the project's tasks.
+	planned := &domain.Task{Title: "Planned", Status: "Pending", UserID: primitive.NewObjectID(), ProjectID: projectID, Duedate: now}
+	assigned := &domain.Task{Title: "Assigned", Status: "Pending", UserID: primitive.NewObjectID(), ProjectID: projectID, AssigneeID: memberID, Duedate: now}
+	elsewhere := &domain.Task{Title: "Elsewhere", Status: "Pending", UserID: primitive.NewObjectID(), ProjectID: otherProjectID}
+	personal := &domain.Task{Title: "Personal", Status: "Pending", UserID: memberID, Duedate: now}
+	for _, task := range []*domain.Task{planned, assigned, elsewhere, personal} {
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+	titles := func(q TaskQuery) []string {
+		q.UserID, q.SortBy = memberID, SortByTitle
+		tasks, _, err := s.taskRepo.ListTasks(ctx, q)
+		assert.NoError(err)
+		var titles []string
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	assert.Equal([]string{"Assigned", "Planned"}, titles(TaskQuery{ProjectID: projectID}))
+	assert.Equal([]string{"Assigned"}, titles(TaskQuery{ProjectID: projectID, Scope: ScopeAssigned}))
+	assert.Equal([]string{"Assigned", "Personal"}, titles(TaskQuery{}))
+
+	assert.NoError(s.taskRepo.ArchiveProject(ctx, projectID, now))
+	found, err := s.taskRepo.GetByID(ctx, planned.ID)
+	assert.NoError(err)
+	assert.True(now.Equal(found.ArchivedAt))
+	assert.Equal(projectID, found.ProjectID)
+	assert.Equal(int64(2), found.Version)
+	assert.Empty(titles(TaskQuery{ProjectID: projectID}))
+	assert.Equal([]string{"Personal"}, titles(TaskQuery{}))
+	assert.Equal([]string{"Assigned", "Planned"}, titles(TaskQuery{ProjectID: projectID, IncludeArchived: true}))
+	due, err := s.taskRepo.ListDueBetween(ctx, now, now)
+	assert.NoError(err)
+	if assert.Len(due, 1) {
+		assert.Equal("Personal", due[0].Title)
+	}
+
+	assert.NoError(s.taskRepo.ArchiveProject(ctx, projectID, time.Time{}))
+	assert.Equal([]string{"Assigned", "Planned"}, titles(TaskQuery{ProjectID: projectID}))
+	found, err = s.taskRepo.GetByID(ctx, elsewhere.ID)
+	assert.NoError(err)
+	assert.Equal(int64(1), found.Version)
+}
+
+func (s *TaskRepositoryTestSuite) TestListTasks_SearchFilters() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tasks := []*domain.Task{
+		{Title: "Fix login bug", Description: "Users see 100% CPU", Status: "Pending", UserID: ownerID, Duedate: base, Tags: []string{"backend"}},
+		{Title: "Write docs", Description: "Explain the LOGIN flow", Status: "Completed", UserID: ownerID, Duedate: base.AddDate(0, 0, 1), Tags: []string{"docs"}},
+		{Title: "Deploy", Description: "Roll out the login_v2 service", Status: "In Progress", UserID: ownerID, Duedate: base.AddDate(0, 0, 2), Tags: []string{"infra", "backend"}},
+		{Title: "Plan sprint", Status: "Pending", UserID: ownerID, Duedate: base.AddDate(0, 0, 3)},
+	}
+	for _, task := range tasks {
+		assert.NoError(s.taskRepo.Create(ctx, task))
+	}
+	titles := func(q TaskQuery) []string {
+		q.UserID, q.SortBy = ownerID, SortByTitle
+		tasks, _, err := s.taskRepo.ListTasks(ctx, q)
+		assert.NoError(err)
+		var titles []string
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	assert.Equal([]string{"Deploy", "Fix login bug", "Write docs"}, titles(TaskQuery{Text: []string{"Login"}}))
+	assert.Equal([]string{"Fix login bug", "Write docs"}, titles(TaskQuery{Text: []string{"login"}, ExcludeText: []string{"service"}}))
+	// Wildcards in the search text match only themselves.
+	assert.Equal([]string{"Fix login bug"}, titles(TaskQuery{Text: []string{"100%"}}))
+	assert.Equal([]string{"Deploy"}, titles(TaskQuery{Text: []string{"login_"}}))
+	assert.Equal([]string{"Deploy", "Fix login bug", "Plan sprint"}, titles(TaskQuery{ExcludeStatuses: []string{"Completed"}}))
+	assert.Equal([]string{"Plan sprint", "Write docs"}, titles(TaskQuery{ExcludeTags: []string{"backend"}}))
+	assert.Equal([]string{"Fix login bug", "Write docs"}, titles(TaskQuery{DueBefore: base.AddDate(0, 0, 2)}))
+	assert.Equal([]string{"Deploy"}, titles(TaskQuery{DueFrom: base.AddDate(0, 0, 1), DueBefore: base.AddDate(0, 0, 3), ExcludeTags: []string{"docs"}}))
+}
+
+func (s *TaskRepositoryTestSuite) TestExternalIDs() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	ownerID, otherID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	imported := &domain.Task{Title: "Imported", Status: "Pending", UserID: ownerID, ExternalID: "JIRA-1"}
+	assert.NoError(s.taskRepo.Create(ctx, imported))
+	assert.NoError(s.taskRepo.Create(ctx, &domain.Task{Title: "Local", Status: "Pending", UserID: ownerID}))
+	assert.NoError(s.taskRepo.Create(ctx, &domain.Task{Title: "Local too", Status: "Pending", UserID: ownerID}))
+	assert.NoError(s.taskRepo.Create(ctx, &domain.Task{Title: "Theirs", Status: "Pending", UserID: otherID, ExternalID: "JIRA-1"}))
+	err := s.taskRepo.Create(ctx, &domain.Task{Title: "Again", Status: "Pending", UserID: ownerID, ExternalID: "JIRA-1"})
+	assert.True(mongo.IsDuplicateKeyError(err), err)
+
+	imported.DeletedAt, imported.DeletedBy = time.Now().UTC().Truncate(time.Millisecond), ownerID
+	assert.NoError(s.taskRepo.UpdateFields(ctx, imported, []TaskField{TaskFieldDeletion}))
+	found, err := s.taskRepo.GetByExternalID(ctx, ownerID, "JIRA-1")
+	if assert.NoError(err) {
+		assert.Equal(imported.ID, found.ID)
+		assert.Equal("JIRA-1", found.ExternalID)
+	}
+	_, err = s.taskRepo.GetByExternalID(ctx, ownerID, "JIRA-2")
+	assert.ErrorIs(err, mongo.ErrNoDocuments)
+}
@@ -0,0 +1,243 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ITokenRepository stores refresh tokens, the IDs of revoked access tokens
+// and password reset tokens.
+type ITokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	FindRefreshTokenByHash(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	// MarkRefreshTokenUsed atomically flags an unused token as rotated. It
+	// returns false if the token had already been used.
+	MarkRefreshTokenUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time) (bool, error)
+	FindRefreshTokenFamily(ctx context.Context, familyID primitive.ObjectID) ([]domain.RefreshToken, error)
+	FindRefreshTokensByUser(ctx context.Context, userID primitive.ObjectID) ([]domain.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID primitive.ObjectID) error
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+	// ConsumeToken atomically revokes a single-use token, such as a stream
+	// ticket, until expiresAt. It returns false if it was already revoked.
+	ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error)
+	CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error
+	FindPasswordResetTokenByHash(ctx context.Context, hash string) (*domain.PasswordResetToken, error)
+	// MarkPasswordResetTokenUsed atomically spends an unused reset token. It
+	// returns false if the token had already been used.
+	MarkPasswordResetTokenUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time) (bool, error)
+	// InvalidatePasswordResetTokens spends every unused reset token of a user.
+	InvalidatePasswordResetTokens(ctx context.Context, userID primitive.ObjectID, usedAt time.Time) error
+}
+
+// mongoTokenRepository is the concrete implementation.
+type mongoTokenRepository struct {
+	refreshTokens *mongo.Collection
+	revokedTokens *mongo.Collection
+	resetTokens   *mongo.Collection
+}
+
+// NewTokenRepository is the constructor.
+func NewTokenRepository(db *mongo.Database) ITokenRepository {
+	refreshTokens := db.Collection("refresh_tokens")
+	revokedTokens := db.Collection("revoked_tokens")
+	resetTokens := db.Collection("password_reset_tokens")
+
+	_, _ = refreshTokens.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"token_hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"family_id": 1}},
+		{Keys: bson.M{"user_id": 1}},
+		// Let Mongo drop refresh tokens once they have expired.
+		{Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	// A revoked access token only needs remembering until it would expire anyway.
+	_, _ = revokedTokens.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	_, _ = resetTokens.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.M{"token_hash": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.M{"user_id": 1}},
+		{Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	return &mongoTokenRepository{refreshTokens: refreshTokens, revokedTokens: revokedTokens, resetTokens: resetTokens}
+}
+
+// toBsonRefreshToken converts a domain.RefreshToken to its BSON model.
+func toBsonRefreshToken(token *domain.RefreshToken) *datamodels.RefreshToken {
+	return &datamodels.RefreshToken{
+		ID:              token.ID,
+		UserID:          token.UserID,
+		FamilyID:        token.FamilyID,
+		TokenHash:       token.TokenHash,
+		AccessTokenID:   token.AccessTokenID,
+		AccessExpiresAt: token.AccessExpiresAt,
+		ExpiresAt:       token.ExpiresAt,
+		CreatedAt:       token.CreatedAt,
+		UsedAt:          token.UsedAt,
+		Revoked:         token.Revoked,
+	}
+}
+
+// toDomainRefreshToken converts a BSON refresh token to a domain.RefreshToken.
+func toDomainRefreshToken(token *datamodels.RefreshToken) *domain.RefreshToken {
+	return &domain.RefreshToken{
+		ID:              token.ID,
+		UserID:          token.UserID,
+		FamilyID:        token.FamilyID,
+		TokenHash:       token.TokenHash,
+		AccessTokenID:   token.AccessTokenID,
+		AccessExpiresAt: token.AccessExpiresAt,
+		ExpiresAt:       token.ExpiresAt,
+		CreatedAt:       token.CreatedAt,
+		UsedAt:          token.UsedAt,
+		Revoked:         token.Revoked,
+	}
+}
+
+func (r *mongoTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	result, err := r.refreshTokens.InsertOne(ctx, toBsonRefreshToken(token))
+	if err != nil {
+		return err
+	}
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoTokenRepository) FindRefreshTokenByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	var bsonToken datamodels.RefreshToken
+	err := r.refreshTokens.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&bsonToken)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainRefreshToken(&bsonToken), nil
+}
+
+func (r *mongoTokenRepository) MarkRefreshTokenUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time) (bool, error) {
+	// Matching on a zero used_at makes the check-and-set a single atomic write.
+	filter := bson.M{"_id": id, "used_at": time.Time{}}
+	result, err := r.refreshTokens.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"used_at": usedAt}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *mongoTokenRepository) FindRefreshTokenFamily(ctx context.Context, familyID primitive.ObjectID) ([]domain.RefreshToken, error) {
+	return r.findRefreshTokens(ctx, bson.M{"family_id": familyID})
+}
+
+func (r *mongoTokenRepository) FindRefreshTokensByUser(ctx context.Context, userID primitive.ObjectID) ([]domain.RefreshToken, error) {
+	return r.findRefreshTokens(ctx, bson.M{"user_id": userID})
+}
+
+func (r *mongoTokenRepository) findRefreshTokens(ctx context.Context, filter bson.M) ([]domain.RefreshToken, error) {
+	var bsonTokens []datamodels.RefreshToken
+	cursor, err := r.refreshTokens.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err = cursor.All(ctx, &bsonTokens); err != nil {
+		return nil, err
+	}
+	tokens := make([]domain.RefreshToken, len(bsonTokens))
+	for i, t := range bsonTokens {
+		tokens[i] = *toDomainRefreshToken(&t)
+	}
+	return tokens, nil
+}
+
+func (r *mongoTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	_, err := r.refreshTokens.UpdateMany(ctx, bson.M{"family_id": familyID}, bson.M{"$set": bson.M{"revoked": true}})
+	return err
+}
+
+func (r *mongoTokenRepository) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	filter := bson.M{"_id": jti}
+	update := bson.M{"$set": bson.M{"expires_at": expiresAt}}
+	_, err := r.revokedTokens.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *mongoTokenRepository) ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	_, err := r.revokedTokens.InsertOne(ctx, bson.M{"_id": jti, "expires_at": expiresAt})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *mongoTokenRepository) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	count, err := r.revokedTokens.CountDocuments(ctx, bson.M{"_id": jti}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// toBsonPasswordResetToken converts a domain.PasswordResetToken to its BSON model.
+func toBsonPasswordResetToken(token *domain.PasswordResetToken) *datamodels.PasswordResetToken {
+	return &datamodels.PasswordResetToken{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		TokenHash: token.TokenHash,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+		UsedAt:    token.UsedAt,
+	}
+}
+
+// toDomainPasswordResetToken converts a BSON reset token to a domain.PasswordResetToken.
+func toDomainPasswordResetToken(token *datamodels.PasswordResetToken) *domain.PasswordResetToken {
+	return &domain.PasswordResetToken{
+		ID:        token.ID,
+		UserID:    token.UserID,
+		TokenHash: token.TokenHash,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+		UsedAt:    token.UsedAt,
+	}
+}
+
+func (r *mongoTokenRepository) CreatePasswordResetToken(ctx context.Context, token *domain.PasswordResetToken) error {
+	result, err := r.resetTokens.InsertOne(ctx, toBsonPasswordResetToken(token))
+	if err != nil {
+		return err
+	}
+	token.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoTokenRepository) FindPasswordResetTokenByHash(ctx context.Context, hash string) (*domain.PasswordResetToken, error) {
+	var bsonToken datamodels.PasswordResetToken
+	err := r.resetTokens.FindOne(ctx, bson.M{"token_hash": hash}).Decode(&bsonToken)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainPasswordResetToken(&bsonToken), nil
+}
+
+func (r *mongoTokenRepository) MarkPasswordResetTokenUsed(ctx context.Context, id primitive.ObjectID, usedAt time.Time) (bool, error) {
+	filter := bson.M{"_id": id, "used_at": time.Time{}}
+	result, err := r.resetTokens.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"used_at": usedAt}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *mongoTokenRepository) InvalidatePasswordResetTokens(ctx context.Context, userID primitive.ObjectID, usedAt time.Time) error {
+	filter := bson.M{"user_id": userID, "used_at": time.Time{}}
+	_, err := r.resetTokens.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"used_at": usedAt}})
+	return err
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TokenRepositoryTestSuite exercises an ITokenRepository implementation.
+type TokenRepositoryTestSuite struct {
+	suite.Suite
+	backend   testBackend
+	tokenRepo ITokenRepository
+}
+
+// SetupTest gives every test an empty repository.
+func (s *TokenRepositoryTestSuite) SetupTest() {
+	s.tokenRepo = s.backend.open(s.T()).Tokens
+}
+
+func TestTokenRepository(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			suite.Run(t, &TokenRepositoryTestSuite{backend: backend})
+		})
+	}
+}
+
+func (s *TokenRepositoryTestSuite) TestRefreshTokenRotationAndFamilyRevocation() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	familyID := primitive.NewObjectID()
+
+	first := &domain.RefreshToken{
+		UserID:          primitive.NewObjectID(),
+		FamilyID:        familyID,
+		TokenHash:       "hash-1",
+		AccessTokenID:   "jti-1",
+		AccessExpiresAt: now.Add(15 * time.Minute),
+		ExpiresAt:       now.Add(time.Hour),
+		CreatedAt:       now,
+	}
+	assert.NoError(s.tokenRepo.CreateRefreshToken(ctx, first))
+	assert.False(first.ID.IsZero())
+
+	found, err := s.tokenRepo.FindRefreshTokenByHash(ctx, "hash-1")
+	assert.NoError(err)
+	assert.Equal(first.ID, found.ID)
+	assert.Equal(familyID, found.FamilyID)
+	assert.True(found.UsedAt.IsZero())
+	assert.True(first.ExpiresAt.Equal(found.ExpiresAt))
+
+	// Only the first rotation of a token succeeds.
+	marked, err := s.tokenRepo.MarkRefreshTokenUsed(ctx, first.ID, now)
+	assert.NoError(err)
+	assert.True(marked)
+	marked, err = s.tokenRepo.MarkRefreshTokenUsed(ctx, first.ID, now)
+	assert.NoError(err)
+	assert.False(marked)
+
+	second := &domain.RefreshToken{UserID: first.UserID, FamilyID: familyID, TokenHash: "hash-2", AccessTokenID: "jti-2",
+		AccessExpiresAt: now.Add(15 * time.Minute), ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	assert.NoError(s.tokenRepo.CreateRefreshToken(ctx, second))
+
+	family, err := s.tokenRepo.FindRefreshTokenFamily(ctx, familyID)
+	assert.NoError(err)
+	assert.Len(family, 2)
+
+	assert.NoError(s.tokenRepo.RevokeRefreshTokenFamily(ctx, familyID))
+	found, err = s.tokenRepo.FindRefreshTokenByHash(ctx, "hash-2")
+	assert.NoError(err)
+	assert.True(found.Revoked)
+
+	_, err = s.tokenRepo.FindRefreshTokenByHash(ctx, "unknown")
+	assert.Equal(mongo.ErrNoDocuments, err)
+}
+
+func (s *TokenRepositoryTestSuite) TestAccessTokenRevocation() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(ctx, "jti-a")
+	assert.NoError(err)
+	assert.False(revoked)
+
+	assert.NoError(s.tokenRepo.RevokeAccessToken(ctx, "jti-a", time.Now().Add(time.Hour)))
+	// Revoking twice is harmless.
+	assert.NoError(s.tokenRepo.RevokeAccessToken(ctx, "jti-a", time.Now().Add(time.Hour)))
+
+	revoked, err = s.tokenRepo.IsAccessTokenRevoked(ctx, "jti-a")
+	assert.NoError(err)
+	assert.True(revoked)
+}
+
+func (s *TokenRepositoryTestSuite) TestConsumeTokenOnlyOnce() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Minute)
+
+	first, err := s.tokenRepo.ConsumeToken(ctx, "ticket-a", expiresAt)
+	assert.NoError(err)
+	again, err := s.tokenRepo.ConsumeToken(ctx, "ticket-a", expiresAt)
+	assert.NoError(err)
+	other, err := s.tokenRepo.ConsumeToken(ctx, "ticket-b", expiresAt)
+	assert.NoError(err)
+
+	// --- ASSERT ---
+	assert.True(first)
+	assert.False(again, "a ticket can only be used once")
+	assert.True(other)
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(ctx, "ticket-a")
+	assert.NoError(err)
+	assert.True(revoked)
+}
+
+func (s *TokenRepositoryTestSuite) TestFindRefreshTokensByUser() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	userID := primitive.NewObjectID()
+	for i, owner := range []primitive.ObjectID{userID, userID, primitive.NewObjectID()} {
+		assert.NoError(s.tokenRepo.CreateRefreshToken(ctx, &domain.RefreshToken{UserID: owner, FamilyID: primitive.NewObjectID(),
+			TokenHash: "hash-" + string(rune('a'+i)), AccessExpiresAt: now, ExpiresAt: now.Add(time.Hour), CreatedAt: now}))
+	}
+
+	tokens, err := s.tokenRepo.FindRefreshTokensByUser(ctx, userID)
+
+	// --- ASSERT ---
+	assert.NoError(err)
+	assert.Len(tokens, 2)
+	for _, token := range tokens {
+		assert.Equal(userID, token.UserID)
+	}
+}
+
+func (s *TokenRepositoryTestSuite) TestPasswordResetTokens() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	userID := primitive.NewObjectID()
+	first := &domain.PasswordResetToken{UserID: userID, TokenHash: "reset-1", ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	second := &domain.PasswordResetToken{UserID: userID, TokenHash: "reset-2", ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	assert.NoError(s.tokenRepo.CreatePasswordResetToken(ctx, first))
+	assert.NoError(s.tokenRepo.CreatePasswordResetToken(ctx, second))
+
+	found, findErr := s.tokenRepo.FindPasswordResetTokenByHash(ctx, "reset-1")
+	marked, markErr := s.tokenRepo.MarkPasswordResetTokenUsed(ctx, first.ID, now)
+	markedAgain, _ := s.tokenRepo.MarkPasswordResetTokenUsed(ctx, first.ID, now)
+	invalidateErr := s.tokenRepo.InvalidatePasswordResetTokens(ctx, userID, now)
+	superseded, _ := s.tokenRepo.FindPasswordResetTokenByHash(ctx, "reset-2")
+	_, unknownErr := s.tokenRepo.FindPasswordResetTokenByHash(ctx, "unknown")
+
+	// --- ASSERT ---
+	assert.NoError(findErr)
+	assert.Equal(first.ID, found.ID)
+	assert.Equal(userID, found.UserID)
+	assert.True(found.UsedAt.IsZero())
+	assert.True(first.ExpiresAt.Equal(found.ExpiresAt))
+	assert.NoError(markErr)
+	assert.True(marked)
+	assert.False(markedAgain, "a reset token works once")
+	assert.NoError(invalidateErr)
+	assert.False(superseded.UsedAt.IsZero())
+	assert.Equal(mongo.ErrNoDocuments, unknownErr)
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrTransactionsUnsupported is returned when the database cannot run a unit
+// of work atomically, such as a MongoDB server that is not a replica set.
+var ErrTransactionsUnsupported = errors.New("transactions are not supported by this database")
+
+// IUnitOfWork runs a group of repository calls atomically: either all of
+// their writes are kept or none are.
+type IUnitOfWork interface {
+	// Run calls fn with a context that the repositories of the same backend
+	// recognize. fn must make every call of the unit with that context. If
+	// fn returns an error the writes are undone and Run returns it. fn may
+	// be called again when the database asks for the unit to be retried, so
+	// it should not keep state between calls.
+	Run(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// mongoUnitOfWork runs units of work as MongoDB multi-document transactions.
+type mongoUnitOfWork struct {
+	client *mongo.Client
+}
+
+// NewMongoUnitOfWork is the constructor. Transactions need a replica set or
+// a sharded cluster; on a standalone server Run fails with
+// ErrTransactionsUnsupported.
+func NewMongoUnitOfWork(client *mongo.Client) IUnitOfWork {
+	return &mongoUnitOfWork{client: client}
+}
+
+// illegalOperation is the server error code for a transaction on a
+// standalone server.
+const illegalOperation = 20
+
+func (u *mongoUnitOfWork) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	// A unit of work inside another joins its transaction.
+	if session := mongo.SessionFromContext(ctx); session != nil {
+		return fn(ctx)
+	}
+	session, err := u.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == illegalOperation {
+		return ErrTransactionsUnsupported
+	}
+	return err
+}
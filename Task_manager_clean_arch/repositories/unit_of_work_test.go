@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			repos := backend.open(t)
+			ctx := context.Background()
+			ownerID := primitive.NewObjectID()
+			kept := &domain.Task{Title: "Kept", Status: "Pending", UserID: ownerID}
+			require.NoError(t, repos.Tasks.Create(ctx, kept))
+
+			// A failing unit undoes creates, updates, deletes and audit entries.
+			errStop := errors.New("stop")
+			var created *domain.Task
+			err := repos.UnitOfWork.Run(ctx, func(ctx context.Context) error {
+				created = &domain.Task{Title: "Rolled back", Status: "Pending", UserID: ownerID}
+				if err := repos.Tasks.Create(ctx, created); err != nil {
+					return err
+				}
+				update := *kept
+				update.Title = "Changed"
+				if err := repos.Tasks.Update(ctx, &update); err != nil {
+					return err
+				}
+				if err := repos.Audit.Append(ctx, &domain.AuditEntry{EntityID: kept.ID, Action: domain.AuditActionUpdate, Timestamp: time.Now()}); err != nil {
+					return err
+				}
+				// The unit sees its own writes.
+				found, err := repos.Tasks.GetByID(ctx, created.ID)
+				if err != nil {
+					return err
+				}
+				assert.Equal(t, "Rolled back", found.Title)
+				return errStop
+			})
+			if errors.Is(err, ErrTransactionsUnsupported) {
+				t.Skip(err)
+			}
+			assert.ErrorIs(t, err, errStop)
+
+			_, err = repos.Tasks.GetByID(ctx, created.ID)
+			assert.Error(t, err)
+			found, err := repos.Tasks.GetByID(ctx, kept.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "Kept", found.Title)
+			assert.Equal(t, int64(1), found.Version)
+			entries, _, err := repos.Audit.List(ctx, AuditQuery{EntityID: kept.ID})
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+
+			// A nested unit joins the outer one, which commits both.
+			err = repos.UnitOfWork.Run(ctx, func(ctx context.Context) error {
+				if err := repos.Tasks.Create(ctx, &domain.Task{Title: "Outer", Status: "Pending", UserID: ownerID}); err != nil {
+					return err
+				}
+				return repos.UnitOfWork.Run(ctx, func(ctx context.Context) error {
+					return repos.Tasks.Delete(ctx, kept.ID)
+				})
+			})
+			require.NoError(t, err)
+
+			// --- ASSERT ---
+			tasks, err := repos.Tasks.GetAllByUserID(ctx, ownerID)
+			require.NoError(t, err)
+			require.Len(t, tasks, 1)
+			assert.Equal(t, "Outer", tasks[0].Title)
+		})
+	}
+}
+
+func TestMemoryUnitOfWork_UndoesOnlyItsOwnWrites(t *testing.T) {
+	repos := NewMemoryRepositories()
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	now := time.Now()
+
+	var other *domain.Task
+	err := repos.UnitOfWork.Run(ctx, func(unitCtx context.Context) error {
+		if _, err := repos.LoginAttempts.RecordFailure(unitCtx, "user:alice", now, time.Minute); err != nil {
+			return err
+		}
+		if err := repos.Tasks.Create(unitCtx, &domain.Task{Title: "Rolled back", Status: "Pending", UserID: ownerID}); err != nil {
+			return err
+		}
+		// Another request writes while the unit runs.
+		other = &domain.Task{Title: "Other request", Status: "Pending", UserID: ownerID}
+		if err := repos.Tasks.Create(ctx, other); err != nil {
+			return err
+		}
+		if _, err := repos.LoginAttempts.RecordFailure(ctx, "user:bob", now, time.Minute); err != nil {
+			return err
+		}
+		return errors.New("stop")
+	})
+	require.Error(t, err)
+
+	// --- ASSERT ---
+	tasks, err := repos.Tasks.GetAllByUserID(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, other.ID, tasks[0].ID)
+	_, err = repos.LoginAttempts.Find(ctx, "user:alice")
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	attempts, err := repos.LoginAttempts.Find(ctx, "user:bob")
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts.Failures)
+}
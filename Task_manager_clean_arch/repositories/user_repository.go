@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"encoding/base64"
+	"regexp"
 	"taskmanager/domain"
 	datamodels "taskmanager/repositories/models"
 
@@ -11,12 +13,72 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// UserField names a part of a user that UpdateFields can write on its own.
+type UserField string
+
+const (
+	UserFieldPassword        UserField = "password"
+	UserFieldRoles           UserField = "roles"
+	UserFieldReminderOffsets UserField = "reminder_offsets"
+	UserFieldDisabled        UserField = "disabled_at"
+)
+
 type IUserRepository interface {
 	Create(ctx context.Context, user *domain.User) error
 	FindByUsername(ctx context.Context, username string) (*domain.User, error)
 	FindByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error)
 	Update(ctx context.Context, user *domain.User) error
+	// UpdateFields saves only the listed fields of user, so whatever else
+	// changed since it was read is kept. Updating a missing user is a no-op.
+	UpdateFields(ctx context.Context, user *domain.User, fields []UserField) error
 	Count(ctx context.Context) (int64, error)
+	// CountByRole counts the users who have the role.
+	CountByRole(ctx context.Context, role string) (int64, error)
+	// List returns a page of the users matching query and the cursor of the
+	// next page, or "" on the last one.
+	List(ctx context.Context, query UserQuery) ([]domain.User, string, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+// Account statuses a UserQuery can filter on.
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+)
+
+// UserQuery describes a filtered page of users, ordered by username. Zero
+// values mean "no filter".
+type UserQuery struct {
+	// Search matches the usernames that contain it, ignoring case.
+	Search string
+	Role   string
+	Status string // UserStatusActive or UserStatusDisabled
+	Limit  int
+	Cursor string
+}
+
+// encodeUserCursor records the username of the last user on a page, which
+// is unique.
+func encodeUserCursor(user *domain.User) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(user.Username))
+}
+
+func decodeUserCursor(cursor string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) == 0 {
+		return "", ErrInvalidCursor
+	}
+	return string(raw), nil
+}
+
+// pageUsers trims a result fetched with Limit+1 rows down to one page and
+// returns the cursor for the next page, or "" on the last one.
+func pageUsers(users []domain.User, q UserQuery) ([]domain.User, string, error) {
+	if q.Limit <= 0 || len(users) <= q.Limit {
+		return users, "", nil
+	}
+	users = users[:q.Limit]
+	return users, encodeUserCursor(&users[len(users)-1]), nil
 }
 
 // mongoUserRepository is the concrete implementation.
@@ -39,20 +101,29 @@ func NewUserRepository(db *mongo.Database) IUserRepository {
 // toBsonUser converts a pure domain.User into a BSON-tagged datamodels.User.
 func toBsonUser(user *domain.User) *datamodels.User {
 	return &datamodels.User{
-		ID:       user.ID,
-		Username: user.Username,
-		Password: user.Password,
-		Role:     user.Role,
+		ID:              user.ID,
+		Username:        user.Username,
+		Password:        user.Password,
+		Roles:           user.Roles,
+		ReminderOffsets: user.ReminderOffsets,
+		DisabledAt:      toBsonTime(user.DisabledAt),
 	}
 }
 
 // toDomainUser converts a BSON-tagged datamodels.User into a pure domain.User.
+// Users saved before they had several roles have their one role instead.
 func toDomainUser(user *datamodels.User) *domain.User {
+	roles := user.Roles
+	if roles == nil && user.Role != "" {
+		roles = []string{user.Role}
+	}
 	return &domain.User{
-		ID:       user.ID,
-		Username: user.Username,
-		Password: user.Password,
-		Role:     user.Role,
+		ID:              user.ID,
+		Username:        user.Username,
+		Password:        user.Password,
+		Roles:           roles,
+		ReminderOffsets: user.ReminderOffsets,
+		DisabledAt:      toDomainTime(user.DisabledAt),
 	}
 }
 
@@ -95,11 +166,99 @@ func (r *mongoUserRepository) FindByID(ctx context.Context, id primitive.ObjectI
 func (r *mongoUserRepository) Update(ctx context.Context, user *domain.User) error {
 	bsonUser := toBsonUser(user)
 	filter := bson.M{"_id": bsonUser.ID}
-	update := bson.M{"$set": bsonUser} // Use $set to update all fields in the BSON model
+	// Use $set to update all fields in the BSON model, and drop the old single role
+	update := bson.M{"$set": bsonUser, "$unset": bson.M{"role": ""}}
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
 
+func (r *mongoUserRepository) UpdateFields(ctx context.Context, user *domain.User, fields []UserField) error {
+	// Render the whole user with its bson tags, then keep the chosen keys.
+	data, err := bson.Marshal(toBsonUser(user))
+	if err != nil {
+		return err
+	}
+	var document bson.M
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	set := bson.M{}
+	update := bson.M{"$set": set}
+	for _, field := range fields {
+		set[string(field)] = document[string(field)]
+		if field == UserFieldRoles {
+			update["$unset"] = bson.M{"role": ""}
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": user.ID}, update)
+	return err
+}
+
 func (r *mongoUserRepository) Count(ctx context.Context) (int64, error) {
 	return r.collection.CountDocuments(ctx, bson.M{})
 }
+
+func (r *mongoUserRepository) CountByRole(ctx context.Context, role string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"$or": bson.A{
+		bson.M{"roles": role},
+		bson.M{"role": role, "roles": bson.M{"$exists": false}},
+	}})
+}
+
+func (r *mongoUserRepository) List(ctx context.Context, q UserQuery) ([]domain.User, string, error) {
+	var conditions []bson.M
+	if q.Search != "" {
+		conditions = append(conditions, bson.M{"username": primitive.Regex{Pattern: regexp.QuoteMeta(q.Search), Options: "i"}})
+	}
+	if q.Role != "" {
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"roles": q.Role},
+			bson.M{"role": q.Role, "roles": bson.M{"$exists": false}},
+		}})
+	}
+	switch q.Status {
+	case UserStatusActive:
+		conditions = append(conditions, bson.M{"disabled_at": nil})
+	case UserStatusDisabled:
+		conditions = append(conditions, bson.M{"disabled_at": bson.M{"$ne": nil}})
+	}
+	if q.Cursor != "" {
+		last, err := decodeUserCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		conditions = append(conditions, bson.M{"username": bson.M{"$gt": last}})
+	}
+	filter := bson.M{}
+	if len(conditions) > 0 {
+		filter = bson.M{"$and": conditions}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "username", Value: 1}})
+	if q.Limit > 0 {
+		opts.SetLimit(int64(q.Limit) + 1)
+	}
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonUsers []datamodels.User
+	if err = cursor.All(ctx, &bsonUsers); err != nil {
+		return nil, "", err
+	}
+	users := make([]domain.User, len(bsonUsers))
+	for i := range bsonUsers {
+		users[i] = *toDomainUser(&bsonUsers[i])
+	}
+	return pageUsers(users, q)
+}
+
+func (r *mongoUserRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
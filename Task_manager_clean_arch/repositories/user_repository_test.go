@@ -2,66 +2,44 @@ package repositories
 
 import (
 	"context"
-	"log"
-	"os"
 	"taskmanager/domain"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type MongoUserTestSuite struct {
+// UserRepositoryTestSuite exercises an IUserRepository implementation.
+type UserRepositoryTestSuite struct {
 	suite.Suite
-	client   *mongo.Client
+	backend  testBackend
 	userRepo IUserRepository
-	dbName   string
 }
 
-func (s *MongoUserTestSuite) SetupSuite() {
-	mongoURI := os.Getenv("MONGO_TEST_URI")
-	if mongoURI == "" {
-		mongoURI = "mongodb://localhost:27017"
-	}
-
-	client, err := mongo.Connect(context.TODO(), options.Client().ApplyURI(mongoURI))
-	if err != nil {
-		log.Fatalf("Failed to connect to Mongo for testing: %v", err)
-	}
-
-	s.client = client
-	s.dbName = "taskmanager_testdb"
-	db := s.client.Database(s.dbName)
-	s.userRepo = NewUserRepository(db)
-}
-
-func (s *MongoUserTestSuite) TearDownSuite() {
-	err := s.client.Database(s.dbName).Drop(context.TODO())
-	assert.NoError(s.T(), err, "Failed to drop test database")
-
-	err = s.client.Disconnect(context.TODO())
-	assert.NoError(s.T(), err, "Failed to disconnect from Mongo")
-}
-
-func (s *MongoUserTestSuite) BeforeTest(suiteName, testName string) {
-	err := s.client.Database(s.dbName).Collection("users").Drop(context.TODO())
-	assert.NoError(s.T(), err, "Failed to drop users collection before test")
+// SetupTest gives every test an empty repository.
+func (s *UserRepositoryTestSuite) SetupTest() {
+	s.userRepo = s.backend.open(s.T()).Users
 }
 
 func TestUserRepositorySuite(t *testing.T) {
-	suite.Run(t, new(MongoUserTestSuite))
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			suite.Run(t, &UserRepositoryTestSuite{backend: backend})
+		})
+	}
 }
 
-func (s *MongoUserTestSuite) TestCreateAndFindByUsername() {
+func (s *UserRepositoryTestSuite) TestCreateAndFindByUsername() {
 	assert := assert.New(s.T())
 	ctx := context.Background()
 
 	testUser := &domain.User{
 		Username: "testuser",
 		Password: "hashedpassword",
-		Role:     "user",
+		Roles:    []string{"user"},
 	}
 
 	err := s.userRepo.Create(ctx, testUser)
@@ -77,7 +55,7 @@ func (s *MongoUserTestSuite) TestCreateAndFindByUsername() {
 	assert.Equal("hashedpassword", foundUser.Password)
 }
 
-func (s *MongoUserTestSuite) TestFindByUsername_NotFound() {
+func (s *UserRepositoryTestSuite) TestFindByUsername_NotFound() {
 	assert := assert.New(s.T())
 	ctx := context.Background()
 
@@ -89,12 +67,12 @@ func (s *MongoUserTestSuite) TestFindByUsername_NotFound() {
 	assert.Equal(mongo.ErrNoDocuments, err, "Error should be mongo.ErrNoDocuments")
 }
 
-func (s *MongoUserTestSuite) TestCreate_DuplicateUsername() {
+func (s *UserRepositoryTestSuite) TestCreate_DuplicateUsername() {
 	assert := assert.New(s.T())
 	ctx := context.Background()
 
-	user1 := &domain.User{Username: "duplicate", Password: "pw1", Role: "user"}
-	user2 := &domain.User{Username: "duplicate", Password: "pw2", Role: "user"}
+	user1 := &domain.User{Username: "duplicate", Password: "pw1", Roles: []string{"user"}}
+	user2 := &domain.User{Username: "duplicate", Password: "pw2", Roles: []string{"user"}}
 
 	err := s.userRepo.Create(ctx, user1)
 	assert.NoError(err)
@@ -104,3 +82,131 @@ func (s *MongoUserTestSuite) TestCreate_DuplicateUsername() {
 
 	assert.True(mongo.IsDuplicateKeyError(err), "Error should be a duplicate key error")
 }
+
+func (s *UserRepositoryTestSuite) TestReminderOffsets_NilAndEmptyStayDistinct() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	user := &domain.User{Username: "reminded", Password: "pw", Roles: []string{"user"}}
+	assert.NoError(s.userRepo.Create(ctx, user))
+	found, err := s.userRepo.FindByID(ctx, user.ID)
+	assert.NoError(err)
+	assert.Nil(found.ReminderOffsets)
+
+	found.ReminderOffsets = []time.Duration{time.Hour, -30 * time.Minute}
+	assert.NoError(s.userRepo.Update(ctx, found))
+	found, err = s.userRepo.FindByID(ctx, user.ID)
+	assert.NoError(err)
+	assert.Equal([]time.Duration{time.Hour, -30 * time.Minute}, found.ReminderOffsets)
+
+	// An empty list turns reminders off and must not read back as nil.
+	found.ReminderOffsets = []time.Duration{}
+	assert.NoError(s.userRepo.Update(ctx, found))
+	found, err = s.userRepo.FindByID(ctx, user.ID)
+	assert.NoError(err)
+	assert.NotNil(found.ReminderOffsets)
+	assert.Empty(found.ReminderOffsets)
+}
+
+func (s *UserRepositoryTestSuite) TestList_FiltersAndPages() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	disabledAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, user := range []*domain.User{
+		{Username: "carol", Password: "pw", Roles: []string{domain.RoleAdmin}},
+		{Username: "Alice", Password: "pw", Roles: []string{domain.RoleUser}},
+		{Username: "bob_100%", Password: "pw", Roles: []string{domain.RoleUser}, DisabledAt: disabledAt},
+		{Username: "dave", Password: "pw", Roles: []string{domain.RoleManager, domain.RoleAdmin}},
+	} {
+		assert.NoError(s.userRepo.Create(ctx, user))
+	}
+
+	first, cursor, err := s.userRepo.List(ctx, UserQuery{Limit: 2})
+	assert.NoError(err)
+	second, last, err := s.userRepo.List(ctx, UserQuery{Limit: 2, Cursor: cursor})
+	assert.NoError(err)
+	searched, _, err := s.userRepo.List(ctx, UserQuery{Search: "0%"})
+	assert.NoError(err)
+	admins, _, err := s.userRepo.List(ctx, UserQuery{Role: domain.RoleAdmin})
+	assert.NoError(err)
+	disabled, _, err := s.userRepo.List(ctx, UserQuery{Status: UserStatusDisabled})
+	assert.NoError(err)
+	active, _, err := s.userRepo.List(ctx, UserQuery{Status: UserStatusActive, Search: "A"})
+	assert.NoError(err)
+	_, _, badCursorErr := s.userRepo.List(ctx, UserQuery{Cursor: "!"})
+
+	// --- ASSERT ---
+	usernames := func(users []domain.User) []string {
+		names := make([]string, len(users))
+		for i, user := range users {
+			names[i] = user.Username
+		}
+		return names
+	}
+	assert.Equal([]string{"Alice", "bob_100%"}, usernames(first))
+	assert.Equal([]string{"carol", "dave"}, usernames(second))
+	assert.Empty(last)
+	assert.Equal([]string{"bob_100%"}, usernames(searched), "LIKE wildcards in the search are matched literally")
+	assert.Equal([]string{"carol", "dave"}, usernames(admins))
+	assert.Equal([]string{"bob_100%"}, usernames(disabled))
+	assert.True(disabledAt.Equal(disabled[0].DisabledAt))
+	assert.Equal([]string{"Alice", "carol", "dave"}, usernames(active), "the search ignores case")
+	assert.ErrorIs(badCursorErr, ErrInvalidCursor)
+}
+
+func (s *UserRepositoryTestSuite) TestDisableAndDelete() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	user := &domain.User{Username: "leaving", Password: "pw", Roles: []string{domain.RoleUser}}
+	assert.NoError(s.userRepo.Create(ctx, user))
+	user.DisabledAt = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(s.userRepo.Update(ctx, user))
+	disabled, err := s.userRepo.FindByID(ctx, user.ID)
+	assert.NoError(err)
+	disabledAt := disabled.DisabledAt
+	disabled.DisabledAt = time.Time{}
+	assert.NoError(s.userRepo.Update(ctx, disabled))
+	enabled, err := s.userRepo.FindByID(ctx, user.ID)
+	assert.NoError(err)
+
+	assert.NoError(s.userRepo.Delete(ctx, user.ID))
+	_, findErr := s.userRepo.FindByID(ctx, user.ID)
+	count, err := s.userRepo.Count(ctx)
+	assert.NoError(err)
+
+	// --- ASSERT ---
+	assert.True(user.DisabledAt.Equal(disabledAt))
+	assert.True(enabled.DisabledAt.IsZero())
+	assert.Equal(mongo.ErrNoDocuments, findErr)
+	assert.Zero(count)
+}
+
+func (s *UserRepositoryTestSuite) TestUpdateFields_KeepsOtherFields() {
+	assert := assert.New(s.T())
+	ctx := context.Background()
+
+	user := &domain.User{Username: "admin2", Password: "old", Roles: []string{domain.RoleUser, domain.RoleAdmin}}
+	assert.NoError(s.userRepo.Create(ctx, user))
+	// Two admins read the account, then each changes a different field.
+	demoted, err := s.userRepo.FindByID(ctx, user.ID)
+	assert.NoError(err)
+	disabled, err := s.userRepo.FindByID(ctx, user.ID)
+	assert.NoError(err)
+	disabled.DisabledAt = time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	assert.NoError(s.userRepo.UpdateFields(ctx, disabled, []UserField{UserFieldDisabled}))
+	demoted.Roles = []string{domain.RoleUser}
+	demoted.Password = "ignored"
+	assert.NoError(s.userRepo.UpdateFields(ctx, demoted, []UserField{UserFieldRoles}))
+	found, err := s.userRepo.FindByID(ctx, user.ID)
+	assert.NoError(err)
+	missing := &domain.User{ID: primitive.NewObjectID(), Password: "pw"}
+	missingErr := s.userRepo.UpdateFields(ctx, missing, []UserField{UserFieldPassword})
+
+	// --- ASSERT ---
+	assert.Equal([]string{domain.RoleUser}, found.Roles)
+	assert.True(disabled.DisabledAt.Equal(found.DisabledAt))
+	assert.Equal("old", found.Password)
+	assert.NoError(missingErr)
+}
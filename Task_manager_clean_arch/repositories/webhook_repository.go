@@ -0,0 +1,314 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	datamodels "taskmanager/repositories/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IWebhookRepository stores webhooks and the log of their deliveries.
+type IWebhookRepository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error)
+	// ListByUserID returns the user's webhooks, oldest first.
+	ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Webhook, error)
+	// ListEnabledFor returns the webhooks of the given users and every
+	// all_tasks webhook, leaving out those that are disabled, oldest first.
+	ListEnabledFor(ctx context.Context, userIDs []primitive.ObjectID) ([]domain.Webhook, error)
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	// RecordDeliveryFailure counts a delivery that failed for good against
+	// the webhook and returns the webhook as saved. Once the count reaches
+	// disableAfter the webhook is disabled at the given time, unless it is
+	// disabled already; zero never disables it. Only those two fields are
+	// written, so edits made meanwhile are kept. It returns
+	// mongo.ErrNoDocuments when the webhook is gone.
+	RecordDeliveryFailure(ctx context.Context, id primitive.ObjectID, disableAfter int, at time.Time) (*domain.Webhook, error)
+	// ResetDeliveryFailures clears the count of failed deliveries and
+	// nothing else. It returns mongo.ErrNoDocuments when the webhook is gone.
+	ResetDeliveryFailures(ctx context.Context, id primitive.ObjectID) error
+	// Delete removes the webhook and its deliveries.
+	Delete(ctx context.Context, id primitive.ObjectID) error
+
+	CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id primitive.ObjectID) (*domain.WebhookDelivery, error)
+	// ListDeliveries returns up to limit of the webhook's deliveries, newest first.
+	ListDeliveries(ctx context.Context, webhookID primitive.ObjectID, limit int) ([]domain.WebhookDelivery, error)
+	// ClaimDelivery takes the pending delivery that has been due longest at
+	// now and postpones it to until, so that no other worker takes it while
+	// it is being sent. Deliveries to the webhooks in skip are left alone.
+	// It returns mongo.ErrNoDocuments when none is due.
+	ClaimDelivery(ctx context.Context, now, until time.Time, skip []primitive.ObjectID) (*domain.WebhookDelivery, error)
+	// UpdateDelivery saves the outcome of an attempt at a delivery that was
+	// claimed until claimedUntil. It returns mongo.ErrNoDocuments when the
+	// delivery is gone, or when the claim ran out and it was claimed again.
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery, claimedUntil time.Time) error
+	// DeleteDeliveriesBefore removes the finished deliveries created before
+	// the given time and returns how many there were.
+	DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// mongoWebhookRepository is the concrete implementation.
+type mongoWebhookRepository struct {
+	webhooks   *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+// NewWebhookRepository is the constructor.
+func NewWebhookRepository(db *mongo.Database) IWebhookRepository {
+	webhooks := db.Collection("webhooks")
+	_, _ = webhooks.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "all_tasks", Value: 1}}},
+	})
+	deliveries := db.Collection("webhook_deliveries")
+	_, _ = deliveries.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return &mongoWebhookRepository{webhooks: webhooks, deliveries: deliveries}
+}
+
+func toBsonWebhook(webhook *domain.Webhook) *datamodels.Webhook {
+	return &datamodels.Webhook{
+		ID:               webhook.ID,
+		UserID:           webhook.UserID,
+		URL:              webhook.URL,
+		Secret:           webhook.Secret,
+		Events:           webhook.Events,
+		AllTasks:         webhook.AllTasks,
+		FailedDeliveries: webhook.FailedDeliveries,
+		DisabledAt:       toBsonTime(webhook.DisabledAt),
+		CreatedAt:        webhook.CreatedAt,
+	}
+}
+
+func toDomainWebhook(webhook *datamodels.Webhook) *domain.Webhook {
+	return &domain.Webhook{
+		ID:               webhook.ID,
+		UserID:           webhook.UserID,
+		URL:              webhook.URL,
+		Secret:           webhook.Secret,
+		Events:           webhook.Events,
+		AllTasks:         webhook.AllTasks,
+		FailedDeliveries: webhook.FailedDeliveries,
+		DisabledAt:       toDomainTime(webhook.DisabledAt),
+		CreatedAt:        webhook.CreatedAt,
+	}
+}
+
+func toBsonWebhookDelivery(delivery *domain.WebhookDelivery) *datamodels.WebhookDelivery {
+	return &datamodels.WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastAttemptAt:  toBsonTime(delivery.LastAttemptAt),
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+func toDomainWebhookDelivery(delivery *datamodels.WebhookDelivery) *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Payload:        delivery.Payload,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastAttemptAt:  toDomainTime(delivery.LastAttemptAt),
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
+
+func (r *mongoWebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	result, err := r.webhooks.InsertOne(ctx, toBsonWebhook(webhook))
+	if err != nil {
+		return err
+	}
+	webhook.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoWebhookRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error) {
+	var bsonWebhook datamodels.Webhook
+	if err := r.webhooks.FindOne(ctx, bson.M{"_id": id}).Decode(&bsonWebhook); err != nil {
+		return nil, err
+	}
+	return toDomainWebhook(&bsonWebhook), nil
+}
+
+func (r *mongoWebhookRepository) findWebhooks(ctx context.Context, filter bson.M) ([]domain.Webhook, error) {
+	cursor, err := r.webhooks.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonWebhooks []datamodels.Webhook
+	if err := cursor.All(ctx, &bsonWebhooks); err != nil {
+		return nil, err
+	}
+	webhooks := make([]domain.Webhook, len(bsonWebhooks))
+	for i := range bsonWebhooks {
+		webhooks[i] = *toDomainWebhook(&bsonWebhooks[i])
+	}
+	return webhooks, nil
+}
+
+func (r *mongoWebhookRepository) ListByUserID(ctx context.Context, userID primitive.ObjectID) ([]domain.Webhook, error) {
+	return r.findWebhooks(ctx, bson.M{"user_id": userID})
+}
+
+func (r *mongoWebhookRepository) ListEnabledFor(ctx context.Context, userIDs []primitive.ObjectID) ([]domain.Webhook, error) {
+	if userIDs == nil {
+		userIDs = []primitive.ObjectID{}
+	}
+	return r.findWebhooks(ctx, bson.M{
+		"disabled_at": nil,
+		"$or":         bson.A{bson.M{"user_id": bson.M{"$in": userIDs}}, bson.M{"all_tasks": true}},
+	})
+}
+
+func (r *mongoWebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	result, err := r.webhooks.ReplaceOne(ctx, bson.M{"_id": webhook.ID}, toBsonWebhook(webhook))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoWebhookRepository) RecordDeliveryFailure(ctx context.Context, id primitive.ObjectID, disableAfter int, at time.Time) (*domain.Webhook, error) {
+	// An update pipeline reads the old count and decides whether to disable
+	// in the same atomic step as it adds the failure.
+	failures := bson.D{{Key: "$add", Value: bson.A{"$failed_deliveries", 1}}}
+	disable := bson.D{{Key: "$and", Value: bson.A{
+		disableAfter > 0,
+		bson.D{{Key: "$gte", Value: bson.A{failures, disableAfter}}},
+		bson.D{{Key: "$eq", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$disabled_at", nil}}}, nil}}},
+	}}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: "failed_deliveries", Value: failures},
+		{Key: "disabled_at", Value: bson.D{{Key: "$cond", Value: bson.A{disable, at, "$disabled_at"}}}},
+	}}}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var bsonWebhook datamodels.Webhook
+	if err := r.webhooks.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, opts).Decode(&bsonWebhook); err != nil {
+		return nil, err
+	}
+	return toDomainWebhook(&bsonWebhook), nil
+}
+
+func (r *mongoWebhookRepository) ResetDeliveryFailures(ctx context.Context, id primitive.ObjectID) error {
+	result, err := r.webhooks.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"failed_deliveries": 0}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoWebhookRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.deliveries.DeleteMany(ctx, bson.M{"webhook_id": id}); err != nil {
+		return err
+	}
+	_, err := r.webhooks.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+func (r *mongoWebhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	result, err := r.deliveries.InsertOne(ctx, toBsonWebhookDelivery(delivery))
+	if err != nil {
+		return err
+	}
+	delivery.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *mongoWebhookRepository) GetDelivery(ctx context.Context, id primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	var bsonDelivery datamodels.WebhookDelivery
+	if err := r.deliveries.FindOne(ctx, bson.M{"_id": id}).Decode(&bsonDelivery); err != nil {
+		return nil, err
+	}
+	return toDomainWebhookDelivery(&bsonDelivery), nil
+}
+
+func (r *mongoWebhookRepository) ListDeliveries(ctx context.Context, webhookID primitive.ObjectID, limit int) ([]domain.WebhookDelivery, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.deliveries.Find(ctx, bson.M{"webhook_id": webhookID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var bsonDeliveries []datamodels.WebhookDelivery
+	if err := cursor.All(ctx, &bsonDeliveries); err != nil {
+		return nil, err
+	}
+	deliveries := make([]domain.WebhookDelivery, len(bsonDeliveries))
+	for i := range bsonDeliveries {
+		deliveries[i] = *toDomainWebhookDelivery(&bsonDeliveries[i])
+	}
+	return deliveries, nil
+}
+
+func (r *mongoWebhookRepository) ClaimDelivery(ctx context.Context, now, until time.Time, skip []primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	filter := bson.M{"status": domain.WebhookDeliveryPending, "next_attempt_at": bson.M{"$lte": now}}
+	if len(skip) > 0 {
+		filter["webhook_id"] = bson.M{"$nin": skip}
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+	var bsonDelivery datamodels.WebhookDelivery
+	err := r.deliveries.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"next_attempt_at": until}}, opts).Decode(&bsonDelivery)
+	if err != nil {
+		return nil, err
+	}
+	return toDomainWebhookDelivery(&bsonDelivery), nil
+}
+
+func (r *mongoWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery, claimedUntil time.Time) error {
+	filter := bson.M{"_id": delivery.ID, "next_attempt_at": claimedUntil}
+	result, err := r.deliveries.ReplaceOne(ctx, filter, toBsonWebhookDelivery(delivery))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+func (r *mongoWebhookRepository) DeleteDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.deliveries.DeleteMany(ctx, bson.M{
+		"status":     bson.M{"$ne": domain.WebhookDeliveryPending},
+		"created_at": bson.M{"$lt": before},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
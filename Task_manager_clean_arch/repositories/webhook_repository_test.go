@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWebhookRepository_Webhooks(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			webhooks := backend.open(t).Webhooks
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+			chat := &domain.Webhook{UserID: alice, URL: "https://chat.example.com/hook", Secret: "s1", Events: []string{"task.created"}, CreatedAt: now}
+			ci := &domain.Webhook{UserID: alice, URL: "https://ci.example.com/hook", Secret: "s2", AllTasks: true, CreatedAt: now.Add(time.Minute)}
+			require.NoError(t, webhooks.Create(ctx, ci))
+			require.NoError(t, webhooks.Create(ctx, chat))
+			bobs := &domain.Webhook{UserID: bob, URL: "https://bob.example.com", Secret: "s3", CreatedAt: now}
+			require.NoError(t, webhooks.Create(ctx, bobs))
+			audit := &domain.Webhook{UserID: carol, URL: "https://audit.example.com", Secret: "s4", AllTasks: true, CreatedAt: now.Add(time.Hour)}
+			require.NoError(t, webhooks.Create(ctx, audit))
+
+			listed, err := webhooks.ListByUserID(ctx, alice)
+			require.NoError(t, err)
+			require.Len(t, listed, 2)
+			assert.Equal(t, chat.ID, listed[0].ID)
+			assert.Equal(t, []string{"task.created"}, listed[0].Events)
+			assert.True(t, listed[1].AllTasks)
+			assert.Empty(t, listed[1].Events)
+
+			ci.FailedDeliveries = 5
+			ci.DisabledAt = now.Add(time.Hour)
+			require.NoError(t, webhooks.Update(ctx, ci))
+			err = webhooks.Update(ctx, &domain.Webhook{ID: primitive.NewObjectID(), CreatedAt: now})
+			assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+
+			// --- ASSERT ---
+			found, err := webhooks.GetByID(ctx, ci.ID)
+			require.NoError(t, err)
+			assert.Equal(t, 5, found.FailedDeliveries)
+			assert.True(t, now.Add(time.Hour).Equal(found.DisabledAt))
+			ids := func(webhooks []domain.Webhook) []primitive.ObjectID {
+				ids := []primitive.ObjectID{}
+				for _, webhook := range webhooks {
+					ids = append(ids, webhook.ID)
+				}
+				return ids
+			}
+			enabled, err := webhooks.ListEnabledFor(ctx, []primitive.ObjectID{alice, bob})
+			require.NoError(t, err)
+			assert.Equal(t, []primitive.ObjectID{chat.ID, bobs.ID, audit.ID}, ids(enabled), "disabled webhooks are left out")
+			enabled, err = webhooks.ListEnabledFor(ctx, []primitive.ObjectID{bob})
+			require.NoError(t, err)
+			assert.Equal(t, []primitive.ObjectID{bobs.ID, audit.ID}, ids(enabled))
+			enabled, err = webhooks.ListEnabledFor(ctx, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []primitive.ObjectID{audit.ID}, ids(enabled), "all_tasks webhooks are listed for anyone")
+		})
+	}
+}
+
+func TestWebhookRepository_DeliveryFailures(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			webhooks := backend.open(t).Webhooks
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			webhook := &domain.Webhook{UserID: primitive.NewObjectID(), URL: "https://old.example.com", Secret: "s", CreatedAt: now}
+			require.NoError(t, webhooks.Create(ctx, webhook))
+			stale := *webhook
+
+			first, err := webhooks.RecordDeliveryFailure(ctx, webhook.ID, 2, now)
+			require.NoError(t, err)
+			// An edit saved meanwhile from a copy read earlier.
+			stale.URL = "https://new.example.com"
+			stale.FailedDeliveries = 1
+			require.NoError(t, webhooks.Update(ctx, &stale))
+			second, err := webhooks.RecordDeliveryFailure(ctx, webhook.ID, 2, now.Add(time.Minute))
+			require.NoError(t, err)
+			third, err := webhooks.RecordDeliveryFailure(ctx, webhook.ID, 2, now.Add(time.Hour))
+			require.NoError(t, err)
+			require.NoError(t, webhooks.ResetDeliveryFailures(ctx, webhook.ID))
+			reset, err := webhooks.GetByID(ctx, webhook.ID)
+			require.NoError(t, err)
+			unlimited, err := webhooks.RecordDeliveryFailure(ctx, webhook.ID, 0, now)
+			require.NoError(t, err)
+			_, missingErr := webhooks.RecordDeliveryFailure(ctx, primitive.NewObjectID(), 2, now)
+			missingResetErr := webhooks.ResetDeliveryFailures(ctx, primitive.NewObjectID())
+
+			// --- ASSERT ---
+			assert.Equal(t, 1, first.FailedDeliveries)
+			assert.True(t, first.DisabledAt.IsZero())
+			assert.Equal(t, 2, second.FailedDeliveries)
+			assert.True(t, now.Add(time.Minute).Equal(second.DisabledAt))
+			assert.Equal(t, "https://new.example.com", second.URL)
+			assert.Equal(t, 3, third.FailedDeliveries)
+			assert.True(t, now.Add(time.Minute).Equal(third.DisabledAt), "an earlier disable is kept")
+			assert.Zero(t, reset.FailedDeliveries)
+			assert.True(t, now.Add(time.Minute).Equal(reset.DisabledAt), "resetting the count leaves the webhook disabled")
+			assert.Equal(t, "https://new.example.com", reset.URL)
+			assert.Equal(t, 1, unlimited.FailedDeliveries)
+			assert.ErrorIs(t, missingErr, mongo.ErrNoDocuments)
+			assert.ErrorIs(t, missingResetErr, mongo.ErrNoDocuments)
+		})
+	}
+}
+
+func TestWebhookRepository_Deliveries(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend.name, func(t *testing.T) {
+			webhooks := backend.open(t).Webhooks
+			ctx := context.Background()
+			now := time.Now().UTC().Truncate(time.Millisecond)
+			webhook := &domain.Webhook{UserID: primitive.NewObjectID(), URL: "https://example.com", Secret: "s", CreatedAt: now}
+			require.NoError(t, webhooks.Create(ctx, webhook))
+			newDelivery := func(event string, due time.Time) *domain.WebhookDelivery {
+				delivery := &domain.WebhookDelivery{WebhookID: webhook.ID, EventID: primitive.NewObjectID(), Event: event,
+					Payload: `{"event":"` + event + `"}`, Status: domain.WebhookDeliveryPending, NextAttemptAt: due, CreatedAt: due}
+				require.NoError(t, webhooks.CreateDelivery(ctx, delivery))
+				return delivery
+			}
+			later := newDelivery("task.updated", now.Add(time.Minute))
+			first := newDelivery("task.created", now.Add(-time.Minute))
+			second := newDelivery("task.deleted", now)
+
+			_, err := webhooks.ClaimDelivery(ctx, now, now.Add(time.Hour), []primitive.ObjectID{webhook.ID})
+			assert.ErrorIs(t, err, mongo.ErrNoDocuments, "deliveries to skipped webhooks are not claimed")
+			claimed, err := webhooks.ClaimDelivery(ctx, now, now.Add(time.Hour), []primitive.ObjectID{primitive.NewObjectID()})
+			require.NoError(t, err)
+			assert.Equal(t, first.ID, claimed.ID)
+			assert.Equal(t, `{"event":"task.created"}`, claimed.Payload)
+			assert.True(t, now.Add(time.Hour).Equal(claimed.NextAttemptAt))
+			claimed2, err := webhooks.ClaimDelivery(ctx, now, now.Add(time.Second), nil)
+			require.NoError(t, err)
+			assert.Equal(t, second.ID, claimed2.ID)
+			_, err = webhooks.ClaimDelivery(ctx, now, now.Add(time.Hour), nil)
+			assert.ErrorIs(t, err, mongo.ErrNoDocuments, "claimed deliveries are not due again until their lease ends")
+
+			claimed.Status = domain.WebhookDeliverySucceeded
+			claimed.Attempts = 1
+			claimed.LastAttemptAt = now
+			claimed.ResponseStatus = 204
+			require.NoError(t, webhooks.UpdateDelivery(ctx, claimed, now.Add(time.Hour)))
+			reclaimed, err := webhooks.ClaimDelivery(ctx, now.Add(time.Second), now.Add(time.Hour), nil)
+			require.NoError(t, err)
+			require.Equal(t, second.ID, reclaimed.ID, "the lease on the second delivery ran out")
+			claimed2.Status = domain.WebhookDeliveryFailed
+			err = webhooks.UpdateDelivery(ctx, claimed2, now.Add(time.Second))
+			assert.ErrorIs(t, err, mongo.ErrNoDocuments, "an outcome is not saved once the claim is lost")
+			deleted, err := webhooks.DeleteDeliveriesBefore(ctx, now)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), deleted, "only finished deliveries are pruned")
+
+			// --- ASSERT ---
+			listed, err := webhooks.ListDeliveries(ctx, webhook.ID, 10)
+			require.NoError(t, err)
+			require.Len(t, listed, 2)
+			assert.Equal(t, []primitive.ObjectID{later.ID, second.ID}, []primitive.ObjectID{listed[0].ID, listed[1].ID})
+			assert.Equal(t, domain.WebhookDeliveryPending, listed[1].Status)
+			_, err = webhooks.GetDelivery(ctx, first.ID)
+			assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+			limited, err := webhooks.ListDeliveries(ctx, webhook.ID, 1)
+			require.NoError(t, err)
+			assert.Len(t, limited, 1)
+
+			require.NoError(t, webhooks.Delete(ctx, webhook.ID))
+			_, err = webhooks.GetDelivery(ctx, later.ID)
+			assert.ErrorIs(t, err, mongo.ErrNoDocuments, "deleting a webhook deletes its deliveries")
+		})
+	}
+}
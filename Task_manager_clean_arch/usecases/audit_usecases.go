@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 200
+)
+
+// ErrInvalidAuditQuery is returned when an audit log query is malformed.
+var ErrInvalidAuditQuery = errors.New("invalid audit query")
+
+type IAuditUsecase interface {
+	ListAuditEntries(ctx context.Context, query repositories.AuditQuery) ([]domain.AuditEntry, string, error)
+}
+
+type auditUsecase struct {
+	auditRepo repositories.IAuditRepository
+}
+
+func NewAuditUsecase(auditRepo repositories.IAuditRepository) IAuditUsecase {
+	return &auditUsecase{auditRepo: auditRepo}
+}
+
+// ListAuditEntries returns one page of the whole audit log, newest first.
+func (uc *auditUsecase) ListAuditEntries(ctx context.Context, query repositories.AuditQuery) ([]domain.AuditEntry, string, error) {
+	return listAuditEntries(ctx, uc.auditRepo, query)
+}
+
+// listAuditEntries applies the page size limits and validates the time range
+// before querying the audit log.
+func listAuditEntries(ctx context.Context, repo repositories.IAuditRepository, query repositories.AuditQuery) ([]domain.AuditEntry, string, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultAuditPageSize
+	}
+	if query.Limit > MaxAuditPageSize {
+		query.Limit = MaxAuditPageSize
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, "", fmt.Errorf("%w: time range is empty", ErrInvalidAuditQuery)
+	}
+
+	entries, next, err := repo.List(ctx, query)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidAuditQuery, err)
+	}
+	return entries, next, err
+}
+
+// newTaskAuditEntry describes a change to a task. before is nil for a create
+// and after is nil for a delete. Timestamps are kept to the millisecond so
+// they read back identically from every storage backend.
+func newTaskAuditEntry(action string, before, after *domain.Task, actorID primitive.ObjectID) *domain.AuditEntry {
+	entityID := primitive.NilObjectID
+	if after != nil {
+		entityID = after.ID
+	} else if before != nil {
+		entityID = before.ID
+	}
+	return &domain.AuditEntry{
+		EntityType: domain.AuditEntityTask,
+		EntityID:   entityID,
+		Action:     action,
+		ActorID:    actorID,
+		Timestamp:  time.Now().UTC().Truncate(time.Millisecond),
+		Changes:    taskChanges(before, after),
+	}
+}
+
+// taskChanges lists the audited fields whose value differs between the two
+// versions of a task. A nil task counts as having every field empty.
+func taskChanges(before, after *domain.Task) []domain.FieldChange {
+	beforeFields, afterFields := auditedTaskFields(before), auditedTaskFields(after)
+	var changes []domain.FieldChange
+	for i, field := range beforeFields {
+		if field.value != afterFields[i].value {
+			changes = append(changes, domain.FieldChange{Field: field.name, Before: field.value, After: afterFields[i].value})
+		}
+	}
+	return changes
+}
+
+type auditedField struct {
+	name  string
+	value string
+}
+
+// auditedTaskFields returns the user-editable fields of a task as strings,
+// always in the same order. Collaborators read as "userID:role" pairs and
+// recurrence as "RRULE (time zone)".
+func auditedTaskFields(task *domain.Task) []auditedField {
+	if task == nil {
+		task = &domain.Task{}
+	}
+	dueDate := ""
+	if !task.Duedate.IsZero() {
+		dueDate = task.Duedate.UTC().Format(time.RFC3339Nano)
+	}
+	parentID := ""
+	if !task.ParentID.IsZero() {
+		parentID = task.ParentID.Hex()
+	}
+	blockedBy := make([]string, len(task.BlockedBy))
+	for i, id := range task.BlockedBy {
+		blockedBy[i] = id.Hex()
+	}
+	assigneeID := ""
+	if !task.AssigneeID.IsZero() {
+		assigneeID = task.AssigneeID.Hex()
+	}
+	collaborators := make([]string, len(task.Collaborators))
+	for i, c := range task.Collaborators {
+		collaborators[i] = c.UserID.Hex() + ":" + c.Role
+	}
+	projectID := ""
+	if !task.ProjectID.IsZero() {
+		projectID = task.ProjectID.Hex()
+	}
+	recurrence := ""
+	if task.Recurrence != nil {
+		recurrence = task.Recurrence.Rule + " (" + task.Recurrence.TimeZone + ")"
+	}
+	return []auditedField{
+		{name: "title", value: task.Title},
+		{name: "description", value: task.Description},
+		{name: "due_date", value: dueDate},
+		{name: "status", value: task.Status},
+		{name: "parent_id", value: parentID},
+		{name: "blocked_by", value: strings.Join(blockedBy, ",")},
+		{name: "assignee_id", value: assigneeID},
+		{name: "collaborators", value: strings.Join(collaborators, ",")},
+		{name: "recurrence", value: recurrence},
+		{name: "tags", value: strings.Join(task.Tags, ",")},
+		{name: "project_id", value: projectID},
+	}
+}
@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/repositories"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// MaxCalendarFeeds caps the feeds one user can have at a time.
+	MaxCalendarFeeds          = 20
+	MaxCalendarFeedNameLength = 100
+	DefaultCalendarFeedName   = "Calendar"
+)
+
+var (
+	// ErrInvalidCalendarFeed is returned for a feed name that cannot be used,
+	// and when the user already has MaxCalendarFeeds feeds.
+	ErrInvalidCalendarFeed = errors.New("invalid calendar feed")
+	// ErrCalendarFeedNotFound is returned for a feed that does not exist or
+	// belongs to another user.
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+)
+
+type ICalendarUsecase interface {
+	// CreateFeed creates a feed and returns it with its token. Only a hash
+	// of the token is kept, so it cannot be shown again.
+	CreateFeed(ctx context.Context, name string, userID primitive.ObjectID) (*domain.CalendarFeed, string, error)
+	ListFeeds(ctx context.Context, userID primitive.ObjectID) ([]domain.CalendarFeed, error)
+	// RevokeFeed deletes one of the user's feeds; its URL stops working.
+	RevokeFeed(ctx context.Context, feedID string, userID primitive.ObjectID) error
+	// ResolveFeedToken returns the user a feed token belongs to, or a zero ID
+	// if the token is unknown or revoked, or the user's account is disabled.
+	ResolveFeedToken(ctx context.Context, token string) (primitive.ObjectID, error)
+	// FeedTasks passes the tasks the user can see that have a due date to
+	// write, a page at a time, soonest first.
+	FeedTasks(ctx context.Context, userID primitive.ObjectID, write func([]domain.Task) error) error
+}
+
+type calendarUsecase struct {
+	feedRepo    repositories.ICalendarFeedRepository
+	userRepo    repositories.IUserRepository
+	taskUsecase ITaskUsecase
+}
+
+func NewCalendarUsecase(feedRepo repositories.ICalendarFeedRepository, userRepo repositories.IUserRepository, taskUsecase ITaskUsecase) ICalendarUsecase {
+	return &calendarUsecase{feedRepo: feedRepo, userRepo: userRepo, taskUsecase: taskUsecase}
+}
+
+func (uc *calendarUsecase) CreateFeed(ctx context.Context, name string, userID primitive.ObjectID) (*domain.CalendarFeed, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = DefaultCalendarFeedName
+	}
+	if utf8.RuneCountInString(name) > MaxCalendarFeedNameLength {
+		return nil, "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidCalendarFeed, MaxCalendarFeedNameLength)
+	}
+	existing, err := uc.feedRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= MaxCalendarFeeds {
+		return nil, "", fmt.Errorf("%w: no more than %d feeds; revoke one first", ErrInvalidCalendarFeed, MaxCalendarFeeds)
+	}
+
+	token, err := infrastructure.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+	feed := &domain.CalendarFeed{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: infrastructure.HashToken(token),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := uc.feedRepo.Create(ctx, feed); err != nil {
+		return nil, "", err
+	}
+	return feed, token, nil
+}
+
+func (uc *calendarUsecase) ListFeeds(ctx context.Context, userID primitive.ObjectID) ([]domain.CalendarFeed, error) {
+	return uc.feedRepo.ListByUserID(ctx, userID)
+}
+
+func (uc *calendarUsecase) RevokeFeed(ctx context.Context, feedID string, userID primitive.ObjectID) error {
+	id, err := primitive.ObjectIDFromHex(feedID)
+	if err != nil {
+		return ErrCalendarFeedNotFound
+	}
+	feed, err := uc.feedRepo.GetByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) || err == nil && feed.UserID != userID {
+		return ErrCalendarFeedNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return uc.feedRepo.Delete(ctx, id)
+}
+
+func (uc *calendarUsecase) ResolveFeedToken(ctx context.Context, token string) (primitive.ObjectID, error) {
+	if token == "" {
+		return primitive.NilObjectID, nil
+	}
+	feed, err := uc.feedRepo.FindByTokenHash(ctx, infrastructure.HashToken(token))
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, nil
+	}
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	// Feeds of a disabled account stop with its tokens, and work again when
+	// it is enabled.
+	user, err := uc.userRepo.FindByID(ctx, feed.UserID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, nil
+	}
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	if !user.DisabledAt.IsZero() {
+		return primitive.NilObjectID, nil
+	}
+	return feed.UserID, nil
+}
+
+func (uc *calendarUsecase) FeedTasks(ctx context.Context, userID primitive.ObjectID, write func([]domain.Task) error) error {
+	query := repositories.TaskQuery{SortBy: repositories.SortByDueDate}
+	return uc.taskUsecase.ExportTasks(ctx, query, userID, func(tasks []domain.Task) error {
+		due := make([]domain.Task, 0, len(tasks))
+		for _, task := range tasks {
+			if !task.Duedate.IsZero() {
+				due = append(due, task)
+			}
+		}
+		return write(due)
+	})
+}
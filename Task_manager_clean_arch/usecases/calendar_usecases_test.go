@@ -0,0 +1,107 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCalendarFeeds_TokenWorksUntilRevoked(t *testing.T) {
+	f := newFixture(t)
+	usecase := NewCalendarUsecase(f.repos.CalendarFeeds, f.repos.Users, f.newTaskUsecase())
+	ctx := context.Background()
+	ownerID, otherID := f.user, f.manager
+
+	feed, token, err := usecase.CreateFeed(ctx, "  ", ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCalendarFeedName, feed.Name)
+	assert.NotEmpty(t, token)
+	assert.NotContains(t, feed.TokenHash, token)
+	_, _, err = usecase.CreateFeed(ctx, string(make([]rune, MaxCalendarFeedNameLength+1)), ownerID)
+	assert.ErrorIs(t, err, ErrInvalidCalendarFeed)
+
+	resolved, err := usecase.ResolveFeedToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, ownerID, resolved)
+	// Another user cannot revoke the feed.
+	assert.ErrorIs(t, usecase.RevokeFeed(ctx, feed.ID.Hex(), otherID), ErrCalendarFeedNotFound)
+	assert.ErrorIs(t, usecase.RevokeFeed(ctx, "not-an-id", ownerID), ErrCalendarFeedNotFound)
+
+	require.NoError(t, usecase.RevokeFeed(ctx, feed.ID.Hex(), ownerID))
+
+	// --- ASSERT ---
+	resolved, err = usecase.ResolveFeedToken(ctx, token)
+	require.NoError(t, err)
+	assert.True(t, resolved.IsZero())
+	feeds, err := usecase.ListFeeds(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, feeds)
+}
+
+func TestCalendarFeeds_FeedTasksHaveDueDates(t *testing.T) {
+	f := newFixture(t)
+	taskUsecase := f.newTaskUsecase()
+	usecase := NewCalendarUsecase(f.repos.CalendarFeeds, f.repos.Users, taskUsecase)
+	ctx := context.Background()
+	ownerID := f.user
+	due := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	for _, task := range []*domain.Task{
+		{Title: "Later", Status: StatusPending, Duedate: due.AddDate(0, 0, 1)},
+		{Title: "Someday", Status: StatusPending},
+		{Title: "Sooner", Status: StatusPending, Duedate: due},
+	} {
+		_, err := taskUsecase.CreateTask(ctx, task, ownerID)
+		require.NoError(t, err)
+	}
+
+	var titles []string
+	err := usecase.FeedTasks(ctx, ownerID, func(tasks []domain.Task) error {
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return nil
+	})
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Sooner", "Later"}, titles)
+}
+
+func TestCalendarFeeds_TokenStopsWithAccount(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		close func(ctx context.Context, userAdmin IUserAdminUsecase, userID, adminID primitive.ObjectID) error
+	}{
+		{"disabled", func(ctx context.Context, userAdmin IUserAdminUsecase, userID, adminID primitive.ObjectID) error {
+			_, err := userAdmin.DisableUser(ctx, userID.Hex(), adminID)
+			return err
+		}},
+		{"deleted", func(ctx context.Context, userAdmin IUserAdminUsecase, userID, adminID primitive.ObjectID) error {
+			_, err := userAdmin.DeleteUser(ctx, userID.Hex(), "", adminID)
+			return err
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			usecase := NewCalendarUsecase(f.repos.CalendarFeeds, f.repos.Users, f.newTaskUsecase())
+			ctx := context.Background()
+			_, token, err := usecase.CreateFeed(ctx, "Phone", f.user)
+			require.NoError(t, err)
+			resolved, err := usecase.ResolveFeedToken(ctx, token)
+			require.NoError(t, err)
+			require.Equal(t, f.user, resolved)
+
+			require.NoError(t, tc.close(ctx, f.newUserAdminUsecase(), f.user, f.admin))
+
+			// --- ASSERT ---
+			resolved, err = usecase.ResolveFeedToken(ctx, token)
+			require.NoError(t, err)
+			assert.True(t, resolved.IsZero())
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/mocks"
+	"taskmanager/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fixture is what the usecase tests build on: in-memory repositories with
+// the built-in roles, one user holding each of them, and a task usecase.
+// Options add what a test needs on top.
+type fixture struct {
+	repos   *repositories.Repositories
+	roles   IRoleUsecase
+	admin   primitive.ObjectID
+	manager primitive.ObjectID
+	user    primitive.ObjectID
+	// users holds the ID of every user added, by username.
+	users map[string]primitive.ObjectID
+	tasks ITaskUsecase
+	// task is the user's task added by withTask or withReminders.
+	task *domain.Task
+
+	// Set by withReminders.
+	reminders IReminderUsecase
+	notifier  *mocks.INotifier
+	// Set by withProject.
+	projects IProjectUsecase
+	project  *domain.Project
+	// Set by withWebhooks.
+	webhooks IWebhookUsecase
+	sender   *mocks.IWebhookSender
+	// Set by withSessions.
+	accounts IUserUsecase
+	sessions []*TokenPair
+	// loginClock is when logins through newThrottledUserUsecase are made.
+	loginClock time.Time
+}
+
+// fixtureOption adds to the fixture. Options run in order, after the
+// defaults are in place.
+type fixtureOption func(t *testing.T, f *fixture)
+
+func newFixture(t *testing.T, opts ...fixtureOption) *fixture {
+	f := &fixture{repos: repositories.NewMemoryRepositories(), users: map[string]primitive.ObjectID{}}
+	f.roles = NewRoleUsecase(f.repos.Roles, f.repos.Users, f.repos.Audit)
+	require.NoError(t, f.roles.EnsureBuiltInRoles(context.Background()))
+	f.admin = f.addUser(t, domain.RoleAdmin, domain.RoleAdmin)
+	f.manager = f.addUser(t, domain.RoleManager, domain.RoleManager)
+	f.user = f.addUser(t, domain.RoleUser)
+	f.tasks = f.newTaskUsecase()
+	for _, opt := range opts {
+		opt(t, f)
+	}
+	return f
+}
+
+// withTask has the user create a pending task with the title.
+func withTask(title string) fixtureOption {
+	return func(t *testing.T, f *fixture) {
+		task, err := f.tasks.CreateTask(context.Background(), &domain.Task{Title: title, Status: StatusPending}, f.user)
+		require.NoError(t, err)
+		f.task = task
+	}
+}
+
+// addUser stores a user with the password "pw" in name only, and the user
+// role unless other roles are given.
+func (f *fixture) addUser(t *testing.T, username string, roles ...string) primitive.ObjectID {
+	if len(roles) == 0 {
+		roles = []string{domain.RoleUser}
+	}
+	user := &domain.User{Username: username, Password: "pw", Roles: roles}
+	require.NoError(t, f.repos.Users.Create(context.Background(), user))
+	f.users[username] = user.ID
+	return user.ID
+}
+
+// newFakePasswordService hashes a password by prefixing it, so tests that
+// log in do not spend their time in bcrypt.
+func newFakePasswordService() *mocks.IPasswordService {
+	passwords := new(mocks.IPasswordService)
+	passwords.On("HashPassword", mock.Anything).Return(func(password string) (string, error) {
+		return "hashed:" + password, nil
+	})
+	passwords.On("CheckPasswordHash", mock.Anything, mock.Anything).Return(func(password, hash string) bool {
+		return hash == "hashed:"+password
+	})
+	return passwords
+}
+
+// setPassword gives a user a fake hash of password, for tests that log in.
+func (f *fixture) setPassword(t *testing.T, userID primitive.ObjectID, password string) {
+	ctx := context.Background()
+	hash, err := newFakePasswordService().HashPassword(password)
+	require.NoError(t, err)
+	user, err := f.repos.Users.FindByID(ctx, userID)
+	require.NoError(t, err)
+	user.Password = hash
+	require.NoError(t, f.repos.Users.Update(ctx, user))
+}
+
+func (f *fixture) newTaskUsecase(opts ...TaskUsecaseOption) ITaskUsecase {
+	return NewTaskUsecase(f.repos.Tasks, f.repos.Users, f.repos.Audit, f.repos.Tags, f.repos.Projects, opts...)
+}
+
+func (f *fixture) newUserAdminUsecase() IUserAdminUsecase {
+	return NewUserAdminUsecase(f.repos, infrastructure.NewPasswordService(), f.roles)
+}
+
+// newUserUsecase signs tokens with a test secret and checks the hashes
+// setPassword gives.
+func (f *fixture) newUserUsecase(t *testing.T, opts ...UserOption) IUserUsecase {
+	t.Setenv("JWT_SECRET", "test-secret")
+	return NewUserUsecase(f.repos.Users, f.repos.Tokens, newFakePasswordService(), infrastructure.NewJWTService(), opts...)
+}
+
+// racingUserRepository runs a hook once, right after the first listing or
+// lookup of users has been read, as if another request's change landed just
+// then.
+type racingUserRepository struct {
+	repositories.IUserRepository
+	afterList, afterFind func()
+}
+
+func (r *racingUserRepository) List(ctx context.Context, query repositories.UserQuery) ([]domain.User, string, error) {
+	users, next, err := r.IUserRepository.List(ctx, query)
+	if race := r.afterList; race != nil {
+		r.afterList = nil
+		race()
+	}
+	return users, next, err
+}
+
+func (r *racingUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	user, err := r.IUserRepository.FindByID(ctx, id)
+	if race := r.afterFind; race != nil {
+		r.afterFind = nil
+		race()
+	}
+	return user, err
+}
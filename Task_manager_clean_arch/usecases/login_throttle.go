@@ -0,0 +1,215 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrTooManyLoginAttempts is returned while logins for a username or from a
+// client address must wait. It looks the same whether or not the username
+// exists.
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts; try again later")
+
+// LoginThrottledError is ErrTooManyLoginAttempts with how long to wait.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string { return ErrTooManyLoginAttempts.Error() }
+
+func (e *LoginThrottledError) Unwrap() error { return ErrTooManyLoginAttempts }
+
+// LoginLimit is how many failed logins in a row one username or one client
+// address is allowed.
+type LoginLimit struct {
+	// FreeAttempts can fail without making the next attempt wait.
+	FreeAttempts int
+	// LockoutThreshold failures lock logins out for the policy's
+	// LockoutDuration. Zero never locks out.
+	LockoutThreshold int
+}
+
+// LoginPolicy sets how failed logins slow down the attempts after them.
+type LoginPolicy struct {
+	Username LoginLimit
+	// IP allows more failures than Username, since many users can share
+	// one address.
+	IP LoginLimit
+	// BaseDelay is the wait after the first failure past the free attempts.
+	// It doubles with every further failure, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutDuration is how long a lockout lasts. Failures are forgotten
+	// once this long has passed since the last one.
+	LockoutDuration time.Duration
+}
+
+// DefaultLoginPolicy allows 3 quick failures per username, then waits of 1s
+// doubling up to a minute, and a 15 minute lockout at 10 failures. Client
+// addresses get 10 quick failures and are locked out at 50.
+func DefaultLoginPolicy() LoginPolicy {
+	return LoginPolicy{
+		Username:        LoginLimit{FreeAttempts: 3, LockoutThreshold: 10},
+		IP:              LoginLimit{FreeAttempts: 10, LockoutThreshold: 50},
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 15 * time.Minute,
+	}
+}
+
+// lockedOut reports whether the given number of failures in a row locks
+// logins out.
+func (p LoginPolicy) lockedOut(failures int, limit LoginLimit) bool {
+	return limit.LockoutThreshold > 0 && failures >= limit.LockoutThreshold
+}
+
+// wait is how long the attempt after the given number of failures in a
+// row must wait.
+func (p LoginPolicy) wait(failures int, limit LoginLimit) time.Duration {
+	if p.lockedOut(failures, limit) {
+		return p.LockoutDuration
+	}
+	if failures <= limit.FreeAttempts {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := limit.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
+
+// WithLoginThrottle counts failed logins in attempts and makes further
+// ones wait as policy says. Without it logins are never throttled.
+func WithLoginThrottle(attempts repositories.ILoginAttemptRepository, policy LoginPolicy) UserOption {
+	return func(uc *userUsecase) {
+		uc.loginAttempts = attempts
+		uc.loginPolicy = policy
+	}
+}
+
+// usernameLoginKey counts the failures for a username, whether or not a
+// user has it, so that lockouts do not reveal which usernames exist.
+func usernameLoginKey(username string) string {
+	return "username:" + username
+}
+
+func ipLoginKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+// loginCounter is one count of failed logins a login attempt adds to.
+type loginCounter struct {
+	key      string
+	limit    LoginLimit
+	username bool
+}
+
+// loginCounters returns the counts a login attempt is checked against, or
+// nil when logins are not throttled.
+func (uc *userUsecase) loginCounters(username, clientIP string) []loginCounter {
+	if uc.loginAttempts == nil {
+		return nil
+	}
+	counters := []loginCounter{{key: usernameLoginKey(username), limit: uc.loginPolicy.Username, username: true}}
+	if clientIP != "" {
+		counters = append(counters, loginCounter{key: ipLoginKey(clientIP), limit: uc.loginPolicy.IP})
+	}
+	return counters
+}
+
+// checkLoginAllowed fails with a *LoginThrottledError while any of the
+// counters must wait.
+func (uc *userUsecase) checkLoginAllowed(ctx context.Context, counters []loginCounter, now time.Time) error {
+	var wait time.Duration
+	for _, counter := range counters {
+		attempts, err := uc.loginAttempts.Find(ctx, counter.key)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		wait = max(wait, attempts.LockedUntil.Sub(now))
+	}
+	if wait > 0 {
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// reserveLoginAttempt counts the attempt as failed before its password is
+// checked, so concurrent guesses cannot all be checked before any of them
+// is counted. It returns the counts in the order of counters, or fails with
+// a *LoginThrottledError when earlier attempts have used up the ones before
+// the lockout; the refused attempt stays counted and locks logins out.
+func (uc *userUsecase) reserveLoginAttempt(ctx context.Context, counters []loginCounter, now time.Time) ([]int, error) {
+	failures := make([]int, len(counters))
+	var refused bool
+	for i, counter := range counters {
+		attempts, err := uc.loginAttempts.RecordFailure(ctx, counter.key, now, uc.loginPolicy.LockoutDuration)
+		if err != nil {
+			return nil, err
+		}
+		failures[i] = attempts.Failures
+		if !uc.loginPolicy.lockedOut(attempts.Failures-1, counter.limit) {
+			continue
+		}
+		refused = true
+		if err := uc.loginAttempts.Lock(ctx, counter.key, now.Add(uc.loginPolicy.LockoutDuration)); err != nil {
+			return nil, err
+		}
+	}
+	if refused {
+		return nil, &LoginThrottledError{RetryAfter: uc.loginPolicy.LockoutDuration}
+	}
+	return failures, nil
+}
+
+// recordLoginFailure makes the next attempt wait when a counter reserved
+// by reserveLoginAttempt has run out of free attempts. user is nil when no
+// user has the username; a lockout of an existing user is recorded in the
+// audit log as made by no one.
+func (uc *userUsecase) recordLoginFailure(ctx context.Context, counters []loginCounter, failures []int, user *domain.User, now time.Time) error {
+	for i, counter := range counters {
+		wait := uc.loginPolicy.wait(failures[i], counter.limit)
+		if wait == 0 {
+			continue
+		}
+		until := now.Add(wait)
+		if err := uc.loginAttempts.Lock(ctx, counter.key, until); err != nil {
+			return err
+		}
+		if !counter.username || user == nil || uc.auditRepo == nil || !uc.loginPolicy.lockedOut(failures[i], counter.limit) {
+			continue
+		}
+		if err := uc.auditRepo.Append(ctx, newUserAuditEntry(domain.AuditActionLock, user.ID, primitive.NilObjectID,
+			domain.FieldChange{Field: "locked_until", Before: "", After: until.Format(time.RFC3339)})); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forgetLoginAttempt takes back an attempt reserveLoginAttempt counted once
+// its password proved right. The username's failures are forgotten. The
+// client address keeps the failures counted before, or one account the
+// attacker holds would let them reset the count between guesses.
+func (uc *userUsecase) forgetLoginAttempt(ctx context.Context, counters []loginCounter) error {
+	for _, counter := range counters {
+		forget := uc.loginAttempts.UndoFailure
+		if counter.username {
+			forget = uc.loginAttempts.Delete
+		}
+		if err := forget(ctx, counter.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
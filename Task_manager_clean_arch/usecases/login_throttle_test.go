@@ -0,0 +1,230 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// testLoginPolicy allows two quick failures per username, then waits of a
+// minute doubling up to ten, and an hour's lockout at the fifth failure.
+var testLoginPolicy = LoginPolicy{
+	Username:        LoginLimit{FreeAttempts: 2, LockoutThreshold: 5},
+	IP:              LoginLimit{FreeAttempts: 3, LockoutThreshold: 6},
+	BaseDelay:       time.Minute,
+	MaxDelay:        10 * time.Minute,
+	LockoutDuration: time.Hour,
+}
+
+// loginNow is the fixed time throttled logins are made at.
+var loginNow = time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+// newThrottledUserUsecase gives the fixture's user the password "pw" and
+// returns a user usecase that throttles logins with testLoginPolicy. Its
+// clock starts at loginNow and moves only with waitOut.
+func (f *fixture) newThrottledUserUsecase(t *testing.T) IUserUsecase {
+	f.setPassword(t, f.user, "pw")
+	f.loginClock = loginNow
+	return f.newUserUsecase(t, WithUserAuditLog(f.repos.Audit), WithLoginThrottle(f.repos.LoginAttempts, testLoginPolicy),
+		WithUserClock(func() time.Time { return f.loginClock }))
+}
+
+// waitOut moves the clock of newThrottledUserUsecase to the end of a login
+// key's wait.
+func (f *fixture) waitOut(t *testing.T, key string) {
+	attempts, err := f.repos.LoginAttempts.Find(context.Background(), key)
+	require.NoError(t, err)
+	if attempts.LockedUntil.After(f.loginClock) {
+		f.loginClock = attempts.LockedUntil
+	}
+}
+
+// retryAfter returns how long a throttled login said to wait, or zero.
+func retryAfter(err error) time.Duration {
+	if throttled, ok := err.(*LoginThrottledError); ok {
+		return throttled.RetryAfter
+	}
+	return 0
+}
+
+func TestLoginPolicy_Wait(t *testing.T) {
+	limit := testLoginPolicy.Username
+
+	// --- ASSERT ---
+	for failures, want := range []time.Duration{0, 0, 0, time.Minute, 2 * time.Minute, time.Hour, time.Hour} {
+		assert.Equal(t, want, testLoginPolicy.wait(failures, limit), "after %d failures", failures)
+	}
+	slow := LoginPolicy{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	assert.Equal(t, 5*time.Second, slow.wait(40, LoginLimit{}), "no lockout threshold, and the delay is capped")
+}
+
+// TestLogin_ProgressiveDelaysAndLockout fails to log in until the username
+// is locked out, and expects a username no one has to behave the same.
+func TestLogin_ProgressiveDelaysAndLockout(t *testing.T) {
+	f := newFixture(t)
+	admin, users := f.newUserAdminUsecase(), f.newThrottledUserUsecase(t)
+	ctx := context.Background()
+	// Each failure past the free ones is followed by the right password,
+	// which must be turned away without being checked.
+	failUntilLocked := func(username string) []error {
+		var errs []error
+		for i := 1; i <= testLoginPolicy.Username.LockoutThreshold; i++ {
+			_, err := users.Login(ctx, username, "wrong", "")
+			errs = append(errs, err)
+			if i <= testLoginPolicy.Username.FreeAttempts {
+				continue
+			}
+			_, err = users.Login(ctx, username, "pw", "")
+			errs = append(errs, err)
+			if i < testLoginPolicy.Username.LockoutThreshold {
+				f.waitOut(t, usernameLoginKey(username))
+			}
+		}
+		return errs
+	}
+
+	userErrs := failUntilLocked(domain.RoleUser)
+	ghostErrs := failUntilLocked("ghost")
+	_, err := admin.UnlockUser(ctx, f.user.Hex(), f.admin)
+	require.NoError(t, err)
+	_, unlockedErr := users.Login(ctx, domain.RoleUser, "pw", "")
+	history, _, err := f.repos.Audit.List(ctx, repositories.AuditQuery{EntityID: f.user, Limit: 10})
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	require.Len(t, userErrs, 8)
+	for _, i := range []int{0, 1, 2, 4, 6} {
+		assert.ErrorIs(t, userErrs[i], ErrInvalidCredentials, "attempt %d", i)
+	}
+	for i, wait := range map[int]time.Duration{3: time.Minute, 5: 2 * time.Minute, 7: time.Hour} {
+		assert.ErrorIs(t, userErrs[i], ErrTooManyLoginAttempts, "attempt %d", i)
+		assert.Equal(t, wait, retryAfter(userErrs[i]), "attempt %d", i)
+	}
+	require.Len(t, ghostErrs, len(userErrs))
+	for i := range userErrs {
+		assert.Equal(t, userErrs[i].Error(), ghostErrs[i].Error(), "attempt %d", i)
+		assert.Equal(t, retryAfter(userErrs[i]), retryAfter(ghostErrs[i]), "attempt %d", i)
+	}
+	assert.NoError(t, unlockedErr)
+	require.Len(t, history, 2)
+	assert.Equal(t, domain.AuditActionUnlock, history[0].Action)
+	assert.Equal(t, f.admin, history[0].ActorID)
+	assert.Equal(t, domain.AuditActionLock, history[1].Action)
+	assert.True(t, history[1].ActorID.IsZero())
+}
+
+// TestLogin_ConcurrentGuessesStopAtTheLockout makes many wrong guesses at
+// once and expects no more passwords to be checked than the lockout allows.
+func TestLogin_ConcurrentGuessesStopAtTheLockout(t *testing.T) {
+	f := newFixture(t)
+	f.setPassword(t, f.user, "pw")
+	passwords := newFakePasswordService()
+	users := NewUserUsecase(f.repos.Users, f.repos.Tokens, passwords, nil, WithLoginThrottle(f.repos.LoginAttempts, testLoginPolicy),
+		WithUserClock(func() time.Time { return loginNow }))
+	ctx := context.Background()
+
+	errs := make([]error, 50)
+	var guesses sync.WaitGroup
+	for i := range errs {
+		guesses.Add(1)
+		go func() {
+			defer guesses.Done()
+			_, errs[i] = users.Login(ctx, domain.RoleUser, "wrong", "")
+		}()
+	}
+	guesses.Wait()
+	_, lockedErr := users.Login(ctx, domain.RoleUser, "pw", "")
+
+	// --- ASSERT ---
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrTooManyLoginAttempts), "unexpected error %v", err)
+	}
+	checked := 0
+	for _, call := range passwords.Calls {
+		if call.Method == "CheckPasswordHash" {
+			checked++
+		}
+	}
+	assert.LessOrEqual(t, checked, testLoginPolicy.Username.LockoutThreshold)
+	assert.ErrorIs(t, lockedErr, ErrTooManyLoginAttempts)
+}
+
+// TestLogin_SuccessForgetsUsernameFailures logs in between failures and
+// expects the free attempts to start over.
+func TestLogin_SuccessForgetsUsernameFailures(t *testing.T) {
+	users := newFixture(t).newThrottledUserUsecase(t)
+	ctx := context.Background()
+
+	var errs []error
+	for _, password := range []string{"wrong", "wrong", "pw", "wrong", "wrong", "pw"} {
+		_, err := users.Login(ctx, domain.RoleUser, password, "")
+		errs = append(errs, err)
+	}
+
+	// --- ASSERT ---
+	assert.NoError(t, errs[2])
+	assert.NoError(t, errs[5])
+}
+
+// TestLogin_ThrottlesClientAddress guesses many usernames from one address
+// and expects the address to be locked out, but not other addresses.
+func TestLogin_ThrottlesClientAddress(t *testing.T) {
+	f := newFixture(t)
+	users := f.newThrottledUserUsecase(t)
+	ctx := context.Background()
+
+	for i := range testLoginPolicy.IP.LockoutThreshold {
+		_, err := users.Login(ctx, "guess-"+string(rune('a'+i)), "wrong", "203.0.113.7")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+		f.waitOut(t, ipLoginKey("203.0.113.7"))
+	}
+	require.NoError(t, f.repos.LoginAttempts.Lock(ctx, ipLoginKey("203.0.113.7"), f.loginClock.Add(time.Hour)))
+	_, lockedErr := users.Login(ctx, domain.RoleUser, "pw", "203.0.113.7")
+	_, otherErr := users.Login(ctx, domain.RoleUser, "pw", "198.51.100.1")
+	attempts, err := f.repos.LoginAttempts.Find(ctx, ipLoginKey("203.0.113.7"))
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, lockedErr, ErrTooManyLoginAttempts)
+	assert.NoError(t, otherErr)
+	assert.Equal(t, testLoginPolicy.IP.LockoutThreshold, attempts.Failures, "a successful login elsewhere leaves the address's count")
+}
+
+// TestChangePassword_SharesTheLoginThrottle guesses the current password
+// from a session and expects the guesses to throttle logins too.
+func TestChangePassword_SharesTheLoginThrottle(t *testing.T) {
+	f := newFixture(t)
+	users := f.newThrottledUserUsecase(t)
+	ctx := context.Background()
+
+	var errs []error
+	for range testLoginPolicy.Username.FreeAttempts + 1 {
+		_, err := users.ChangePassword(ctx, f.user, "wrong", "new-pw", "203.0.113.7")
+		errs = append(errs, err)
+	}
+	_, throttledErr := users.ChangePassword(ctx, f.user, "pw", "new-pw", "203.0.113.7")
+	_, loginErr := users.Login(ctx, domain.RoleUser, "pw", "198.51.100.1")
+	f.waitOut(t, usernameLoginKey(domain.RoleUser))
+	_, changeErr := users.ChangePassword(ctx, f.user, "pw", "new-pw", "203.0.113.7")
+	_, forgottenErr := f.repos.LoginAttempts.Find(ctx, usernameLoginKey(domain.RoleUser))
+	address, err := f.repos.LoginAttempts.Find(ctx, ipLoginKey("203.0.113.7"))
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	for _, err := range errs {
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	}
+	assert.Equal(t, time.Minute, retryAfter(throttledErr), "the right password is not checked while throttled")
+	assert.ErrorIs(t, loginErr, ErrTooManyLoginAttempts)
+	assert.NoError(t, changeErr)
+	assert.ErrorIs(t, forgottenErr, mongo.ErrNoDocuments, "the right password forgets the username's failures")
+	assert.Equal(t, testLoginPolicy.Username.FreeAttempts+1, address.Failures)
+}
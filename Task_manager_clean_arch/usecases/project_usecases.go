@@ -0,0 +1,316 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const MaxProjectNameLength = 100
+
+var (
+	// ErrInvalidProject is returned for a project name that cannot be used.
+	ErrInvalidProject = errors.New("invalid project")
+	// ErrProjectNotFound is returned for a project that does not exist or
+	// that the user is not a member of.
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrProjectArchived is returned when changing an archived project or
+	// adding tasks to it.
+	ErrProjectArchived = errors.New("project is archived")
+	// ErrInvalidProjectMember is returned for an unknown member role and for
+	// a change that would leave a project without an owner.
+	ErrInvalidProjectMember = errors.New("invalid project member")
+)
+
+// ProjectUpdate renames a project or changes its description. Nil fields are
+// left as they are.
+type ProjectUpdate struct {
+	Name        *string
+	Description *string
+}
+
+type IProjectUsecase interface {
+	// CreateProject creates a project owned by the user.
+	CreateProject(ctx context.Context, name, description string, userID primitive.ObjectID) (*domain.Project, error)
+	// ListProjects returns the projects the user is a member of.
+	ListProjects(ctx context.Context, includeArchived bool, userID primitive.ObjectID) ([]domain.Project, error)
+	GetProject(ctx context.Context, projectID string, userID primitive.ObjectID) (*domain.Project, error)
+	UpdateProject(ctx context.Context, projectID string, update ProjectUpdate, userID primitive.ObjectID) (*domain.Project, error)
+	// SetMember adds memberID to the project with the given role, or changes
+	// the role they already have. Only owners can manage members.
+	SetMember(ctx context.Context, projectID string, memberID primitive.ObjectID, role string, userID primitive.ObjectID) (*domain.Project, error)
+	// RemoveMember takes memberID out of the project. Owners can remove
+	// anyone, and members can remove themselves.
+	RemoveMember(ctx context.Context, projectID string, memberID primitive.ObjectID, userID primitive.ObjectID) (*domain.Project, error)
+	// ArchiveProject archives the project and its tasks, which stay readable
+	// but can no longer be changed.
+	ArchiveProject(ctx context.Context, projectID string, userID primitive.ObjectID) (*domain.Project, error)
+	UnarchiveProject(ctx context.Context, projectID string, userID primitive.ObjectID) (*domain.Project, error)
+}
+
+type projectUsecase struct {
+	projectRepo repositories.IProjectRepository
+	taskRepo    repositories.ITaskRepository
+	userRepo    repositories.IUserRepository
+}
+
+func NewProjectUsecase(projectRepo repositories.IProjectRepository, taskRepo repositories.ITaskRepository, userRepo repositories.IUserRepository) IProjectUsecase {
+	return &projectUsecase{projectRepo: projectRepo, taskRepo: taskRepo, userRepo: userRepo}
+}
+
+// projectPermission maps the user's role in a project onto the permission it
+// grants on the project's tasks. A nil project grants nothing.
+func projectPermission(project *domain.Project, userID primitive.ObjectID) taskPermission {
+	if project == nil {
+		return permissionNone
+	}
+	for _, m := range project.Members {
+		if m.UserID != userID {
+			continue
+		}
+		switch m.Role {
+		case domain.ProjectOwner:
+			return permissionManage
+		case domain.ProjectEditor:
+			return permissionEdit
+		default:
+			return permissionView
+		}
+	}
+	return permissionNone
+}
+
+// memberProject loads a project userID is a member of. Projects they are not
+// a member of are reported as not found, so their existence is not revealed.
+func memberProject(ctx context.Context, projectRepo repositories.IProjectRepository, projectID, userID primitive.ObjectID) (*domain.Project, error) {
+	project, err := projectRepo.GetByID(ctx, projectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if projectPermission(project, userID) < permissionView {
+		return nil, ErrProjectNotFound
+	}
+	return project, nil
+}
+
+// checkProjectForNewTask fails unless userID may add tasks to the project.
+// A zero projectID is a personal task, which anyone may create.
+func (uc *taskUsecase) checkProjectForNewTask(ctx context.Context, projectID, userID primitive.ObjectID) error {
+	if projectID.IsZero() {
+		return nil
+	}
+	project, err := memberProject(ctx, uc.projectRepo, projectID, userID)
+	if err != nil {
+		return err
+	}
+	if !project.ArchivedAt.IsZero() {
+		return ErrProjectArchived
+	}
+	if projectPermission(project, userID) < permissionEdit {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// authorizeProject loads a project and checks that userID holds at least the
+// needed permission in it.
+func (uc *projectUsecase) authorizeProject(ctx context.Context, projectID string, userID primitive.ObjectID, need taskPermission) (*domain.Project, error) {
+	objectID, err := primitive.ObjectIDFromHex(projectID)
+	if err != nil {
+		return nil, ErrProjectNotFound
+	}
+	project, err := memberProject(ctx, uc.projectRepo, objectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if projectPermission(project, userID) < need {
+		return nil, ErrForbidden
+	}
+	return project, nil
+}
+
+// authorizeChange is authorizeProject for changes, which archived projects
+// do not accept.
+func (uc *projectUsecase) authorizeChange(ctx context.Context, projectID string, userID primitive.ObjectID, need taskPermission) (*domain.Project, error) {
+	project, err := uc.authorizeProject(ctx, projectID, userID, need)
+	if err != nil {
+		return nil, err
+	}
+	if !project.ArchivedAt.IsZero() {
+		return nil, ErrProjectArchived
+	}
+	return project, nil
+}
+
+// normalizeProjectName trims a project name and checks it.
+func normalizeProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", fmt.Errorf("%w: name cannot be empty", ErrInvalidProject)
+	case utf8.RuneCountInString(name) > MaxProjectNameLength:
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidProject, MaxProjectNameLength)
+	}
+	return name, nil
+}
+
+// checkHasOwner fails if no member of the project is an owner.
+func checkHasOwner(members []domain.ProjectMember) error {
+	for _, m := range members {
+		if m.Role == domain.ProjectOwner {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: a project needs at least one owner", ErrInvalidProjectMember)
+}
+
+func (uc *projectUsecase) CreateProject(ctx context.Context, name, description string, userID primitive.ObjectID) (*domain.Project, error) {
+	name, err := normalizeProjectName(name)
+	if err != nil {
+		return nil, err
+	}
+	project := &domain.Project{
+		Name:        name,
+		Description: description,
+		Members:     []domain.ProjectMember{{UserID: userID, Role: domain.ProjectOwner}},
+		CreatedAt:   time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if err := uc.projectRepo.Create(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (uc *projectUsecase) ListProjects(ctx context.Context, includeArchived bool, userID primitive.ObjectID) ([]domain.Project, error) {
+	return uc.projectRepo.ListByMember(ctx, userID, includeArchived)
+}
+
+func (uc *projectUsecase) GetProject(ctx context.Context, projectID string, userID primitive.ObjectID) (*domain.Project, error) {
+	return uc.authorizeProject(ctx, projectID, userID, permissionView)
+}
+
+func (uc *projectUsecase) UpdateProject(ctx context.Context, projectID string, update ProjectUpdate, userID primitive.ObjectID) (*domain.Project, error) {
+	project, err := uc.authorizeChange(ctx, projectID, userID, permissionManage)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		if project.Name, err = normalizeProjectName(*update.Name); err != nil {
+			return nil, err
+		}
+	}
+	if update.Description != nil {
+		project.Description = *update.Description
+	}
+	if err := uc.saveProject(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (uc *projectUsecase) SetMember(ctx context.Context, projectID string, memberID primitive.ObjectID, role string, userID primitive.ObjectID) (*domain.Project, error) {
+	switch role {
+	case domain.ProjectOwner, domain.ProjectEditor, domain.ProjectViewer:
+	default:
+		return nil, fmt.Errorf("%w: role must be %q, %q or %q", ErrInvalidProjectMember, domain.ProjectOwner, domain.ProjectEditor, domain.ProjectViewer)
+	}
+	project, err := uc.authorizeChange(ctx, projectID, userID, permissionManage)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.userRepo.FindByID(ctx, memberID); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUserNotFound, memberID.Hex())
+	}
+
+	members := make([]domain.ProjectMember, 0, len(project.Members)+1)
+	for _, m := range project.Members {
+		if m.UserID != memberID {
+			members = append(members, m)
+		}
+	}
+	members = append(members, domain.ProjectMember{UserID: memberID, Role: role})
+	if err := checkHasOwner(members); err != nil {
+		return nil, err
+	}
+	project.Members = members
+	if err := uc.saveProject(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (uc *projectUsecase) RemoveMember(ctx context.Context, projectID string, memberID primitive.ObjectID, userID primitive.ObjectID) (*domain.Project, error) {
+	need := permissionManage
+	if memberID == userID {
+		need = permissionView
+	}
+	project, err := uc.authorizeChange(ctx, projectID, userID, need)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []domain.ProjectMember
+	for _, m := range project.Members {
+		if m.UserID != memberID {
+			members = append(members, m)
+		}
+	}
+	if err := checkHasOwner(members); err != nil {
+		return nil, err
+	}
+	project.Members = members
+	if err := uc.saveProject(ctx, project); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (uc *projectUsecase) ArchiveProject(ctx context.Context, projectID string, userID primitive.ObjectID) (*domain.Project, error) {
+	return uc.setArchived(ctx, projectID, true, userID)
+}
+
+func (uc *projectUsecase) UnarchiveProject(ctx context.Context, projectID string, userID primitive.ObjectID) (*domain.Project, error) {
+	return uc.setArchived(ctx, projectID, false, userID)
+}
+
+// setArchived archives or unarchives a project and then its tasks. Doing it
+// again when the project is already in that state repeats the task update,
+// which finishes an earlier attempt that failed halfway.
+func (uc *projectUsecase) setArchived(ctx context.Context, projectID string, archived bool, userID primitive.ObjectID) (*domain.Project, error) {
+	project, err := uc.authorizeProject(ctx, projectID, userID, permissionManage)
+	if err != nil {
+		return nil, err
+	}
+	if archived && project.ArchivedAt.IsZero() {
+		project.ArchivedAt = time.Now().UTC().Truncate(time.Millisecond)
+	} else if !archived {
+		project.ArchivedAt = time.Time{}
+	}
+	if err := uc.saveProject(ctx, project); err != nil {
+		return nil, err
+	}
+	if err := uc.taskRepo.ArchiveProject(ctx, project.ID, project.ArchivedAt); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// saveProject stores a changed project.
+func (uc *projectUsecase) saveProject(ctx context.Context, project *domain.Project) error {
+	err := uc.projectRepo.Update(ctx, project)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrProjectNotFound
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// withProject adds a project the user owns, with an "editor" and a "viewer"
+// who hold those roles in it.
+func withProject() fixtureOption {
+	return func(t *testing.T, f *fixture) {
+		ctx := context.Background()
+		f.projects = NewProjectUsecase(f.repos.Projects, f.repos.Tasks, f.repos.Users)
+		project, err := f.projects.CreateProject(ctx, " Website ", "Relaunch", f.user)
+		require.NoError(t, err)
+		id := project.ID.Hex()
+		_, err = f.projects.SetMember(ctx, id, f.addUser(t, "editor"), domain.ProjectEditor, f.user)
+		require.NoError(t, err)
+		f.project, err = f.projects.SetMember(ctx, id, f.addUser(t, "viewer"), domain.ProjectViewer, f.user)
+		require.NoError(t, err)
+	}
+}
+
+func TestProjectTasks_FollowMemberRoles(t *testing.T) {
+	f := newFixture(t, withProject())
+	editor, viewer := f.users["editor"], f.users["viewer"]
+	outsider := f.addUser(t, "outsider")
+	ctx := context.Background()
+	assert.Equal(t, "Website", f.project.Name)
+
+	_, err := f.tasks.CreateTask(ctx, &domain.Task{Title: "Peek", Status: StatusPending, ProjectID: f.project.ID}, viewer)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Sneak", Status: StatusPending, ProjectID: f.project.ID}, outsider)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+	task, err := f.tasks.CreateTask(ctx, &domain.Task{Title: "Landing page", Status: StatusPending, ProjectID: f.project.ID}, editor)
+	require.NoError(t, err)
+	id := task.ID.Hex()
+
+	// Another editor can change the task, and the viewer can only read it.
+	_, err = f.projects.SetMember(ctx, f.project.ID.Hex(), outsider, domain.ProjectEditor, f.user)
+	require.NoError(t, err)
+	_, err = f.tasks.UpdateTask(ctx, id, &domain.Task{Title: "Landing page v2", Status: StatusInProgress}, outsider)
+	assert.NoError(t, err)
+	_, err = f.tasks.GetTaskByID(ctx, id, viewer)
+	assert.NoError(t, err)
+	_, err = f.tasks.UpdateTask(ctx, id, &domain.Task{Title: "Nope", Status: StatusInProgress}, viewer)
+	assert.ErrorIs(t, err, ErrForbidden)
+	assert.ErrorIs(t, f.tasks.DeleteTask(ctx, id, 0, outsider), ErrForbidden)
+
+	// Leaving the project takes the access away.
+	_, err = f.projects.RemoveMember(ctx, f.project.ID.Hex(), outsider, outsider)
+	require.NoError(t, err)
+	_, err = f.tasks.GetTaskByID(ctx, id, outsider)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	// --- ASSERT ---
+	listed, _, err := f.tasks.ListTasks(ctx, repositories.TaskQuery{ProjectID: f.project.ID}, viewer)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Landing page v2", listed[0].Title)
+	_, _, err = f.tasks.ListTasks(ctx, repositories.TaskQuery{ProjectID: f.project.ID}, outsider)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+	assert.NoError(t, f.tasks.DeleteTask(ctx, id, 0, f.user))
+}
+
+func TestProjectMembers_KeepAnOwner(t *testing.T) {
+	f := newFixture(t, withProject())
+	editor, viewer := f.users["editor"], f.users["viewer"]
+	outsider := f.addUser(t, "outsider")
+	ctx := context.Background()
+	id := f.project.ID.Hex()
+
+	_, err := f.projects.SetMember(ctx, id, viewer, domain.ProjectOwner, editor)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = f.projects.SetMember(ctx, id, viewer, "admin", f.user)
+	assert.ErrorIs(t, err, ErrInvalidProjectMember)
+	_, err = f.projects.SetMember(ctx, id, primitive.NewObjectID(), domain.ProjectViewer, f.user)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	_, err = f.projects.RemoveMember(ctx, id, f.user, f.user)
+	assert.ErrorIs(t, err, ErrInvalidProjectMember)
+	_, err = f.projects.SetMember(ctx, id, f.user, domain.ProjectEditor, f.user)
+	assert.ErrorIs(t, err, ErrInvalidProjectMember)
+	_, err = f.projects.GetProject(ctx, id, outsider)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	// Once someone else owns the project, the first owner can step down.
+	_, err = f.projects.SetMember(ctx, id, editor, domain.ProjectOwner, f.user)
+	require.NoError(t, err)
+	project, err := f.projects.RemoveMember(ctx, id, f.user, f.user)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.Equal(t, []domain.ProjectMember{
+		{UserID: viewer, Role: domain.ProjectViewer},
+		{UserID: editor, Role: domain.ProjectOwner},
+	}, project.Members)
+	listed, err := f.projects.ListProjects(ctx, false, f.user)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
+
+func TestArchiveProject_MakesTasksReadOnly(t *testing.T) {
+	f := newFixture(t, withProject())
+	editor, viewer := f.users["editor"], f.users["viewer"]
+	ctx := context.Background()
+	id := f.project.ID.Hex()
+	task, err := f.tasks.CreateTask(ctx, &domain.Task{Title: "Launch", Status: StatusPending, ProjectID: f.project.ID}, f.user)
+	require.NoError(t, err)
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Personal", Status: StatusPending}, f.user)
+	require.NoError(t, err)
+
+	_, err = f.projects.ArchiveProject(ctx, id, editor)
+	assert.ErrorIs(t, err, ErrForbidden)
+	archived, err := f.projects.ArchiveProject(ctx, id, f.user)
+	require.NoError(t, err)
+	assert.False(t, archived.ArchivedAt.IsZero())
+
+	_, err = f.tasks.UpdateTask(ctx, task.ID.Hex(), &domain.Task{Title: "Relaunch", Status: StatusPending}, f.user)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Late", Status: StatusPending, ProjectID: f.project.ID}, f.user)
+	assert.ErrorIs(t, err, ErrProjectArchived)
+	_, err = f.projects.UpdateProject(ctx, id, ProjectUpdate{Name: stringPtr("Old site")}, f.user)
+	assert.ErrorIs(t, err, ErrProjectArchived)
+	personal, _, err := f.tasks.ListTasks(ctx, repositories.TaskQuery{}, f.user)
+	require.NoError(t, err)
+	require.Len(t, personal, 1)
+	assert.Equal(t, "Personal", personal[0].Title)
+	inProject, _, err := f.tasks.ListTasks(ctx, repositories.TaskQuery{ProjectID: f.project.ID}, viewer)
+	require.NoError(t, err)
+	assert.Len(t, inProject, 1)
+
+	_, err = f.projects.UnarchiveProject(ctx, id, f.user)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	updated, err := f.tasks.UpdateTask(ctx, task.ID.Hex(), &domain.Task{Title: "Relaunch", Status: StatusPending}, editor)
+	require.NoError(t, err)
+	assert.True(t, updated.ArchivedAt.IsZero())
+	listed, err := f.projects.ListProjects(ctx, false, viewer)
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+}
@@ -0,0 +1,306 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRecurrence is returned for a malformed or unsupported RRULE.
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// Recurrence frequencies supported by RecurrenceRule.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// maxRecurrencePeriods bounds how many periods an expansion will walk, so a
+// rule that matches rarely or never cannot loop forever.
+const maxRecurrencePeriods = 100000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// RecurrenceRule is the subset of an RFC 5545 RRULE that tasks support:
+// FREQ, INTERVAL, BYDAY (plain weekdays), BYMONTHDAY, COUNT and UNTIL.
+// Weeks start on Monday.
+type RecurrenceRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int // 1 to 31, or -1 to -31 counting back from month end
+	Count      int   // 0 means unlimited
+	Until      string
+}
+
+// ParseRecurrenceRule parses an RRULE such as "FREQ=WEEKLY;BYDAY=MO,WE".
+// A leading "RRULE:" is accepted.
+func ParseRecurrenceRule(rule string) (*RecurrenceRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	r := &RecurrenceRule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: %q is not NAME=VALUE", ErrInvalidRecurrence, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s given twice", ErrInvalidRecurrence, name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = value
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRecurrence, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive integer", ErrInvalidRecurrence)
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive integer", ErrInvalidRecurrence)
+			}
+			r.Count = n
+		case "UNTIL":
+			if _, err := parseUntil(value, time.UTC); err != nil {
+				return nil, err
+			}
+			r.Until = value
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return nil, fmt.Errorf("%w: unsupported BYDAY value %s", ErrInvalidRecurrence, code)
+				}
+				r.ByDay = append(r.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: BYMONTHDAY values must be 1 to 31 or -1 to -31", ErrInvalidRecurrence)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRecurrence, name)
+		}
+	}
+	if r.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	}
+	if r.Count > 0 && r.Until != "" {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRecurrence)
+	}
+	return r, nil
+}
+
+// String formats the rule in canonical RRULE form.
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != "" {
+		parts = append(parts, "UNTIL="+r.Until)
+	}
+	return strings.Join(parts, ";")
+}
+
+// parseUntil reads an UNTIL value. A UTC date-time ends in "Z"; a floating
+// date-time or a bare date is read in loc, and a bare date includes the
+// whole day.
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: UNTIL must look like 20261231 or 20261231T170000Z", ErrInvalidRecurrence)
+}
+
+// Occurrences returns up to n occurrences of the series that starts at
+// dtstart, the first being dtstart itself. Dates are worked out on the wall
+// clock of loc, so a 09:00 task stays at 09:00 local time across DST changes.
+func (r *RecurrenceRule) Occurrences(dtstart time.Time, loc *time.Location, n int) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+	start := dtstart.In(loc)
+	var until time.Time
+	if r.Until != "" {
+		until, _ = parseUntil(r.Until, loc)
+	}
+	limit := n
+	if r.Count > 0 && r.Count < limit {
+		limit = r.Count
+	}
+
+	occurrences := []time.Time{dtstart}
+	for period := 0; len(occurrences) < limit && period < maxRecurrencePeriods; period++ {
+		for _, day := range r.periodDays(start, period) {
+			t := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), loc)
+			if !t.After(start) {
+				continue
+			}
+			if !until.IsZero() && t.After(until) {
+				return occurrences
+			}
+			occurrences = append(occurrences, t)
+			if len(occurrences) == limit {
+				break
+			}
+		}
+	}
+	return occurrences
+}
+
+// periodDays lists, in order, the dates the rule selects in the given period
+// (day, week, month or year) counted from the start.
+func (r *RecurrenceRule) periodDays(start time.Time, period int) []time.Time {
+	// Noon keeps date arithmetic clear of DST transitions.
+	anchor := time.Date(start.Year(), start.Month(), start.Day(), 12, 0, 0, 0, time.UTC)
+	step := period * r.Interval
+
+	var candidates []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		candidates = []time.Time{anchor.AddDate(0, 0, step)}
+	case FreqWeekly:
+		monday := anchor.AddDate(0, 0, -((int(anchor.Weekday())+6)%7)+7*step)
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []time.Weekday{start.Weekday()}
+		}
+		for _, day := range days {
+			candidates = append(candidates, monday.AddDate(0, 0, (int(day)+6)%7))
+		}
+	case FreqMonthly:
+		first := time.Date(anchor.Year(), anchor.Month()+time.Month(step), 1, 12, 0, 0, 0, time.UTC)
+		candidates = r.monthDays(first, start.Day())
+	case FreqYearly:
+		year := anchor.Year() + step
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			candidates = r.monthDays(time.Date(year, start.Month(), 1, 12, 0, 0, 0, time.UTC), start.Day())
+		} else {
+			for month := time.January; month <= time.December; month++ {
+				candidates = append(candidates, r.monthDays(time.Date(year, month, 1, 12, 0, 0, 0, time.UTC), start.Day())...)
+			}
+		}
+	}
+
+	var days []time.Time
+	for _, day := range candidates {
+		if r.matchesByDay(day) && r.matchesByMonthDay(day) {
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return dedupeDays(days)
+}
+
+// monthDays expands one month: every BYMONTHDAY, else every BYDAY weekday,
+// else the start's day of month. Days the month does not have are skipped,
+// as RFC 5545 requires.
+func (r *RecurrenceRule) monthDays(first time.Time, startDay int) []time.Time {
+	length := first.AddDate(0, 1, -1).Day()
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = length + d + 1
+			}
+			if d >= 1 && d <= length {
+				days = append(days, first.AddDate(0, 0, d-1))
+			}
+		}
+	case len(r.ByDay) > 0:
+		for d := 0; d < length; d++ {
+			days = append(days, first.AddDate(0, 0, d))
+		}
+	default:
+		if startDay <= length {
+			days = append(days, first.AddDate(0, 0, startDay-1))
+		}
+	}
+	return days
+}
+
+func (r *RecurrenceRule) matchesByDay(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) matchesByMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	length := time.Date(day.Year(), day.Month()+1, 0, 12, 0, 0, 0, time.UTC).Day()
+	for _, d := range r.ByMonthDay {
+		if d < 0 {
+			d = length + d + 1
+		}
+		if day.Day() == d {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeDays drops repeated dates from a sorted list.
+func dedupeDays(days []time.Time) []time.Time {
+	var unique []time.Time
+	for i, day := range days {
+		if i == 0 || !day.Equal(days[i-1]) {
+			unique = append(unique, day)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+// occurrenceDates formats occurrences as local wall-clock times.
+func occurrenceDates(occurrences []time.Time, loc *time.Location) []string {
+	dates := make([]string, len(occurrences))
+	for i, t := range occurrences {
+		dates[i] = t.In(loc).Format("2006-01-02 15:04 MST")
+	}
+	return dates
+}
+
+func TestParseRecurrenceRule(t *testing.T) {
+	rule, err := ParseRecurrenceRule("RRULE:freq=weekly;interval=2;byday=MO,FR;count=6")
+	assert.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=6", rule.String())
+
+	for _, invalid := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;COUNT=3;UNTIL=20261231",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		_, err := ParseRecurrenceRule(invalid)
+		assert.ErrorIs(t, err, ErrInvalidRecurrence, invalid)
+	}
+}
+
+func TestOccurrences_WeeklyByDay(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR")
+	require.NoError(t, err)
+	start := time.Date(2026, 1, 7, 9, 0, 0, 0, time.UTC) // a Wednesday
+
+	// The start always counts, then Fridays and Mondays of every other week.
+	assert.Equal(t, []string{
+		"2026-01-07 09:00 UTC",
+		"2026-01-09 09:00 UTC",
+		"2026-01-19 09:00 UTC",
+		"2026-01-23 09:00 UTC",
+		"2026-02-02 09:00 UTC",
+	}, occurrenceDates(rule.Occurrences(start, time.UTC, 5), time.UTC))
+}
+
+func TestOccurrences_MonthlySkipsShortMonths(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=MONTHLY")
+	require.NoError(t, err)
+	start := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []string{
+		"2026-01-31 12:00 UTC",
+		"2026-03-31 12:00 UTC",
+		"2026-05-31 12:00 UTC",
+	}, occurrenceDates(rule.Occurrences(start, time.UTC, 3), time.UTC))
+
+	lastDay, err := ParseRecurrenceRule("FREQ=MONTHLY;BYMONTHDAY=-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"2026-01-31 12:00 UTC",
+		"2026-02-28 12:00 UTC",
+		"2026-03-31 12:00 UTC",
+	}, occurrenceDates(lastDay.Occurrences(start, time.UTC, 3), time.UTC))
+}
+
+func TestOccurrences_YearlyOnLeapDay(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=YEARLY")
+	require.NoError(t, err)
+	start := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []string{
+		"2024-02-29 00:00 UTC",
+		"2028-02-29 00:00 UTC",
+	}, occurrenceDates(rule.Occurrences(start, time.UTC, 2), time.UTC))
+}
+
+func TestOccurrences_CountAndUntil(t *testing.T) {
+	start := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	counted, err := ParseRecurrenceRule("FREQ=DAILY;COUNT=3")
+	require.NoError(t, err)
+	assert.Len(t, counted.Occurrences(start, time.UTC, 10), 3)
+
+	// A bare UNTIL date includes that whole day.
+	until, err := ParseRecurrenceRule("FREQ=DAILY;UNTIL=20260604")
+	require.NoError(t, err)
+	assert.Len(t, until.Occurrences(start, time.UTC, 10), 4)
+
+	// Only the start is left when nothing else matches before UNTIL.
+	rare, err := ParseRecurrenceRule("FREQ=MONTHLY;BYMONTHDAY=31;BYDAY=MO;UNTIL=20260801")
+	require.NoError(t, err)
+	assert.Len(t, rare.Occurrences(start, time.UTC, 10), 1)
+}
+
+func TestOccurrences_KeepsWallClockAcrossDST(t *testing.T) {
+	berlin := mustLoadLocation(t, "Europe/Berlin")
+	rule, err := ParseRecurrenceRule("FREQ=DAILY")
+	require.NoError(t, err)
+
+	// Clocks go forward on 29 March 2026 in Berlin.
+	start := time.Date(2026, 3, 28, 9, 0, 0, 0, berlin)
+	occurrences := rule.Occurrences(start, berlin, 3)
+	assert.Equal(t, []string{
+		"2026-03-28 09:00 CET",
+		"2026-03-29 09:00 CEST",
+		"2026-03-30 09:00 CEST",
+	}, occurrenceDates(occurrences, berlin))
+	assert.Equal(t, 23*time.Hour, occurrences[1].Sub(occurrences[0]))
+
+	// A time skipped by the change moves forward instead of vanishing.
+	nightly := time.Date(2026, 3, 28, 2, 30, 0, 0, berlin)
+	assert.Equal(t, []string{
+		"2026-03-28 02:30 CET",
+		"2026-03-29 03:30 CEST",
+		"2026-03-30 02:30 CEST",
+	}, occurrenceDates(rule.Occurrences(nightly, berlin, 3), berlin))
+}
@@ -0,0 +1,263 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DefaultReminderInterval = time.Minute
+	// MaxReminderOffset bounds offsets both ways, which also bounds how far
+	// around now each check has to look for due tasks.
+	MaxReminderOffset = 7 * 24 * time.Hour
+	// MaxReminderOffsets bounds how many offsets one user can have.
+	MaxReminderOffsets = 10
+)
+
+// ErrInvalidReminderOffsets is returned for an offset list the scheduler
+// cannot honor.
+var ErrInvalidReminderOffsets = errors.New("invalid reminder offsets")
+
+// DefaultReminderOffsets remind users a day before a task is due and when
+// it falls due.
+func DefaultReminderOffsets() []time.Duration {
+	return []time.Duration{24 * time.Hour, 0}
+}
+
+type IReminderUsecase interface {
+	// Run checks for due reminders immediately and then on every tick until
+	// ctx is cancelled. It returns once the check in progress has finished.
+	Run(ctx context.Context)
+	// CheckReminders sends every reminder that is due at now and returns how
+	// many were sent.
+	CheckReminders(ctx context.Context, now time.Time) (int, error)
+	// GetReminderOffsets returns the user's offsets and whether they are the
+	// server defaults.
+	GetReminderOffsets(ctx context.Context, userID primitive.ObjectID) ([]time.Duration, bool, error)
+	// SetReminderOffsets replaces the user's offsets; nil restores the defaults.
+	SetReminderOffsets(ctx context.Context, userID primitive.ObjectID, offsets []time.Duration) ([]time.Duration, error)
+}
+
+type reminderUsecase struct {
+	taskRepo     repositories.ITaskRepository
+	userRepo     repositories.IUserRepository
+	reminderRepo repositories.IReminderRepository
+	notifier     infrastructure.INotifier
+	workflow     *StatusWorkflow
+	interval     time.Duration
+	defaults     []time.Duration
+}
+
+// ReminderOption customizes a reminder usecase at construction time.
+type ReminderOption func(*reminderUsecase)
+
+// WithReminderInterval sets how often Run checks for due reminders.
+func WithReminderInterval(interval time.Duration) ReminderOption {
+	return func(uc *reminderUsecase) { uc.interval = interval }
+}
+
+// WithDefaultReminderOffsets sets the offsets of users who have not chosen
+// their own.
+func WithDefaultReminderOffsets(offsets []time.Duration) ReminderOption {
+	return func(uc *reminderUsecase) { uc.defaults = offsets }
+}
+
+// WithReminderWorkflow sets the workflow that decides which tasks are done
+// and need no reminders. It should match the task usecase's.
+func WithReminderWorkflow(workflow *StatusWorkflow) ReminderOption {
+	return func(uc *reminderUsecase) { uc.workflow = workflow }
+}
+
+func NewReminderUsecase(taskRepo repositories.ITaskRepository, userRepo repositories.IUserRepository, reminderRepo repositories.IReminderRepository, notifier infrastructure.INotifier, opts ...ReminderOption) IReminderUsecase {
+	uc := &reminderUsecase{
+		taskRepo:     taskRepo,
+		userRepo:     userRepo,
+		reminderRepo: reminderRepo,
+		notifier:     notifier,
+		workflow:     DefaultStatusWorkflow(),
+		interval:     DefaultReminderInterval,
+		defaults:     DefaultReminderOffsets(),
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// ValidateReminderOffsets checks that offsets are within MaxReminderOffset
+// and returns them sorted, longest first, without repeats.
+func ValidateReminderOffsets(offsets []time.Duration) ([]time.Duration, error) {
+	if len(offsets) > MaxReminderOffsets {
+		return nil, fmt.Errorf("%w: at most %d offsets", ErrInvalidReminderOffsets, MaxReminderOffsets)
+	}
+	sorted := make([]time.Duration, 0, len(offsets))
+	seen := map[time.Duration]bool{}
+	for _, offset := range offsets {
+		if offset > MaxReminderOffset || offset < -MaxReminderOffset {
+			return nil, fmt.Errorf("%w: %s is more than %s from the due date", ErrInvalidReminderOffsets, offset, MaxReminderOffset)
+		}
+		if !seen[offset] {
+			seen[offset] = true
+			sorted = append(sorted, offset)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	return sorted, nil
+}
+
+func (uc *reminderUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.interval)
+	defer ticker.Stop()
+	for {
+		if sent, err := uc.CheckReminders(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Reminder check failed after sending %d reminders: %v", sent, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckReminders looks at tasks due within MaxReminderOffset of now. Each
+// open task reminds its owner and its assignee, and for each of them only
+// the latest offset that has been reached is sent, so a server that was down
+// sends one reminder rather than a backlog. A reminder is recorded before it
+// is sent, which keeps it from going out twice; if sending fails the record
+// is dropped so the next check retries.
+func (uc *reminderUsecase) CheckReminders(ctx context.Context, now time.Time) (int, error) {
+	tasks, err := uc.taskRepo.ListDueBetween(ctx, now.Add(-MaxReminderOffset), now.Add(MaxReminderOffset))
+	if err != nil {
+		return 0, err
+	}
+
+	users := map[primitive.ObjectID]*domain.User{}
+	sent := 0
+	var errs []error
+	for i := range tasks {
+		task := &tasks[i]
+		if uc.isDone(task.Status) {
+			continue
+		}
+		for _, recipientID := range reminderRecipients(task) {
+			user, ok := users[recipientID]
+			if !ok {
+				// A user who no longer exists gets no reminders. A failed
+				// lookup is not remembered, so the user's other tasks try
+				// again.
+				user, err = uc.userRepo.FindByID(ctx, recipientID)
+				if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+					errs = append(errs, fmt.Errorf("reminder recipient %s: %w", recipientID.Hex(), err))
+					continue
+				}
+				users[recipientID] = user
+			}
+			if user == nil {
+				continue
+			}
+			offset, due := latestReachedOffset(uc.offsetsFor(user), task.Duedate, now)
+			if !due {
+				continue
+			}
+
+			reminder := &domain.Reminder{
+				TaskID:  task.ID,
+				UserID:  user.ID,
+				DueDate: task.Duedate,
+				Offset:  offset,
+				SentAt:  now.UTC().Truncate(time.Millisecond),
+			}
+			if err := uc.reminderRepo.Create(ctx, reminder); err != nil {
+				if !mongo.IsDuplicateKeyError(err) {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			if err := uc.notifier.Notify(ctx, reminder, task); err != nil {
+				errs = append(errs, fmt.Errorf("reminder for task %s: %w", task.ID.Hex(), err))
+				if err := uc.reminderRepo.Delete(context.WithoutCancel(ctx), reminder.ID); err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			sent++
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// reminderRecipients are the owner and, if different, the assignee.
+func reminderRecipients(task *domain.Task) []primitive.ObjectID {
+	recipients := []primitive.ObjectID{task.UserID}
+	if !task.AssigneeID.IsZero() && task.AssigneeID != task.UserID {
+		recipients = append(recipients, task.AssigneeID)
+	}
+	return recipients
+}
+
+// latestReachedOffset returns the offset whose reminder time most recently
+// passed, if any has.
+func latestReachedOffset(offsets []time.Duration, dueDate, now time.Time) (time.Duration, bool) {
+	var latest time.Time
+	var chosen time.Duration
+	found := false
+	for _, offset := range offsets {
+		at := dueDate.Add(-offset)
+		if !at.After(now) && (!found || at.After(latest)) {
+			latest, chosen, found = at, offset, true
+		}
+	}
+	return chosen, found
+}
+
+func (uc *reminderUsecase) offsetsFor(user *domain.User) []time.Duration {
+	if user.ReminderOffsets == nil {
+		return uc.defaults
+	}
+	return user.ReminderOffsets
+}
+
+// isDone reports whether a possibly non-canonical status counts as finished.
+func (uc *reminderUsecase) isDone(status string) bool {
+	if canonical, err := uc.workflow.Canonical(status); err == nil {
+		status = canonical
+	}
+	return uc.workflow.IsDone(status)
+}
+
+func (uc *reminderUsecase) GetReminderOffsets(ctx context.Context, userID primitive.ObjectID) ([]time.Duration, bool, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	return uc.offsetsFor(user), user.ReminderOffsets == nil, nil
+}
+
+func (uc *reminderUsecase) SetReminderOffsets(ctx context.Context, userID primitive.ObjectID, offsets []time.Duration) ([]time.Duration, error) {
+	if offsets != nil {
+		var err error
+		if offsets, err = ValidateReminderOffsets(offsets); err != nil {
+			return nil, err
+		}
+	}
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.ReminderOffsets = offsets
+	if err := uc.userRepo.UpdateFields(ctx, user, []repositories.UserField{repositories.UserFieldReminderOffsets}); err != nil {
+		return nil, err
+	}
+	return uc.offsetsFor(user), nil
+}
@@ -0,0 +1,200 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"taskmanager/domain"
+	"taskmanager/mocks"
+	"taskmanager/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reminderDue is when the task added by withReminders is due.
+var reminderDue = time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+
+// withReminders adds a reminder usecase with a mocked notifier, and a task
+// of the user's assigned to the manager and due at reminderDue.
+func withReminders() fixtureOption {
+	return func(t *testing.T, f *fixture) {
+		f.notifier = new(mocks.INotifier)
+		f.task = &domain.Task{Title: "File taxes", Status: StatusPending, Duedate: reminderDue, UserID: f.user, AssigneeID: f.manager}
+		require.NoError(t, f.repos.Tasks.Create(context.Background(), f.task))
+		f.reminders = NewReminderUsecase(f.repos.Tasks, f.repos.Users, f.repos.Reminders, f.notifier)
+	}
+}
+
+// expectReminder expects one notification for userID at the given offset.
+func (f *fixture) expectReminder(userID primitive.ObjectID, offset time.Duration, err error) {
+	f.notifier.On("Notify", mock.Anything, mock.MatchedBy(func(r *domain.Reminder) bool {
+		return r.UserID == userID && r.Offset == offset && r.TaskID == f.task.ID
+	}), mock.Anything).Return(err).Once()
+}
+
+func TestCheckReminders_SendsEachReminderOnce(t *testing.T) {
+	f := newFixture(t, withReminders())
+	ctx := context.Background()
+
+	// Nothing is due two days out.
+	sent, err := f.reminders.CheckReminders(ctx, reminderDue.Add(-48*time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+
+	f.expectReminder(f.user, 24*time.Hour, nil)
+	f.expectReminder(f.manager, 24*time.Hour, nil)
+	sent, err = f.reminders.CheckReminders(ctx, reminderDue.Add(-23*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	// A restarted scheduler over the same storage does not send them again.
+	restarted := NewReminderUsecase(f.repos.Tasks, f.repos.Users, f.repos.Reminders, f.notifier)
+	sent, err = restarted.CheckReminders(ctx, reminderDue.Add(-22*time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+
+	f.expectReminder(f.user, 0, nil)
+	f.expectReminder(f.manager, 0, nil)
+	sent, err = restarted.CheckReminders(ctx, reminderDue.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+
+	// --- ASSERT ---
+	f.notifier.AssertExpectations(t)
+}
+
+func TestCheckReminders_SendsOnlyTheLatestReachedOffset(t *testing.T) {
+	f := newFixture(t, withReminders())
+	ctx := context.Background()
+	_, err := f.reminders.SetReminderOffsets(ctx, f.user, []time.Duration{24 * time.Hour, time.Hour})
+	require.NoError(t, err)
+	_, err = f.reminders.SetReminderOffsets(ctx, f.manager, []time.Duration{})
+	require.NoError(t, err)
+
+	// Both offsets have passed, as after downtime; only the hour one goes out,
+	// and the assignee has turned reminders off.
+	f.expectReminder(f.user, time.Hour, nil)
+	sent, err := f.reminders.CheckReminders(ctx, reminderDue.Add(-30*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	// --- ASSERT ---
+	f.notifier.AssertExpectations(t)
+}
+
+func TestCheckReminders_SkipsDoneTasks(t *testing.T) {
+	f := newFixture(t, withReminders())
+	ctx := context.Background()
+	f.task.Status = StatusCompleted
+	require.NoError(t, f.repos.Tasks.Update(ctx, f.task))
+
+	sent, err := f.reminders.CheckReminders(ctx, reminderDue)
+	assert.NoError(t, err)
+	assert.Zero(t, sent)
+
+	// --- ASSERT ---
+	f.notifier.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCheckReminders_RetriesFailedNotifications(t *testing.T) {
+	f := newFixture(t, withReminders())
+	ctx := context.Background()
+	_, err := f.reminders.SetReminderOffsets(ctx, f.manager, []time.Duration{})
+	require.NoError(t, err)
+
+	f.expectReminder(f.user, 0, errors.New("webhook down"))
+	sent, err := f.reminders.CheckReminders(ctx, reminderDue)
+	assert.ErrorContains(t, err, "webhook down")
+	assert.Zero(t, sent)
+
+	f.expectReminder(f.user, 0, nil)
+	sent, err = f.reminders.CheckReminders(ctx, reminderDue.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	// --- ASSERT ---
+	f.notifier.AssertExpectations(t)
+}
+
+// unreachableUserRepository fails to look up one user.
+type unreachableUserRepository struct {
+	repositories.IUserRepository
+	unreachable primitive.ObjectID
+}
+
+func (r unreachableUserRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	if id == r.unreachable {
+		return nil, errors.New("connection reset")
+	}
+	return r.IUserRepository.FindByID(ctx, id)
+}
+
+func TestCheckReminders_ReportsFailedRecipientLookups(t *testing.T) {
+	f := newFixture(t, withReminders())
+	ctx := context.Background()
+	users := unreachableUserRepository{IUserRepository: f.repos.Users, unreachable: f.manager}
+	flaky := NewReminderUsecase(f.repos.Tasks, users, f.repos.Reminders, f.notifier)
+
+	f.expectReminder(f.user, 0, nil)
+	sent, err := flaky.CheckReminders(ctx, reminderDue)
+	assert.ErrorContains(t, err, "connection reset")
+	assert.Equal(t, 1, sent)
+
+	// The assignee was not taken for a deleted user, so the next check
+	// reminds them.
+	f.expectReminder(f.manager, 0, nil)
+	sent, err = f.reminders.CheckReminders(ctx, reminderDue.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	// --- ASSERT ---
+	f.notifier.AssertExpectations(t)
+}
+
+func TestSetReminderOffsets(t *testing.T) {
+	f := newFixture(t, withReminders())
+	ctx := context.Background()
+
+	offsets, isDefault, err := f.reminders.GetReminderOffsets(ctx, f.user)
+	assert.NoError(t, err)
+	assert.True(t, isDefault)
+	assert.Equal(t, DefaultReminderOffsets(), offsets)
+
+	offsets, err = f.reminders.SetReminderOffsets(ctx, f.user, []time.Duration{0, time.Hour, -time.Hour, time.Hour})
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{time.Hour, 0, -time.Hour}, offsets)
+
+	_, err = f.reminders.SetReminderOffsets(ctx, f.user, []time.Duration{8 * 24 * time.Hour})
+	assert.ErrorIs(t, err, ErrInvalidReminderOffsets)
+
+	offsets, err = f.reminders.SetReminderOffsets(ctx, f.user, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultReminderOffsets(), offsets)
+	_, isDefault, err = f.reminders.GetReminderOffsets(ctx, f.user)
+	assert.NoError(t, err)
+	assert.True(t, isDefault)
+}
+
+func TestRun_StopsWhenCancelled(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewReminderUsecase(repos.Tasks, repos.Users, repos.Reminders, new(mocks.INotifier), WithReminderInterval(time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		usecase.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancellation")
+	}
+}
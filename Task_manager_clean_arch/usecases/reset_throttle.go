@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"taskmanager/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrTooManyResetRequests is returned while password resets for a username
+// or from a client address must wait. It looks the same whether or not the
+// username exists.
+var ErrTooManyResetRequests = errors.New("too many password reset requests; try again later")
+
+// ResetThrottledError is ErrTooManyResetRequests with how long to wait.
+type ResetThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ResetThrottledError) Error() string { return ErrTooManyResetRequests.Error() }
+
+func (e *ResetThrottledError) Unwrap() error { return ErrTooManyResetRequests }
+
+// PasswordResetPolicy limits how often password resets can be requested,
+// so that nobody can flood a user's mailbox or probe many usernames.
+type PasswordResetPolicy struct {
+	// PerUsername requests can be made for one username in Window.
+	PerUsername int
+	// PerIP requests can be made from one client address in Window.
+	PerIP  int
+	Window time.Duration
+}
+
+// DefaultPasswordResetPolicy allows 3 requests per username and 20 per
+// client address an hour.
+func DefaultPasswordResetPolicy() PasswordResetPolicy {
+	return PasswordResetPolicy{PerUsername: 3, PerIP: 20, Window: time.Hour}
+}
+
+// WithPasswordResetThrottle counts password reset requests in attempts and
+// refuses them past the policy's limits. Without it they are not limited.
+func WithPasswordResetThrottle(attempts repositories.ILoginAttemptRepository, policy PasswordResetPolicy) UserOption {
+	return func(uc *userUsecase) {
+		uc.resetAttempts = attempts
+		uc.resetPolicy = policy
+	}
+}
+
+// usernameResetKey counts the requests for a username, whether or not a
+// user has it.
+func usernameResetKey(username string) string {
+	return "reset:username:" + username
+}
+
+func ipResetKey(clientIP string) string {
+	return "reset:ip:" + clientIP
+}
+
+// countResetRequest counts a password reset request against the username
+// and the client address, and fails with a *ResetThrottledError, without
+// counting it, while either has used up its requests.
+func (uc *userUsecase) countResetRequest(ctx context.Context, username, clientIP string, now time.Time) error {
+	if uc.resetAttempts == nil {
+		return nil
+	}
+	limits := map[string]int{usernameResetKey(username): uc.resetPolicy.PerUsername}
+	if clientIP != "" {
+		limits[ipResetKey(clientIP)] = uc.resetPolicy.PerIP
+	}
+
+	var wait time.Duration
+	for key := range limits {
+		attempts, err := uc.resetAttempts.Find(ctx, key)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		wait = max(wait, attempts.LockedUntil.Sub(now))
+	}
+	if wait > 0 {
+		return &ResetThrottledError{RetryAfter: wait}
+	}
+
+	for key, limit := range limits {
+		attempts, err := uc.resetAttempts.RecordFailure(ctx, key, now, uc.resetPolicy.Window)
+		if err != nil {
+			return err
+		}
+		if attempts.Failures >= limit {
+			if err := uc.resetAttempts.Lock(ctx, key, now.Add(uc.resetPolicy.Window)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPasswordReset_LimitsRequests requests resets until the username and
+// then the client address run out, and expects a username no one has to
+// behave the same.
+func TestPasswordReset_LimitsRequests(t *testing.T) {
+	f := newFixture(t)
+	mailer, sent := newResetMailer()
+	policy := PasswordResetPolicy{PerUsername: 2, PerIP: 3, Window: time.Hour}
+	users := f.newUserUsecase(t, WithMailer(mailer), WithPasswordResetThrottle(f.repos.LoginAttempts, policy))
+	ctx := context.Background()
+
+	var userErrs, unknownErrs []error
+	for range policy.PerUsername + 1 {
+		userErrs = append(userErrs, users.RequestPasswordReset(ctx, domain.RoleUser, "203.0.113.7"))
+		unknownErrs = append(unknownErrs, users.RequestPasswordReset(ctx, "nobody", "198.51.100.1"))
+	}
+	lastErr := users.RequestPasswordReset(ctx, "someone", "203.0.113.7")
+	addressErr := users.RequestPasswordReset(ctx, "someone-else", "203.0.113.7")
+
+	// --- ASSERT ---
+	for i := range policy.PerUsername {
+		assert.NoError(t, userErrs[i])
+		assert.NoError(t, unknownErrs[i])
+		assert.Equal(t, domain.RoleUser, receiveMail(t, sent).To)
+	}
+	var throttled *ResetThrottledError
+	assert.ErrorAs(t, userErrs[policy.PerUsername], &throttled)
+	assert.InDelta(t, time.Hour, throttled.RetryAfter, float64(time.Second))
+	assert.ErrorAs(t, unknownErrs[policy.PerUsername], &throttled, "unknown usernames are not revealed")
+	assert.InDelta(t, time.Hour, throttled.RetryAfter, float64(time.Second))
+	assert.NoError(t, lastErr, "refused requests are not counted")
+	assert.ErrorIs(t, addressErr, ErrTooManyResetRequests)
+	assert.Empty(t, sent)
+}
@@ -0,0 +1,461 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/repositories"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	MaxRoleDescriptionLength = 200
+	// RoleCacheTTL is how long role definitions are cached. Changes made
+	// through this usecase apply at once; those made by other servers
+	// sharing the database apply within the TTL.
+	RoleCacheTTL = 10 * time.Second
+)
+
+var (
+	// ErrInvalidRole is returned for a role that cannot be saved, for unknown
+	// roles or permissions, and for deleting a built-in role or one that
+	// users still have.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrRoleNotFound is returned for a role that does not exist.
+	ErrRoleNotFound = errors.New("role not found")
+	// ErrRoleExists is returned when a role by that name already exists.
+	ErrRoleExists = errors.New("role already exists")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+// KnownPermissions returns every permission a role can hold.
+func KnownPermissions() []string {
+	return []string{
+		domain.PermTaskCreate,
+		domain.PermTaskImport,
+		domain.PermTaskBatch,
+		domain.PermTaskDelete,
+		domain.PermTaskReadAny,
+		domain.PermTaskUpdateAny,
+		domain.PermTaskManageAny,
+		domain.PermUserPromote,
+		domain.PermUserManage,
+		domain.PermRoleManage,
+		domain.PermAuditRead,
+		domain.PermTrashPurge,
+		domain.PermWebhookAllTasks,
+	}
+}
+
+// builtInRoles are created on startup when they are missing. Apart from
+// admin, which always holds every permission, they can be edited afterwards.
+func builtInRoles() []domain.Role {
+	roles := []domain.Role{
+		{Name: domain.RoleAdmin, Description: "Can do everything", Permissions: KnownPermissions()},
+		{Name: domain.RoleManager, Description: "Creates tasks and can edit every task", Permissions: []string{
+			domain.PermTaskCreate,
+			domain.PermTaskImport,
+			domain.PermTaskBatch,
+			domain.PermTaskReadAny,
+			domain.PermTaskUpdateAny,
+		}},
+		{Name: domain.RoleUser, Description: "Works on the tasks they own or are given"},
+	}
+	// Stored permissions are sorted.
+	for _, role := range roles {
+		slices.Sort(role.Permissions)
+	}
+	return roles
+}
+
+// RoleUpdate holds the changes to a role; nil fields are left as they are.
+type RoleUpdate struct {
+	Description *string
+	Permissions *[]string
+}
+
+type IRoleUsecase interface {
+	// UserPermissions returns every permission the user's roles hold.
+	infrastructure.IPermissionChecker
+	// EnsureBuiltInRoles creates the built-in roles that are missing and
+	// gives admin every permission. It runs on startup.
+	EnsureBuiltInRoles(ctx context.Context) error
+	ListRoles(ctx context.Context) ([]domain.Role, error)
+	GetRole(ctx context.Context, name string) (*domain.Role, error)
+	// CreateRole saves a custom role. Actors can only create roles whose
+	// permissions they hold themselves.
+	CreateRole(ctx context.Context, role *domain.Role, actorID primitive.ObjectID) (*domain.Role, error)
+	// UpdateRole changes a role's description or permissions. The admin role
+	// cannot be changed.
+	UpdateRole(ctx context.Context, name string, update *RoleUpdate, actorID primitive.ObjectID) (*domain.Role, error)
+	// DeleteRole deletes a custom role that no user has.
+	DeleteRole(ctx context.Context, name string, actorID primitive.ObjectID) error
+	// SetUserRoles replaces the user's roles. Actors can only give or take
+	// away roles whose permissions they hold themselves, and the last
+	// enabled admin keeps the admin role.
+	SetUserRoles(ctx context.Context, userID string, roles []string, actorID primitive.ObjectID) (*domain.User, error)
+}
+
+type roleUsecase struct {
+	roleRepo  repositories.IRoleRepository
+	userRepo  repositories.IUserRepository
+	auditRepo repositories.IAuditRepository
+
+	mu sync.Mutex
+	// cached maps role names to their permissions; nil when it must be
+	// loaded again.
+	cached   map[string][]string
+	cachedAt time.Time
+}
+
+func NewRoleUsecase(roleRepo repositories.IRoleRepository, userRepo repositories.IUserRepository, auditRepo repositories.IAuditRepository) IRoleUsecase {
+	return &roleUsecase{roleRepo: roleRepo, userRepo: userRepo, auditRepo: auditRepo}
+}
+
+// rolePermissions returns the permissions of every role, by name.
+func (uc *roleUsecase) rolePermissions(ctx context.Context) (map[string][]string, error) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if uc.cached != nil && time.Since(uc.cachedAt) < RoleCacheTTL {
+		return uc.cached, nil
+	}
+	roles, err := uc.roleRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uc.cached = make(map[string][]string, len(roles))
+	for _, role := range roles {
+		uc.cached[role.Name] = role.Permissions
+	}
+	uc.cachedAt = time.Now()
+	return uc.cached, nil
+}
+
+func (uc *roleUsecase) invalidate() {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	uc.cached = nil
+}
+
+// permissionsOf returns the union of the permissions of the named roles.
+// Roles that no longer exist grant nothing.
+func (uc *roleUsecase) permissionsOf(ctx context.Context, roles []string) ([]string, error) {
+	byRole, err := uc.rolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var permissions []string
+	for _, role := range roles {
+		for _, permission := range byRole[role] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions, nil
+}
+
+// UserPermissions reads the user's roles on every call, so that giving or
+// taking away a role applies to tokens already issued. Users that no longer
+// exist hold no permissions.
+func (uc *roleUsecase) UserPermissions(ctx context.Context, userID primitive.ObjectID) ([]string, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.permissionsOf(ctx, user.Roles)
+}
+
+func (uc *roleUsecase) EnsureBuiltInRoles(ctx context.Context) error {
+	defer uc.invalidate()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for _, builtIn := range builtInRoles() {
+		role, err := uc.roleRepo.GetByName(ctx, builtIn.Name)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			builtIn.BuiltIn = true
+			builtIn.CreatedAt, builtIn.UpdatedAt = now, now
+			// Another server may have created it in the meantime.
+			if err := uc.roleRepo.Create(ctx, &builtIn); err != nil && !mongo.IsDuplicateKeyError(err) {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		// New permissions are added to admin as they appear.
+		if builtIn.Name == domain.RoleAdmin && !slices.Equal(role.Permissions, builtIn.Permissions) {
+			role.Permissions, role.UpdatedAt = builtIn.Permissions, now
+			if err := uc.roleRepo.Update(ctx, role); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (uc *roleUsecase) ListRoles(ctx context.Context) ([]domain.Role, error) {
+	return uc.roleRepo.List(ctx)
+}
+
+func (uc *roleUsecase) GetRole(ctx context.Context, name string) (*domain.Role, error) {
+	role, err := uc.roleRepo.GetByName(ctx, name)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrRoleNotFound
+	}
+	return role, err
+}
+
+// normalizePermissions trims and deduplicates permissions, checks that they
+// are known, and sorts them.
+func normalizePermissions(permissions []string) ([]string, error) {
+	normalized := []string{}
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if !slices.Contains(KnownPermissions(), permission) {
+			return nil, fmt.Errorf("%w: unknown permission %q", ErrInvalidRole, permission)
+		}
+		if !slices.Contains(normalized, permission) {
+			normalized = append(normalized, permission)
+		}
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+func checkRoleDescription(description string) (string, error) {
+	description = strings.TrimSpace(description)
+	if utf8.RuneCountInString(description) > MaxRoleDescriptionLength {
+		return "", fmt.Errorf("%w: description is longer than %d characters", ErrInvalidRole, MaxRoleDescriptionLength)
+	}
+	return description, nil
+}
+
+// checkHeld fails with ErrForbidden unless the actor holds every permission.
+func (uc *roleUsecase) checkHeld(ctx context.Context, actorID primitive.ObjectID, permissions []string) error {
+	held, err := uc.UserPermissions(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		if !slices.Contains(held, permission) {
+			return fmt.Errorf("%w: you do not hold the %s permission yourself", ErrForbidden, permission)
+		}
+	}
+	return nil
+}
+
+func (uc *roleUsecase) CreateRole(ctx context.Context, role *domain.Role, actorID primitive.ObjectID) (*domain.Role, error) {
+	role.Name = strings.TrimSpace(role.Name)
+	if !roleNamePattern.MatchString(role.Name) {
+		return nil, fmt.Errorf("%w: name must be 2 to 32 lowercase letters, digits, '-' or '_', starting with a letter", ErrInvalidRole)
+	}
+	var err error
+	if role.Description, err = checkRoleDescription(role.Description); err != nil {
+		return nil, err
+	}
+	if role.Permissions, err = normalizePermissions(role.Permissions); err != nil {
+		return nil, err
+	}
+	if err := uc.checkHeld(ctx, actorID, role.Permissions); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	role.ID = primitive.NilObjectID
+	role.BuiltIn = false
+	role.CreatedAt, role.UpdatedAt = now, now
+	if err := uc.roleRepo.Create(ctx, role); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrRoleExists
+		}
+		return nil, err
+	}
+	uc.invalidate()
+	if err := uc.auditRepo.Append(ctx, newRoleAuditEntry(domain.AuditActionCreate, nil, role, actorID)); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (uc *roleUsecase) UpdateRole(ctx context.Context, name string, update *RoleUpdate, actorID primitive.ObjectID) (*domain.Role, error) {
+	role, err := uc.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if role.Name == domain.RoleAdmin {
+		return nil, fmt.Errorf("%w: the admin role cannot be changed", ErrInvalidRole)
+	}
+	before := *role
+	if update.Description != nil {
+		if role.Description, err = checkRoleDescription(*update.Description); err != nil {
+			return nil, err
+		}
+	}
+	if update.Permissions != nil {
+		if role.Permissions, err = normalizePermissions(*update.Permissions); err != nil {
+			return nil, err
+		}
+		// Taking a permission away is as much a change to it as granting it.
+		var changed []string
+		for _, permission := range KnownPermissions() {
+			if slices.Contains(before.Permissions, permission) != slices.Contains(role.Permissions, permission) {
+				changed = append(changed, permission)
+			}
+		}
+		if err := uc.checkHeld(ctx, actorID, changed); err != nil {
+			return nil, err
+		}
+	}
+
+	role.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := uc.roleRepo.Update(ctx, role); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	uc.invalidate()
+	if err := uc.auditRepo.Append(ctx, newRoleAuditEntry(domain.AuditActionUpdate, &before, role, actorID)); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (uc *roleUsecase) DeleteRole(ctx context.Context, name string, actorID primitive.ObjectID) error {
+	role, err := uc.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return fmt.Errorf("%w: built-in roles cannot be deleted", ErrInvalidRole)
+	}
+	holders, err := uc.userRepo.CountByRole(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	if holders > 0 {
+		return fmt.Errorf("%w: %d users still have the role", ErrInvalidRole, holders)
+	}
+	if err := uc.roleRepo.Delete(ctx, role.ID); err != nil {
+		return err
+	}
+	uc.invalidate()
+	return uc.auditRepo.Append(ctx, newRoleAuditEntry(domain.AuditActionDelete, role, nil, actorID))
+}
+
+func (uc *roleUsecase) SetUserRoles(ctx context.Context, userID string, roles []string, actorID primitive.ObjectID) (*domain.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := uc.userRepo.FindByID(ctx, objectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	byRole, err := uc.rolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var normalized []string
+	for _, role := range roles {
+		role = strings.TrimSpace(role)
+		if _, ok := byRole[role]; !ok {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidRole, role)
+		}
+		if !slices.Contains(normalized, role) {
+			normalized = append(normalized, role)
+		}
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: a user needs at least one role", ErrInvalidRole)
+	}
+
+	var changed []string
+	for _, role := range normalized {
+		if !slices.Contains(user.Roles, role) {
+			changed = append(changed, role)
+		}
+	}
+	for _, role := range user.Roles {
+		if !slices.Contains(normalized, role) {
+			changed = append(changed, role)
+		}
+	}
+	changedPermissions, err := uc.permissionsOf(ctx, changed)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkHeld(ctx, actorID, changedPermissions); err != nil {
+		return nil, err
+	}
+
+	before := strings.Join(user.Roles, ",")
+	previous := *user
+	user.Roles = normalized
+	if err := saveKeepingAdmin(ctx, uc.userRepo, &previous, user, []repositories.UserField{repositories.UserFieldRoles}); err != nil {
+		return nil, err
+	}
+	return user, uc.auditRepo.Append(ctx, &domain.AuditEntry{
+		EntityType: domain.AuditEntityUser,
+		EntityID:   user.ID,
+		Action:     domain.AuditActionUpdate,
+		ActorID:    actorID,
+		Timestamp:  time.Now().UTC().Truncate(time.Millisecond),
+		Changes:    []domain.FieldChange{{Field: "roles", Before: before, After: strings.Join(user.Roles, ",")}},
+	})
+}
+
+// newRoleAuditEntry describes a change to a role. before is nil for a create
+// and after is nil for a delete.
+func newRoleAuditEntry(action string, before, after *domain.Role, actorID primitive.ObjectID) *domain.AuditEntry {
+	entityID := primitive.NilObjectID
+	if after != nil {
+		entityID = after.ID
+	} else if before != nil {
+		entityID = before.ID
+	}
+	fields := func(role *domain.Role) []auditedField {
+		if role == nil {
+			role = &domain.Role{}
+		}
+		return []auditedField{
+			{name: "name", value: role.Name},
+			{name: "description", value: role.Description},
+			{name: "permissions", value: strings.Join(role.Permissions, ",")},
+		}
+	}
+	var changes []domain.FieldChange
+	beforeFields, afterFields := fields(before), fields(after)
+	for i, field := range beforeFields {
+		if field.value != afterFields[i].value {
+			changes = append(changes, domain.FieldChange{Field: field.name, Before: field.value, After: afterFields[i].value})
+		}
+	}
+	return &domain.AuditEntry{
+		EntityType: domain.AuditEntityRole,
+		EntityID:   entityID,
+		Action:     action,
+		ActorID:    actorID,
+		Timestamp:  time.Now().UTC().Truncate(time.Millisecond),
+		Changes:    changes,
+	}
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRoles_BuiltInRoles(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	// Running it again on the next start changes nothing.
+	require.NoError(t, f.roles.EnsureBuiltInRoles(ctx))
+
+	roles, err := f.roles.ListRoles(ctx)
+	require.NoError(t, err)
+	adminPermissions, err := f.roles.UserPermissions(ctx, f.admin)
+	require.NoError(t, err)
+	managerPermissions, err := f.roles.UserPermissions(ctx, f.manager)
+	require.NoError(t, err)
+	userPermissions, err := f.roles.UserPermissions(ctx, f.user)
+	require.NoError(t, err)
+	strangerPermissions, err := f.roles.UserPermissions(ctx, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	require.Len(t, roles, 3)
+	for _, role := range roles {
+		assert.True(t, role.BuiltIn, role.Name)
+	}
+	assert.ElementsMatch(t, KnownPermissions(), adminPermissions)
+	assert.Contains(t, managerPermissions, domain.PermTaskUpdateAny)
+	assert.NotContains(t, managerPermissions, domain.PermUserPromote)
+	assert.Empty(t, userPermissions)
+	assert.Empty(t, strangerPermissions)
+	_, err = f.roles.UpdateRole(ctx, domain.RoleAdmin, &RoleUpdate{Permissions: &[]string{}}, f.admin)
+	assert.ErrorIs(t, err, ErrInvalidRole)
+	assert.ErrorIs(t, f.roles.DeleteRole(ctx, domain.RoleManager, f.admin), ErrInvalidRole)
+}
+
+func TestRoles_CustomRoleLifecycle(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	auditor, err := f.roles.CreateRole(ctx, &domain.Role{Name: "auditor", Permissions: []string{domain.PermAuditRead, " audit:read"}}, f.admin)
+	require.NoError(t, err)
+	_, err = f.roles.CreateRole(ctx, &domain.Role{Name: "auditor"}, f.admin)
+	assert.ErrorIs(t, err, ErrRoleExists)
+	_, err = f.roles.CreateRole(ctx, &domain.Role{Name: "Bad Name"}, f.admin)
+	assert.ErrorIs(t, err, ErrInvalidRole)
+	_, err = f.roles.CreateRole(ctx, &domain.Role{Name: "wizard", Permissions: []string{"task:levitate"}}, f.admin)
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	user, err := f.roles.SetUserRoles(ctx, f.user.Hex(), []string{domain.RoleUser, "auditor"}, f.admin)
+	require.NoError(t, err)
+	permissions, err := f.roles.UserPermissions(ctx, f.user)
+	require.NoError(t, err)
+	assert.Equal(t, []string{domain.PermAuditRead}, permissions)
+	assert.ErrorIs(t, f.roles.DeleteRole(ctx, "auditor", f.admin), ErrInvalidRole, "the role is still in use")
+
+	// A role change applies at once, without waiting for the cache.
+	_, err = f.roles.UpdateRole(ctx, "auditor", &RoleUpdate{Permissions: &[]string{domain.PermAuditRead, domain.PermTaskReadAny}}, f.admin)
+	require.NoError(t, err)
+	permissions, err = f.roles.UserPermissions(ctx, f.user)
+	require.NoError(t, err)
+	_, err = f.roles.SetUserRoles(ctx, f.user.Hex(), []string{domain.RoleUser}, f.admin)
+	require.NoError(t, err)
+	deleteErr := f.roles.DeleteRole(ctx, "auditor", f.admin)
+	history, _, err := f.repos.Audit.List(ctx, repositories.AuditQuery{EntityType: domain.AuditEntityRole, Limit: 10})
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.Equal(t, []string{domain.PermAuditRead}, auditor.Permissions)
+	assert.Equal(t, []string{domain.RoleUser, "auditor"}, user.Roles)
+	assert.ElementsMatch(t, []string{domain.PermAuditRead, domain.PermTaskReadAny}, permissions)
+	assert.NoError(t, deleteErr)
+	_, err = f.roles.GetRole(ctx, "auditor")
+	assert.ErrorIs(t, err, ErrRoleNotFound)
+	require.Len(t, history, 3)
+	assert.Equal(t, domain.AuditActionDelete, history[0].Action)
+	assert.Equal(t, domain.AuditActionCreate, history[2].Action)
+}
+
+func TestRoles_CannotGrantPermissionsNotHeld(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	// A manager who may manage roles, but holds nothing else an admin has.
+	_, err := f.roles.CreateRole(ctx, &domain.Role{Name: "role-admin", Permissions: []string{domain.PermRoleManage, domain.PermUserPromote}}, f.admin)
+	require.NoError(t, err)
+	_, err = f.roles.SetUserRoles(ctx, f.manager.Hex(), []string{domain.RoleManager, "role-admin"}, f.admin)
+	require.NoError(t, err)
+
+	_, createErr := f.roles.CreateRole(ctx, &domain.Role{Name: "purger", Permissions: []string{domain.PermTrashPurge}}, f.manager)
+	_, promoteErr := f.roles.SetUserRoles(ctx, f.user.Hex(), []string{domain.RoleAdmin}, f.manager)
+	_, demoteErr := f.roles.SetUserRoles(ctx, f.admin.Hex(), []string{domain.RoleUser}, f.manager)
+	_, grantErr := f.roles.SetUserRoles(ctx, f.user.Hex(), []string{domain.RoleManager}, f.manager)
+	_, unknownErr := f.roles.SetUserRoles(ctx, f.user.Hex(), []string{"ghost"}, f.admin)
+	_, emptyErr := f.roles.SetUserRoles(ctx, f.user.Hex(), nil, f.admin)
+	_, missingErr := f.roles.SetUserRoles(ctx, primitive.NewObjectID().Hex(), []string{domain.RoleUser}, f.admin)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, createErr, ErrForbidden)
+	assert.ErrorIs(t, promoteErr, ErrForbidden)
+	assert.ErrorIs(t, demoteErr, ErrForbidden)
+	assert.NoError(t, grantErr, "the manager holds every permission of the manager role")
+	assert.ErrorIs(t, unknownErr, ErrInvalidRole)
+	assert.ErrorIs(t, emptyErr, ErrInvalidRole)
+	assert.ErrorIs(t, missingErr, ErrUserNotFound)
+}
+
+func TestRoles_ManagerCanEditButNotDeleteOthersTasks(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	tasks := f.newTaskUsecase(WithPermissionChecker(f.roles))
+	task, err := tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending}, f.user)
+	require.NoError(t, err)
+
+	seen, seeErr := tasks.GetTaskByID(ctx, task.ID.Hex(), f.manager)
+	_, editErr := tasks.UpdateTask(ctx, task.ID.Hex(), &domain.Task{Title: "Deploy v2", Status: StatusPending}, f.manager)
+	deleteErr := tasks.DeleteTask(ctx, task.ID.Hex(), 0, f.manager)
+	_, strangerErr := tasks.GetTaskByID(ctx, task.ID.Hex(), primitive.NewObjectID())
+
+	// --- ASSERT ---
+	assert.NoError(t, seeErr)
+	assert.Equal(t, "Deploy", seen.Title)
+	assert.NoError(t, editErr)
+	assert.ErrorIs(t, deleteErr, ErrForbidden)
+	assert.ErrorIs(t, strangerErr, ErrTaskNotFound)
+	assert.NoError(t, tasks.DeleteTask(ctx, task.ID.Hex(), 0, f.admin), "task:manage:any reaches every task")
+}
@@ -0,0 +1,232 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// DefaultTagColor is given to tags created without a color.
+	DefaultTagColor  = "#808080"
+	MaxTagNameLength = 50
+)
+
+var (
+	// ErrInvalidTag is returned for a tag name or color that cannot be used,
+	// and for tagging a task with a tag its owner has not defined.
+	ErrInvalidTag = errors.New("invalid tag")
+	// ErrTagNotFound is returned for a tag that does not exist or belongs to
+	// another user.
+	ErrTagNotFound = errors.New("tag not found")
+	// ErrTagExists is returned when the user already has a tag by that name.
+	ErrTagExists = errors.New("tag already exists")
+)
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// TagUpdate renames or recolors a tag. Nil fields are left as they are.
+type TagUpdate struct {
+	Name  *string
+	Color *string
+}
+
+type ITagUsecase interface {
+	CreateTag(ctx context.Context, name, color string, userID primitive.ObjectID) (*domain.Tag, error)
+	ListTags(ctx context.Context, userID primitive.ObjectID) ([]domain.Tag, error)
+	// UpdateTag renames or recolors one of the user's tags. A rename is
+	// carried over to every task the user owns.
+	UpdateTag(ctx context.Context, tagID string, update TagUpdate, userID primitive.ObjectID) (*domain.Tag, error)
+	// DeleteTag deletes one of the user's tags and takes it off their tasks.
+	DeleteTag(ctx context.Context, tagID string, userID primitive.ObjectID) error
+}
+
+type tagUsecase struct {
+	tagRepo    repositories.ITagRepository
+	taskRepo   repositories.ITaskRepository
+	unitOfWork repositories.IUnitOfWork
+}
+
+// NewTagUsecase changes a tag and the tasks carrying it in one unit of work,
+// which must belong to the same backend as the repositories.
+func NewTagUsecase(tagRepo repositories.ITagRepository, taskRepo repositories.ITaskRepository, unitOfWork repositories.IUnitOfWork) ITagUsecase {
+	return &tagUsecase{tagRepo: tagRepo, taskRepo: taskRepo, unitOfWork: unitOfWork}
+}
+
+// normalizeTagName trims a tag name and checks it. Commas are reserved for
+// separating tags in task filters.
+func normalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", fmt.Errorf("%w: name cannot be empty", ErrInvalidTag)
+	case utf8.RuneCountInString(name) > MaxTagNameLength:
+		return "", fmt.Errorf("%w: name is longer than %d characters", ErrInvalidTag, MaxTagNameLength)
+	case strings.Contains(name, ","):
+		return "", fmt.Errorf("%w: name cannot contain commas", ErrInvalidTag)
+	}
+	return name, nil
+}
+
+// normalizeTagColor checks a "#rrggbb" color and lowercases it.
+func normalizeTagColor(color string) (string, error) {
+	if !tagColorPattern.MatchString(color) {
+		return "", fmt.Errorf("%w: color %q is not of the form #rrggbb", ErrInvalidTag, color)
+	}
+	return strings.ToLower(color), nil
+}
+
+// tagSaveError reports a name clash as ErrTagExists.
+func tagSaveError(err error, name string) error {
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%w: %s", ErrTagExists, name)
+	}
+	return err
+}
+
+func (uc *tagUsecase) CreateTag(ctx context.Context, name, color string, userID primitive.ObjectID) (*domain.Tag, error) {
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+	if color == "" {
+		color = DefaultTagColor
+	}
+	if color, err = normalizeTagColor(color); err != nil {
+		return nil, err
+	}
+
+	tag := &domain.Tag{UserID: userID, Name: name, Color: color, CreatedAt: time.Now().UTC().Truncate(time.Millisecond)}
+	if err := uc.tagRepo.Create(ctx, tag); err != nil {
+		return nil, tagSaveError(err, name)
+	}
+	return tag, nil
+}
+
+func (uc *tagUsecase) ListTags(ctx context.Context, userID primitive.ObjectID) ([]domain.Tag, error) {
+	return uc.tagRepo.ListByUserID(ctx, userID)
+}
+
+// findTag loads one of the user's tags. Other users' tags are reported as
+// not found.
+func (uc *tagUsecase) findTag(ctx context.Context, tagID string, userID primitive.ObjectID) (*domain.Tag, error) {
+	objectID, err := primitive.ObjectIDFromHex(tagID)
+	if err != nil {
+		return nil, ErrTagNotFound
+	}
+	tag, err := uc.tagRepo.GetByID(ctx, objectID)
+	if err != nil || tag.UserID != userID {
+		return nil, ErrTagNotFound
+	}
+	return tag, nil
+}
+
+// UpdateTag saves the tag before renaming it on tasks, so a name clash
+// leaves the tasks untouched.
+func (uc *tagUsecase) UpdateTag(ctx context.Context, tagID string, update TagUpdate, userID primitive.ObjectID) (*domain.Tag, error) {
+	tag, err := uc.findTag(ctx, tagID, userID)
+	if err != nil {
+		return nil, err
+	}
+	oldName := tag.Name
+	if update.Name != nil {
+		if tag.Name, err = normalizeTagName(*update.Name); err != nil {
+			return nil, err
+		}
+	}
+	if update.Color != nil {
+		if tag.Color, err = normalizeTagColor(*update.Color); err != nil {
+			return nil, err
+		}
+	}
+
+	err = uc.run(ctx, func(ctx context.Context) error {
+		if err := uc.tagRepo.Update(ctx, tag); err != nil {
+			return tagSaveError(err, tag.Name)
+		}
+		if tag.Name == oldName {
+			return nil
+		}
+		return uc.taskRepo.RenameTag(ctx, userID, oldName, tag.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// DeleteTag untags the tasks first, so a failure never leaves tasks carrying
+// a tag that no longer exists.
+func (uc *tagUsecase) DeleteTag(ctx context.Context, tagID string, userID primitive.ObjectID) error {
+	tag, err := uc.findTag(ctx, tagID, userID)
+	if err != nil {
+		return err
+	}
+	return uc.run(ctx, func(ctx context.Context) error {
+		if err := uc.taskRepo.RemoveTag(ctx, userID, tag.Name); err != nil {
+			return err
+		}
+		return uc.tagRepo.Delete(ctx, tag.ID)
+	})
+}
+
+// run calls fn in the unit of work. Without transactions, such as on a
+// standalone MongoDB server, the steps run one after another in the order
+// that leaves the least behind if one fails.
+func (uc *tagUsecase) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := uc.unitOfWork.Run(ctx, fn)
+	if errors.Is(err, repositories.ErrTransactionsUnsupported) {
+		err = fn(ctx)
+	}
+	return err
+}
+
+// applyTags sets the task's tags, dropping repeats. Tags the task does not
+// already carry must be ones its owner has defined.
+func (uc *taskUsecase) applyTags(ctx context.Context, task *domain.Task, requested []string) error {
+	current := map[string]bool{}
+	for _, name := range task.Tags {
+		current[name] = true
+	}
+	var tags []string
+	seen := map[string]bool{}
+	var added []string
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, name)
+		if !current[name] {
+			added = append(added, name)
+		}
+	}
+
+	if len(added) > 0 {
+		defined, err := uc.tagRepo.ListByUserID(ctx, task.UserID)
+		if err != nil {
+			return err
+		}
+		known := map[string]bool{}
+		for _, tag := range defined {
+			known[tag.Name] = true
+		}
+		for _, name := range added {
+			if !known[name] {
+				return fmt.Errorf("%w: the task's owner has no tag %q", ErrInvalidTag, name)
+			}
+		}
+	}
+	task.Tags = tags
+	return nil
+}
@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"taskmanager/domain"
+	"taskmanager/mocks"
+	"taskmanager/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreateTag_Validation(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTagUsecase(repos.Tags, repos.Tasks, repos.UnitOfWork)
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+
+	tag, err := usecase.CreateTag(ctx, "  backend ", "", userID)
+	require.NoError(t, err)
+	assert.Equal(t, "backend", tag.Name)
+	assert.Equal(t, DefaultTagColor, tag.Color)
+
+	_, err = usecase.CreateTag(ctx, "backend", "#FF0000", userID)
+	assert.ErrorIs(t, err, ErrTagExists)
+	_, err = usecase.CreateTag(ctx, " ", "", userID)
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	_, err = usecase.CreateTag(ctx, "a,b", "", userID)
+	assert.ErrorIs(t, err, ErrInvalidTag)
+	_, err = usecase.CreateTag(ctx, "infra", "red", userID)
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	// --- ASSERT ---
+	infra, err := usecase.CreateTag(ctx, "infra", "#FF0000", userID)
+	require.NoError(t, err)
+	assert.Equal(t, "#ff0000", infra.Color)
+	tags, err := usecase.ListTags(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+}
+
+func TestUpdateAndDeleteTag_CascadeToTasks(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	tags := NewTagUsecase(repos.Tags, repos.Tasks, repos.UnitOfWork)
+	tasks := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+
+	backend, err := tags.CreateTag(ctx, "backend", "", ownerID)
+	require.NoError(t, err)
+	docs, err := tags.CreateTag(ctx, "docs", "", ownerID)
+	require.NoError(t, err)
+	task, err := tasks.CreateTask(ctx, &domain.Task{Title: "API", Status: StatusPending, Tags: []string{"backend", "docs", "backend"}}, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backend", "docs"}, task.Tags)
+
+	// Someone else's tag is invisible, and renames cannot clash.
+	_, err = tags.UpdateTag(ctx, backend.ID.Hex(), TagUpdate{Name: stringPtr("api")}, primitive.NewObjectID())
+	assert.ErrorIs(t, err, ErrTagNotFound)
+	_, err = tags.UpdateTag(ctx, backend.ID.Hex(), TagUpdate{Name: stringPtr("docs")}, ownerID)
+	assert.ErrorIs(t, err, ErrTagExists)
+
+	renamed, err := tags.UpdateTag(ctx, backend.ID.Hex(), TagUpdate{Name: stringPtr("api"), Color: stringPtr("#00AA00")}, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, "#00aa00", renamed.Color)
+	require.NoError(t, tags.DeleteTag(ctx, docs.ID.Hex(), ownerID))
+
+	// --- ASSERT ---
+	found, err := tasks.GetTaskByID(ctx, task.ID.Hex(), ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api"}, found.Tags)
+	remaining, err := tags.ListTags(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "api", remaining[0].Name)
+}
+
+func TestUpdateTag_RenameIsUndoneWhenTasksCannotFollow(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	taskRepo := new(mocks.ITaskRepository)
+	taskRepo.On("RenameTag", mock.Anything, mock.Anything, "backend", "api").Return(errors.New("disk full"))
+	tags := NewTagUsecase(repos.Tags, taskRepo, repos.UnitOfWork)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	backend, err := tags.CreateTag(ctx, "backend", "", ownerID)
+	require.NoError(t, err)
+
+	_, err = tags.UpdateTag(ctx, backend.ID.Hex(), TagUpdate{Name: stringPtr("api")}, ownerID)
+	remaining, listErr := tags.ListTags(ctx, ownerID)
+	require.NoError(t, listErr)
+
+	// --- ASSERT ---
+	assert.EqualError(t, err, "disk full")
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "backend", remaining[0].Name)
+	taskRepo.AssertExpectations(t)
+}
+
+func TestTaskTags_MustBeTheOwnersTags(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	tags := NewTagUsecase(repos.Tags, repos.Tasks, repos.UnitOfWork)
+	tasks := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	otherID := primitive.NewObjectID()
+
+	_, err := tags.CreateTag(ctx, "infra", "", otherID)
+	require.NoError(t, err)
+	_, err = tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending, Tags: []string{"infra"}}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	_, err = tags.CreateTag(ctx, "infra", "", ownerID)
+	require.NoError(t, err)
+	task, err := tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending, Tags: []string{"infra"}}, ownerID)
+	require.NoError(t, err)
+	_, err = tasks.CreateTask(ctx, &domain.Task{Title: "Write up", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+
+	_, err = tasks.PatchTask(ctx, task.ID.Hex(), &TaskPatch{Tags: &[]string{"infra", "docs"}}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidTag)
+
+	// --- ASSERT ---
+	listed, _, err := tasks.ListTasks(ctx, repositories.TaskQuery{AnyTags: []string{" infra", ""}}, ownerID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, "Deploy", listed[0].Title)
+	history, _, err := tasks.GetTaskHistory(ctx, task.ID.Hex(), repositories.AuditQuery{}, ownerID)
+	require.NoError(t, err)
+	assert.Contains(t, history[0].Changes, domain.FieldChange{Field: "tags", After: "infra"})
+}
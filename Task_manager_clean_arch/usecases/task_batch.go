@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxBatchOperations caps the number of operations in one batch.
+const MaxBatchOperations = 1000
+
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+var (
+	// ErrInvalidBatch is returned for a batch that is empty, too long, or has
+	// an operation that cannot be run.
+	ErrInvalidBatch = errors.New("invalid batch")
+	// ErrBatchAborted is the result of the operations of a batch that was
+	// abandoned because another of its operations failed.
+	ErrBatchAborted = errors.New("batch aborted")
+)
+
+// BatchOperation is one step of a batch. Task is the new task of a create
+// and the replacement of an update. A non-zero Version must match the stored
+// version of the task an update or delete changes.
+type BatchOperation struct {
+	Op      string
+	TaskID  string
+	Task    *domain.Task
+	Version int64
+}
+
+// BatchResult is the outcome of one operation: the created or updated task,
+// or the error. A delete has neither.
+type BatchResult struct {
+	Task *domain.Task
+	Err  error
+}
+
+// checkBatch rejects a batch before any of it runs.
+func checkBatch(ops []BatchOperation) error {
+	switch {
+	case len(ops) == 0:
+		return fmt.Errorf("%w: no operations", ErrInvalidBatch)
+	case len(ops) > MaxBatchOperations:
+		return fmt.Errorf("%w: more than %d operations", ErrInvalidBatch, MaxBatchOperations)
+	}
+	for i, op := range ops {
+		switch {
+		case op.Op != BatchCreate && op.Op != BatchUpdate && op.Op != BatchDelete:
+			return fmt.Errorf("%w: operation %d: op must be %q, %q or %q", ErrInvalidBatch, i, BatchCreate, BatchUpdate, BatchDelete)
+		case op.Op != BatchCreate && op.TaskID == "":
+			return fmt.Errorf("%w: operation %d: %s needs a task ID", ErrInvalidBatch, i, op.Op)
+		case op.Op != BatchDelete && op.Task == nil:
+			return fmt.Errorf("%w: operation %d: %s needs a task", ErrInvalidBatch, i, op.Op)
+		}
+	}
+	return nil
+}
+
+// RunBatch runs the operations in one unit of work. When an operation fails,
+// none of the batch is kept: the results are returned with that operation's
+// error, and the other operations get ErrBatchAborted. Any other error comes
+// without results. Task events are held back until the batch commits, so
+// nothing hears of a change that was rolled back. A database that cannot
+// run the unit, such as a standalone MongoDB server, gets
+// repositories.ErrTransactionsUnsupported and none of the batch is kept;
+// there is no fallback, since a batch applied part way could not promise
+// all or nothing.
+func (uc *taskUsecase) RunBatch(ctx context.Context, ops []BatchOperation, userID primitive.ObjectID) ([]BatchResult, error) {
+	if err := checkBatch(ops); err != nil {
+		return nil, err
+	}
+	if uc.unitOfWork == nil {
+		return nil, repositories.ErrTransactionsUnsupported
+	}
+
+	var results []BatchResult
+	var events []TaskEvent
+	failed := -1
+	err := uc.unitOfWork.Run(ctx, func(ctx context.Context) error {
+		// The unit may be retried, so every attempt starts afresh.
+		results, events, failed = make([]BatchResult, len(ops)), nil, -1
+		ctx = withPendingEvents(ctx, &events)
+		for i, op := range ops {
+			task, err := uc.runBatchOperation(ctx, op, userID)
+			if err != nil {
+				results[i].Err, failed = err, i
+				return err
+			}
+			results[i].Task = task
+		}
+		return nil
+	})
+	if err == nil {
+		uc.publish(ctx, events)
+		return results, nil
+	}
+	if failed < 0 || !errors.Is(err, results[failed].Err) {
+		return nil, err
+	}
+	for i := range results {
+		if i != failed {
+			results[i] = BatchResult{Err: fmt.Errorf("%w: operation %d failed", ErrBatchAborted, failed)}
+		}
+	}
+	return results, fmt.Errorf("operation %d: %w", failed, err)
+}
+
+func (uc *taskUsecase) runBatchOperation(ctx context.Context, op BatchOperation, userID primitive.ObjectID) (*domain.Task, error) {
+	switch op.Op {
+	case BatchCreate:
+		task := *op.Task
+		return uc.CreateTask(ctx, &task, userID)
+	case BatchUpdate:
+		task := *op.Task
+		task.Version = op.Version
+		return uc.UpdateTask(ctx, op.TaskID, &task, userID)
+	default:
+		return nil, uc.DeleteTask(ctx, op.TaskID, op.Version, userID)
+	}
+}
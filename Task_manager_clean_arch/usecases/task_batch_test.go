@@ -0,0 +1,137 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRunBatch_AppliesEveryOperation(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects, WithUnitOfWork(repos.UnitOfWork))
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	existing, err := usecase.CreateTask(ctx, &domain.Task{Title: "Old", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	doomed, err := usecase.CreateTask(ctx, &domain.Task{Title: "Doomed", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+
+	_, err = usecase.RunBatch(ctx, nil, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidBatch)
+	_, err = usecase.RunBatch(ctx, []BatchOperation{{Op: BatchUpdate, Task: &domain.Task{Title: "No ID", Status: StatusPending}}}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidBatch)
+
+	results, err := usecase.RunBatch(ctx, []BatchOperation{
+		{Op: BatchCreate, Task: &domain.Task{Title: "New", Status: StatusPending}},
+		{Op: BatchUpdate, TaskID: existing.ID.Hex(), Version: existing.Version, Task: &domain.Task{Title: "Renamed", Status: StatusInProgress}},
+		{Op: BatchDelete, TaskID: doomed.ID.Hex()},
+	}, ownerID)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	require.Len(t, results, 3)
+	assert.Equal(t, "New", results[0].Task.Title)
+	assert.Equal(t, "Renamed", results[1].Task.Title)
+	assert.Equal(t, BatchResult{}, results[2])
+	tasks, err := usecase.GetUserTasks(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestRunBatch_RollsBackWhenAnOperationFails(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects, WithUnitOfWork(repos.UnitOfWork))
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	existing, err := usecase.CreateTask(ctx, &domain.Task{Title: "Old", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+
+	results, err := usecase.RunBatch(ctx, []BatchOperation{
+		{Op: BatchCreate, Task: &domain.Task{Title: "New", Status: StatusPending}},
+		{Op: BatchUpdate, TaskID: existing.ID.Hex(), Task: &domain.Task{Title: "Renamed", Status: StatusPending}},
+		{Op: BatchDelete, TaskID: existing.ID.Hex(), Version: 1},
+		{Op: BatchCreate, Task: &domain.Task{Title: "Never", Status: StatusPending}},
+	}, ownerID)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	require.Len(t, results, 4)
+	assert.ErrorIs(t, results[2].Err, ErrVersionMismatch)
+	for _, i := range []int{0, 1, 3} {
+		assert.ErrorIs(t, results[i].Err, ErrBatchAborted)
+		assert.Nil(t, results[i].Task)
+	}
+	tasks, err := usecase.GetUserTasks(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Old", tasks[0].Title)
+	history, _, err := usecase.GetTaskHistory(ctx, existing.ID.Hex(), repositories.AuditQuery{}, ownerID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
+
+// recordingSink keeps the task events it is given.
+type recordingSink struct {
+	events []TaskEvent
+}
+
+func (s *recordingSink) PublishTaskEvents(ctx context.Context, events []TaskEvent) {
+	s.events = append(s.events, events...)
+}
+
+func TestRunBatch_PublishesEventsOnlyAfterCommit(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	sink := &recordingSink{}
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects, WithUnitOfWork(repos.UnitOfWork), WithTaskEventSink(sink))
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+
+	_, err := usecase.RunBatch(ctx, []BatchOperation{
+		{Op: BatchCreate, Task: &domain.Task{Title: "Rolled back", Status: StatusPending}},
+		{Op: BatchDelete, TaskID: primitive.NewObjectID().Hex()},
+	}, ownerID)
+	require.ErrorIs(t, err, ErrTaskNotFound)
+	assert.Empty(t, sink.events, "a rolled back batch publishes nothing")
+
+	results, err := usecase.RunBatch(ctx, []BatchOperation{
+		{Op: BatchCreate, Task: &domain.Task{Title: "First", Status: StatusPending}},
+		{Op: BatchCreate, Task: &domain.Task{Title: "Second", Status: StatusPending}},
+	}, ownerID)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	require.Len(t, sink.events, 2)
+	for i, event := range sink.events {
+		assert.Equal(t, EventTaskCreated, event.Type)
+		assert.Equal(t, results[i].Task.ID, event.Task.ID)
+		assert.Equal(t, []primitive.ObjectID{ownerID}, event.Audience)
+	}
+}
+
+// TestRunBatch_RefusedWithoutTransactions runs a batch on a database that
+// cannot undo part of one, and expects none of it to be applied.
+func TestRunBatch_RefusedWithoutTransactions(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	ops := []BatchOperation{{Op: BatchCreate, Task: &domain.Task{Title: "New", Status: StatusPending}}}
+
+	standalone := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects, WithUnitOfWork(noTransactions{}))
+	standaloneResults, standaloneErr := standalone.RunBatch(ctx, ops, ownerID)
+	withoutUnit := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	withoutUnitResults, withoutUnitErr := withoutUnit.RunBatch(ctx, ops, ownerID)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, standaloneErr, repositories.ErrTransactionsUnsupported)
+	assert.Nil(t, standaloneResults)
+	assert.ErrorIs(t, withoutUnitErr, repositories.ErrTransactionsUnsupported)
+	assert.Nil(t, withoutUnitResults)
+	tasks, err := withoutUnit.GetUserTasks(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
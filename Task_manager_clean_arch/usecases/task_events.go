@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Task event types.
+const (
+	EventTaskCreated = "task.created"
+	EventTaskUpdated = "task.updated"
+	EventTaskDeleted = "task.deleted"
+	// EventTaskStatusChanged comes with the task.updated event of an update
+	// that changed the task's status.
+	EventTaskStatusChanged = "task.status_changed"
+	// EventTaskAccessRevoked comes with the task.updated event of an update
+	// that took the task away from some of its audience, and goes only to
+	// them. It carries nothing of the task but its ID.
+	EventTaskAccessRevoked = "task.access_revoked"
+)
+
+// TaskEventTypes lists every task event type.
+func TaskEventTypes() []string {
+	return []string{EventTaskCreated, EventTaskUpdated, EventTaskDeleted, EventTaskStatusChanged, EventTaskAccessRevoked}
+}
+
+// TaskEvent describes a change to a task that has been stored. Task is the
+// task after the change, as it was when it was deleted, or only its ID for
+// task.access_revoked.
+type TaskEvent struct {
+	ID   primitive.ObjectID
+	Type string
+	Task domain.Task
+	// PreviousStatus is the status a task.status_changed event moved from.
+	PreviousStatus string
+	ActorID        primitive.ObjectID
+	// Audience are the users who could see the task: its owner, assignee,
+	// collaborators and the members of its project. Users who can see every
+	// task through their roles are not listed; sinks look them up.
+	Audience   []primitive.ObjectID
+	OccurredAt time.Time
+}
+
+// ITaskEventSink receives task events. Events are only published once the
+// changes they describe are committed, and a sink cannot undo the change,
+// so it handles its own errors.
+type ITaskEventSink interface {
+	PublishTaskEvents(ctx context.Context, events []TaskEvent)
+}
+
+// WithTaskEventSink adds a sink that is told about every task change. The
+// option can be given more than once.
+func WithTaskEventSink(sink ITaskEventSink) TaskUsecaseOption {
+	return func(uc *taskUsecase) { uc.sinks = append(uc.sinks, sink) }
+}
+
+type pendingEventsKey struct{}
+
+// withPendingEvents makes emit collect events in pending instead of
+// publishing them, for a unit of work that may still be rolled back.
+func withPendingEvents(ctx context.Context, pending *[]TaskEvent) context.Context {
+	return context.WithValue(ctx, pendingEventsKey{}, pending)
+}
+
+// emit publishes an event about task, which has just been stored. before is
+// the previous version for an update and nil otherwise; an update that
+// changed the status also emits task.status_changed, and one that left
+// users of the previous audience out emits task.access_revoked to them.
+func (uc *taskUsecase) emit(ctx context.Context, eventType string, before, task *domain.Task, actorID primitive.ObjectID) error {
+	if len(uc.sinks) == 0 {
+		return nil
+	}
+	project, err := uc.projectOf(ctx, task)
+	if err != nil {
+		return err
+	}
+	event := TaskEvent{
+		ID:         primitive.NewObjectID(),
+		Type:       eventType,
+		Task:       *task,
+		ActorID:    actorID,
+		Audience:   taskAudience(task, project),
+		OccurredAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	events := []TaskEvent{event}
+	if before != nil && before.Status != task.Status {
+		event.ID = primitive.NewObjectID()
+		event.Type = EventTaskStatusChanged
+		event.PreviousStatus = before.Status
+		events = append(events, event)
+	}
+	if before != nil {
+		revoked, err := uc.revokedAudience(ctx, before, event.Audience)
+		if err != nil {
+			return err
+		}
+		if len(revoked) > 0 {
+			events = append(events, TaskEvent{
+				ID:         primitive.NewObjectID(),
+				Type:       EventTaskAccessRevoked,
+				Task:       domain.Task{ID: task.ID},
+				ActorID:    actorID,
+				Audience:   revoked,
+				OccurredAt: event.OccurredAt,
+			})
+		}
+	}
+
+	if pending, ok := ctx.Value(pendingEventsKey{}).(*[]TaskEvent); ok {
+		*pending = append(*pending, events...)
+		return nil
+	}
+	uc.publish(ctx, events)
+	return nil
+}
+
+// publish hands events to every sink. The request that caused them may end
+// as soon as they are published, so the sinks get a context that outlives it.
+func (uc *taskUsecase) publish(ctx context.Context, events []TaskEvent) {
+	if len(events) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for _, sink := range uc.sinks {
+		sink.PublishTaskEvents(ctx, events)
+	}
+}
+
+// revokedAudience lists the users who could see before but are not in
+// audience, the audience of the task after the change.
+func (uc *taskUsecase) revokedAudience(ctx context.Context, before *domain.Task, audience []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	project, err := uc.projectOf(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	var revoked []primitive.ObjectID
+	for _, userID := range taskAudience(before, project) {
+		if !slices.Contains(audience, userID) {
+			revoked = append(revoked, userID)
+		}
+	}
+	return revoked, nil
+}
+
+// taskAudience lists the users with any permission on task, without repeats.
+func taskAudience(task *domain.Task, project *domain.Project) []primitive.ObjectID {
+	var audience []primitive.ObjectID
+	seen := map[primitive.ObjectID]bool{}
+	add := func(id primitive.ObjectID) {
+		if !id.IsZero() && !seen[id] {
+			seen[id] = true
+			audience = append(audience, id)
+		}
+	}
+	add(task.UserID)
+	add(task.AssigneeID)
+	for _, c := range task.Collaborators {
+		add(c.UserID)
+	}
+	if project != nil {
+		for _, m := range project.Members {
+			add(m.UserID)
+		}
+	}
+	return audience
+}
@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrInvalidTaskPatch is returned for a patch that sets a field to a value
+// the field cannot hold.
+var ErrInvalidTaskPatch = errors.New("invalid task patch")
+
+// TaskPatch is a partial update of a task's editable fields, such as a JSON
+// merge patch describes. Nil fields are left as they are.
+type TaskPatch struct {
+	Title       *string
+	Description *string
+	// DueDate set to the zero time clears the due date.
+	DueDate *time.Time
+	Status  *string
+	// ParentID set to the zero ID makes the task top-level.
+	ParentID  *primitive.ObjectID
+	BlockedBy *[]primitive.ObjectID
+	Tags      *[]string
+	// Recurrence holds the RRULE; set to "" it stops the task repeating.
+	Recurrence *string
+	TimeZone   *string
+	// Version, when not zero, must match the task's current version.
+	Version int64
+}
+
+// requestFor returns the full update the patch amounts to for task.
+func (p *TaskPatch) requestFor(task *domain.Task) (*domain.Task, error) {
+	requested := &domain.Task{
+		Title:       task.Title,
+		Description: task.Description,
+		Duedate:     task.Duedate,
+		Status:      task.Status,
+		ParentID:    task.ParentID,
+		BlockedBy:   task.BlockedBy,
+		Tags:        task.Tags,
+	}
+	if p.Title != nil {
+		if strings.TrimSpace(*p.Title) == "" {
+			return nil, fmt.Errorf("%w: title cannot be empty", ErrInvalidTaskPatch)
+		}
+		requested.Title = *p.Title
+	}
+	if p.Description != nil {
+		requested.Description = *p.Description
+	}
+	if p.DueDate != nil {
+		requested.Duedate = *p.DueDate
+	}
+	if p.Status != nil {
+		requested.Status = *p.Status
+	}
+	if p.ParentID != nil {
+		requested.ParentID = *p.ParentID
+	}
+	if p.BlockedBy != nil {
+		requested.BlockedBy = *p.BlockedBy
+	}
+	if p.Tags != nil {
+		requested.Tags = *p.Tags
+	}
+
+	var rule, timeZone string
+	if task.Recurrence != nil {
+		rule, timeZone = task.Recurrence.Rule, task.Recurrence.TimeZone
+	}
+	if p.Recurrence != nil {
+		rule = *p.Recurrence
+	}
+	if p.TimeZone != nil {
+		timeZone = *p.TimeZone
+	}
+	if rule != "" {
+		requested.Recurrence = &domain.Recurrence{Rule: rule, TimeZone: timeZone}
+	} else if p.TimeZone != nil && *p.TimeZone != "" {
+		return nil, fmt.Errorf("%w: time_zone needs a recurrence rule", ErrInvalidRecurrence)
+	}
+	return requested, nil
+}
+
+// PatchTask applies the same rules as UpdateTask to the fields the patch
+// sets, and writes only the fields that end up changed.
+func (uc *taskUsecase) PatchTask(ctx context.Context, taskID string, patch *TaskPatch, userID primitive.ObjectID) (*domain.Task, error) {
+	task, err := uc.authorizeTask(ctx, taskID, userID, permissionEdit)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(task, patch.Version); err != nil {
+		return nil, err
+	}
+	requested, err := patch.requestFor(task)
+	if err != nil {
+		return nil, err
+	}
+	before := *task
+
+	next, err := uc.applyChanges(ctx, &before, task, requested, userID)
+	if err != nil {
+		return nil, err
+	}
+	fields := changedTaskFields(&before, task)
+	if len(fields) == 0 {
+		return task, nil
+	}
+	version := task.Version
+	err = uc.run(ctx, func(ctx context.Context) error {
+		task.Version = version
+		if err := uc.taskRepo.UpdateFields(ctx, task, fields); err != nil {
+			return updateError(err)
+		}
+		if err := uc.auditUpdate(ctx, &before, task, userID); err != nil {
+			return err
+		}
+		return uc.createOccurrence(ctx, next, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// changedTaskFields lists the stored fields that differ between two
+// versions of a task.
+func changedTaskFields(before, after *domain.Task) []repositories.TaskField {
+	var fields []repositories.TaskField
+	changed := func(field repositories.TaskField, differs bool) {
+		if differs {
+			fields = append(fields, field)
+		}
+	}
+	changed(repositories.TaskFieldTitle, before.Title != after.Title)
+	changed(repositories.TaskFieldDescription, before.Description != after.Description)
+	changed(repositories.TaskFieldDueDate, !before.Duedate.Equal(after.Duedate))
+	changed(repositories.TaskFieldStatus, before.Status != after.Status || len(before.StatusHistory) != len(after.StatusHistory))
+	changed(repositories.TaskFieldParent, before.ParentID != after.ParentID)
+	changed(repositories.TaskFieldBlockedBy, !reflect.DeepEqual(before.BlockedBy, after.BlockedBy))
+	changed(repositories.TaskFieldAssignee, before.AssigneeID != after.AssigneeID)
+	changed(repositories.TaskFieldCollaborators, !reflect.DeepEqual(before.Collaborators, after.Collaborators))
+	changed(repositories.TaskFieldRecurrence, !reflect.DeepEqual(before.Recurrence, after.Recurrence))
+	changed(repositories.TaskFieldTags, !slices.Equal(before.Tags, after.Tags))
+	return fields
+}
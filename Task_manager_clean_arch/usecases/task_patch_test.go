@@ -0,0 +1,123 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/mocks"
+	"taskmanager/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func stringPtr(s string) *string { return &s }
+
+func TestPatchTask_LeavesOtherFieldsAlone(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	due := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	task, err := usecase.CreateTask(ctx, &domain.Task{Title: "Draft", Description: "Notes", Duedate: due, Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+
+	patched, err := usecase.PatchTask(ctx, task.ID.Hex(), &TaskPatch{Status: stringPtr("in_progress"), Version: 1}, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusInProgress, patched.Status)
+	assert.Equal(t, int64(2), patched.Version)
+
+	// --- ASSERT ---
+	found, err := usecase.GetTaskByID(ctx, task.ID.Hex(), ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, "Draft", found.Title)
+	assert.Equal(t, "Notes", found.Description)
+	assert.True(t, due.Equal(found.Duedate))
+	assert.Len(t, found.StatusHistory, 2)
+
+	history, _, err := usecase.GetTaskHistory(ctx, task.ID.Hex(), repositories.AuditQuery{}, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.FieldChange{{Field: "status", Before: StatusPending, After: StatusInProgress}}, history[0].Changes)
+}
+
+func TestPatchTask_WritesOnlyChangedFields(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	existing := &domain.Task{ID: taskID, Title: "Draft", Description: "Notes", Status: StatusPending, UserID: ownerID, Version: 4}
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(existing, nil)
+	mockTaskRepo.On("UpdateFields", mock.Anything, mock.Anything,
+		[]repositories.TaskField{repositories.TaskFieldTitle, repositories.TaskFieldDueDate}).Return(nil)
+	mockAuditRepo.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	due := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	// Setting the description to its current value is not a change.
+	_, err := usecase.PatchTask(context.Background(), taskID.Hex(),
+		&TaskPatch{Title: stringPtr("Final"), Description: stringPtr("Notes"), DueDate: &due}, ownerID)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	mockTaskRepo.AssertExpectations(t)
+	mockTaskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPatchTask_NoChangesWritesNothing(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	existing := &domain.Task{ID: taskID, Title: "Draft", Status: StatusPending, UserID: ownerID, Version: 1}
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(existing, nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	patched, err := usecase.PatchTask(context.Background(), taskID.Hex(), &TaskPatch{Title: stringPtr("Draft")}, ownerID)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), patched.Version)
+	mockTaskRepo.AssertNotCalled(t, "UpdateFields", mock.Anything, mock.Anything, mock.Anything)
+	mockAuditRepo.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+}
+
+func TestPatchTask_Failure_InvalidFields(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	due := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	task, err := usecase.CreateTask(ctx, recurringTaskRequest(StatusPending, due, "FREQ=DAILY", ""), ownerID)
+	require.NoError(t, err)
+	id := task.ID.Hex()
+
+	_, err = usecase.PatchTask(ctx, id, &TaskPatch{Title: stringPtr(" ")}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidTaskPatch)
+	_, err = usecase.PatchTask(ctx, id, &TaskPatch{Status: stringPtr("Shelved")}, ownerID)
+	assert.ErrorIs(t, err, ErrUnknownStatus)
+	// A recurring task cannot lose its due date, but can stop recurring first.
+	_, err = usecase.PatchTask(ctx, id, &TaskPatch{DueDate: &time.Time{}}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+	_, err = usecase.PatchTask(ctx, id, &TaskPatch{DueDate: &time.Time{}, Recurrence: stringPtr("")}, ownerID)
+	assert.NoError(t, err)
+	_, err = usecase.PatchTask(ctx, id, &TaskPatch{TimeZone: stringPtr("Europe/Berlin")}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+	_, err = usecase.PatchTask(ctx, id, &TaskPatch{Title: stringPtr("Stale"), Version: 1}, ownerID)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	// --- ASSERT ---
+	found, err := usecase.GetTaskByID(ctx, id, ownerID)
+	require.NoError(t, err)
+	assert.Nil(t, found.Recurrence)
+	assert.True(t, found.Duedate.IsZero())
+	assert.Equal(t, int64(2), found.Version)
+}
@@ -0,0 +1,241 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	// ErrForbidden is returned when the user can see a task but lacks the
+	// permission the operation needs.
+	ErrForbidden = errors.New("permission denied")
+	// ErrInvalidCollaborator is returned for an unknown collaborator role or
+	// an attempt to share a task with its owner.
+	ErrInvalidCollaborator = errors.New("invalid collaborator")
+	// ErrUserNotFound is returned when an assignee or collaborator does not exist.
+	ErrUserNotFound = errors.New("user not found")
+)
+
+// taskPermission is what a user may do with a task. Each level includes the
+// ones below it.
+type taskPermission int
+
+const (
+	permissionNone taskPermission = iota
+	permissionView
+	permissionEdit
+	// permissionManage covers deleting a task and changing who it is shared with.
+	permissionManage
+)
+
+// permissionFor works out a user's permission on a task. The owner manages
+// it, the assignee and editors can change it, and viewers can read it.
+// Members of the task's project, which may be nil, hold at least the
+// permission of their project role, and every user holds at least granted,
+// the permission their roles give them over all tasks. Tasks of archived
+// projects are read-only.
+func permissionFor(task *domain.Task, project *domain.Project, userID primitive.ObjectID, granted taskPermission) taskPermission {
+	permission := max(taskPermissionFor(task, userID), projectPermission(project, userID), granted)
+	if !task.ArchivedAt.IsZero() && permission > permissionView {
+		permission = permissionView
+	}
+	return permission
+}
+
+// taskPermissionFor is the permission the task itself grants userID.
+func taskPermissionFor(task *domain.Task, userID primitive.ObjectID) taskPermission {
+	if task.UserID == userID {
+		return permissionManage
+	}
+	if task.AssigneeID == userID {
+		return permissionEdit
+	}
+	for _, c := range task.Collaborators {
+		if c.UserID == userID {
+			if c.Role == domain.CollaboratorEditor {
+				return permissionEdit
+			}
+			return permissionView
+		}
+	}
+	return permissionNone
+}
+
+// projectOf loads the project a task belongs to. It returns nil for personal
+// tasks and for tasks whose project no longer exists.
+func (uc *taskUsecase) projectOf(ctx context.Context, task *domain.Task) (*domain.Project, error) {
+	if task.ProjectID.IsZero() {
+		return nil, nil
+	}
+	project, err := uc.projectRepo.GetByID(ctx, task.ProjectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	return project, err
+}
+
+// grantedPermission is the permission userID's roles give them over every
+// task. It is none when the usecase has no permission checker.
+func (uc *taskUsecase) grantedPermission(ctx context.Context, userID primitive.ObjectID) (taskPermission, error) {
+	if uc.permissions == nil {
+		return permissionNone, nil
+	}
+	permissions, err := uc.permissions.UserPermissions(ctx, userID)
+	if err != nil {
+		return permissionNone, err
+	}
+	switch {
+	case slices.Contains(permissions, domain.PermTaskManageAny):
+		return permissionManage, nil
+	case slices.Contains(permissions, domain.PermTaskUpdateAny):
+		return permissionEdit, nil
+	case slices.Contains(permissions, domain.PermTaskReadAny):
+		return permissionView, nil
+	}
+	return permissionNone, nil
+}
+
+// permission loads what it takes to work out userID's permission on task.
+func (uc *taskUsecase) permission(ctx context.Context, task *domain.Task, userID primitive.ObjectID) (taskPermission, error) {
+	project, err := uc.projectOf(ctx, task)
+	if err != nil {
+		return permissionNone, err
+	}
+	granted, err := uc.grantedPermission(ctx, userID)
+	if err != nil {
+		return permissionNone, err
+	}
+	return permissionFor(task, project, userID, granted), nil
+}
+
+// authorizeTask loads a task and checks that userID holds at least the needed
+// permission. Tasks the user cannot see are reported as not found, so their
+// existence is not revealed.
+func (uc *taskUsecase) authorizeTask(ctx context.Context, taskID string, userID primitive.ObjectID, need taskPermission) (*domain.Task, error) {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return nil, ErrInvalidTaskID
+	}
+
+	task, err := uc.taskRepo.GetByID(ctx, objectID)
+	if err != nil {
+		return nil, ErrTaskNotFound
+	}
+
+	permission, err := uc.permission(ctx, task, userID)
+	if err != nil {
+		return nil, err
+	}
+	if permission < permissionView {
+		return nil, ErrTaskNotFound
+	}
+	if permission < need {
+		return nil, ErrForbidden
+	}
+	return task, nil
+}
+
+// checkUserExists fails with ErrUserNotFound unless id names a user.
+func (uc *taskUsecase) checkUserExists(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := uc.userRepo.FindByID(ctx, id); err != nil {
+		return fmt.Errorf("%w: %s", ErrUserNotFound, id.Hex())
+	}
+	return nil
+}
+
+// AssignTask makes assigneeID the user working on the task. Anyone who can
+// edit the task can assign it.
+func (uc *taskUsecase) AssignTask(ctx context.Context, taskID string, assigneeID primitive.ObjectID, userID primitive.ObjectID) (*domain.Task, error) {
+	task, err := uc.authorizeTask(ctx, taskID, userID, permissionEdit)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkUserExists(ctx, assigneeID); err != nil {
+		return nil, err
+	}
+
+	before := *task
+	task.AssigneeID = assigneeID
+	if err := uc.saveUpdate(ctx, &before, task, userID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// UnassignTask clears the task's assignee.
+func (uc *taskUsecase) UnassignTask(ctx context.Context, taskID string, userID primitive.ObjectID) (*domain.Task, error) {
+	task, err := uc.authorizeTask(ctx, taskID, userID, permissionEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *task
+	task.AssigneeID = primitive.NilObjectID
+	if err := uc.saveUpdate(ctx, &before, task, userID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// ShareTask grants collaboratorID the given role on the task, replacing any
+// role they already had. Only the owner can share a task.
+func (uc *taskUsecase) ShareTask(ctx context.Context, taskID string, collaboratorID primitive.ObjectID, role string, userID primitive.ObjectID) (*domain.Task, error) {
+	if role != domain.CollaboratorViewer && role != domain.CollaboratorEditor {
+		return nil, fmt.Errorf("%w: role must be %q or %q", ErrInvalidCollaborator, domain.CollaboratorViewer, domain.CollaboratorEditor)
+	}
+	task, err := uc.authorizeTask(ctx, taskID, userID, permissionManage)
+	if err != nil {
+		return nil, err
+	}
+	if collaboratorID == task.UserID {
+		return nil, fmt.Errorf("%w: the owner already has full access", ErrInvalidCollaborator)
+	}
+	if err := uc.checkUserExists(ctx, collaboratorID); err != nil {
+		return nil, err
+	}
+
+	before := *task
+	collaborators := make([]domain.Collaborator, 0, len(task.Collaborators)+1)
+	for _, c := range task.Collaborators {
+		if c.UserID != collaboratorID {
+			collaborators = append(collaborators, c)
+		}
+	}
+	task.Collaborators = append(collaborators, domain.Collaborator{UserID: collaboratorID, Role: role})
+	if err := uc.saveUpdate(ctx, &before, task, userID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// UnshareTask removes collaboratorID from the task. The owner can remove
+// anyone, and collaborators can remove themselves.
+func (uc *taskUsecase) UnshareTask(ctx context.Context, taskID string, collaboratorID primitive.ObjectID, userID primitive.ObjectID) (*domain.Task, error) {
+	need := permissionManage
+	if collaboratorID == userID {
+		need = permissionView
+	}
+	task, err := uc.authorizeTask(ctx, taskID, userID, need)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *task
+	var collaborators []domain.Collaborator
+	for _, c := range task.Collaborators {
+		if c.UserID != collaboratorID {
+			collaborators = append(collaborators, c)
+		}
+	}
+	task.Collaborators = collaborators
+	if err := uc.saveUpdate(ctx, &before, task, userID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAssignTask_AssigneeCanSeeAndEditTask(t *testing.T) {
+	f := newFixture(t, withTask("Shared work"))
+	alice := f.addUser(t, "alice")
+	ctx := context.Background()
+	taskID := f.task.ID.Hex()
+
+	_, err := f.tasks.GetTaskByID(ctx, taskID, alice)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	assigned, err := f.tasks.AssignTask(ctx, taskID, alice, f.user)
+	assert.NoError(t, err)
+	assert.Equal(t, alice, assigned.AssigneeID)
+
+	_, err = f.tasks.UpdateTask(ctx, taskID, &domain.Task{Title: "Started", Status: StatusInProgress}, alice)
+	assert.NoError(t, err)
+
+	tasks, _, err := f.tasks.ListTasks(ctx, repositories.TaskQuery{Scope: repositories.ScopeAssigned}, alice)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	// The assignee can work on the task but not delete it.
+	assert.ErrorIs(t, f.tasks.DeleteTask(ctx, taskID, 0, alice), ErrForbidden)
+
+	unassigned, err := f.tasks.UnassignTask(ctx, taskID, alice)
+	assert.NoError(t, err)
+	assert.True(t, unassigned.AssigneeID.IsZero())
+	_, err = f.tasks.GetTaskByID(ctx, taskID, alice)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestAssignTask_Failure_UnknownUser(t *testing.T) {
+	f := newFixture(t, withTask("Shared work"))
+
+	_, err := f.tasks.AssignTask(context.Background(), f.task.ID.Hex(), primitive.NewObjectID(), f.user)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestShareTask_ViewerAndEditorPermissions(t *testing.T) {
+	f := newFixture(t, withTask("Shared work"))
+	alice := f.addUser(t, "alice")
+	bob := f.addUser(t, "bob")
+	ctx := context.Background()
+	taskID := f.task.ID.Hex()
+
+	_, err := f.tasks.ShareTask(ctx, taskID, alice, domain.CollaboratorViewer, f.user)
+	assert.NoError(t, err)
+
+	_, err = f.tasks.GetTaskByID(ctx, taskID, alice)
+	assert.NoError(t, err)
+	_, err = f.tasks.UpdateTask(ctx, taskID, &domain.Task{Title: "Renamed", Status: StatusPending}, alice)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = f.tasks.AssignTask(ctx, taskID, alice, alice)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	// Sharing again replaces the role rather than adding a second entry.
+	shared, err := f.tasks.ShareTask(ctx, taskID, alice, domain.CollaboratorEditor, f.user)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Collaborator{{UserID: alice, Role: domain.CollaboratorEditor}}, shared.Collaborators)
+	_, err = f.tasks.UpdateTask(ctx, taskID, &domain.Task{Title: "Renamed", Status: StatusPending}, alice)
+	assert.NoError(t, err)
+
+	// Only the owner manages sharing.
+	_, err = f.tasks.ShareTask(ctx, taskID, bob, domain.CollaboratorViewer, alice)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	// A collaborator may leave on their own.
+	left, err := f.tasks.UnshareTask(ctx, taskID, alice, alice)
+	assert.NoError(t, err)
+	assert.Empty(t, left.Collaborators)
+	_, err = f.tasks.GetTaskByID(ctx, taskID, alice)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestShareTask_Failure_InvalidCollaborator(t *testing.T) {
+	f := newFixture(t, withTask("Shared work"))
+	alice := f.addUser(t, "alice")
+	ctx := context.Background()
+
+	_, err := f.tasks.ShareTask(ctx, f.task.ID.Hex(), alice, "admin", f.user)
+	assert.ErrorIs(t, err, ErrInvalidCollaborator)
+	_, err = f.tasks.ShareTask(ctx, f.task.ID.Hex(), f.user, domain.CollaboratorViewer, f.user)
+	assert.ErrorIs(t, err, ErrInvalidCollaborator)
+}
+
+func TestListTasks_Failure_UnknownScope(t *testing.T) {
+	f := newFixture(t, withTask("Shared work"))
+
+	_, _, err := f.tasks.ListTasks(context.Background(), repositories.TaskQuery{Scope: "everything"}, f.user)
+	assert.ErrorIs(t, err, ErrInvalidTaskQuery)
+}
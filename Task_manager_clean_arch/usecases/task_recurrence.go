@@ -0,0 +1,166 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"taskmanager/domain"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultOccurrencePreview = 5
+	MaxOccurrencePreview     = 100
+)
+
+// applyRecurrence validates the requested rule and time zone and sets them on
+// task; a nil request makes the task a one-off. A task that already recurs
+// keeps its place in the series, so editing the rule changes the occurrences
+// still to come. task.ID must be set, as a new series is named after it.
+func applyRecurrence(task *domain.Task, requested *domain.Recurrence) error {
+	if requested == nil {
+		task.Recurrence = nil
+		return nil
+	}
+	rule, err := ParseRecurrenceRule(requested.Rule)
+	if err != nil {
+		return err
+	}
+	timeZone := requested.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return fmt.Errorf("%w: unknown time zone %q", ErrInvalidRecurrence, timeZone)
+	}
+	if task.Duedate.IsZero() {
+		return fmt.Errorf("%w: a recurring task needs a due date", ErrInvalidRecurrence)
+	}
+
+	if task.Recurrence == nil {
+		task.Recurrence = &domain.Recurrence{Start: task.Duedate.UTC(), SeriesID: task.ID, Occurrence: 1}
+	} else {
+		recurrence := *task.Recurrence
+		task.Recurrence = &recurrence
+	}
+	task.Recurrence.Rule = rule.String()
+	task.Recurrence.TimeZone = timeZone
+	return nil
+}
+
+// upcomingOccurrences returns the due dates of up to n occurrences after the
+// one r describes, on the wall clock of the series' time zone.
+func upcomingOccurrences(r *domain.Recurrence, n int) ([]time.Time, error) {
+	rule, err := ParseRecurrenceRule(r.Rule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidRecurrence, r.TimeZone)
+	}
+	occurrences := rule.Occurrences(r.Start, loc, r.Occurrence+n)
+	if len(occurrences) <= r.Occurrence {
+		return []time.Time{}, nil
+	}
+	upcoming := occurrences[r.Occurrence:]
+	for i, t := range upcoming {
+		upcoming[i] = t.In(loc)
+	}
+	return upcoming, nil
+}
+
+// openingStatus is the status a new occurrence starts in: the status the
+// previous one was created with, unless that was already a done status.
+func (uc *taskUsecase) openingStatus(task *domain.Task) string {
+	if len(task.StatusHistory) > 0 && !uc.isDone(task.StatusHistory[0].To) {
+		return task.StatusHistory[0].To
+	}
+	if status, err := uc.workflow.Canonical(StatusPending); err == nil {
+		return status
+	}
+	return task.Status
+}
+
+// nextOccurrence returns the next task of the series when task has just
+// been completed, and links task to it; otherwise it returns nil. The
+// successor is not stored yet: createOccurrence does that once task is
+// saved. Each occurrence spawns at most one successor, so reopening and
+// completing it again does not duplicate the series. Nothing is returned
+// once the rule's COUNT or UNTIL is exhausted.
+func (uc *taskUsecase) nextOccurrence(before, task *domain.Task, userID primitive.ObjectID) (*domain.Task, error) {
+	if task.Recurrence == nil || !task.Recurrence.NextTaskID.IsZero() || uc.isDone(before.Status) || !uc.isDone(task.Status) {
+		return nil, nil
+	}
+	upcoming, err := upcomingOccurrences(task.Recurrence, 1)
+	if err != nil || len(upcoming) == 0 {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	status := uc.openingStatus(task)
+	next := &domain.Task{
+		ID:            primitive.NewObjectID(),
+		Title:         task.Title,
+		Description:   task.Description,
+		Duedate:       upcoming[0].UTC(),
+		Status:        status,
+		UserID:        task.UserID,
+		CreatedAt:     now,
+		StatusHistory: []domain.StatusChange{{To: status, ChangedBy: userID, ChangedAt: now}},
+		ParentID:      task.ParentID,
+		AssigneeID:    task.AssigneeID,
+		Collaborators: append([]domain.Collaborator(nil), task.Collaborators...),
+		Tags:          append([]string(nil), task.Tags...),
+		ProjectID:     task.ProjectID,
+		Recurrence: &domain.Recurrence{
+			Rule:       task.Recurrence.Rule,
+			TimeZone:   task.Recurrence.TimeZone,
+			Start:      task.Recurrence.Start,
+			SeriesID:   task.Recurrence.SeriesID,
+			Occurrence: task.Recurrence.Occurrence + 1,
+		},
+	}
+
+	recurrence := *task.Recurrence
+	recurrence.NextTaskID = next.ID
+	task.Recurrence = &recurrence
+	return next, nil
+}
+
+// createOccurrence stores the successor nextOccurrence returned, if any. It
+// runs in the unit of work that saved the completed task, after the save,
+// so an update that loses a race or fails creates nothing.
+func (uc *taskUsecase) createOccurrence(ctx context.Context, next *domain.Task, userID primitive.ObjectID) error {
+	if next == nil {
+		return nil
+	}
+	next.Version = 0
+	if err := uc.taskRepo.Create(ctx, next); err != nil {
+		return err
+	}
+	if err := uc.auditRepo.Append(ctx, newTaskAuditEntry(domain.AuditActionCreate, nil, next, userID)); err != nil {
+		return err
+	}
+	return uc.emit(ctx, EventTaskCreated, nil, next, userID)
+}
+
+// GetOccurrences previews the due dates of the next n occurrences of a
+// recurring task after this one.
+func (uc *taskUsecase) GetOccurrences(ctx context.Context, taskID string, n int, userID primitive.ObjectID) ([]time.Time, error) {
+	task, err := uc.GetTaskByID(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Recurrence == nil {
+		return nil, fmt.Errorf("%w: task does not recur", ErrInvalidRecurrence)
+	}
+	if n <= 0 {
+		n = DefaultOccurrencePreview
+	}
+	if n > MaxOccurrencePreview {
+		n = MaxOccurrencePreview
+	}
+	return upcomingOccurrences(task.Recurrence, n)
+}
@@ -0,0 +1,169 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recurringTaskRequest builds a create or update request for a recurring task.
+func recurringTaskRequest(status string, due time.Time, rule, timeZone string) *domain.Task {
+	return &domain.Task{
+		Title:      "Water plants",
+		Status:     status,
+		Duedate:    due,
+		Recurrence: &domain.Recurrence{Rule: rule, TimeZone: timeZone},
+	}
+}
+
+func TestCompletingRecurringTask_CreatesNextOccurrence(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	// Clocks go forward on 8 March 2026 in New York.
+	due := time.Date(2026, 3, 6, 9, 0, 0, 0, newYork) // a Friday
+	first, err := usecase.CreateTask(ctx, recurringTaskRequest(StatusPending, due, "freq=weekly;byday=MO,FR", "America/New_York"), ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.Recurrence{
+		Rule: "FREQ=WEEKLY;BYDAY=MO,FR", TimeZone: "America/New_York", Start: due.UTC(), SeriesID: first.ID, Occurrence: 1,
+	}, first.Recurrence)
+
+	completed, err := usecase.UpdateTask(ctx, first.ID.Hex(), recurringTaskRequest(StatusCompleted, due, first.Recurrence.Rule, "America/New_York"), ownerID)
+	require.NoError(t, err)
+	require.False(t, completed.Recurrence.NextTaskID.IsZero())
+
+	next, err := usecase.GetTaskByID(ctx, completed.Recurrence.NextTaskID.Hex(), ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, next.Status)
+	assert.Equal(t, "2026-03-09 09:00 EDT", next.Duedate.In(newYork).Format("2006-01-02 15:04 MST"))
+	assert.Equal(t, first.ID, next.Recurrence.SeriesID)
+	assert.Equal(t, 2, next.Recurrence.Occurrence)
+
+	history, _, err := usecase.GetTaskHistory(ctx, next.ID.Hex(), repositories.AuditQuery{}, ownerID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, domain.AuditActionCreate, history[0].Action)
+
+	// Reopening and completing again must not duplicate the next occurrence.
+	_, err = usecase.UpdateTask(ctx, first.ID.Hex(), recurringTaskRequest(StatusReopened, due, first.Recurrence.Rule, "America/New_York"), ownerID)
+	require.NoError(t, err)
+	_, err = usecase.UpdateTask(ctx, first.ID.Hex(), recurringTaskRequest(StatusCompleted, due, first.Recurrence.Rule, "America/New_York"), ownerID)
+	require.NoError(t, err)
+	tasks, err := repos.Tasks.GetAllByUserID(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+// conflictingTaskRepository loses every race to save a task.
+type conflictingTaskRepository struct {
+	repositories.ITaskRepository
+}
+
+func (r conflictingTaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	return repositories.ErrVersionConflict
+}
+
+func (r conflictingTaskRepository) UpdateFields(ctx context.Context, task *domain.Task, fields []repositories.TaskField) error {
+	return repositories.ErrVersionConflict
+}
+
+func TestCompletingRecurringTask_LostRaceCreatesNoOccurrence(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	due := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+	task, err := usecase.CreateTask(ctx, recurringTaskRequest(StatusPending, due, "FREQ=DAILY", ""), ownerID)
+	require.NoError(t, err)
+
+	racing := NewTaskUsecase(conflictingTaskRepository{repos.Tasks}, repos.Users, repos.Audit, repos.Tags, repos.Projects, WithUnitOfWork(repos.UnitOfWork))
+	_, err = racing.UpdateTask(ctx, task.ID.Hex(), recurringTaskRequest(StatusCompleted, due, task.Recurrence.Rule, ""), ownerID)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	completed := StatusCompleted
+	_, err = racing.PatchTask(ctx, task.ID.Hex(), &TaskPatch{Status: &completed}, ownerID)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	tasks, err := repos.Tasks.GetAllByUserID(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, StatusPending, tasks[0].Status)
+	assert.True(t, tasks[0].Recurrence.NextTaskID.IsZero())
+	entries, _, err := repos.Audit.List(ctx, repositories.AuditQuery{ActorID: ownerID})
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestCompletingRecurringTask_StopsAtCount(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	due := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	task, err := usecase.CreateTask(ctx, recurringTaskRequest(StatusPending, due, "FREQ=DAILY;COUNT=2", ""), ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", task.Recurrence.TimeZone)
+
+	for i := 0; i < 2; i++ {
+		task, err = usecase.UpdateTask(ctx, task.ID.Hex(), recurringTaskRequest(StatusCompleted, task.Duedate, task.Recurrence.Rule, ""), ownerID)
+		require.NoError(t, err)
+		if task.Recurrence.NextTaskID.IsZero() {
+			break
+		}
+		task, err = usecase.GetTaskByID(ctx, task.Recurrence.NextTaskID.Hex(), ownerID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, task.Recurrence.Occurrence)
+	assert.True(t, task.Recurrence.NextTaskID.IsZero())
+
+	tasks, err := repos.Tasks.GetAllByUserID(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestRecurringTask_Failure_InvalidRule(t *testing.T) {
+	usecase := newRelationsUsecase(t)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	due := time.Date(2026, 5, 1, 18, 0, 0, 0, time.UTC)
+
+	_, err := usecase.CreateTask(ctx, recurringTaskRequest(StatusPending, due, "FREQ=HOURLY", ""), ownerID)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+
+	_, err = usecase.CreateTask(ctx, recurringTaskRequest(StatusPending, due, "FREQ=DAILY", "Mars/Olympus_Mons"), ownerID)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+
+	_, err = usecase.CreateTask(ctx, recurringTaskRequest(StatusPending, time.Time{}, "FREQ=DAILY", ""), ownerID)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+}
+
+func TestGetOccurrences(t *testing.T) {
+	usecase := newRelationsUsecase(t)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	due := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	task, err := usecase.CreateTask(ctx, recurringTaskRequest(StatusPending, due, "FREQ=MONTHLY;BYMONTHDAY=-1", ""), ownerID)
+	require.NoError(t, err)
+
+	occurrences, err := usecase.GetOccurrences(ctx, task.ID.Hex(), 2, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2026-02-28 12:00 UTC", "2026-03-31 12:00 UTC"}, occurrenceDates(occurrences, time.UTC))
+
+	_, err = usecase.GetOccurrences(ctx, task.ID.Hex(), 2, primitive.NewObjectID())
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	oneOff, err := usecase.CreateTask(ctx, &domain.Task{Title: "Once", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	_, err = usecase.GetOccurrences(ctx, oneOff.ID.Hex(), 2, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidRecurrence)
+}
@@ -0,0 +1,268 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"taskmanager/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	// ErrInvalidTaskRelation is returned when a parent or blocker does not
+	// exist, is not visible to the user, or would make a task its own ancestor.
+	ErrInvalidTaskRelation = errors.New("invalid task relation")
+	// ErrDependencyCycle is returned when a blocked-by link would close a loop.
+	ErrDependencyCycle = errors.New("dependency cycle")
+	// ErrOpenBlockers is returned when a task is completed before its blockers.
+	ErrOpenBlockers = errors.New("task has open blockers")
+)
+
+// TaskDependencies is the dependency neighborhood of one task.
+type TaskDependencies struct {
+	// BlockedBy are the tasks that must be done first, in the order stored.
+	BlockedBy []domain.Task
+	// Blocking are the tasks waiting on this one, oldest first.
+	Blocking []domain.Task
+}
+
+// visibleTasks keeps the tasks userID may see, loading each project they
+// belong to once.
+func (uc *taskUsecase) visibleTasks(ctx context.Context, tasks []domain.Task, userID primitive.ObjectID) ([]domain.Task, error) {
+	granted, err := uc.grantedPermission(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	projects := map[primitive.ObjectID]*domain.Project{}
+	visible := tasks[:0]
+	for _, task := range tasks {
+		project, loaded := projects[task.ProjectID]
+		if !loaded {
+			var err error
+			if project, err = uc.projectOf(ctx, &task); err != nil {
+				return nil, err
+			}
+			projects[task.ProjectID] = project
+		}
+		if permissionFor(&task, project, userID, granted) >= permissionView {
+			visible = append(visible, task)
+		}
+	}
+	return visible, nil
+}
+
+// dedupeIDs drops repeated IDs, keeping the first occurrence of each.
+func dedupeIDs(ids []primitive.ObjectID) []primitive.ObjectID {
+	if len(ids) == 0 {
+		return nil
+	}
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	var unique []primitive.ObjectID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// applyRelations validates and sets a task's parent and blockers. task.ID is
+// zero for a task that has not been created yet, which cannot be in a cycle.
+func (uc *taskUsecase) applyRelations(ctx context.Context, task *domain.Task, parentID primitive.ObjectID, blockedBy []primitive.ObjectID, userID primitive.ObjectID) error {
+	blockedBy = dedupeIDs(blockedBy)
+
+	if !task.ID.IsZero() {
+		if parentID == task.ID {
+			return fmt.Errorf("%w: a task cannot be its own parent", ErrInvalidTaskRelation)
+		}
+		for _, id := range blockedBy {
+			if id == task.ID {
+				return fmt.Errorf("%w: a task cannot block itself", ErrDependencyCycle)
+			}
+		}
+	}
+
+	referenced := blockedBy
+	if !parentID.IsZero() {
+		referenced = append([]primitive.ObjectID{parentID}, blockedBy...)
+	}
+	if len(referenced) > 0 {
+		found, err := uc.taskRepo.GetByIDs(ctx, referenced)
+		if err != nil {
+			return err
+		}
+		found, err = uc.visibleTasks(ctx, found, userID)
+		if err != nil {
+			return err
+		}
+		visible := make(map[primitive.ObjectID]bool, len(found))
+		for _, t := range found {
+			visible[t.ID] = true
+		}
+		for _, id := range referenced {
+			if !visible[id] {
+				return fmt.Errorf("%w: task %s not found", ErrInvalidTaskRelation, id.Hex())
+			}
+		}
+	}
+
+	if !task.ID.IsZero() {
+		if err := uc.checkAncestry(ctx, task.ID, parentID); err != nil {
+			return err
+		}
+		if err := uc.checkDependencyCycle(ctx, task.ID, blockedBy); err != nil {
+			return err
+		}
+	}
+
+	task.ParentID = parentID
+	task.BlockedBy = blockedBy
+	return nil
+}
+
+// checkAncestry walks up from parentID and fails if it reaches taskID.
+func (uc *taskUsecase) checkAncestry(ctx context.Context, taskID, parentID primitive.ObjectID) error {
+	seen := map[primitive.ObjectID]bool{}
+	for current := parentID; !current.IsZero() && !seen[current]; {
+		if current == taskID {
+			return fmt.Errorf("%w: a task cannot be a subtask of its own subtask", ErrInvalidTaskRelation)
+		}
+		seen[current] = true
+		ancestor, err := uc.taskRepo.GetByID(ctx, current)
+		if err != nil {
+			// A dangling parent reference ends the chain.
+			return nil
+		}
+		current = ancestor.ParentID
+	}
+	return nil
+}
+
+// checkDependencyCycle follows blocked-by links breadth first from the new
+// blockers and fails if any path leads back to taskID.
+func (uc *taskUsecase) checkDependencyCycle(ctx context.Context, taskID primitive.ObjectID, blockedBy []primitive.ObjectID) error {
+	seen := map[primitive.ObjectID]bool{}
+	frontier := blockedBy
+	for len(frontier) > 0 {
+		tasks, err := uc.taskRepo.GetByIDs(ctx, frontier)
+		if err != nil {
+			return err
+		}
+		frontier = nil
+		for _, t := range tasks {
+			seen[t.ID] = true
+			for _, next := range t.BlockedBy {
+				if next == taskID {
+					return fmt.Errorf("%w: task %s already depends on this task", ErrDependencyCycle, t.ID.Hex())
+				}
+				if !seen[next] {
+					seen[next] = true
+					frontier = append(frontier, next)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isDone reports whether a possibly non-canonical status counts as finished.
+func (uc *taskUsecase) isDone(status string) bool {
+	if canonical, err := uc.workflow.Canonical(status); err == nil {
+		status = canonical
+	}
+	return uc.workflow.IsDone(status)
+}
+
+// checkBlockers fails if after is done while one of its blockers is not.
+// before is nil for a new task. A task that was already done is only checked
+// against blockers added in this change, so reopening a blocker later does not
+// lock its dependents. Blockers that no longer exist do not block.
+func (uc *taskUsecase) checkBlockers(ctx context.Context, before, after *domain.Task) error {
+	if !uc.isDone(after.Status) {
+		return nil
+	}
+	ids := after.BlockedBy
+	if before != nil && uc.isDone(before.Status) {
+		existing := make(map[primitive.ObjectID]bool, len(before.BlockedBy))
+		for _, id := range before.BlockedBy {
+			existing[id] = true
+		}
+		ids = nil
+		for _, id := range after.BlockedBy {
+			if !existing[id] {
+				ids = append(ids, id)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	blockers, err := uc.taskRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	var open []string
+	for _, blocker := range blockers {
+		if !uc.isDone(blocker.Status) {
+			open = append(open, fmt.Sprintf("%q", blocker.Title))
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("%w: %s", ErrOpenBlockers, strings.Join(open, ", "))
+	}
+	return nil
+}
+
+// GetSubtasks returns the direct subtasks of a task the user can see.
+func (uc *taskUsecase) GetSubtasks(ctx context.Context, taskID string, userID primitive.ObjectID) ([]domain.Task, error) {
+	task, err := uc.GetTaskByID(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	subtasks, err := uc.taskRepo.ListSubtasks(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.visibleTasks(ctx, subtasks, userID)
+}
+
+// GetDependencies returns what a task is blocked by and what it blocks.
+func (uc *taskUsecase) GetDependencies(ctx context.Context, taskID string, userID primitive.ObjectID) (*TaskDependencies, error) {
+	task, err := uc.GetTaskByID(ctx, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockers []domain.Task
+	if len(task.BlockedBy) > 0 {
+		if blockers, err = uc.taskRepo.GetByIDs(ctx, task.BlockedBy); err != nil {
+			return nil, err
+		}
+	}
+	if blockers, err = uc.visibleTasks(ctx, blockers, userID); err != nil {
+		return nil, err
+	}
+	byID := make(map[primitive.ObjectID]domain.Task, len(blockers))
+	for _, blocker := range blockers {
+		byID[blocker.ID] = blocker
+	}
+	deps := &TaskDependencies{BlockedBy: []domain.Task{}}
+	for _, id := range task.BlockedBy {
+		if blocker, ok := byID[id]; ok {
+			deps.BlockedBy = append(deps.BlockedBy, blocker)
+		}
+	}
+
+	blocking, err := uc.taskRepo.ListBlockedTasks(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if deps.Blocking, err = uc.visibleTasks(ctx, blocking, userID); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newRelationsUsecase returns a task usecase over in-memory repositories
+// seeded with the given tasks.
+func newRelationsUsecase(t *testing.T, tasks ...*domain.Task) ITaskUsecase {
+	repos := repositories.NewMemoryRepositories()
+	for _, task := range tasks {
+		assert.NoError(t, repos.Tasks.Create(context.Background(), task))
+	}
+	return NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+}
+
+func TestUpdateTask_Failure_DependencyCycle(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	a := &domain.Task{ID: primitive.NewObjectID(), Title: "A", Status: StatusPending, UserID: ownerID}
+	b := &domain.Task{ID: primitive.NewObjectID(), Title: "B", Status: StatusPending, UserID: ownerID, BlockedBy: []primitive.ObjectID{a.ID}}
+	c := &domain.Task{ID: primitive.NewObjectID(), Title: "C", Status: StatusPending, UserID: ownerID, BlockedBy: []primitive.ObjectID{b.ID}}
+	usecase := newRelationsUsecase(t, a, b, c)
+
+	// A -> C -> B -> A would close a loop.
+	_, err := usecase.UpdateTask(context.Background(), a.ID.Hex(),
+		&domain.Task{Title: "A", Status: StatusPending, BlockedBy: []primitive.ObjectID{c.ID}}, ownerID)
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+
+	_, err = usecase.UpdateTask(context.Background(), a.ID.Hex(),
+		&domain.Task{Title: "A", Status: StatusPending, BlockedBy: []primitive.ObjectID{a.ID}}, ownerID)
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+}
+
+func TestUpdateTask_Failure_SubtaskOfOwnSubtask(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	parent := &domain.Task{ID: primitive.NewObjectID(), Title: "Parent", Status: StatusPending, UserID: ownerID}
+	child := &domain.Task{ID: primitive.NewObjectID(), Title: "Child", Status: StatusPending, UserID: ownerID, ParentID: parent.ID}
+	usecase := newRelationsUsecase(t, parent, child)
+
+	_, err := usecase.UpdateTask(context.Background(), parent.ID.Hex(),
+		&domain.Task{Title: "Parent", Status: StatusPending, ParentID: child.ID}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidTaskRelation)
+}
+
+func TestCreateTask_Failure_RelationToInvisibleTask(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	foreign := &domain.Task{ID: primitive.NewObjectID(), Title: "Theirs", Status: StatusPending, UserID: primitive.NewObjectID()}
+	usecase := newRelationsUsecase(t, foreign)
+
+	_, err := usecase.CreateTask(context.Background(),
+		&domain.Task{Title: "Mine", Status: StatusPending, ParentID: foreign.ID}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidTaskRelation)
+}
+
+func TestUpdateTask_CompletionWaitsForBlockers(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	blocker := &domain.Task{ID: primitive.NewObjectID(), Title: "Blocker", Status: StatusInProgress, UserID: ownerID}
+	task := &domain.Task{ID: primitive.NewObjectID(), Title: "Task", Status: StatusInProgress, UserID: ownerID, BlockedBy: []primitive.ObjectID{blocker.ID}}
+	usecase := newRelationsUsecase(t, blocker, task)
+	ctx := context.Background()
+
+	_, err := usecase.UpdateTask(ctx, task.ID.Hex(),
+		&domain.Task{Title: "Task", Status: StatusCompleted, BlockedBy: task.BlockedBy}, ownerID)
+	assert.ErrorIs(t, err, ErrOpenBlockers)
+	assert.ErrorContains(t, err, `"Blocker"`)
+
+	_, err = usecase.UpdateTask(ctx, blocker.ID.Hex(), &domain.Task{Title: "Blocker", Status: StatusCompleted}, ownerID)
+	assert.NoError(t, err)
+
+	completed, err := usecase.UpdateTask(ctx, task.ID.Hex(),
+		&domain.Task{Title: "Task", Status: StatusCompleted, BlockedBy: task.BlockedBy}, ownerID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusCompleted, completed.Status)
+}
+
+func TestGetSubtasksAndDependencies(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	parent := &domain.Task{ID: primitive.NewObjectID(), Title: "Parent", Status: StatusPending, UserID: ownerID}
+	usecase := newRelationsUsecase(t, parent)
+	ctx := context.Background()
+
+	child, err := usecase.CreateTask(ctx, &domain.Task{Title: "Child", Status: StatusPending, ParentID: parent.ID,
+		BlockedBy: []primitive.ObjectID{parent.ID, parent.ID}}, ownerID)
+	assert.NoError(t, err)
+	assert.Equal(t, []primitive.ObjectID{parent.ID}, child.BlockedBy, "duplicate blockers are dropped")
+
+	subtasks, err := usecase.GetSubtasks(ctx, parent.ID.Hex(), ownerID)
+	assert.NoError(t, err)
+	if assert.Len(t, subtasks, 1) {
+		assert.Equal(t, child.ID, subtasks[0].ID)
+	}
+
+	deps, err := usecase.GetDependencies(ctx, parent.ID.Hex(), ownerID)
+	assert.NoError(t, err)
+	assert.Empty(t, deps.BlockedBy)
+	if assert.Len(t, deps.Blocking, 1) {
+		assert.Equal(t, child.ID, deps.Blocking[0].ID)
+	}
+
+	_, err = usecase.GetSubtasks(ctx, parent.ID.Hex(), primitive.NewObjectID())
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
@@ -0,0 +1,268 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"time"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxSearchTerms caps the number of terms in one search query.
+	MaxSearchTerms = 20
+	// MaxSearchCandidates caps how many matching tasks are ranked. Matches
+	// beyond it, in due date order, are not considered.
+	MaxSearchCandidates = 1000
+)
+
+// searchTerm is one term of a search query: free text when field is empty,
+// otherwise a field:value filter. A leading "-" negates it.
+type searchTerm struct {
+	field   string
+	value   string
+	negated bool
+}
+
+// tokenizeSearch splits a search query into terms. Terms are separated by
+// spaces, and double quotes group words into one term or one value, as in
+// "release notes" or tag:"needs review".
+func tokenizeSearch(q string) ([]searchTerm, error) {
+	var terms []searchTerm
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		var term searchTerm
+		if runes[i] == '-' {
+			term.negated = true
+			i++
+		}
+		var b strings.Builder
+		quoted, sawQuote := false, false
+	scan:
+		for ; i < len(runes); i++ {
+			r := runes[i]
+			switch {
+			case r == '"':
+				quoted, sawQuote = !quoted, true
+			case quoted:
+				b.WriteRune(r)
+			case unicode.IsSpace(r):
+				break scan
+			case r == ':' && term.field == "" && !sawQuote && b.Len() > 0:
+				term.field = strings.ToLower(b.String())
+				b.Reset()
+			default:
+				b.WriteRune(r)
+			}
+		}
+		if quoted {
+			return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidTaskQuery)
+		}
+		term.value = b.String()
+		if term.field == "" && strings.TrimSpace(term.value) == "" {
+			continue
+		}
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+// parseSearch compiles a search query into a task query and the free-text
+// terms used for ranking. It understands
+//
+//	word or "a phrase"    must appear in the title or description
+//	status:done           status is done (or open, or a workflow state)
+//	tag:infra             task has the tag
+//	due:<2026-12-01       due before, on or after a day (<, <=, >, >= or =)
+//	project:<id>          task is in the project
+//
+// Every term except due and project can be negated with a leading "-".
+// Repeated status terms match any of the statuses; other terms must all
+// match. Dates are days in UTC.
+func (uc *taskUsecase) parseSearch(q string) (repositories.TaskQuery, []string, error) {
+	var query repositories.TaskQuery
+	terms, err := tokenizeSearch(q)
+	if err != nil {
+		return query, nil, err
+	}
+	if len(terms) == 0 {
+		return query, nil, fmt.Errorf("%w: search query is empty", ErrInvalidTaskQuery)
+	}
+	if len(terms) > MaxSearchTerms {
+		return query, nil, fmt.Errorf("%w: search query has more than %d terms", ErrInvalidTaskQuery, MaxSearchTerms)
+	}
+
+	var text []string
+	for _, term := range terms {
+		value := strings.TrimSpace(term.value)
+		if term.field != "" && value == "" {
+			return query, nil, fmt.Errorf("%w: %s needs a value", ErrInvalidTaskQuery, term.field)
+		}
+		switch term.field {
+		case "":
+			if term.negated {
+				query.ExcludeText = append(query.ExcludeText, value)
+			} else {
+				query.Text = append(query.Text, value)
+				text = append(text, value)
+			}
+		case "status":
+			statuses := uc.searchStatuses(value)
+			if term.negated {
+				query.ExcludeStatuses = append(query.ExcludeStatuses, statuses...)
+			} else {
+				query.Statuses = append(query.Statuses, statuses...)
+			}
+		case "tag":
+			if term.negated {
+				query.ExcludeTags = append(query.ExcludeTags, value)
+			} else {
+				query.AllTags = append(query.AllTags, value)
+			}
+		case "due":
+			if term.negated {
+				return query, nil, fmt.Errorf("%w: due cannot be negated", ErrInvalidTaskQuery)
+			}
+			if err := applyDueTerm(&query, value); err != nil {
+				return query, nil, err
+			}
+		case "project":
+			projectID, err := primitive.ObjectIDFromHex(value)
+			if err != nil || term.negated || (!query.ProjectID.IsZero() && query.ProjectID != projectID) {
+				return query, nil, fmt.Errorf("%w: project must be a single project ID", ErrInvalidTaskQuery)
+			}
+			query.ProjectID = projectID
+		default:
+			return query, nil, fmt.Errorf("%w: unknown search field %q", ErrInvalidTaskQuery, term.field)
+		}
+	}
+	return query, text, nil
+}
+
+// searchStatuses resolves a status term. Workflow states match however they
+// are spelled, "done" and "open" match the done and not-done states, and
+// anything else is matched verbatim so legacy statuses can be found.
+func (uc *taskUsecase) searchStatuses(value string) []string {
+	if canonical, err := uc.workflow.Canonical(value); err == nil {
+		return []string{canonical}
+	}
+	alias := normalizeStatus(value)
+	if alias != "done" && alias != "open" {
+		return []string{value}
+	}
+	var statuses []string
+	for _, state := range uc.workflow.States() {
+		if uc.workflow.IsDone(state) == (alias == "done") {
+			statuses = append(statuses, state)
+		}
+	}
+	return statuses
+}
+
+// applyDueTerm narrows the query's due date range by one due term, keeping
+// the tighter bound when several terms overlap.
+func applyDueTerm(query *repositories.TaskQuery, value string) error {
+	op := ""
+	for _, prefix := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, prefix) {
+			op, value = prefix, value[len(prefix):]
+			break
+		}
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return fmt.Errorf("%w: due date must look like 2026-12-01", ErrInvalidTaskQuery)
+	}
+	next := day.AddDate(0, 0, 1)
+
+	from, before := time.Time{}, time.Time{}
+	switch op {
+	case "<":
+		before = day
+	case "<=":
+		before = next
+	case ">":
+		from = next
+	case ">=":
+		from = day
+	default:
+		from, before = day, next
+	}
+	if !from.IsZero() && from.After(query.DueFrom) {
+		query.DueFrom = from
+	}
+	if !before.IsZero() && (query.DueBefore.IsZero() || before.Before(query.DueBefore)) {
+		query.DueBefore = before
+	}
+	return nil
+}
+
+func (uc *taskUsecase) SearchTasks(ctx context.Context, q string, limit int, userID primitive.ObjectID) ([]domain.Task, error) {
+	query, text, err := uc.parseSearch(q)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultTaskPageSize
+	}
+	if limit > MaxTaskPageSize {
+		limit = MaxTaskPageSize
+	}
+
+	// Gather the candidates a page at a time through ListTasks, which
+	// applies the same visibility rules as the task listing.
+	query.SortBy = repositories.SortByDueDate
+	query.Limit = MaxTaskPageSize
+	var candidates []domain.Task
+	for len(candidates) < MaxSearchCandidates {
+		page, next, err := uc.ListTasks(ctx, query, userID)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, page...)
+		if next == "" {
+			break
+		}
+		query.Cursor = next
+	}
+	if len(candidates) > MaxSearchCandidates {
+		candidates = candidates[:MaxSearchCandidates]
+	}
+
+	rankTasks(candidates, text)
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+// rankTasks orders tasks by how often the free-text terms appear in them,
+// counting a match in the title three times as much as one in the
+// description. Ties keep their order.
+func rankTasks(tasks []domain.Task, text []string) {
+	if len(text) == 0 {
+		return
+	}
+	scores := make(map[primitive.ObjectID]int, len(tasks))
+	for _, task := range tasks {
+		title, description := strings.ToLower(task.Title), strings.ToLower(task.Description)
+		score := 0
+		for _, term := range text {
+			term = strings.ToLower(term)
+			score += 3*strings.Count(title, term) + strings.Count(description, term)
+		}
+		scores[task.ID] = score
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return scores[tasks[i].ID] > scores[tasks[j].ID]
+	})
+}
@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseSearch(t *testing.T) {
+	uc := &taskUsecase{workflow: DefaultStatusWorkflow()}
+	day := func(d int) time.Time { return time.Date(2026, 12, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name  string
+		q     string
+		query repositories.TaskQuery
+		text  []string
+	}{
+		{
+			name:  "text and phrases",
+			q:     `deploy "release notes" -draft`,
+			query: repositories.TaskQuery{Text: []string{"deploy", "release notes"}, ExcludeText: []string{"draft"}},
+			text:  []string{"deploy", "release notes"},
+		},
+		{
+			name:  "statuses and aliases",
+			q:     `status:in_progress STATUS:Pending -status:completed`,
+			query: repositories.TaskQuery{Statuses: []string{StatusInProgress, StatusPending}, ExcludeStatuses: []string{StatusCompleted}},
+		},
+		{
+			name:  "done alias",
+			q:     `status:done`,
+			query: repositories.TaskQuery{Statuses: []string{StatusCompleted}},
+		},
+		{
+			name:  "open alias",
+			q:     `status:open`,
+			query: repositories.TaskQuery{Statuses: []string{StatusInProgress, StatusPending, StatusReopened}},
+		},
+		{
+			name:  "tags",
+			q:     `tag:infra tag:"needs review" -tag:docs`,
+			query: repositories.TaskQuery{AllTags: []string{"infra", "needs review"}, ExcludeTags: []string{"docs"}},
+		},
+		{
+			name:  "due range",
+			q:     `due:>=2026-12-01 due:<2026-12-10 due:<=2026-12-05`,
+			query: repositories.TaskQuery{DueFrom: day(1), DueBefore: day(6)},
+		},
+		{
+			name:  "due on a day",
+			q:     `due:2026-12-03`,
+			query: repositories.TaskQuery{DueFrom: day(3), DueBefore: day(4)},
+		},
+		{
+			name:  "quoted colon is text",
+			q:     `"note: urgent"`,
+			query: repositories.TaskQuery{Text: []string{"note: urgent"}},
+			text:  []string{"note: urgent"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, text, err := uc.parseSearch(tt.q)
+			require.NoError(t, err)
+			assert.Equal(t, tt.query, query)
+			assert.Equal(t, tt.text, text)
+		})
+	}
+
+	for _, q := range []string{"", `  ""  `, `"open quote`, "owner:me", "tag:", "-due:<2026-12-01", "due:tomorrow", "project:nope", "a b c d e f g h i j k l m n o p q r s t u"} {
+		_, _, err := uc.parseSearch(q)
+		assert.ErrorIs(t, err, ErrInvalidTaskQuery, q)
+	}
+}
+
+func TestSearchTasks_RanksTextMatches(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	due := time.Now().UTC().Truncate(time.Millisecond)
+
+	for i, task := range []*domain.Task{
+		{Title: "Call the bank", Description: "Ask about the login token", Status: StatusPending},
+		{Title: "Login page", Description: "Login form and login errors", Status: StatusPending},
+		{Title: "Fix login", Description: "Users are locked out", Status: StatusCompleted},
+		{Title: "Login audit", Description: "Check login history", Status: StatusInProgress},
+		{Title: "Unrelated", Status: StatusPending},
+	} {
+		task.Duedate = due.Add(time.Duration(i) * time.Hour)
+		_, err := usecase.CreateTask(ctx, task, ownerID)
+		require.NoError(t, err)
+	}
+	_, err := usecase.CreateTask(ctx, &domain.Task{Title: "Login for someone else", Status: StatusPending}, primitive.NewObjectID())
+	require.NoError(t, err)
+
+	_, err = usecase.SearchTasks(ctx, "owner:me", 0, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidTaskQuery)
+	open, err := usecase.SearchTasks(ctx, "login -status:done", 2, ownerID)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	var titles []string
+	for _, task := range open {
+		titles = append(titles, task.Title)
+	}
+	assert.Equal(t, []string{"Login page", "Login audit"}, titles)
+	all, err := usecase.SearchTasks(ctx, "LOGIN", 0, ownerID)
+	require.NoError(t, err)
+	require.Len(t, all, 4)
+	assert.Equal(t, "Call the bank", all[3].Title)
+}
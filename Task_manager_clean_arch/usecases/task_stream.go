@@ -0,0 +1,235 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultStreamHistory is how many recent events a task stream keeps to
+	// replay to clients that reconnect.
+	DefaultStreamHistory = 1000
+	// DefaultStreamBuffer is how many events can wait for a subscriber that
+	// reads slowly before it is dropped.
+	DefaultStreamBuffer = 64
+)
+
+// StreamEvent is a task event with the ID a task stream gave it. IDs are
+// only meaningful to the stream, and only until the server restarts.
+type StreamEvent struct {
+	ID string
+	TaskEvent
+	seq uint64
+}
+
+// ITaskStream is an in-process event bus that fans task events out to the
+// clients watching them live.
+type ITaskStream interface {
+	// PublishTaskEvents sends each event to the subscribers in its audience
+	// and to those who can read every task.
+	ITaskEventSink
+	// Subscribe follows the events of the tasks the user can see. A client
+	// that reconnects passes the ID of the last event it received, and the
+	// subscription starts with the events it missed.
+	Subscribe(ctx context.Context, userID primitive.ObjectID, lastEventID string) (*TaskSubscription, error)
+	// Close ends every subscription, and those made afterwards at once, so
+	// that open streams do not hold up a server that is shutting down.
+	Close()
+}
+
+// TaskSubscription is one client's view of a task stream. It must be closed.
+type TaskSubscription struct {
+	// Missed are the events after the last event ID given to Subscribe.
+	Missed []StreamEvent
+	// Reset reports that the events after the last event ID are no longer
+	// known, so the client should load its tasks again.
+	Reset bool
+	// Events delivers new events. It is closed when the subscriber falls
+	// too far behind or the stream is closed; the client should reconnect
+	// with the ID of the last event it received.
+	Events <-chan StreamEvent
+	sub    *subscriber
+	stream *taskStream
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *TaskSubscription) Close() {
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+	s.stream.drop(s.sub, false)
+}
+
+// Lagged reports whether Events was closed because the subscriber fell
+// too far behind.
+func (s *TaskSubscription) Lagged() bool {
+	s.stream.mu.Lock()
+	defer s.stream.mu.Unlock()
+	return s.sub.lagged
+}
+
+type subscriber struct {
+	userID primitive.ObjectID
+	// readAny is set for users whose roles grant task:read:any.
+	readAny bool
+	events  chan StreamEvent
+	lagged  bool
+}
+
+// sees reports whether the subscriber may receive event. Those who can read
+// any task keep seeing every task, so access they never lost is not revoked.
+func (sub *subscriber) sees(event *StreamEvent) bool {
+	if event.Type == EventTaskAccessRevoked {
+		return slices.Contains(event.Audience, sub.userID)
+	}
+	return sub.readAny || slices.Contains(event.Audience, sub.userID)
+}
+
+type taskStream struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	history     []StreamEvent
+	maxHistory  int
+	buffer      int
+	subscribers map[*subscriber]struct{}
+	closed      bool
+	permissions infrastructure.IPermissionChecker
+}
+
+// TaskStreamOption customizes a task stream at construction time.
+type TaskStreamOption func(*taskStream)
+
+// WithStreamHistory sets how many recent events are kept for replay.
+func WithStreamHistory(events int) TaskStreamOption {
+	return func(s *taskStream) { s.maxHistory = events }
+}
+
+// WithStreamBuffer sets how many events can wait for a slow subscriber.
+func WithStreamBuffer(events int) TaskStreamOption {
+	return func(s *taskStream) { s.buffer = events }
+}
+
+// WithStreamPermissions lets users whose roles grant task:read:any follow
+// the events of every task. Roles are checked when a subscription starts, so
+// a change applies from the client's next reconnect. Without it, users only
+// follow the tasks in their audience.
+func WithStreamPermissions(checker infrastructure.IPermissionChecker) TaskStreamOption {
+	return func(s *taskStream) { s.permissions = checker }
+}
+
+func NewTaskStream(opts ...TaskStreamOption) ITaskStream {
+	s := &taskStream{
+		// The epoch tells the IDs of this run from those of an earlier one,
+		// which cannot be replayed.
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		maxHistory:  DefaultStreamHistory,
+		buffer:      DefaultStreamBuffer,
+		subscribers: map[*subscriber]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *taskStream) PublishTaskEvents(_ context.Context, events []TaskEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range events {
+		s.seq++
+		streamEvent := StreamEvent{ID: s.epoch + "-" + strconv.FormatUint(s.seq, 10), TaskEvent: event, seq: s.seq}
+		s.history = append(s.history, streamEvent)
+		if len(s.history) > s.maxHistory {
+			s.history = slices.Delete(s.history, 0, len(s.history)-s.maxHistory)
+		}
+		for sub := range s.subscribers {
+			if !sub.sees(&streamEvent) {
+				continue
+			}
+			select {
+			case sub.events <- streamEvent:
+			default:
+				// Publishing never waits for a slow client. It is dropped
+				// instead, and catches up from the history when it reconnects.
+				s.drop(sub, true)
+			}
+		}
+	}
+}
+
+func (s *taskStream) Subscribe(ctx context.Context, userID primitive.ObjectID, lastEventID string) (*TaskSubscription, error) {
+	sub := &subscriber{userID: userID, events: make(chan StreamEvent, s.buffer)}
+	if s.permissions != nil {
+		permissions, err := s.permissions.UserPermissions(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		sub.readAny = slices.Contains(permissions, domain.PermTaskReadAny)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription := &TaskSubscription{Events: sub.events, sub: sub, stream: s}
+	if s.closed {
+		close(sub.events)
+		return subscription, nil
+	}
+	s.subscribers[sub] = struct{}{}
+	if lastEventID == "" {
+		return subscription, nil
+	}
+
+	after, ok := s.parseEventID(lastEventID)
+	oldest := s.seq + 1
+	if len(s.history) > 0 {
+		oldest = s.history[0].seq
+	}
+	// Events between the last one received and the oldest kept are lost.
+	if !ok || after > s.seq || after+1 < oldest {
+		subscription.Reset = true
+		return subscription, nil
+	}
+	for i := range s.history {
+		if s.history[i].seq > after && sub.sees(&s.history[i]) {
+			subscription.Missed = append(subscription.Missed, s.history[i])
+		}
+	}
+	return subscription, nil
+}
+
+func (s *taskStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sub := range s.subscribers {
+		s.drop(sub, false)
+	}
+}
+
+// drop ends a subscription that has not ended yet. The caller holds mu.
+func (s *taskStream) drop(sub *subscriber, lagged bool) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	delete(s.subscribers, sub)
+	sub.lagged = lagged
+	close(sub.events)
+}
+
+// parseEventID returns the sequence number of an event ID from this run.
+func (s *taskStream) parseEventID(id string) (uint64, bool) {
+	epoch, seq, found := strings.Cut(id, "-")
+	if !found || epoch != s.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
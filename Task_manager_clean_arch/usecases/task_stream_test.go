@@ -0,0 +1,186 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"taskmanager/domain"
+	"taskmanager/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func streamEvent(eventType string, audience ...primitive.ObjectID) TaskEvent {
+	return TaskEvent{ID: primitive.NewObjectID(), Type: eventType, Audience: audience}
+}
+
+// subscribe starts a subscription that must not fail.
+func subscribe(t *testing.T, stream ITaskStream, userID primitive.ObjectID, lastEventID string) *TaskSubscription {
+	subscription, err := stream.Subscribe(context.Background(), userID, lastEventID)
+	require.NoError(t, err)
+	return subscription
+}
+
+func TestTaskStream_ScopesEventsToTheirAudience(t *testing.T) {
+	stream := NewTaskStream()
+	ownerID, strangerID := primitive.NewObjectID(), primitive.NewObjectID()
+	owner := subscribe(t, stream, ownerID, "")
+	defer owner.Close()
+	stranger := subscribe(t, stream, strangerID, "")
+	defer stranger.Close()
+
+	stream.PublishTaskEvents(context.Background(), []TaskEvent{
+		streamEvent(EventTaskCreated, ownerID),
+		streamEvent(EventTaskUpdated, ownerID),
+	})
+
+	// --- ASSERT ---
+	require.Len(t, owner.Events, 2)
+	first, second := <-owner.Events, <-owner.Events
+	assert.Equal(t, EventTaskCreated, first.Type)
+	assert.Equal(t, EventTaskUpdated, second.Type)
+	assert.NotEqual(t, first.ID, second.ID)
+	assert.Empty(t, stranger.Events)
+}
+
+func TestTaskStream_ReadAnyHoldersFollowEveryTask(t *testing.T) {
+	f := newFixture(t)
+	stream := NewTaskStream(WithStreamPermissions(f.roles))
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	manager := subscribe(t, stream, f.manager, "")
+	defer manager.Close()
+	user := subscribe(t, stream, f.user, "")
+	defer user.Close()
+	stream.PublishTaskEvents(ctx, []TaskEvent{streamEvent(EventTaskCreated, ownerID)})
+	received := <-manager.Events
+	stream.PublishTaskEvents(ctx, []TaskEvent{streamEvent(EventTaskUpdated, ownerID)})
+	resumed := subscribe(t, stream, f.manager, received.ID)
+	defer resumed.Close()
+
+	// --- ASSERT ---
+	assert.Equal(t, EventTaskCreated, received.Type)
+	require.Len(t, manager.Events, 1, "task:read:any holders are not in the audience but see every task")
+	require.Len(t, resumed.Missed, 1)
+	assert.Equal(t, EventTaskUpdated, resumed.Missed[0].Type)
+	assert.Empty(t, user.Events)
+}
+
+// TestTaskStream_UnassignedUserIsToldAccessWasRevoked unassigns a task and
+// expects the former assignee to be told to drop it, without its changes.
+func TestTaskStream_UnassignedUserIsToldAccessWasRevoked(t *testing.T) {
+	f := newFixture(t)
+	stream := NewTaskStream(WithStreamPermissions(f.roles))
+	f.tasks = f.newTaskUsecase(WithTaskEventSink(stream))
+	ctx := context.Background()
+	carolID := f.addUser(t, "carol")
+	task, err := f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending, AssigneeID: carolID}, f.user)
+	require.NoError(t, err)
+	carol := subscribe(t, stream, carolID, "")
+	defer carol.Close()
+	owner := subscribe(t, stream, f.user, "")
+	defer owner.Close()
+	manager := subscribe(t, stream, f.manager, "")
+	defer manager.Close()
+
+	_, err = f.tasks.UnassignTask(ctx, task.ID.Hex(), f.user)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	require.Len(t, carol.Events, 1)
+	revoked := <-carol.Events
+	assert.Equal(t, EventTaskAccessRevoked, revoked.Type)
+	assert.Equal(t, domain.Task{ID: task.ID}, revoked.Task, "nothing of the change reaches the former assignee")
+	assert.Equal(t, []primitive.ObjectID{carolID}, revoked.Audience)
+	require.Len(t, owner.Events, 1)
+	assert.Equal(t, EventTaskUpdated, (<-owner.Events).Type)
+	require.Len(t, manager.Events, 1, "task:read:any holders keep the task")
+	assert.Equal(t, EventTaskUpdated, (<-manager.Events).Type)
+}
+
+func TestTaskStream_SubscribeFailsWhenRolesCannotBeLoaded(t *testing.T) {
+	checker := new(mocks.IPermissionChecker)
+	checker.On("UserPermissions", mock.Anything, mock.Anything).Return(nil, errors.New("database is down"))
+	stream := NewTaskStream(WithStreamPermissions(checker))
+
+	subscription, err := stream.Subscribe(context.Background(), primitive.NewObjectID(), "")
+
+	// --- ASSERT ---
+	assert.EqualError(t, err, "database is down")
+	assert.Nil(t, subscription)
+}
+
+func TestTaskStream_ResumesAfterLastEventID(t *testing.T) {
+	stream := NewTaskStream(WithStreamHistory(3))
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+	first := subscribe(t, stream, userID, "")
+	stream.PublishTaskEvents(ctx, []TaskEvent{streamEvent(EventTaskCreated, userID)})
+	received := <-first.Events
+	first.Close()
+	stream.PublishTaskEvents(ctx, []TaskEvent{
+		streamEvent(EventTaskUpdated, userID),
+		streamEvent(EventTaskUpdated, primitive.NewObjectID()),
+		streamEvent(EventTaskDeleted, userID),
+	})
+
+	resumed := subscribe(t, stream, userID, received.ID)
+	defer resumed.Close()
+	stream.PublishTaskEvents(ctx, []TaskEvent{streamEvent(EventTaskCreated, userID)})
+	tooOld := subscribe(t, stream, userID, received.ID)
+	defer tooOld.Close()
+	unknown := subscribe(t, stream, userID, "earlier-run-7")
+	defer unknown.Close()
+
+	// --- ASSERT ---
+	assert.False(t, resumed.Reset)
+	require.Len(t, resumed.Missed, 2)
+	assert.Equal(t, EventTaskUpdated, resumed.Missed[0].Type)
+	assert.Equal(t, EventTaskDeleted, resumed.Missed[1].Type)
+	assert.True(t, tooOld.Reset, "the event after the last one received has left the history")
+	assert.Empty(t, tooOld.Missed)
+	assert.True(t, unknown.Reset)
+}
+
+func TestTaskStream_DropsSlowSubscribers(t *testing.T) {
+	stream := NewTaskStream(WithStreamBuffer(1))
+	userID := primitive.NewObjectID()
+	slow := subscribe(t, stream, userID, "")
+	defer slow.Close()
+
+	stream.PublishTaskEvents(context.Background(), []TaskEvent{
+		streamEvent(EventTaskCreated, userID),
+		streamEvent(EventTaskUpdated, userID),
+	})
+
+	// --- ASSERT ---
+	buffered, ok := <-slow.Events
+	require.True(t, ok)
+	_, ok = <-slow.Events
+	assert.False(t, ok, "the subscription is closed once its buffer overflows")
+	assert.True(t, slow.Lagged())
+	resumed := subscribe(t, stream, userID, buffered.ID)
+	defer resumed.Close()
+	require.Len(t, resumed.Missed, 1)
+	assert.Equal(t, EventTaskUpdated, resumed.Missed[0].Type)
+}
+
+func TestTaskStream_CloseEndsSubscriptions(t *testing.T) {
+	stream := NewTaskStream()
+	userID := primitive.NewObjectID()
+	open := subscribe(t, stream, userID, "")
+
+	stream.Close()
+	late := subscribe(t, stream, userID, "")
+	open.Close()
+
+	// --- ASSERT ---
+	_, ok := <-open.Events
+	assert.False(t, ok)
+	assert.False(t, open.Lagged())
+	_, ok = <-late.Events
+	assert.False(t, ok)
+}
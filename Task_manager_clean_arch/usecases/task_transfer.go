@@ -0,0 +1,179 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MaxImportRows caps the number of rows in one import.
+const MaxImportRows = 10000
+
+const (
+	ImportCreated = "created"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+)
+
+// ErrInvalidImport is returned when an import file cannot be read to the end.
+// Rows before the problem have been imported.
+var ErrInvalidImport = errors.New("invalid import")
+
+// ImportRow is one task read from an import file. Err is set instead of Task
+// when the row itself is malformed.
+type ImportRow struct {
+	// Row numbers the row in error reports: its line in a CSV file, or its
+	// position among the tasks of a JSON or iCalendar file.
+	Row        int
+	ExternalID string
+	Task       *domain.Task
+	Err        error
+}
+
+// ImportRowReader yields the rows of an import one at a time. Read returns
+// io.EOF after the last row. Any other error means the rest of the file
+// cannot be read.
+type ImportRowReader interface {
+	Read() (*ImportRow, error)
+}
+
+// ImportResult is the outcome of one row: the task created for it, or why it
+// was skipped or failed.
+type ImportResult struct {
+	Row        int
+	ExternalID string
+	Status     string
+	TaskID     primitive.ObjectID
+	Err        error
+}
+
+// ImportReport sums up an import. In a dry run, created rows are the ones
+// that would be created.
+type ImportReport struct {
+	DryRun  bool
+	Created int
+	Skipped int
+	Failed  int
+	Results []ImportResult
+}
+
+func (r *ImportReport) add(result ImportResult) {
+	switch result.Status {
+	case ImportCreated:
+		r.Created++
+	case ImportSkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// ImportTasks imports the rows one by one, so a failed row does not stop the
+// rows after it. A row is skipped when its external ID matches a task the
+// user imported before, an earlier row of the same file, or the ID of one of
+// the user's own tasks, as in a re-imported export.
+func (uc *taskUsecase) ImportTasks(ctx context.Context, rows ImportRowReader, dryRun bool, userID primitive.ObjectID) (*ImportReport, error) {
+	report := &ImportReport{DryRun: dryRun}
+	seen := map[string]int{}
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			return report, nil
+		}
+		if err != nil {
+			return report, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if len(report.Results) == MaxImportRows {
+			return report, fmt.Errorf("%w: more than %d rows", ErrInvalidImport, MaxImportRows)
+		}
+
+		externalID := strings.TrimSpace(row.ExternalID)
+		result := ImportResult{Row: row.Row, ExternalID: externalID, Status: ImportFailed, Err: row.Err}
+		if result.Err == nil {
+			if first, ok := seen[externalID]; ok {
+				result.Status, result.Err = ImportSkipped, fmt.Errorf("external ID already used in row %d", first)
+			} else {
+				result.Status, result.TaskID, result.Err = uc.importRow(ctx, row.Task, externalID, dryRun, userID)
+			}
+		}
+		if result.Status == ImportCreated && externalID != "" {
+			seen[externalID] = row.Row
+		}
+		report.add(result)
+	}
+}
+
+// importRow creates the task of one row, or only checks it in a dry run.
+func (uc *taskUsecase) importRow(ctx context.Context, task *domain.Task, externalID string, dryRun bool, userID primitive.ObjectID) (string, primitive.ObjectID, error) {
+	if externalID != "" {
+		existing, err := uc.importedTask(ctx, externalID, userID)
+		if err != nil {
+			return ImportFailed, primitive.NilObjectID, err
+		}
+		if existing != nil {
+			return ImportSkipped, existing.ID, errors.New("already imported")
+		}
+	}
+
+	task.ExternalID = externalID
+	if dryRun {
+		if err := uc.prepareNewTask(ctx, task, userID); err != nil {
+			return ImportFailed, primitive.NilObjectID, err
+		}
+		return ImportCreated, primitive.NilObjectID, nil
+	}
+	created, err := uc.CreateTask(ctx, task, userID)
+	if mongo.IsDuplicateKeyError(err) {
+		// Another import stored the same external ID in the meantime.
+		return ImportSkipped, primitive.NilObjectID, errors.New("already imported")
+	}
+	if err != nil {
+		return ImportFailed, primitive.NilObjectID, err
+	}
+	return ImportCreated, created.ID, nil
+}
+
+// importedTask finds the user's task that externalID refers to, if any.
+func (uc *taskUsecase) importedTask(ctx context.Context, externalID string, userID primitive.ObjectID) (*domain.Task, error) {
+	task, err := uc.taskRepo.GetByExternalID(ctx, userID, externalID)
+	if err == nil || !errors.Is(err, mongo.ErrNoDocuments) {
+		return task, err
+	}
+	id, err := primitive.ObjectIDFromHex(externalID)
+	if err != nil {
+		return nil, nil
+	}
+	task, err = uc.taskRepo.GetByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		task, err = uc.taskRepo.GetTrashedByID(ctx, id)
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) || err == nil && task.UserID != userID {
+		return nil, nil
+	}
+	return task, err
+}
+
+func (uc *taskUsecase) ExportTasks(ctx context.Context, query repositories.TaskQuery, userID primitive.ObjectID, write func([]domain.Task) error) error {
+	query.Limit, query.Cursor = MaxTaskPageSize, ""
+	for {
+		tasks, next, err := uc.ListTasks(ctx, query, userID)
+		if err != nil {
+			return err
+		}
+		if err := write(tasks); err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		query.Cursor = next
+	}
+}
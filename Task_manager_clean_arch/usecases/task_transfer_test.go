@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"io"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// sliceRows reads import rows from a slice, then fails with err if it is set.
+type sliceRows struct {
+	rows []ImportRow
+	err  error
+}
+
+func (r *sliceRows) Read() (*ImportRow, error) {
+	if len(r.rows) == 0 {
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return &row, nil
+}
+
+func importRows() *sliceRows {
+	return &sliceRows{rows: []ImportRow{
+		{Row: 2, ExternalID: "T-1", Task: &domain.Task{Title: "First", Status: "pending"}},
+		{Row: 3, ExternalID: "T-2", Task: &domain.Task{Title: "Bad", Status: "Someday"}},
+		{Row: 4, Err: errors.New("due_date must be RFC 3339")},
+		{Row: 5, ExternalID: "T-1", Task: &domain.Task{Title: "First again", Status: StatusPending}},
+		{Row: 6, ExternalID: "T-2", Task: &domain.Task{Title: "Fixed", Status: StatusCompleted}},
+		{Row: 7, Task: &domain.Task{Title: "No ID", Status: StatusPending}},
+	}}
+}
+
+func TestImportTasks_DryRunWritesNothing(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+
+	report, err := usecase.ImportTasks(ctx, importRows(), true, ownerID)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.True(t, report.DryRun)
+	assert.Equal(t, [3]int{3, 1, 2}, [3]int{report.Created, report.Skipped, report.Failed})
+	var statuses []string
+	for _, result := range report.Results {
+		statuses = append(statuses, result.Status)
+	}
+	assert.Equal(t, []string{ImportCreated, ImportFailed, ImportFailed, ImportSkipped, ImportCreated, ImportCreated}, statuses)
+	assert.ErrorIs(t, report.Results[1].Err, ErrUnknownStatus)
+	tasks, err := usecase.GetUserTasks(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestImportTasks_SkipsWhatWasImportedBefore(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	local, err := usecase.CreateTask(ctx, &domain.Task{Title: "Local", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+
+	first, err := usecase.ImportTasks(ctx, importRows(), false, ownerID)
+	require.NoError(t, err)
+	require.Equal(t, 3, first.Created)
+	imported, err := repos.Tasks.GetByID(ctx, first.Results[0].TaskID)
+	require.NoError(t, err)
+	assert.Equal(t, "T-1", imported.ExternalID)
+
+	// An exported task carries its own ID as its external ID.
+	again := importRows()
+	again.rows = append(again.rows, ImportRow{Row: 8, ExternalID: local.ID.Hex(), Task: &domain.Task{Title: "Local", Status: StatusPending}})
+	again.err = errors.New("unexpected end of file")
+	second, err := usecase.ImportTasks(ctx, again, false, ownerID)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrInvalidImport)
+	assert.Equal(t, [3]int{1, 5, 1}, [3]int{second.Created, second.Skipped, second.Failed})
+	assert.Equal(t, first.Results[0].TaskID, second.Results[0].TaskID)
+	assert.Equal(t, local.ID, second.Results[6].TaskID)
+	tasks, err := usecase.GetUserTasks(ctx, ownerID)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 5)
+}
+
+func TestExportTasks_PagesThroughEveryTask(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	for i := 0; i < MaxTaskPageSize+5; i++ {
+		_, err := usecase.CreateTask(ctx, &domain.Task{Title: "Task", Status: StatusPending}, ownerID)
+		require.NoError(t, err)
+	}
+
+	var pages []int
+	err := usecase.ExportTasks(ctx, repositories.TaskQuery{Limit: 1}, ownerID, func(tasks []domain.Task) error {
+		pages = append(pages, len(tasks))
+		return nil
+	})
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Equal(t, []int{MaxTaskPageSize, 5}, pages)
+}
@@ -0,0 +1,199 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"taskmanager/domain"
+	"taskmanager/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// DefaultTrashRetention is how long a deleted task stays in the trash.
+	DefaultTrashRetention = 30 * 24 * time.Hour
+	DefaultPurgeInterval  = time.Hour
+)
+
+// ListTrash returns the user's own deleted tasks, most recently deleted first.
+func (uc *taskUsecase) ListTrash(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error) {
+	return uc.taskRepo.ListTrash(ctx, repositories.TrashQuery{UserID: userID})
+}
+
+// RestoreTask takes a task out of the trash. Only those who could delete it
+// may restore it.
+func (uc *taskUsecase) RestoreTask(ctx context.Context, taskID string, userID primitive.ObjectID) (*domain.Task, error) {
+	objectID, err := primitive.ObjectIDFromHex(taskID)
+	if err != nil {
+		return nil, ErrInvalidTaskID
+	}
+	task, err := uc.taskRepo.GetTrashedByID(ctx, objectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	permission, err := uc.permission(ctx, task, userID)
+	if err != nil {
+		return nil, err
+	}
+	if permission < permissionView {
+		return nil, ErrTaskNotFound
+	}
+	if permission < permissionManage {
+		return nil, ErrForbidden
+	}
+
+	task.DeletedAt, task.DeletedBy = time.Time{}, primitive.NilObjectID
+	version := task.Version
+	err = uc.run(ctx, func(ctx context.Context) error {
+		task.Version = version
+		if err := uc.taskRepo.UpdateFields(ctx, task, []repositories.TaskField{repositories.TaskFieldDeletion}); err != nil {
+			return updateError(err)
+		}
+		if err := uc.auditRepo.Append(ctx, newTaskAuditEntry(domain.AuditActionRestore, nil, task, userID)); err != nil {
+			return err
+		}
+		// Task events have no type of their own for a restore.
+		return uc.emit(ctx, EventTaskUpdated, nil, task, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+type ITrashUsecase interface {
+	// Run purges expired tasks immediately and then on every tick until ctx
+	// is cancelled. It returns once the purge in progress has finished.
+	Run(ctx context.Context)
+	// PurgeExpired permanently removes every task that has been in the trash
+	// for longer than the retention period at now, and returns how many.
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
+	// EmptyTrash permanently removes every task in the owner's trash on an
+	// admin's behalf, and returns how many.
+	EmptyTrash(ctx context.Context, ownerID string, adminID primitive.ObjectID) (int, error)
+}
+
+type trashUsecase struct {
+	taskRepo   repositories.ITaskRepository
+	userRepo   repositories.IUserRepository
+	auditRepo  repositories.IAuditRepository
+	unitOfWork repositories.IUnitOfWork
+	retention  time.Duration
+	interval   time.Duration
+}
+
+// TrashOption customizes a trash usecase at construction time.
+type TrashOption func(*trashUsecase)
+
+// WithTrashRetention sets how long deleted tasks are kept before the purger
+// removes them.
+func WithTrashRetention(retention time.Duration) TrashOption {
+	return func(uc *trashUsecase) { uc.retention = retention }
+}
+
+// WithTrashUnitOfWork purges each task and records its audit entry in one
+// unit of work. The unit of work must belong to the same backend as the
+// repositories.
+func WithTrashUnitOfWork(unitOfWork repositories.IUnitOfWork) TrashOption {
+	return func(uc *trashUsecase) { uc.unitOfWork = unitOfWork }
+}
+
+// WithPurgeInterval sets how often Run looks for expired tasks.
+func WithPurgeInterval(interval time.Duration) TrashOption {
+	return func(uc *trashUsecase) { uc.interval = interval }
+}
+
+func NewTrashUsecase(taskRepo repositories.ITaskRepository, userRepo repositories.IUserRepository, auditRepo repositories.IAuditRepository, opts ...TrashOption) ITrashUsecase {
+	uc := &trashUsecase{
+		taskRepo:  taskRepo,
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		retention: DefaultTrashRetention,
+		interval:  DefaultPurgeInterval,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+func (uc *trashUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.interval)
+	defer ticker.Stop()
+	for {
+		if purged, err := uc.PurgeExpired(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Trash purge failed after removing %d tasks: %v", purged, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired records the purges as made by no one, since no user asked
+// for them.
+func (uc *trashUsecase) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	tasks, err := uc.taskRepo.ListTrash(ctx, repositories.TrashQuery{DeletedBefore: now.Add(-uc.retention)})
+	if err != nil {
+		return 0, err
+	}
+	return uc.purge(ctx, tasks, primitive.NilObjectID)
+}
+
+func (uc *trashUsecase) EmptyTrash(ctx context.Context, ownerID string, adminID primitive.ObjectID) (int, error) {
+	objectID, err := primitive.ObjectIDFromHex(ownerID)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrUserNotFound, ownerID)
+	}
+	_, err = uc.userRepo.FindByID(ctx, objectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, fmt.Errorf("%w: %s", ErrUserNotFound, ownerID)
+	}
+	if err != nil {
+		return 0, err
+	}
+	tasks, err := uc.taskRepo.ListTrash(ctx, repositories.TrashQuery{UserID: objectID})
+	if err != nil {
+		return 0, err
+	}
+	return uc.purge(ctx, tasks, adminID)
+}
+
+// purge deletes the tasks for good, auditing each one, and stops at the
+// first failure.
+func (uc *trashUsecase) purge(ctx context.Context, tasks []domain.Task, actorID primitive.ObjectID) (int, error) {
+	for i := range tasks {
+		err := uc.run(ctx, func(ctx context.Context) error {
+			if err := uc.taskRepo.Delete(ctx, tasks[i].ID); err != nil {
+				return err
+			}
+			return uc.auditRepo.Append(ctx, newTaskAuditEntry(domain.AuditActionPurge, &tasks[i], nil, actorID))
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(tasks), nil
+}
+
+// run calls fn in the unit of work. Without one, or without transactions,
+// the steps run one after another.
+func (uc *trashUsecase) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := repositories.ErrTransactionsUnsupported
+	if uc.unitOfWork != nil {
+		err = uc.unitOfWork.Run(ctx, fn)
+	}
+	if errors.Is(err, repositories.ErrTransactionsUnsupported) {
+		err = fn(ctx)
+	}
+	return err
+}
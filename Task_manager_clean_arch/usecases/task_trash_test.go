@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"taskmanager/domain"
+	"taskmanager/mocks"
+	"taskmanager/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeleteTask_MovesTaskToTrash(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	viewerID := primitive.NewObjectID()
+
+	task, err := usecase.CreateTask(ctx, &domain.Task{Title: "Draft", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	id := task.ID.Hex()
+	require.NoError(t, usecase.DeleteTask(ctx, id, 0, ownerID))
+
+	_, err = usecase.GetTaskByID(ctx, id, ownerID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	trash, err := usecase.ListTrash(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, ownerID, trash[0].DeletedBy)
+	empty, err := usecase.ListTrash(ctx, viewerID)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = usecase.RestoreTask(ctx, id, viewerID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	restored, err := usecase.RestoreTask(ctx, id, ownerID)
+	require.NoError(t, err)
+	assert.True(t, restored.DeletedAt.IsZero())
+
+	// --- ASSERT ---
+	found, err := usecase.GetTaskByID(ctx, id, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, "Draft", found.Title)
+	_, err = usecase.RestoreTask(ctx, id, ownerID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	history, _, err := usecase.GetTaskHistory(ctx, id, repositories.AuditQuery{}, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.AuditActionRestore, history[0].Action)
+}
+
+func TestTrash_PurgesAfterRetention(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	ownerID := primitive.NewObjectID()
+
+	trashed := func(title string, deletedAt time.Time) *domain.Task {
+		task := &domain.Task{Title: title, Status: StatusPending, UserID: ownerID, DeletedAt: deletedAt, DeletedBy: ownerID}
+		require.NoError(t, repos.Tasks.Create(ctx, task))
+		return task
+	}
+	expired := trashed("Expired", now.Add(-8*24*time.Hour))
+	recent := trashed("Recent", now.Add(-6*24*time.Hour))
+
+	trash := NewTrashUsecase(repos.Tasks, repos.Users, repos.Audit, WithTrashRetention(7*24*time.Hour))
+	purged, err := trash.PurgeExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	// --- ASSERT ---
+	_, err = repos.Tasks.GetTrashedByID(ctx, expired.ID)
+	assert.Error(t, err)
+	_, err = repos.Tasks.GetTrashedByID(ctx, recent.ID)
+	assert.NoError(t, err)
+	entries, _, err := repos.Audit.List(ctx, repositories.AuditQuery{EntityID: expired.ID})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, domain.AuditActionPurge, entries[0].Action)
+	assert.True(t, entries[0].ActorID.IsZero())
+}
+
+func TestEmptyTrash_RemovesOnlyThatUsersTrash(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	adminID := primitive.NewObjectID()
+	owner := &domain.User{Username: "owner", Password: "pw", Roles: []string{"user"}}
+	require.NoError(t, repos.Users.Create(ctx, owner))
+
+	for _, userID := range []primitive.ObjectID{owner.ID, owner.ID, adminID} {
+		task := &domain.Task{Title: "Gone", Status: StatusPending, UserID: userID, DeletedAt: now, DeletedBy: userID}
+		require.NoError(t, repos.Tasks.Create(ctx, task))
+	}
+	live := &domain.Task{Title: "Live", Status: StatusPending, UserID: owner.ID}
+	require.NoError(t, repos.Tasks.Create(ctx, live))
+
+	trash := NewTrashUsecase(repos.Tasks, repos.Users, repos.Audit)
+	_, err := trash.EmptyTrash(ctx, primitive.NewObjectID().Hex(), adminID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	_, err = trash.EmptyTrash(ctx, "nope", adminID)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	purged, err := trash.EmptyTrash(ctx, owner.ID.Hex(), adminID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	// --- ASSERT ---
+	remaining, err := repos.Tasks.ListTrash(ctx, repositories.TrashQuery{})
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, adminID, remaining[0].UserID)
+	_, err = repos.Tasks.GetByID(ctx, live.ID)
+	assert.NoError(t, err)
+}
+
+func TestTrash_DatabaseErrorsAreNotNotFound(t *testing.T) {
+	ctx := context.Background()
+	outage := errors.New("database unavailable")
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockTaskRepo.On("GetTrashedByID", mock.Anything, mock.Anything).Return(nil, outage)
+	mockUserRepo.On("FindByID", mock.Anything, mock.Anything).Return(nil, outage)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, new(mocks.IAuditRepository), new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	_, err := usecase.RestoreTask(ctx, primitive.NewObjectID().Hex(), primitive.NewObjectID())
+	assert.ErrorIs(t, err, outage)
+	assert.NotErrorIs(t, err, ErrTaskNotFound)
+
+	trash := NewTrashUsecase(mockTaskRepo, mockUserRepo, new(mocks.IAuditRepository))
+	_, err = trash.EmptyTrash(ctx, primitive.NewObjectID().Hex(), primitive.NewObjectID())
+	assert.ErrorIs(t, err, outage)
+	assert.NotErrorIs(t, err, ErrUserNotFound)
+}
@@ -3,79 +3,439 @@ package usecases
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"taskmanager/domain"
+	"taskmanager/infrastructure"
 	"taskmanager/repositories"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DefaultTaskPageSize = 20
+	MaxTaskPageSize     = 100
+)
+
+var (
+	// ErrInvalidTaskQuery is returned when a task listing query is malformed.
+	ErrInvalidTaskQuery = errors.New("invalid task query")
+	ErrInvalidTaskID    = errors.New("invalid task ID format")
+	ErrTaskNotFound     = errors.New("task not found")
+	// ErrVersionMismatch is returned when a task has changed since the
+	// version a client based its update or delete on.
+	ErrVersionMismatch = errors.New("task has been modified since it was read")
 )
 
 type ITaskUsecase interface {
 	CreateTask(ctx context.Context, task *domain.Task, userID primitive.ObjectID) (*domain.Task, error)
 	GetUserTasks(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error)
+	ListTasks(ctx context.Context, query repositories.TaskQuery, userID primitive.ObjectID) ([]domain.Task, string, error)
+	// SearchTasks finds the tasks matching a search query, best matches
+	// first. See parseSearch for the query language.
+	SearchTasks(ctx context.Context, q string, limit int, userID primitive.ObjectID) ([]domain.Task, error)
 	GetTaskByID(ctx context.Context, taskID string, userID primitive.ObjectID) (*domain.Task, error)
+	// UpdateTask replaces the task's editable fields. A non-zero
+	// updatedTask.Version must match the stored version.
 	UpdateTask(ctx context.Context, taskID string, updatedTask *domain.Task, userID primitive.ObjectID) (*domain.Task, error)
-	DeleteTask(ctx context.Context, taskID string, userID primitive.ObjectID) error
+	// PatchTask changes only the fields the patch sets.
+	PatchTask(ctx context.Context, taskID string, patch *TaskPatch, userID primitive.ObjectID) (*domain.Task, error)
+	// DeleteTask moves the task to the trash. A non-zero version must match
+	// the stored one.
+	DeleteTask(ctx context.Context, taskID string, version int64, userID primitive.ObjectID) error
+	ListTrash(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error)
+	RestoreTask(ctx context.Context, taskID string, userID primitive.ObjectID) (*domain.Task, error)
+	GetSubtasks(ctx context.Context, taskID string, userID primitive.ObjectID) ([]domain.Task, error)
+	GetDependencies(ctx context.Context, taskID string, userID primitive.ObjectID) (*TaskDependencies, error)
+	GetTaskHistory(ctx context.Context, taskID string, query repositories.AuditQuery, userID primitive.ObjectID) ([]domain.AuditEntry, string, error)
+	AssignTask(ctx context.Context, taskID string, assigneeID primitive.ObjectID, userID primitive.ObjectID) (*domain.Task, error)
+	UnassignTask(ctx context.Context, taskID string, userID primitive.ObjectID) (*domain.Task, error)
+	ShareTask(ctx context.Context, taskID string, collaboratorID primitive.ObjectID, role string, userID primitive.ObjectID) (*domain.Task, error)
+	UnshareTask(ctx context.Context, taskID string, collaboratorID primitive.ObjectID, userID primitive.ObjectID) (*domain.Task, error)
+	GetOccurrences(ctx context.Context, taskID string, n int, userID primitive.ObjectID) ([]time.Time, error)
+	// RunBatch applies the operations in order, all or nothing.
+	RunBatch(ctx context.Context, ops []BatchOperation, userID primitive.ObjectID) ([]BatchResult, error)
+	// ImportTasks creates a task for every row, skipping rows whose external
+	// ID was imported before. A dry run only checks the rows.
+	ImportTasks(ctx context.Context, rows ImportRowReader, dryRun bool, userID primitive.ObjectID) (*ImportReport, error)
+	// ExportTasks passes every task matching the query to write, a page at a
+	// time. The query's Limit and Cursor are ignored.
+	ExportTasks(ctx context.Context, query repositories.TaskQuery, userID primitive.ObjectID, write func([]domain.Task) error) error
 }
 
 type taskUsecase struct {
-	taskRepo repositories.ITaskRepository
+	taskRepo    repositories.ITaskRepository
+	userRepo    repositories.IUserRepository
+	auditRepo   repositories.IAuditRepository
+	tagRepo     repositories.ITagRepository
+	projectRepo repositories.IProjectRepository
+	workflow    *StatusWorkflow
+	unitOfWork  repositories.IUnitOfWork
+	sinks       []ITaskEventSink
+	permissions infrastructure.IPermissionChecker
+}
+
+// TaskUsecaseOption customizes a task usecase at construction time.
+type TaskUsecaseOption func(*taskUsecase)
+
+// WithStatusWorkflow replaces the default status workflow.
+func WithStatusWorkflow(workflow *StatusWorkflow) TaskUsecaseOption {
+	return func(uc *taskUsecase) { uc.workflow = workflow }
 }
 
-func NewTaskUsecase(repo repositories.ITaskRepository) ITaskUsecase {
-	return &taskUsecase{taskRepo: repo}
+// WithUnitOfWork keeps every task change and its audit entry together, and
+// lets RunBatch apply its operations atomically. The unit of work must
+// belong to the same backend as the repositories.
+func WithUnitOfWork(unitOfWork repositories.IUnitOfWork) TaskUsecaseOption {
+	return func(uc *taskUsecase) { uc.unitOfWork = unitOfWork }
+}
+
+// WithPermissionChecker lets roles grant access to every task: task:read:any
+// to view, task:update:any to edit and task:manage:any to manage them.
+func WithPermissionChecker(checker infrastructure.IPermissionChecker) TaskUsecaseOption {
+	return func(uc *taskUsecase) { uc.permissions = checker }
+}
+
+func NewTaskUsecase(repo repositories.ITaskRepository, userRepo repositories.IUserRepository, auditRepo repositories.IAuditRepository, tagRepo repositories.ITagRepository, projectRepo repositories.IProjectRepository, opts ...TaskUsecaseOption) ITaskUsecase {
+	uc := &taskUsecase{taskRepo: repo, userRepo: userRepo, auditRepo: auditRepo, tagRepo: tagRepo, projectRepo: projectRepo, workflow: DefaultStatusWorkflow()}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
 }
 
 func (uc *taskUsecase) CreateTask(ctx context.Context, task *domain.Task, userID primitive.ObjectID) (*domain.Task, error) {
+	if err := uc.prepareNewTask(ctx, task, userID); err != nil {
+		return nil, err
+	}
+	version := task.Version
+	err := uc.run(ctx, func(ctx context.Context) error {
+		task.Version = version
+		if err := uc.taskRepo.Create(ctx, task); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Append(ctx, newTaskAuditEntry(domain.AuditActionCreate, nil, task, userID)); err != nil {
+			return err
+		}
+		return uc.emit(ctx, EventTaskCreated, nil, task, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// run calls fn in the unit of work and publishes the task events fn emits
+// once it commits. Without a unit of work or transactions, such as on a
+// standalone MongoDB server, fn runs on its own, so its steps go in the
+// order that leaves the least behind if one fails. A unit inside another,
+// such as an operation of a batch, is part of the outer one. fn may be
+// called more than once.
+func (uc *taskUsecase) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, nested := ctx.Value(pendingEventsKey{}).(*[]TaskEvent); nested {
+		return fn(ctx)
+	}
+	var events []TaskEvent
+	attempt := func(ctx context.Context) error {
+		events = nil
+		return fn(withPendingEvents(ctx, &events))
+	}
+	err := repositories.ErrTransactionsUnsupported
+	if uc.unitOfWork != nil {
+		err = uc.unitOfWork.Run(ctx, attempt)
+	}
+	if errors.Is(err, repositories.ErrTransactionsUnsupported) {
+		err = attempt(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	uc.publish(ctx, events)
+	return nil
+}
+
+// prepareNewTask checks a new task and fills in the fields CreateTask sets,
+// without storing anything.
+func (uc *taskUsecase) prepareNewTask(ctx context.Context, task *domain.Task, userID primitive.ObjectID) error {
+	status, err := uc.workflow.Canonical(task.Status)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.checkProjectForNewTask(ctx, task.ProjectID, userID); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
 	task.UserID = userID
-	err := uc.taskRepo.Create(ctx, task)
-	return task, err
+	task.CreatedAt = now
+	task.ArchivedAt = time.Time{}
+	task.Status = status
+	task.StatusHistory = []domain.StatusChange{{To: status, ChangedBy: userID, ChangedAt: now}}
+	if err := uc.applyRelations(ctx, task, task.ParentID, task.BlockedBy, userID); err != nil {
+		return err
+	}
+	if err := uc.checkBlockers(ctx, nil, task); err != nil {
+		return err
+	}
+	requestedTags := task.Tags
+	task.Tags = nil
+	if err := uc.applyTags(ctx, task, requestedTags); err != nil {
+		return err
+	}
+	if requested := task.Recurrence; requested != nil {
+		// A new series is named after its first task, so it needs its ID now.
+		if task.ID.IsZero() {
+			task.ID = primitive.NewObjectID()
+		}
+		task.Recurrence = nil
+		if err := applyRecurrence(task, requested); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (uc *taskUsecase) GetUserTasks(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error) {
 	return uc.taskRepo.GetAllByUserID(ctx, userID)
 }
 
-func (uc *taskUsecase) GetTaskByID(ctx context.Context, taskID string, userID primitive.ObjectID) (*domain.Task, error) {
-	objectID, err := primitive.ObjectIDFromHex(taskID)
-	if err != nil {
-		return nil, errors.New("invalid task ID format")
+// ListTasks returns one page of the tasks the user owns, is assigned to or is
+// shared with, plus the cursor for the next page. The query is always scoped
+// to userID, whatever the caller put in it. A query for a project lists the
+// tasks of that project instead, archived or not, for its members only.
+func (uc *taskUsecase) ListTasks(ctx context.Context, query repositories.TaskQuery, userID primitive.ObjectID) ([]domain.Task, string, error) {
+	query.UserID = userID
+	query.IncludeArchived = false
+	if !query.ProjectID.IsZero() {
+		project, err := memberProject(ctx, uc.projectRepo, query.ProjectID, userID)
+		if err != nil {
+			return nil, "", err
+		}
+		query.IncludeArchived = !project.ArchivedAt.IsZero()
 	}
+	query.AnyTags = trimTags(query.AnyTags)
+	query.AllTags = trimTags(query.AllTags)
+	query.ExcludeTags = trimTags(query.ExcludeTags)
 
-	task, err := uc.taskRepo.GetByID(ctx, objectID)
-	if err != nil {
-		return nil, errors.New("task not found")
+	// Match known statuses however the client spelled them; legacy
+	// statuses outside the workflow are matched verbatim.
+	for _, statuses := range [][]string{query.Statuses, query.ExcludeStatuses} {
+		for i, status := range statuses {
+			if canonical, err := uc.workflow.Canonical(status); err == nil {
+				statuses[i] = canonical
+			}
+		}
 	}
 
-	if task.UserID != userID {
-		return nil, errors.New("task not found")
+	if !query.Scope.IsValid() {
+		return nil, "", fmt.Errorf("%w: unsupported scope %q", ErrInvalidTaskQuery, query.Scope)
 	}
 
-	return task, nil
+	if query.SortBy == "" {
+		query.SortBy = repositories.SortByDueDate
+	}
+	if !query.SortBy.IsValid() {
+		return nil, "", fmt.Errorf("%w: unsupported sort field %q", ErrInvalidTaskQuery, query.SortBy)
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultTaskPageSize
+	}
+	if query.Limit > MaxTaskPageSize {
+		query.Limit = MaxTaskPageSize
+	}
+
+	if !query.DueFrom.IsZero() && !query.DueTo.IsZero() && query.DueFrom.After(query.DueTo) ||
+		!query.DueFrom.IsZero() && !query.DueBefore.IsZero() && !query.DueFrom.Before(query.DueBefore) {
+		return nil, "", fmt.Errorf("%w: due date range is empty", ErrInvalidTaskQuery)
+	}
+
+	tasks, next, err := uc.taskRepo.ListTasks(ctx, query)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidTaskQuery, err)
+	}
+	return tasks, next, err
+}
+
+// trimTags trims tag names in a filter and drops empty ones.
+func trimTags(tags []string) []string {
+	var trimmed []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			trimmed = append(trimmed, tag)
+		}
+	}
+	return trimmed
+}
+
+func (uc *taskUsecase) GetTaskByID(ctx context.Context, taskID string, userID primitive.ObjectID) (*domain.Task, error) {
+	return uc.authorizeTask(ctx, taskID, userID, permissionView)
 }
 
 func (uc *taskUsecase) UpdateTask(ctx context.Context, taskID string, updatedTask *domain.Task, userID primitive.ObjectID) (*domain.Task, error) {
-	taskToUpdate, err := uc.GetTaskByID(ctx, taskID, userID)
+	taskToUpdate, err := uc.authorizeTask(ctx, taskID, userID, permissionEdit)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkVersion(taskToUpdate, updatedTask.Version); err != nil {
+		return nil, err
+	}
+	before := *taskToUpdate
 
-	taskToUpdate.Title = updatedTask.Title
-	taskToUpdate.Description = updatedTask.Description
-	taskToUpdate.Duedate = updatedTask.Duedate
-	taskToUpdate.Status = updatedTask.Status
-
-	err = uc.taskRepo.Update(ctx, taskToUpdate)
+	next, err := uc.applyChanges(ctx, &before, taskToUpdate, updatedTask, userID)
+	if err != nil {
+		return nil, err
+	}
+	version := taskToUpdate.Version
+	err = uc.run(ctx, func(ctx context.Context) error {
+		taskToUpdate.Version = version
+		if err := uc.saveUpdate(ctx, &before, taskToUpdate, userID); err != nil {
+			return err
+		}
+		return uc.createOccurrence(ctx, next, userID)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return taskToUpdate, nil
 }
 
-func (uc *taskUsecase) DeleteTask(ctx context.Context, taskID string, userID primitive.ObjectID) error {
-	taskToDelete, err := uc.GetTaskByID(ctx, taskID, userID)
+// applyChanges validates the editable fields of requested and copies them
+// onto task, which still reads as before. Completing a recurring task
+// returns its next occurrence, for the caller to create once task is saved.
+func (uc *taskUsecase) applyChanges(ctx context.Context, before, task, requested *domain.Task, userID primitive.ObjectID) (*domain.Task, error) {
+	if err := uc.applyRelations(ctx, task, requested.ParentID, requested.BlockedBy, userID); err != nil {
+		return nil, err
+	}
+	if err := uc.applyStatus(task, requested.Status, userID); err != nil {
+		return nil, err
+	}
+	if err := uc.checkBlockers(ctx, before, task); err != nil {
+		return nil, err
+	}
+	if err := uc.applyTags(ctx, task, requested.Tags); err != nil {
+		return nil, err
+	}
+
+	task.Title = requested.Title
+	task.Description = requested.Description
+	task.Duedate = requested.Duedate
+	if err := applyRecurrence(task, requested.Recurrence); err != nil {
+		return nil, err
+	}
+	return uc.nextOccurrence(before, task, userID)
+}
+
+// checkVersion fails with ErrVersionMismatch unless version is zero or the
+// task's current version.
+func checkVersion(task *domain.Task, version int64) error {
+	if version != 0 && version != task.Version {
+		return fmt.Errorf("%w: current version is %d", ErrVersionMismatch, task.Version)
+	}
+	return nil
+}
+
+// saveUpdate stores a changed task and audits the difference from before,
+// in one unit of work. The update only applies if nobody else saved the
+// task since it was read.
+func (uc *taskUsecase) saveUpdate(ctx context.Context, before, task *domain.Task, userID primitive.ObjectID) error {
+	version := task.Version
+	return uc.run(ctx, func(ctx context.Context) error {
+		task.Version = version
+		if err := uc.taskRepo.Update(ctx, task); err != nil {
+			return updateError(err)
+		}
+		return uc.auditUpdate(ctx, before, task, userID)
+	})
+}
+
+// updateError translates a repository update failure for callers.
+func updateError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrVersionConflict):
+		return ErrVersionMismatch
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrTaskNotFound
+	}
+	return err
+}
+
+// auditUpdate records the difference between two versions of a task and
+// emits the events of the update.
+func (uc *taskUsecase) auditUpdate(ctx context.Context, before, task *domain.Task, userID primitive.ObjectID) error {
+	// An update that changes nothing leaves nothing to audit.
+	entry := newTaskAuditEntry(domain.AuditActionUpdate, before, task, userID)
+	if len(entry.Changes) == 0 {
+		return nil
+	}
+	if err := uc.auditRepo.Append(ctx, entry); err != nil {
+		return err
+	}
+	return uc.emit(ctx, EventTaskUpdated, before, task, userID)
+}
+
+func (uc *taskUsecase) DeleteTask(ctx context.Context, taskID string, version int64, userID primitive.ObjectID) error {
+	taskToDelete, err := uc.authorizeTask(ctx, taskID, userID, permissionManage)
 	if err != nil {
 		return err
 	}
+	if err := checkVersion(taskToDelete, version); err != nil {
+		return err
+	}
 
-	return uc.taskRepo.Delete(ctx, taskToDelete.ID)
+	taskToDelete.DeletedAt = time.Now().UTC().Truncate(time.Millisecond)
+	taskToDelete.DeletedBy = userID
+	version = taskToDelete.Version
+	return uc.run(ctx, func(ctx context.Context) error {
+		taskToDelete.Version = version
+		if err := uc.taskRepo.UpdateFields(ctx, taskToDelete, []repositories.TaskField{repositories.TaskFieldDeletion}); err != nil {
+			return updateError(err)
+		}
+		if err := uc.auditRepo.Append(ctx, newTaskAuditEntry(domain.AuditActionDelete, taskToDelete, nil, userID)); err != nil {
+			return err
+		}
+		return uc.emit(ctx, EventTaskDeleted, nil, taskToDelete, userID)
+	})
+}
+
+// GetTaskHistory returns one page of a task's audit entries, newest first.
+// Only users who can see the task can see its history.
+func (uc *taskUsecase) GetTaskHistory(ctx context.Context, taskID string, query repositories.AuditQuery, userID primitive.ObjectID) ([]domain.AuditEntry, string, error) {
+	task, err := uc.GetTaskByID(ctx, taskID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	query.EntityType = domain.AuditEntityTask
+	query.EntityID = task.ID
+	return listAuditEntries(ctx, uc.auditRepo, query)
+}
+
+// applyStatus moves task to the requested status if the workflow allows it,
+// recording who made the transition and when.
+func (uc *taskUsecase) applyStatus(task *domain.Task, requested string, userID primitive.ObjectID) error {
+	to, err := uc.workflow.Canonical(requested)
+	if err != nil {
+		return err
+	}
+	from := task.Status
+	if canonical, err := uc.workflow.Canonical(from); err == nil {
+		from = canonical
+	}
+	if err := uc.workflow.CheckTransition(from, to); err != nil {
+		return err
+	}
+
+	if from != to {
+		task.StatusHistory = append(task.StatusHistory, domain.StatusChange{
+			From:      from,
+			To:        to,
+			ChangedBy: userID,
+			ChangedAt: time.Now().UTC(),
+		})
+	}
+	task.Status = to
+	return nil
 }
@@ -2,8 +2,10 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"taskmanager/domain"
 	"taskmanager/mocks"
+	"taskmanager/repositories"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,6 +15,8 @@ import (
 
 func TestCreateTask_Success(t *testing.T) {
 	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
 	userID := primitive.NewObjectID()
 
 	taskToCreate := &domain.Task{
@@ -23,8 +27,11 @@ func TestCreateTask_Success(t *testing.T) {
 	mockTaskRepo.On("Create", mock.Anything, mock.MatchedBy(func(task *domain.Task) bool {
 		return task.UserID == userID && task.Title == "New Task"
 	})).Return(nil)
+	mockAuditRepo.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditActionCreate && entry.ActorID == userID
+	})).Return(nil)
 
-	usecase := NewTaskUsecase(mockTaskRepo)
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
 	createdTask, err := usecase.CreateTask(context.Background(), taskToCreate, userID)
 
 	// --- ASSERT ---
@@ -34,8 +41,25 @@ func TestCreateTask_Success(t *testing.T) {
 	mockTaskRepo.AssertExpectations(t)
 }
 
+func TestCreateTask_FailedAuditEntryKeepsNoTask(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	mockAuditRepo := new(mocks.IAuditRepository)
+	mockAuditRepo.On("Append", mock.Anything, mock.Anything).Return(errors.New("audit log unavailable"))
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, mockAuditRepo, repos.Tags, repos.Projects, WithUnitOfWork(repos.UnitOfWork))
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+
+	_, err := usecase.CreateTask(ctx, &domain.Task{Title: "Unaudited", Status: StatusPending}, userID)
+	assert.Error(t, err)
+	tasks, err := repos.Tasks.GetAllByUserID(ctx, userID)
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
 func TestGetTaskByID_Success_OwnerMatch(t *testing.T) {
 	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
 	taskID := primitive.NewObjectID()
 	userID := primitive.NewObjectID()
 
@@ -47,7 +71,7 @@ func TestGetTaskByID_Success_OwnerMatch(t *testing.T) {
 
 	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(fakeTask, nil)
 
-	usecase := NewTaskUsecase(mockTaskRepo)
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
 	foundTask, err := usecase.GetTaskByID(context.Background(), taskID.Hex(), userID)
 
 	// --- ASSERT ---
@@ -59,6 +83,8 @@ func TestGetTaskByID_Success_OwnerMatch(t *testing.T) {
 
 func TestGetTaskByID_Failure_OwnerMismatch(t *testing.T) {
 	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
 	taskID := primitive.NewObjectID()
 	ownerUserID := primitive.NewObjectID()
 	requesterUserID := primitive.NewObjectID()
@@ -70,7 +96,7 @@ func TestGetTaskByID_Failure_OwnerMismatch(t *testing.T) {
 	}
 
 	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(fakeTask, nil)
-	usecase := NewTaskUsecase(mockTaskRepo)
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
 	foundTask, err := usecase.GetTaskByID(context.Background(), taskID.Hex(), requesterUserID)
 
 	// --- ASSERT ---
@@ -79,3 +105,252 @@ func TestGetTaskByID_Failure_OwnerMismatch(t *testing.T) {
 	assert.Equal(t, "task not found", err.Error())
 	mockTaskRepo.AssertExpectations(t)
 }
+
+func TestListTasks_AppliesDefaultsAndUserScope(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	userID := primitive.NewObjectID()
+
+	expected := []domain.Task{{ID: primitive.NewObjectID(), Title: "First", UserID: userID}}
+
+	mockTaskRepo.On("ListTasks", mock.Anything, mock.MatchedBy(func(q repositories.TaskQuery) bool {
+		return q.UserID == userID && q.SortBy == repositories.SortByDueDate && q.Limit == DefaultTaskPageSize
+	})).Return(expected, "next-page", nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	// A caller-supplied UserID must be overridden by the authenticated user.
+	tasks, next, err := usecase.ListTasks(context.Background(), repositories.TaskQuery{UserID: primitive.NewObjectID()}, userID)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, expected, tasks)
+	assert.Equal(t, "next-page", next)
+	mockTaskRepo.AssertExpectations(t)
+}
+
+func TestListTasks_Failure_InvalidSortField(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	_, _, err := usecase.ListTasks(context.Background(), repositories.TaskQuery{SortBy: "priority"}, primitive.NewObjectID())
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrInvalidTaskQuery)
+	mockTaskRepo.AssertNotCalled(t, "ListTasks", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_RecordsStatusTransition(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+
+	existing := &domain.Task{ID: taskID, Title: "Write docs", Status: StatusPending, UserID: userID}
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(existing, nil)
+	mockTaskRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	mockAuditRepo.On("Append", mock.Anything, mock.Anything).Return(nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	updated, err := usecase.UpdateTask(context.Background(), taskID.Hex(), &domain.Task{Title: "Write docs", Status: "in_progress"}, userID)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, StatusInProgress, updated.Status)
+	if assert.Len(t, updated.StatusHistory, 1) {
+		change := updated.StatusHistory[0]
+		assert.Equal(t, StatusPending, change.From)
+		assert.Equal(t, StatusInProgress, change.To)
+		assert.Equal(t, userID, change.ChangedBy)
+		assert.False(t, change.ChangedAt.IsZero())
+	}
+	mockTaskRepo.AssertExpectations(t)
+}
+
+func TestUpdateTask_Failure_TransitionNotAllowed(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	userID := primitive.NewObjectID()
+
+	existing := &domain.Task{ID: taskID, Title: "Ship it", Status: StatusCompleted, UserID: userID}
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(existing, nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	_, err := usecase.UpdateTask(context.Background(), taskID.Hex(), &domain.Task{Title: "Ship it", Status: StatusPending}, userID)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+	mockTaskRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_WritesFieldLevelAuditEntry(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	existing := &domain.Task{ID: taskID, Title: "Draft", Description: "same", Status: StatusPending, UserID: ownerID}
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(existing, nil)
+	mockTaskRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	var recorded *domain.AuditEntry
+	mockAuditRepo.On("Append", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(1).(*domain.AuditEntry)
+	}).Return(nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	_, err := usecase.UpdateTask(context.Background(), taskID.Hex(),
+		&domain.Task{Title: "Final", Description: "same", Status: StatusInProgress}, ownerID)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, domain.AuditEntityTask, recorded.EntityType)
+		assert.Equal(t, taskID, recorded.EntityID)
+		assert.Equal(t, domain.AuditActionUpdate, recorded.Action)
+		assert.Equal(t, ownerID, recorded.ActorID)
+		assert.Equal(t, []domain.FieldChange{
+			{Field: "title", Before: "Draft", After: "Final"},
+			{Field: "status", Before: StatusPending, After: StatusInProgress},
+		}, recorded.Changes)
+	}
+}
+
+func TestUpdateTask_NoChangesWritesNoAuditEntry(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	existing := &domain.Task{ID: taskID, Title: "Same", Status: StatusPending, UserID: ownerID}
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(existing, nil)
+	mockTaskRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	_, err := usecase.UpdateTask(context.Background(), taskID.Hex(), &domain.Task{Title: "Same", Status: StatusPending}, ownerID)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	mockAuditRepo.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_Failure_StaleVersion(t *testing.T) {
+	repos := repositories.NewMemoryRepositories()
+	usecase := NewTaskUsecase(repos.Tasks, repos.Users, repos.Audit, repos.Tags, repos.Projects)
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+
+	task, err := usecase.CreateTask(ctx, &domain.Task{Title: "Draft", Status: StatusPending}, ownerID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), task.Version)
+
+	updated, err := usecase.UpdateTask(ctx, task.ID.Hex(), &domain.Task{Title: "First", Status: StatusPending, Version: 1}, ownerID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	// A second editor who read version 1 is turned away, for updates and deletes alike.
+	_, err = usecase.UpdateTask(ctx, task.ID.Hex(), &domain.Task{Title: "Second", Status: StatusPending, Version: 1}, ownerID)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	assert.ErrorIs(t, usecase.DeleteTask(ctx, task.ID.Hex(), 1, ownerID), ErrVersionMismatch)
+
+	// --- ASSERT ---
+	found, err := usecase.GetTaskByID(ctx, task.ID.Hex(), ownerID)
+	assert.NoError(t, err)
+	assert.Equal(t, "First", found.Title)
+	assert.NoError(t, usecase.DeleteTask(ctx, task.ID.Hex(), 2, ownerID))
+}
+
+func TestUpdateTask_Failure_ConcurrentSave(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	// Someone saves the task between our read and our write.
+	existing := &domain.Task{ID: taskID, Title: "Draft", Status: StatusPending, UserID: ownerID, Version: 3}
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(existing, nil)
+	mockTaskRepo.On("Update", mock.Anything, mock.Anything).Return(repositories.ErrVersionConflict)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	_, err := usecase.UpdateTask(context.Background(), taskID.Hex(), &domain.Task{Title: "Final", Status: StatusPending, Version: 3}, ownerID)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	mockAuditRepo.AssertNotCalled(t, "Append", mock.Anything, mock.Anything)
+}
+
+func TestDeleteTask_RecordsDeletedValues(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	existing := &domain.Task{ID: taskID, Title: "Old", Status: StatusCompleted, UserID: ownerID}
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(existing, nil)
+	mockTaskRepo.On("UpdateFields", mock.Anything, mock.MatchedBy(func(task *domain.Task) bool {
+		return !task.DeletedAt.IsZero() && task.DeletedBy == ownerID
+	}), []repositories.TaskField{repositories.TaskFieldDeletion}).Return(nil)
+	mockAuditRepo.On("Append", mock.Anything, mock.MatchedBy(func(entry *domain.AuditEntry) bool {
+		return entry.Action == domain.AuditActionDelete && entry.EntityID == taskID &&
+			assert.ObjectsAreEqual([]domain.FieldChange{
+				{Field: "title", Before: "Old"},
+				{Field: "status", Before: StatusCompleted},
+			}, entry.Changes)
+	})).Return(nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	err := usecase.DeleteTask(context.Background(), taskID.Hex(), 0, ownerID)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	mockAuditRepo.AssertExpectations(t)
+	mockTaskRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestGetTaskHistory_ScopesQueryToTask(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+	ownerID := primitive.NewObjectID()
+
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(&domain.Task{ID: taskID, UserID: ownerID}, nil)
+	mockAuditRepo.On("List", mock.Anything, mock.MatchedBy(func(q repositories.AuditQuery) bool {
+		return q.EntityType == domain.AuditEntityTask && q.EntityID == taskID && q.Limit == DefaultAuditPageSize
+	})).Return([]domain.AuditEntry{{EntityID: taskID}}, "", nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	// Filters naming another entity must not leak into the result.
+	entries, _, err := usecase.GetTaskHistory(context.Background(), taskID.Hex(),
+		repositories.AuditQuery{EntityID: primitive.NewObjectID()}, ownerID)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	mockAuditRepo.AssertExpectations(t)
+}
+
+func TestGetTaskHistory_Failure_NotOwner(t *testing.T) {
+	mockTaskRepo := new(mocks.ITaskRepository)
+	mockUserRepo := new(mocks.IUserRepository)
+	mockAuditRepo := new(mocks.IAuditRepository)
+	taskID := primitive.NewObjectID()
+
+	mockTaskRepo.On("GetByID", mock.Anything, taskID).Return(&domain.Task{ID: taskID, UserID: primitive.NewObjectID()}, nil)
+
+	usecase := NewTaskUsecase(mockTaskRepo, mockUserRepo, mockAuditRepo, new(mocks.ITagRepository), new(mocks.IProjectRepository))
+	_, _, err := usecase.GetTaskHistory(context.Background(), taskID.Hex(), repositories.AuditQuery{}, primitive.NewObjectID())
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+	mockAuditRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
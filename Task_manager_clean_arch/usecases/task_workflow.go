@@ -0,0 +1,156 @@
+package usecases
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	StatusPending    = "Pending"
+	StatusInProgress = "In Progress"
+	StatusCompleted  = "Completed"
+	StatusReopened   = "Reopened"
+)
+
+var (
+	// ErrUnknownStatus is returned when a status is not a state of the workflow.
+	ErrUnknownStatus = errors.New("unknown status")
+	// ErrInvalidStatusTransition is returned when the workflow does not allow
+	// moving a task from its current status to the requested one.
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+)
+
+// StatusWorkflow is the state machine task statuses move through. States are
+// the keys of the transition table; each maps to the states reachable from it.
+type StatusWorkflow struct {
+	transitions map[string]map[string]bool
+	// canonical maps a normalized spelling to the state's configured name.
+	canonical map[string]string
+	// done holds the states that count as finished work.
+	done map[string]bool
+}
+
+// NewStatusWorkflow builds a workflow from a transition table. Every target
+// state must also appear as a key. StatusCompleted, if defined, is the done
+// state.
+func NewStatusWorkflow(transitions map[string][]string) (*StatusWorkflow, error) {
+	if len(transitions) == 0 {
+		return nil, errors.New("workflow must define at least one status")
+	}
+	w := &StatusWorkflow{
+		transitions: make(map[string]map[string]bool),
+		canonical:   make(map[string]string),
+		done:        make(map[string]bool),
+	}
+	for state := range transitions {
+		key := normalizeStatus(state)
+		if key == "" {
+			return nil, errors.New("workflow status names must not be empty")
+		}
+		if existing, ok := w.canonical[key]; ok {
+			return nil, fmt.Errorf("workflow statuses %q and %q are ambiguous", existing, state)
+		}
+		w.canonical[key] = state
+		w.transitions[state] = make(map[string]bool)
+	}
+	for from, targets := range transitions {
+		for _, to := range targets {
+			if _, ok := w.transitions[to]; !ok {
+				return nil, fmt.Errorf("workflow transition %q -> %q targets an undefined status", from, to)
+			}
+			w.transitions[from][to] = true
+		}
+	}
+	if _, ok := w.transitions[StatusCompleted]; ok {
+		w.done[StatusCompleted] = true
+	}
+	return w, nil
+}
+
+// DefaultStatusWorkflow is used unless the deployment configures its own.
+// A completed task has to be reopened before work on it can resume.
+func DefaultStatusWorkflow() *StatusWorkflow {
+	w, _ := NewStatusWorkflow(map[string][]string{
+		StatusPending:    {StatusInProgress, StatusCompleted},
+		StatusInProgress: {StatusPending, StatusCompleted},
+		StatusCompleted:  {StatusReopened},
+		StatusReopened:   {StatusInProgress, StatusCompleted},
+	})
+	return w
+}
+
+// ParseStatusWorkflow reads a workflow from JSON of the form
+// {"transitions": {"Pending": ["In Progress"], "In Progress": []}, "done": ["In Progress"]}.
+// "done" is optional and replaces the default done state.
+func ParseStatusWorkflow(data []byte) (*StatusWorkflow, error) {
+	var config struct {
+		Transitions map[string][]string `json:"transitions"`
+		Done        []string            `json:"done"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	w, err := NewStatusWorkflow(config.Transitions)
+	if err != nil {
+		return nil, err
+	}
+	if config.Done != nil {
+		w.done = make(map[string]bool)
+		for _, state := range config.Done {
+			if _, ok := w.transitions[state]; !ok {
+				return nil, fmt.Errorf("workflow done status %q is undefined", state)
+			}
+			w.done[state] = true
+		}
+	}
+	return w, nil
+}
+
+// normalizeStatus folds case, and treats runs of spaces, underscores and
+// hyphens as a single space, so "in_progress" matches "In Progress".
+func normalizeStatus(status string) string {
+	fields := strings.FieldsFunc(strings.ToLower(status), func(r rune) bool {
+		return r == ' ' || r == '_' || r == '-' || r == '\t'
+	})
+	return strings.Join(fields, " ")
+}
+
+// Canonical returns the workflow's spelling of status, or ErrUnknownStatus.
+func (w *StatusWorkflow) Canonical(status string) (string, error) {
+	if state, ok := w.canonical[normalizeStatus(status)]; ok {
+		return state, nil
+	}
+	return "", fmt.Errorf("%w %q: allowed statuses are %s", ErrUnknownStatus, status, strings.Join(w.States(), ", "))
+}
+
+// States lists the workflow's statuses in alphabetical order.
+func (w *StatusWorkflow) States() []string {
+	states := make([]string, 0, len(w.transitions))
+	for state := range w.transitions {
+		states = append(states, state)
+	}
+	sort.Strings(states)
+	return states
+}
+
+// IsDone reports whether a canonical status counts as finished work.
+func (w *StatusWorkflow) IsDone(status string) bool {
+	return w.done[status]
+}
+
+// CheckTransition validates moving from one status to another. Both must be
+// canonical. Staying in the same status is always allowed, and so is leaving
+// a legacy status the workflow does not know about.
+func (w *StatusWorkflow) CheckTransition(from, to string) error {
+	if from == to {
+		return nil
+	}
+	allowed, known := w.transitions[from]
+	if !known || allowed[to] {
+		return nil
+	}
+	return fmt.Errorf("%w: cannot move from %q to %q", ErrInvalidStatusTransition, from, to)
+}
@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusWorkflow_CanonicalFixesSpelling(t *testing.T) {
+	workflow := DefaultStatusWorkflow()
+
+	for _, input := range []string{"in progress", "IN_PROGRESS", " In-Progress ", "in  progress"} {
+		status, err := workflow.Canonical(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, StatusInProgress, status, input)
+	}
+
+	_, err := workflow.Canonical("Complted")
+	assert.ErrorIs(t, err, ErrUnknownStatus)
+}
+
+func TestStatusWorkflow_CheckTransition(t *testing.T) {
+	workflow := DefaultStatusWorkflow()
+
+	assert.NoError(t, workflow.CheckTransition(StatusPending, StatusInProgress))
+	assert.NoError(t, workflow.CheckTransition(StatusCompleted, StatusCompleted))
+	assert.NoError(t, workflow.CheckTransition(StatusCompleted, StatusReopened))
+	// A completed task must be reopened before it goes back to pending.
+	assert.ErrorIs(t, workflow.CheckTransition(StatusCompleted, StatusPending), ErrInvalidStatusTransition)
+	// Tasks created before the workflow existed may leave their legacy status.
+	assert.NoError(t, workflow.CheckTransition("Done", StatusPending))
+}
+
+func TestParseStatusWorkflow(t *testing.T) {
+	workflow, err := ParseStatusWorkflow([]byte(`{"transitions": {"Open": ["Closed"], "Closed": []}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Closed", "Open"}, workflow.States())
+	assert.ErrorIs(t, workflow.CheckTransition("Closed", "Open"), ErrInvalidStatusTransition)
+
+	_, err = ParseStatusWorkflow([]byte(`{"transitions": {"Open": ["Missing"]}}`))
+	assert.Error(t, err)
+}
+
+func TestStatusWorkflow_DoneStates(t *testing.T) {
+	assert.True(t, DefaultStatusWorkflow().IsDone(StatusCompleted))
+	assert.False(t, DefaultStatusWorkflow().IsDone(StatusReopened))
+
+	workflow, err := ParseStatusWorkflow([]byte(`{"transitions": {"Open": ["Closed"], "Closed": []}, "done": ["Closed"]}`))
+	assert.NoError(t, err)
+	assert.True(t, workflow.IsDone("Closed"))
+	assert.False(t, workflow.IsDone("Open"))
+
+	_, err = ParseStatusWorkflow([]byte(`{"transitions": {"Open": []}, "done": ["Closed"]}`))
+	assert.Error(t, err)
+}
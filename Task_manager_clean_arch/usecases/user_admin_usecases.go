@@ -0,0 +1,605 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	DefaultUserPageSize = 50
+	MaxUserPageSize     = 200
+)
+
+var (
+	// ErrInvalidUserQuery is returned when a user listing query is malformed.
+	ErrInvalidUserQuery = errors.New("invalid user query")
+	// ErrLastAdmin is returned for demoting, disabling or deleting the only
+	// enabled admin, which would leave no one able to manage the server.
+	ErrLastAdmin = errors.New("cannot remove the last admin")
+	// ErrInvalidReassignment is returned when a deleted user's tasks cannot
+	// go to the user named.
+	ErrInvalidReassignment = errors.New("invalid task reassignment")
+)
+
+// IUserAdminUsecase manages user accounts on an admin's behalf. Actors can
+// only act on users whose permissions they hold themselves, so no one can
+// take over an account that can do more than they can.
+type IUserAdminUsecase interface {
+	// IsAccountActive reports whether the user exists and is not disabled.
+	infrastructure.IAccountChecker
+	// ListUsers returns a page of users ordered by username.
+	ListUsers(ctx context.Context, query repositories.UserQuery) ([]domain.User, string, error)
+	GetUser(ctx context.Context, userID string) (*domain.User, error)
+	// PromoteUser gives the user the admin role. The admin role holds every
+	// permission, so only actors who hold every permission can give it.
+	PromoteUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error)
+	// DemoteUser takes the admin role away. A user left without roles gets
+	// the user role.
+	DemoteUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error)
+	// DisableUser stops the user from logging in and from using the tokens
+	// they already have, until EnableUser.
+	DisableUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error)
+	EnableUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error)
+	// DeleteUser deletes the user with their tags, webhooks and calendar
+	// feeds. Their tasks, trashed ones included, and project memberships go
+	// to the user reassignTo names, or are deleted for good when it is
+	// empty. It returns how many tasks were reassigned or deleted. The
+	// deletion is atomic when the database supports transactions.
+	DeleteUser(ctx context.Context, userID, reassignTo string, actorID primitive.ObjectID) (int, error)
+	// UnlockUser forgets the failed logins for the user's username, so they
+	// can log in again at once. Failures from client addresses still count.
+	UnlockUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error)
+	// ResetPassword gives the user a new random password and returns it.
+	// Every session of the user ends.
+	ResetPassword(ctx context.Context, userID string, actorID primitive.ObjectID) (string, error)
+}
+
+type userAdminUsecase struct {
+	userRepo        repositories.IUserRepository
+	tokenRepo       repositories.ITokenRepository
+	loginAttempts   repositories.ILoginAttemptRepository
+	taskRepo        repositories.ITaskRepository
+	tagRepo         repositories.ITagRepository
+	projectRepo     repositories.IProjectRepository
+	webhookRepo     repositories.IWebhookRepository
+	feedRepo        repositories.ICalendarFeedRepository
+	auditRepo       repositories.IAuditRepository
+	passwordService infrastructure.IPasswordService
+	permissions     infrastructure.IPermissionChecker
+	unitOfWork      repositories.IUnitOfWork
+}
+
+// NewUserAdminUsecase takes its repositories from one backend's bundle,
+// since deleting a user reaches nearly all of them.
+func NewUserAdminUsecase(repos *repositories.Repositories, ps infrastructure.IPasswordService, permissions infrastructure.IPermissionChecker) IUserAdminUsecase {
+	return &userAdminUsecase{
+		userRepo:        repos.Users,
+		tokenRepo:       repos.Tokens,
+		loginAttempts:   repos.LoginAttempts,
+		taskRepo:        repos.Tasks,
+		tagRepo:         repos.Tags,
+		projectRepo:     repos.Projects,
+		webhookRepo:     repos.Webhooks,
+		feedRepo:        repos.CalendarFeeds,
+		auditRepo:       repos.Audit,
+		passwordService: ps,
+		permissions:     permissions,
+		unitOfWork:      repos.UnitOfWork,
+	}
+}
+
+// isEnabledAdmin reports whether the user counts towards keeping an admin.
+func isEnabledAdmin(user *domain.User) bool {
+	return slices.Contains(user.Roles, domain.RoleAdmin) && user.DisabledAt.IsZero()
+}
+
+// checkNotLastAdmin fails with ErrLastAdmin if the user is the only enabled
+// admin.
+func checkNotLastAdmin(ctx context.Context, userRepo repositories.IUserRepository, user *domain.User) error {
+	if !isEnabledAdmin(user) {
+		return nil
+	}
+	admins, _, err := userRepo.List(ctx, repositories.UserQuery{Role: domain.RoleAdmin, Status: repositories.UserStatusActive, Limit: 2})
+	if err != nil {
+		return err
+	}
+	if len(admins) > 1 {
+		return nil
+	}
+	return ErrLastAdmin
+}
+
+// saveKeepingAdmin saves the fields of user, which was read as before, and
+// fails with ErrLastAdmin if that leaves no enabled admin. Checking only
+// beforehand would let two admins demoting or disabling each other at once
+// both pass, so the check is made again once the change is stored: the
+// later of two such changes always finds no admin left and puts its fields
+// back as they were.
+func saveKeepingAdmin(ctx context.Context, userRepo repositories.IUserRepository, before, user *domain.User, fields []repositories.UserField) error {
+	if err := checkNotLastAdmin(ctx, userRepo, before); err != nil {
+		return err
+	}
+	if err := userRepo.UpdateFields(ctx, user, fields); err != nil {
+		return err
+	}
+	if !isEnabledAdmin(before) || isEnabledAdmin(user) {
+		return nil
+	}
+	admins, _, err := userRepo.List(ctx, repositories.UserQuery{Role: domain.RoleAdmin, Status: repositories.UserStatusActive, Limit: 1})
+	if err == nil && len(admins) > 0 {
+		return nil
+	}
+	if err == nil {
+		err = ErrLastAdmin
+	}
+	// The change is put back even if the request has been cancelled.
+	if undoErr := userRepo.UpdateFields(context.WithoutCancel(ctx), before, fields); undoErr != nil {
+		return errors.Join(err, undoErr)
+	}
+	return err
+}
+
+// newUserAuditEntry describes a change to a user account.
+func newUserAuditEntry(action string, userID, actorID primitive.ObjectID, changes ...domain.FieldChange) *domain.AuditEntry {
+	return &domain.AuditEntry{
+		EntityType: domain.AuditEntityUser,
+		EntityID:   userID,
+		Action:     action,
+		ActorID:    actorID,
+		Timestamp:  time.Now().UTC().Truncate(time.Millisecond),
+		Changes:    changes,
+	}
+}
+
+func (uc *userAdminUsecase) IsAccountActive(ctx context.Context, userID primitive.ObjectID) (bool, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user.DisabledAt.IsZero(), nil
+}
+
+func (uc *userAdminUsecase) ListUsers(ctx context.Context, query repositories.UserQuery) ([]domain.User, string, error) {
+	if query.Limit <= 0 {
+		query.Limit = DefaultUserPageSize
+	}
+	if query.Limit > MaxUserPageSize {
+		query.Limit = MaxUserPageSize
+	}
+	query.Search = strings.TrimSpace(query.Search)
+	switch query.Status {
+	case "", repositories.UserStatusActive, repositories.UserStatusDisabled:
+	default:
+		return nil, "", fmt.Errorf("%w: unknown status %q", ErrInvalidUserQuery, query.Status)
+	}
+
+	users, next, err := uc.userRepo.List(ctx, query)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidUserQuery, err)
+	}
+	return users, next, err
+}
+
+func (uc *userAdminUsecase) GetUser(ctx context.Context, userID string) (*domain.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	user, err := uc.userRepo.FindByID(ctx, objectID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+// authorizeUser loads a user the actor may manage: one whose permissions
+// the actor holds as well.
+func (uc *userAdminUsecase) authorizeUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error) {
+	user, err := uc.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	held, err := uc.permissions.UserPermissions(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := uc.permissions.UserPermissions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range theirs {
+		if !slices.Contains(held, permission) {
+			return nil, fmt.Errorf("%w: the user holds %s, which you do not", ErrForbidden, permission)
+		}
+	}
+	return user, nil
+}
+
+func (uc *userAdminUsecase) PromoteUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error) {
+	user, err := uc.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	held, err := uc.permissions.UserPermissions(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+	for _, permission := range KnownPermissions() {
+		if !slices.Contains(held, permission) {
+			return nil, fmt.Errorf("%w: the admin role holds %s, which you do not", ErrForbidden, permission)
+		}
+	}
+	if slices.Contains(user.Roles, domain.RoleAdmin) {
+		return user, nil
+	}
+
+	before := strings.Join(user.Roles, ",")
+	user.Roles = append(user.Roles, domain.RoleAdmin)
+	if err := uc.userRepo.UpdateFields(ctx, user, []repositories.UserField{repositories.UserFieldRoles}); err != nil {
+		return nil, err
+	}
+	return user, uc.auditRepo.Append(ctx, newUserAuditEntry(domain.AuditActionUpdate, user.ID, actorID,
+		domain.FieldChange{Field: "roles", Before: before, After: strings.Join(user.Roles, ",")}))
+}
+
+func (uc *userAdminUsecase) DemoteUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error) {
+	user, err := uc.authorizeUser(ctx, userID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(user.Roles, domain.RoleAdmin) {
+		return user, nil
+	}
+
+	previous := *user
+	user.Roles = slices.DeleteFunc(slices.Clone(user.Roles), func(role string) bool { return role == domain.RoleAdmin })
+	if len(user.Roles) == 0 {
+		user.Roles = []string{domain.RoleUser}
+	}
+	if err := saveKeepingAdmin(ctx, uc.userRepo, &previous, user, []repositories.UserField{repositories.UserFieldRoles}); err != nil {
+		return nil, err
+	}
+	return user, uc.auditRepo.Append(ctx, newUserAuditEntry(domain.AuditActionUpdate, user.ID, actorID,
+		domain.FieldChange{Field: "roles", Before: strings.Join(previous.Roles, ","), After: strings.Join(user.Roles, ",")}))
+}
+
+func (uc *userAdminUsecase) DisableUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error) {
+	user, err := uc.authorizeUser(ctx, userID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == actorID {
+		return nil, fmt.Errorf("%w: you cannot disable your own account", ErrForbidden)
+	}
+	if !user.DisabledAt.IsZero() {
+		return user, nil
+	}
+	return uc.setDisabledAt(ctx, user, time.Now().UTC().Truncate(time.Millisecond), actorID)
+}
+
+func (uc *userAdminUsecase) EnableUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error) {
+	user, err := uc.authorizeUser(ctx, userID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt.IsZero() {
+		return user, nil
+	}
+	return uc.setDisabledAt(ctx, user, time.Time{}, actorID)
+}
+
+func (uc *userAdminUsecase) setDisabledAt(ctx context.Context, user *domain.User, disabledAt time.Time, actorID primitive.ObjectID) (*domain.User, error) {
+	previous := *user
+	user.DisabledAt = disabledAt
+	if err := saveKeepingAdmin(ctx, uc.userRepo, &previous, user, []repositories.UserField{repositories.UserFieldDisabled}); err != nil {
+		return nil, err
+	}
+	return user, uc.auditRepo.Append(ctx, newUserAuditEntry(domain.AuditActionUpdate, user.ID, actorID,
+		domain.FieldChange{Field: "disabled", Before: strconv.FormatBool(!previous.DisabledAt.IsZero()), After: strconv.FormatBool(!disabledAt.IsZero())}))
+}
+
+func (uc *userAdminUsecase) UnlockUser(ctx context.Context, userID string, actorID primitive.ObjectID) (*domain.User, error) {
+	user, err := uc.authorizeUser(ctx, userID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	key := usernameLoginKey(user.Username)
+	attempts, err := uc.loginAttempts.Find(ctx, key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.loginAttempts.Delete(ctx, key); err != nil {
+		return nil, err
+	}
+	return user, uc.auditRepo.Append(ctx, newUserAuditEntry(domain.AuditActionUnlock, user.ID, actorID,
+		domain.FieldChange{Field: "failed_logins", Before: strconv.Itoa(attempts.Failures), After: "0"}))
+}
+
+func (uc *userAdminUsecase) ResetPassword(ctx context.Context, userID string, actorID primitive.ObjectID) (string, error) {
+	user, err := uc.authorizeUser(ctx, userID, actorID)
+	if err != nil {
+		return "", err
+	}
+	password, err := infrastructure.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if user.Password, err = uc.passwordService.HashPassword(password); err != nil {
+		return "", err
+	}
+	if err := uc.userRepo.UpdateFields(ctx, user, []repositories.UserField{repositories.UserFieldPassword}); err != nil {
+		return "", err
+	}
+	if err := revokeUserSessions(ctx, uc.tokenRepo, user.ID); err != nil {
+		return "", err
+	}
+	// The hashes are secret, so the change is recorded without them.
+	return password, uc.auditRepo.Append(ctx, newUserAuditEntry(domain.AuditActionUpdate, user.ID, actorID,
+		domain.FieldChange{Field: "password", Before: "", After: "reset"}))
+}
+
+func (uc *userAdminUsecase) DeleteUser(ctx context.Context, userID, reassignTo string, actorID primitive.ObjectID) (moved int, err error) {
+	user, err := uc.authorizeUser(ctx, userID, actorID)
+	if err != nil {
+		return 0, err
+	}
+	if user.ID == actorID {
+		return 0, fmt.Errorf("%w: you cannot delete your own account", ErrForbidden)
+	}
+	if err := checkNotLastAdmin(ctx, uc.userRepo, user); err != nil {
+		return 0, err
+	}
+	var target *domain.User
+	if reassignTo != "" {
+		if target, err = uc.GetUser(ctx, reassignTo); err != nil {
+			return 0, fmt.Errorf("%w: no user %s", ErrInvalidReassignment, reassignTo)
+		}
+		if target.ID == user.ID {
+			return 0, fmt.Errorf("%w: tasks cannot go to the user being deleted", ErrInvalidReassignment)
+		}
+	}
+
+	deleteUser := func(ctx context.Context) error {
+		tasks, err := uc.ownedTasks(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if target != nil {
+			err = uc.reassignTasks(ctx, tasks, user, target, actorID)
+		} else {
+			err = uc.deleteTasks(ctx, tasks, actorID)
+		}
+		if err != nil {
+			return err
+		}
+		moved = len(tasks)
+		if err := uc.taskRepo.RemoveUser(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := uc.leaveProjects(ctx, user.ID, target); err != nil {
+			return err
+		}
+		if err := uc.deleteBelongings(ctx, user.ID); err != nil {
+			return err
+		}
+		if err := uc.userRepo.Delete(ctx, user.ID); err != nil {
+			return err
+		}
+		change := domain.FieldChange{Field: "username", Before: user.Username}
+		if target != nil {
+			change = domain.FieldChange{Field: "tasks", Before: user.ID.Hex(), After: target.ID.Hex()}
+		}
+		return uc.auditRepo.Append(ctx, newUserAuditEntry(domain.AuditActionDelete, user.ID, actorID, change))
+	}
+	// The account is disabled first, through the last admin guard, so an
+	// admin being deleted cannot race another admin's demotion or removal.
+	// A deletion that fails enables it again.
+	if isEnabledAdmin(user) {
+		disabled := *user
+		disabled.DisabledAt = time.Now().UTC().Truncate(time.Millisecond)
+		if err := saveKeepingAdmin(ctx, uc.userRepo, user, &disabled, []repositories.UserField{repositories.UserFieldDisabled}); err != nil {
+			return 0, err
+		}
+		defer func() {
+			if err != nil {
+				if enableErr := uc.userRepo.UpdateFields(context.WithoutCancel(ctx), user, []repositories.UserField{repositories.UserFieldDisabled}); enableErr != nil {
+					err = errors.Join(err, enableErr)
+				}
+			}
+		}()
+	}
+	err = uc.unitOfWork.Run(ctx, deleteUser)
+	if errors.Is(err, repositories.ErrTransactionsUnsupported) {
+		// Without transactions, such as on a standalone MongoDB server, the
+		// steps run one after another. The account goes last, so a deletion
+		// that fails part way leaves the user in place to be deleted again,
+		// and that finishes the job.
+		err = deleteUser(ctx)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// ownedTasks returns every task the user owns, live or in the trash.
+func (uc *userAdminUsecase) ownedTasks(ctx context.Context, userID primitive.ObjectID) ([]domain.Task, error) {
+	tasks, err := uc.taskRepo.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := uc.taskRepo.ListTrash(ctx, repositories.TrashQuery{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return append(tasks, trashed...), nil
+}
+
+// reassignTasks gives the tasks to target, along with any tags they use that
+// target does not have yet. An external ID target already uses is dropped,
+// since external IDs are unique per owner.
+func (uc *userAdminUsecase) reassignTasks(ctx context.Context, tasks []domain.Task, from, target *domain.User, actorID primitive.ObjectID) error {
+	if err := uc.copyTags(ctx, from.ID, target.ID); err != nil {
+		return err
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		if task.ExternalID != "" {
+			_, err := uc.taskRepo.GetByExternalID(ctx, target.ID, task.ExternalID)
+			if err == nil {
+				task.ExternalID = ""
+			} else if !errors.Is(err, mongo.ErrNoDocuments) {
+				return err
+			}
+		}
+		task.UserID = target.ID
+		if err := uc.taskRepo.Update(ctx, task); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Append(ctx, &domain.AuditEntry{
+			EntityType: domain.AuditEntityTask,
+			EntityID:   task.ID,
+			Action:     domain.AuditActionUpdate,
+			ActorID:    actorID,
+			Timestamp:  time.Now().UTC().Truncate(time.Millisecond),
+			Changes:    []domain.FieldChange{{Field: "user_id", Before: from.ID.Hex(), After: target.ID.Hex()}},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyTags creates the tags of one user that another does not have by name.
+func (uc *userAdminUsecase) copyTags(ctx context.Context, fromID, toID primitive.ObjectID) error {
+	tags, err := uc.tagRepo.ListByUserID(ctx, fromID)
+	if err != nil {
+		return err
+	}
+	existing, err := uc.tagRepo.ListByUserID(ctx, toID)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if slices.ContainsFunc(existing, func(t domain.Tag) bool { return t.Name == tag.Name }) {
+			continue
+		}
+		if err := uc.tagRepo.Create(ctx, &domain.Tag{UserID: toID, Name: tag.Name, Color: tag.Color, CreatedAt: tag.CreatedAt}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteTasks removes the tasks for good, auditing each one as a purge, and
+// unlinks other users' tasks from them.
+func (uc *userAdminUsecase) deleteTasks(ctx context.Context, tasks []domain.Task, actorID primitive.ObjectID) error {
+	ids := make([]primitive.ObjectID, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+		if err := uc.taskRepo.Delete(ctx, tasks[i].ID); err != nil {
+			return err
+		}
+		if err := uc.auditRepo.Append(ctx, newTaskAuditEntry(domain.AuditActionPurge, &tasks[i], nil, actorID)); err != nil {
+			return err
+		}
+	}
+	return uc.taskRepo.RemoveTaskLinks(ctx, ids)
+}
+
+// leaveProjects takes the user out of every project. With a target, the
+// target takes their place, keeping the higher of the two roles. Without
+// one, a project left with members but no owner has its first remaining
+// member made owner.
+func (uc *userAdminUsecase) leaveProjects(ctx context.Context, userID primitive.ObjectID, target *domain.User) error {
+	projects, err := uc.projectRepo.ListByMember(ctx, userID, true)
+	if err != nil {
+		return err
+	}
+	for i := range projects {
+		project := &projects[i]
+		var role string
+		project.Members = slices.DeleteFunc(project.Members, func(m domain.ProjectMember) bool {
+			if m.UserID == userID {
+				role = m.Role
+				return true
+			}
+			return false
+		})
+		if target != nil {
+			uc.addProjectMember(project, target.ID, role)
+		} else if len(project.Members) > 0 && checkHasOwner(project.Members) != nil {
+			project.Members[0].Role = domain.ProjectOwner
+		}
+		if err := uc.projectRepo.Update(ctx, project); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// projectRoles lists the project roles from the one granting least.
+var projectRoles = []string{domain.ProjectViewer, domain.ProjectEditor, domain.ProjectOwner}
+
+// addProjectMember gives the user the role in the project, unless they
+// already have a role that grants more.
+func (uc *userAdminUsecase) addProjectMember(project *domain.Project, userID primitive.ObjectID, role string) {
+	for i, m := range project.Members {
+		if m.UserID == userID {
+			if slices.Index(projectRoles, role) > slices.Index(projectRoles, m.Role) {
+				project.Members[i].Role = role
+			}
+			return
+		}
+	}
+	project.Members = append(project.Members, domain.ProjectMember{UserID: userID, Role: role})
+}
+
+// deleteBelongings deletes the user's tags, webhooks and calendar feeds.
+func (uc *userAdminUsecase) deleteBelongings(ctx context.Context, userID primitive.ObjectID) error {
+	tags, err := uc.tagRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := uc.tagRepo.Delete(ctx, tag.ID); err != nil {
+			return err
+		}
+	}
+	webhooks, err := uc.webhookRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, webhook := range webhooks {
+		if err := uc.webhookRepo.Delete(ctx, webhook.ID); err != nil {
+			return err
+		}
+	}
+	feeds, err := uc.feedRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, feed := range feeds {
+		if err := uc.feedRepo.Delete(ctx, feed.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
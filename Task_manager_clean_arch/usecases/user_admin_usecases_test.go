@@ -0,0 +1,363 @@
+package usecases
+
+import (
+	"context"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/repositories"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestUserAdmin_ListUsers(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	_, err := admin.DisableUser(ctx, f.user.Hex(), f.admin)
+	require.NoError(t, err)
+
+	page, cursor, err := admin.ListUsers(ctx, repositories.UserQuery{Limit: 2})
+	require.NoError(t, err)
+	rest, _, err := admin.ListUsers(ctx, repositories.UserQuery{Cursor: cursor})
+	require.NoError(t, err)
+	searched, _, err := admin.ListUsers(ctx, repositories.UserQuery{Search: " MAN "})
+	require.NoError(t, err)
+	disabled, _, err := admin.ListUsers(ctx, repositories.UserQuery{Status: repositories.UserStatusDisabled})
+	require.NoError(t, err)
+	_, _, statusErr := admin.ListUsers(ctx, repositories.UserQuery{Status: "asleep"})
+	_, _, cursorErr := admin.ListUsers(ctx, repositories.UserQuery{Cursor: "%%"})
+
+	// --- ASSERT ---
+	require.Len(t, page, 2)
+	assert.Equal(t, domain.RoleAdmin, page[0].Username)
+	assert.Equal(t, domain.RoleManager, page[1].Username)
+	require.Len(t, rest, 1)
+	assert.Equal(t, domain.RoleUser, rest[0].Username)
+	require.Len(t, searched, 1)
+	assert.Equal(t, f.manager, searched[0].ID)
+	require.Len(t, disabled, 1)
+	assert.Equal(t, f.user, disabled[0].ID)
+	assert.ErrorIs(t, statusErr, ErrInvalidUserQuery)
+	assert.ErrorIs(t, cursorErr, ErrInvalidUserQuery)
+}
+
+func TestUserAdmin_DisableAndEnable(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	f.setPassword(t, f.user, "pw")
+	users := NewUserUsecase(f.repos.Users, f.repos.Tokens, newFakePasswordService(), nil)
+
+	disabled, err := admin.DisableUser(ctx, f.user.Hex(), f.admin)
+	require.NoError(t, err)
+	activeWhileDisabled, err := admin.IsAccountActive(ctx, f.user)
+	require.NoError(t, err)
+	_, loginErr := users.Login(ctx, domain.RoleUser, "pw", "")
+	enabled, err := admin.EnableUser(ctx, f.user.Hex(), f.admin)
+	require.NoError(t, err)
+	activeAgain, err := admin.IsAccountActive(ctx, f.user)
+	require.NoError(t, err)
+	activeStranger, err := admin.IsAccountActive(ctx, primitive.NewObjectID())
+	require.NoError(t, err)
+	_, selfErr := admin.DisableUser(ctx, f.admin.Hex(), f.admin)
+	_, missingErr := admin.DisableUser(ctx, "not-an-id", f.admin)
+	history, _, err := f.repos.Audit.List(ctx, repositories.AuditQuery{EntityType: domain.AuditEntityUser, EntityID: f.user, Limit: 10})
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.False(t, disabled.DisabledAt.IsZero())
+	assert.False(t, activeWhileDisabled)
+	assert.EqualError(t, loginErr, "invalid username or password")
+	assert.True(t, enabled.DisabledAt.IsZero())
+	assert.True(t, activeAgain)
+	assert.False(t, activeStranger, "a deleted user is not active")
+	assert.ErrorIs(t, selfErr, ErrForbidden)
+	assert.ErrorIs(t, missingErr, ErrUserNotFound)
+	require.Len(t, history, 2)
+	assert.Equal(t, []domain.FieldChange{{Field: "disabled", Before: "true", After: "false"}}, history[0].Changes)
+}
+
+func TestUserAdmin_CannotManageUsersWhoCanDoMore(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	_, err := f.roles.CreateRole(ctx, &domain.Role{Name: "support", Permissions: []string{domain.PermUserManage}}, f.admin)
+	require.NoError(t, err)
+	_, err = f.roles.SetUserRoles(ctx, f.user.Hex(), []string{"support"}, f.admin)
+	require.NoError(t, err)
+
+	_, resetAdminErr := admin.ResetPassword(ctx, f.admin.Hex(), f.user)
+	_, disableManagerErr := admin.DisableUser(ctx, f.manager.Hex(), f.user)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, resetAdminErr, ErrForbidden)
+	assert.ErrorIs(t, disableManagerErr, ErrForbidden, "the manager holds task permissions support does not")
+}
+
+func TestUserAdmin_PromoteNeedsEveryPermission(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	_, err := f.roles.CreateRole(ctx, &domain.Role{Name: "promoter", Permissions: []string{domain.PermUserPromote}}, f.admin)
+	require.NoError(t, err)
+	_, err = f.roles.SetUserRoles(ctx, f.user.Hex(), []string{"promoter"}, f.admin)
+	require.NoError(t, err)
+
+	_, selfErr := admin.PromoteUser(ctx, f.user.Hex(), f.user)
+	_, otherErr := admin.PromoteUser(ctx, f.manager.Hex(), f.user)
+	promoted, err := admin.PromoteUser(ctx, f.manager.Hex(), f.admin)
+	require.NoError(t, err)
+	again, againErr := admin.PromoteUser(ctx, f.manager.Hex(), f.admin)
+	_, missingErr := admin.PromoteUser(ctx, primitive.NewObjectID().Hex(), f.admin)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, selfErr, ErrForbidden, "user:promote alone cannot make its holder an admin")
+	assert.ErrorIs(t, otherErr, ErrForbidden)
+	assert.Equal(t, []string{domain.RoleManager, domain.RoleAdmin}, promoted.Roles)
+	assert.NoError(t, againErr)
+	assert.Equal(t, promoted.Roles, again.Roles)
+	assert.ErrorIs(t, missingErr, ErrUserNotFound)
+	history, _, err := f.repos.Audit.List(ctx, repositories.AuditQuery{EntityType: domain.AuditEntityUser, EntityID: f.manager, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "manager,admin", history[0].Changes[0].After)
+}
+
+func TestUserAdmin_LastAdminIsKept(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	// A manager who holds every permission, without being an admin.
+	_, err := f.roles.CreateRole(ctx, &domain.Role{Name: "deputy", Permissions: KnownPermissions()}, f.admin)
+	require.NoError(t, err)
+	_, err = f.roles.SetUserRoles(ctx, f.manager.Hex(), []string{domain.RoleManager, "deputy"}, f.admin)
+	require.NoError(t, err)
+
+	_, demoteErr := admin.DemoteUser(ctx, f.admin.Hex(), f.manager)
+	_, disableErr := admin.DisableUser(ctx, f.admin.Hex(), f.manager)
+	_, deleteErr := admin.DeleteUser(ctx, f.admin.Hex(), "", f.manager)
+	_, rolesErr := f.roles.SetUserRoles(ctx, f.admin.Hex(), []string{domain.RoleUser}, f.manager)
+	_, err = f.roles.SetUserRoles(ctx, f.user.Hex(), []string{domain.RoleAdmin}, f.admin)
+	require.NoError(t, err)
+	demoted, demoteAgainErr := admin.DemoteUser(ctx, f.admin.Hex(), f.manager)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, demoteErr, ErrLastAdmin)
+	assert.ErrorIs(t, disableErr, ErrLastAdmin)
+	assert.ErrorIs(t, deleteErr, ErrLastAdmin)
+	assert.ErrorIs(t, rolesErr, ErrLastAdmin)
+	require.NoError(t, demoteAgainErr, "another admin is left")
+	assert.Equal(t, []string{domain.RoleUser}, demoted.Roles)
+	_, err = admin.DisableUser(ctx, f.user.Hex(), f.manager)
+	assert.ErrorIs(t, err, ErrLastAdmin, "the new admin is the last one now")
+}
+
+func TestUserAdmin_AdminsRemovingEachOtherKeepOne(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	_, err := f.roles.SetUserRoles(ctx, f.user.Hex(), []string{domain.RoleAdmin}, f.admin)
+	require.NoError(t, err)
+
+	// The other admin demotes f.admin between this demotion's check and its
+	// write.
+	users := &racingUserRepository{IUserRepository: f.repos.Users, afterList: func() {
+		_, err := f.newUserAdminUsecase().DemoteUser(ctx, f.admin.Hex(), f.user)
+		require.NoError(t, err)
+	}}
+	repos := *f.repos
+	repos.Users = users
+	racing := NewUserAdminUsecase(&repos, infrastructure.NewPasswordService(), f.roles)
+	_, err = racing.DemoteUser(ctx, f.user.Hex(), f.admin)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrLastAdmin)
+	admins, _, err := f.repos.Users.List(ctx, repositories.UserQuery{Role: domain.RoleAdmin, Status: repositories.UserStatusActive})
+	require.NoError(t, err)
+	require.Len(t, admins, 1)
+	assert.Equal(t, f.user, admins[0].ID)
+}
+
+// seedOwnedTasks gives the user a tagged live task, a trashed task and a
+// project shared with the manager.
+func seedOwnedTasks(t *testing.T, f *fixture) (live, trashed domain.Task, project domain.Project) {
+	ctx := context.Background()
+	require.NoError(t, f.repos.Tags.Create(ctx, &domain.Tag{UserID: f.user, Name: "ops", Color: "#ff0000", CreatedAt: time.Now()}))
+	project = domain.Project{Name: "Launch", Members: []domain.ProjectMember{
+		{UserID: f.user, Role: domain.ProjectOwner},
+		{UserID: f.manager, Role: domain.ProjectViewer},
+	}}
+	require.NoError(t, f.repos.Projects.Create(ctx, &project))
+	live = domain.Task{Title: "Deploy", Status: StatusPending, UserID: f.user, Tags: []string{"ops"}, ExternalID: "jira-1", ProjectID: project.ID}
+	require.NoError(t, f.repos.Tasks.Create(ctx, &live))
+	trashed = domain.Task{Title: "Old", Status: StatusPending, UserID: f.user, DeletedAt: time.Now(), DeletedBy: f.user}
+	require.NoError(t, f.repos.Tasks.Create(ctx, &trashed))
+	require.NoError(t, f.repos.Webhooks.Create(ctx, &domain.Webhook{UserID: f.user, URL: "https://example.com/hook", Secret: "s"}))
+	return live, trashed, project
+}
+
+func TestUserAdmin_DeleteUserReassignsTasks(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	live, trashed, project := seedOwnedTasks(t, f)
+	// The manager already imported a task with the same external ID.
+	require.NoError(t, f.repos.Tasks.Create(ctx, &domain.Task{Title: "Mine", Status: StatusPending, UserID: f.manager, ExternalID: "jira-1"}))
+
+	_, selfErr := admin.DeleteUser(ctx, f.user.Hex(), f.user.Hex(), f.admin)
+	_, unknownErr := admin.DeleteUser(ctx, f.user.Hex(), primitive.NewObjectID().Hex(), f.admin)
+	moved, err := admin.DeleteUser(ctx, f.user.Hex(), f.manager.Hex(), f.admin)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, selfErr, ErrInvalidReassignment)
+	assert.ErrorIs(t, unknownErr, ErrInvalidReassignment)
+	assert.Equal(t, 2, moved)
+	_, err = f.repos.Users.FindByID(ctx, f.user)
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	reassigned, err := f.repos.Tasks.GetByID(ctx, live.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.manager, reassigned.UserID)
+	assert.Empty(t, reassigned.ExternalID, "the manager's task keeps the external ID")
+	assert.Equal(t, live.Version+1, reassigned.Version)
+	stillTrashed, err := f.repos.Tasks.GetTrashedByID(ctx, trashed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.manager, stillTrashed.UserID)
+	managerTags, err := f.repos.Tags.ListByUserID(ctx, f.manager)
+	require.NoError(t, err)
+	require.Len(t, managerTags, 1)
+	assert.Equal(t, "#ff0000", managerTags[0].Color)
+	userTags, err := f.repos.Tags.ListByUserID(ctx, f.user)
+	require.NoError(t, err)
+	assert.Empty(t, userTags)
+	webhooks, err := f.repos.Webhooks.ListByUserID(ctx, f.user)
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+	updatedProject, err := f.repos.Projects.GetByID(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ProjectMember{{UserID: f.manager, Role: domain.ProjectOwner}}, updatedProject.Members)
+}
+
+func TestUserAdmin_DeleteUserRemovesTasks(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	live, trashed, project := seedOwnedTasks(t, f)
+
+	deleted, err := admin.DeleteUser(ctx, f.user.Hex(), "", f.admin)
+	require.NoError(t, err)
+	purges, _, err := f.repos.Audit.List(ctx, repositories.AuditQuery{EntityType: domain.AuditEntityTask, Action: domain.AuditActionPurge, Limit: 10})
+	require.NoError(t, err)
+	deletions, _, err := f.repos.Audit.List(ctx, repositories.AuditQuery{EntityType: domain.AuditEntityUser, Action: domain.AuditActionDelete, Limit: 10})
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.Equal(t, 2, deleted)
+	_, err = f.repos.Tasks.GetByID(ctx, live.ID)
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	_, err = f.repos.Tasks.GetTrashedByID(ctx, trashed.ID)
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	assert.Len(t, purges, 2)
+	require.Len(t, deletions, 1)
+	assert.Equal(t, f.user, deletions[0].EntityID)
+	assert.Equal(t, f.admin, deletions[0].ActorID)
+	updatedProject, err := f.repos.Projects.GetByID(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ProjectMember{{UserID: f.manager, Role: domain.ProjectOwner}}, updatedProject.Members, "the project is not left without an owner")
+}
+
+func TestUserAdmin_DeleteUserClearsReferencesFromOtherTasks(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	live, _, _ := seedOwnedTasks(t, f)
+	// The manager's task is assigned to and shared with the user, sits under
+	// the user's task and waits on it.
+	theirs := domain.Task{
+		Title:         "Announce",
+		Status:        StatusPending,
+		UserID:        f.manager,
+		ParentID:      live.ID,
+		BlockedBy:     []primitive.ObjectID{live.ID},
+		AssigneeID:    f.user,
+		Collaborators: []domain.Collaborator{{UserID: f.user, Role: domain.CollaboratorEditor}},
+	}
+	require.NoError(t, f.repos.Tasks.Create(ctx, &theirs))
+
+	_, err := admin.DeleteUser(ctx, f.user.Hex(), "", f.admin)
+	require.NoError(t, err)
+	found, err := f.repos.Tasks.GetByID(ctx, theirs.ID)
+	require.NoError(t, err)
+	assigned, _, err := f.repos.Tasks.ListTasks(ctx, repositories.TaskQuery{UserID: f.user, Limit: 10})
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.True(t, found.AssigneeID.IsZero())
+	assert.Empty(t, found.Collaborators)
+	assert.True(t, found.ParentID.IsZero())
+	assert.Empty(t, found.BlockedBy)
+	assert.Empty(t, assigned)
+}
+
+// noTransactions is the unit of work of a database without transactions,
+// such as a standalone MongoDB server.
+type noTransactions struct{}
+
+func (noTransactions) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	return repositories.ErrTransactionsUnsupported
+}
+
+func TestUserAdmin_DeleteUserWithoutTransactions(t *testing.T) {
+	f := newFixture(t)
+	f.repos.UnitOfWork = noTransactions{}
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	live, trashed, _ := seedOwnedTasks(t, f)
+
+	moved, err := admin.DeleteUser(ctx, f.user.Hex(), f.manager.Hex(), f.admin)
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+	_, err = f.repos.Users.FindByID(ctx, f.user)
+	assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	reassigned, err := f.repos.Tasks.GetByID(ctx, live.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.manager, reassigned.UserID)
+	stillTrashed, err := f.repos.Tasks.GetTrashedByID(ctx, trashed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.manager, stillTrashed.UserID)
+}
+
+func TestUserAdmin_ResetPassword(t *testing.T) {
+	f := newFixture(t)
+	admin := f.newUserAdminUsecase()
+	ctx := context.Background()
+	session := &domain.RefreshToken{UserID: f.user, FamilyID: primitive.NewObjectID(), TokenHash: "session", AccessTokenID: "jti",
+		AccessExpiresAt: time.Now().Add(time.Minute), ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now()}
+	require.NoError(t, f.repos.Tokens.CreateRefreshToken(ctx, session))
+
+	password, err := admin.ResetPassword(ctx, f.user.Hex(), f.admin)
+	require.NoError(t, err)
+	stored, err := f.repos.Users.FindByID(ctx, f.user)
+	require.NoError(t, err)
+	refresh, err := f.repos.Tokens.FindRefreshTokenByHash(ctx, "session")
+	require.NoError(t, err)
+	accessRevoked, err := f.repos.Tokens.IsAccessTokenRevoked(ctx, "jti")
+	require.NoError(t, err)
+	history, _, err := f.repos.Audit.List(ctx, repositories.AuditQuery{EntityID: f.user, Limit: 10})
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.Len(t, password, 43)
+	assert.True(t, infrastructure.NewPasswordService().CheckPasswordHash(password, stored.Password))
+	require.Len(t, history, 1)
+	assert.Equal(t, "password", history[0].Changes[0].Field)
+	assert.NotContains(t, history[0].Changes[0].After, stored.Password)
+	assert.True(t, refresh.Revoked, "the user's sessions end")
+	assert.True(t, accessRevoked)
+}
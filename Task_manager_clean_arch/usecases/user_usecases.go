@@ -3,32 +3,138 @@ package usecases
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
 	"taskmanager/domain"
 	"taskmanager/infrastructure"
 	"taskmanager/repositories"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// RefreshTokenTTL bounds how long a session can be kept alive without the
+// user logging in again.
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+// PasswordResetTokenTTL is how long a mailed password reset token works.
+const PasswordResetTokenTTL = time.Hour
+
+// dummyPasswordHash is checked in place of the hash of an unknown username,
+// so refusing it takes as long as refusing a wrong password. It is a bcrypt
+// hash at the cost the password service uses, of no password anyone sends.
+const dummyPasswordHash = "$2a$14$7nFWux/POdsdHFvxbYKAP.KLXBJA.ruusx8aZXHo5xe91KAtoskNi"
+
+var (
+	// ErrInvalidCredentials is returned for a failed login, whatever the
+	// reason, so the response does not reveal which usernames exist.
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens.
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when an already rotated refresh token
+	// is presented again. The whole token family is revoked when it happens.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected; session revoked")
+	// ErrWrongPassword is returned when a password change is made without
+	// the right current password.
+	ErrWrongPassword = errors.New("current password is incorrect")
+	// ErrInvalidPassword is returned for a new password that cannot be used.
+	ErrInvalidPassword = errors.New("invalid password")
+	// ErrInvalidResetToken is returned for unknown, expired or spent
+	// password reset tokens.
+	ErrInvalidResetToken = errors.New("invalid or expired password reset token")
+)
+
+// TokenPair is what a successful login or refresh hands back to the client.
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
 type IUserUsecase interface {
 	Register(ctx context.Context, username, password string) (*domain.User, error)
-	Login(ctx context.Context, username, password string) (string, error)
-	Promote(ctx context.Context, userID string) (*domain.User, error)
+	// Login fails with a *LoginThrottledError while failed logins for the
+	// username or from clientIP must wait. clientIP may be empty.
+	Login(ctx context.Context, username, password, clientIP string) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+	// ChangePassword sets a new password after checking the current one.
+	// Every session of the user ends, so a new token pair is returned to
+	// keep the caller logged in. Wrong current passwords count as failed
+	// logins, and the change fails with a *LoginThrottledError while logins
+	// for the user or from clientIP must wait.
+	ChangePassword(ctx context.Context, userID primitive.ObjectID, currentPassword, newPassword, clientIP string) (*TokenPair, error)
+	// RequestPasswordReset mails a reset token to the user. It succeeds, and
+	// takes as long, whether or not the user exists, so callers cannot probe
+	// for usernames. It fails with a *ResetThrottledError while requests for
+	// the username or from clientIP must wait. clientIP may be empty.
+	RequestPasswordReset(ctx context.Context, username, clientIP string) error
+	// ConfirmPasswordReset spends a reset token to set a new password and
+	// ends every session of the user.
+	ConfirmPasswordReset(ctx context.Context, token, newPassword string) error
 }
 
 type userUsecase struct {
 	userRepo        repositories.IUserRepository
+	tokenRepo       repositories.ITokenRepository
+	auditRepo       repositories.IAuditRepository
 	passwordService infrastructure.IPasswordService
 	jwtService      infrastructure.IJWTService
+	mailer          infrastructure.IMailer
+	loginAttempts   repositories.ILoginAttemptRepository
+	loginPolicy     LoginPolicy
+	resetAttempts   repositories.ILoginAttemptRepository
+	resetPolicy     PasswordResetPolicy
+	background      *sync.WaitGroup
+	// clock is when logins, password changes and reset requests are
+	// counted as made.
+	clock func() time.Time
+}
+
+// UserOption configures the user usecase.
+type UserOption func(*userUsecase)
+
+// WithMailer sets how password reset tokens reach users. The default
+// writes them to the standard logger.
+func WithMailer(mailer infrastructure.IMailer) UserOption {
+	return func(uc *userUsecase) { uc.mailer = mailer }
+}
+
+// WithBackgroundWork counts the mail RequestPasswordReset sends after it
+// has answered in background, so shutdown can wait for it.
+func WithBackgroundWork(background *sync.WaitGroup) UserOption {
+	return func(uc *userUsecase) { uc.background = background }
+}
+
+// WithUserClock sets when logins, password changes and reset requests are
+// counted as made. The default is time.Now.
+func WithUserClock(clock func() time.Time) UserOption {
+	return func(uc *userUsecase) { uc.clock = clock }
+}
+
+// WithUserAuditLog records password changes and lockouts in the audit log.
+func WithUserAuditLog(auditRepo repositories.IAuditRepository) UserOption {
+	return func(uc *userUsecase) { uc.auditRepo = auditRepo }
 }
 
-func NewUserUsecase(repo repositories.IUserRepository, ps infrastructure.IPasswordService, js infrastructure.IJWTService) IUserUsecase {
-	return &userUsecase{
+func NewUserUsecase(repo repositories.IUserRepository, tokenRepo repositories.ITokenRepository, ps infrastructure.IPasswordService, js infrastructure.IJWTService, opts ...UserOption) IUserUsecase {
+	uc := &userUsecase{
 		userRepo:        repo,
+		tokenRepo:       tokenRepo,
 		passwordService: ps,
 		jwtService:      js,
+		mailer:          infrastructure.NewLogMailer(nil),
+		background:      &sync.WaitGroup{},
+		clock:           time.Now,
 	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
 }
 
 func (uc *userUsecase) Register(ctx context.Context, username, password string) (*domain.User, error) {
@@ -49,15 +155,15 @@ func (uc *userUsecase) Register(ctx context.Context, username, password string)
 	if err != nil {
 		return nil, err
 	}
-	role := "user"
+	role := domain.RoleUser
 	if userCount == 0 {
-		role = "admin"
+		role = domain.RoleAdmin
 	}
 
 	user := &domain.User{
 		Username: username,
 		Password: hashedPassword,
-		Role:     role,
+		Roles:    []string{role},
 	}
 
 	err = uc.userRepo.Create(ctx, user)
@@ -68,36 +174,345 @@ func (uc *userUsecase) Register(ctx context.Context, username, password string)
 	return user, nil
 }
 
-func (uc *userUsecase) Login(ctx context.Context, username, password string) (string, error) {
+func (uc *userUsecase) Login(ctx context.Context, username, password, clientIP string) (*TokenPair, error) {
+	now := uc.clock().UTC()
+	counters := uc.loginCounters(username, clientIP)
+	// A throttled attempt is refused before the password is checked, so
+	// guesses made while waiting tell nothing.
+	if err := uc.checkLoginAllowed(ctx, counters, now); err != nil {
+		return nil, err
+	}
+
+	// Unknown usernames fail, and are counted, like wrong passwords. Any
+	// other error is not the client's fault and counts for nothing.
 	user, err := uc.userRepo.FindByUsername(ctx, username)
+	hash := dummyPasswordHash
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		user = nil
+	case err != nil:
+		return nil, err
+	default:
+		hash = user.Password
+	}
+	failures, err := uc.reserveLoginAttempt(ctx, counters, now)
+	if err != nil {
+		return nil, err
+	}
+	// A disabled account looks like a wrong password, so the response does
+	// not reveal that the password was right.
+	if !uc.passwordService.CheckPasswordHash(password, hash) || user == nil || !user.DisabledAt.IsZero() {
+		if err := uc.recordLoginFailure(ctx, counters, failures, user, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+	if err := uc.forgetLoginAttempt(ctx, counters); err != nil {
+		return nil, err
+	}
+
+	// Every login starts a new refresh token family.
+	return uc.issueTokens(ctx, user, primitive.NewObjectID())
+}
+
+// Refresh rotates a refresh token: the presented token is spent and a new
+// access/refresh pair from the same family is returned. Presenting a token
+// that was already spent means it leaked, so the family is revoked.
+func (uc *userUsecase) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	stored, err := uc.tokenRepo.FindRefreshTokenByHash(ctx, infrastructure.HashToken(refreshToken))
 	if err != nil {
-		return "", errors.New("invalid username or password")
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	if stored.Revoked {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !stored.UsedAt.IsZero() {
+		if err := uc.revokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Losing this race to a concurrent refresh is treated as reuse as well.
+	marked, err := uc.tokenRepo.MarkRefreshTokenUsed(ctx, stored.ID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		if err := uc.revokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRefreshTokenReused
 	}
 
-	if !uc.passwordService.CheckPasswordHash(password, user.Password) {
-		return "", errors.New("invalid username or password")
+	user, err := uc.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil || !user.DisabledAt.IsZero() {
+		return nil, ErrInvalidRefreshToken
 	}
+	return uc.issueTokens(ctx, user, stored.FamilyID)
+}
 
-	return uc.jwtService.GenerateToken(*user)
+// Logout revokes the refresh token's family and every access token issued
+// from it.
+func (uc *userUsecase) Logout(ctx context.Context, refreshToken string) error {
+	stored, err := uc.tokenRepo.FindRefreshTokenByHash(ctx, infrastructure.HashToken(refreshToken))
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+	return uc.revokeFamily(ctx, stored.FamilyID)
 }
 
-func (uc *userUsecase) Promote(ctx context.Context, userID string) (*domain.User, error) {
-	objectID, err := primitive.ObjectIDFromHex(userID)
+// issueTokens mints an access token and a refresh token in the given family
+// and stores the refresh token's hash.
+func (uc *userUsecase) issueTokens(ctx context.Context, user *domain.User, familyID primitive.ObjectID) (*TokenPair, error) {
+	accessToken, err := uc.jwtService.GenerateToken(*user)
 	if err != nil {
-		return nil, errors.New("invalid user ID format")
+		return nil, err
+	}
+	refreshToken, err := infrastructure.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	stored := &domain.RefreshToken{
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       infrastructure.HashToken(refreshToken),
+		AccessTokenID:   accessToken.ID,
+		AccessExpiresAt: accessToken.ExpiresAt,
+		ExpiresAt:       now.Add(RefreshTokenTTL),
+		CreatedAt:       now,
+	}
+	if err := uc.tokenRepo.CreateRefreshToken(ctx, stored); err != nil {
+		return nil, err
 	}
 
-	user, err := uc.userRepo.FindByID(ctx, objectID)
+	return &TokenPair{
+		AccessToken:      accessToken.Token,
+		AccessExpiresAt:  accessToken.ExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// revokeFamily revokes every refresh token in a family along with the
+// access tokens that were issued with them and have not yet expired.
+func (uc *userUsecase) revokeFamily(ctx context.Context, familyID primitive.ObjectID) error {
+	tokens, err := uc.tokenRepo.FindRefreshTokenFamily(ctx, familyID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return err
 	}
+	if err := uc.tokenRepo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return revokeAccessTokens(ctx, uc.tokenRepo, tokens)
+}
 
-	user.Role = "admin"
+// revokeAccessTokens revokes the access tokens issued with the given
+// refresh tokens that have not yet expired.
+func revokeAccessTokens(ctx context.Context, tokenRepo repositories.ITokenRepository, tokens []domain.RefreshToken) error {
+	now := time.Now()
+	for _, token := range tokens {
+		if token.AccessTokenID == "" || token.AccessExpiresAt.Before(now) {
+			continue
+		}
+		if err := tokenRepo.RevokeAccessToken(ctx, token.AccessTokenID, token.AccessExpiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	err = uc.userRepo.Update(ctx, user)
+// revokeUserSessions ends every session of a user once their password has
+// changed: each refresh token family is revoked with its access tokens,
+// and reset tokens that were mailed for the old password stop working.
+func revokeUserSessions(ctx context.Context, tokenRepo repositories.ITokenRepository, userID primitive.ObjectID) error {
+	tokens, err := tokenRepo.FindRefreshTokensByUser(ctx, userID)
 	if err != nil {
+		return err
+	}
+	// Revoked families already had their access tokens revoked.
+	tokens = slices.DeleteFunc(tokens, func(token domain.RefreshToken) bool { return token.Revoked })
+	revoked := make(map[primitive.ObjectID]bool)
+	for _, token := range tokens {
+		if revoked[token.FamilyID] {
+			continue
+		}
+		if err := tokenRepo.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+			return err
+		}
+		revoked[token.FamilyID] = true
+	}
+	if err := revokeAccessTokens(ctx, tokenRepo, tokens); err != nil {
+		return err
+	}
+	return tokenRepo.InvalidatePasswordResetTokens(ctx, userID, time.Now().UTC())
+}
+
+func (uc *userUsecase) ChangePassword(ctx context.Context, userID primitive.ObjectID, currentPassword, newPassword, clientIP string) (*TokenPair, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	// A stolen session must not get more guesses at the password than the
+	// login form gives, so the check shares the login counts.
+	now := uc.clock().UTC()
+	counters := uc.loginCounters(user.Username, clientIP)
+	if err := uc.checkLoginAllowed(ctx, counters, now); err != nil {
+		return nil, err
+	}
+	failures, err := uc.reserveLoginAttempt(ctx, counters, now)
+	if err != nil {
+		return nil, err
+	}
+	if !uc.passwordService.CheckPasswordHash(currentPassword, user.Password) {
+		if err := uc.recordLoginFailure(ctx, counters, failures, user, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrWrongPassword
+	}
+	if err := uc.forgetLoginAttempt(ctx, counters); err != nil {
+		return nil, err
+	}
+	if err := checkNewPassword(newPassword); err != nil {
 		return nil, err
 	}
+	if err := uc.setPassword(ctx, user, newPassword); err != nil {
+		return nil, err
+	}
+	return uc.issueTokens(ctx, user, primitive.NewObjectID())
+}
 
-	return user, nil
+func (uc *userUsecase) RequestPasswordReset(ctx context.Context, username, clientIP string) error {
+	now := uc.clock().UTC()
+	if err := uc.countResetRequest(ctx, username, clientIP, now); err != nil {
+		return err
+	}
+	user, err := uc.userRepo.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil
+		}
+		return err
+	}
+	// Disabled accounts get no mail, but the caller is not told so.
+	if !user.DisabledAt.IsZero() {
+		return nil
+	}
+
+	// The token is stored and mailed after the answer, so that the answer
+	// takes as long as for a username no one has.
+	ctx = context.WithoutCancel(ctx)
+	uc.background.Add(1)
+	go func() {
+		defer uc.background.Done()
+		if err := uc.sendPasswordReset(ctx, user, now); err != nil {
+			log.Printf("Failed to send a password reset to user %s: %v", user.ID.Hex(), err)
+		}
+	}()
+	return nil
+}
+
+// sendPasswordReset stores a new reset token for the user and mails it.
+func (uc *userUsecase) sendPasswordReset(ctx context.Context, user *domain.User, now time.Time) error {
+	token, err := infrastructure.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	stored := &domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: infrastructure.HashToken(token),
+		ExpiresAt: now.Add(PasswordResetTokenTTL),
+		CreatedAt: now,
+	}
+	if err := uc.tokenRepo.CreatePasswordResetToken(ctx, stored); err != nil {
+		return err
+	}
+	return uc.mailer.Send(ctx, infrastructure.Mail{
+		To:      user.Username,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of %s. If it was you, send this token with your new password "+
+			"to /auth/password-reset/confirm before %s:\n\n%s\n\nIf it was not you, ignore this message.",
+			user.Username, stored.ExpiresAt.Format(time.RFC1123), token),
+	})
+}
+
+func (uc *userUsecase) ConfirmPasswordReset(ctx context.Context, token, newPassword string) error {
+	stored, err := uc.tokenRepo.FindPasswordResetTokenByHash(ctx, infrastructure.HashToken(token))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !stored.UsedAt.IsZero() || time.Now().After(stored.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	user, err := uc.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if !user.DisabledAt.IsZero() {
+		return ErrInvalidResetToken
+	}
+	// A token is not spent on a password that would be refused.
+	if err := checkNewPassword(newPassword); err != nil {
+		return err
+	}
+
+	// Of two requests racing with the same token only one gets to spend it.
+	spent, err := uc.tokenRepo.MarkPasswordResetTokenUsed(ctx, stored.ID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !spent {
+		return ErrInvalidResetToken
+	}
+	return uc.setPassword(ctx, user, newPassword)
+}
+
+// checkNewPassword fails with ErrInvalidPassword for a password that cannot
+// be set.
+func checkNewPassword(password string) error {
+	if password == "" {
+		return fmt.Errorf("%w: the new password is empty", ErrInvalidPassword)
+	}
+	return nil
+}
+
+// setPassword stores the hash of the user's new password, ends their
+// sessions and records the change as made by the user.
+func (uc *userUsecase) setPassword(ctx context.Context, user *domain.User, password string) error {
+	hashed, err := uc.passwordService.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	user.Password = hashed
+	if err := uc.userRepo.UpdateFields(ctx, user, []repositories.UserField{repositories.UserFieldPassword}); err != nil {
+		return err
+	}
+	if err := revokeUserSessions(ctx, uc.tokenRepo, user.ID); err != nil {
+		return err
+	}
+	if uc.auditRepo == nil {
+		return nil
+	}
+	return uc.auditRepo.Append(ctx, newUserAuditEntry(domain.AuditActionUpdate, user.ID, user.ID,
+		domain.FieldChange{Field: "password", Before: "", After: "changed"}))
 }
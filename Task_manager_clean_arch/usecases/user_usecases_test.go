@@ -2,13 +2,23 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
 	"taskmanager/domain"
+	"taskmanager/infrastructure"
 	"taskmanager/mocks"
+	"taskmanager/repositories"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // TestRegister_Success_FirstUserIsAdmin tests  for registration
@@ -19,6 +29,7 @@ func TestRegister_Success_FirstUserIsAdmin(t *testing.T) {
 	mockUserRepo := new(mocks.IUserRepository)
 	mockPasswordSvc := new(mocks.IPasswordService)
 	mockJwtSvc := new(mocks.IJWTService)
+	mockTokenRepo := new(mocks.ITokenRepository)
 
 	// 2. Define the input we will pass to the function we are testing.
 	username := "adminuser"
@@ -34,16 +45,16 @@ func TestRegister_Success_FirstUserIsAdmin(t *testing.T) {
 	mockPasswordSvc.On("HashPassword", password).Return(hashedPassword, nil)
 
 	mockUserRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *domain.User) bool {
-		return user.Role == "admin" && user.Username == username && user.Password == hashedPassword
+		return slices.Equal(user.Roles, []string{"admin"}) && user.Username == username && user.Password == hashedPassword
 	})).Return(nil)
 
-	usecase := NewUserUsecase(mockUserRepo, mockPasswordSvc, mockJwtSvc)
+	usecase := NewUserUsecase(mockUserRepo, mockTokenRepo, mockPasswordSvc, mockJwtSvc)
 	createdUser, err := usecase.Register(context.Background(), username, password)
 
 	// Use testify's assertion library to make our checks clean and readable.
-	assert.NoError(t, err)                     // We assert that no error was returned.
-	assert.NotNil(t, createdUser)              // We assert that we got a user object back.
-	assert.Equal(t, "admin", createdUser.Role) // We assert that the user's role is "admin".
+	assert.NoError(t, err)                                // We assert that no error was returned.
+	assert.NotNil(t, createdUser)                         // We assert that we got a user object back.
+	assert.Equal(t, []string{"admin"}, createdUser.Roles) // We assert that the user's only role is "admin".
 	assert.Equal(t, username, createdUser.Username)
 
 	mockUserRepo.AssertExpectations(t)
@@ -55,13 +66,14 @@ func TestRegister_Failure_UsernameExists(t *testing.T) {
 	mockUserRepo := new(mocks.IUserRepository)
 	mockPasswordSvc := new(mocks.IPasswordService)
 	mockJwtSvc := new(mocks.IJWTService)
+	mockTokenRepo := new(mocks.ITokenRepository)
 
 	username := "existinguser"
 	password := "password123"
 
 	mockUserRepo.On("FindByUsername", mock.Anything, username).Return(&domain.User{}, nil)
 
-	usecase := NewUserUsecase(mockUserRepo, mockPasswordSvc, mockJwtSvc)
+	usecase := NewUserUsecase(mockUserRepo, mockTokenRepo, mockPasswordSvc, mockJwtSvc)
 	createdUser, err := usecase.Register(context.Background(), username, password)
 
 	// --- ASSERT ---
@@ -72,3 +84,318 @@ func TestRegister_Failure_UsernameExists(t *testing.T) {
 	mockPasswordSvc.AssertNotCalled(t, "HashPassword", mock.Anything)
 	mockUserRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
+
+// TestRefresh_Failure_ReusedTokenRevokesFamily replays a refresh token that was
+// already rotated and expects the whole family and its access tokens revoked.
+func TestRefresh_Failure_ReusedTokenRevokesFamily(t *testing.T) {
+	mockUserRepo := new(mocks.IUserRepository)
+	mockPasswordSvc := new(mocks.IPasswordService)
+	mockJwtSvc := new(mocks.IJWTService)
+	mockTokenRepo := new(mocks.ITokenRepository)
+
+	familyID := primitive.NewObjectID()
+	stale := &domain.RefreshToken{
+		ID:        primitive.NewObjectID(),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(time.Hour),
+		UsedAt:    time.Now().Add(-time.Minute),
+	}
+	liveAccessExpiry := time.Now().Add(10 * time.Minute)
+	family := []domain.RefreshToken{
+		*stale,
+		{FamilyID: familyID, AccessTokenID: "live-jti", AccessExpiresAt: liveAccessExpiry},
+		{FamilyID: familyID, AccessTokenID: "expired-jti", AccessExpiresAt: time.Now().Add(-time.Minute)},
+	}
+
+	mockTokenRepo.On("FindRefreshTokenByHash", mock.Anything, infrastructure.HashToken("stale-token")).Return(stale, nil)
+	mockTokenRepo.On("FindRefreshTokenFamily", mock.Anything, familyID).Return(family, nil)
+	mockTokenRepo.On("RevokeRefreshTokenFamily", mock.Anything, familyID).Return(nil)
+	mockTokenRepo.On("RevokeAccessToken", mock.Anything, "live-jti", liveAccessExpiry).Return(nil)
+
+	usecase := NewUserUsecase(mockUserRepo, mockTokenRepo, mockPasswordSvc, mockJwtSvc)
+	tokens, err := usecase.Refresh(context.Background(), "stale-token")
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Nil(t, tokens)
+	mockTokenRepo.AssertExpectations(t)
+	mockTokenRepo.AssertNotCalled(t, "RevokeAccessToken", mock.Anything, "expired-jti", mock.Anything)
+	mockTokenRepo.AssertNotCalled(t, "MarkRefreshTokenUsed", mock.Anything, mock.Anything, mock.Anything)
+	mockJwtSvc.AssertNotCalled(t, "GenerateToken", mock.Anything)
+}
+
+// TestRefresh_Success_RotatesToken spends the presented token and issues a new
+// pair in the same family.
+func TestRefresh_Success_RotatesToken(t *testing.T) {
+	mockUserRepo := new(mocks.IUserRepository)
+	mockPasswordSvc := new(mocks.IPasswordService)
+	mockJwtSvc := new(mocks.IJWTService)
+	mockTokenRepo := new(mocks.ITokenRepository)
+
+	user := &domain.User{ID: primitive.NewObjectID(), Username: "someone", Roles: []string{"user"}}
+	current := &domain.RefreshToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    user.ID,
+		FamilyID:  primitive.NewObjectID(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	access := &infrastructure.AccessToken{Token: "signed.jwt", ID: "new-jti", ExpiresAt: time.Now().Add(15 * time.Minute)}
+
+	mockTokenRepo.On("FindRefreshTokenByHash", mock.Anything, infrastructure.HashToken("current-token")).Return(current, nil)
+	mockTokenRepo.On("MarkRefreshTokenUsed", mock.Anything, current.ID, mock.Anything).Return(true, nil)
+	mockUserRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil)
+	mockJwtSvc.On("GenerateToken", *user).Return(access, nil)
+	mockTokenRepo.On("CreateRefreshToken", mock.Anything, mock.MatchedBy(func(token *domain.RefreshToken) bool {
+		return token.FamilyID == current.FamilyID && token.AccessTokenID == "new-jti" && token.TokenHash != ""
+	})).Return(nil)
+
+	usecase := NewUserUsecase(mockUserRepo, mockTokenRepo, mockPasswordSvc, mockJwtSvc)
+	tokens, err := usecase.Refresh(context.Background(), "current-token")
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	assert.Equal(t, "signed.jwt", tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.NotEqual(t, "current-token", tokens.RefreshToken)
+	mockTokenRepo.AssertExpectations(t)
+	mockUserRepo.AssertExpectations(t)
+}
+
+// TestLogin_Failure_DisabledAccount rejects a disabled user with the same
+// error as a wrong password, even when the password is right.
+func TestLogin_Failure_DisabledAccount(t *testing.T) {
+	mockUserRepo := new(mocks.IUserRepository)
+	mockPasswordSvc := new(mocks.IPasswordService)
+	mockJwtSvc := new(mocks.IJWTService)
+	mockTokenRepo := new(mocks.ITokenRepository)
+
+	disabled := &domain.User{ID: primitive.NewObjectID(), Username: "gone", Password: "hashed", DisabledAt: time.Now()}
+	mockUserRepo.On("FindByUsername", mock.Anything, "gone").Return(disabled, nil)
+	mockPasswordSvc.On("CheckPasswordHash", "right-password", "hashed").Return(true)
+
+	usecase := NewUserUsecase(mockUserRepo, mockTokenRepo, mockPasswordSvc, mockJwtSvc)
+	tokens, err := usecase.Login(context.Background(), "gone", "right-password", "")
+
+	// --- ASSERT ---
+	assert.EqualError(t, err, "invalid username or password")
+	assert.Nil(t, tokens)
+	mockJwtSvc.AssertNotCalled(t, "GenerateToken", mock.Anything)
+	mockTokenRepo.AssertNotCalled(t, "CreateRefreshToken", mock.Anything, mock.Anything)
+}
+
+// TestLogin_Failure_UnknownUserChecksDummyHash checks the password of an
+// unknown username against a hash all the same, so it takes as long to
+// refuse as a wrong password.
+func TestLogin_Failure_UnknownUserChecksDummyHash(t *testing.T) {
+	mockUserRepo := new(mocks.IUserRepository)
+	mockPasswordSvc := new(mocks.IPasswordService)
+	mockJwtSvc := new(mocks.IJWTService)
+	mockTokenRepo := new(mocks.ITokenRepository)
+
+	mockUserRepo.On("FindByUsername", mock.Anything, "nobody").Return(nil, mongo.ErrNoDocuments)
+	mockPasswordSvc.On("CheckPasswordHash", "guess", dummyPasswordHash).Return(false)
+
+	usecase := NewUserUsecase(mockUserRepo, mockTokenRepo, mockPasswordSvc, mockJwtSvc)
+	tokens, err := usecase.Login(context.Background(), "nobody", "guess", "")
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Nil(t, tokens)
+	mockPasswordSvc.AssertExpectations(t)
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	require.NoError(t, err)
+	assert.Equal(t, 14, cost, "the dummy hash costs as much as real ones")
+}
+
+// TestLogin_RepositoryErrorIsNotAFailure returns a failed user lookup as it
+// is, without counting it against the username or the client.
+func TestLogin_RepositoryErrorIsNotAFailure(t *testing.T) {
+	mockUserRepo := new(mocks.IUserRepository)
+	mockPasswordSvc := new(mocks.IPasswordService)
+	mockJwtSvc := new(mocks.IJWTService)
+	mockTokenRepo := new(mocks.ITokenRepository)
+	repos := repositories.NewMemoryRepositories()
+	lookupErr := errors.New("connection refused")
+
+	mockUserRepo.On("FindByUsername", mock.Anything, "alice").Return(nil, lookupErr)
+
+	usecase := NewUserUsecase(mockUserRepo, mockTokenRepo, mockPasswordSvc, mockJwtSvc,
+		WithLoginThrottle(repos.LoginAttempts, testLoginPolicy))
+	tokens, err := usecase.Login(context.Background(), "alice", "password", "203.0.113.7")
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, lookupErr)
+	assert.NotErrorIs(t, err, ErrInvalidCredentials)
+	assert.Nil(t, tokens)
+	mockPasswordSvc.AssertNotCalled(t, "CheckPasswordHash", mock.Anything, mock.Anything)
+	for _, key := range []string{usernameLoginKey("alice"), ipLoginKey("203.0.113.7")} {
+		_, findErr := repos.LoginAttempts.Find(context.Background(), key)
+		assert.ErrorIs(t, findErr, mongo.ErrNoDocuments, key)
+	}
+}
+
+// withSessions gives the user the password "old-password" and a user
+// usecase that mails through mailer, and logs them in twice, as if from two
+// devices.
+func withSessions(mailer infrastructure.IMailer) fixtureOption {
+	return func(t *testing.T, f *fixture) {
+		f.setPassword(t, f.user, "old-password")
+		f.accounts = f.newUserUsecase(t, WithMailer(mailer), WithUserAuditLog(f.repos.Audit))
+		for range 2 {
+			tokens, err := f.accounts.Login(context.Background(), "user", "old-password", "")
+			require.NoError(t, err)
+			f.sessions = append(f.sessions, tokens)
+		}
+	}
+}
+
+// assertSessionsEnded checks that the refresh and access tokens of every
+// session withSessions started are revoked.
+func (f *fixture) assertSessionsEnded(t *testing.T) {
+	ctx := context.Background()
+	for _, session := range f.sessions {
+		stored, err := f.repos.Tokens.FindRefreshTokenByHash(ctx, infrastructure.HashToken(session.RefreshToken))
+		require.NoError(t, err)
+		revoked, err := f.repos.Tokens.IsAccessTokenRevoked(ctx, stored.AccessTokenID)
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		_, err = f.accounts.Refresh(ctx, session.RefreshToken)
+		assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	}
+}
+
+// TestChangePassword_EndsEverySession changes a password and expects every
+// earlier session to end, while the caller gets a fresh one.
+func TestChangePassword_EndsEverySession(t *testing.T) {
+	f := newFixture(t, withSessions(new(mocks.IMailer)))
+	ctx := context.Background()
+
+	_, wrongErr := f.accounts.ChangePassword(ctx, f.user, "guess", "new-password", "")
+	_, emptyErr := f.accounts.ChangePassword(ctx, f.user, "old-password", "", "")
+	_, missingErr := f.accounts.ChangePassword(ctx, primitive.NewObjectID(), "old-password", "new-password", "")
+	tokens, err := f.accounts.ChangePassword(ctx, f.user, "old-password", "new-password", "")
+	require.NoError(t, err)
+	_, oldLoginErr := f.accounts.Login(ctx, "user", "old-password", "")
+	_, newLoginErr := f.accounts.Login(ctx, "user", "new-password", "")
+	history, _, err := f.repos.Audit.List(ctx, repositories.AuditQuery{EntityID: f.user, Limit: 10})
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, wrongErr, ErrWrongPassword)
+	assert.ErrorIs(t, emptyErr, ErrInvalidPassword)
+	assert.ErrorIs(t, missingErr, ErrUserNotFound)
+	f.assertSessionsEnded(t)
+	_, err = f.accounts.Refresh(ctx, tokens.RefreshToken)
+	assert.NoError(t, err, "the session the change returns is a new one")
+	assert.Error(t, oldLoginErr)
+	assert.NoError(t, newLoginErr)
+	require.Len(t, history, 1)
+	assert.Equal(t, f.user, history[0].ActorID)
+	assert.Equal(t, "password", history[0].Changes[0].Field)
+}
+
+// newResetMailer returns a mailer that passes on what it sends, since reset
+// tokens are mailed in the background.
+func newResetMailer() (*mocks.IMailer, <-chan infrastructure.Mail) {
+	mailer := new(mocks.IMailer)
+	sent := make(chan infrastructure.Mail, 8)
+	mailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent <- args.Get(1).(infrastructure.Mail)
+	}).Return(nil)
+	return mailer, sent
+}
+
+func receiveMail(t *testing.T, sent <-chan infrastructure.Mail) infrastructure.Mail {
+	select {
+	case mail := <-sent:
+		return mail
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no mail was sent")
+		return infrastructure.Mail{}
+	}
+}
+
+// TestPasswordReset_MailsSingleUseToken walks through the forgot-password
+// flow: the token is mailed, works once, and ends every session.
+func TestPasswordReset_MailsSingleUseToken(t *testing.T) {
+	mailer, sent := newResetMailer()
+	f := newFixture(t, withSessions(mailer))
+	ctx := context.Background()
+	// The token is on a line of its own in the mail.
+	tokenIn := func(mail infrastructure.Mail) string { return strings.Split(mail.Body, "\n")[2] }
+
+	unknownErr := f.accounts.RequestPasswordReset(ctx, "nobody", "")
+	require.NoError(t, f.accounts.RequestPasswordReset(ctx, "user", ""))
+	mails := []infrastructure.Mail{receiveMail(t, sent)}
+	require.NoError(t, f.accounts.RequestPasswordReset(ctx, "user", ""))
+	mails = append(mails, receiveMail(t, sent))
+	emptyErr := f.accounts.ConfirmPasswordReset(ctx, tokenIn(mails[1]), "")
+	confirmErr := f.accounts.ConfirmPasswordReset(ctx, tokenIn(mails[1]), "new-password")
+	replayErr := f.accounts.ConfirmPasswordReset(ctx, tokenIn(mails[1]), "other-password")
+	supersededErr := f.accounts.ConfirmPasswordReset(ctx, tokenIn(mails[0]), "other-password")
+	bogusErr := f.accounts.ConfirmPasswordReset(ctx, "bogus", "other-password")
+	_, loginErr := f.accounts.Login(ctx, "user", "new-password", "")
+
+	// --- ASSERT ---
+	assert.NoError(t, unknownErr, "unknown usernames are not revealed")
+	assert.Equal(t, "user", mails[0].To)
+	assert.Len(t, tokenIn(mails[0]), 43)
+	assert.ErrorIs(t, emptyErr, ErrInvalidPassword)
+	assert.NoError(t, confirmErr, "a refused password does not spend the token")
+	assert.ErrorIs(t, replayErr, ErrInvalidResetToken)
+	assert.ErrorIs(t, supersededErr, ErrInvalidResetToken, "earlier tokens stop working once the password changes")
+	assert.ErrorIs(t, bogusErr, ErrInvalidResetToken)
+	assert.NoError(t, loginErr)
+	f.assertSessionsEnded(t)
+	stored, err := f.repos.Tokens.FindPasswordResetTokenByHash(ctx, infrastructure.HashToken(tokenIn(mails[1])))
+	require.NoError(t, err)
+	assert.Equal(t, f.user, stored.UserID)
+	assert.False(t, stored.UsedAt.IsZero())
+	assert.Empty(t, sent, "no mail is sent for unknown usernames")
+}
+
+// TestPasswordReset_KeepsConcurrentDisable disables the account while a
+// reset is being confirmed, and expects it to stay disabled.
+func TestPasswordReset_KeepsConcurrentDisable(t *testing.T) {
+	mailer := new(mocks.IMailer)
+	var sent infrastructure.Mail
+	mailer.On("Send", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(1).(infrastructure.Mail)
+	}).Return(nil)
+	f := newFixture(t, withSessions(mailer))
+	ctx := context.Background()
+	var background sync.WaitGroup
+	users := &racingUserRepository{IUserRepository: f.repos.Users, afterFind: func() {
+		_, err := f.newUserAdminUsecase().DisableUser(ctx, f.user.Hex(), f.admin)
+		require.NoError(t, err)
+	}}
+	accounts := f.newUserUsecase(t, WithMailer(mailer), WithBackgroundWork(&background))
+	racing := NewUserUsecase(users, f.repos.Tokens, newFakePasswordService(), infrastructure.NewJWTService())
+
+	require.NoError(t, accounts.RequestPasswordReset(ctx, "user", ""))
+	background.Wait()
+	err := racing.ConfirmPasswordReset(ctx, strings.Split(sent.Body, "\n")[2], "new-password")
+	user, findErr := f.repos.Users.FindByID(ctx, f.user)
+
+	// --- ASSERT ---
+	assert.NoError(t, err)
+	require.NoError(t, findErr)
+	assert.False(t, user.DisabledAt.IsZero())
+}
+
+// TestPasswordReset_ExpiredToken refuses a token past its expiry.
+func TestPasswordReset_ExpiredToken(t *testing.T) {
+	f := newFixture(t, withSessions(new(mocks.IMailer)))
+	ctx := context.Background()
+	past := time.Now().UTC().Add(-2 * PasswordResetTokenTTL)
+	require.NoError(t, f.repos.Tokens.CreatePasswordResetToken(ctx, &domain.PasswordResetToken{
+		UserID: f.user, TokenHash: infrastructure.HashToken("expired"), ExpiresAt: past.Add(PasswordResetTokenTTL), CreatedAt: past,
+	}))
+
+	err := f.accounts.ConfirmPasswordReset(ctx, "expired", "new-password")
+
+	// --- ASSERT ---
+	assert.ErrorIs(t, err, ErrInvalidResetToken)
+	_, err = f.accounts.Login(ctx, "user", "old-password", "")
+	assert.NoError(t, err)
+}
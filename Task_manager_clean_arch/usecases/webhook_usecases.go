@@ -0,0 +1,681 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"taskmanager/domain"
+	"taskmanager/infrastructure"
+	"taskmanager/repositories"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// MaxWebhooks caps the webhooks one user can have.
+	MaxWebhooks         = 10
+	MaxWebhookURLLength = 2048
+
+	DefaultWebhookInterval = 10 * time.Second
+	// DefaultWebhookWorkers is how many deliveries are sent at once.
+	DefaultWebhookWorkers = 4
+	// WebhookMaxAttempts is how many times a delivery is tried before it
+	// fails for good. The first retry waits WebhookRetryDelay, and every
+	// later one twice as long as the one before, up to MaxWebhookRetryDelay.
+	WebhookMaxAttempts   = 8
+	WebhookRetryDelay    = 30 * time.Second
+	MaxWebhookRetryDelay = time.Hour
+	// DefaultWebhookFailureLimit is how many deliveries in a row can fail
+	// for good before the webhook is disabled.
+	DefaultWebhookFailureLimit = 5
+	// DefaultWebhookRetention is how long finished deliveries stay in the log.
+	DefaultWebhookRetention = 30 * 24 * time.Hour
+
+	DefaultWebhookDeliveryPageSize = 50
+	MaxWebhookDeliveryPageSize     = 200
+
+	// webhookLease is how long a claimed delivery is hidden from other
+	// workers while it is sent. It must outlast the sender's timeout.
+	webhookLease = time.Minute
+	// maxWebhookErrorLength keeps long error messages out of the log.
+	maxWebhookErrorLength = 500
+)
+
+var (
+	// ErrInvalidWebhook is returned for a webhook that cannot be saved, when
+	// the user already has MaxWebhooks, and when redelivering to a disabled
+	// webhook.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrWebhookNotFound is returned for a webhook or delivery that does not
+	// exist or belongs to another user.
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+type IWebhookUsecase interface {
+	// PublishTaskEvents queues a delivery of each event to every enabled
+	// webhook that wants it.
+	ITaskEventSink
+	// Run sends due deliveries immediately, then on every tick or as soon as
+	// new ones are queued, until ctx is cancelled. It returns once the
+	// attempts in progress have finished.
+	Run(ctx context.Context)
+	// DeliverDue makes one attempt at every delivery due at now, and returns
+	// how many succeeded.
+	DeliverDue(ctx context.Context, now time.Time) (int, error)
+
+	// CreateWebhook registers webhook for the user and returns it with its
+	// signing secret.
+	CreateWebhook(ctx context.Context, webhook *domain.Webhook, userID primitive.ObjectID) (*domain.Webhook, error)
+	ListWebhooks(ctx context.Context, userID primitive.ObjectID) ([]domain.Webhook, error)
+	GetWebhook(ctx context.Context, webhookID string, userID primitive.ObjectID) (*domain.Webhook, error)
+	// UpdateWebhook applies the changes the update sets. Enabling a webhook
+	// clears its count of failed deliveries.
+	UpdateWebhook(ctx context.Context, webhookID string, update *WebhookUpdate, userID primitive.ObjectID) (*domain.Webhook, error)
+	// DeleteWebhook deletes the webhook and its delivery log.
+	DeleteWebhook(ctx context.Context, webhookID string, userID primitive.ObjectID) error
+	// ListDeliveries returns up to limit of the webhook's deliveries, newest first.
+	ListDeliveries(ctx context.Context, webhookID string, limit int, userID primitive.ObjectID) ([]domain.WebhookDelivery, error)
+	// Redeliver queues the payload of an earlier delivery again, as a new
+	// delivery that is due now.
+	Redeliver(ctx context.Context, webhookID, deliveryID string, userID primitive.ObjectID) (*domain.WebhookDelivery, error)
+}
+
+// WebhookUpdate holds the changes to a webhook; nil fields are left as they are.
+type WebhookUpdate struct {
+	URL      *string
+	Events   *[]string
+	AllTasks *bool
+	Enabled  *bool
+}
+
+// WebhookEvent is the JSON payload of a delivery. ID is the event's, so a
+// receiver can spot an event it has already handled in a retry or redelivery.
+type WebhookEvent struct {
+	ID             string      `json:"id"`
+	Event          string      `json:"event"`
+	OccurredAt     time.Time   `json:"occurred_at"`
+	ActorID        string      `json:"actor_id"`
+	PreviousStatus string      `json:"previous_status,omitempty"`
+	Task           WebhookTask `json:"task"`
+}
+
+// WebhookTask is the task of a WebhookEvent.
+type WebhookTask struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	UserID      string     `json:"user_id"`
+	AssigneeID  string     `json:"assignee_id,omitempty"`
+	ProjectID   string     `json:"project_id,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+	Version     int64      `json:"version"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type webhookUsecase struct {
+	webhookRepo  repositories.IWebhookRepository
+	sender       infrastructure.IWebhookSender
+	interval     time.Duration
+	workers      int
+	failureLimit int
+	retention    time.Duration
+	permissions  infrastructure.IPermissionChecker
+	// queued wakes Run when deliveries are queued.
+	queued chan struct{}
+	// clock measures how long DeliverDue has been running.
+	clock func() time.Time
+}
+
+// WebhookOption customizes a webhook usecase at construction time.
+type WebhookOption func(*webhookUsecase)
+
+// WithWebhookInterval sets how often Run looks for due deliveries.
+func WithWebhookInterval(interval time.Duration) WebhookOption {
+	return func(uc *webhookUsecase) { uc.interval = interval }
+}
+
+// WithWebhookWorkers sets how many deliveries are sent at once.
+func WithWebhookWorkers(workers int) WebhookOption {
+	return func(uc *webhookUsecase) { uc.workers = workers }
+}
+
+// WithWebhookFailureLimit sets how many deliveries in a row can fail before
+// a webhook is disabled.
+func WithWebhookFailureLimit(limit int) WebhookOption {
+	return func(uc *webhookUsecase) { uc.failureLimit = limit }
+}
+
+// WithWebhookRetention sets how long finished deliveries stay in the log.
+func WithWebhookRetention(retention time.Duration) WebhookOption {
+	return func(uc *webhookUsecase) { uc.retention = retention }
+}
+
+// WithWebhookPermissions lets users whose roles grant webhook:all_tasks
+// create webhooks for every task. Without it, nobody can.
+func WithWebhookPermissions(checker infrastructure.IPermissionChecker) WebhookOption {
+	return func(uc *webhookUsecase) { uc.permissions = checker }
+}
+
+func NewWebhookUsecase(webhookRepo repositories.IWebhookRepository, sender infrastructure.IWebhookSender, opts ...WebhookOption) IWebhookUsecase {
+	uc := &webhookUsecase{
+		webhookRepo:  webhookRepo,
+		sender:       sender,
+		interval:     DefaultWebhookInterval,
+		workers:      DefaultWebhookWorkers,
+		failureLimit: DefaultWebhookFailureLimit,
+		retention:    DefaultWebhookRetention,
+		queued:       make(chan struct{}, 1),
+		clock:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	return uc
+}
+
+// checkWebhook validates and normalizes the URL and event list of a webhook.
+func checkWebhook(webhook *domain.Webhook) error {
+	webhook.URL = strings.TrimSpace(webhook.URL)
+	if len(webhook.URL) > MaxWebhookURLLength {
+		return fmt.Errorf("%w: url is longer than %d characters", ErrInvalidWebhook, MaxWebhookURLLength)
+	}
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("%w: url must not contain credentials", ErrInvalidWebhook)
+	}
+
+	var events []string
+	for _, event := range webhook.Events {
+		event = strings.TrimSpace(event)
+		if !slices.Contains(TaskEventTypes(), event) {
+			return fmt.Errorf("%w: unknown event %q; events are %s", ErrInvalidWebhook, event, strings.Join(TaskEventTypes(), ", "))
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	webhook.Events = events
+	return nil
+}
+
+// checkAllTasks fails with ErrForbidden unless userID may watch every task.
+func (uc *webhookUsecase) checkAllTasks(ctx context.Context, userID primitive.ObjectID) error {
+	if uc.permissions != nil {
+		permissions, err := uc.permissions.UserPermissions(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(permissions, domain.PermWebhookAllTasks) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: all_tasks needs the %s permission", ErrForbidden, domain.PermWebhookAllTasks)
+}
+
+func (uc *webhookUsecase) CreateWebhook(ctx context.Context, webhook *domain.Webhook, userID primitive.ObjectID) (*domain.Webhook, error) {
+	if err := checkWebhook(webhook); err != nil {
+		return nil, err
+	}
+	if webhook.AllTasks {
+		if err := uc.checkAllTasks(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	existing, err := uc.webhookRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxWebhooks {
+		return nil, fmt.Errorf("%w: no more than %d webhooks; delete one first", ErrInvalidWebhook, MaxWebhooks)
+	}
+
+	secret, err := infrastructure.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	webhook.ID = primitive.NilObjectID
+	webhook.UserID = userID
+	webhook.Secret = secret
+	webhook.FailedDeliveries = 0
+	webhook.DisabledAt = time.Time{}
+	webhook.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := uc.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (uc *webhookUsecase) ListWebhooks(ctx context.Context, userID primitive.ObjectID) ([]domain.Webhook, error) {
+	return uc.webhookRepo.ListByUserID(ctx, userID)
+}
+
+func (uc *webhookUsecase) GetWebhook(ctx context.Context, webhookID string, userID primitive.ObjectID) (*domain.Webhook, error) {
+	id, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	webhook, err := uc.webhookRepo.GetByID(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) || err == nil && webhook.UserID != userID {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+func (uc *webhookUsecase) UpdateWebhook(ctx context.Context, webhookID string, update *WebhookUpdate, userID primitive.ObjectID) (*domain.Webhook, error) {
+	webhook, err := uc.GetWebhook(ctx, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if update.URL != nil {
+		webhook.URL = *update.URL
+	}
+	if update.Events != nil {
+		webhook.Events = *update.Events
+	}
+	if update.AllTasks != nil {
+		if *update.AllTasks && !webhook.AllTasks {
+			if err := uc.checkAllTasks(ctx, userID); err != nil {
+				return nil, err
+			}
+		}
+		webhook.AllTasks = *update.AllTasks
+	}
+	if err := checkWebhook(webhook); err != nil {
+		return nil, err
+	}
+	if update.Enabled != nil {
+		switch {
+		case *update.Enabled:
+			webhook.DisabledAt = time.Time{}
+			webhook.FailedDeliveries = 0
+		case webhook.DisabledAt.IsZero():
+			webhook.DisabledAt = time.Now().UTC().Truncate(time.Millisecond)
+		}
+	}
+	if err := uc.webhookRepo.Update(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (uc *webhookUsecase) DeleteWebhook(ctx context.Context, webhookID string, userID primitive.ObjectID) error {
+	webhook, err := uc.GetWebhook(ctx, webhookID, userID)
+	if err != nil {
+		return err
+	}
+	return uc.webhookRepo.Delete(ctx, webhook.ID)
+}
+
+func (uc *webhookUsecase) ListDeliveries(ctx context.Context, webhookID string, limit int, userID primitive.ObjectID) ([]domain.WebhookDelivery, error) {
+	webhook, err := uc.GetWebhook(ctx, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultWebhookDeliveryPageSize
+	}
+	if limit > MaxWebhookDeliveryPageSize {
+		limit = MaxWebhookDeliveryPageSize
+	}
+	return uc.webhookRepo.ListDeliveries(ctx, webhook.ID, limit)
+}
+
+func (uc *webhookUsecase) Redeliver(ctx context.Context, webhookID, deliveryID string, userID primitive.ObjectID) (*domain.WebhookDelivery, error) {
+	webhook, err := uc.GetWebhook(ctx, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := primitive.ObjectIDFromHex(deliveryID)
+	if err != nil {
+		return nil, ErrWebhookNotFound
+	}
+	earlier, err := uc.webhookRepo.GetDelivery(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) || err == nil && earlier.WebhookID != webhook.ID {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.DisabledAt.IsZero() {
+		return nil, fmt.Errorf("%w: the webhook is disabled; enable it first", ErrInvalidWebhook)
+	}
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	delivery := &domain.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       earlier.EventID,
+		Event:         earlier.Event,
+		Payload:       earlier.Payload,
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := uc.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	uc.wake()
+	return delivery, nil
+}
+
+// wants reports whether webhook should be sent event. allTasks says whether
+// the webhook may see every task, rather than only its owner's; such a
+// webhook keeps seeing the task, so it is not told of access revoked from
+// others.
+func wants(webhook *domain.Webhook, event *TaskEvent, allTasks bool) bool {
+	if len(webhook.Events) > 0 && !slices.Contains(webhook.Events, event.Type) {
+		return false
+	}
+	if event.Type == EventTaskAccessRevoked {
+		return slices.Contains(event.Audience, webhook.UserID)
+	}
+	return allTasks || slices.Contains(event.Audience, webhook.UserID)
+}
+
+// allTasksOwners returns the owners of all_tasks webhooks who still hold
+// webhook:all_tasks. Roles can change after a webhook is created, and a
+// webhook whose owner lost the permission only sees the owner's tasks.
+func (uc *webhookUsecase) allTasksOwners(ctx context.Context, webhooks []domain.Webhook) map[primitive.ObjectID]bool {
+	owners := map[primitive.ObjectID]bool{}
+	for i := range webhooks {
+		ownerID := webhooks[i].UserID
+		if _, checked := owners[ownerID]; checked || !webhooks[i].AllTasks {
+			continue
+		}
+		err := uc.checkAllTasks(ctx, ownerID)
+		if err != nil && !errors.Is(err, ErrForbidden) {
+			log.Printf("Limited the all_tasks webhooks of user %s to their own tasks: %v", ownerID.Hex(), err)
+		}
+		owners[ownerID] = err == nil
+	}
+	return owners
+}
+
+// PublishTaskEvents stores the deliveries before returning, so they survive
+// a restart. Only the webhooks of the events' audience and the all_tasks
+// webhooks are looked at. Failures are logged: the task changes are already
+// committed.
+func (uc *webhookUsecase) PublishTaskEvents(ctx context.Context, events []TaskEvent) {
+	var audience []primitive.ObjectID
+	for i := range events {
+		for _, userID := range events[i].Audience {
+			if !slices.Contains(audience, userID) {
+				audience = append(audience, userID)
+			}
+		}
+	}
+	webhooks, err := uc.webhookRepo.ListEnabledFor(ctx, audience)
+	if err != nil {
+		log.Printf("Dropped webhook deliveries of %d task events: %v", len(events), err)
+		return
+	}
+
+	allTasks := uc.allTasksOwners(ctx, webhooks)
+	queued := 0
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for i := range events {
+		event := &events[i]
+		var payload []byte
+		for j := range webhooks {
+			if !wants(&webhooks[j], event, webhooks[j].AllTasks && allTasks[webhooks[j].UserID]) {
+				continue
+			}
+			if payload == nil {
+				if payload, err = json.Marshal(newWebhookEvent(event)); err != nil {
+					log.Printf("Dropped webhook deliveries of event %s: %v", event.ID.Hex(), err)
+					break
+				}
+			}
+			delivery := &domain.WebhookDelivery{
+				WebhookID:     webhooks[j].ID,
+				EventID:       event.ID,
+				Event:         event.Type,
+				Payload:       string(payload),
+				Status:        domain.WebhookDeliveryPending,
+				NextAttemptAt: now,
+				CreatedAt:     now,
+			}
+			if err := uc.webhookRepo.CreateDelivery(ctx, delivery); err != nil {
+				log.Printf("Dropped delivery of event %s to webhook %s: %v", event.ID.Hex(), webhooks[j].ID.Hex(), err)
+				continue
+			}
+			queued++
+		}
+	}
+	if queued > 0 {
+		uc.wake()
+	}
+}
+
+func newWebhookEvent(event *TaskEvent) *WebhookEvent {
+	task := &event.Task
+	payload := &WebhookEvent{
+		ID:             event.ID.Hex(),
+		Event:          event.Type,
+		OccurredAt:     event.OccurredAt,
+		ActorID:        event.ActorID.Hex(),
+		PreviousStatus: event.PreviousStatus,
+		Task: WebhookTask{
+			ID:          task.ID.Hex(),
+			Title:       task.Title,
+			Description: task.Description,
+			Status:      task.Status,
+			UserID:      task.UserID.Hex(),
+			Tags:        task.Tags,
+			Version:     task.Version,
+			CreatedAt:   task.CreatedAt,
+		},
+	}
+	if !task.Duedate.IsZero() {
+		payload.Task.DueDate = &task.Duedate
+	}
+	if !task.AssigneeID.IsZero() {
+		payload.Task.AssigneeID = task.AssigneeID.Hex()
+	}
+	if !task.ProjectID.IsZero() {
+		payload.Task.ProjectID = task.ProjectID.Hex()
+	}
+	return payload
+}
+
+// wake tells Run that deliveries are waiting, without blocking.
+func (uc *webhookUsecase) wake() {
+	select {
+	case uc.queued <- struct{}{}:
+	default:
+	}
+}
+
+func (uc *webhookUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.interval)
+	defer ticker.Stop()
+	for {
+		if sent, err := uc.DeliverDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("Webhook delivery failed after sending %d deliveries: %v", sent, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-uc.queued:
+		}
+	}
+}
+
+// webhookAttempt is the result of one attempt made by a DeliverDue worker.
+type webhookAttempt struct {
+	webhookID primitive.ObjectID
+	sent      bool
+	err       error
+}
+
+// DeliverDue claims due deliveries one at a time, so that several servers
+// can share the work, and then prunes the log. Up to uc.workers claimed
+// deliveries are sent at once, but only one per webhook, so an endpoint
+// that hangs holds up its own deliveries and nobody else's.
+//
+// Each claim is made at now plus the time spent so far, so that a slow
+// attempt does not leave the next claim with a lease that has already run
+// out. A delivery interrupted by shutdown is tried again once its claim
+// runs out.
+func (uc *webhookUsecase) DeliverDue(ctx context.Context, now time.Time) (int, error) {
+	start := uc.clock()
+	results := make(chan webhookAttempt, uc.workers)
+	busy := map[primitive.ObjectID]bool{}
+	sent := 0
+	var err error
+	collect := func() {
+		result := <-results
+		delete(busy, result.webhookID)
+		if result.sent {
+			sent++
+		}
+		if err == nil {
+			err = result.err
+		}
+	}
+	for err == nil && ctx.Err() == nil {
+		if len(busy) >= uc.workers {
+			collect()
+			continue
+		}
+		skip := make([]primitive.ObjectID, 0, len(busy))
+		for webhookID := range busy {
+			skip = append(skip, webhookID)
+		}
+		claimedAt := now.Add(uc.clock().Sub(start)).UTC().Truncate(time.Millisecond)
+		delivery, claimErr := uc.webhookRepo.ClaimDelivery(ctx, claimedAt, claimedAt.Add(webhookLease), skip)
+		if errors.Is(claimErr, mongo.ErrNoDocuments) {
+			if len(busy) == 0 {
+				break
+			}
+			// The webhooks being sent to may have more deliveries waiting.
+			collect()
+			continue
+		}
+		if claimErr != nil {
+			err = claimErr
+			break
+		}
+		busy[delivery.WebhookID] = true
+		go func() {
+			ok, err := uc.attempt(ctx, delivery, claimedAt)
+			results <- webhookAttempt{webhookID: delivery.WebhookID, sent: ok, err: err}
+		}()
+	}
+	for len(busy) > 0 {
+		collect()
+	}
+	if err != nil {
+		return sent, err
+	}
+	if ctx.Err() != nil {
+		return sent, ctx.Err()
+	}
+	if _, err := uc.webhookRepo.DeleteDeliveriesBefore(ctx, now.Add(-uc.retention)); err != nil {
+		return sent, err
+	}
+	return sent, nil
+}
+
+// attempt sends a claimed delivery once and records the outcome. After the
+// last attempt the delivery fails for good, which counts against its
+// webhook; any success clears the count.
+func (uc *webhookUsecase) attempt(ctx context.Context, delivery *domain.WebhookDelivery, now time.Time) (bool, error) {
+	claimedUntil := delivery.NextAttemptAt
+	webhook, err := uc.webhookRepo.GetByID(ctx, delivery.WebhookID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// The webhook was deleted along with its log while this was claimed.
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !webhook.DisabledAt.IsZero() {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.Error = "webhook is disabled"
+		_, err := uc.saveAttempt(ctx, delivery, claimedUntil)
+		return false, err
+	}
+
+	status, sendErr := uc.sender.Send(ctx, webhook.URL, webhook.Secret, delivery)
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+	delivery.Attempts++
+	delivery.LastAttemptAt = now
+	delivery.ResponseStatus = status
+	delivery.Error = ""
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.WebhookDeliverySucceeded
+	case delivery.Attempts >= WebhookMaxAttempts:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.Error = truncateError(sendErr)
+	default:
+		delivery.NextAttemptAt = now.Add(webhookRetryDelay(delivery.Attempts))
+		delivery.Error = truncateError(sendErr)
+	}
+	if saved, err := uc.saveAttempt(ctx, delivery, claimedUntil); !saved {
+		return false, err
+	}
+
+	// The webhook may have been edited while the delivery was sent, so only
+	// the count is written back, never the copy read before sending.
+	switch {
+	case sendErr == nil && webhook.FailedDeliveries > 0:
+		err = uc.webhookRepo.ResetDeliveryFailures(ctx, webhook.ID)
+	case delivery.Status == domain.WebhookDeliveryFailed:
+		var saved *domain.Webhook
+		saved, err = uc.webhookRepo.RecordDeliveryFailure(ctx, webhook.ID, uc.failureLimit, now)
+		if err == nil && saved.DisabledAt.Equal(now) {
+			log.Printf("Disabled webhook %s after %d failed deliveries in a row", webhook.ID.Hex(), saved.FailedDeliveries)
+		}
+	}
+	// A webhook deleted while the delivery was sent has no count to keep.
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return false, err
+	}
+	return sendErr == nil, nil
+}
+
+// saveAttempt records the outcome of an attempt, and reports false without
+// an error when the claim ran out while sending and another worker has
+// claimed the delivery since. The outcome is then that worker's to record.
+func (uc *webhookUsecase) saveAttempt(ctx context.Context, delivery *domain.WebhookDelivery, claimedUntil time.Time) (bool, error) {
+	err := uc.webhookRepo.UpdateDelivery(ctx, delivery, claimedUntil)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Dropped the outcome of delivery %s: its claim ran out while it was sent", delivery.ID.Hex())
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// webhookRetryDelay is how long to wait after the given number of failed
+// attempts.
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := WebhookRetryDelay
+	for i := 1; i < attempts && delay < MaxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxWebhookRetryDelay)
+}
+
+func truncateError(err error) string {
+	message := err.Error()
+	if len(message) > maxWebhookErrorLength {
+		message = strings.ToValidUTF8(message[:maxWebhookErrorLength], "")
+	}
+	return message
+}
@@ -0,0 +1,385 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"taskmanager/domain"
+	"taskmanager/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// withWebhooks adds a webhook usecase with a mocked sender, and has the task
+// usecase publish its events to it.
+func withWebhooks(opts ...WebhookOption) fixtureOption {
+	return func(t *testing.T, f *fixture) {
+		f.sender = new(mocks.IWebhookSender)
+		f.webhooks = NewWebhookUsecase(f.repos.Webhooks, f.sender, append(opts, WithWebhookPermissions(f.roles))...)
+		f.tasks = f.newTaskUsecase(WithUnitOfWork(f.repos.UnitOfWork), WithTaskEventSink(f.webhooks))
+	}
+}
+
+func TestWebhooks_DeliverEventsToSubscribers(t *testing.T) {
+	f := newFixture(t, withWebhooks())
+	ctx := context.Background()
+	ownerID, strangerID := primitive.NewObjectID(), primitive.NewObjectID()
+	adminID := f.admin
+	ownHook, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: " https://chat.example.com/hook "}, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, "https://chat.example.com/hook", ownHook.URL)
+	assert.NotEmpty(t, ownHook.Secret)
+	statusHook, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://ci.example.com", Events: []string{EventTaskStatusChanged}}, ownerID)
+	require.NoError(t, err)
+	_, err = f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://stranger.example.com"}, strangerID)
+	require.NoError(t, err)
+	adminHook, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://audit.example.com", AllTasks: true}, adminID)
+	require.NoError(t, err)
+	_, err = f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://stranger.example.com", AllTasks: true}, strangerID)
+	assert.ErrorIs(t, err, ErrForbidden, "only webhook:all_tasks holders can watch every task")
+	_, err = f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "ftp://example.com"}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	_, err = f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://example.com", Events: []string{"task.exploded"}}, ownerID)
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+
+	task, err := f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	_, err = f.tasks.UpdateTask(ctx, task.ID.Hex(), &domain.Task{Title: "Deploy", Status: StatusInProgress}, ownerID)
+	require.NoError(t, err)
+
+	sent := map[primitive.ObjectID][]WebhookEvent{}
+	f.sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			delivery := args.Get(3).(*domain.WebhookDelivery)
+			var event WebhookEvent
+			require.NoError(t, json.Unmarshal([]byte(delivery.Payload), &event))
+			sent[delivery.WebhookID] = append(sent[delivery.WebhookID], event)
+		}).
+		Return(http.StatusOK, nil)
+	delivered, err := f.webhooks.DeliverDue(ctx, time.Now())
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	assert.Equal(t, 7, delivered)
+	eventTypes := func(events []WebhookEvent) []string {
+		var types []string
+		for _, event := range events {
+			types = append(types, event.Event)
+		}
+		return types
+	}
+	assert.ElementsMatch(t, []string{EventTaskCreated, EventTaskUpdated, EventTaskStatusChanged}, eventTypes(sent[ownHook.ID]))
+	assert.ElementsMatch(t, []string{EventTaskCreated, EventTaskUpdated, EventTaskStatusChanged}, eventTypes(sent[adminHook.ID]))
+	require.Len(t, sent[statusHook.ID], 1)
+	changed := sent[statusHook.ID][0]
+	assert.Equal(t, StatusPending, changed.PreviousStatus)
+	assert.Equal(t, StatusInProgress, changed.Task.Status)
+	assert.Equal(t, task.ID.Hex(), changed.Task.ID)
+	assert.Equal(t, ownerID.Hex(), changed.ActorID)
+	assert.Len(t, sent, 3, "the stranger's webhook gets nothing")
+	f.sender.AssertCalled(t, "Send", mock.Anything, "https://chat.example.com/hook", ownHook.Secret, mock.Anything)
+	deliveries, err := f.webhooks.ListDeliveries(ctx, ownHook.ID.Hex(), 0, ownerID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	for _, delivery := range deliveries {
+		assert.Equal(t, domain.WebhookDeliverySucceeded, delivery.Status)
+		assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	}
+	_, err = f.webhooks.ListDeliveries(ctx, ownHook.ID.Hex(), 0, strangerID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
+
+func TestWebhooks_PublishLooksUpOnlyTheAudiencesWebhooks(t *testing.T) {
+	repo := new(mocks.IWebhookRepository)
+	webhooks := NewWebhookUsecase(repo, new(mocks.IWebhookSender))
+	a, b, c := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	repo.On("ListEnabledFor", mock.Anything, []primitive.ObjectID{a, b, c}).Return([]domain.Webhook{}, nil).Once()
+
+	webhooks.PublishTaskEvents(context.Background(), []TaskEvent{
+		{ID: primitive.NewObjectID(), Type: EventTaskUpdated, Audience: []primitive.ObjectID{a, b}},
+		{ID: primitive.NewObjectID(), Type: EventTaskStatusChanged, Audience: []primitive.ObjectID{b, c}},
+	})
+
+	// --- ASSERT ---
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything)
+}
+
+func TestWebhooks_AllTasksEndsWhenOwnerLosesPermission(t *testing.T) {
+	f := newFixture(t, withWebhooks())
+	ctx := context.Background()
+	otherID := primitive.NewObjectID()
+	admin, err := f.repos.Users.FindByID(ctx, f.admin)
+	require.NoError(t, err)
+	hook, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://audit.example.com", AllTasks: true}, admin.ID)
+	require.NoError(t, err)
+
+	admin.Roles = []string{domain.RoleUser}
+	require.NoError(t, f.repos.Users.Update(ctx, admin))
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Someone else's", Status: StatusPending}, otherID)
+	require.NoError(t, err)
+	own, err := f.tasks.CreateTask(ctx, &domain.Task{Title: "Own", Status: StatusPending}, admin.ID)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	deliveries, err := f.webhooks.ListDeliveries(ctx, hook.ID.Hex(), 0, admin.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1, "a demoted owner no longer gets other users' tasks")
+	var event WebhookEvent
+	require.NoError(t, json.Unmarshal([]byte(deliveries[0].Payload), &event))
+	assert.Equal(t, own.ID.Hex(), event.Task.ID)
+}
+
+func TestWebhooks_RetryWithBackoffThenDisable(t *testing.T) {
+	f := newFixture(t, withWebhooks(WithWebhookFailureLimit(1)))
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	hook, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://down.example.com"}, ownerID)
+	require.NoError(t, err)
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	f.sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(http.StatusBadGateway, errors.New("webhook answered 502 Bad Gateway"))
+
+	now := time.Now()
+	_, err = f.webhooks.DeliverDue(ctx, now)
+	require.NoError(t, err)
+	deliveries, err := f.webhooks.ListDeliveries(ctx, hook.ID.Hex(), 0, ownerID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	first := deliveries[0]
+	assert.Equal(t, domain.WebhookDeliveryPending, first.Status)
+	assert.Equal(t, 1, first.Attempts)
+	assert.Equal(t, http.StatusBadGateway, first.ResponseStatus)
+	assert.Equal(t, WebhookRetryDelay, first.NextAttemptAt.Sub(first.LastAttemptAt))
+
+	// Nothing is retried before the backoff has passed.
+	_, err = f.webhooks.DeliverDue(ctx, now.Add(WebhookRetryDelay/2))
+	require.NoError(t, err)
+	f.sender.AssertNumberOfCalls(t, "Send", 1)
+	for attempt := 1; attempt < WebhookMaxAttempts; attempt++ {
+		now = now.Add(webhookRetryDelay(attempt))
+		_, err = f.webhooks.DeliverDue(ctx, now)
+		require.NoError(t, err)
+	}
+
+	// --- ASSERT ---
+	f.sender.AssertNumberOfCalls(t, "Send", WebhookMaxAttempts)
+	deliveries, err = f.webhooks.ListDeliveries(ctx, hook.ID.Hex(), 0, ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.WebhookDeliveryFailed, deliveries[0].Status)
+	assert.Equal(t, "webhook answered 502 Bad Gateway", deliveries[0].Error)
+	disabled, err := f.webhooks.GetWebhook(ctx, hook.ID.Hex(), ownerID)
+	require.NoError(t, err)
+	assert.False(t, disabled.DisabledAt.IsZero())
+	assert.Equal(t, 1, disabled.FailedDeliveries)
+
+	_, err = f.webhooks.Redeliver(ctx, hook.ID.Hex(), first.ID.Hex(), ownerID)
+	assert.ErrorIs(t, err, ErrInvalidWebhook)
+	enabled := true
+	reenabled, err := f.webhooks.UpdateWebhook(ctx, hook.ID.Hex(), &WebhookUpdate{Enabled: &enabled}, ownerID)
+	require.NoError(t, err)
+	assert.True(t, reenabled.DisabledAt.IsZero())
+	assert.Zero(t, reenabled.FailedDeliveries)
+	again, err := f.webhooks.Redeliver(ctx, hook.ID.Hex(), first.ID.Hex(), ownerID)
+	require.NoError(t, err)
+	assert.Equal(t, first.EventID, again.EventID)
+	assert.Equal(t, first.Payload, again.Payload)
+	assert.Equal(t, domain.WebhookDeliveryPending, again.Status)
+}
+
+// TestWebhooks_EditsDuringASendAreKept edits the webhook while deliveries
+// to it are sent, and expects their outcomes to leave the edits alone.
+func TestWebhooks_EditsDuringASendAreKept(t *testing.T) {
+	f := newFixture(t, withWebhooks(WithWebhookFailureLimit(2)))
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	hook, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://old.example.com"}, ownerID)
+	require.NoError(t, err)
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	edit := func(update *WebhookUpdate) func(mock.Arguments) {
+		return func(mock.Arguments) {
+			_, err := f.webhooks.UpdateWebhook(ctx, hook.ID.Hex(), update, ownerID)
+			require.NoError(t, err)
+		}
+	}
+	newURL, events, disabled := "https://new.example.com", []string{EventTaskCreated}, false
+	f.sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(edit(&WebhookUpdate{URL: &newURL, Events: &events})).
+		Return(http.StatusBadGateway, errors.New("webhook answered 502 Bad Gateway")).Once()
+	f.sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(http.StatusBadGateway, errors.New("webhook answered 502 Bad Gateway")).Times(WebhookMaxAttempts - 1)
+
+	now := time.Now()
+	for attempt := 1; attempt <= WebhookMaxAttempts; attempt++ {
+		_, err = f.webhooks.DeliverDue(ctx, now)
+		require.NoError(t, err)
+		now = now.Add(webhookRetryDelay(attempt))
+	}
+	failed, err := f.webhooks.GetWebhook(ctx, hook.ID.Hex(), ownerID)
+	require.NoError(t, err)
+
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Release", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	f.sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(edit(&WebhookUpdate{Enabled: &disabled})).
+		Return(http.StatusOK, nil).Once()
+	_, err = f.webhooks.DeliverDue(ctx, now)
+	require.NoError(t, err)
+	succeeded, err := f.webhooks.GetWebhook(ctx, hook.ID.Hex(), ownerID)
+	require.NoError(t, err)
+
+	// --- ASSERT ---
+	f.sender.AssertExpectations(t)
+	assert.Equal(t, newURL, failed.URL)
+	assert.Equal(t, events, failed.Events)
+	assert.Equal(t, 1, failed.FailedDeliveries)
+	assert.True(t, failed.DisabledAt.IsZero())
+	assert.Equal(t, newURL, succeeded.URL)
+	assert.Zero(t, succeeded.FailedDeliveries)
+	assert.False(t, succeeded.DisabledAt.IsZero(), "a successful delivery does not enable the webhook again")
+}
+
+func TestWebhooks_ClaimsAfterASlowAttemptGetAFreshLease(t *testing.T) {
+	f := newFixture(t, withWebhooks(WithWebhookWorkers(1)))
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	_, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://slow.example.com", Events: []string{EventTaskCreated}}, ownerID)
+	require.NoError(t, err)
+	fastHook, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://fast.example.com", Events: []string{EventTaskUpdated}}, ownerID)
+	require.NoError(t, err)
+	task, err := f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	_, err = f.tasks.UpdateTask(ctx, task.ID.Hex(), &domain.Task{Title: "Deploy v2", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	clock := time.Now()
+	f.webhooks.(*webhookUsecase).clock = func() time.Time { return clock }
+	f.sender.On("Send", mock.Anything, "https://slow.example.com", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { clock = clock.Add(webhookLease + time.Second) }).
+		Return(http.StatusNoContent, nil)
+	var leasedUntil time.Time
+	f.sender.On("Send", mock.Anything, "https://fast.example.com", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			stored, err := f.repos.Webhooks.GetDelivery(ctx, args.Get(3).(*domain.WebhookDelivery).ID)
+			require.NoError(t, err)
+			leasedUntil = stored.NextAttemptAt
+		}).
+		Return(http.StatusNoContent, nil)
+
+	now := time.Now()
+	delivered, err := f.webhooks.DeliverDue(ctx, now)
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.True(t, leasedUntil.After(now.Add(webhookLease+time.Second)),
+		"the second claim is made after the first attempt, not when the batch started")
+	deliveries, err := f.webhooks.ListDeliveries(ctx, fastHook.ID.Hex(), 0, ownerID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, deliveries[0].Status)
+}
+
+func TestWebhooks_OutcomeOfALostClaimIsDropped(t *testing.T) {
+	f := newFixture(t, withWebhooks(WithWebhookFailureLimit(1), WithWebhookWorkers(1)))
+	ctx := context.Background()
+	ownerID := primitive.NewObjectID()
+	hook, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://slow.example.com"}, ownerID)
+	require.NoError(t, err)
+	_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending}, ownerID)
+	require.NoError(t, err)
+	clock := time.Now()
+	f.webhooks.(*webhookUsecase).clock = func() time.Time { return clock }
+	now := time.Now()
+	// The first attempt hangs past its lease, and another server claims the
+	// delivery again and sends it before the first attempt gives up.
+	f.sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			clock = clock.Add(webhookLease + time.Second)
+			delivered, err := f.webhooks.DeliverDue(ctx, now.Add(webhookLease+time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, 1, delivered)
+		}).
+		Return(http.StatusGatewayTimeout, errors.New("webhook timed out")).Once()
+	f.sender.On("Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(http.StatusNoContent, nil).Once()
+
+	delivered, err := f.webhooks.DeliverDue(ctx, now)
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+	f.sender.AssertNumberOfCalls(t, "Send", 2)
+	deliveries, err := f.webhooks.ListDeliveries(ctx, hook.ID.Hex(), 0, ownerID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, domain.WebhookDeliverySucceeded, deliveries[0].Status, "the timeout of the lost claim is not recorded")
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	stillEnabled, err := f.webhooks.GetWebhook(ctx, hook.ID.Hex(), ownerID)
+	require.NoError(t, err)
+	assert.True(t, stillEnabled.DisabledAt.IsZero())
+}
+
+func TestWebhooks_HangingEndpointHoldsUpOnlyItsOwnDeliveries(t *testing.T) {
+	f := newFixture(t, withWebhooks(WithWebhookWorkers(2)))
+	ctx := context.Background()
+	ownerID, otherID := primitive.NewObjectID(), primitive.NewObjectID()
+	_, err := f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://hanging.example.com"}, ownerID)
+	require.NoError(t, err)
+	_, err = f.webhooks.CreateWebhook(ctx, &domain.Webhook{URL: "https://up.example.com"}, otherID)
+	require.NoError(t, err)
+	for _, userID := range []primitive.ObjectID{ownerID, ownerID, ownerID, otherID, otherID} {
+		_, err = f.tasks.CreateTask(ctx, &domain.Task{Title: "Deploy", Status: StatusPending}, userID)
+		require.NoError(t, err)
+	}
+	// The hanging endpoint answers only once every delivery to the other
+	// one has been sent.
+	released := make(chan struct{})
+	var inFlight, mostInFlight atomic.Int32
+	f.sender.On("Send", mock.Anything, "https://hanging.example.com", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			if n := inFlight.Add(1); n > mostInFlight.Load() {
+				mostInFlight.Store(n)
+			}
+			defer inFlight.Add(-1)
+			select {
+			case <-released:
+			case <-time.After(5 * time.Second):
+			}
+		}).
+		Return(0, errors.New("webhook timed out"))
+	upSent := 0
+	f.sender.On("Send", mock.Anything, "https://up.example.com", mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) {
+			if upSent++; upSent == 2 {
+				close(released)
+			}
+		}).
+		Return(http.StatusNoContent, nil)
+
+	start := time.Now()
+	delivered, err := f.webhooks.DeliverDue(ctx, start)
+
+	// --- ASSERT ---
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Less(t, time.Since(start), 5*time.Second, "the other webhook's deliveries were not held up")
+	assert.Equal(t, int32(1), mostInFlight.Load(), "one delivery per webhook is in flight at a time")
+	f.sender.AssertNumberOfCalls(t, "Send", 5)
+}
+
+func TestWebhookRetryDelay_DoublesUpToTheCap(t *testing.T) {
+	// --- ASSERT ---
+	assert.Equal(t, WebhookRetryDelay, webhookRetryDelay(1))
+	assert.Equal(t, 2*WebhookRetryDelay, webhookRetryDelay(2))
+	assert.Equal(t, 4*WebhookRetryDelay, webhookRetryDelay(3))
+	assert.Equal(t, MaxWebhookRetryDelay, webhookRetryDelay(30))
+}